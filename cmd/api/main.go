@@ -1,6 +1,9 @@
 package main
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/Nimirandad/bike-rental-service/internal/app"
 	"github.com/Nimirandad/bike-rental-service/internal/config"
 )
@@ -24,10 +27,16 @@ import (
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
 
-// @securityDefinitions.basic BasicAuth
-
 func main() {
 	cfg := config.Load()
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := app.Migrate(&cfg, os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	app.Run(&cfg)
 }
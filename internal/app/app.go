@@ -1,11 +1,21 @@
 package app
 
 import (
+	"context"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
 	"github.com/Nimirandad/bike-rental-service/internal/config"
 	"github.com/Nimirandad/bike-rental-service/internal/database"
 	"github.com/Nimirandad/bike-rental-service/internal/logger"
+	"github.com/Nimirandad/bike-rental-service/internal/mailer"
+	"github.com/Nimirandad/bike-rental-service/internal/repositories"
 	"github.com/Nimirandad/bike-rental-service/internal/routes"
 	"github.com/Nimirandad/bike-rental-service/internal/server"
+	"github.com/Nimirandad/bike-rental-service/internal/services"
+	"github.com/Nimirandad/bike-rental-service/internal/tracing"
 )
 
 func Run(cfg *config.Config) {
@@ -14,19 +24,121 @@ func Run(cfg *config.Config) {
 
 	log.Info().Str("log_level", cfg.LogLevel).Msg("Logger initialized")
 
-	db, err := database.Connect(cfg.SQLitePath)
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.TracingExporter, cfg.TracingOTLPEndpoint)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to set up tracing")
+	}
+
+	db, err := database.Connect(cfg.DBDriver, cfg.DataSource(), cfg.AutoMigrate)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to connect to database")
 	}
-	defer db.Close()
+	db.QueryTimeout = time.Duration(cfg.DBQueryTimeoutSeconds) * time.Second
+
+	log.Info().Str("driver", cfg.DBDriver).Msg("Database connected")
+
+	adminAccountService := services.NewAdminAccountService(repositories.NewAdminAccountRepository(db))
+	if err := adminAccountService.EnsureBootstrapAdmin(context.Background(), cfg.AdminBootstrapEmail, cfg.AdminBootstrapPassword); err != nil {
+		log.Fatal().Err(err).Msg("Failed to create bootstrap admin")
+	}
 
-	log.Info().Str("db_path", cfg.SQLitePath).Msg("Database connected")
+	// Workers get their own context so they keep running while the HTTP
+	// server drains and are only stopped once no request can still need them.
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	var workers sync.WaitGroup
 
-	srv := server.NewServer(cfg, db.DB)
+	reservationService := services.NewReservationService(
+		repositories.NewRentalRepository(db),
+		repositories.NewUnitOfWork(db),
+		cfg.ReservationMinutes,
+	)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		reservationService.RunExpiryWorker(workerCtx, time.Duration(cfg.ReservationExpiryIntervalSeconds)*time.Second)
+	}()
+
+	outboxService := services.NewOutboxService(repositories.NewEmailOutboxRepository(db), newMailer(cfg))
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		outboxService.RunDeliveryWorker(workerCtx, time.Duration(cfg.OutboxIntervalSeconds)*time.Second)
+	}()
+
+	tokenService := services.NewTokenService(
+		repositories.NewRevokedTokenRepository(db),
+		repositories.NewUnitOfWork(db),
+		time.Duration(cfg.AccessTokenTTLMinutes)*time.Minute,
+		time.Duration(cfg.RefreshTokenTTLDays)*24*time.Hour,
+	)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		tokenService.RunCleanupWorker(workerCtx, time.Duration(cfg.TokenCleanupIntervalSeconds)*time.Second)
+	}()
+
+	srv := server.NewServer(cfg, db)
 	routes.RegisterRoutes(srv)
 
-	log.Info().Str("port", cfg.Port).Msg("Starting server")
-	if err := srv.Start(cfg.Port); err != nil {
-		log.Fatal().Err(err).Msg("Server failed to start")
+	signalCtx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+
+	// Both listeners share one lifecycle: a signal or either listener failing
+	// shuts both down.
+	serverErr := make(chan error, 2)
+	go func() {
+		log.Info().Str("port", cfg.Port).Msg("Starting server")
+		serverErr <- srv.Start(cfg.Port)
+	}()
+	if cfg.AdminListenerEnabled() {
+		go func() {
+			log.Info().Str("addr", cfg.AdminBindAddr).Str("port", cfg.AdminPort).Msg("Starting admin server")
+			serverErr <- srv.StartAdmin(cfg.AdminPort)
+		}()
+	}
+
+	var startErr error
+	select {
+	case startErr = <-serverErr:
+	case <-signalCtx.Done():
+		log.Info().Msg("Shutdown signal received, draining connections")
+	}
+	stopSignals()
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownTimeoutSeconds)*time.Second)
+	defer cancelShutdown()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("Server did not shut down cleanly")
+	}
+
+	stopWorkers()
+	workers.Wait()
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("Failed to flush traces")
+	}
+
+	if err := db.Close(); err != nil {
+		log.Error().Err(err).Msg("Failed to close database")
+	}
+
+	if startErr != nil {
+		log.Fatal().Err(startErr).Msg("Server failed to start")
 	}
+	log.Info().Msg("Server stopped")
+}
+
+// newMailer returns the mailer selected by MAILER: "smtp" for a real SMTP
+// server, anything else logs emails for local development.
+func newMailer(cfg *config.Config) mailer.Mailer {
+	log := logger.Get()
+
+	if cfg.MailerDriver == "smtp" {
+		log.Info().Str("host", cfg.SMTPHost).Int("port", cfg.SMTPPort).Msg("Using SMTP mailer")
+		return mailer.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom)
+	}
+
+	log.Info().Str("mail_dir", cfg.MailDir).Msg("Using log mailer")
+	return mailer.NewLogMailer(cfg.MailDir)
 }
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/Nimirandad/bike-rental-service/internal/config"
+	"github.com/Nimirandad/bike-rental-service/internal/database"
+)
+
+const migrateUsage = `usage: migrate <command>
+
+commands:
+  up          apply all pending migrations
+  down [n]    revert the last n migrations (default 1)
+  status      list migrations and when they were applied
+  seed        load demo bikes into an empty database`
+
+// Migrate runs the migrate subcommand named in args against the configured
+// database and writes its report to out.
+func Migrate(cfg *config.Config, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing command\n%s", migrateUsage)
+	}
+
+	db, err := database.Connect(cfg.DBDriver, cfg.DataSource(), false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	switch args[0] {
+	case "up":
+		applied, err := database.MigrateUp(db)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Applied %d migration(s)\n", applied)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps <= 0 {
+				return fmt.Errorf("down expects a positive number of steps, got %q", args[1])
+			}
+		}
+
+		reverted, err := database.MigrateDown(db, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Reverted %d migration(s)\n", reverted)
+
+	case "status":
+		states, err := database.MigrationStatus(db)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, state := range states {
+			appliedAt := "pending"
+			if state.AppliedAt != nil {
+				appliedAt = state.AppliedAt.UTC().Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", state.Version, state.Name, appliedAt)
+		}
+		return w.Flush()
+
+	case "seed":
+		seeded, err := database.Seed(db)
+		if err != nil {
+			return err
+		}
+		if seeded {
+			fmt.Fprintln(out, "Seed data loaded")
+		} else {
+			fmt.Fprintln(out, "Bikes already present, seed skipped")
+		}
+
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], migrateUsage)
+	}
+
+	return nil
+}
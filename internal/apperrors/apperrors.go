@@ -0,0 +1,104 @@
+// Package apperrors defines the typed errors returned by repositories and
+// services. Each error has a Kind, which decides the HTTP status it is
+// reported with, and a Code that API clients can match on instead of the
+// human-readable message.
+package apperrors
+
+import (
+	"errors"
+	"net/http"
+)
+
+type Kind int
+
+const (
+	KindNotFound Kind = iota + 1
+	KindConflict
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	// KindUnprocessable is a well-formed request that the current state of
+	// the resource does not allow, such as a forbidden status transition.
+	KindUnprocessable
+)
+
+// Status returns the HTTP status code for the kind.
+func (k Kind) Status() int {
+	switch k {
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindUnprocessable:
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Error is a domain error. Errors are compared by identity, so the sentinels
+// declared once in the constants package work with errors.Is however deeply
+// they are wrapped.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+
+	// sentinel is the error this one was derived from by WithMessage.
+	sentinel *Error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is reports whether e was derived from target by WithMessage.
+func (e *Error) Is(target error) bool {
+	return e.sentinel != nil && e.sentinel == target
+}
+
+// WithMessage returns a copy of err with a message describing this
+// occurrence, such as the limit that was exceeded. The copy keeps err's kind
+// and code and still matches err with errors.Is.
+func WithMessage(err *Error, message string) *Error {
+	return &Error{Kind: err.Kind, Code: err.Code, Message: message, sentinel: err}
+}
+
+func NotFound(code, message string) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message}
+}
+
+func Conflict(code, message string) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: message}
+}
+
+func Validation(code, message string) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: message}
+}
+
+func Unauthorized(code, message string) *Error {
+	return &Error{Kind: KindUnauthorized, Code: code, Message: message}
+}
+
+func Forbidden(code, message string) *Error {
+	return &Error{Kind: KindForbidden, Code: code, Message: message}
+}
+
+func Unprocessable(code, message string) *Error {
+	return &Error{Kind: KindUnprocessable, Code: code, Message: message}
+}
+
+// As returns the first *Error in err's chain.
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
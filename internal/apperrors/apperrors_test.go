@@ -0,0 +1,69 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKindStatus(t *testing.T) {
+	tests := []struct {
+		kind   Kind
+		status int
+	}{
+		{KindNotFound, http.StatusNotFound},
+		{KindConflict, http.StatusConflict},
+		{KindValidation, http.StatusBadRequest},
+		{KindUnauthorized, http.StatusUnauthorized},
+		{KindForbidden, http.StatusForbidden},
+		{KindUnprocessable, http.StatusUnprocessableEntity},
+		{Kind(0), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.status, tt.kind.Status())
+	}
+}
+
+func TestAs(t *testing.T) {
+	errBikeNotFound := NotFound("bike_not_found", "bike not found")
+
+	t.Run("Finds a wrapped error", func(t *testing.T) {
+		err := fmt.Errorf("bike with id 7: %w", errBikeNotFound)
+
+		appErr, ok := As(err)
+
+		assert.True(t, ok)
+		assert.Equal(t, "bike_not_found", appErr.Code)
+		assert.Equal(t, KindNotFound, appErr.Kind)
+		assert.True(t, errors.Is(err, errBikeNotFound))
+	})
+
+	t.Run("Ignores untyped errors", func(t *testing.T) {
+		_, ok := As(errors.New("database error"))
+
+		assert.False(t, ok)
+	})
+
+	t.Run("Errors with the same code are distinct", func(t *testing.T) {
+		assert.False(t, errors.Is(errBikeNotFound, NotFound("bike_not_found", "bike not found")))
+	})
+}
+
+func TestWithMessage(t *testing.T) {
+	errTooFar := Validation("too_far", "location is too far")
+
+	err := fmt.Errorf("ending rental: %w", WithMessage(errTooFar, "location must be within 2 km"))
+
+	appErr, ok := As(err)
+	assert.True(t, ok)
+	assert.Equal(t, "location must be within 2 km", appErr.Message)
+	assert.Equal(t, "too_far", appErr.Code)
+	assert.Equal(t, KindValidation, appErr.Kind)
+	assert.True(t, errors.Is(err, errTooFar))
+	assert.False(t, errors.Is(err, Validation("too_far", "location is too far")))
+	assert.Equal(t, "location is too far", errTooFar.Message)
+}
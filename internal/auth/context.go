@@ -0,0 +1,39 @@
+// Package auth carries the authenticated principal of a request through its
+// context. The middlewares in server/middlewares put it there; handlers read
+// it back with UserFromContext or AdminFromContext.
+package auth
+
+import (
+	"context"
+
+	"github.com/Nimirandad/bike-rental-service/internal/utils"
+)
+
+type contextKey int
+
+const (
+	userKey contextKey = iota
+	adminKey
+)
+
+// WithUser returns a copy of ctx carrying the claims of an authenticated rider.
+func WithUser(ctx context.Context, claims *utils.JWTClaims) context.Context {
+	return context.WithValue(ctx, userKey, claims)
+}
+
+// UserFromContext returns the rider stored by WithUser.
+func UserFromContext(ctx context.Context) (*utils.JWTClaims, bool) {
+	claims, ok := ctx.Value(userKey).(*utils.JWTClaims)
+	return claims, ok && claims != nil
+}
+
+// WithAdmin returns a copy of ctx carrying the claims of an authenticated admin.
+func WithAdmin(ctx context.Context, claims *utils.AdminJWTClaims) context.Context {
+	return context.WithValue(ctx, adminKey, claims)
+}
+
+// AdminFromContext returns the admin stored by WithAdmin.
+func AdminFromContext(ctx context.Context) (*utils.AdminJWTClaims, bool) {
+	claims, ok := ctx.Value(adminKey).(*utils.AdminJWTClaims)
+	return claims, ok && claims != nil
+}
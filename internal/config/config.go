@@ -2,26 +2,142 @@ package config
 
 import (
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	SQLitePath string
-	Port       string
-	LogLevel   string
+	DBDriver    string
+	DatabaseURL string
+	SQLitePath  string
+	AutoMigrate bool
+	Port        string
+	LogLevel    string
+
+	AdminPort     string
+	AdminBindAddr string
+
+	TracingExporter     string
+	TracingOTLPEndpoint string
+
+	HTTPReadTimeoutSeconds  int
+	HTTPWriteTimeoutSeconds int
+	HTTPIdleTimeoutSeconds  int
+	ShutdownTimeoutSeconds  int
+	DBQueryTimeoutSeconds   int
+
+	ReservationMinutes               int
+	ReservationExpiryIntervalSeconds int
+
+	PausedPricePerMinute float64
+
+	StationReturnPolicy string
+	OutOfStationFee     float64
+
+	NoParkingPolicy string
+	NoParkingFine   float64
+
+	ReturnDistanceMode  string
+	ReturnMaxDistanceKm float64
+
+	EBikeMinBatteryPercent int
+
+	AdminBootstrapEmail    string
+	AdminBootstrapPassword string
+
+	AccessTokenTTLMinutes       int
+	RefreshTokenTTLDays         int
+	TokenCleanupIntervalSeconds int
+
+	AppBaseURL                string
+	MailerDriver              string
+	SMTPHost                  string
+	SMTPPort                  int
+	SMTPUsername              string
+	SMTPPassword              string
+	MailFrom                  string
+	MailDir                   string
+	PasswordResetTTLMinutes   int
+	EmailVerificationTTLHours int
+	OutboxIntervalSeconds     int
 }
 
 func Load() Config {
 	_ = godotenv.Load()
 
 	return Config{
-		SQLitePath: getEnvDefault("SQLITE_PATH", SQLitePath),
-		Port:       getEnvDefault("HTTP_PORT", HTTPPort),
-		LogLevel:   getEnvDefault("LOG_LEVEL", LogLevel),
+		DBDriver:    getEnvDefault("DB_DRIVER", DBDriver),
+		DatabaseURL: os.Getenv("DATABASE_URL"),
+		SQLitePath:  getEnvDefault("SQLITE_PATH", SQLitePath),
+		AutoMigrate: getEnvBoolDefault("AUTO_MIGRATE", AutoMigrate),
+		Port:        getEnvDefault("HTTP_PORT", HTTPPort),
+		LogLevel:    getEnvDefault("LOG_LEVEL", LogLevel),
+
+		AdminPort:     os.Getenv("ADMIN_HTTP_PORT"),
+		AdminBindAddr: getEnvDefault("ADMIN_BIND_ADDR", AdminBindAddr),
+
+		TracingExporter:     getEnvDefault("TRACING_EXPORTER", TracingExporter),
+		TracingOTLPEndpoint: getEnvDefault("TRACING_OTLP_ENDPOINT", TracingOTLPEndpoint),
+
+		HTTPReadTimeoutSeconds:  getEnvIntDefault("HTTP_READ_TIMEOUT_SECONDS", HTTPReadTimeoutSeconds),
+		HTTPWriteTimeoutSeconds: getEnvIntDefault("HTTP_WRITE_TIMEOUT_SECONDS", HTTPWriteTimeoutSeconds),
+		HTTPIdleTimeoutSeconds:  getEnvIntDefault("HTTP_IDLE_TIMEOUT_SECONDS", HTTPIdleTimeoutSeconds),
+		ShutdownTimeoutSeconds:  getEnvIntDefault("SHUTDOWN_TIMEOUT_SECONDS", ShutdownTimeoutSeconds),
+		DBQueryTimeoutSeconds:   getEnvNonNegativeIntDefault("DB_QUERY_TIMEOUT_SECONDS", DBQueryTimeoutSeconds),
+
+		ReservationMinutes:               getEnvIntDefault("RESERVATION_MINUTES", ReservationMinutes),
+		ReservationExpiryIntervalSeconds: getEnvIntDefault("RESERVATION_EXPIRY_INTERVAL_SECONDS", ReservationExpiryIntervalSeconds),
+
+		PausedPricePerMinute: getEnvFloatDefault("PAUSED_PRICE_PER_MINUTE", PausedPricePerMinute),
+
+		StationReturnPolicy: getEnvDefault("STATION_RETURN_POLICY", StationReturnPolicy),
+		OutOfStationFee:     getEnvFloatDefault("OUT_OF_STATION_FEE", OutOfStationFee),
+
+		NoParkingPolicy: getEnvDefault("NO_PARKING_POLICY", NoParkingPolicy),
+		NoParkingFine:   getEnvFloatDefault("NO_PARKING_FINE", NoParkingFine),
+
+		ReturnDistanceMode:  getEnvDefault("RETURN_DISTANCE_MODE", ReturnDistanceMode),
+		ReturnMaxDistanceKm: getEnvFloatDefault("RETURN_MAX_DISTANCE_KM", ReturnMaxDistanceKm),
+
+		EBikeMinBatteryPercent: getEnvNonNegativeIntDefault("EBIKE_MIN_BATTERY_PERCENT", EBikeMinBatteryPercent),
+
+		AdminBootstrapEmail:    os.Getenv("ADMIN_BOOTSTRAP_EMAIL"),
+		AdminBootstrapPassword: os.Getenv("ADMIN_BOOTSTRAP_PASSWORD"),
+
+		AccessTokenTTLMinutes:       getEnvIntDefault("ACCESS_TOKEN_TTL_MINUTES", AccessTokenTTLMinutes),
+		RefreshTokenTTLDays:         getEnvIntDefault("REFRESH_TOKEN_TTL_DAYS", RefreshTokenTTLDays),
+		TokenCleanupIntervalSeconds: getEnvIntDefault("TOKEN_CLEANUP_INTERVAL_SECONDS", TokenCleanupIntervalSeconds),
+
+		AppBaseURL:                getEnvDefault("APP_BASE_URL", AppBaseURL),
+		MailerDriver:              getEnvDefault("MAILER", MailerDriver),
+		SMTPHost:                  os.Getenv("SMTP_HOST"),
+		SMTPPort:                  getEnvIntDefault("SMTP_PORT", SMTPPort),
+		SMTPUsername:              os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:              os.Getenv("SMTP_PASSWORD"),
+		MailFrom:                  getEnvDefault("MAIL_FROM", MailFrom),
+		MailDir:                   os.Getenv("MAIL_DIR"),
+		PasswordResetTTLMinutes:   getEnvIntDefault("PASSWORD_RESET_TTL_MINUTES", PasswordResetTTLMinutes),
+		EmailVerificationTTLHours: getEnvIntDefault("EMAIL_VERIFICATION_TTL_HOURS", EmailVerificationTTLHours),
+		OutboxIntervalSeconds:     getEnvIntDefault("OUTBOX_INTERVAL_SECONDS", OutboxIntervalSeconds),
 	}
 }
 
+// DataSource returns what the selected driver connects to: the file path for
+// SQLite and DATABASE_URL for Postgres.
+func (c *Config) DataSource() string {
+	if c.DBDriver == "postgres" {
+		return c.DatabaseURL
+	}
+	return c.SQLitePath
+}
+
+// AdminListenerEnabled reports whether admin routes are served on their own
+// listener. It is on when ADMIN_HTTP_PORT is set.
+func (c *Config) AdminListenerEnabled() bool {
+	return c.AdminPort != ""
+}
+
 func getEnvDefault(key, defaultValue string) string {
 	value := os.Getenv(key)
 	if value == "" {
@@ -29,3 +145,43 @@ func getEnvDefault(key, defaultValue string) string {
 	}
 	return value
 }
+
+// getEnvIntDefault returns the positive integer in key, or defaultValue when
+// the variable is unset or not a positive integer.
+func getEnvIntDefault(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvNonNegativeIntDefault is getEnvIntDefault for settings where 0 means
+// off, such as a query timeout or a battery threshold.
+func getEnvNonNegativeIntDefault(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvBoolDefault returns the boolean in key, or defaultValue when the
+// variable is unset or not a boolean.
+func getEnvBoolDefault(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvFloatDefault returns the non-negative number in key, or defaultValue
+// when the variable is unset or not a non-negative number.
+func getEnvFloatDefault(key string, defaultValue float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	return value
+}
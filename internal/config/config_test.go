@@ -16,6 +16,9 @@ func TestLoad_WithDefaultValues(t *testing.T) {
 
 	assert.Equal(t, "data/bike_rental.db", config.SQLitePath)
 	assert.Equal(t, "8080", config.Port)
+	assert.Equal(t, "sqlite", config.DBDriver)
+	assert.Equal(t, "none", config.TracingExporter)
+	assert.Equal(t, "http://localhost:4318", config.TracingOTLPEndpoint)
 }
 
 func TestLoad_WithCustomValues(t *testing.T) {
@@ -47,4 +50,150 @@ func TestGetEnvDefault_WithoutValue(t *testing.T) {
 	result := getEnvDefault("NON_EXISTENT_VAR", "default_value")
 
 	assert.Equal(t, "default_value", result)
-}
\ No newline at end of file
+}
+func TestLoad_ReservationSettings(t *testing.T) {
+	os.Setenv("RESERVATION_MINUTES", "15")
+	os.Setenv("RESERVATION_EXPIRY_INTERVAL_SECONDS", "not-a-number")
+	defer func() {
+		os.Unsetenv("RESERVATION_MINUTES")
+		os.Unsetenv("RESERVATION_EXPIRY_INTERVAL_SECONDS")
+	}()
+
+	config := Load()
+
+	assert.Equal(t, 15, config.ReservationMinutes)
+	assert.Equal(t, ReservationExpiryIntervalSeconds, config.ReservationExpiryIntervalSeconds)
+}
+
+func TestLoad_TokenSettings(t *testing.T) {
+	os.Setenv("ACCESS_TOKEN_TTL_MINUTES", "5")
+	os.Unsetenv("REFRESH_TOKEN_TTL_DAYS")
+	os.Setenv("TOKEN_CLEANUP_INTERVAL_SECONDS", "600")
+	defer func() {
+		os.Unsetenv("ACCESS_TOKEN_TTL_MINUTES")
+		os.Unsetenv("TOKEN_CLEANUP_INTERVAL_SECONDS")
+	}()
+
+	config := Load()
+
+	assert.Equal(t, 5, config.AccessTokenTTLMinutes)
+	assert.Equal(t, RefreshTokenTTLDays, config.RefreshTokenTTLDays)
+	assert.Equal(t, 600, config.TokenCleanupIntervalSeconds)
+}
+
+func TestLoad_ServerTimeouts(t *testing.T) {
+	os.Setenv("HTTP_WRITE_TIMEOUT_SECONDS", "45")
+	os.Setenv("SHUTDOWN_TIMEOUT_SECONDS", "-1")
+	os.Setenv("DB_QUERY_TIMEOUT_SECONDS", "2")
+	defer func() {
+		os.Unsetenv("HTTP_WRITE_TIMEOUT_SECONDS")
+		os.Unsetenv("SHUTDOWN_TIMEOUT_SECONDS")
+		os.Unsetenv("DB_QUERY_TIMEOUT_SECONDS")
+	}()
+
+	config := Load()
+
+	assert.Equal(t, HTTPReadTimeoutSeconds, config.HTTPReadTimeoutSeconds)
+	assert.Equal(t, 45, config.HTTPWriteTimeoutSeconds)
+	assert.Equal(t, HTTPIdleTimeoutSeconds, config.HTTPIdleTimeoutSeconds)
+	assert.Equal(t, ShutdownTimeoutSeconds, config.ShutdownTimeoutSeconds)
+	assert.Equal(t, 2, config.DBQueryTimeoutSeconds)
+}
+
+func TestLoad_ZeroDisablesOptionalLimits(t *testing.T) {
+	os.Setenv("DB_QUERY_TIMEOUT_SECONDS", "0")
+	os.Setenv("EBIKE_MIN_BATTERY_PERCENT", "0")
+	os.Setenv("HTTP_READ_TIMEOUT_SECONDS", "0")
+	defer func() {
+		os.Unsetenv("DB_QUERY_TIMEOUT_SECONDS")
+		os.Unsetenv("EBIKE_MIN_BATTERY_PERCENT")
+		os.Unsetenv("HTTP_READ_TIMEOUT_SECONDS")
+	}()
+
+	config := Load()
+
+	assert.Equal(t, 0, config.DBQueryTimeoutSeconds)
+	assert.Equal(t, 0, config.EBikeMinBatteryPercent)
+	assert.Equal(t, HTTPReadTimeoutSeconds, config.HTTPReadTimeoutSeconds)
+
+	os.Setenv("EBIKE_MIN_BATTERY_PERCENT", "-5")
+	assert.Equal(t, EBikeMinBatteryPercent, Load().EBikeMinBatteryPercent)
+}
+
+func TestLoad_AdminListener(t *testing.T) {
+	os.Unsetenv("ADMIN_HTTP_PORT")
+	os.Unsetenv("ADMIN_BIND_ADDR")
+
+	config := Load()
+	assert.False(t, config.AdminListenerEnabled())
+	assert.Equal(t, AdminBindAddr, config.AdminBindAddr)
+
+	os.Setenv("ADMIN_HTTP_PORT", "9090")
+	os.Setenv("ADMIN_BIND_ADDR", "10.0.0.5")
+	defer func() {
+		os.Unsetenv("ADMIN_HTTP_PORT")
+		os.Unsetenv("ADMIN_BIND_ADDR")
+	}()
+
+	config = Load()
+	assert.True(t, config.AdminListenerEnabled())
+	assert.Equal(t, "9090", config.AdminPort)
+	assert.Equal(t, "10.0.0.5", config.AdminBindAddr)
+}
+
+func TestGetEnvIntDefault(t *testing.T) {
+	os.Setenv("TEST_INT_VAR", "-3")
+	defer os.Unsetenv("TEST_INT_VAR")
+
+	assert.Equal(t, 7, getEnvIntDefault("TEST_INT_VAR", 7))
+
+	os.Setenv("TEST_INT_VAR", "42")
+	assert.Equal(t, 42, getEnvIntDefault("TEST_INT_VAR", 7))
+}
+
+func TestGetEnvBoolDefault(t *testing.T) {
+	os.Setenv("TEST_BOOL_VAR", "maybe")
+	defer os.Unsetenv("TEST_BOOL_VAR")
+
+	assert.True(t, getEnvBoolDefault("TEST_BOOL_VAR", true))
+
+	os.Setenv("TEST_BOOL_VAR", "false")
+	assert.False(t, getEnvBoolDefault("TEST_BOOL_VAR", true))
+}
+
+func TestGetEnvFloatDefault(t *testing.T) {
+	os.Setenv("TEST_FLOAT_VAR", "-0.5")
+	defer os.Unsetenv("TEST_FLOAT_VAR")
+
+	assert.Equal(t, 0.1, getEnvFloatDefault("TEST_FLOAT_VAR", 0.1))
+
+	os.Setenv("TEST_FLOAT_VAR", "0")
+	assert.Equal(t, 0.0, getEnvFloatDefault("TEST_FLOAT_VAR", 0.1))
+
+	os.Setenv("TEST_FLOAT_VAR", "0.25")
+	assert.Equal(t, 0.25, getEnvFloatDefault("TEST_FLOAT_VAR", 0.1))
+}
+
+func TestLoad_MailSettings(t *testing.T) {
+	os.Setenv("MAILER", "smtp")
+	os.Setenv("SMTP_PORT", "2525")
+	os.Unsetenv("PASSWORD_RESET_TTL_MINUTES")
+	defer func() {
+		os.Unsetenv("MAILER")
+		os.Unsetenv("SMTP_PORT")
+	}()
+
+	config := Load()
+
+	assert.Equal(t, "smtp", config.MailerDriver)
+	assert.Equal(t, 2525, config.SMTPPort)
+	assert.Equal(t, PasswordResetTTLMinutes, config.PasswordResetTTLMinutes)
+}
+
+func TestConfig_DataSource(t *testing.T) {
+	config := Config{DBDriver: "sqlite", SQLitePath: "data/test.db", DatabaseURL: "postgres://db/app"}
+	assert.Equal(t, "data/test.db", config.DataSource())
+
+	config.DBDriver = "postgres"
+	assert.Equal(t, "postgres://db/app", config.DataSource())
+}
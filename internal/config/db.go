@@ -8,4 +8,4 @@ func LoadDBConfig() DBConfig {
 	return DBConfig{
 		SQLitePath: getEnvDefault("SQLITE_PATH", SQLitePath),
 	}
-}
\ No newline at end of file
+}
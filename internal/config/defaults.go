@@ -1,7 +1,48 @@
 package config
 
 const (
-	HTTPPort   = "8080"
-	SQLitePath = "data/bike_rental.db"
-	LogLevel   = "info"
+	HTTPPort    = "8080"
+	DBDriver    = "sqlite"
+	SQLitePath  = "data/bike_rental.db"
+	AutoMigrate = true
+	LogLevel    = "info"
+
+	AdminBindAddr = "127.0.0.1"
+
+	TracingExporter     = "none"
+	TracingOTLPEndpoint = "http://localhost:4318"
+
+	HTTPReadTimeoutSeconds  = 15
+	HTTPWriteTimeoutSeconds = 30
+	HTTPIdleTimeoutSeconds  = 60
+	ShutdownTimeoutSeconds  = 30
+	DBQueryTimeoutSeconds   = 5
+
+	ReservationMinutes               = 10
+	ReservationExpiryIntervalSeconds = 30
+
+	PausedPricePerMinute = 0.10
+
+	StationReturnPolicy = "free_floating"
+	OutOfStationFee     = 2.00
+
+	NoParkingPolicy = "reject"
+	NoParkingFine   = 5.00
+
+	ReturnDistanceMode  = "from_start"
+	ReturnMaxDistanceKm = 5.0
+
+	EBikeMinBatteryPercent = 20
+
+	AccessTokenTTLMinutes       = 15
+	RefreshTokenTTLDays         = 30
+	TokenCleanupIntervalSeconds = 3600
+
+	AppBaseURL                = "http://localhost:8080"
+	MailerDriver              = "log"
+	SMTPPort                  = 587
+	MailFrom                  = "no-reply@bikerental.local"
+	PasswordResetTTLMinutes   = 60
+	EmailVerificationTTLHours = 48
+	OutboxIntervalSeconds     = 10
 )
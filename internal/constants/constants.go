@@ -1,6 +1,10 @@
 package constants
 
-import "errors"
+import (
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/apperrors"
+)
 
 // Geographical boundaries for bike locations
 const (
@@ -14,17 +18,114 @@ const (
 	MaxLimit     = 100
 )
 
+// Nearby bike search
+const (
+	DefaultRadiusKm = 1.0
+	MaxRadiusKm     = 50.0
+)
+
+// Stations. A station's area must lie within MaxStationRadiusMeters of its
+// anchor point, so stations near a point can be found with a bounding box.
+const (
+	MaxStationRadiusMeters = 1000.0
+	MinStationPolygonSize  = 3
+)
+
+// Bikes. New bikes cost DefaultPricePerMinute when neither the request nor
+// their type sets a price. An e-bike's range is estimated linearly from its
+// battery level, EBikeFullChargeRangeKm being the range on a full charge.
+const (
+	DefaultPricePerMinute  = 0.5
+	EBikeFullChargeRangeKm = 60.0
+)
+
+// Trip tracking. A request may carry up to MaxTrackPoints locations, and
+// their timestamps may run up to MaxTrackClockSkew ahead of the server clock.
+const (
+	MaxTrackPoints    = 500
+	MaxTrackClockSkew = time.Minute
+)
+
 // User Service Errors
 var (
-	ErrEmailAlreadyExists = errors.New("email already registered")
-	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrEmailAlreadyExists   = apperrors.Conflict("email_already_registered", "email already registered")
+	ErrInvalidCredentials   = apperrors.Unauthorized("invalid_credentials", "invalid credentials")
+	ErrEmailAlreadyVerified = apperrors.Conflict("email_already_verified", "email is already verified")
+	ErrUserNotFound         = apperrors.NotFound("user_not_found", "user not found")
+)
+
+// Authentication Errors
+var (
+	ErrMissingToken     = apperrors.Unauthorized("missing_token", "authorization header is required")
+	ErrInvalidToken     = apperrors.Unauthorized("invalid_token", "invalid or expired token")
+	ErrTokenRevoked     = apperrors.Unauthorized("token_revoked", "token has been revoked")
+	ErrPermissionDenied = apperrors.Forbidden("permission_denied", "admin role lacks the required permission")
+)
+
+// Token Errors
+var (
+	ErrInvalidRefreshToken = apperrors.Unauthorized("invalid_refresh_token", "invalid or expired refresh token")
+	ErrRefreshTokenReused  = apperrors.Unauthorized("refresh_token_reused", "refresh token has already been used; the session has been revoked")
+	ErrInvalidActionToken  = apperrors.Validation("invalid_action_token", "invalid, expired or already used token")
 )
 
 // Rental Service Errors
 var (
-	ErrBikeNotAvailable    = errors.New("bike is not available for rental")
-	ErrUserHasActiveRental = errors.New("user already has an active rental")
-	ErrBikeNotFound        = errors.New("bike not found")
-	ErrNoActiveRental      = errors.New("you don't have an active rental")
-	ErrEndLocationTooFar   = errors.New("end location must be within 5km of start location")
+	ErrBikeNotAvailable    = apperrors.Conflict("bike_not_available", "bike is not available for rental")
+	ErrUserHasActiveRental = apperrors.Conflict("active_rental_exists", "user already has an active rental")
+	ErrBikeNotFound        = apperrors.NotFound("bike_not_found", "bike not found")
+	ErrBatteryNotSupported = apperrors.Validation("battery_not_supported", "only e-bikes have a battery level")
+	ErrNoActiveRental      = apperrors.Conflict("no_active_rental", "you don't have an active rental")
+	ErrEndLocationTooFar   = apperrors.Validation("end_location_too_far", "end location is too far to return the bike")
+	ErrRentalNotRunning    = apperrors.Conflict("rental_not_running", "rental is not running")
+	ErrRentalNotPaused     = apperrors.Conflict("rental_not_paused", "rental is not paused")
+	ErrRentalNotFound      = apperrors.NotFound("rental_not_found", "rental not found")
+	ErrTrackPointTooEarly  = apperrors.Validation("track_point_too_early", "locations must not be recorded before the rental started")
+
+	ErrInvalidRentalTransition = apperrors.Unprocessable("invalid_rental_transition", "invalid rental status transition")
+)
+
+// Reservation Errors
+var (
+	ErrBikeReserved             = apperrors.Conflict("bike_reserved", "bike is reserved by another user")
+	ErrUserHasActiveReservation = apperrors.Conflict("active_reservation_exists", "user already has an active reservation")
+	ErrNoActiveReservation      = apperrors.Conflict("no_active_reservation", "you don't have an active reservation")
+	ErrReservationNotFound      = apperrors.NotFound("reservation_not_found", "reservation not found")
+)
+
+// Pricing Errors
+var (
+	ErrPricePlanNotFound = apperrors.NotFound("price_plan_not_found", "price plan not found")
+	ErrPricePlanInUse    = apperrors.Conflict("price_plan_in_use", "price plan is assigned to one or more bikes")
+	// ErrUnknownPricePlan is returned when a bike refers to a plan that does
+	// not exist, which is a fault in the request rather than a missing route
+	// resource.
+	ErrUnknownPricePlan = apperrors.Validation("unknown_price_plan", "price plan does not exist")
+	// ErrUnknownZoneStation is returned when a zone rate names a station that
+	// does not exist.
+	ErrUnknownZoneStation = apperrors.Validation("unknown_zone_station", "zone rate station does not exist")
+	ErrDuplicateZoneRate  = apperrors.Validation("duplicate_zone_rate", "a plan can have one zone rate per station and apply_to")
+)
+
+// Station Errors
+var (
+	ErrStationNotFound      = apperrors.NotFound("station_not_found", "station not found")
+	ErrStationNameTaken     = apperrors.Conflict("station_name_taken", "a station with this name already exists")
+	ErrStationInUse         = apperrors.Conflict("station_in_use", "station has bikes parked at it")
+	ErrStationFull          = apperrors.Conflict("station_full", "station has no free docks")
+	ErrStationHasZoneRates  = apperrors.Conflict("station_has_zone_rates", "station is used by price plan zone rates")
+	ErrReturnOutsideStation = apperrors.Validation("return_outside_station", "rentals must end inside a station")
+)
+
+// Geofence Errors
+var (
+	ErrOutsideOperatingArea = apperrors.Validation("outside_operating_area", "location is outside the operating area")
+	ErrNoParkingZone        = apperrors.Validation("no_parking_zone", "location is inside a no-parking zone")
+)
+
+// Admin Account Errors
+var (
+	ErrAdminNotFound  = apperrors.NotFound("admin_not_found", "admin not found")
+	ErrLastSuperadmin = apperrors.Conflict("last_superadmin", "at least one enabled superadmin must remain")
+	ErrAdminDisabled  = apperrors.Unauthorized("admin_disabled", "admin account is disabled")
 )
@@ -0,0 +1,213 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+)
+
+// DB is a connection pool bound to a SQL dialect. Exec, Query and QueryRow
+// and their Context variants rebind ? placeholders before running the query
+// and record an OpenTelemetry span for it.
+//
+// QueryTimeout bounds each repository call made through the pool or through
+// a transaction begun on it; zero means no limit beyond the caller's context.
+type DB struct {
+	*sql.DB
+	Dialect      Dialect
+	QueryTimeout time.Duration
+}
+
+// NewDB wraps an open pool. It is mostly useful in tests, where the pool
+// comes from sqlmock.
+func NewDB(db *sql.DB, dialect Dialect) *DB {
+	return &DB{DB: db, Dialect: dialect}
+}
+
+// Connect opens the database for driver. dsn is the file path for SQLite and
+// a connection URL for Postgres. With autoMigrate set, pending migrations are
+// applied before it returns.
+func Connect(driver, dsn string, autoMigrate bool) (*DB, error) {
+	dialect, err := DialectFor(driver)
+	if err != nil {
+		return nil, err
+	}
+
+	var database *sql.DB
+	if dialect == SQLite {
+		database, err = openSQLite(dsn)
+	} else {
+		database, err = openPostgres(dsn)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := database.Ping(); err != nil {
+		database.Close()
+		return nil, fmt.Errorf("error pinging database: %v", err)
+	}
+
+	db := NewDB(database, dialect)
+	if autoMigrate {
+		if _, err := MigrateUp(db); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	return db, nil
+}
+
+func openSQLite(dbPath string) (*sql.DB, error) {
+	dir := filepath.Dir(dbPath)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("failed to create directory for SQLite database: %v", err)
+	}
+
+	// busy_timeout lets concurrent writers wait for the lock instead of failing,
+	// and _txlock=immediate takes the write lock at BEGIN so transactions that
+	// read before writing cannot deadlock each other.
+	database, err := sql.Open("sqlite", dbPath+"?parseTime=true&_pragma=busy_timeout(10000)&_txlock=immediate")
+	if err != nil {
+		return nil, fmt.Errorf("error while establishing new connection to DB: %v", err)
+	}
+
+	return database, nil
+}
+
+func openPostgres(dsn string) (*sql.DB, error) {
+	if dsn == "" {
+		return nil, fmt.Errorf("DATABASE_URL is required for the postgres driver")
+	}
+
+	database, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error while establishing new connection to DB: %v", err)
+	}
+
+	return database, nil
+}
+
+func (db *DB) Close() error {
+	if err := db.DB.Close(); err != nil {
+		return fmt.Errorf("error while closing the DB connection: %v", err)
+	}
+	return nil
+}
+
+func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return db.ExecContext(context.Background(), query, args...)
+}
+
+func (db *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return db.QueryContext(context.Background(), query, args...)
+}
+
+func (db *DB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return db.QueryRowContext(context.Background(), query, args...)
+}
+
+func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	query = db.Dialect.Rebind(query)
+	ctx, span := startQuerySpan(ctx, db.Dialect, query)
+	result, err := db.DB.ExecContext(ctx, query, args...)
+	endQuerySpan(span, err)
+	return result, err
+}
+
+func (db *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	query = db.Dialect.Rebind(query)
+	ctx, span := startQuerySpan(ctx, db.Dialect, query)
+	rows, err := db.DB.QueryContext(ctx, query, args...)
+	endQuerySpan(span, err)
+	return rows, err
+}
+
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	query = db.Dialect.Rebind(query)
+	ctx, span := startQuerySpan(ctx, db.Dialect, query)
+	row := db.DB.QueryRowContext(ctx, query, args...)
+	endQuerySpan(span, row.Err())
+	return row
+}
+
+func (db *DB) Begin() (*Tx, error) {
+	return db.BeginTx(context.Background(), nil)
+}
+
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := db.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx, Dialect: db.Dialect, QueryTimeout: db.QueryTimeout}, nil
+}
+
+// WithQueryTimeout derives a context that expires after QueryTimeout. The
+// returned cancel function must be called once the query's rows have been
+// read, not as soon as the query returns.
+func (db *DB) WithQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, db.QueryTimeout)
+}
+
+// Tx is a transaction that rebinds and traces queries like DB.
+type Tx struct {
+	*sql.Tx
+	Dialect      Dialect
+	QueryTimeout time.Duration
+}
+
+// WithQueryTimeout is the transaction counterpart of DB.WithQueryTimeout.
+func (tx *Tx) WithQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, tx.QueryTimeout)
+}
+
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+func (tx *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return tx.ExecContext(context.Background(), query, args...)
+}
+
+func (tx *Tx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return tx.QueryContext(context.Background(), query, args...)
+}
+
+func (tx *Tx) QueryRow(query string, args ...interface{}) *sql.Row {
+	return tx.QueryRowContext(context.Background(), query, args...)
+}
+
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	query = tx.Dialect.Rebind(query)
+	ctx, span := startQuerySpan(ctx, tx.Dialect, query)
+	result, err := tx.Tx.ExecContext(ctx, query, args...)
+	endQuerySpan(span, err)
+	return result, err
+}
+
+func (tx *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	query = tx.Dialect.Rebind(query)
+	ctx, span := startQuerySpan(ctx, tx.Dialect, query)
+	rows, err := tx.Tx.QueryContext(ctx, query, args...)
+	endQuerySpan(span, err)
+	return rows, err
+}
+
+func (tx *Tx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	query = tx.Dialect.Rebind(query)
+	ctx, span := startQuerySpan(ctx, tx.Dialect, query)
+	row := tx.Tx.QueryRowContext(ctx, query, args...)
+	endQuerySpan(span, row.Err())
+	return row
+}
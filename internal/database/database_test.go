@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// slowQuery counts to a hundred million, which takes SQLite well over a second.
+const slowQuery = "WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c WHERE x < 100000000) SELECT COUNT(*) FROM c"
+
+func TestWithQueryTimeout(t *testing.T) {
+	db, err := Connect(DriverSQLite, filepath.Join(t.TempDir(), "test.db"), false)
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	defer db.Close()
+
+	t.Run("Zero timeout leaves the context untouched", func(t *testing.T) {
+		ctx, cancel := db.WithQueryTimeout(t.Context())
+		defer cancel()
+
+		_, hasDeadline := ctx.Deadline()
+		assert.False(t, hasDeadline)
+	})
+
+	db.QueryTimeout = 20 * time.Millisecond
+
+	t.Run("Slow query on the pool reports a deadline error", func(t *testing.T) {
+		ctx, cancel := db.WithQueryTimeout(t.Context())
+		defer cancel()
+
+		var count int
+		err := db.QueryRowContext(ctx, slowQuery).Scan(&count)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("Transactions inherit the timeout", func(t *testing.T) {
+		tx, err := db.BeginTx(t.Context(), nil)
+		if err != nil {
+			t.Fatalf("failed to begin transaction: %v", err)
+		}
+		defer tx.Rollback()
+
+		ctx, cancel := tx.WithQueryTimeout(t.Context())
+		defer cancel()
+
+		var count int
+		err = tx.QueryRowContext(ctx, slowQuery).Scan(&count)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// Dialect covers the SQL differences between the supported backends.
+// Queries are written once with ? placeholders and rebound for the dialect
+// when they run. New rows are read back with INSERT ... RETURNING, which both
+// backends support.
+type Dialect interface {
+	// Name is the DB_DRIVER value that selects the dialect and the directory
+	// its migrations live in.
+	Name() string
+	// Rebind rewrites ? placeholders into the dialect's bind parameters.
+	Rebind(query string) string
+}
+
+var (
+	SQLite   Dialect = sqliteDialect{}
+	Postgres Dialect = postgresDialect{}
+)
+
+// DialectFor returns the dialect for a DB_DRIVER value.
+func DialectFor(driver string) (Dialect, error) {
+	switch driver {
+	case DriverSQLite:
+		return SQLite, nil
+	case DriverPostgres:
+		return Postgres, nil
+	default:
+		return nil, errors.New("unsupported database driver " + strconv.Quote(driver))
+	}
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string { return DriverSQLite }
+
+func (sqliteDialect) Rebind(query string) string { return query }
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string { return DriverPostgres }
+
+// Rebind numbers the placeholders as $1, $2, ... Question marks inside quoted
+// string literals are left alone.
+func (postgresDialect) Rebind(query string) string {
+	if !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+
+	n := 0
+	inString := false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'':
+			inString = !inString
+			b.WriteByte(c)
+		case c == '?' && !inString:
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
+
+// IsUniqueViolation reports whether err was caused by a unique constraint on
+// either backend.
+func IsUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "23505"
+	}
+
+	return strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
@@ -0,0 +1,38 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialectFor(t *testing.T) {
+	dialect, err := DialectFor("sqlite")
+	assert.NoError(t, err)
+	assert.Equal(t, SQLite, dialect)
+
+	dialect, err = DialectFor("postgres")
+	assert.NoError(t, err)
+	assert.Equal(t, Postgres, dialect)
+
+	_, err = DialectFor("mysql")
+	assert.Error(t, err)
+}
+
+func TestRebind(t *testing.T) {
+	query := "UPDATE bikes SET is_available = ? WHERE id = ? AND status IN ('a?', 'b')"
+
+	assert.Equal(t, query, SQLite.Rebind(query))
+	assert.Equal(t, "UPDATE bikes SET is_available = $1 WHERE id = $2 AND status IN ('a?', 'b')", Postgres.Rebind(query))
+	assert.Equal(t, "SELECT 1", Postgres.Rebind("SELECT 1"))
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	assert.False(t, IsUniqueViolation(nil))
+	assert.True(t, IsUniqueViolation(errors.New("constraint failed: UNIQUE constraint failed: users.email (2067)")))
+	assert.True(t, IsUniqueViolation(&pgconn.PgError{Code: "23505"}))
+	assert.False(t, IsUniqueViolation(&pgconn.PgError{Code: "23503"}))
+	assert.False(t, IsUniqueViolation(errors.New("connection refused")))
+}
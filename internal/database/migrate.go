@@ -0,0 +1,295 @@
+package database
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+//go:embed seed.sql
+var seedSQL string
+
+const createMigrationsTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+)`
+
+// Migration is one schema change. Each dialect has its own directory under
+// migrations/ with files named <version>_<name>.up.sql and
+// <version>_<name>.down.sql, and both directories carry the same versions.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationState reports whether a migration has been applied. AppliedAt is
+// nil for pending migrations.
+type MigrationState struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+// LoadMigrations reads the dialect's embedded migrations sorted by version.
+func LoadMigrations(dialect Dialect) ([]Migration, error) {
+	dir := "migrations/" + dialect.Name()
+
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading migrations: %v", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		fileName := entry.Name()
+
+		var direction string
+		switch {
+		case strings.HasSuffix(fileName, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(fileName, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("migration %s must end in .up.sql or .down.sql", fileName)
+		}
+
+		base := strings.TrimSuffix(fileName, "."+direction+".sql")
+		versionPart, name, found := strings.Cut(base, "_")
+		version, err := strconv.Atoi(versionPart)
+		if !found || err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s must be named <version>_<name>", fileName)
+		}
+
+		content, err := migrationFiles.ReadFile(dir + "/" + fileName)
+		if err != nil {
+			return nil, fmt.Errorf("error reading migration %s: %v", fileName, err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		}
+		if migration.Name != name {
+			return nil, fmt.Errorf("migration version %d is used by %s and %s", version, migration.Name, name)
+		}
+
+		if direction == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// baselineColumns are the columns migration 0001 has that the original
+// schema, from before versioned migrations, lacks. Its CREATE TABLE IF NOT
+// EXISTS statements skip tables that already exist, so databases created with
+// that schema, such as data/bike_rental.db, get them added first.
+var baselineColumns = []struct {
+	table, column, definition string
+}{
+	{"users", "email_verified_at", "DATETIME"},
+	{"bikes", "price_plan_id", "INTEGER REFERENCES price_plans(id)"},
+	{"rentals", "cost_breakdown", "TEXT"},
+}
+
+// MigrateUp applies every pending migration in version order, each in its own
+// transaction, and returns how many were applied.
+func MigrateUp(db *DB) (int, error) {
+	migrations, applied, err := loadState(db)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		err := withTx(db, func(tx *Tx) error {
+			if migration.Version == 1 && db.Dialect == SQLite {
+				if err := upgradeBaseline(tx); err != nil {
+					return err
+				}
+			}
+			if _, err := tx.Exec(migration.Up); err != nil {
+				return err
+			}
+			_, err := tx.Exec("INSERT INTO schema_migrations (version, name) VALUES (?, ?)", migration.Version, migration.Name)
+			return err
+		})
+		if err != nil {
+			return count, fmt.Errorf("error applying migration %d_%s: %v", migration.Version, migration.Name, err)
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// MigrateDown reverts the latest steps applied migrations, newest first, and
+// returns how many were reverted.
+func MigrateDown(db *DB, steps int) (int, error) {
+	migrations, applied, err := loadState(db)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := len(migrations) - 1; i >= 0 && count < steps; i-- {
+		migration := migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		if migration.Down == "" {
+			return count, fmt.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+		}
+
+		err := withTx(db, func(tx *Tx) error {
+			if _, err := tx.Exec(migration.Down); err != nil {
+				return err
+			}
+			_, err := tx.Exec("DELETE FROM schema_migrations WHERE version = ?", migration.Version)
+			return err
+		})
+		if err != nil {
+			return count, fmt.Errorf("error reverting migration %d_%s: %v", migration.Version, migration.Name, err)
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// MigrationStatus lists every known migration and when it was applied.
+func MigrationStatus(db *DB) ([]MigrationState, error) {
+	migrations, applied, err := loadState(db)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]MigrationState, 0, len(migrations))
+	for _, migration := range migrations {
+		state := MigrationState{Version: migration.Version, Name: migration.Name}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			state.AppliedAt = &appliedAt
+		}
+		states = append(states, state)
+	}
+
+	return states, nil
+}
+
+// Seed loads the demo bikes. It does nothing when the bikes table already has
+// rows, so it is safe to run more than once.
+func Seed(db *DB) (bool, error) {
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM bikes").Scan(&count); err != nil {
+		return false, fmt.Errorf("error checking seed data: %v", err)
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	if err := withTx(db, func(tx *Tx) error {
+		_, err := tx.Exec(seedSQL)
+		return err
+	}); err != nil {
+		return false, fmt.Errorf("error seeding database: %v", err)
+	}
+
+	return true, nil
+}
+
+// loadState reads the embedded migrations and the versions already applied,
+// creating the schema_migrations table on first use.
+func loadState(db *DB) ([]Migration, map[int]time.Time, error) {
+	migrations, err := LoadMigrations(db.Dialect)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := db.Exec(createMigrationsTable); err != nil {
+		return nil, nil, fmt.Errorf("error creating schema_migrations table: %v", err)
+	}
+
+	rows, err := db.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading schema_migrations: %v", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, nil, fmt.Errorf("error reading schema_migrations: %v", err)
+		}
+		applied[version] = appliedAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error reading schema_migrations: %v", err)
+	}
+
+	return migrations, applied, nil
+}
+
+// upgradeBaseline adds the baselineColumns missing from existing tables so
+// migration 0001 can run over a SQLite database created before migrations.
+// Tables that do not exist are left for the migration to create.
+func upgradeBaseline(tx *Tx) error {
+	for _, baseline := range baselineColumns {
+		var columns, matching int
+		err := tx.QueryRow(
+			"SELECT COUNT(*), COUNT(CASE WHEN name = ? THEN 1 END) FROM pragma_table_info(?)",
+			baseline.column, baseline.table,
+		).Scan(&columns, &matching)
+		if err != nil {
+			return fmt.Errorf("error inspecting table %s: %v", baseline.table, err)
+		}
+		if columns == 0 || matching > 0 {
+			continue
+		}
+
+		if _, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", baseline.table, baseline.column, baseline.definition)); err != nil {
+			return fmt.Errorf("error adding %s.%s: %v", baseline.table, baseline.column, err)
+		}
+	}
+	return nil
+}
+
+func withTx(db *DB, fn func(tx *Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
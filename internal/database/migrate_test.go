@@ -0,0 +1,179 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	sqliteMigrations, err := LoadMigrations(SQLite)
+	assert.NoError(t, err)
+	if len(sqliteMigrations) == 0 {
+		t.Fatal("no embedded migrations")
+	}
+
+	postgresMigrations, err := LoadMigrations(Postgres)
+	assert.NoError(t, err)
+	assert.Len(t, postgresMigrations, len(sqliteMigrations), "every migration needs a version for each dialect")
+
+	for i, migration := range sqliteMigrations {
+		assert.NotEmpty(t, migration.Up, "migration %d has no up script", migration.Version)
+		assert.NotEmpty(t, migration.Down, "migration %d has no down script", migration.Version)
+		if i > 0 {
+			assert.Greater(t, migration.Version, sqliteMigrations[i-1].Version)
+		}
+		if i < len(postgresMigrations) {
+			assert.Equal(t, migration.Version, postgresMigrations[i].Version)
+			assert.Equal(t, migration.Name, postgresMigrations[i].Name)
+			assert.NotEmpty(t, postgresMigrations[i].Down, "postgres migration %d has no down script", migration.Version)
+		}
+	}
+}
+
+func TestMigrateUpDown(t *testing.T) {
+	db, err := Connect(DriverSQLite, filepath.Join(t.TempDir(), "test.db"), false)
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	defer db.Close()
+
+	migrations, err := LoadMigrations(SQLite)
+	assert.NoError(t, err)
+
+	applied, err := MigrateUp(db)
+	assert.NoError(t, err)
+	assert.Equal(t, len(migrations), applied)
+
+	applied, err = MigrateUp(db)
+	assert.NoError(t, err)
+	assert.Zero(t, applied, "a second run should find nothing pending")
+
+	states, err := MigrationStatus(db)
+	assert.NoError(t, err)
+	for _, state := range states {
+		assert.NotNil(t, state.AppliedAt)
+	}
+
+	reverted, err := MigrateDown(db, len(migrations))
+	assert.NoError(t, err)
+	assert.Equal(t, len(migrations), reverted)
+
+	var tables int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name NOT IN ('schema_migrations', 'sqlite_sequence')").Scan(&tables))
+	assert.Zero(t, tables, "down migrations should drop every table")
+
+	states, err = MigrationStatus(db)
+	assert.NoError(t, err)
+	for _, state := range states {
+		assert.Nil(t, state.AppliedAt)
+	}
+}
+
+func TestSeed_OnlyIntoEmptyDatabase(t *testing.T) {
+	db, err := Connect(DriverSQLite, filepath.Join(t.TempDir(), "test.db"), true)
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	defer db.Close()
+
+	seeded, err := Seed(db)
+	assert.NoError(t, err)
+	assert.True(t, seeded)
+
+	var bikes int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM bikes").Scan(&bikes))
+	assert.Positive(t, bikes)
+
+	seeded, err = Seed(db)
+	assert.NoError(t, err)
+	assert.False(t, seeded)
+
+	var after int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM bikes").Scan(&after))
+	assert.Equal(t, bikes, after)
+}
+
+// baselineSchema is the schema data/bike_rental.db was created with, before
+// versioned migrations.
+const baselineSchema = `
+CREATE TABLE users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    email TEXT NOT NULL UNIQUE,
+    hashed_password TEXT NOT NULL,
+    first_name TEXT NOT NULL,
+    last_name TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE bikes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    is_available INTEGER NOT NULL DEFAULT 1,
+    latitude REAL NOT NULL,
+    longitude REAL NOT NULL,
+    price_per_minute REAL NOT NULL DEFAULT 0.5,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE rentals (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    bike_id INTEGER NOT NULL,
+    status TEXT NOT NULL,
+    start_time DATETIME NOT NULL,
+    end_time DATETIME,
+    start_latitude REAL NOT NULL,
+    start_longitude REAL NOT NULL,
+    end_latitude REAL,
+    end_longitude REAL,
+    duration_minutes INTEGER,
+    cost REAL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (bike_id) REFERENCES bikes(id)
+);
+CREATE INDEX idx_users_email ON users(email);
+CREATE INDEX idx_bikes_available ON bikes(is_available);
+CREATE INDEX idx_rentals_user ON rentals(user_id);
+CREATE INDEX idx_rentals_bike ON rentals(bike_id);
+CREATE INDEX idx_rentals_status ON rentals(status);
+INSERT INTO users (email, hashed_password, first_name, last_name) VALUES ('ana@example.com', 'hash', 'Ana', 'García');
+INSERT INTO bikes (latitude, longitude, price_per_minute) VALUES (51.5074, -0.1278, 0.65);
+INSERT INTO rentals (user_id, bike_id, status, start_time, start_latitude, start_longitude) VALUES (1, 1, 'ended', '2024-01-15 10:00:00', 51.5074, -0.1278);
+`
+
+func TestMigrateUp_BaselineSchema(t *testing.T) {
+	db, err := Connect(DriverSQLite, filepath.Join(t.TempDir(), "test.db"), false)
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(baselineSchema)
+	assert.NoError(t, err)
+
+	migrations, err := LoadMigrations(SQLite)
+	assert.NoError(t, err)
+
+	applied, err := MigrateUp(db)
+	assert.NoError(t, err)
+	assert.Equal(t, len(migrations), applied)
+
+	var pricePerMinute float64
+	var pricePlanID *int
+	var bikeType string
+	assert.NoError(t, db.QueryRow("SELECT price_per_minute, price_plan_id, type FROM bikes WHERE id = 1").Scan(&pricePerMinute, &pricePlanID, &bikeType))
+	assert.Equal(t, 0.65, pricePerMinute, "existing rows survive the upgrade")
+	assert.Nil(t, pricePlanID)
+	assert.Equal(t, "classic", bikeType)
+
+	var verifiedAt *string
+	assert.NoError(t, db.QueryRow("SELECT email_verified_at FROM users WHERE id = 1").Scan(&verifiedAt))
+	assert.Nil(t, verifiedAt)
+
+	var breakdown *string
+	assert.NoError(t, db.QueryRow("SELECT cost_breakdown FROM rentals WHERE id = 1").Scan(&breakdown))
+	assert.Nil(t, breakdown)
+}
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS email_outbox;
+DROP TABLE IF EXISTS user_tokens;
+DROP TABLE IF EXISTS revoked_tokens;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS reservations;
+DROP TABLE IF EXISTS rental_segments;
+DROP TABLE IF EXISTS rentals;
+DROP TABLE IF EXISTS bikes;
+DROP TABLE IF EXISTS price_plans;
+DROP TABLE IF EXISTS admins;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS stations (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    radius_meters DOUBLE PRECISION,
+    polygon TEXT,
+    capacity INTEGER NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE bikes ADD COLUMN IF NOT EXISTS station_id INTEGER REFERENCES stations(id);
+
+CREATE INDEX IF NOT EXISTS idx_bikes_station ON bikes(station_id);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS geofences (
+    id SERIAL PRIMARY KEY,
+    kind TEXT NOT NULL CHECK (kind IN ('operating_area', 'no_parking', 'slow')),
+    name TEXT NOT NULL DEFAULT '',
+    rings TEXT NOT NULL,
+    speed_limit_kmh DOUBLE PRECISION,
+    min_latitude DOUBLE PRECISION NOT NULL,
+    max_latitude DOUBLE PRECISION NOT NULL,
+    min_longitude DOUBLE PRECISION NOT NULL,
+    max_longitude DOUBLE PRECISION NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_geofences_kind ON geofences(kind);
+CREATE INDEX IF NOT EXISTS idx_geofences_bounds ON geofences(min_latitude, max_latitude);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS return_distance_rule (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    mode TEXT NOT NULL CHECK (mode IN ('none', 'from_start', 'from_station')),
+    max_distance_km DOUBLE PRECISION NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
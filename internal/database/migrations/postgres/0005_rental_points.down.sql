@@ -0,0 +1,3 @@
+ALTER TABLE rentals DROP COLUMN distance_km;
+DROP INDEX IF EXISTS idx_rental_points_rental;
+DROP TABLE IF EXISTS rental_points;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS rental_points (
+    id SERIAL PRIMARY KEY,
+    rental_id INTEGER NOT NULL,
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    recorded_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (rental_id) REFERENCES rentals(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rental_points_rental ON rental_points(rental_id, recorded_at);
+
+ALTER TABLE rentals ADD COLUMN distance_km DOUBLE PRECISION;
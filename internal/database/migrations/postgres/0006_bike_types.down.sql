@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS bike_type_prices;
+DROP INDEX IF EXISTS idx_bikes_type;
+ALTER TABLE bikes DROP COLUMN battery_level;
+ALTER TABLE bikes DROP COLUMN type;
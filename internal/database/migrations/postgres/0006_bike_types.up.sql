@@ -0,0 +1,12 @@
+ALTER TABLE bikes ADD COLUMN type TEXT NOT NULL DEFAULT 'classic' CHECK (type IN ('classic', 'ebike', 'cargo'));
+ALTER TABLE bikes ADD COLUMN battery_level INTEGER CHECK (battery_level BETWEEN 0 AND 100);
+
+CREATE INDEX IF NOT EXISTS idx_bikes_type ON bikes(type);
+
+CREATE TABLE IF NOT EXISTS bike_type_prices (
+    type TEXT PRIMARY KEY CHECK (type IN ('classic', 'ebike', 'cargo')),
+    price_per_minute DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO bike_type_prices (type, price_per_minute) VALUES ('classic', 0.5), ('ebike', 0.5), ('cargo', 0.5);
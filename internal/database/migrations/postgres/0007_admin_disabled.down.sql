@@ -0,0 +1 @@
+ALTER TABLE admins DROP COLUMN disabled_at;
@@ -0,0 +1 @@
+ALTER TABLE admins ADD COLUMN disabled_at TIMESTAMPTZ;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_price_plan_zone_rates_station;
+DROP TABLE IF EXISTS price_plan_zone_rates;
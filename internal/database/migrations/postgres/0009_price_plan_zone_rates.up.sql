@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS price_plan_zone_rates (
+    id SERIAL PRIMARY KEY,
+    price_plan_id INTEGER NOT NULL REFERENCES price_plans(id),
+    station_id INTEGER NOT NULL REFERENCES stations(id),
+    apply_to TEXT NOT NULL CHECK (apply_to IN ('start', 'end')),
+    fee DOUBLE PRECISION NOT NULL DEFAULT 0,
+    multiplier DOUBLE PRECISION NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (price_plan_id, station_id, apply_to)
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_plan_zone_rates_station ON price_plan_zone_rates(station_id);
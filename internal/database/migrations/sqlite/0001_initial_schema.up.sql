@@ -0,0 +1,159 @@
+CREATE TABLE IF NOT EXISTS users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    email TEXT NOT NULL UNIQUE,
+    hashed_password TEXT NOT NULL,
+    first_name TEXT NOT NULL,
+    last_name TEXT NOT NULL,
+    email_verified_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS admins (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    email TEXT NOT NULL UNIQUE,
+    hashed_password TEXT NOT NULL,
+    name TEXT NOT NULL,
+    role TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS price_plans (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    unlock_fee REAL NOT NULL DEFAULT 0,
+    price_per_minute REAL,
+    free_minutes INTEGER NOT NULL DEFAULT 0,
+    daily_cap REAL,
+    night_multiplier REAL NOT NULL DEFAULT 1,
+    night_start_hour INTEGER NOT NULL DEFAULT 0,
+    night_end_hour INTEGER NOT NULL DEFAULT 0,
+    weekend_multiplier REAL NOT NULL DEFAULT 1,
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    paused_price_per_minute REAL
+);
+
+CREATE TABLE IF NOT EXISTS bikes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    is_available INTEGER NOT NULL DEFAULT 1,
+    latitude REAL NOT NULL,
+    longitude REAL NOT NULL,
+    price_per_minute REAL NOT NULL DEFAULT 0.5,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    price_plan_id INTEGER,
+    FOREIGN KEY (price_plan_id) REFERENCES price_plans(id)
+);
+
+CREATE TABLE IF NOT EXISTS rentals (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    bike_id INTEGER NOT NULL,
+    status TEXT NOT NULL,
+    start_time DATETIME NOT NULL,
+    end_time DATETIME,
+    start_latitude REAL NOT NULL,
+    start_longitude REAL NOT NULL,
+    end_latitude REAL,
+    end_longitude REAL,
+    duration_minutes INTEGER,
+    cost REAL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    cost_breakdown TEXT,
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (bike_id) REFERENCES bikes(id)
+);
+
+CREATE TABLE IF NOT EXISTS rental_segments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    rental_id INTEGER NOT NULL,
+    state TEXT NOT NULL,
+    started_at DATETIME NOT NULL,
+    ended_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (rental_id) REFERENCES rentals(id)
+);
+
+CREATE TABLE IF NOT EXISTS reservations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    bike_id INTEGER NOT NULL,
+    status TEXT NOT NULL,
+    expires_at DATETIME NOT NULL,
+    rental_id INTEGER,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (bike_id) REFERENCES bikes(id),
+    FOREIGN KEY (rental_id) REFERENCES rentals(id)
+);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    family_id TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    access_jti TEXT NOT NULL,
+    access_expires_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    used_at DATETIME,
+    revoked_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti TEXT PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE TABLE IF NOT EXISTS user_tokens (
+    jti TEXT PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    purpose TEXT NOT NULL,
+    expires_at DATETIME NOT NULL,
+    used_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE TABLE IF NOT EXISTS email_outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    recipient TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    status TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at DATETIME NOT NULL,
+    sent_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
+CREATE INDEX IF NOT EXISTS idx_admins_email ON admins(email);
+CREATE INDEX IF NOT EXISTS idx_bikes_available ON bikes(is_available);
+CREATE INDEX IF NOT EXISTS idx_bikes_price_plan ON bikes(price_plan_id);
+CREATE INDEX IF NOT EXISTS idx_bikes_location ON bikes(latitude, longitude);
+CREATE INDEX IF NOT EXISTS idx_rentals_user ON rentals(user_id);
+CREATE INDEX IF NOT EXISTS idx_rentals_bike ON rentals(bike_id);
+CREATE INDEX IF NOT EXISTS idx_rentals_status ON rentals(status);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_rentals_one_running_per_user ON rentals(user_id) WHERE status IN ('running', 'paused');
+CREATE INDEX IF NOT EXISTS idx_rental_segments_rental ON rental_segments(rental_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_rental_segments_one_open_per_rental ON rental_segments(rental_id) WHERE ended_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_reservations_status_expiry ON reservations(status, expires_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reservations_one_active_per_user ON reservations(user_id) WHERE status = 'active';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reservations_one_active_per_bike ON reservations(bike_id) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user ON refresh_tokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family ON refresh_tokens(family_id);
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expiry ON revoked_tokens(expires_at);
+CREATE INDEX IF NOT EXISTS idx_user_tokens_user_purpose ON user_tokens(user_id, purpose);
+CREATE INDEX IF NOT EXISTS idx_email_outbox_status_next_attempt ON email_outbox(status, next_attempt_at);
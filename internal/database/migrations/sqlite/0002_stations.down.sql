@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_bikes_station;
+ALTER TABLE bikes DROP COLUMN station_id;
+DROP TABLE IF EXISTS stations;
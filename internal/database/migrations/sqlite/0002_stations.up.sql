@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS stations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    latitude REAL NOT NULL,
+    longitude REAL NOT NULL,
+    radius_meters REAL,
+    polygon TEXT,
+    capacity INTEGER NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE bikes ADD COLUMN station_id INTEGER REFERENCES stations(id);
+
+CREATE INDEX IF NOT EXISTS idx_bikes_station ON bikes(station_id);
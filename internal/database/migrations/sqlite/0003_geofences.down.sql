@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_geofences_bounds;
+DROP INDEX IF EXISTS idx_geofences_kind;
+DROP TABLE IF EXISTS geofences;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS geofences (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    kind TEXT NOT NULL CHECK (kind IN ('operating_area', 'no_parking', 'slow')),
+    name TEXT NOT NULL DEFAULT '',
+    rings TEXT NOT NULL,
+    speed_limit_kmh REAL,
+    min_latitude REAL NOT NULL,
+    max_latitude REAL NOT NULL,
+    min_longitude REAL NOT NULL,
+    max_longitude REAL NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_geofences_kind ON geofences(kind);
+CREATE INDEX IF NOT EXISTS idx_geofences_bounds ON geofences(min_latitude, max_latitude);
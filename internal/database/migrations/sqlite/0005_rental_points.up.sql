@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS rental_points (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    rental_id INTEGER NOT NULL,
+    latitude REAL NOT NULL,
+    longitude REAL NOT NULL,
+    recorded_at DATETIME NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (rental_id) REFERENCES rentals(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rental_points_rental ON rental_points(rental_id, recorded_at);
+
+ALTER TABLE rentals ADD COLUMN distance_km REAL;
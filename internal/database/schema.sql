@@ -1,44 +0,0 @@
-CREATE TABLE IF NOT EXISTS users (
-    id INTEGER PRIMARY KEY AUTOINCREMENT,
-    email TEXT NOT NULL UNIQUE,
-    hashed_password TEXT NOT NULL,
-    first_name TEXT NOT NULL,
-    last_name TEXT NOT NULL,
-    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
-);
-
-CREATE TABLE IF NOT EXISTS bikes (
-    id INTEGER PRIMARY KEY AUTOINCREMENT,
-    is_available INTEGER NOT NULL DEFAULT 1,
-    latitude REAL NOT NULL,
-    longitude REAL NOT NULL,
-    price_per_minute REAL NOT NULL DEFAULT 0.5,
-    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
-);
-
-CREATE TABLE IF NOT EXISTS rentals (
-    id INTEGER PRIMARY KEY AUTOINCREMENT,
-    user_id INTEGER NOT NULL,
-    bike_id INTEGER NOT NULL,
-    status TEXT NOT NULL,
-    start_time DATETIME NOT NULL,
-    end_time DATETIME,
-    start_latitude REAL NOT NULL,
-    start_longitude REAL NOT NULL,
-    end_latitude REAL,
-    end_longitude REAL,
-    duration_minutes INTEGER,
-    cost REAL,
-    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-    FOREIGN KEY (user_id) REFERENCES users(id),
-    FOREIGN KEY (bike_id) REFERENCES bikes(id)
-);
-
-CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
-CREATE INDEX IF NOT EXISTS idx_bikes_available ON bikes(is_available);
-CREATE INDEX IF NOT EXISTS idx_rentals_user ON rentals(user_id);
-CREATE INDEX IF NOT EXISTS idx_rentals_bike ON rentals(bike_id);
-CREATE INDEX IF NOT EXISTS idx_rentals_status ON rentals(status);
\ No newline at end of file
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/Nimirandad/bike-rental-service/internal/database")
+
+// startQuerySpan starts a client span for query named after its SQL verb, so
+// traces read "SELECT", "UPDATE" and so on without repeating the statement.
+func startQuerySpan(ctx context.Context, dialect Dialect, query string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, queryOperation(query),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", dialect.Name()),
+			attribute.String("db.statement", query),
+		),
+	)
+}
+
+// endQuerySpan records err on the span. sql.ErrNoRows is an expected outcome
+// of lookups and is not marked as an error.
+func endQuerySpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func queryOperation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "SQL"
+	}
+	return strings.ToUpper(fields[0])
+}
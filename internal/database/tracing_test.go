@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestQuerySpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+
+	db, err := Connect(DriverSQLite, filepath.Join(t.TempDir(), "test.db"), true)
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	defer db.Close()
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+
+	var count int
+	assert.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM bikes WHERE is_available = ?", 1).Scan(&count))
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	_, err = tx.ExecContext(ctx, "UPDATE bikes SET is_available = 1 WHERE id = ?", 0)
+	assert.NoError(t, err)
+	_, err = tx.ExecContext(ctx, "INSERT INTO missing_table VALUES (1)")
+	assert.Error(t, err)
+	assert.NoError(t, tx.Rollback())
+	parent.End()
+
+	var children []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Parent().SpanID() == parent.SpanContext().SpanID() {
+			children = append(children, span)
+		}
+	}
+	if len(children) != 3 {
+		t.Fatalf("expected 3 query spans under the request, got %d", len(children))
+	}
+
+	assert.Equal(t, "SELECT", children[0].Name())
+	assert.Contains(t, children[0].Attributes(), attribute.String("db.system", "sqlite"))
+	assert.Contains(t, children[0].Attributes(), attribute.String("db.statement", "SELECT COUNT(*) FROM bikes WHERE is_available = ?"))
+	assert.Equal(t, "UPDATE", children[1].Name())
+	assert.Equal(t, "INSERT", children[2].Name())
+	assert.NotEmpty(t, children[2].Events(), "failed queries record the error")
+}
@@ -0,0 +1,87 @@
+package geojson
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+)
+
+// Geofences converts an uploaded collection into geofences of kind. Each
+// Polygon feature becomes one geofence and each MultiPolygon one per
+// polygon. The optional properties are "name" and, for slow zones,
+// "speed_limit_kmh". Problems are returned as validation errors keyed by
+// feature.
+func Geofences(kind models.GeofenceKind, collection *FeatureCollection) ([]*models.Geofence, map[string]string) {
+	errors := make(map[string]string)
+
+	if collection.Type != TypeFeatureCollection {
+		errors["type"] = "Type must be " + TypeFeatureCollection
+		return nil, errors
+	}
+
+	geofences := []*models.Geofence{}
+	for i, feature := range collection.Features {
+		key := fmt.Sprintf("features[%d]", i)
+
+		if feature.Geometry == nil {
+			errors[key+".geometry"] = "Geometry is required"
+			continue
+		}
+
+		polygons, err := feature.Geometry.Polygons()
+		if err != nil {
+			errors[key+".geometry"] = capitalize(err.Error())
+			continue
+		}
+
+		name, _ := feature.Properties["name"].(string)
+
+		var speedLimit *float64
+		if value, ok := feature.Properties["speed_limit_kmh"]; ok && kind == models.GeofenceSlow {
+			limit, isNumber := value.(float64)
+			if !isNumber || limit <= 0 {
+				errors[key+".properties.speed_limit_kmh"] = "Speed limit must be a number greater than 0"
+				continue
+			}
+			speedLimit = &limit
+		}
+
+		for _, rings := range polygons {
+			geofence := &models.Geofence{
+				Kind:          kind,
+				Name:          strings.TrimSpace(name),
+				Rings:         rings,
+				SpeedLimitKmh: speedLimit,
+			}
+			geofence.SetBounds()
+			geofences = append(geofences, geofence)
+		}
+	}
+
+	return geofences, errors
+}
+
+// FromGeofences builds a collection with one Polygon feature per geofence.
+func FromGeofences(geofences []*models.Geofence) FeatureCollection {
+	features := make([]Feature, 0, len(geofences))
+	for _, geofence := range geofences {
+		properties := map[string]interface{}{
+			"id":   geofence.ID,
+			"kind": geofence.Kind,
+			"name": geofence.Name,
+		}
+		if geofence.SpeedLimitKmh != nil {
+			properties["speed_limit_kmh"] = *geofence.SpeedLimitKmh
+		}
+		features = append(features, NewFeature(NewPolygon(geofence.Rings), properties))
+	}
+	return NewFeatureCollection(features)
+}
+
+func capitalize(message string) string {
+	if message == "" {
+		return message
+	}
+	return strings.ToUpper(message[:1]) + message[1:]
+}
@@ -0,0 +1,166 @@
+// Package geojson reads and writes the subset of GeoJSON (RFC 7946) the
+// service exchanges: feature collections of polygons and line strings.
+// Positions are [longitude, latitude], the reverse of models.GeoPoint.
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+)
+
+// ContentType is the media type of GeoJSON documents.
+const ContentType = "application/geo+json"
+
+const (
+	TypeFeatureCollection = "FeatureCollection"
+	TypeFeature           = "Feature"
+	TypePolygon           = "Polygon"
+	TypeMultiPolygon      = "MultiPolygon"
+	TypeLineString        = "LineString"
+)
+
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+type Feature struct {
+	Type       string                 `json:"type"`
+	Properties map[string]interface{} `json:"properties"`
+	Geometry   *Geometry              `json:"geometry"`
+}
+
+// Geometry keeps its coordinates raw, since their nesting depends on Type.
+type Geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+func NewFeatureCollection(features []Feature) FeatureCollection {
+	if features == nil {
+		features = []Feature{}
+	}
+	return FeatureCollection{Type: TypeFeatureCollection, Features: features}
+}
+
+func NewFeature(geometry *Geometry, properties map[string]interface{}) Feature {
+	return Feature{Type: TypeFeature, Properties: properties, Geometry: geometry}
+}
+
+// NewPolygon builds a Polygon geometry from rings of points.
+func NewPolygon(rings [][]models.GeoPoint) *Geometry {
+	coordinates := make([][][2]float64, len(rings))
+	for i, ring := range rings {
+		coordinates[i] = positions(ring)
+	}
+	return newGeometry(TypePolygon, coordinates)
+}
+
+// NewLineString builds a LineString geometry through the points in order.
+func NewLineString(points []models.GeoPoint) *Geometry {
+	return newGeometry(TypeLineString, positions(points))
+}
+
+func newGeometry(geometryType string, coordinates interface{}) *Geometry {
+	// Marshalling float arrays cannot fail.
+	raw, _ := json.Marshal(coordinates)
+	return &Geometry{Type: geometryType, Coordinates: raw}
+}
+
+func positions(points []models.GeoPoint) [][2]float64 {
+	coordinates := make([][2]float64, len(points))
+	for i, point := range points {
+		coordinates[i] = [2]float64{point.Longitude, point.Latitude}
+	}
+	return coordinates
+}
+
+// Polygons returns the polygons of a Polygon or MultiPolygon geometry, each
+// as its rings. Every ring must be closed, have at least four positions and
+// valid coordinates.
+func (g *Geometry) Polygons() ([][][]models.GeoPoint, error) {
+	var raw [][][][]float64
+	switch g.Type {
+	case TypePolygon:
+		var polygon [][][]float64
+		if err := json.Unmarshal(g.Coordinates, &polygon); err != nil {
+			return nil, errors.New("invalid polygon coordinates")
+		}
+		raw = [][][][]float64{polygon}
+	case TypeMultiPolygon:
+		if err := json.Unmarshal(g.Coordinates, &raw); err != nil {
+			return nil, errors.New("invalid multipolygon coordinates")
+		}
+	default:
+		return nil, fmt.Errorf("geometry type must be %s or %s", TypePolygon, TypeMultiPolygon)
+	}
+
+	polygons := make([][][]models.GeoPoint, 0, len(raw))
+	for _, rawPolygon := range raw {
+		if len(rawPolygon) == 0 {
+			return nil, errors.New("polygon has no rings")
+		}
+
+		rings := make([][]models.GeoPoint, 0, len(rawPolygon))
+		for _, rawRing := range rawPolygon {
+			ring, err := parseRing(rawRing)
+			if err != nil {
+				return nil, err
+			}
+			rings = append(rings, ring)
+		}
+		polygons = append(polygons, rings)
+	}
+
+	if len(polygons) == 0 {
+		return nil, errors.New("multipolygon has no polygons")
+	}
+
+	return polygons, nil
+}
+
+func parseRing(raw [][]float64) ([]models.GeoPoint, error) {
+	if len(raw) < 4 {
+		return nil, errors.New("rings need at least 4 positions")
+	}
+
+	ring := make([]models.GeoPoint, len(raw))
+	for i, position := range raw {
+		if len(position) < 2 {
+			return nil, errors.New("positions need a longitude and a latitude")
+		}
+		longitude, latitude := position[0], position[1]
+		if latitude < constants.MinLatitude || latitude > constants.MaxLatitude ||
+			longitude < constants.MinLongitude || longitude > constants.MaxLongitude {
+			return nil, errors.New("positions must have valid coordinates")
+		}
+		ring[i] = models.GeoPoint{Latitude: latitude, Longitude: longitude}
+	}
+
+	if ring[0] != ring[len(ring)-1] {
+		return nil, errors.New("rings must be closed")
+	}
+
+	return ring, nil
+}
+
+// Write sends collection as a GeoJSON document.
+func Write(w http.ResponseWriter, status int, collection FeatureCollection) error {
+	return writeDocument(w, status, collection)
+}
+
+// WriteFeature sends a single feature as a GeoJSON document.
+func WriteFeature(w http.ResponseWriter, status int, feature Feature) error {
+	return writeDocument(w, status, feature)
+}
+
+func writeDocument(w http.ResponseWriter, status int, document interface{}) error {
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(document)
+}
@@ -0,0 +1,181 @@
+package geojson
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const squareCoordinates = `[[[-3.71, 40.42], [-3.70, 40.42], [-3.70, 40.43], [-3.71, 40.43], [-3.71, 40.42]]]`
+
+func collection(t *testing.T, body string) *FeatureCollection {
+	t.Helper()
+
+	var fc FeatureCollection
+	if err := json.Unmarshal([]byte(body), &fc); err != nil {
+		t.Fatalf("invalid test collection: %v", err)
+	}
+	return &fc
+}
+
+func TestGeometry_Polygons(t *testing.T) {
+	t.Run("Polygon", func(t *testing.T) {
+		geometry := &Geometry{Type: TypePolygon, Coordinates: json.RawMessage(squareCoordinates)}
+
+		polygons, err := geometry.Polygons()
+
+		assert.NoError(t, err)
+		assert.Len(t, polygons, 1)
+		assert.Len(t, polygons[0][0], 5)
+		assert.Equal(t, models.GeoPoint{Latitude: 40.42, Longitude: -3.71}, polygons[0][0][0])
+	})
+
+	t.Run("MultiPolygon", func(t *testing.T) {
+		geometry := &Geometry{Type: TypeMultiPolygon, Coordinates: json.RawMessage("[" + squareCoordinates + "," + squareCoordinates + "]")}
+
+		polygons, err := geometry.Polygons()
+
+		assert.NoError(t, err)
+		assert.Len(t, polygons, 2)
+	})
+
+	tests := []struct {
+		name        string
+		geometry    Geometry
+		wantMessage string
+	}{
+		{"Unsupported type", Geometry{Type: "Point", Coordinates: json.RawMessage(`[-3.7, 40.4]`)}, "geometry type must be Polygon or MultiPolygon"},
+		{"Open ring", Geometry{Type: TypePolygon, Coordinates: json.RawMessage(`[[[-3.71, 40.42], [-3.70, 40.42], [-3.70, 40.43], [-3.71, 40.43]]]`)}, "rings must be closed"},
+		{"Too few positions", Geometry{Type: TypePolygon, Coordinates: json.RawMessage(`[[[-3.71, 40.42], [-3.70, 40.42], [-3.71, 40.42]]]`)}, "rings need at least 4 positions"},
+		{"Invalid coordinates", Geometry{Type: TypePolygon, Coordinates: json.RawMessage(`[[[-3.71, 95], [-3.70, 40.42], [-3.70, 40.43], [-3.71, 95]]]`)}, "positions must have valid coordinates"},
+		{"Malformed coordinates", Geometry{Type: TypePolygon, Coordinates: json.RawMessage(`"nope"`)}, "invalid polygon coordinates"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.geometry.Polygons()
+			assert.EqualError(t, err, tt.wantMessage)
+		})
+	}
+}
+
+func TestGeofences(t *testing.T) {
+	t.Run("Valid collection", func(t *testing.T) {
+		fc := collection(t, `{"type": "FeatureCollection", "features": [
+			{"type": "Feature", "properties": {"name": " Centro ", "speed_limit_kmh": 10}, "geometry": {"type": "Polygon", "coordinates": `+squareCoordinates+`}},
+			{"type": "Feature", "properties": null, "geometry": {"type": "MultiPolygon", "coordinates": [`+squareCoordinates+`, `+squareCoordinates+`]}}
+		]}`)
+
+		geofences, errs := Geofences(models.GeofenceSlow, fc)
+
+		assert.Empty(t, errs)
+		assert.Len(t, geofences, 3)
+		assert.Equal(t, "Centro", geofences[0].Name)
+		assert.Equal(t, 10.0, *geofences[0].SpeedLimitKmh)
+		assert.Equal(t, models.GeofenceSlow, geofences[0].Kind)
+		assert.Equal(t, 40.42, geofences[0].MinLatitude)
+		assert.Equal(t, 40.43, geofences[0].MaxLatitude)
+		assert.Equal(t, -3.71, geofences[0].MinLongitude)
+		assert.Equal(t, -3.70, geofences[0].MaxLongitude)
+		assert.Nil(t, geofences[1].SpeedLimitKmh)
+	})
+
+	t.Run("Speed limit ignored outside slow zones", func(t *testing.T) {
+		fc := collection(t, `{"type": "FeatureCollection", "features": [
+			{"type": "Feature", "properties": {"speed_limit_kmh": 10}, "geometry": {"type": "Polygon", "coordinates": `+squareCoordinates+`}}
+		]}`)
+
+		geofences, errs := Geofences(models.GeofenceNoParking, fc)
+
+		assert.Empty(t, errs)
+		assert.Nil(t, geofences[0].SpeedLimitKmh)
+	})
+
+	t.Run("Invalid features", func(t *testing.T) {
+		fc := collection(t, `{"type": "FeatureCollection", "features": [
+			{"type": "Feature", "properties": {}},
+			{"type": "Feature", "properties": {}, "geometry": {"type": "Point", "coordinates": [-3.7, 40.4]}},
+			{"type": "Feature", "properties": {"speed_limit_kmh": "fast"}, "geometry": {"type": "Polygon", "coordinates": `+squareCoordinates+`}}
+		]}`)
+
+		geofences, errs := Geofences(models.GeofenceSlow, fc)
+
+		assert.Equal(t, map[string]string{
+			"features[0].geometry":                   "Geometry is required",
+			"features[1].geometry":                   "Geometry type must be Polygon or MultiPolygon",
+			"features[2].properties.speed_limit_kmh": "Speed limit must be a number greater than 0",
+		}, errs)
+		assert.Empty(t, geofences)
+	})
+
+	t.Run("Not a feature collection", func(t *testing.T) {
+		_, errs := Geofences(models.GeofenceSlow, collection(t, `{"type": "Feature"}`))
+
+		assert.Equal(t, "Type must be FeatureCollection", errs["type"])
+	})
+}
+
+func TestFromGeofences_RoundTrip(t *testing.T) {
+	fc := collection(t, `{"type": "FeatureCollection", "features": [
+		{"type": "Feature", "properties": {"name": "Centro"}, "geometry": {"type": "Polygon", "coordinates": `+squareCoordinates+`}}
+	]}`)
+	geofences, _ := Geofences(models.GeofenceNoParking, fc)
+	geofences[0].ID = 7
+
+	exported := FromGeofences(geofences)
+
+	assert.Equal(t, TypeFeatureCollection, exported.Type)
+	assert.Len(t, exported.Features, 1)
+	assert.Equal(t, 7, exported.Features[0].Properties["id"])
+	assert.Equal(t, models.GeofenceNoParking, exported.Features[0].Properties["kind"])
+
+	polygons, err := exported.Features[0].Geometry.Polygons()
+	assert.NoError(t, err)
+	assert.Equal(t, geofences[0].Rings, polygons[0])
+}
+
+func TestWrite(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := Write(w, 200, NewFeatureCollection(nil))
+
+	assert.NoError(t, err)
+	assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"type": "FeatureCollection", "features": []}`, w.Body.String())
+}
+
+func TestFromRoute(t *testing.T) {
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	route := &models.RentalRoute{
+		RentalID:   7,
+		Status:     models.RentalStatusEnded,
+		DistanceKm: 1.2,
+		Points: []models.RentalPoint{
+			{Latitude: 40.41, Longitude: -3.70, RecordedAt: start},
+			{Latitude: 40.42, Longitude: -3.71, RecordedAt: start.Add(time.Minute)},
+		},
+	}
+
+	w := httptest.NewRecorder()
+	err := WriteFeature(w, 200, FromRoute(route))
+
+	assert.NoError(t, err)
+	assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"type": "Feature",
+		"properties": {
+			"rental_id": 7,
+			"status": "ended",
+			"distance_km": 1.2,
+			"recorded_at": ["2024-01-15T10:00:00Z", "2024-01-15T10:01:00Z"]
+		},
+		"geometry": {"type": "LineString", "coordinates": [[-3.7, 40.41], [-3.71, 40.42]]}
+	}`, w.Body.String())
+
+	route.Points = route.Points[:1]
+	assert.Nil(t, FromRoute(route).Geometry)
+}
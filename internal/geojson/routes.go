@@ -0,0 +1,32 @@
+package geojson
+
+import (
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+)
+
+// FromRoute builds a LineString feature through the route's points. The
+// point timestamps are listed in the recorded_at property, in the same order
+// as the coordinates. A route with a single point has no line to draw, so
+// its geometry is null.
+func FromRoute(route *models.RentalRoute) Feature {
+	recordedAt := make([]time.Time, len(route.Points))
+	for i, point := range route.Points {
+		recordedAt[i] = point.RecordedAt
+	}
+
+	properties := map[string]interface{}{
+		"rental_id":   route.RentalID,
+		"status":      route.Status,
+		"distance_km": route.DistanceKm,
+		"recorded_at": recordedAt,
+	}
+
+	var geometry *Geometry
+	if len(route.Points) > 1 {
+		geometry = NewLineString(route.GeoPoints())
+	}
+
+	return NewFeature(geometry, properties)
+}
@@ -0,0 +1,73 @@
+// Package gpx writes rental routes as GPX 1.1 tracks, the format most GPS
+// and fitness apps import.
+package gpx
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+)
+
+// ContentType is the media type of GPX documents.
+const ContentType = "application/gpx+xml"
+
+const (
+	version   = "1.1"
+	creator   = "bike-rental-service"
+	namespace = "http://www.topografix.com/GPX/1/1"
+)
+
+type Document struct {
+	XMLName xml.Name `xml:"gpx"`
+	Version string   `xml:"version,attr"`
+	Creator string   `xml:"creator,attr"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Tracks  []Track  `xml:"trk"`
+}
+
+type Track struct {
+	Name     string    `xml:"name"`
+	Segments []Segment `xml:"trkseg"`
+}
+
+type Segment struct {
+	Points []Point `xml:"trkpt"`
+}
+
+type Point struct {
+	Latitude  float64   `xml:"lat,attr"`
+	Longitude float64   `xml:"lon,attr"`
+	Time      time.Time `xml:"time"`
+}
+
+// FromRoute builds a document with one track of one segment through the
+// route's points.
+func FromRoute(route *models.RentalRoute) Document {
+	points := make([]Point, len(route.Points))
+	for i, point := range route.Points {
+		points[i] = Point{Latitude: point.Latitude, Longitude: point.Longitude, Time: point.RecordedAt.UTC()}
+	}
+
+	return Document{
+		Version: version,
+		Creator: creator,
+		Xmlns:   namespace,
+		Tracks: []Track{{
+			Name:     "Rental " + strconv.Itoa(route.RentalID),
+			Segments: []Segment{{Points: points}},
+		}},
+	}
+}
+
+// Write sends document as a GPX file.
+func Write(w http.ResponseWriter, status int, document Document) error {
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(status)
+	if _, err := w.Write([]byte(xml.Header)); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(document)
+}
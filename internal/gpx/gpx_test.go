@@ -0,0 +1,33 @@
+package gpx
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite_Route(t *testing.T) {
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	route := &models.RentalRoute{
+		RentalID: 7,
+		Points: []models.RentalPoint{
+			{Latitude: 40.41, Longitude: -3.7, RecordedAt: start},
+			{Latitude: 40.42, Longitude: -3.71, RecordedAt: start.Add(time.Minute)},
+		},
+	}
+
+	w := httptest.NewRecorder()
+	err := Write(w, 200, FromRoute(route))
+
+	assert.NoError(t, err)
+	assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+		`<gpx version="1.1" creator="bike-rental-service" xmlns="http://www.topografix.com/GPX/1/1">`+
+		`<trk><name>Rental 7</name><trkseg>`+
+		`<trkpt lat="40.41" lon="-3.7"><time>2024-01-15T10:00:00Z</time></trkpt>`+
+		`<trkpt lat="40.42" lon="-3.71"><time>2024-01-15T10:01:00Z</time></trkpt>`+
+		`</trkseg></trk></gpx>`, w.Body.String())
+}
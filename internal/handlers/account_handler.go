@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Nimirandad/bike-rental-service/internal/logger"
+	"github.com/Nimirandad/bike-rental-service/internal/services"
+	"github.com/Nimirandad/bike-rental-service/internal/types"
+	"github.com/Nimirandad/bike-rental-service/internal/utils"
+)
+
+type AccountService interface {
+	RequestEmailVerification(ctx context.Context, userID int) error
+	VerifyEmail(ctx context.Context, token string) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+}
+
+type AccountHandler struct {
+	accountService AccountService
+}
+
+func NewAccountHandler(accountService *services.AccountService) *AccountHandler {
+	return &AccountHandler{accountService: accountService}
+}
+
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Email a single-use password reset link. The response is the same whether or not the email is registered
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body types.ForgotPasswordRequest true "Account email"
+// @Success 200 {object} types.SuccessResponse "Reset link sent if the email is registered"
+// @Failure 400 {object} types.Problem "Invalid request payload or validation errors"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /users/password/forgot [post]
+func (h *AccountHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+	var req types.ForgotPasswordRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error().Err(err).Msg("Failed to decode forgot password request")
+		types.WriteError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if valid, msg := utils.ValidateEmail(req.Email); !valid {
+		types.WriteValidationErrors(w, map[string]string{"email": msg})
+		return
+	}
+
+	if err := h.accountService.RequestPasswordReset(r.Context(), req.Email); err != nil {
+		logServiceError(&log, err).Msg("Failed to queue password reset email")
+		types.WriteProblem(w, err, "Internal server error")
+		return
+	}
+
+	log.Info().Msg("Password reset requested")
+	types.WriteSuccess(w, "If the email is registered, a password reset link has been sent", nil)
+}
+
+// ResetPassword godoc
+// @Summary Reset password
+// @Description Set a new password with the token from a reset email. The token works once, and every session of the user is logged out
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body types.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} types.SuccessResponse "Password reset successfully"
+// @Failure 400 {object} types.Problem "Invalid, expired or used token, or validation errors"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /users/password/reset [post]
+func (h *AccountHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+	var req types.ResetPasswordRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error().Err(err).Msg("Failed to decode reset password request")
+		types.WriteError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if validationErrors := utils.ValidateResetPasswordRequest(req.Token, req.Password); len(validationErrors) > 0 {
+		log.Warn().Interface("validation_errors", validationErrors).Msg("Reset password validation failed")
+		types.WriteValidationErrors(w, validationErrors)
+		return
+	}
+
+	if err := h.accountService.ResetPassword(r.Context(), req.Token, req.Password); err != nil {
+		logServiceError(&log, err).Msg("Failed to reset password")
+		types.WriteProblem(w, err, "Internal server error")
+		return
+	}
+
+	log.Info().Msg("Password reset successfully")
+	types.WriteSuccess(w, "Password reset successfully", nil)
+}
+
+// VerifyEmail godoc
+// @Summary Verify email address
+// @Description Confirm the user's email address with the token from a verification email
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body types.VerifyEmailRequest true "Verification token"
+// @Success 200 {object} types.SuccessResponse "Email verified successfully"
+// @Failure 400 {object} types.Problem "Invalid, expired or used token"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /users/email/verify [post]
+func (h *AccountHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+	var req types.VerifyEmailRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error().Err(err).Msg("Failed to decode verify email request")
+		types.WriteError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if req.Token == "" {
+		types.WriteValidationErrors(w, map[string]string{"token": "Token is required"})
+		return
+	}
+
+	if err := h.accountService.VerifyEmail(r.Context(), req.Token); err != nil {
+		logServiceError(&log, err).Msg("Failed to verify email")
+		types.WriteProblem(w, err, "Internal server error")
+		return
+	}
+
+	log.Info().Msg("Email verified successfully")
+	types.WriteSuccess(w, "Email verified successfully", nil)
+}
+
+// ResendVerification godoc
+// @Summary Resend verification email
+// @Description Email a new verification link to the authenticated user. Earlier links stop working
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} types.SuccessResponse "Verification email sent"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 409 {object} types.Problem "Email already verified"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /users/email/verify/resend [post]
+func (h *AccountHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	claims, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.accountService.RequestEmailVerification(r.Context(), claims.Sub); err != nil {
+		logServiceError(&log, err).Int("user_id", claims.Sub).Msg("Failed to queue verification email")
+		types.WriteProblem(w, err, "Internal server error")
+		return
+	}
+
+	log.Info().Int("user_id", claims.Sub).Msg("Verification email queued")
+	types.WriteSuccess(w, "Verification email sent", nil)
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockAccountService struct {
+	RequestEmailVerificationFunc func(userID int) error
+	VerifyEmailFunc              func(token string) error
+	RequestPasswordResetFunc     func(email string) error
+	ResetPasswordFunc            func(token, newPassword string) error
+}
+
+func (m *MockAccountService) RequestEmailVerification(ctx context.Context, userID int) error {
+	return m.RequestEmailVerificationFunc(userID)
+}
+
+func (m *MockAccountService) VerifyEmail(ctx context.Context, token string) error {
+	return m.VerifyEmailFunc(token)
+}
+
+func (m *MockAccountService) RequestPasswordReset(ctx context.Context, email string) error {
+	return m.RequestPasswordResetFunc(email)
+}
+
+func (m *MockAccountService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	return m.ResetPasswordFunc(token, newPassword)
+}
+
+func TestAccountHandler_ForgotPassword(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{"Success", `{"email":"test@example.com"}`, nil, http.StatusOK},
+		{"Invalid JSON", `invalid`, nil, http.StatusBadRequest},
+		{"Invalid email", `{"email":"not-an-email"}`, nil, http.StatusBadRequest},
+		{"Service error", `{"email":"test@example.com"}`, errors.New("database error"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &AccountHandler{accountService: &MockAccountService{
+				RequestPasswordResetFunc: func(email string) error { return tt.err },
+			}}
+			req := httptest.NewRequest(http.MethodPost, "/api/users/password/forgot", bytes.NewReader([]byte(tt.body)))
+			w := httptest.NewRecorder()
+
+			handler.ForgotPassword(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestAccountHandler_ResetPassword(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{"Success", `{"token":"signed-token","password":"NewPass123"}`, nil, http.StatusOK},
+		{"Invalid JSON", `invalid`, nil, http.StatusBadRequest},
+		{"Weak password", `{"token":"signed-token","password":"short"}`, nil, http.StatusBadRequest},
+		{"Invalid token", `{"token":"used-token","password":"NewPass123"}`, constants.ErrInvalidActionToken, http.StatusBadRequest},
+		{"Service error", `{"token":"signed-token","password":"NewPass123"}`, errors.New("database error"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &AccountHandler{accountService: &MockAccountService{
+				ResetPasswordFunc: func(token, newPassword string) error { return tt.err },
+			}}
+			req := httptest.NewRequest(http.MethodPost, "/api/users/password/reset", bytes.NewReader([]byte(tt.body)))
+			w := httptest.NewRecorder()
+
+			handler.ResetPassword(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestAccountHandler_VerifyEmail(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{"Success", `{"token":"signed-token"}`, nil, http.StatusOK},
+		{"Missing token", `{}`, nil, http.StatusBadRequest},
+		{"Invalid token", `{"token":"used-token"}`, constants.ErrInvalidActionToken, http.StatusBadRequest},
+		{"Service error", `{"token":"signed-token"}`, errors.New("database error"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &AccountHandler{accountService: &MockAccountService{
+				VerifyEmailFunc: func(token string) error { return tt.err },
+			}}
+			req := httptest.NewRequest(http.MethodPost, "/api/users/email/verify", bytes.NewReader([]byte(tt.body)))
+			w := httptest.NewRecorder()
+
+			handler.VerifyEmail(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestAccountHandler_ResendVerification(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"Success", nil, http.StatusOK},
+		{"Already verified", constants.ErrEmailAlreadyVerified, http.StatusConflict},
+		{"Service error", errors.New("database error"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &AccountHandler{accountService: &MockAccountService{
+				RequestEmailVerificationFunc: func(userID int) error { return tt.err },
+			}}
+			req := withUser(httptest.NewRequest(http.MethodPost, "/api/users/email/verify/resend", nil), &models.User{ID: 1})
+			w := httptest.NewRecorder()
+
+			handler.ResendVerification(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
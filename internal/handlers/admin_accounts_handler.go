@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/logger"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/services"
+	"github.com/Nimirandad/bike-rental-service/internal/types"
+	"github.com/Nimirandad/bike-rental-service/internal/utils"
+)
+
+type AdminAccountService interface {
+	Login(ctx context.Context, email, password string) (*models.Admin, error)
+	CreateAdmin(ctx context.Context, email, password, name string, role models.AdminRole) (*models.Admin, error)
+	GetAllAdmins(ctx context.Context, page, limit int) ([]*models.Admin, int, error)
+	UpdateAdmin(ctx context.Context, adminID int, name *string, role *models.AdminRole, password *string, disabled *bool) (*models.Admin, error)
+}
+
+type AdminAccountHandler struct {
+	adminAccountService AdminAccountService
+}
+
+func NewAdminAccountHandler(adminAccountService *services.AdminAccountService) *AdminAccountHandler {
+	return &AdminAccountHandler{adminAccountService: adminAccountService}
+}
+
+// LoginAdmin godoc
+// @Summary Admin login
+// @Description Authenticate an admin account and return an admin JWT token
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param credentials body types.LoginRequest true "Login credentials"
+// @Success 200 {object} types.SuccessResponse{data=types.LoginResponse} "Login successful with admin JWT token"
+// @Failure 400 {object} types.Problem "Invalid request payload"
+// @Failure 401 {object} types.Problem "Invalid credentials or admin disabled"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /admin/login [post]
+func (h *AdminAccountHandler) LoginAdmin(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+	var req types.LoginRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error().Err(err).Msg("Failed to decode admin login request")
+		types.WriteError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	log.Info().Str("email", req.Email).Msg("Admin login attempt")
+
+	if validationErrors := utils.ValidateLoginRequest(req.Email, req.Password); len(validationErrors) > 0 {
+		log.Warn().Interface("validation_errors", validationErrors).Msg("Admin login validation failed")
+		types.WriteValidationErrors(w, validationErrors)
+		return
+	}
+
+	admin, err := h.adminAccountService.Login(r.Context(), req.Email, req.Password)
+	if err != nil {
+		logServiceError(&log, err).Str("email", req.Email).Msg("Admin login error")
+		types.WriteProblem(w, err, "Internal server error")
+		return
+	}
+
+	token, err := utils.GenerateAdminJWT(admin)
+	if err != nil {
+		log.Error().Err(err).Int("admin_id", admin.ID).Msg("Failed to generate admin JWT token")
+		types.WriteError(w, http.StatusInternalServerError, "Error generating token")
+		return
+	}
+
+	log.Info().Int("admin_id", admin.ID).Str("role", string(admin.Role)).Msg("Admin login successful")
+	types.WriteSuccess(w, "Login successful", types.LoginResponse{Token: token})
+}
+
+// CreateAdmin godoc
+// @Summary Create an admin account (Admin)
+// @Description Create an admin account with a role (requires the admins:write permission)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param admin body types.CreateAdminRequest true "Admin account data"
+// @Security BearerAuth
+// @Success 200 {object} types.SuccessResponse{data=models.Admin} "Admin created successfully"
+// @Failure 400 {object} types.Problem "Validation failed"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 403 {object} types.Problem "Forbidden - admin role lacks permission"
+// @Failure 409 {object} types.Problem "Email already exists"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /admin/admins [post]
+func (h *AdminAccountHandler) CreateAdmin(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	claims, ok := currentAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	var req types.CreateAdminRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Warn().Err(err).Msg("Failed to decode create admin request")
+		types.WriteError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if validationErrors := utils.ValidateCreateAdminRequest(req.Email, req.Password, req.Name, req.Role); len(validationErrors) > 0 {
+		log.Warn().Interface("validation_errors", validationErrors).Msg("Create admin validation failed")
+		types.WriteValidationErrors(w, validationErrors)
+		return
+	}
+
+	admin, err := h.adminAccountService.CreateAdmin(r.Context(), req.Email, req.Password, req.Name, models.AdminRole(req.Role))
+	if err != nil {
+		logServiceError(&log, err).Str("email", req.Email).Msg("Failed to create admin")
+		types.WriteProblem(w, err, "Error creating admin")
+		return
+	}
+
+	log.Info().Int("admin_id", claims.Sub).Int("created_admin_id", admin.ID).Str("role", string(admin.Role)).Msg("Admin created successfully")
+	types.WriteSuccess(w, "Admin created successfully", admin)
+}
+
+// GetAllAdmins godoc
+// @Summary Get all admin accounts (Admin)
+// @Description Get paginated list of admin accounts (requires the admins:write permission)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page (max 100)" default(20)
+// @Security BearerAuth
+// @Success 200 {object} types.PaginatedResponse{data=[]models.Admin} "All admins retrieved successfully"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 403 {object} types.Problem "Forbidden - admin role lacks permission"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /admin/admins [get]
+func (h *AdminAccountHandler) GetAllAdmins(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	_, ok := currentAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	page := constants.DefaultPage
+	if pageParam := r.URL.Query().Get("page"); pageParam != "" {
+		if p, err := strconv.Atoi(pageParam); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	limit := constants.DefaultLimit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		if l, err := strconv.Atoi(limitParam); err == nil && l > 0 && l <= constants.MaxLimit {
+			limit = l
+		}
+	}
+
+	admins, total, err := h.adminAccountService.GetAllAdmins(r.Context(), page, limit)
+	if err != nil {
+		logServiceError(&log, err).Int("page", page).Int("limit", limit).Msg("Error retrieving admins")
+		types.WriteProblem(w, err, "Error retrieving admins")
+		return
+	}
+
+	log.Info().Int("total", total).Int("returned", len(admins)).Int("page", page).Int("limit", limit).Msg("All admins retrieved successfully")
+	types.WritePaginatedSuccess(w, "All admins retrieved successfully", admins, total, page, limit)
+}
+
+// UpdateAdmin godoc
+// @Summary Update an admin account (Admin)
+// @Description Change an admin's name, role or password, or disable the account (requires the admins:write permission). Changes apply to the admin's next request. The last enabled superadmin cannot be demoted or disabled.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param admin-id path int true "Admin ID"
+// @Param admin body types.UpdateAdminRequest true "Admin update data"
+// @Security BearerAuth
+// @Success 200 {object} types.SuccessResponse{data=models.Admin} "Admin updated successfully"
+// @Failure 400 {object} types.Problem "Invalid admin ID or update data"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 403 {object} types.Problem "Forbidden - admin role lacks permission"
+// @Failure 404 {object} types.Problem "Admin not found"
+// @Failure 409 {object} types.Problem "Cannot demote or disable the last superadmin"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /admin/admins/{admin-id} [patch]
+func (h *AdminAccountHandler) UpdateAdmin(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	claims, ok := currentAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	adminIDStr := r.PathValue("admin-id")
+	adminID, err := strconv.Atoi(adminIDStr)
+	if err != nil || adminID <= 0 {
+		log.Warn().Str("admin_id", adminIDStr).Msg("Invalid admin ID for update")
+		types.WriteError(w, http.StatusBadRequest, "Invalid admin ID")
+		return
+	}
+
+	var req types.UpdateAdminRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Warn().Err(err).Int("target_admin_id", adminID).Msg("Failed to decode update admin request")
+		types.WriteError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if req.Name == nil && req.Role == nil && req.Password == nil && req.Disabled == nil {
+		log.Warn().Int("target_admin_id", adminID).Msg("No fields provided for admin update")
+		types.WriteError(w, http.StatusBadRequest, "At least one field must be provided for update")
+		return
+	}
+
+	if validationErrors := utils.ValidateUpdateAdminRequest(req.Name, req.Role, req.Password); len(validationErrors) > 0 {
+		log.Warn().Interface("validation_errors", validationErrors).Msg("Update admin validation failed")
+		types.WriteValidationErrors(w, validationErrors)
+		return
+	}
+
+	var role *models.AdminRole
+	if req.Role != nil {
+		newRole := models.AdminRole(*req.Role)
+		role = &newRole
+	}
+
+	admin, err := h.adminAccountService.UpdateAdmin(r.Context(), adminID, req.Name, role, req.Password, req.Disabled)
+	if err != nil {
+		logServiceError(&log, err).Int("target_admin_id", adminID).Msg("Error updating admin")
+		types.WriteProblem(w, err, "Error updating admin")
+		return
+	}
+
+	log.Info().Int("admin_id", claims.Sub).Int("target_admin_id", admin.ID).Str("role", string(admin.Role)).Msg("Admin updated successfully")
+	types.WriteSuccess(w, "Admin updated successfully", admin)
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockAdminAccountService struct {
+	LoginFunc        func(email, password string) (*models.Admin, error)
+	CreateAdminFunc  func(email, password, name string, role models.AdminRole) (*models.Admin, error)
+	GetAllAdminsFunc func(page, limit int) ([]*models.Admin, int, error)
+	UpdateAdminFunc  func(adminID int, name *string, role *models.AdminRole, password *string, disabled *bool) (*models.Admin, error)
+}
+
+func (m *MockAdminAccountService) Login(ctx context.Context, email, password string) (*models.Admin, error) {
+	return m.LoginFunc(email, password)
+}
+
+func (m *MockAdminAccountService) CreateAdmin(ctx context.Context, email, password, name string, role models.AdminRole) (*models.Admin, error) {
+	return m.CreateAdminFunc(email, password, name, role)
+}
+
+func (m *MockAdminAccountService) GetAllAdmins(ctx context.Context, page, limit int) ([]*models.Admin, int, error) {
+	return m.GetAllAdminsFunc(page, limit)
+}
+
+func (m *MockAdminAccountService) UpdateAdmin(ctx context.Context, adminID int, name *string, role *models.AdminRole, password *string, disabled *bool) (*models.Admin, error) {
+	return m.UpdateAdminFunc(adminID, name, role, password, disabled)
+}
+
+func TestAdminAccountHandler_LoginAdmin_Success(t *testing.T) {
+	os.Setenv("JWT_SECRET", "test-secret")
+	defer os.Unsetenv("JWT_SECRET")
+
+	mockService := &MockAdminAccountService{
+		LoginFunc: func(email, password string) (*models.Admin, error) {
+			return &models.Admin{ID: 3, Email: email, Name: "Ops", Role: models.AdminRoleFleet}, nil
+		},
+	}
+
+	handler := &AdminAccountHandler{adminAccountService: mockService}
+	body, _ := json.Marshal(map[string]string{"email": "ops@example.com", "password": "secret123"})
+	req := httptest.NewRequest(http.MethodPost, "/admin/login", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.LoginAdmin(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data struct {
+			Token string `json:"token"`
+		} `json:"data"`
+	}
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+
+	claims, err := utils.ValidateAdminJWT(response.Data.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, claims.Sub)
+	assert.Equal(t, models.AdminRoleFleet, claims.Role)
+}
+
+func TestAdminAccountHandler_LoginAdmin_InvalidCredentials(t *testing.T) {
+	mockService := &MockAdminAccountService{
+		LoginFunc: func(email, password string) (*models.Admin, error) {
+			return nil, constants.ErrInvalidCredentials
+		},
+	}
+
+	handler := &AdminAccountHandler{adminAccountService: mockService}
+	body, _ := json.Marshal(map[string]string{"email": "ops@example.com", "password": "wrong123"})
+	req := httptest.NewRequest(http.MethodPost, "/admin/login", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.LoginAdmin(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAdminAccountHandler_CreateAdmin_Success(t *testing.T) {
+	mockService := &MockAdminAccountService{
+		CreateAdminFunc: func(email, password, name string, role models.AdminRole) (*models.Admin, error) {
+			return &models.Admin{ID: 2, Email: email, Name: name, Role: role}, nil
+		},
+	}
+
+	handler := &AdminAccountHandler{adminAccountService: mockService}
+	body, _ := json.Marshal(map[string]string{"email": "ops@example.com", "password": "secret123", "name": "Ops", "role": "fleet"})
+	req := httptest.NewRequest(http.MethodPost, "/admin/admins", bytes.NewReader(body))
+	req = withAdmin(req, models.AdminRoleSuperadmin)
+	w := httptest.NewRecorder()
+
+	handler.CreateAdmin(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAdminAccountHandler_CreateAdmin_InvalidRole(t *testing.T) {
+	handler := &AdminAccountHandler{}
+	body, _ := json.Marshal(map[string]string{"email": "ops@example.com", "password": "secret123", "name": "Ops", "role": "owner"})
+	req := httptest.NewRequest(http.MethodPost, "/admin/admins", bytes.NewReader(body))
+	req = withAdmin(req, models.AdminRoleSuperadmin)
+	w := httptest.NewRecorder()
+
+	handler.CreateAdmin(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAdminAccountHandler_UpdateAdmin_LastSuperadmin(t *testing.T) {
+	mockService := &MockAdminAccountService{
+		UpdateAdminFunc: func(adminID int, name *string, role *models.AdminRole, password *string, disabled *bool) (*models.Admin, error) {
+			return nil, constants.ErrLastSuperadmin
+		},
+	}
+
+	handler := &AdminAccountHandler{adminAccountService: mockService}
+	body, _ := json.Marshal(map[string]string{"role": "support"})
+	req := httptest.NewRequest(http.MethodPatch, "/admin/admins/1", bytes.NewReader(body))
+	req.SetPathValue("admin-id", "1")
+	req = withAdmin(req, models.AdminRoleSuperadmin)
+	w := httptest.NewRecorder()
+
+	handler.UpdateAdmin(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestAdminAccountHandler_UpdateAdmin_NotFound(t *testing.T) {
+	mockService := &MockAdminAccountService{
+		UpdateAdminFunc: func(adminID int, name *string, role *models.AdminRole, password *string, disabled *bool) (*models.Admin, error) {
+			return nil, constants.ErrAdminNotFound
+		},
+	}
+
+	handler := &AdminAccountHandler{adminAccountService: mockService}
+	body, _ := json.Marshal(map[string]string{"name": "Ghost"})
+	req := httptest.NewRequest(http.MethodPatch, "/admin/admins/99", bytes.NewReader(body))
+	req.SetPathValue("admin-id", "99")
+	req = withAdmin(req, models.AdminRoleSuperadmin)
+	w := httptest.NewRecorder()
+
+	handler.UpdateAdmin(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAdminAccountHandler_UpdateAdmin_Disable(t *testing.T) {
+	var gotDisabled *bool
+	mockService := &MockAdminAccountService{
+		UpdateAdminFunc: func(adminID int, name *string, role *models.AdminRole, password *string, disabled *bool) (*models.Admin, error) {
+			gotDisabled = disabled
+			return &models.Admin{ID: adminID, Role: models.AdminRoleFleet}, nil
+		},
+	}
+
+	handler := &AdminAccountHandler{adminAccountService: mockService}
+	body, _ := json.Marshal(map[string]bool{"disabled": true})
+	req := httptest.NewRequest(http.MethodPatch, "/admin/admins/2", bytes.NewReader(body))
+	req.SetPathValue("admin-id", "2")
+	req = withAdmin(req, models.AdminRoleSuperadmin)
+	w := httptest.NewRecorder()
+
+	handler.UpdateAdmin(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	if assert.NotNil(t, gotDisabled) {
+		assert.True(t, *gotDisabled)
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"strconv"
@@ -8,21 +9,25 @@ import (
 	"github.com/Nimirandad/bike-rental-service/internal/constants"
 	"github.com/Nimirandad/bike-rental-service/internal/logger"
 	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/queryspec"
 	"github.com/Nimirandad/bike-rental-service/internal/services"
 	"github.com/Nimirandad/bike-rental-service/internal/types"
 	"github.com/Nimirandad/bike-rental-service/internal/utils"
 )
 
 type AdminService interface {
-	CreateBike(latitude, longitude, pricePerMinute float64) (*models.Bike, error)
-	GetAllBikes(page, limit int) ([]*models.Bike, int, error)
-	UpdateBike(bikeID int, latitude, longitude *float64, isAvailable *bool, pricePerMinute *float64) (*models.Bike, error)
-	GetAllUsers(page, limit int) ([]*models.User, int, error)
-	GetUserByID(userID int) (*models.User, error)
-	UpdateUser(userID int, email, firstName, lastName, hashedPassword *string) (*models.User, error)
-	GetAllRentals(page, limit int) ([]*models.Rental, int, error)
-	GetRentalByID(rentalID int) (*models.Rental, error)
-	UpdateRental(rentalID int, status *string) (*models.Rental, error)
+	CreateBike(ctx context.Context, latitude, longitude float64, pricePerMinute *float64, pricePlanID *int, bikeType models.BikeType, batteryLevel *int) (*models.Bike, error)
+	GetAllBikes(ctx context.Context, spec *queryspec.Spec, page, limit int) ([]*models.Bike, int, error)
+	GetAllBikesByCursor(ctx context.Context, spec *queryspec.Spec, limit int) ([]*models.Bike, queryspec.Cursors, error)
+	UpdateBike(ctx context.Context, bikeID int, latitude, longitude *float64, isAvailable *bool, pricePerMinute *float64, pricePlanID *int, bikeType *models.BikeType, batteryLevel *int) (*models.Bike, error)
+	GetAllUsers(ctx context.Context, spec *queryspec.Spec, page, limit int) ([]*models.User, int, error)
+	GetAllUsersByCursor(ctx context.Context, spec *queryspec.Spec, limit int) ([]*models.User, queryspec.Cursors, error)
+	GetUserByID(ctx context.Context, userID int) (*models.User, error)
+	UpdateUser(ctx context.Context, userID int, email, firstName, lastName, hashedPassword *string) (*models.User, error)
+	GetAllRentals(ctx context.Context, spec *queryspec.Spec, page, limit int) ([]*models.Rental, int, error)
+	GetAllRentalsByCursor(ctx context.Context, spec *queryspec.Spec, limit int) ([]*models.Rental, queryspec.Cursors, error)
+	GetRentalByID(ctx context.Context, rentalID int) (*models.Rental, error)
+	UpdateRental(ctx context.Context, rentalID int, status models.RentalStatus) (*models.Rental, error)
 }
 
 type AdminHandler struct {
@@ -37,24 +42,23 @@ func NewAdminHandler(adminService *services.AdminService) *AdminHandler {
 
 // AddBike godoc
 // @Summary Add a new bike (Admin)
-// @Description Create a new bike with location and pricing (requires admin authentication)
+// @Description Create a new bike with location, type and pricing. Without price_per_minute the type's default price applies (requires admin authentication)
 // @Tags admin
 // @Accept json
 // @Produce json
 // @Param bike body types.AddBikeRequest true "Bike data with coordinates and price"
-// @Security BasicAuth
+// @Security BearerAuth
 // @Success 200 {object} types.SuccessResponse{data=models.Bike} "Bike added successfully"
-// @Failure 400 {object} types.ErrorResponse "Invalid coordinates or price"
-// @Failure 401 {object} types.ErrorResponse "Unauthorized - admin credentials required"
-// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 400 {object} types.Problem "Invalid coordinates, price, type or battery level"
+// @Failure 401 {object} types.Problem "Unauthorized - admin token required"
+// @Failure 403 {object} types.Problem "Forbidden - admin role lacks permission"
+// @Failure 500 {object} types.Problem "Internal server error"
 // @Router /admin/bikes [post]
 func (h *AdminHandler) AddBike(w http.ResponseWriter, r *http.Request) {
-	log := logger.Get()
-	authHeader := r.Header.Get("Authorization")
-	if err := utils.ValidateAdminBasicAuth(authHeader); err != nil {
-		log.Warn().Err(err).Msg("Unauthorized admin access attempt")
-		w.Header().Set("WWW-Authenticate", `Basic realm="Admin Access"`)
-		types.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+	log := logger.FromContext(r.Context())
+
+	claims, ok := currentAdmin(w, r)
+	if !ok {
 		return
 	}
 
@@ -79,50 +83,66 @@ func (h *AdminHandler) AddBike(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pricePerMinute := 0.5
-	if req.PricePerMinute != nil {
-		if *req.PricePerMinute <= 0 {
-			log.Warn().Float64("price", *req.PricePerMinute).Msg("Invalid price per minute")
-			types.WriteError(w, http.StatusBadRequest, "Price per minute must be greater than 0")
+	if req.PricePerMinute != nil && *req.PricePerMinute <= 0 {
+		log.Warn().Float64("price", *req.PricePerMinute).Msg("Invalid price per minute")
+		types.WriteError(w, http.StatusBadRequest, "Price per minute must be greater than 0")
+		return
+	}
+
+	bikeType := models.BikeTypeClassic
+	if req.Type != "" {
+		bikeType = models.BikeType(req.Type)
+		if !bikeType.IsValid() {
+			log.Warn().Str("type", req.Type).Msg("Invalid bike type")
+			types.WriteError(w, http.StatusBadRequest, "Type must be classic, ebike or cargo")
 			return
 		}
-		pricePerMinute = *req.PricePerMinute
 	}
 
-	bike, err := h.adminService.CreateBike(req.Latitude, req.Longitude, pricePerMinute)
+	if req.BatteryLevel != nil && (*req.BatteryLevel < 0 || *req.BatteryLevel > 100) {
+		log.Warn().Int("battery_level", *req.BatteryLevel).Msg("Invalid battery level")
+		types.WriteError(w, http.StatusBadRequest, "Battery level must be between 0 and 100")
+		return
+	}
+
+	if req.PricePlanID != nil && *req.PricePlanID <= 0 {
+		log.Warn().Int("price_plan_id", *req.PricePlanID).Msg("Invalid price plan ID")
+		types.WriteError(w, http.StatusBadRequest, "Price plan ID must be a positive integer")
+		return
+	}
+
+	bike, err := h.adminService.CreateBike(r.Context(), req.Latitude, req.Longitude, req.PricePerMinute, req.PricePlanID, bikeType, req.BatteryLevel)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to create bike")
-		types.WriteError(w, http.StatusInternalServerError, "Error creating bike")
+		logServiceError(&log, err).Msg("Failed to create bike")
+		types.WriteProblem(w, err, "Error creating bike")
 		return
 	}
 
-	log.Info().Int("bike_id", bike.ID).Float64("latitude", bike.Latitude).Float64("longitude", bike.Longitude).Msg("Bike created successfully")
+	log.Info().Int("admin_id", claims.Sub).Int("bike_id", bike.ID).Float64("latitude", bike.Latitude).Float64("longitude", bike.Longitude).Msg("Bike created successfully")
 	types.WriteSuccess(w, "Bike created successfully", bike)
 }
 
 // UpdateBike godoc
 // @Summary Update bike (Admin)
-// @Description Update bike details like location, availability, or price (requires admin authentication)
+// @Description Update bike details like location, availability, price, type or battery level (requires admin authentication)
 // @Tags admin
 // @Accept json
 // @Produce json
 // @Param bike-id path int true "Bike ID"
 // @Param bike body types.UpdateBikeRequest true "Bike update data"
-// @Security BasicAuth
+// @Security BearerAuth
 // @Success 200 {object} types.SuccessResponse{data=models.Bike} "Bike updated successfully"
-// @Failure 400 {object} types.ErrorResponse "Invalid bike ID or update data"
-// @Failure 401 {object} types.ErrorResponse "Unauthorized"
-// @Failure 404 {object} types.ErrorResponse "Bike not found"
-// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 400 {object} types.Problem "Invalid bike ID or update data"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 403 {object} types.Problem "Forbidden - admin role lacks permission"
+// @Failure 404 {object} types.Problem "Bike not found"
+// @Failure 500 {object} types.Problem "Internal server error"
 // @Router /admin/bikes/{bike-id} [patch]
 func (h *AdminHandler) UpdateBike(w http.ResponseWriter, r *http.Request) {
-	log := logger.Get()
+	log := logger.FromContext(r.Context())
 
-	authHeader := r.Header.Get("Authorization")
-	if err := utils.ValidateAdminBasicAuth(authHeader); err != nil {
-		log.Warn().Err(err).Msg("Unauthorized admin attempt to update bike")
-		w.Header().Set("WWW-Authenticate", `Basic realm="Admin Access"`)
-		types.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+	claims, ok := currentAdmin(w, r)
+	if !ok {
 		return
 	}
 
@@ -141,7 +161,8 @@ func (h *AdminHandler) UpdateBike(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Latitude == nil && req.Longitude == nil && req.IsAvailable == nil && req.PricePerMinute == nil {
+	if req.Latitude == nil && req.Longitude == nil && req.IsAvailable == nil && req.PricePerMinute == nil && req.PricePlanID == nil &&
+		req.Type == nil && req.BatteryLevel == nil {
 		log.Warn().Int("bike_id", bikeID).Msg("No fields provided for update")
 		types.WriteError(w, http.StatusBadRequest, "At least one field must be provided for update")
 		return
@@ -171,45 +192,68 @@ func (h *AdminHandler) UpdateBike(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	log.Info().Int("bike_id", bikeID).Msg("Admin attempting to update bike")
+	if req.PricePlanID != nil && *req.PricePlanID < 0 {
+		log.Warn().Int("price_plan_id", *req.PricePlanID).Int("bike_id", bikeID).Msg("Invalid price plan ID for bike update")
+		types.WriteError(w, http.StatusBadRequest, "Price plan ID must be 0 or a positive integer")
+		return
+	}
 
-	bike, err := h.adminService.UpdateBike(bikeID, req.Latitude, req.Longitude, req.IsAvailable, req.PricePerMinute)
-	if err != nil {
-		if err.Error() == "bike with id "+bikeIDStr+" not found" {
-			log.Warn().Int("bike_id", bikeID).Msg("Bike not found for update")
-			types.WriteError(w, http.StatusNotFound, "Bike not found")
+	var bikeType *models.BikeType
+	if req.Type != nil {
+		t := models.BikeType(*req.Type)
+		if !t.IsValid() {
+			log.Warn().Str("type", *req.Type).Int("bike_id", bikeID).Msg("Invalid bike type for bike update")
+			types.WriteError(w, http.StatusBadRequest, "Type must be classic, ebike or cargo")
 			return
 		}
-		log.Error().Err(err).Int("bike_id", bikeID).Msg("Error updating bike")
-		types.WriteError(w, http.StatusInternalServerError, "Error updating bike")
+		bikeType = &t
+	}
+
+	if req.BatteryLevel != nil && (*req.BatteryLevel < 0 || *req.BatteryLevel > 100) {
+		log.Warn().Int("battery_level", *req.BatteryLevel).Int("bike_id", bikeID).Msg("Invalid battery level for bike update")
+		types.WriteError(w, http.StatusBadRequest, "Battery level must be between 0 and 100")
+		return
+	}
+
+	log.Info().Int("bike_id", bikeID).Msg("Admin attempting to update bike")
+
+	bike, err := h.adminService.UpdateBike(r.Context(), bikeID, req.Latitude, req.Longitude, req.IsAvailable, req.PricePerMinute, req.PricePlanID, bikeType, req.BatteryLevel)
+	if err != nil {
+		logServiceError(&log, err).Int("bike_id", bikeID).Msg("Error updating bike")
+		types.WriteProblem(w, err, "Error updating bike")
 		return
 	}
 
-	log.Info().Int("bike_id", bikeID).Msg("Bike updated successfully by admin")
+	log.Info().Int("admin_id", claims.Sub).Int("bike_id", bikeID).Msg("Bike updated successfully by admin")
 	types.WriteSuccess(w, "Bike updated successfully", bike)
 }
 
 // GetAllBikes godoc
 // @Summary Get all bikes (Admin)
-// @Description Get paginated list of all bikes in the system (requires admin authentication)
+// @Description Get a paginated, filterable list of all bikes in the system (requires admin authentication)
 // @Tags admin
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page (max 100)" default(20)
-// @Security BasicAuth
+// @Param is_available query bool false "Filter by availability"
+// @Param price_plan_id query int false "Filter by price plan"
+// @Param created_at[gte] query string false "Created at or after (RFC 3339 or YYYY-MM-DD); also [gt], [lt], [lte]"
+// @Param sort query string false "Comma-separated sort fields, prefix with - for descending (id, price_per_minute, created_at, updated_at)"
+// @Param cursor query string false "Keyset pagination cursor; send it empty for the first page (page is then ignored)"
+// @Security BearerAuth
 // @Success 200 {object} types.PaginatedResponse{data=[]models.Bike} "All bikes retrieved successfully"
-// @Failure 401 {object} types.ErrorResponse "Unauthorized"
-// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Success 200 {object} types.CursorPaginatedResponse{data=[]models.Bike} "Page of bikes when cursor is set"
+// @Failure 400 {object} types.Problem "Invalid filter or sort parameter"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 403 {object} types.Problem "Forbidden - admin role lacks permission"
+// @Failure 500 {object} types.Problem "Internal server error"
 // @Router /admin/bikes [get]
 func (h *AdminHandler) GetAllBikes(w http.ResponseWriter, r *http.Request) {
-	log := logger.Get()
+	log := logger.FromContext(r.Context())
 
-	authHeader := r.Header.Get("Authorization")
-	if err := utils.ValidateAdminBasicAuth(authHeader); err != nil {
-		log.Warn().Err(err).Msg("Unauthorized admin attempt to get all bikes")
-		w.Header().Set("WWW-Authenticate", `Basic realm="Admin Access"`)
-		types.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+	_, ok := currentAdmin(w, r)
+	if !ok {
 		return
 	}
 
@@ -227,12 +271,32 @@ func (h *AdminHandler) GetAllBikes(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	spec, validationErrors := queryspec.Parse(r.URL.Query(), queryspec.Bikes)
+	if validationErrors != nil {
+		log.Warn().Interface("errors", validationErrors).Msg("Invalid bike list query")
+		types.WriteValidationErrors(w, validationErrors)
+		return
+	}
+
 	log.Info().Int("page", page).Int("limit", limit).Msg("Admin fetching all bikes")
 
-	bikes, total, err := h.adminService.GetAllBikes(page, limit)
+	if spec.Cursor != nil {
+		bikes, cursors, err := h.adminService.GetAllBikesByCursor(r.Context(), spec, limit)
+		if err != nil {
+			logServiceError(&log, err).Int("limit", limit).Msg("Error retrieving bikes for admin")
+			types.WriteProblem(w, err, "Error retrieving bikes")
+			return
+		}
+
+		log.Info().Int("returned", len(bikes)).Int("limit", limit).Msg("All bikes retrieved successfully by admin")
+		types.WriteCursorPaginatedSuccess(w, "All bikes retrieved successfully", bikes, limit, cursors.Next, cursors.Prev)
+		return
+	}
+
+	bikes, total, err := h.adminService.GetAllBikes(r.Context(), spec, page, limit)
 	if err != nil {
-		log.Error().Err(err).Int("page", page).Int("limit", limit).Msg("Error retrieving bikes for admin")
-		types.WriteError(w, http.StatusInternalServerError, "Error retrieving bikes")
+		logServiceError(&log, err).Int("page", page).Int("limit", limit).Msg("Error retrieving bikes for admin")
+		types.WriteProblem(w, err, "Error retrieving bikes")
 		return
 	}
 
@@ -242,25 +306,30 @@ func (h *AdminHandler) GetAllBikes(w http.ResponseWriter, r *http.Request) {
 
 // GetAllUsers godoc
 // @Summary Get all users (Admin)
-// @Description Get paginated list of all users in the system (requires admin authentication)
+// @Description Get a paginated, searchable list of all users in the system (requires admin authentication)
 // @Tags admin
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page (max 100)" default(20)
-// @Security BasicAuth
+// @Param q query string false "Case-insensitive search on email, first and last name"
+// @Param email query string false "Filter by exact email"
+// @Param created_at[gte] query string false "Registered at or after (RFC 3339 or YYYY-MM-DD); also [gt], [lt], [lte]"
+// @Param sort query string false "Comma-separated sort fields, prefix with - for descending (id, email, first_name, last_name, created_at)"
+// @Param cursor query string false "Keyset pagination cursor; send it empty for the first page (page is then ignored)"
+// @Security BearerAuth
 // @Success 200 {object} types.PaginatedResponse{data=[]models.User} "All users retrieved successfully"
-// @Failure 401 {object} types.ErrorResponse "Unauthorized"
-// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Success 200 {object} types.CursorPaginatedResponse{data=[]models.User} "Page of users when cursor is set"
+// @Failure 400 {object} types.Problem "Invalid filter or sort parameter"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 403 {object} types.Problem "Forbidden - admin role lacks permission"
+// @Failure 500 {object} types.Problem "Internal server error"
 // @Router /admin/users [get]
 func (h *AdminHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
-	log := logger.Get()
+	log := logger.FromContext(r.Context())
 
-	authHeader := r.Header.Get("Authorization")
-	if err := utils.ValidateAdminBasicAuth(authHeader); err != nil {
-		log.Warn().Err(err).Msg("Unauthorized admin attempt to get all users")
-		w.Header().Set("WWW-Authenticate", `Basic realm="Admin Access"`)
-		types.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+	_, ok := currentAdmin(w, r)
+	if !ok {
 		return
 	}
 
@@ -278,12 +347,32 @@ func (h *AdminHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	spec, validationErrors := queryspec.Parse(r.URL.Query(), queryspec.Users)
+	if validationErrors != nil {
+		log.Warn().Interface("errors", validationErrors).Msg("Invalid user list query")
+		types.WriteValidationErrors(w, validationErrors)
+		return
+	}
+
 	log.Info().Int("page", page).Int("limit", limit).Msg("Admin fetching all users")
 
-	users, total, err := h.adminService.GetAllUsers(page, limit)
+	if spec.Cursor != nil {
+		users, cursors, err := h.adminService.GetAllUsersByCursor(r.Context(), spec, limit)
+		if err != nil {
+			logServiceError(&log, err).Int("limit", limit).Msg("Error retrieving users for admin")
+			types.WriteProblem(w, err, "Error retrieving users")
+			return
+		}
+
+		log.Info().Int("returned", len(users)).Int("limit", limit).Msg("All users retrieved successfully by admin")
+		types.WriteCursorPaginatedSuccess(w, "All users retrieved successfully", users, limit, cursors.Next, cursors.Prev)
+		return
+	}
+
+	users, total, err := h.adminService.GetAllUsers(r.Context(), spec, page, limit)
 	if err != nil {
-		log.Error().Err(err).Int("page", page).Int("limit", limit).Msg("Error retrieving users for admin")
-		types.WriteError(w, http.StatusInternalServerError, "Error retrieving users")
+		logServiceError(&log, err).Int("page", page).Int("limit", limit).Msg("Error retrieving users for admin")
+		types.WriteProblem(w, err, "Error retrieving users")
 		return
 	}
 
@@ -298,20 +387,18 @@ func (h *AdminHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param user-id path int true "User ID"
-// @Security BasicAuth
+// @Security BearerAuth
 // @Success 200 {object} types.SuccessResponse{data=models.User} "User details retrieved successfully"
-// @Failure 400 {object} types.ErrorResponse "Invalid user ID"
-// @Failure 401 {object} types.ErrorResponse "Unauthorized"
-// @Failure 404 {object} types.ErrorResponse "User not found"
+// @Failure 400 {object} types.Problem "Invalid user ID"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 403 {object} types.Problem "Forbidden - admin role lacks permission"
+// @Failure 404 {object} types.Problem "User not found"
 // @Router /admin/users/{user-id} [get]
 func (h *AdminHandler) GetUserDetails(w http.ResponseWriter, r *http.Request) {
-	log := logger.Get()
+	log := logger.FromContext(r.Context())
 
-	authHeader := r.Header.Get("Authorization")
-	if err := utils.ValidateAdminBasicAuth(authHeader); err != nil {
-		log.Warn().Err(err).Msg("Unauthorized admin attempt to get user details")
-		w.Header().Set("WWW-Authenticate", `Basic realm="Admin Access"`)
-		types.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+	_, ok := currentAdmin(w, r)
+	if !ok {
 		return
 	}
 
@@ -325,10 +412,10 @@ func (h *AdminHandler) GetUserDetails(w http.ResponseWriter, r *http.Request) {
 
 	log.Info().Int("user_id", userID).Msg("Admin fetching user details")
 
-	user, err := h.adminService.GetUserByID(userID)
+	user, err := h.adminService.GetUserByID(r.Context(), userID)
 	if err != nil {
-		log.Warn().Err(err).Int("user_id", userID).Msg("User not found for admin")
-		types.WriteError(w, http.StatusNotFound, "User not found")
+		logServiceError(&log, err).Int("user_id", userID).Msg("Error retrieving user for admin")
+		types.WriteProblem(w, err, "Error retrieving user")
 		return
 	}
 
@@ -344,21 +431,19 @@ func (h *AdminHandler) GetUserDetails(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param user-id path int true "User ID"
 // @Param user body types.AdminUpdateUserRequest true "User update data"
-// @Security BasicAuth
+// @Security BearerAuth
 // @Success 200 {object} types.SuccessResponse{data=models.User} "User updated successfully"
-// @Failure 400 {object} types.ErrorResponse "Invalid user ID or update data"
-// @Failure 401 {object} types.ErrorResponse "Unauthorized"
-// @Failure 409 {object} types.ErrorResponse "Email already exists"
-// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 400 {object} types.Problem "Invalid user ID or update data"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 403 {object} types.Problem "Forbidden - admin role lacks permission"
+// @Failure 409 {object} types.Problem "Email already exists"
+// @Failure 500 {object} types.Problem "Internal server error"
 // @Router /admin/users/{user-id} [patch]
 func (h *AdminHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
-	log := logger.Get()
+	log := logger.FromContext(r.Context())
 
-	authHeader := r.Header.Get("Authorization")
-	if err := utils.ValidateAdminBasicAuth(authHeader); err != nil {
-		log.Warn().Err(err).Msg("Unauthorized admin attempt to update user")
-		w.Header().Set("WWW-Authenticate", `Basic realm="Admin Access"`)
-		types.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+	claims, ok := currentAdmin(w, r)
+	if !ok {
 		return
 	}
 
@@ -389,6 +474,7 @@ func (h *AdminHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		if err != nil {
 			log.Error().Err(err).Int("user_id", userID).Msg("Error hashing password for admin user update")
 			types.WriteError(w, http.StatusInternalServerError, "Error processing password")
+
 			return
 		}
 		hashedPassword = &hashed
@@ -396,43 +482,44 @@ func (h *AdminHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 
 	log.Info().Int("user_id", userID).Msg("Admin attempting to update user")
 
-	user, err := h.adminService.UpdateUser(userID, req.Email, req.FirstName, req.LastName, hashedPassword)
+	user, err := h.adminService.UpdateUser(r.Context(), userID, req.Email, req.FirstName, req.LastName, hashedPassword)
 	if err != nil {
-		if err == constants.ErrEmailAlreadyExists {
-			log.Warn().Int("user_id", userID).Msg("Email already exists for admin user update")
-			types.WriteError(w, http.StatusConflict, "Email already exists")
-			return
-		}
-		log.Error().Err(err).Int("user_id", userID).Msg("Error updating user by admin")
-		types.WriteError(w, http.StatusInternalServerError, "Error updating user")
+		logServiceError(&log, err).Int("user_id", userID).Msg("Error updating user by admin")
+		types.WriteProblem(w, err, "Error updating user")
 		return
 	}
 
-	log.Info().Int("user_id", userID).Str("email", user.Email).Msg("User updated successfully by admin")
+	log.Info().Int("admin_id", claims.Sub).Int("user_id", userID).Str("email", user.Email).Msg("User updated successfully by admin")
 	types.WriteSuccess(w, "User updated successfully", user)
 }
 
 // GetAllRentals godoc
 // @Summary Get all rentals (Admin)
-// @Description Get paginated list of all rentals in the system (requires admin authentication)
+// @Description Get a paginated, filterable list of all rentals in the system (requires admin authentication)
 // @Tags admin
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page (max 100)" default(20)
-// @Security BasicAuth
+// @Param status query string false "Filter by status" Enums(running, paused, ended, cancelled, refunded)
+// @Param user_id query int false "Filter by user"
+// @Param bike_id query int false "Filter by bike"
+// @Param start_time[gte] query string false "Started at or after (RFC 3339 or YYYY-MM-DD); also [gt], [lt], [lte] and end_time[...]"
+// @Param sort query string false "Comma-separated sort fields, prefix with - for descending (id, start_time, end_time, cost)"
+// @Param cursor query string false "Keyset pagination cursor; send it empty for the first page (page is then ignored)"
+// @Security BearerAuth
 // @Success 200 {object} types.PaginatedResponse{data=[]models.Rental} "All rentals retrieved successfully"
-// @Failure 401 {object} types.ErrorResponse "Unauthorized"
-// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Success 200 {object} types.CursorPaginatedResponse{data=[]models.Rental} "Page of rentals when cursor is set"
+// @Failure 400 {object} types.Problem "Invalid filter or sort parameter"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 403 {object} types.Problem "Forbidden - admin role lacks permission"
+// @Failure 500 {object} types.Problem "Internal server error"
 // @Router /admin/rentals [get]
 func (h *AdminHandler) GetAllRentals(w http.ResponseWriter, r *http.Request) {
-	log := logger.Get()
+	log := logger.FromContext(r.Context())
 
-	authHeader := r.Header.Get("Authorization")
-	if err := utils.ValidateAdminBasicAuth(authHeader); err != nil {
-		log.Warn().Err(err).Msg("Unauthorized admin attempt to get all rentals")
-		w.Header().Set("WWW-Authenticate", `Basic realm="Admin Access"`)
-		types.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+	_, ok := currentAdmin(w, r)
+	if !ok {
 		return
 	}
 
@@ -450,12 +537,32 @@ func (h *AdminHandler) GetAllRentals(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	spec, validationErrors := queryspec.Parse(r.URL.Query(), queryspec.Rentals)
+	if validationErrors != nil {
+		log.Warn().Interface("errors", validationErrors).Msg("Invalid rental list query")
+		types.WriteValidationErrors(w, validationErrors)
+		return
+	}
+
 	log.Info().Int("page", page).Int("limit", limit).Msg("Admin fetching all rentals")
 
-	rentals, total, err := h.adminService.GetAllRentals(page, limit)
+	if spec.Cursor != nil {
+		rentals, cursors, err := h.adminService.GetAllRentalsByCursor(r.Context(), spec, limit)
+		if err != nil {
+			logServiceError(&log, err).Int("limit", limit).Msg("Error retrieving rentals for admin")
+			types.WriteProblem(w, err, "Error retrieving rentals")
+			return
+		}
+
+		log.Info().Int("returned", len(rentals)).Int("limit", limit).Msg("All rentals retrieved successfully by admin")
+		types.WriteCursorPaginatedSuccess(w, "All rentals retrieved successfully", rentals, limit, cursors.Next, cursors.Prev)
+		return
+	}
+
+	rentals, total, err := h.adminService.GetAllRentals(r.Context(), spec, page, limit)
 	if err != nil {
-		log.Error().Err(err).Int("page", page).Int("limit", limit).Msg("Error retrieving rentals for admin")
-		types.WriteError(w, http.StatusInternalServerError, "Error retrieving rentals")
+		logServiceError(&log, err).Int("page", page).Int("limit", limit).Msg("Error retrieving rentals for admin")
+		types.WriteProblem(w, err, "Error retrieving rentals")
 		return
 	}
 
@@ -470,20 +577,18 @@ func (h *AdminHandler) GetAllRentals(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param rental-id path int true "Rental ID"
-// @Security BasicAuth
+// @Security BearerAuth
 // @Success 200 {object} types.SuccessResponse{data=models.Rental} "Rental details retrieved successfully"
-// @Failure 400 {object} types.ErrorResponse "Invalid rental ID"
-// @Failure 401 {object} types.ErrorResponse "Unauthorized"
-// @Failure 404 {object} types.ErrorResponse "Rental not found"
+// @Failure 400 {object} types.Problem "Invalid rental ID"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 403 {object} types.Problem "Forbidden - admin role lacks permission"
+// @Failure 404 {object} types.Problem "Rental not found"
 // @Router /admin/rentals/{rental-id} [get]
 func (h *AdminHandler) GetRentalDetails(w http.ResponseWriter, r *http.Request) {
-	log := logger.Get()
+	log := logger.FromContext(r.Context())
 
-	authHeader := r.Header.Get("Authorization")
-	if err := utils.ValidateAdminBasicAuth(authHeader); err != nil {
-		log.Warn().Err(err).Msg("Unauthorized admin attempt to get rental details")
-		w.Header().Set("WWW-Authenticate", `Basic realm="Admin Access"`)
-		types.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+	_, ok := currentAdmin(w, r)
+	if !ok {
 		return
 	}
 
@@ -497,10 +602,10 @@ func (h *AdminHandler) GetRentalDetails(w http.ResponseWriter, r *http.Request)
 
 	log.Info().Int("rental_id", rentalID).Msg("Admin fetching rental details")
 
-	rental, err := h.adminService.GetRentalByID(rentalID)
+	rental, err := h.adminService.GetRentalByID(r.Context(), rentalID)
 	if err != nil {
-		log.Warn().Err(err).Int("rental_id", rentalID).Msg("Rental not found for admin")
-		types.WriteError(w, http.StatusNotFound, "Rental not found")
+		logServiceError(&log, err).Int("rental_id", rentalID).Msg("Error retrieving rental for admin")
+		types.WriteProblem(w, err, "Error retrieving rental")
 		return
 	}
 
@@ -510,26 +615,26 @@ func (h *AdminHandler) GetRentalDetails(w http.ResponseWriter, r *http.Request)
 
 // UpdateRental godoc
 // @Summary Update rental (Admin)
-// @Description Update rental status (requires admin authentication)
+// @Description Move a rental to a new status following the rental state machine (requires admin authentication)
 // @Tags admin
 // @Accept json
 // @Produce json
 // @Param rental-id path int true "Rental ID"
 // @Param rental body types.UpdateRentalRequest true "Rental update data with status"
-// @Security BasicAuth
+// @Security BearerAuth
 // @Success 200 {object} types.SuccessResponse{data=models.Rental} "Rental updated successfully"
-// @Failure 400 {object} types.ErrorResponse "Invalid rental ID or status required"
-// @Failure 401 {object} types.ErrorResponse "Unauthorized"
-// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 400 {object} types.Problem "Invalid rental ID or status required"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 403 {object} types.Problem "Forbidden - admin role lacks permission"
+// @Failure 404 {object} types.Problem "Rental not found"
+// @Failure 422 {object} types.Problem "Status change not allowed from the current status"
+// @Failure 500 {object} types.Problem "Internal server error"
 // @Router /admin/rentals/{rental-id} [patch]
 func (h *AdminHandler) UpdateRental(w http.ResponseWriter, r *http.Request) {
-	log := logger.Get()
+	log := logger.FromContext(r.Context())
 
-	authHeader := r.Header.Get("Authorization")
-	if err := utils.ValidateAdminBasicAuth(authHeader); err != nil {
-		log.Warn().Err(err).Msg("Unauthorized admin attempt to update rental")
-		w.Header().Set("WWW-Authenticate", `Basic realm="Admin Access"`)
-		types.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+	claims, ok := currentAdmin(w, r)
+	if !ok {
 		return
 	}
 
@@ -556,13 +661,13 @@ func (h *AdminHandler) UpdateRental(w http.ResponseWriter, r *http.Request) {
 
 	log.Info().Int("rental_id", rentalID).Str("new_status", *req.Status).Msg("Admin attempting to update rental")
 
-	rental, err := h.adminService.UpdateRental(rentalID, req.Status)
+	rental, err := h.adminService.UpdateRental(r.Context(), rentalID, models.RentalStatus(*req.Status))
 	if err != nil {
-		log.Error().Err(err).Int("rental_id", rentalID).Str("status", *req.Status).Msg("Error updating rental by admin")
-		types.WriteError(w, http.StatusInternalServerError, "Error updating rental")
+		logServiceError(&log, err).Int("rental_id", rentalID).Str("status", *req.Status).Msg("Error updating rental by admin")
+		types.WriteProblem(w, err, "Error updating rental")
 		return
 	}
 
-	log.Info().Int("rental_id", rentalID).Str("status", rental.Status).Msg("Rental updated successfully by admin")
+	log.Info().Int("admin_id", claims.Sub).Int("rental_id", rentalID).Str("status", string(rental.Status)).Msg("Rental updated successfully by admin")
 	types.WriteSuccess(w, "Rental updated successfully", rental)
 }
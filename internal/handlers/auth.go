@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Nimirandad/bike-rental-service/internal/auth"
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/logger"
+	"github.com/Nimirandad/bike-rental-service/internal/types"
+	"github.com/Nimirandad/bike-rental-service/internal/utils"
+)
+
+// currentUser returns the rider authenticated by middlewares.RequireUser. It
+// writes a 401 when the route was not wrapped by that middleware.
+func currentUser(w http.ResponseWriter, r *http.Request) (*utils.JWTClaims, bool) {
+	claims, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		log := logger.FromContext(r.Context())
+		log.Warn().Str("path", r.URL.Path).Msg("No authenticated user in request context")
+		types.WriteProblem(w, constants.ErrMissingToken, "")
+	}
+	return claims, ok
+}
+
+// currentAdmin returns the admin authenticated by middlewares.RequireAdmin. It
+// writes a 401 when the route was not wrapped by that middleware.
+func currentAdmin(w http.ResponseWriter, r *http.Request) (*utils.AdminJWTClaims, bool) {
+	claims, ok := auth.AdminFromContext(r.Context())
+	if !ok {
+		log := logger.FromContext(r.Context())
+		log.Warn().Str("path", r.URL.Path).Msg("No authenticated admin in request context")
+		w.Header().Set("WWW-Authenticate", `Bearer realm="Admin Access"`)
+		types.WriteProblem(w, constants.ErrMissingToken, "")
+	}
+	return claims, ok
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/auth"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/utils"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// withUser returns req as middlewares.RequireUser would pass it on for user.
+func withUser(req *http.Request, user *models.User) *http.Request {
+	claims := &utils.JWTClaims{
+		Sub:       user.ID,
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "test-jti",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}
+	return req.WithContext(auth.WithUser(req.Context(), claims))
+}
+
+// withAdmin returns req as middlewares.RequireAdmin would pass it on for an
+// admin with role.
+func withAdmin(req *http.Request, role models.AdminRole) *http.Request {
+	claims := &utils.AdminJWTClaims{Sub: 1, Email: "admin@example.com", Role: role}
+	return req.WithContext(auth.WithAdmin(req.Context(), claims))
+}
+
+func TestCurrentUser_MissingFromContext(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/users/profile", nil)
+	w := httptest.NewRecorder()
+
+	claims, ok := currentUser(w, req)
+
+	assert.False(t, ok)
+	assert.Nil(t, claims)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestCurrentAdmin_IgnoresUserPrincipal(t *testing.T) {
+	req := withUser(httptest.NewRequest(http.MethodGet, "/admin/bikes", nil), &models.User{ID: 1})
+	w := httptest.NewRecorder()
+
+	claims, ok := currentAdmin(w, req)
+
+	assert.False(t, ok)
+	assert.Nil(t, claims)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Nimirandad/bike-rental-service/internal/logger"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/services"
+	"github.com/Nimirandad/bike-rental-service/internal/types"
+)
+
+type BikeTypePriceService interface {
+	GetBikeTypePrices(ctx context.Context) ([]*models.BikeTypePrice, error)
+	UpdateBikeTypePrice(ctx context.Context, bikeType models.BikeType, pricePerMinute float64) (*models.BikeTypePrice, error)
+}
+
+type BikeTypePriceHandler struct {
+	bikeTypePriceService BikeTypePriceService
+}
+
+func NewBikeTypePriceHandler(bikeTypePriceService *services.BikeTypePriceService) *BikeTypePriceHandler {
+	return &BikeTypePriceHandler{
+		bikeTypePriceService: bikeTypePriceService,
+	}
+}
+
+// GetBikeTypePrices godoc
+// @Summary List default prices per bike type (Admin)
+// @Description Get the price per minute given to new bikes of each type when none is set (requires admin authentication)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} types.SuccessResponse{data=[]models.BikeTypePrice} "Bike type prices retrieved successfully"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 403 {object} types.Problem "Forbidden - admin role lacks permission"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /admin/bike-types [get]
+func (h *BikeTypePriceHandler) GetBikeTypePrices(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	if _, ok := currentAdmin(w, r); !ok {
+		return
+	}
+
+	prices, err := h.bikeTypePriceService.GetBikeTypePrices(r.Context())
+	if err != nil {
+		logServiceError(&log, err).Msg("Error retrieving bike type prices")
+		types.WriteProblem(w, err, "Error retrieving bike type prices")
+		return
+	}
+
+	types.WriteSuccess(w, "Bike type prices retrieved successfully", prices)
+}
+
+// UpdateBikeTypePrice godoc
+// @Summary Set the default price of a bike type (Admin)
+// @Description Set the price per minute given to new bikes of the type when none is set. Existing bikes keep their price (requires admin authentication)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param bike-type path string true "Bike type" Enums(classic, ebike, cargo)
+// @Param price body types.BikeTypePriceRequest true "Default price"
+// @Security BearerAuth
+// @Success 200 {object} types.SuccessResponse{data=models.BikeTypePrice} "Bike type price updated successfully"
+// @Failure 400 {object} types.Problem "Invalid bike type or price"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 403 {object} types.Problem "Forbidden - admin role lacks permission"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /admin/bike-types/{bike-type} [put]
+func (h *BikeTypePriceHandler) UpdateBikeTypePrice(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	claims, ok := currentAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	bikeType := models.BikeType(r.PathValue("bike-type"))
+	if !bikeType.IsValid() {
+		log.Warn().Str("bike_type", string(bikeType)).Msg("Invalid bike type")
+		types.WriteError(w, http.StatusBadRequest, "Bike type must be classic, ebike or cargo")
+		return
+	}
+
+	var req types.BikeTypePriceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Warn().Err(err).Str("bike_type", string(bikeType)).Msg("Failed to decode bike type price request")
+		types.WriteError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if req.PricePerMinute <= 0 {
+		log.Warn().Float64("price", req.PricePerMinute).Str("bike_type", string(bikeType)).Msg("Invalid bike type price")
+		types.WriteError(w, http.StatusBadRequest, "Price per minute must be greater than 0")
+		return
+	}
+
+	price, err := h.bikeTypePriceService.UpdateBikeTypePrice(r.Context(), bikeType, req.PricePerMinute)
+	if err != nil {
+		logServiceError(&log, err).Str("bike_type", string(bikeType)).Msg("Error updating bike type price")
+		types.WriteProblem(w, err, "Error updating bike type price")
+		return
+	}
+
+	log.Info().Int("admin_id", claims.Sub).Str("bike_type", string(bikeType)).Float64("price_per_minute", price.PricePerMinute).Msg("Bike type price updated successfully")
+	types.WriteSuccess(w, "Bike type price updated successfully", price)
+}
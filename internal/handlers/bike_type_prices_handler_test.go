@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockBikeTypePriceService struct {
+	GetBikeTypePricesFunc   func() ([]*models.BikeTypePrice, error)
+	UpdateBikeTypePriceFunc func(bikeType models.BikeType, pricePerMinute float64) (*models.BikeTypePrice, error)
+}
+
+func (m *MockBikeTypePriceService) GetBikeTypePrices(ctx context.Context) ([]*models.BikeTypePrice, error) {
+	return m.GetBikeTypePricesFunc()
+}
+
+func (m *MockBikeTypePriceService) UpdateBikeTypePrice(ctx context.Context, bikeType models.BikeType, pricePerMinute float64) (*models.BikeTypePrice, error) {
+	return m.UpdateBikeTypePriceFunc(bikeType, pricePerMinute)
+}
+
+func TestBikeTypePriceHandler_GetBikeTypePrices(t *testing.T) {
+	mockService := &MockBikeTypePriceService{
+		GetBikeTypePricesFunc: func() ([]*models.BikeTypePrice, error) {
+			return []*models.BikeTypePrice{
+				{Type: models.BikeTypeClassic, PricePerMinute: 0.5},
+				{Type: models.BikeTypeEBike, PricePerMinute: 0.8},
+			}, nil
+		},
+	}
+
+	handler := &BikeTypePriceHandler{bikeTypePriceService: mockService}
+	req := httptest.NewRequest(http.MethodGet, "/admin/bike-types", nil)
+	req = withAdmin(req, models.AdminRoleFinance)
+	w := httptest.NewRecorder()
+
+	handler.GetBikeTypePrices(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data []models.BikeTypePrice `json:"data"`
+	}
+	json.NewDecoder(w.Body).Decode(&response)
+	assert.Len(t, response.Data, 2)
+	assert.Equal(t, 0.8, response.Data[1].PricePerMinute)
+}
+
+func TestBikeTypePriceHandler_GetBikeTypePrices_ServiceError(t *testing.T) {
+	mockService := &MockBikeTypePriceService{
+		GetBikeTypePricesFunc: func() ([]*models.BikeTypePrice, error) {
+			return nil, errors.New("database error")
+		},
+	}
+
+	handler := &BikeTypePriceHandler{bikeTypePriceService: mockService}
+	req := httptest.NewRequest(http.MethodGet, "/admin/bike-types", nil)
+	req = withAdmin(req, models.AdminRoleFinance)
+	w := httptest.NewRecorder()
+
+	handler.GetBikeTypePrices(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestBikeTypePriceHandler_UpdateBikeTypePrice(t *testing.T) {
+	mockService := &MockBikeTypePriceService{
+		UpdateBikeTypePriceFunc: func(bikeType models.BikeType, pricePerMinute float64) (*models.BikeTypePrice, error) {
+			assert.Equal(t, models.BikeTypeEBike, bikeType)
+			assert.Equal(t, 0.8, pricePerMinute)
+			return &models.BikeTypePrice{Type: bikeType, PricePerMinute: pricePerMinute, UpdatedAt: time.Now()}, nil
+		},
+	}
+
+	handler := &BikeTypePriceHandler{bikeTypePriceService: mockService}
+	req := httptest.NewRequest(http.MethodPut, "/admin/bike-types/ebike", strings.NewReader(`{"price_per_minute":0.8}`))
+	req.SetPathValue("bike-type", "ebike")
+	req = withAdmin(req, models.AdminRoleFinance)
+	w := httptest.NewRecorder()
+
+	handler.UpdateBikeTypePrice(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"price_per_minute":0.8`)
+}
+
+func TestBikeTypePriceHandler_UpdateBikeTypePrice_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		bikeType string
+		body     string
+	}{
+		{name: "unknown type", bikeType: "scooter", body: `{"price_per_minute":0.8}`},
+		{name: "zero price", bikeType: "cargo", body: `{"price_per_minute":0}`},
+		{name: "invalid JSON", bikeType: "cargo", body: `{"price_per_minute":`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &BikeTypePriceHandler{}
+			req := httptest.NewRequest(http.MethodPut, "/admin/bike-types/"+tt.bikeType, strings.NewReader(tt.body))
+			req.SetPathValue("bike-type", tt.bikeType)
+			req = withAdmin(req, models.AdminRoleFinance)
+			w := httptest.NewRecorder()
+
+			handler.UpdateBikeTypePrice(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 
@@ -9,11 +10,11 @@ import (
 	"github.com/Nimirandad/bike-rental-service/internal/models"
 	"github.com/Nimirandad/bike-rental-service/internal/services"
 	"github.com/Nimirandad/bike-rental-service/internal/types"
-	"github.com/Nimirandad/bike-rental-service/internal/utils"
 )
 
 type BikeService interface {
-	GetAvailableBikes(page, limit int) ([]*models.Bike, int, error)
+	GetAvailableBikes(ctx context.Context, bikeType models.BikeType, page, limit int) ([]*models.Bike, int, error)
+	GetNearbyBikes(ctx context.Context, bikeType models.BikeType, latitude, longitude, radiusKm float64, page, limit int) ([]*models.Bike, int, error)
 }
 
 type BikeHandler struct {
@@ -26,38 +27,27 @@ func NewBikeHandler(bikeService *services.BikeService) *BikeHandler {
 
 // GetAvailableBikes godoc
 // @Summary List available bikes
-// @Description Get paginated list of available bikes for rent
+// @Description Get paginated list of available bikes for rent. When lat and lng are provided, only bikes within radius_km are returned, sorted by distance. E-bikes with a low battery are not listed.
 // @Tags bikes
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page (max 100)" default(20)
+// @Param lat query number false "Latitude of the search center"
+// @Param lng query number false "Longitude of the search center"
+// @Param radius_km query number false "Search radius in km (max 50)" default(1)
+// @Param type query string false "Bike type" Enums(classic, ebike, cargo)
 // @Security BearerAuth
 // @Success 200 {object} types.PaginatedResponse{data=[]models.Bike} "List of available bikes"
-// @Failure 401 {object} types.ErrorResponse "Unauthorized - missing or invalid token"
-// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 400 {object} types.Problem "Invalid search coordinates, radius or type"
+// @Failure 401 {object} types.Problem "Unauthorized - missing or invalid token"
+// @Failure 500 {object} types.Problem "Internal server error"
 // @Router /bikes/available [get]
 func (h *BikeHandler) GetAvailableBikes(w http.ResponseWriter, r *http.Request) {
-	log := logger.Get()
+	log := logger.FromContext(r.Context())
 
-	authHeader := r.Header.Get("Authorization")
-	if authHeader == "" {
-		log.Warn().Msg("Attempt to get bikes without authorization header")
-		types.WriteError(w, http.StatusUnauthorized, "Authorization header is required")
-		return
-	}
-
-	tokenString, err := utils.ExtractTokenFromHeader(authHeader)
-	if err != nil {
-		log.Warn().Err(err).Msg("Invalid authorization header format")
-		types.WriteError(w, http.StatusUnauthorized, err.Error())
-		return
-	}
-
-	_, err = utils.ValidateJWT(tokenString)
-	if err != nil {
-		log.Warn().Err(err).Msg("Invalid or expired token")
-		types.WriteError(w, http.StatusUnauthorized, "Invalid or expired token")
+	_, ok := currentUser(w, r)
+	if !ok {
 		return
 	}
 
@@ -75,15 +65,69 @@ func (h *BikeHandler) GetAvailableBikes(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	log.Info().Int("page", page).Int("limit", limit).Msg("Fetching available bikes")
+	bikeType := models.BikeType(r.URL.Query().Get("type"))
+	if bikeType != "" && !bikeType.IsValid() {
+		log.Warn().Str("type", string(bikeType)).Msg("Invalid bike type filter")
+		types.WriteError(w, http.StatusBadRequest, "type must be classic, ebike or cargo")
+		return
+	}
+
+	latParam := r.URL.Query().Get("lat")
+	lngParam := r.URL.Query().Get("lng")
+	if latParam != "" || lngParam != "" {
+		h.getNearbyBikes(w, r, bikeType, latParam, lngParam, page, limit)
+		return
+	}
+
+	log.Info().Str("type", string(bikeType)).Int("page", page).Int("limit", limit).Msg("Fetching available bikes")
 
-	bikes, total, err := h.bikeService.GetAvailableBikes(page, limit)
+	bikes, total, err := h.bikeService.GetAvailableBikes(r.Context(), bikeType, page, limit)
 	if err != nil {
-		log.Error().Err(err).Int("page", page).Int("limit", limit).Msg("Error retrieving bikes")
-		types.WriteError(w, http.StatusInternalServerError, "Error retrieving bikes")
+		logServiceError(&log, err).Int("page", page).Int("limit", limit).Msg("Error retrieving bikes")
+		types.WriteProblem(w, err, "Error retrieving bikes")
 		return
 	}
 
 	log.Info().Int("total", total).Int("returned", len(bikes)).Int("page", page).Int("limit", limit).Msg("Available bikes retrieved successfully")
 	types.WritePaginatedSuccess(w, "Available bikes retrieved successfully", bikes, total, page, limit)
 }
+
+func (h *BikeHandler) getNearbyBikes(w http.ResponseWriter, r *http.Request, bikeType models.BikeType, latParam, lngParam string, page, limit int) {
+	log := logger.FromContext(r.Context())
+
+	latitude, err := strconv.ParseFloat(latParam, 64)
+	if err != nil || latitude < constants.MinLatitude || latitude > constants.MaxLatitude {
+		log.Warn().Str("lat", latParam).Msg("Invalid latitude for nearby search")
+		types.WriteError(w, http.StatusBadRequest, "lat must be a number between -90 and 90")
+		return
+	}
+
+	longitude, err := strconv.ParseFloat(lngParam, 64)
+	if err != nil || longitude < constants.MinLongitude || longitude > constants.MaxLongitude {
+		log.Warn().Str("lng", lngParam).Msg("Invalid longitude for nearby search")
+		types.WriteError(w, http.StatusBadRequest, "lng must be a number between -180 and 180")
+		return
+	}
+
+	radiusKm := constants.DefaultRadiusKm
+	if radiusParam := r.URL.Query().Get("radius_km"); radiusParam != "" {
+		radiusKm, err = strconv.ParseFloat(radiusParam, 64)
+		if err != nil || radiusKm <= 0 || radiusKm > constants.MaxRadiusKm {
+			log.Warn().Str("radius_km", radiusParam).Msg("Invalid radius for nearby search")
+			types.WriteError(w, http.StatusBadRequest, "radius_km must be greater than 0 and at most 50")
+			return
+		}
+	}
+
+	log.Info().Float64("lat", latitude).Float64("lng", longitude).Float64("radius_km", radiusKm).Int("page", page).Int("limit", limit).Msg("Fetching nearby available bikes")
+
+	bikes, total, err := h.bikeService.GetNearbyBikes(r.Context(), bikeType, latitude, longitude, radiusKm, page, limit)
+	if err != nil {
+		logServiceError(&log, err).Float64("lat", latitude).Float64("lng", longitude).Float64("radius_km", radiusKm).Msg("Error retrieving nearby bikes")
+		types.WriteProblem(w, err, "Error retrieving bikes")
+		return
+	}
+
+	log.Info().Int("total", total).Int("returned", len(bikes)).Float64("radius_km", radiusKm).Msg("Nearby bikes retrieved successfully")
+	types.WritePaginatedSuccess(w, "Available bikes retrieved successfully", bikes, total, page, limit)
+}
@@ -1,34 +1,36 @@
 package handlers
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"testing"
 
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
 	"github.com/Nimirandad/bike-rental-service/internal/models"
-	"github.com/Nimirandad/bike-rental-service/internal/utils"
 	"github.com/stretchr/testify/assert"
 )
 
 type MockBikeService struct {
-	GetAvailableBikesFunc func(page, limit int) ([]*models.Bike, int, error)
+	GetAvailableBikesFunc func(bikeType models.BikeType, page, limit int) ([]*models.Bike, int, error)
+	GetNearbyBikesFunc    func(bikeType models.BikeType, latitude, longitude, radiusKm float64, page, limit int) ([]*models.Bike, int, error)
 }
 
-func (m *MockBikeService) GetAvailableBikes(page, limit int) ([]*models.Bike, int, error) {
-	return m.GetAvailableBikesFunc(page, limit)
+func (m *MockBikeService) GetAvailableBikes(ctx context.Context, bikeType models.BikeType, page, limit int) ([]*models.Bike, int, error) {
+	return m.GetAvailableBikesFunc(bikeType, page, limit)
 }
 
-func TestBikeHandler_GetAvailableBikes_Success(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
+func (m *MockBikeService) GetNearbyBikes(ctx context.Context, bikeType models.BikeType, latitude, longitude, radiusKm float64, page, limit int) ([]*models.Bike, int, error) {
+	return m.GetNearbyBikesFunc(bikeType, latitude, longitude, radiusKm, page, limit)
+}
 
+func TestBikeHandler_GetAvailableBikes_Success(t *testing.T) {
 	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
-	token, _ := utils.GenerateJWT(testUser)
 
 	mockService := &MockBikeService{
-		GetAvailableBikesFunc: func(page, limit int) ([]*models.Bike, int, error) {
+		GetAvailableBikesFunc: func(bikeType models.BikeType, page, limit int) ([]*models.Bike, int, error) {
 			bikes := []*models.Bike{
 				{ID: 1, Latitude: 40.416775, Longitude: -3.703790, IsAvailable: true, PricePerMinute: 0.5},
 				{ID: 2, Latitude: 40.417832, Longitude: -3.705064, IsAvailable: true, PricePerMinute: 0.5},
@@ -40,7 +42,7 @@ func TestBikeHandler_GetAvailableBikes_Success(t *testing.T) {
 	handler := &BikeHandler{bikeService: mockService}
 
 	req := httptest.NewRequest(http.MethodGet, "/api/bikes?page=1&limit=10", nil)
-	req.Header.Set("Authorization", "Bearer "+token)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.GetAvailableBikes(w, req)
@@ -59,76 +61,195 @@ func TestBikeHandler_GetAvailableBikes_NoAuthHeader(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
 
-func TestBikeHandler_GetAvailableBikes_InvalidToken(t *testing.T) {
-	handler := &BikeHandler{}
+func TestBikeHandler_GetAvailableBikes_ServiceError(t *testing.T) {
+	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
+
+	mockService := &MockBikeService{
+		GetAvailableBikesFunc: func(bikeType models.BikeType, page, limit int) ([]*models.Bike, int, error) {
+			return nil, 0, errors.New("database error")
+		},
+	}
+
+	handler := &BikeHandler{bikeService: mockService}
 
 	req := httptest.NewRequest(http.MethodGet, "/api/bikes", nil)
-	req.Header.Set("Authorization", "Bearer invalid-token")
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.GetAvailableBikes(w, req)
 
-	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
 
-func TestBikeHandler_GetAvailableBikes_ServiceError(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
-
+func TestBikeHandler_GetAvailableBikes_QueryTimeout(t *testing.T) {
 	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
-	token, _ := utils.GenerateJWT(testUser)
 
 	mockService := &MockBikeService{
-		GetAvailableBikesFunc: func(page, limit int) ([]*models.Bike, int, error) {
-			return nil, 0, errors.New("database error")
+		GetAvailableBikesFunc: func(bikeType models.BikeType, page, limit int) ([]*models.Bike, int, error) {
+			return nil, 0, fmt.Errorf("error retrieving available bikes: %w", context.DeadlineExceeded)
 		},
 	}
 
 	handler := &BikeHandler{bikeService: mockService}
 
 	req := httptest.NewRequest(http.MethodGet, "/api/bikes", nil)
-	req.Header.Set("Authorization", "Bearer "+token)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.GetAvailableBikes(w, req)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
 }
 
-func TestBikeHandler_GetAvailableBikes_InvalidAuthFormat(t *testing.T) {
-	handler := &BikeHandler{}
+func TestBikeHandler_GetAvailableBikes_WithPagination(t *testing.T) {
+	testUser := &models.User{ID: 1, Email: "test@example.com"}
 
-	req := httptest.NewRequest(http.MethodGet, "/api/bikes", nil)
-	req.Header.Set("Authorization", "InvalidFormat")
+	mockService := &MockBikeService{
+		GetAvailableBikesFunc: func(bikeType models.BikeType, page, limit int) ([]*models.Bike, int, error) {
+			assert.Equal(t, 2, page)
+			assert.Equal(t, 15, limit)
+			return []*models.Bike{}, 0, nil
+		},
+	}
+
+	handler := &BikeHandler{bikeService: mockService}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/bikes?page=2&limit=15", nil)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.GetAvailableBikes(w, req)
 
-	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, http.StatusOK, w.Code)
 }
 
-func TestBikeHandler_GetAvailableBikes_WithPagination(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
+func TestBikeHandler_GetAvailableBikes_Nearby(t *testing.T) {
+	testUser := &models.User{ID: 1, Email: "test@example.com"}
 
+	distance := 0.3
+	mockService := &MockBikeService{
+		GetNearbyBikesFunc: func(bikeType models.BikeType, latitude, longitude, radiusKm float64, page, limit int) ([]*models.Bike, int, error) {
+			assert.Equal(t, 51.5074, latitude)
+			assert.Equal(t, -0.1278, longitude)
+			assert.Equal(t, 2.5, radiusKm)
+			return []*models.Bike{{ID: 1, Latitude: 51.5080, Longitude: -0.1270, IsAvailable: true, DistanceKm: &distance}}, 1, nil
+		},
+	}
+
+	handler := &BikeHandler{bikeService: mockService}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/bikes/available?lat=51.5074&lng=-0.1278&radius_km=2.5", nil)
+	req = withUser(req, testUser)
+	w := httptest.NewRecorder()
+
+	handler.GetAvailableBikes(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"distance_km":0.3`)
+}
+
+func TestBikeHandler_GetAvailableBikes_NearbyDefaultRadius(t *testing.T) {
 	testUser := &models.User{ID: 1, Email: "test@example.com"}
-	token, _ := utils.GenerateJWT(testUser)
 
 	mockService := &MockBikeService{
-		GetAvailableBikesFunc: func(page, limit int) ([]*models.Bike, int, error) {
-			assert.Equal(t, 2, page)
-			assert.Equal(t, 15, limit)
+		GetNearbyBikesFunc: func(bikeType models.BikeType, latitude, longitude, radiusKm float64, page, limit int) ([]*models.Bike, int, error) {
+			assert.Equal(t, constants.DefaultRadiusKm, radiusKm)
 			return []*models.Bike{}, 0, nil
 		},
 	}
 
 	handler := &BikeHandler{bikeService: mockService}
 
-	req := httptest.NewRequest(http.MethodGet, "/api/bikes?page=2&limit=15", nil)
-	req.Header.Set("Authorization", "Bearer "+token)
+	req := httptest.NewRequest(http.MethodGet, "/api/bikes/available?lat=51.5074&lng=-0.1278", nil)
+	req = withUser(req, testUser)
+	w := httptest.NewRecorder()
+
+	handler.GetAvailableBikes(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestBikeHandler_GetAvailableBikes_NearbyInvalidParams(t *testing.T) {
+	testUser := &models.User{ID: 1, Email: "test@example.com"}
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "Missing longitude", query: "lat=51.5074"},
+		{name: "Missing latitude", query: "lng=-0.1278"},
+		{name: "Latitude out of range", query: "lat=91&lng=-0.1278"},
+		{name: "Longitude not a number", query: "lat=51.5074&lng=abc"},
+		{name: "Radius too large", query: "lat=51.5074&lng=-0.1278&radius_km=51"},
+		{name: "Radius not positive", query: "lat=51.5074&lng=-0.1278&radius_km=0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &BikeHandler{}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/bikes/available?"+tt.query, nil)
+			req = withUser(req, testUser)
+			w := httptest.NewRecorder()
+
+			handler.GetAvailableBikes(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestBikeHandler_GetAvailableBikes_NearbyServiceError(t *testing.T) {
+	testUser := &models.User{ID: 1, Email: "test@example.com"}
+
+	mockService := &MockBikeService{
+		GetNearbyBikesFunc: func(bikeType models.BikeType, latitude, longitude, radiusKm float64, page, limit int) ([]*models.Bike, int, error) {
+			return nil, 0, errors.New("database error")
+		},
+	}
+
+	handler := &BikeHandler{bikeService: mockService}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/bikes/available?lat=51.5074&lng=-0.1278", nil)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.GetAvailableBikes(w, req)
 
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestBikeHandler_GetAvailableBikes_TypeFilter(t *testing.T) {
+	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
+
+	var listedType, nearbyType models.BikeType
+	mockService := &MockBikeService{
+		GetAvailableBikesFunc: func(bikeType models.BikeType, page, limit int) ([]*models.Bike, int, error) {
+			listedType = bikeType
+			return []*models.Bike{}, 0, nil
+		},
+		GetNearbyBikesFunc: func(bikeType models.BikeType, latitude, longitude, radiusKm float64, page, limit int) ([]*models.Bike, int, error) {
+			nearbyType = bikeType
+			return []*models.Bike{}, 0, nil
+		},
+	}
+
+	handler := &BikeHandler{bikeService: mockService}
+
+	req := withUser(httptest.NewRequest(http.MethodGet, "/api/v1/bikes/available?type=ebike", nil), testUser)
+	w := httptest.NewRecorder()
+	handler.GetAvailableBikes(w, req)
 	assert.Equal(t, http.StatusOK, w.Code)
-}
\ No newline at end of file
+	assert.Equal(t, models.BikeTypeEBike, listedType)
+
+	req = withUser(httptest.NewRequest(http.MethodGet, "/api/v1/bikes/available?type=cargo&lat=51.5&lng=-0.12", nil), testUser)
+	w = httptest.NewRecorder()
+	handler.GetAvailableBikes(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.BikeTypeCargo, nearbyType)
+
+	req = withUser(httptest.NewRequest(http.MethodGet, "/api/v1/bikes/available?type=scooter", nil), testUser)
+	w = httptest.NewRecorder()
+	handler.GetAvailableBikes(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Nimirandad/bike-rental-service/internal/apperrors"
+	"github.com/Nimirandad/bike-rental-service/internal/types"
+
+	"github.com/rs/zerolog"
+)
+
+// logServiceError starts a log event for an error returned by a service. Typed
+// domain errors are the client's doing and are logged as warnings with their
+// code; anything else is logged as an error.
+func logServiceError(log *zerolog.Logger, err error) *zerolog.Event {
+	if appErr, ok := apperrors.As(err); ok {
+		return log.Warn().Err(err).Str("code", appErr.Code)
+	}
+	return log.Error().Err(err)
+}
+
+// NotFound answers requests that match no route, so they get a problem body
+// like every other error.
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	types.WriteError(w, http.StatusNotFound, "No route matches "+r.URL.Path)
+}
+
+// MethodNotAllowed answers requests whose path matches a route that does not
+// accept the method.
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	types.WriteError(w, http.StatusMethodNotAllowed, "Method "+r.Method+" is not allowed on "+r.URL.Path)
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Nimirandad/bike-rental-service/internal/geojson"
+	"github.com/Nimirandad/bike-rental-service/internal/logger"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/services"
+	"github.com/Nimirandad/bike-rental-service/internal/types"
+)
+
+type GeofenceService interface {
+	ReplaceGeofences(ctx context.Context, kind models.GeofenceKind, geofences []*models.Geofence) ([]*models.Geofence, error)
+	GetGeofences(ctx context.Context, kind models.GeofenceKind) ([]*models.Geofence, error)
+}
+
+type GeofenceHandler struct {
+	geofenceService GeofenceService
+}
+
+func NewGeofenceHandler(geofenceService *services.GeofenceService) *GeofenceHandler {
+	return &GeofenceHandler{
+		geofenceService: geofenceService,
+	}
+}
+
+// GetGeofences godoc
+// @Summary List geofences
+// @Description Get every operating area, no-parking and slow zone as a GeoJSON FeatureCollection
+// @Tags geofences
+// @Produce application/geo+json
+// @Success 200 {object} geojson.FeatureCollection "Geofences"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /geofences [get]
+func (h *GeofenceHandler) GetGeofences(w http.ResponseWriter, r *http.Request) {
+	h.writeGeofences(w, r, "")
+}
+
+// GetAllGeofences godoc
+// @Summary List geofences (Admin)
+// @Description Get the geofences, optionally of one kind, as a GeoJSON FeatureCollection (requires admin authentication)
+// @Tags admin
+// @Produce application/geo+json
+// @Param kind query string false "Geofence kind" Enums(operating_area, no_parking, slow)
+// @Security BearerAuth
+// @Success 200 {object} geojson.FeatureCollection "Geofences"
+// @Failure 400 {object} types.Problem "Invalid geofence kind"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 403 {object} types.Problem "Forbidden - admin role lacks permission"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /admin/geofences [get]
+func (h *GeofenceHandler) GetAllGeofences(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	if _, ok := currentAdmin(w, r); !ok {
+		return
+	}
+
+	kind := models.GeofenceKind(r.URL.Query().Get("kind"))
+	if kind != "" && !kind.IsValid() {
+		log.Warn().Str("kind", string(kind)).Msg("Invalid geofence kind")
+		types.WriteError(w, http.StatusBadRequest, "Kind must be one of operating_area, no_parking or slow")
+		return
+	}
+
+	h.writeGeofences(w, r, kind)
+}
+
+func (h *GeofenceHandler) writeGeofences(w http.ResponseWriter, r *http.Request, kind models.GeofenceKind) {
+	log := logger.FromContext(r.Context())
+
+	geofences, err := h.geofenceService.GetGeofences(r.Context(), kind)
+	if err != nil {
+		logServiceError(&log, err).Str("kind", string(kind)).Msg("Error retrieving geofences")
+		types.WriteProblem(w, err, "Error retrieving geofences")
+		return
+	}
+
+	log.Info().Int("returned", len(geofences)).Msg("Geofences retrieved successfully")
+	geojson.Write(w, http.StatusOK, geojson.FromGeofences(geofences))
+}
+
+// ReplaceGeofences godoc
+// @Summary Replace geofences (Admin)
+// @Description Replace every geofence of a kind with the Polygon and MultiPolygon features of a GeoJSON FeatureCollection. An empty collection removes them (requires admin authentication)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param kind path string true "Geofence kind" Enums(operating_area, no_parking, slow)
+// @Param geofences body geojson.FeatureCollection true "GeoJSON FeatureCollection"
+// @Security BearerAuth
+// @Success 200 {object} types.SuccessResponse{data=geojson.FeatureCollection} "Geofences replaced successfully"
+// @Failure 400 {object} types.Problem "Invalid kind or GeoJSON"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 403 {object} types.Problem "Forbidden - admin role lacks permission"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /admin/geofences/{kind} [put]
+func (h *GeofenceHandler) ReplaceGeofences(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	claims, ok := currentAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	kind := models.GeofenceKind(r.PathValue("kind"))
+	if !kind.IsValid() {
+		log.Warn().Str("kind", string(kind)).Msg("Invalid geofence kind")
+		types.WriteError(w, http.StatusBadRequest, "Kind must be one of operating_area, no_parking or slow")
+		return
+	}
+
+	var collection geojson.FeatureCollection
+	if err := json.NewDecoder(r.Body).Decode(&collection); err != nil {
+		log.Warn().Err(err).Msg("Failed to decode geofences request")
+		types.WriteError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	geofences, validationErrors := geojson.Geofences(kind, &collection)
+	if len(validationErrors) > 0 {
+		log.Warn().Interface("errors", validationErrors).Str("kind", string(kind)).Msg("Geofence validation failed")
+		types.WriteValidationErrors(w, validationErrors)
+		return
+	}
+
+	stored, err := h.geofenceService.ReplaceGeofences(r.Context(), kind, geofences)
+	if err != nil {
+		logServiceError(&log, err).Str("kind", string(kind)).Msg("Error replacing geofences")
+		types.WriteProblem(w, err, "Error replacing geofences")
+		return
+	}
+
+	log.Info().Int("admin_id", claims.Sub).Str("kind", string(kind)).Int("count", len(stored)).Msg("Geofences replaced successfully")
+	types.WriteSuccess(w, "Geofences replaced successfully", geojson.FromGeofences(stored))
+}
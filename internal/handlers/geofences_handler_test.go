@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Nimirandad/bike-rental-service/internal/geojson"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockGeofenceService struct {
+	ReplaceGeofencesFunc func(kind models.GeofenceKind, geofences []*models.Geofence) ([]*models.Geofence, error)
+	GetGeofencesFunc     func(kind models.GeofenceKind) ([]*models.Geofence, error)
+}
+
+func (m *MockGeofenceService) ReplaceGeofences(ctx context.Context, kind models.GeofenceKind, geofences []*models.Geofence) ([]*models.Geofence, error) {
+	return m.ReplaceGeofencesFunc(kind, geofences)
+}
+
+func (m *MockGeofenceService) GetGeofences(ctx context.Context, kind models.GeofenceKind) ([]*models.Geofence, error) {
+	return m.GetGeofencesFunc(kind)
+}
+
+const noParkingCollection = `{"type": "FeatureCollection", "features": [
+	{"type": "Feature", "properties": {"name": "Sol"}, "geometry": {"type": "Polygon", "coordinates": [[[-3.71, 40.42], [-3.70, 40.42], [-3.70, 40.43], [-3.71, 40.42]]]}}
+]}`
+
+func TestGeofenceHandler_GetGeofences_Public(t *testing.T) {
+	mockService := &MockGeofenceService{
+		GetGeofencesFunc: func(kind models.GeofenceKind) ([]*models.Geofence, error) {
+			assert.Equal(t, models.GeofenceKind(""), kind)
+			return []*models.Geofence{{ID: 1, Kind: models.GeofenceSlow, Rings: [][]models.GeoPoint{{{Latitude: 40.42, Longitude: -3.71}}}}}, nil
+		},
+	}
+
+	handler := &GeofenceHandler{geofenceService: mockService}
+	req := httptest.NewRequest(http.MethodGet, "/geofences", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetGeofences(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, geojson.ContentType, w.Header().Get("Content-Type"))
+
+	var fc geojson.FeatureCollection
+	json.NewDecoder(w.Body).Decode(&fc)
+	assert.Equal(t, geojson.TypeFeatureCollection, fc.Type)
+	assert.Len(t, fc.Features, 1)
+	assert.Equal(t, "slow", fc.Features[0].Properties["kind"])
+}
+
+func TestGeofenceHandler_GetAllGeofences_InvalidKind(t *testing.T) {
+	handler := &GeofenceHandler{geofenceService: &MockGeofenceService{}}
+	req := httptest.NewRequest(http.MethodGet, "/admin/geofences?kind=parks", nil)
+	req = withAdmin(req, models.AdminRoleFleet)
+	w := httptest.NewRecorder()
+
+	handler.GetAllGeofences(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGeofenceHandler_ReplaceGeofences_Success(t *testing.T) {
+	mockService := &MockGeofenceService{
+		ReplaceGeofencesFunc: func(kind models.GeofenceKind, geofences []*models.Geofence) ([]*models.Geofence, error) {
+			assert.Equal(t, models.GeofenceNoParking, kind)
+			assert.Len(t, geofences, 1)
+			assert.Equal(t, "Sol", geofences[0].Name)
+			geofences[0].ID = 1
+			return geofences, nil
+		},
+	}
+
+	handler := &GeofenceHandler{geofenceService: mockService}
+	req := httptest.NewRequest(http.MethodPut, "/admin/geofences/no_parking", strings.NewReader(noParkingCollection))
+	req.SetPathValue("kind", "no_parking")
+	req = withAdmin(req, models.AdminRoleFleet)
+	w := httptest.NewRecorder()
+
+	handler.ReplaceGeofences(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestGeofenceHandler_ReplaceGeofences_InvalidGeoJSON(t *testing.T) {
+	handler := &GeofenceHandler{geofenceService: &MockGeofenceService{}}
+	body := `{"type": "FeatureCollection", "features": [{"type": "Feature", "geometry": {"type": "LineString", "coordinates": []}}]}`
+	req := httptest.NewRequest(http.MethodPut, "/admin/geofences/slow", strings.NewReader(body))
+	req.SetPathValue("kind", "slow")
+	req = withAdmin(req, models.AdminRoleFleet)
+	w := httptest.NewRecorder()
+
+	handler.ReplaceGeofences(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var resp types.Problem
+	json.NewDecoder(w.Body).Decode(&resp)
+	assert.Contains(t, resp.Details, "features[0].geometry")
+}
+
+func TestGeofenceHandler_ReplaceGeofences_InvalidKind(t *testing.T) {
+	handler := &GeofenceHandler{geofenceService: &MockGeofenceService{}}
+	req := httptest.NewRequest(http.MethodPut, "/admin/geofences/parks", strings.NewReader(noParkingCollection))
+	req.SetPathValue("kind", "parks")
+	req = withAdmin(req, models.AdminRoleFleet)
+	w := httptest.NewRecorder()
+
+	handler.ReplaceGeofences(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGeofenceHandler_ReplaceGeofences_Unauthorized(t *testing.T) {
+	handler := &GeofenceHandler{geofenceService: &MockGeofenceService{}}
+	req := httptest.NewRequest(http.MethodPut, "/admin/geofences/slow", strings.NewReader(noParkingCollection))
+	w := httptest.NewRecorder()
+
+	handler.ReplaceGeofences(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/Nimirandad/bike-rental-service/internal/logger"
@@ -9,7 +10,7 @@ import (
 )
 
 type HealthService interface {
-	CheckHealth() (*services.HealthStatus, bool)
+	CheckHealth(ctx context.Context) (*services.HealthStatus, bool)
 }
 
 type HealthHandler struct {
@@ -32,9 +33,9 @@ func NewHealthHandler(healthService *services.HealthService) *HealthHandler {
 // @Failure 503 {object} services.HealthStatus "Service is unhealthy"
 // @Router /status [get]
 func (h *HealthHandler) CheckHealth(w http.ResponseWriter, r *http.Request) {
-	log := logger.Get()
+	log := logger.FromContext(r.Context())
 
-	health, isHealthy := h.healthService.CheckHealth()
+	health, isHealthy := h.healthService.CheckHealth(r.Context())
 
 	statusCode := http.StatusOK
 	if !isHealthy {
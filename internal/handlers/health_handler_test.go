@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -13,7 +14,7 @@ type MockHealthService struct {
 	CheckHealthFunc func() (*services.HealthStatus, bool)
 }
 
-func (m *MockHealthService) CheckHealth() (*services.HealthStatus, bool) {
+func (m *MockHealthService) CheckHealth(ctx context.Context) (*services.HealthStatus, bool) {
 	return m.CheckHealthFunc()
 }
 
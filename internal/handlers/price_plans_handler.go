@@ -0,0 +1,343 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/logger"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/services"
+	"github.com/Nimirandad/bike-rental-service/internal/types"
+	"github.com/Nimirandad/bike-rental-service/internal/utils"
+)
+
+type PricePlanService interface {
+	CreatePlan(ctx context.Context, plan *models.PricePlan) (*models.PricePlan, error)
+	GetAllPlans(ctx context.Context, page, limit int) ([]*models.PricePlan, int, error)
+	GetPlanByID(ctx context.Context, planID int) (*models.PricePlan, error)
+	UpdatePlan(ctx context.Context, plan *models.PricePlan) (*models.PricePlan, error)
+	DeletePlan(ctx context.Context, planID int) error
+}
+
+type PricePlanHandler struct {
+	pricePlanService PricePlanService
+}
+
+func NewPricePlanHandler(pricePlanService *services.PricePlanService) *PricePlanHandler {
+	return &PricePlanHandler{
+		pricePlanService: pricePlanService,
+	}
+}
+
+// CreatePricePlan godoc
+// @Summary Create a price plan (Admin)
+// @Description Create a pricing plan with unlock fee, free minutes, night/weekend multipliers and daily cap (requires admin authentication)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param plan body types.PricePlanRequest true "Price plan data"
+// @Security BearerAuth
+// @Success 200 {object} types.SuccessResponse{data=models.PricePlan} "Price plan created successfully"
+// @Failure 400 {object} types.Problem "Validation failed or unknown zone rate station"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 403 {object} types.Problem "Forbidden - admin role lacks permission"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /admin/pricing-plans [post]
+func (h *PricePlanHandler) CreatePricePlan(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	claims, ok := currentAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	var req types.PricePlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Warn().Err(err).Msg("Failed to decode create price plan request")
+		types.WriteError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	plan := &models.PricePlan{
+		NightMultiplier:   1,
+		WeekendMultiplier: 1,
+		Timezone:          "UTC",
+	}
+	applyPricePlanRequest(plan, &req)
+
+	if validationErrors := utils.ValidatePricePlan(plan); len(validationErrors) > 0 {
+		log.Warn().Interface("errors", validationErrors).Msg("Price plan validation failed")
+		types.WriteValidationErrors(w, validationErrors)
+		return
+	}
+
+	created, err := h.pricePlanService.CreatePlan(r.Context(), plan)
+	if err != nil {
+		logServiceError(&log, err).Str("name", plan.Name).Msg("Error creating price plan")
+		types.WriteProblem(w, err, "Error creating price plan")
+		return
+	}
+
+	log.Info().Int("admin_id", claims.Sub).Int("price_plan_id", created.ID).Str("name", created.Name).Msg("Price plan created successfully")
+	types.WriteSuccess(w, "Price plan created successfully", created)
+}
+
+// GetAllPricePlans godoc
+// @Summary Get all price plans (Admin)
+// @Description Get paginated list of price plans (requires admin authentication)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page (max 100)" default(20)
+// @Security BearerAuth
+// @Success 200 {object} types.PaginatedResponse{data=[]models.PricePlan} "Price plans retrieved successfully"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 403 {object} types.Problem "Forbidden - admin role lacks permission"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /admin/pricing-plans [get]
+func (h *PricePlanHandler) GetAllPricePlans(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	_, ok := currentAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	page := constants.DefaultPage
+	if pageParam := r.URL.Query().Get("page"); pageParam != "" {
+		if p, err := strconv.Atoi(pageParam); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	limit := constants.DefaultLimit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		if l, err := strconv.Atoi(limitParam); err == nil && l > 0 && l <= constants.MaxLimit {
+			limit = l
+		}
+	}
+
+	plans, total, err := h.pricePlanService.GetAllPlans(r.Context(), page, limit)
+	if err != nil {
+		logServiceError(&log, err).Int("page", page).Int("limit", limit).Msg("Error retrieving price plans")
+		types.WriteProblem(w, err, "Error retrieving price plans")
+		return
+	}
+
+	log.Info().Int("total", total).Int("returned", len(plans)).Msg("Price plans retrieved successfully")
+	types.WritePaginatedSuccess(w, "Price plans retrieved successfully", plans, total, page, limit)
+}
+
+// GetPricePlan godoc
+// @Summary Get price plan details (Admin)
+// @Description Get a single price plan (requires admin authentication)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param plan-id path int true "Price plan ID"
+// @Security BearerAuth
+// @Success 200 {object} types.SuccessResponse{data=models.PricePlan} "Price plan retrieved successfully"
+// @Failure 400 {object} types.Problem "Invalid price plan ID"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 403 {object} types.Problem "Forbidden - admin role lacks permission"
+// @Failure 404 {object} types.Problem "Price plan not found"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /admin/pricing-plans/{plan-id} [get]
+func (h *PricePlanHandler) GetPricePlan(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	_, ok := currentAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	planIDStr := r.PathValue("plan-id")
+	planID, err := strconv.Atoi(planIDStr)
+	if err != nil || planID <= 0 {
+		log.Warn().Str("plan_id", planIDStr).Msg("Invalid price plan ID")
+		types.WriteError(w, http.StatusBadRequest, "Invalid price plan ID")
+		return
+	}
+
+	plan, err := h.pricePlanService.GetPlanByID(r.Context(), planID)
+	if err != nil {
+		logServiceError(&log, err).Int("price_plan_id", planID).Msg("Error retrieving price plan")
+		types.WriteProblem(w, err, "Error retrieving price plan")
+		return
+	}
+
+	types.WriteSuccess(w, "Price plan retrieved successfully", plan)
+}
+
+// UpdatePricePlan godoc
+// @Summary Update price plan (Admin)
+// @Description Update the fields provided on a price plan (requires admin authentication)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param plan-id path int true "Price plan ID"
+// @Param plan body types.PricePlanRequest true "Price plan update data"
+// @Security BearerAuth
+// @Success 200 {object} types.SuccessResponse{data=models.PricePlan} "Price plan updated successfully"
+// @Failure 400 {object} types.Problem "Invalid price plan ID, update data or zone rate station"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 403 {object} types.Problem "Forbidden - admin role lacks permission"
+// @Failure 404 {object} types.Problem "Price plan not found"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /admin/pricing-plans/{plan-id} [patch]
+func (h *PricePlanHandler) UpdatePricePlan(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	claims, ok := currentAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	planIDStr := r.PathValue("plan-id")
+	planID, err := strconv.Atoi(planIDStr)
+	if err != nil || planID <= 0 {
+		log.Warn().Str("plan_id", planIDStr).Msg("Invalid price plan ID in update request")
+		types.WriteError(w, http.StatusBadRequest, "Invalid price plan ID")
+		return
+	}
+
+	var req types.PricePlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Warn().Err(err).Int("price_plan_id", planID).Msg("Failed to decode update price plan request")
+		types.WriteError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	plan, err := h.pricePlanService.GetPlanByID(r.Context(), planID)
+	if err != nil {
+		logServiceError(&log, err).Int("price_plan_id", planID).Msg("Error retrieving price plan for update")
+		types.WriteProblem(w, err, "Error updating price plan")
+		return
+	}
+
+	applyPricePlanRequest(plan, &req)
+
+	if validationErrors := utils.ValidatePricePlan(plan); len(validationErrors) > 0 {
+		log.Warn().Interface("errors", validationErrors).Int("price_plan_id", planID).Msg("Price plan update validation failed")
+		types.WriteValidationErrors(w, validationErrors)
+		return
+	}
+
+	updated, err := h.pricePlanService.UpdatePlan(r.Context(), plan)
+	if err != nil {
+		logServiceError(&log, err).Int("price_plan_id", planID).Msg("Error updating price plan")
+		types.WriteProblem(w, err, "Error updating price plan")
+		return
+	}
+
+	log.Info().Int("admin_id", claims.Sub).Int("price_plan_id", planID).Msg("Price plan updated successfully")
+	types.WriteSuccess(w, "Price plan updated successfully", updated)
+}
+
+// DeletePricePlan godoc
+// @Summary Delete price plan (Admin)
+// @Description Delete a price plan that is not assigned to any bike (requires admin authentication)
+// @Tags admin
+// @Produce json
+// @Param plan-id path int true "Price plan ID"
+// @Security BearerAuth
+// @Success 200 {object} types.SuccessResponse "Price plan deleted successfully"
+// @Failure 400 {object} types.Problem "Invalid price plan ID"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 403 {object} types.Problem "Forbidden - admin role lacks permission"
+// @Failure 404 {object} types.Problem "Price plan not found"
+// @Failure 409 {object} types.Problem "Price plan is assigned to bikes"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /admin/pricing-plans/{plan-id} [delete]
+func (h *PricePlanHandler) DeletePricePlan(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	claims, ok := currentAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	planIDStr := r.PathValue("plan-id")
+	planID, err := strconv.Atoi(planIDStr)
+	if err != nil || planID <= 0 {
+		log.Warn().Str("plan_id", planIDStr).Msg("Invalid price plan ID in delete request")
+		types.WriteError(w, http.StatusBadRequest, "Invalid price plan ID")
+		return
+	}
+
+	if err := h.pricePlanService.DeletePlan(r.Context(), planID); err != nil {
+		logServiceError(&log, err).Int("price_plan_id", planID).Msg("Error deleting price plan")
+		types.WriteProblem(w, err, "Error deleting price plan")
+		return
+	}
+
+	log.Info().Int("admin_id", claims.Sub).Int("price_plan_id", planID).Msg("Price plan deleted successfully")
+	types.WriteSuccess(w, "Price plan deleted successfully", nil)
+}
+
+// applyPricePlanRequest copies the fields present in req onto plan. A
+// price_per_minute, daily_cap or paused_price_per_minute of 0 clears the value.
+func applyPricePlanRequest(plan *models.PricePlan, req *types.PricePlanRequest) {
+	if req.Name != nil {
+		plan.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.UnlockFee != nil {
+		plan.UnlockFee = *req.UnlockFee
+	}
+	if req.PricePerMinute != nil {
+		plan.PricePerMinute = req.PricePerMinute
+		if *req.PricePerMinute == 0 {
+			plan.PricePerMinute = nil
+		}
+	}
+	if req.FreeMinutes != nil {
+		plan.FreeMinutes = *req.FreeMinutes
+	}
+	if req.DailyCap != nil {
+		plan.DailyCap = req.DailyCap
+		if *req.DailyCap == 0 {
+			plan.DailyCap = nil
+		}
+	}
+	if req.NightMultiplier != nil {
+		plan.NightMultiplier = *req.NightMultiplier
+	}
+	if req.NightStartHour != nil {
+		plan.NightStartHour = *req.NightStartHour
+	}
+	if req.NightEndHour != nil {
+		plan.NightEndHour = *req.NightEndHour
+	}
+	if req.WeekendMultiplier != nil {
+		plan.WeekendMultiplier = *req.WeekendMultiplier
+	}
+	if req.Timezone != nil {
+		plan.Timezone = strings.TrimSpace(*req.Timezone)
+	}
+	if req.PausedPricePerMinute != nil {
+		plan.PausedPricePerMinute = req.PausedPricePerMinute
+		if *req.PausedPricePerMinute == 0 {
+			plan.PausedPricePerMinute = nil
+		}
+	}
+	if req.ZoneRates != nil {
+		plan.ZoneRates = make([]models.ZoneRate, 0, len(*req.ZoneRates))
+		for _, rate := range *req.ZoneRates {
+			multiplier := 1.0
+			if rate.Multiplier != nil {
+				multiplier = *rate.Multiplier
+			}
+			plan.ZoneRates = append(plan.ZoneRates, models.ZoneRate{
+				StationID:  rate.StationID,
+				ApplyTo:    models.ZoneRateApplyTo(strings.TrimSpace(rate.ApplyTo)),
+				Fee:        rate.Fee,
+				Multiplier: multiplier,
+			})
+		}
+	}
+}
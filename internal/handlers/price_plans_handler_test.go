@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockPricePlanService struct {
+	CreatePlanFunc  func(plan *models.PricePlan) (*models.PricePlan, error)
+	GetAllPlansFunc func(page, limit int) ([]*models.PricePlan, int, error)
+	GetPlanByIDFunc func(planID int) (*models.PricePlan, error)
+	UpdatePlanFunc  func(plan *models.PricePlan) (*models.PricePlan, error)
+	DeletePlanFunc  func(planID int) error
+}
+
+func (m *MockPricePlanService) CreatePlan(ctx context.Context, plan *models.PricePlan) (*models.PricePlan, error) {
+	return m.CreatePlanFunc(plan)
+}
+
+func (m *MockPricePlanService) GetAllPlans(ctx context.Context, page, limit int) ([]*models.PricePlan, int, error) {
+	return m.GetAllPlansFunc(page, limit)
+}
+
+func (m *MockPricePlanService) GetPlanByID(ctx context.Context, planID int) (*models.PricePlan, error) {
+	return m.GetPlanByIDFunc(planID)
+}
+
+func (m *MockPricePlanService) UpdatePlan(ctx context.Context, plan *models.PricePlan) (*models.PricePlan, error) {
+	return m.UpdatePlanFunc(plan)
+}
+
+func (m *MockPricePlanService) DeletePlan(ctx context.Context, planID int) error {
+	return m.DeletePlanFunc(planID)
+}
+
+func TestPricePlanHandler_CreatePricePlan_Success(t *testing.T) {
+	mockService := &MockPricePlanService{
+		CreatePlanFunc: func(plan *models.PricePlan) (*models.PricePlan, error) {
+			assert.Equal(t, "Standard", plan.Name)
+			assert.Equal(t, 1.0, plan.UnlockFee)
+			assert.Equal(t, 1.0, plan.WeekendMultiplier)
+			assert.Equal(t, "UTC", plan.Timezone)
+			plan.ID = 1
+			return plan, nil
+		},
+	}
+
+	handler := &PricePlanHandler{pricePlanService: mockService}
+	body, _ := json.Marshal(map[string]interface{}{"name": "Standard", "unlock_fee": 1.0, "free_minutes": 5})
+	req := httptest.NewRequest(http.MethodPost, "/admin/pricing-plans", bytes.NewReader(body))
+	req = withAdmin(req, models.AdminRoleSuperadmin)
+	w := httptest.NewRecorder()
+
+	handler.CreatePricePlan(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestPricePlanHandler_CreatePricePlan_ValidationError(t *testing.T) {
+	handler := &PricePlanHandler{pricePlanService: &MockPricePlanService{}}
+	body, _ := json.Marshal(map[string]interface{}{"unlock_fee": -1.0, "timezone": "Nowhere/City"})
+	req := httptest.NewRequest(http.MethodPost, "/admin/pricing-plans", bytes.NewReader(body))
+	req = withAdmin(req, models.AdminRoleSuperadmin)
+	w := httptest.NewRecorder()
+
+	handler.CreatePricePlan(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var resp types.Problem
+	json.NewDecoder(w.Body).Decode(&resp)
+	assert.Contains(t, resp.Details, "name")
+	assert.Contains(t, resp.Details, "unlock_fee")
+	assert.Contains(t, resp.Details, "timezone")
+}
+
+func TestPricePlanHandler_CreatePricePlan_Unauthorized(t *testing.T) {
+	handler := &PricePlanHandler{pricePlanService: &MockPricePlanService{}}
+	req := httptest.NewRequest(http.MethodPost, "/admin/pricing-plans", nil)
+	w := httptest.NewRecorder()
+
+	handler.CreatePricePlan(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestPricePlanHandler_GetAllPricePlans_Success(t *testing.T) {
+	mockService := &MockPricePlanService{
+		GetAllPlansFunc: func(page, limit int) ([]*models.PricePlan, int, error) {
+			return []*models.PricePlan{{ID: 1, Name: "Standard"}}, 1, nil
+		},
+	}
+
+	handler := &PricePlanHandler{pricePlanService: mockService}
+	req := httptest.NewRequest(http.MethodGet, "/admin/pricing-plans?page=1&limit=10", nil)
+	req = withAdmin(req, models.AdminRoleSuperadmin)
+	w := httptest.NewRecorder()
+
+	handler.GetAllPricePlans(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestPricePlanHandler_GetPricePlan_NotFound(t *testing.T) {
+	mockService := &MockPricePlanService{
+		GetPlanByIDFunc: func(planID int) (*models.PricePlan, error) {
+			return nil, constants.ErrPricePlanNotFound
+		},
+	}
+
+	handler := &PricePlanHandler{pricePlanService: mockService}
+	req := httptest.NewRequest(http.MethodGet, "/admin/pricing-plans/99", nil)
+	req.SetPathValue("plan-id", "99")
+	req = withAdmin(req, models.AdminRoleSuperadmin)
+	w := httptest.NewRecorder()
+
+	handler.GetPricePlan(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestPricePlanHandler_UpdatePricePlan_MergesFields(t *testing.T) {
+	dailyCap := 20.0
+	mockService := &MockPricePlanService{
+		GetPlanByIDFunc: func(planID int) (*models.PricePlan, error) {
+			return &models.PricePlan{ID: planID, Name: "Standard", UnlockFee: 1, DailyCap: &dailyCap, NightMultiplier: 1, WeekendMultiplier: 1, Timezone: "UTC"}, nil
+		},
+		UpdatePlanFunc: func(plan *models.PricePlan) (*models.PricePlan, error) {
+			assert.Equal(t, "Standard", plan.Name)
+			assert.Equal(t, 2.0, plan.UnlockFee)
+			assert.Nil(t, plan.DailyCap)
+			return plan, nil
+		},
+	}
+
+	handler := &PricePlanHandler{pricePlanService: mockService}
+	body, _ := json.Marshal(map[string]interface{}{"unlock_fee": 2.0, "daily_cap": 0})
+	req := httptest.NewRequest(http.MethodPatch, "/admin/pricing-plans/1", bytes.NewReader(body))
+	req.SetPathValue("plan-id", "1")
+	req = withAdmin(req, models.AdminRoleSuperadmin)
+	w := httptest.NewRecorder()
+
+	handler.UpdatePricePlan(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestPricePlanHandler_UpdatePricePlan_ReplacesZoneRates(t *testing.T) {
+	mockService := &MockPricePlanService{
+		GetPlanByIDFunc: func(planID int) (*models.PricePlan, error) {
+			return &models.PricePlan{
+				ID: planID, Name: "Standard", NightMultiplier: 1, WeekendMultiplier: 1, Timezone: "UTC",
+				ZoneRates: []models.ZoneRate{{ID: 4, StationID: 2, ApplyTo: models.ZoneRateStart, Fee: 1, Multiplier: 1}},
+			}, nil
+		},
+		UpdatePlanFunc: func(plan *models.PricePlan) (*models.PricePlan, error) {
+			assert.Equal(t, []models.ZoneRate{
+				{StationID: 3, ApplyTo: models.ZoneRateEnd, Fee: 0.5, Multiplier: 1},
+				{StationID: 5, ApplyTo: models.ZoneRateStart, Multiplier: 1.5},
+			}, plan.ZoneRates)
+			return plan, nil
+		},
+	}
+
+	handler := &PricePlanHandler{pricePlanService: mockService}
+	body, _ := json.Marshal(map[string]interface{}{"zone_rates": []map[string]interface{}{
+		{"station_id": 3, "apply_to": "end", "fee": 0.5},
+		{"station_id": 5, "apply_to": "start", "multiplier": 1.5},
+	}})
+	req := httptest.NewRequest(http.MethodPatch, "/admin/pricing-plans/1", bytes.NewReader(body))
+	req.SetPathValue("plan-id", "1")
+	req = withAdmin(req, models.AdminRoleSuperadmin)
+	w := httptest.NewRecorder()
+
+	handler.UpdatePricePlan(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestPricePlanHandler_UpdatePricePlan_InvalidID(t *testing.T) {
+	handler := &PricePlanHandler{pricePlanService: &MockPricePlanService{}}
+	req := httptest.NewRequest(http.MethodPatch, "/admin/pricing-plans/abc", bytes.NewReader([]byte(`{}`)))
+	req.SetPathValue("plan-id", "abc")
+	req = withAdmin(req, models.AdminRoleSuperadmin)
+	w := httptest.NewRecorder()
+
+	handler.UpdatePricePlan(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestPricePlanHandler_DeletePricePlan_InUse(t *testing.T) {
+	mockService := &MockPricePlanService{
+		DeletePlanFunc: func(planID int) error {
+			return constants.ErrPricePlanInUse
+		},
+	}
+
+	handler := &PricePlanHandler{pricePlanService: mockService}
+	req := httptest.NewRequest(http.MethodDelete, "/admin/pricing-plans/1", nil)
+	req.SetPathValue("plan-id", "1")
+	req = withAdmin(req, models.AdminRoleSuperadmin)
+	w := httptest.NewRecorder()
+
+	handler.DeletePricePlan(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestPricePlanHandler_DeletePricePlan_Success(t *testing.T) {
+	mockService := &MockPricePlanService{
+		DeletePlanFunc: func(planID int) error {
+			return nil
+		},
+	}
+
+	handler := &PricePlanHandler{pricePlanService: mockService}
+	req := httptest.NewRequest(http.MethodDelete, "/admin/pricing-plans/1", nil)
+	req.SetPathValue("plan-id", "1")
+	req = withAdmin(req, models.AdminRoleSuperadmin)
+	w := httptest.NewRecorder()
+
+	handler.DeletePricePlan(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -1,22 +1,31 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
-	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/geojson"
+	"github.com/Nimirandad/bike-rental-service/internal/gpx"
 	"github.com/Nimirandad/bike-rental-service/internal/logger"
 	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/queryspec"
 	"github.com/Nimirandad/bike-rental-service/internal/services"
 	"github.com/Nimirandad/bike-rental-service/internal/types"
 	"github.com/Nimirandad/bike-rental-service/internal/utils"
 )
 
 type RentalService interface {
-	StartRental(userID, bikeID int) (*models.Rental, error)
-	EndRental(userID int, endLat, endLong float64) (*models.Rental, error)
-	GetRentalHistory(userID, page, limit int) ([]*models.Rental, int, error)
+	StartRental(ctx context.Context, userID, bikeID int) (*models.Rental, error)
+	EndRental(ctx context.Context, userID int, endLat, endLong float64) (*models.Rental, error)
+	PauseRental(ctx context.Context, userID int) (*models.Rental, error)
+	ResumeRental(ctx context.Context, userID int) (*models.Rental, error)
+	GetRentalHistory(ctx context.Context, userID, page, limit int) ([]*models.Rental, int, error)
+	GetRentalHistoryByCursor(ctx context.Context, userID int, cursor queryspec.Cursor, limit int) ([]*models.Rental, queryspec.Cursors, error)
+	TrackRental(ctx context.Context, userID int, points []models.RentalPoint) (int, error)
+	GetRentalRoute(ctx context.Context, userID, rentalID int) (*models.RentalRoute, error)
 }
 
 type RentalHandler struct {
@@ -36,32 +45,16 @@ func NewRentalHandler(rentalService *services.RentalService) *RentalHandler {
 // @Param rental body types.StartRentalRequest true "Rental start data with bike_id"
 // @Security BearerAuth
 // @Success 200 {object} types.SuccessResponse{data=models.Rental} "Rental started successfully"
-// @Failure 400 {object} types.ErrorResponse "Invalid request payload or bike_id"
-// @Failure 401 {object} types.ErrorResponse "Unauthorized"
-// @Failure 404 {object} types.ErrorResponse "Bike not found"
-// @Failure 409 {object} types.ErrorResponse "User has active rental or bike not available"
-// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 400 {object} types.Problem "Invalid request payload or bike_id"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 404 {object} types.Problem "Bike not found"
+// @Failure 409 {object} types.Problem "User has active rental, bike not available or reserved by another user"
+// @Failure 500 {object} types.Problem "Internal server error"
 // @Router /rentals/start [post]
 func (h *RentalHandler) StartRental(w http.ResponseWriter, r *http.Request) {
-	log := logger.Get()
-	authHeader := r.Header.Get("Authorization")
-	if authHeader == "" {
-		log.Warn().Msg("Start rental: missing authorization header")
-		types.WriteError(w, http.StatusUnauthorized, "Authorization header is required")
-		return
-	}
-
-	tokenString, err := utils.ExtractTokenFromHeader(authHeader)
-	if err != nil {
-		log.Warn().Err(err).Msg("Start rental: invalid authorization format")
-		types.WriteError(w, http.StatusUnauthorized, err.Error())
-		return
-	}
-
-	claims, err := utils.ValidateJWT(tokenString)
-	if err != nil {
-		log.Warn().Err(err).Msg("Start rental: invalid or expired token")
-		types.WriteError(w, http.StatusUnauthorized, "Invalid or expired token")
+	log := logger.FromContext(r.Context())
+	claims, ok := currentUser(w, r)
+	if !ok {
 		return
 	}
 
@@ -82,25 +75,10 @@ func (h *RentalHandler) StartRental(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rental, err := h.rentalService.StartRental(userID, req.BikeID)
+	rental, err := h.rentalService.StartRental(r.Context(), userID, req.BikeID)
 	if err != nil {
-		if err == constants.ErrUserHasActiveRental {
-			log.Warn().Int("user_id", userID).Msg("User already has active rental")
-			types.WriteError(w, http.StatusConflict, "You already have an active rental. Please return your current bike before renting another.")
-			return
-		}
-		if err == constants.ErrBikeNotAvailable {
-			log.Warn().Int("bike_id", req.BikeID).Msg("Bike not available")
-			types.WriteError(w, http.StatusConflict, "This bike is already rented by another user")
-			return
-		}
-		if err == constants.ErrBikeNotFound {
-			log.Warn().Int("bike_id", req.BikeID).Msg("Bike not found")
-			types.WriteError(w, http.StatusNotFound, "Bike not found")
-			return
-		}
-		log.Error().Err(err).Int("user_id", userID).Int("bike_id", req.BikeID).Msg("Failed to start rental")
-		types.WriteError(w, http.StatusInternalServerError, "Error starting rental")
+		logServiceError(&log, err).Int("user_id", userID).Int("bike_id", req.BikeID).Msg("Failed to start rental")
+		types.WriteProblem(w, err, "Error starting rental")
 		return
 	}
 
@@ -117,32 +95,16 @@ func (h *RentalHandler) StartRental(w http.ResponseWriter, r *http.Request) {
 // @Param rental body types.EndRentalRequest true "End location coordinates"
 // @Security BearerAuth
 // @Success 200 {object} types.SuccessResponse{data=models.Rental} "Rental ended successfully with cost"
-// @Failure 400 {object} types.ErrorResponse "Invalid coordinates or location too far from start"
-// @Failure 401 {object} types.ErrorResponse "Unauthorized"
-// @Failure 409 {object} types.ErrorResponse "No active rental to end"
-// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 400 {object} types.Problem "Invalid coordinates, location too far under the return rule, outside the operating area or in a no-parking zone"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 409 {object} types.Problem "No active rental to end"
+// @Failure 500 {object} types.Problem "Internal server error"
 // @Router /rentals/end [post]
 func (h *RentalHandler) EndRental(w http.ResponseWriter, r *http.Request) {
-	log := logger.Get()
-
-	authHeader := r.Header.Get("Authorization")
-	if authHeader == "" {
-		log.Warn().Msg("Attempt to end rental without authorization header")
-		types.WriteError(w, http.StatusUnauthorized, "Authorization header is required")
-		return
-	}
-
-	tokenString, err := utils.ExtractTokenFromHeader(authHeader)
-	if err != nil {
-		log.Warn().Err(err).Msg("Invalid authorization header format")
-		types.WriteError(w, http.StatusUnauthorized, err.Error())
-		return
-	}
+	log := logger.FromContext(r.Context())
 
-	claims, err := utils.ValidateJWT(tokenString)
-	if err != nil {
-		log.Warn().Err(err).Msg("Invalid or expired token for end rental")
-		types.WriteError(w, http.StatusUnauthorized, "Invalid or expired token")
+	claims, ok := currentUser(w, r)
+	if !ok {
 		return
 	}
 
@@ -169,20 +131,10 @@ func (h *RentalHandler) EndRental(w http.ResponseWriter, r *http.Request) {
 
 	log.Info().Int("user_id", userID).Float64("latitude", req.Latitude).Float64("longitude", req.Longitude).Msg("Attempting to end rental")
 
-	rental, err := h.rentalService.EndRental(userID, req.Latitude, req.Longitude)
+	rental, err := h.rentalService.EndRental(r.Context(), userID, req.Latitude, req.Longitude)
 	if err != nil {
-		if err == constants.ErrNoActiveRental {
-			log.Warn().Int("user_id", userID).Msg("User has no active rental to end")
-			types.WriteError(w, http.StatusConflict, "You don't have an active rental to end")
-			return
-		}
-		if err == constants.ErrEndLocationTooFar {
-			log.Warn().Int("user_id", userID).Float64("latitude", req.Latitude).Float64("longitude", req.Longitude).Msg("End location too far from start")
-			types.WriteError(w, http.StatusBadRequest, "End location must be within 5km of the start location")
-			return
-		}
-		log.Error().Err(err).Int("user_id", userID).Msg("Failed to end rental")
-		types.WriteError(w, http.StatusInternalServerError, "Error ending rental")
+		logServiceError(&log, err).Int("user_id", userID).Msg("Failed to end rental")
+		types.WriteProblem(w, err, "Error ending rental")
 		return
 	}
 
@@ -194,6 +146,70 @@ func (h *RentalHandler) EndRental(w http.ResponseWriter, r *http.Request) {
 	types.WriteSuccess(w, "Rental ended successfully", rental)
 }
 
+// PauseRental godoc
+// @Summary Pause the active rental
+// @Description Lock the bike without ending the rental. Paused time is billed at the paused rate.
+// @Tags rentals
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} types.SuccessResponse{data=models.Rental} "Rental paused successfully"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 409 {object} types.Problem "No active rental or rental is not running"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /rentals/pause [post]
+func (h *RentalHandler) PauseRental(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	claims, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	userID := claims.Sub
+
+	rental, err := h.rentalService.PauseRental(r.Context(), userID)
+	if err != nil {
+		logServiceError(&log, err).Int("user_id", userID).Msg("Failed to pause rental")
+		types.WriteProblem(w, err, "Error pausing rental")
+		return
+	}
+
+	log.Info().Int("rental_id", rental.ID).Int("user_id", userID).Msg("Rental paused successfully")
+	types.WriteSuccess(w, "Rental paused successfully", rental)
+}
+
+// ResumeRental godoc
+// @Summary Resume a paused rental
+// @Description Unlock the bike and continue the paused rental
+// @Tags rentals
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} types.SuccessResponse{data=models.Rental} "Rental resumed successfully"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 409 {object} types.Problem "No active rental or rental is not paused"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /rentals/resume [post]
+func (h *RentalHandler) ResumeRental(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	claims, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	userID := claims.Sub
+
+	rental, err := h.rentalService.ResumeRental(r.Context(), userID)
+	if err != nil {
+		logServiceError(&log, err).Int("user_id", userID).Msg("Failed to resume rental")
+		types.WriteProblem(w, err, "Error resuming rental")
+		return
+	}
+
+	log.Info().Int("rental_id", rental.ID).Int("user_id", userID).Msg("Rental resumed successfully")
+	types.WriteSuccess(w, "Rental resumed successfully", rental)
+}
+
 // GetRentalHistory godoc
 // @Summary Get rental history
 // @Description Get paginated rental history for authenticated user
@@ -202,32 +218,19 @@ func (h *RentalHandler) EndRental(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page (max 100)" default(20)
+// @Param cursor query string false "Keyset pagination cursor; send it empty for the first page (page is then ignored)"
 // @Security BearerAuth
 // @Success 200 {object} types.PaginatedResponse{data=[]models.Rental} "Rental history retrieved successfully"
-// @Failure 401 {object} types.ErrorResponse "Unauthorized"
-// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Success 200 {object} types.CursorPaginatedResponse{data=[]models.Rental} "Page of rental history when cursor is set"
+// @Failure 400 {object} types.Problem "Invalid cursor"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 500 {object} types.Problem "Internal server error"
 // @Router /rentals/history [get]
 func (h *RentalHandler) GetRentalHistory(w http.ResponseWriter, r *http.Request) {
-	log := logger.Get()
+	log := logger.FromContext(r.Context())
 
-	authHeader := r.Header.Get("Authorization")
-	if authHeader == "" {
-		log.Warn().Msg("Attempt to get rental history without authorization header")
-		types.WriteError(w, http.StatusUnauthorized, "Authorization header is required")
-		return
-	}
-
-	tokenString, err := utils.ExtractTokenFromHeader(authHeader)
-	if err != nil {
-		log.Warn().Err(err).Msg("Invalid authorization header format")
-		types.WriteError(w, http.StatusUnauthorized, err.Error())
-		return
-	}
-
-	claims, err := utils.ValidateJWT(tokenString)
-	if err != nil {
-		log.Warn().Err(err).Msg("Invalid or expired token for rental history")
-		types.WriteError(w, http.StatusUnauthorized, "Invalid or expired token")
+	claims, ok := currentUser(w, r)
+	if !ok {
 		return
 	}
 
@@ -249,13 +252,142 @@ func (h *RentalHandler) GetRentalHistory(w http.ResponseWriter, r *http.Request)
 
 	log.Info().Int("user_id", userID).Int("page", page).Int("limit", limit).Msg("Fetching rental history")
 
-	rentals, total, err := h.rentalService.GetRentalHistory(userID, page, limit)
+	cursor, err := queryspec.ParseCursor(r.URL.Query())
 	if err != nil {
-		log.Error().Err(err).Int("user_id", userID).Int("page", page).Int("limit", limit).Msg("Error retrieving rental history")
-		types.WriteError(w, http.StatusInternalServerError, "Error retrieving rental history")
+		log.Warn().Int("user_id", userID).Msg("Invalid rental history cursor")
+		types.WriteValidationErrors(w, map[string]string{queryspec.ParamCursor: "Invalid cursor"})
+		return
+	}
+
+	if cursor != nil {
+		rentals, cursors, err := h.rentalService.GetRentalHistoryByCursor(r.Context(), userID, *cursor, limit)
+		if err != nil {
+			logServiceError(&log, err).Int("user_id", userID).Int("limit", limit).Msg("Error retrieving rental history")
+			types.WriteProblem(w, err, "Error retrieving rental history")
+			return
+		}
+
+		log.Info().Int("user_id", userID).Int("returned", len(rentals)).Int("limit", limit).Msg("Rental history retrieved successfully")
+		types.WriteCursorPaginatedSuccess(w, "Rental history retrieved successfully", rentals, limit, cursors.Next, cursors.Prev)
+		return
+	}
+
+	rentals, total, err := h.rentalService.GetRentalHistory(r.Context(), userID, page, limit)
+	if err != nil {
+		logServiceError(&log, err).Int("user_id", userID).Int("page", page).Int("limit", limit).Msg("Error retrieving rental history")
+		types.WriteProblem(w, err, "Error retrieving rental history")
 		return
 	}
 
 	log.Info().Int("user_id", userID).Int("total", total).Int("returned", len(rentals)).Int("page", page).Int("limit", limit).Msg("Rental history retrieved successfully")
 	types.WritePaginatedSuccess(w, "Rental history retrieved successfully", rentals, total, page, limit)
 }
+
+// TrackRental godoc
+// @Summary Record locations of the active rental
+// @Description Record a batch of GPS locations for the authenticated user's running or paused rental
+// @Tags rentals
+// @Accept json
+// @Produce json
+// @Param points body types.TrackRentalRequest true "Locations, oldest first or in any order"
+// @Security BearerAuth
+// @Success 200 {object} types.SuccessResponse{data=types.TrackRentalResponse} "Locations recorded successfully"
+// @Failure 400 {object} types.Problem "Invalid points or points recorded before the rental started"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 409 {object} types.Problem "No active rental"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /rentals/track [post]
+func (h *RentalHandler) TrackRental(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	claims, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	userID := claims.Sub
+
+	var req types.TrackRentalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Warn().Err(err).Int("user_id", userID).Msg("Failed to decode track rental request")
+		types.WriteError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	points := make([]models.RentalPoint, len(req.Points))
+	for i, point := range req.Points {
+		points[i] = models.RentalPoint{Latitude: point.Latitude, Longitude: point.Longitude}
+		if point.RecordedAt != nil {
+			points[i].RecordedAt = *point.RecordedAt
+		}
+	}
+
+	if validationErrors := utils.ValidateTrackPoints(points, time.Now()); len(validationErrors) > 0 {
+		log.Warn().Interface("errors", validationErrors).Int("user_id", userID).Msg("Track rental validation failed")
+		types.WriteValidationErrors(w, validationErrors)
+		return
+	}
+
+	recorded, err := h.rentalService.TrackRental(r.Context(), userID, points)
+	if err != nil {
+		logServiceError(&log, err).Int("user_id", userID).Int("points", len(points)).Msg("Failed to record rental locations")
+		types.WriteProblem(w, err, "Error recording locations")
+		return
+	}
+
+	log.Debug().Int("user_id", userID).Int("recorded", recorded).Msg("Rental locations recorded")
+	types.WriteSuccess(w, "Locations recorded successfully", types.TrackRentalResponse{Recorded: recorded})
+}
+
+// GetRentalRoute godoc
+// @Summary Get the route of a rental
+// @Description Get the route ridden during one of the authenticated user's rentals as a GeoJSON LineString feature, or as a GPX track with format=gpx
+// @Tags rentals
+// @Produce application/geo+json,application/gpx+xml
+// @Param rental-id path int true "Rental ID"
+// @Param format query string false "Response format" Enums(geojson, gpx) default(geojson)
+// @Security BearerAuth
+// @Success 200 {object} geojson.Feature "Route"
+// @Failure 400 {object} types.Problem "Invalid rental ID or format"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 404 {object} types.Problem "Rental not found"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /rentals/{rental-id}/route [get]
+func (h *RentalHandler) GetRentalRoute(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	claims, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	userID := claims.Sub
+
+	rentalIDStr := r.PathValue("rental-id")
+	rentalID, err := strconv.Atoi(rentalIDStr)
+	if err != nil || rentalID <= 0 {
+		log.Warn().Str("rental_id", rentalIDStr).Int("user_id", userID).Msg("Invalid rental ID")
+		types.WriteError(w, http.StatusBadRequest, "Invalid rental ID")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "geojson" && format != "gpx" {
+		log.Warn().Str("format", format).Int("user_id", userID).Msg("Invalid route format")
+		types.WriteError(w, http.StatusBadRequest, "Format must be geojson or gpx")
+		return
+	}
+
+	route, err := h.rentalService.GetRentalRoute(r.Context(), userID, rentalID)
+	if err != nil {
+		logServiceError(&log, err).Int("user_id", userID).Int("rental_id", rentalID).Msg("Error retrieving rental route")
+		types.WriteProblem(w, err, "Error retrieving rental route")
+		return
+	}
+
+	if format == "gpx" {
+		gpx.Write(w, http.StatusOK, gpx.FromRoute(route))
+		return
+	}
+	geojson.WriteFeature(w, http.StatusOK, geojson.FromRoute(route))
+}
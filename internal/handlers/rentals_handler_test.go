@@ -2,16 +2,18 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
-	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Nimirandad/bike-rental-service/internal/constants"
 	"github.com/Nimirandad/bike-rental-service/internal/models"
-	"github.com/Nimirandad/bike-rental-service/internal/utils"
+	"github.com/Nimirandad/bike-rental-service/internal/queryspec"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -19,26 +21,48 @@ type MockRentalService struct {
 	StartRentalFunc      func(userID, bikeID int) (*models.Rental, error)
 	EndRentalFunc        func(userID int, endLat, endLong float64) (*models.Rental, error)
 	GetRentalHistoryFunc func(userID, page, limit int) ([]*models.Rental, int, error)
+	PauseRentalFunc      func(userID int) (*models.Rental, error)
+	ResumeRentalFunc     func(userID int) (*models.Rental, error)
+
+	GetRentalHistoryByCursorFunc func(userID int, cursor queryspec.Cursor, limit int) ([]*models.Rental, queryspec.Cursors, error)
+	TrackRentalFunc              func(userID int, points []models.RentalPoint) (int, error)
+	GetRentalRouteFunc           func(userID, rentalID int) (*models.RentalRoute, error)
 }
 
-func (m *MockRentalService) StartRental(userID, bikeID int) (*models.Rental, error) {
+func (m *MockRentalService) StartRental(ctx context.Context, userID, bikeID int) (*models.Rental, error) {
 	return m.StartRentalFunc(userID, bikeID)
 }
 
-func (m *MockRentalService) EndRental(userID int, endLat, endLong float64) (*models.Rental, error) {
+func (m *MockRentalService) EndRental(ctx context.Context, userID int, endLat, endLong float64) (*models.Rental, error) {
 	return m.EndRentalFunc(userID, endLat, endLong)
 }
 
-func (m *MockRentalService) GetRentalHistory(userID, page, limit int) ([]*models.Rental, int, error) {
+func (m *MockRentalService) PauseRental(ctx context.Context, userID int) (*models.Rental, error) {
+	return m.PauseRentalFunc(userID)
+}
+
+func (m *MockRentalService) ResumeRental(ctx context.Context, userID int) (*models.Rental, error) {
+	return m.ResumeRentalFunc(userID)
+}
+
+func (m *MockRentalService) GetRentalHistory(ctx context.Context, userID, page, limit int) ([]*models.Rental, int, error) {
 	return m.GetRentalHistoryFunc(userID, page, limit)
 }
 
-func TestRentalHandler_StartRental_Success(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
+func (m *MockRentalService) GetRentalHistoryByCursor(ctx context.Context, userID int, cursor queryspec.Cursor, limit int) ([]*models.Rental, queryspec.Cursors, error) {
+	return m.GetRentalHistoryByCursorFunc(userID, cursor, limit)
+}
+
+func (m *MockRentalService) TrackRental(ctx context.Context, userID int, points []models.RentalPoint) (int, error) {
+	return m.TrackRentalFunc(userID, points)
+}
 
+func (m *MockRentalService) GetRentalRoute(ctx context.Context, userID, rentalID int) (*models.RentalRoute, error) {
+	return m.GetRentalRouteFunc(userID, rentalID)
+}
+
+func TestRentalHandler_StartRental_Success(t *testing.T) {
 	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
-	token, _ := utils.GenerateJWT(testUser)
 
 	mockService := &MockRentalService{
 		StartRentalFunc: func(userID, bikeID int) (*models.Rental, error) {
@@ -52,7 +76,7 @@ func TestRentalHandler_StartRental_Success(t *testing.T) {
 	body, _ := json.Marshal(reqBody)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/rentals/start", bytes.NewReader(body))
-	req.Header.Set("Authorization", "Bearer "+token)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.StartRental(w, req)
@@ -72,11 +96,7 @@ func TestRentalHandler_StartRental_NoAuthHeader(t *testing.T) {
 }
 
 func TestRentalHandler_StartRental_InvalidBikeID(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
-
 	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
-	token, _ := utils.GenerateJWT(testUser)
 
 	handler := &RentalHandler{}
 
@@ -84,7 +104,7 @@ func TestRentalHandler_StartRental_InvalidBikeID(t *testing.T) {
 	body, _ := json.Marshal(reqBody)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/rentals/start", bytes.NewReader(body))
-	req.Header.Set("Authorization", "Bearer "+token)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.StartRental(w, req)
@@ -93,11 +113,7 @@ func TestRentalHandler_StartRental_InvalidBikeID(t *testing.T) {
 }
 
 func TestRentalHandler_StartRental_UserHasActiveRental(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
-
 	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
-	token, _ := utils.GenerateJWT(testUser)
 
 	mockService := &MockRentalService{
 		StartRentalFunc: func(userID, bikeID int) (*models.Rental, error) {
@@ -111,7 +127,7 @@ func TestRentalHandler_StartRental_UserHasActiveRental(t *testing.T) {
 	body, _ := json.Marshal(reqBody)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/rentals/start", bytes.NewReader(body))
-	req.Header.Set("Authorization", "Bearer "+token)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.StartRental(w, req)
@@ -120,11 +136,7 @@ func TestRentalHandler_StartRental_UserHasActiveRental(t *testing.T) {
 }
 
 func TestRentalHandler_StartRental_BikeNotAvailable(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
-
 	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
-	token, _ := utils.GenerateJWT(testUser)
 
 	mockService := &MockRentalService{
 		StartRentalFunc: func(userID, bikeID int) (*models.Rental, error) {
@@ -138,7 +150,7 @@ func TestRentalHandler_StartRental_BikeNotAvailable(t *testing.T) {
 	body, _ := json.Marshal(reqBody)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/rentals/start", bytes.NewReader(body))
-	req.Header.Set("Authorization", "Bearer "+token)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.StartRental(w, req)
@@ -146,12 +158,30 @@ func TestRentalHandler_StartRental_BikeNotAvailable(t *testing.T) {
 	assert.Equal(t, http.StatusConflict, w.Code)
 }
 
-func TestRentalHandler_StartRental_BikeNotFound(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
+func TestRentalHandler_StartRental_BikeReserved(t *testing.T) {
+	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
+
+	mockService := &MockRentalService{
+		StartRentalFunc: func(userID, bikeID int) (*models.Rental, error) {
+			return nil, constants.ErrBikeReserved
+		},
+	}
+
+	handler := &RentalHandler{rentalService: mockService}
 
+	body, _ := json.Marshal(map[string]int{"bike_id": 1})
+	req := httptest.NewRequest(http.MethodPost, "/api/rentals/start", bytes.NewReader(body))
+	req = withUser(req, testUser)
+	w := httptest.NewRecorder()
+
+	handler.StartRental(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "reserved by another user")
+}
+
+func TestRentalHandler_StartRental_BikeNotFound(t *testing.T) {
 	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
-	token, _ := utils.GenerateJWT(testUser)
 
 	mockService := &MockRentalService{
 		StartRentalFunc: func(userID, bikeID int) (*models.Rental, error) {
@@ -165,7 +195,7 @@ func TestRentalHandler_StartRental_BikeNotFound(t *testing.T) {
 	body, _ := json.Marshal(reqBody)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/rentals/start", bytes.NewReader(body))
-	req.Header.Set("Authorization", "Bearer "+token)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.StartRental(w, req)
@@ -174,11 +204,7 @@ func TestRentalHandler_StartRental_BikeNotFound(t *testing.T) {
 }
 
 func TestRentalHandler_EndRental_Success(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
-
 	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
-	token, _ := utils.GenerateJWT(testUser)
 
 	mockService := &MockRentalService{
 		EndRentalFunc: func(userID int, endLat, endLong float64) (*models.Rental, error) {
@@ -192,7 +218,7 @@ func TestRentalHandler_EndRental_Success(t *testing.T) {
 	body, _ := json.Marshal(reqBody)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/rentals/end", bytes.NewReader(body))
-	req.Header.Set("Authorization", "Bearer "+token)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.EndRental(w, req)
@@ -201,11 +227,7 @@ func TestRentalHandler_EndRental_Success(t *testing.T) {
 }
 
 func TestRentalHandler_EndRental_InvalidLatitude(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
-
 	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
-	token, _ := utils.GenerateJWT(testUser)
 
 	handler := &RentalHandler{}
 
@@ -213,7 +235,7 @@ func TestRentalHandler_EndRental_InvalidLatitude(t *testing.T) {
 	body, _ := json.Marshal(reqBody)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/rentals/end", bytes.NewReader(body))
-	req.Header.Set("Authorization", "Bearer "+token)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.EndRental(w, req)
@@ -222,11 +244,7 @@ func TestRentalHandler_EndRental_InvalidLatitude(t *testing.T) {
 }
 
 func TestRentalHandler_EndRental_InvalidLongitude(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
-
 	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
-	token, _ := utils.GenerateJWT(testUser)
 
 	handler := &RentalHandler{}
 
@@ -234,7 +252,7 @@ func TestRentalHandler_EndRental_InvalidLongitude(t *testing.T) {
 	body, _ := json.Marshal(reqBody)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/rentals/end", bytes.NewReader(body))
-	req.Header.Set("Authorization", "Bearer "+token)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.EndRental(w, req)
@@ -243,11 +261,7 @@ func TestRentalHandler_EndRental_InvalidLongitude(t *testing.T) {
 }
 
 func TestRentalHandler_EndRental_NoActiveRental(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
-
 	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
-	token, _ := utils.GenerateJWT(testUser)
 
 	mockService := &MockRentalService{
 		EndRentalFunc: func(userID int, endLat, endLong float64) (*models.Rental, error) {
@@ -261,7 +275,7 @@ func TestRentalHandler_EndRental_NoActiveRental(t *testing.T) {
 	body, _ := json.Marshal(reqBody)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/rentals/end", bytes.NewReader(body))
-	req.Header.Set("Authorization", "Bearer "+token)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.EndRental(w, req)
@@ -270,11 +284,7 @@ func TestRentalHandler_EndRental_NoActiveRental(t *testing.T) {
 }
 
 func TestRentalHandler_EndRental_LocationTooFar(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
-
 	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
-	token, _ := utils.GenerateJWT(testUser)
 
 	mockService := &MockRentalService{
 		EndRentalFunc: func(userID int, endLat, endLong float64) (*models.Rental, error) {
@@ -288,7 +298,7 @@ func TestRentalHandler_EndRental_LocationTooFar(t *testing.T) {
 	body, _ := json.Marshal(reqBody)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/rentals/end", bytes.NewReader(body))
-	req.Header.Set("Authorization", "Bearer "+token)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.EndRental(w, req)
@@ -297,11 +307,7 @@ func TestRentalHandler_EndRental_LocationTooFar(t *testing.T) {
 }
 
 func TestRentalHandler_EndRental_InternalError(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
-
 	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
-	token, _ := utils.GenerateJWT(testUser)
 
 	mockService := &MockRentalService{
 		EndRentalFunc: func(userID int, endLat, endLong float64) (*models.Rental, error) {
@@ -315,7 +321,7 @@ func TestRentalHandler_EndRental_InternalError(t *testing.T) {
 	body, _ := json.Marshal(reqBody)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/rentals/end", bytes.NewReader(body))
-	req.Header.Set("Authorization", "Bearer "+token)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.EndRental(w, req)
@@ -324,11 +330,7 @@ func TestRentalHandler_EndRental_InternalError(t *testing.T) {
 }
 
 func TestRentalHandler_GetRentalHistory_Success(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
-
 	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
-	token, _ := utils.GenerateJWT(testUser)
 
 	mockService := &MockRentalService{
 		GetRentalHistoryFunc: func(userID, page, limit int) ([]*models.Rental, int, error) {
@@ -343,7 +345,7 @@ func TestRentalHandler_GetRentalHistory_Success(t *testing.T) {
 	handler := &RentalHandler{rentalService: mockService}
 
 	req := httptest.NewRequest(http.MethodGet, "/api/rentals?page=1&limit=10", nil)
-	req.Header.Set("Authorization", "Bearer "+token)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.GetRentalHistory(w, req)
@@ -352,11 +354,7 @@ func TestRentalHandler_GetRentalHistory_Success(t *testing.T) {
 }
 
 func TestRentalHandler_StartRental_InternalError(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
-
 	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
-	token, _ := utils.GenerateJWT(testUser)
 
 	mockService := &MockRentalService{
 		StartRentalFunc: func(userID, bikeID int) (*models.Rental, error) {
@@ -370,7 +368,7 @@ func TestRentalHandler_StartRental_InternalError(t *testing.T) {
 	body, _ := json.Marshal(reqBody)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/rentals/start", bytes.NewReader(body))
-	req.Header.Set("Authorization", "Bearer "+token)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.StartRental(w, req)
@@ -379,15 +377,11 @@ func TestRentalHandler_StartRental_InternalError(t *testing.T) {
 }
 
 func TestRentalHandler_StartRental_InvalidJSON(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
-
 	testUser := &models.User{ID: 1, Email: "test@example.com"}
-	token, _ := utils.GenerateJWT(testUser)
 
 	handler := &RentalHandler{}
 	req := httptest.NewRequest(http.MethodPost, "/api/rentals/start", bytes.NewReader([]byte("invalid")))
-	req.Header.Set("Authorization", "Bearer "+token)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.StartRental(w, req)
@@ -395,15 +389,11 @@ func TestRentalHandler_StartRental_InvalidJSON(t *testing.T) {
 }
 
 func TestRentalHandler_EndRental_InvalidJSON(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
-
 	testUser := &models.User{ID: 1, Email: "test@example.com"}
-	token, _ := utils.GenerateJWT(testUser)
 
 	handler := &RentalHandler{}
 	req := httptest.NewRequest(http.MethodPost, "/api/rentals/end", bytes.NewReader([]byte("invalid")))
-	req.Header.Set("Authorization", "Bearer "+token)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.EndRental(w, req)
@@ -411,11 +401,7 @@ func TestRentalHandler_EndRental_InvalidJSON(t *testing.T) {
 }
 
 func TestRentalHandler_GetRentalHistory_ServiceError(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
-
 	testUser := &models.User{ID: 1, Email: "test@example.com"}
-	token, _ := utils.GenerateJWT(testUser)
 
 	mockService := &MockRentalService{
 		GetRentalHistoryFunc: func(userID, page, limit int) ([]*models.Rental, int, error) {
@@ -425,69 +411,305 @@ func TestRentalHandler_GetRentalHistory_ServiceError(t *testing.T) {
 
 	handler := &RentalHandler{rentalService: mockService}
 	req := httptest.NewRequest(http.MethodGet, "/api/rentals/history", nil)
-	req.Header.Set("Authorization", "Bearer "+token)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.GetRentalHistory(w, req)
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
 
-func TestRentalHandler_StartRental_InvalidAuthFormat(t *testing.T) {
-	handler := &RentalHandler{}
-	reqBody := map[string]int{"bike_id": 1}
-	body, _ := json.Marshal(reqBody)
+func TestRentalHandler_GetRentalHistory_WithPagination(t *testing.T) {
+	testUser := &models.User{ID: 1}
 
-	req := httptest.NewRequest(http.MethodPost, "/api/rentals/start", bytes.NewReader(body))
-	req.Header.Set("Authorization", "InvalidFormat")
+	mockService := &MockRentalService{
+		GetRentalHistoryFunc: func(userID, page, limit int) ([]*models.Rental, int, error) {
+			return []*models.Rental{
+				{ID: 1, UserID: userID, BikeID: 1},
+			}, 1, nil
+		},
+	}
+
+	handler := &RentalHandler{rentalService: mockService}
+	req := httptest.NewRequest(http.MethodGet, "/rentals/history?page=2&limit=5", nil)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
-	handler.StartRental(w, req)
-	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	handler.GetRentalHistory(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
 }
 
-func TestRentalHandler_EndRental_InvalidAuthFormat(t *testing.T) {
-	handler := &RentalHandler{}
-	reqBody := map[string]float64{"latitude": 40.416775, "longitude": -3.703790}
-	body, _ := json.Marshal(reqBody)
+func TestRentalHandler_GetRentalHistory_Cursor(t *testing.T) {
+	testUser := &models.User{ID: 1}
+	cursor := queryspec.Cursor{ID: 9}
 
-	req := httptest.NewRequest(http.MethodPost, "/api/rentals/end", bytes.NewReader(body))
-	req.Header.Set("Authorization", "InvalidFormat")
+	mockService := &MockRentalService{
+		GetRentalHistoryByCursorFunc: func(userID int, c queryspec.Cursor, limit int) ([]*models.Rental, queryspec.Cursors, error) {
+			assert.Equal(t, cursor, c)
+			assert.Equal(t, 5, limit)
+			return []*models.Rental{{ID: 8, UserID: userID}}, queryspec.Cursors{Next: "next"}, nil
+		},
+	}
+
+	handler := &RentalHandler{rentalService: mockService}
+	req := httptest.NewRequest(http.MethodGet, "/rentals/history?limit=5&cursor="+cursor.Encode(), nil)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
-	handler.EndRental(w, req)
-	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	handler.GetRentalHistory(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"next_cursor":"next"`)
 }
 
-func TestRentalHandler_GetRentalHistory_InvalidAuthFormat(t *testing.T) {
-	handler := &RentalHandler{}
-	req := httptest.NewRequest(http.MethodGet, "/api/rentals/history", nil)
-	req.Header.Set("Authorization", "InvalidFormat")
+func TestRentalHandler_GetRentalHistory_InvalidCursor(t *testing.T) {
+	handler := &RentalHandler{rentalService: &MockRentalService{}}
+	req := httptest.NewRequest(http.MethodGet, "/rentals/history?cursor=garbage", nil)
+	req = withUser(req, &models.User{ID: 1})
 	w := httptest.NewRecorder()
 
 	handler.GetRentalHistory(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRentalHandler_PauseRental_Success(t *testing.T) {
+	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
+
+	mockService := &MockRentalService{
+		PauseRentalFunc: func(userID int) (*models.Rental, error) {
+			return &models.Rental{ID: 1, UserID: userID, BikeID: 1, Status: models.RentalStatusPaused}, nil
+		},
+	}
+
+	handler := &RentalHandler{rentalService: mockService}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/rentals/pause", nil)
+	req = withUser(req, testUser)
+	w := httptest.NewRecorder()
+
+	handler.PauseRental(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"paused"`)
+}
+
+func TestRentalHandler_PauseRental_Errors(t *testing.T) {
+	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"No active rental", constants.ErrNoActiveRental, http.StatusConflict},
+		{"Already paused", constants.ErrRentalNotRunning, http.StatusConflict},
+		{"Internal error", errors.New("database error"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockRentalService{
+				PauseRentalFunc: func(userID int) (*models.Rental, error) {
+					return nil, tt.err
+				},
+			}
+
+			handler := &RentalHandler{rentalService: mockService}
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/rentals/pause", nil)
+			req = withUser(req, testUser)
+			w := httptest.NewRecorder()
+
+			handler.PauseRental(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestRentalHandler_PauseRental_NoAuthHeader(t *testing.T) {
+	handler := &RentalHandler{rentalService: &MockRentalService{}}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/rentals/pause", nil)
+	w := httptest.NewRecorder()
+
+	handler.PauseRental(w, req)
+
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
 
-func TestRentalHandler_GetRentalHistory_WithPagination(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
+func TestRentalHandler_ResumeRental_Success(t *testing.T) {
+	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
 
-	testUser := &models.User{ID: 1}
-	token, _ := utils.GenerateJWT(testUser)
+	mockService := &MockRentalService{
+		ResumeRentalFunc: func(userID int) (*models.Rental, error) {
+			return &models.Rental{ID: 1, UserID: userID, BikeID: 1, Status: models.RentalStatusRunning}, nil
+		},
+	}
+
+	handler := &RentalHandler{rentalService: mockService}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/rentals/resume", nil)
+	req = withUser(req, testUser)
+	w := httptest.NewRecorder()
+
+	handler.ResumeRental(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRentalHandler_ResumeRental_NotPaused(t *testing.T) {
+	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
 
 	mockService := &MockRentalService{
-		GetRentalHistoryFunc: func(userID, page, limit int) ([]*models.Rental, int, error) {
-			return []*models.Rental{
-				{ID: 1, UserID: userID, BikeID: 1},
-			}, 1, nil
+		ResumeRentalFunc: func(userID int) (*models.Rental, error) {
+			return nil, constants.ErrRentalNotPaused
 		},
 	}
 
 	handler := &RentalHandler{rentalService: mockService}
-	req := httptest.NewRequest(http.MethodGet, "/rentals/history?page=2&limit=5", nil)
-	req.Header.Set("Authorization", "Bearer "+token)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/rentals/resume", nil)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
-	handler.GetRentalHistory(w, req)
+	handler.ResumeRental(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "not paused")
+}
+
+func TestRentalHandler_TrackRental_Success(t *testing.T) {
+	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
+	recordedAt := time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+
+	var received []models.RentalPoint
+	mockService := &MockRentalService{
+		TrackRentalFunc: func(userID int, points []models.RentalPoint) (int, error) {
+			received = points
+			return len(points), nil
+		},
+	}
+
+	handler := &RentalHandler{rentalService: mockService}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"points": []map[string]interface{}{
+			{"latitude": 40.4168, "longitude": -3.7038, "recorded_at": recordedAt},
+			{"latitude": 40.4170, "longitude": -3.7040},
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/rentals/track", bytes.NewReader(body))
+	req = withUser(req, testUser)
+	w := httptest.NewRecorder()
+
+	handler.TrackRental(w, req)
+
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"recorded":2`)
+	if assert.Len(t, received, 2) {
+		assert.True(t, received[0].RecordedAt.Equal(recordedAt))
+		assert.True(t, received[1].RecordedAt.IsZero())
+	}
+}
+
+func TestRentalHandler_TrackRental_InvalidPoints(t *testing.T) {
+	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "no points", body: `{"points":[]}`},
+		{name: "invalid latitude", body: `{"points":[{"latitude":91,"longitude":-3.7}]}`},
+		{name: "future point", body: `{"points":[{"latitude":40.4,"longitude":-3.7,"recorded_at":"` + time.Now().Add(time.Hour).Format(time.RFC3339) + `"}]}`},
+		{name: "invalid JSON", body: `{"points":`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &RentalHandler{}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/rentals/track", strings.NewReader(tt.body))
+			req = withUser(req, testUser)
+			w := httptest.NewRecorder()
+
+			handler.TrackRental(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestRentalHandler_TrackRental_NoActiveRental(t *testing.T) {
+	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
+
+	mockService := &MockRentalService{
+		TrackRentalFunc: func(userID int, points []models.RentalPoint) (int, error) {
+			return 0, constants.ErrNoActiveRental
+		},
+	}
+
+	handler := &RentalHandler{rentalService: mockService}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/rentals/track", strings.NewReader(`{"points":[{"latitude":40.4,"longitude":-3.7}]}`))
+	req = withUser(req, testUser)
+	w := httptest.NewRecorder()
+
+	handler.TrackRental(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestRentalHandler_GetRentalRoute(t *testing.T) {
+	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
+	start := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	mockService := &MockRentalService{
+		GetRentalRouteFunc: func(userID, rentalID int) (*models.RentalRoute, error) {
+			if rentalID != 7 {
+				return nil, constants.ErrRentalNotFound
+			}
+			return &models.RentalRoute{
+				RentalID:   7,
+				Status:     models.RentalStatusEnded,
+				DistanceKm: 0.03,
+				Points: []models.RentalPoint{
+					{Latitude: 40.4168, Longitude: -3.7038, RecordedAt: start},
+					{Latitude: 40.4170, Longitude: -3.7040, RecordedAt: start.Add(time.Minute)},
+				},
+			}, nil
+		},
+	}
+
+	tests := []struct {
+		name        string
+		rentalID    string
+		query       string
+		wantStatus  int
+		contentType string
+		contains    string
+	}{
+		{name: "geojson", rentalID: "7", wantStatus: http.StatusOK, contentType: "application/geo+json", contains: `"LineString"`},
+		{name: "gpx", rentalID: "7", query: "?format=gpx", wantStatus: http.StatusOK, contentType: "application/gpx+xml", contains: "<trkpt"},
+		{name: "unknown format", rentalID: "7", query: "?format=kml", wantStatus: http.StatusBadRequest},
+		{name: "invalid id", rentalID: "abc", wantStatus: http.StatusBadRequest},
+		{name: "not found", rentalID: "8", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &RentalHandler{rentalService: mockService}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/rentals/"+tt.rentalID+"/route"+tt.query, nil)
+			req.SetPathValue("rental-id", tt.rentalID)
+			req = withUser(req, testUser)
+			w := httptest.NewRecorder()
+
+			handler.GetRentalRoute(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.contentType != "" {
+				assert.Contains(t, w.Header().Get("Content-Type"), tt.contentType)
+			}
+			if tt.contains != "" {
+				assert.Contains(t, w.Body.String(), tt.contains)
+			}
+		})
+	}
 }
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Nimirandad/bike-rental-service/internal/logger"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/services"
+	"github.com/Nimirandad/bike-rental-service/internal/types"
+)
+
+type ReservationService interface {
+	ReserveBike(ctx context.Context, userID, bikeID int) (*models.Reservation, error)
+	CancelReservation(ctx context.Context, userID int) (*models.Reservation, error)
+}
+
+type ReservationHandler struct {
+	reservationService ReservationService
+}
+
+func NewReservationHandler(reservationService *services.ReservationService) *ReservationHandler {
+	return &ReservationHandler{reservationService: reservationService}
+}
+
+// ReserveBike godoc
+// @Summary Reserve a bike
+// @Description Hold a bike for the authenticated user for a limited time. Starting a rental on the bike converts the reservation.
+// @Tags rentals
+// @Accept json
+// @Produce json
+// @Param reservation body types.ReserveBikeRequest true "Bike to reserve"
+// @Security BearerAuth
+// @Success 200 {object} types.SuccessResponse{data=models.Reservation} "Bike reserved successfully"
+// @Failure 400 {object} types.Problem "Invalid request payload or bike_id"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 404 {object} types.Problem "Bike not found"
+// @Failure 409 {object} types.Problem "Bike not available or user already has a rental or reservation"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /rentals/reserve [post]
+func (h *ReservationHandler) ReserveBike(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	claims, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	userID := claims.Sub
+
+	var req types.ReserveBikeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Warn().Err(err).Int("user_id", userID).Msg("Failed to decode reserve bike request")
+		types.WriteError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if req.BikeID <= 0 {
+		log.Warn().Int("user_id", userID).Int("bike_id", req.BikeID).Msg("Invalid bike_id for reservation")
+		types.WriteError(w, http.StatusBadRequest, "Valid bike_id is required")
+		return
+	}
+
+	log.Info().Int("user_id", userID).Int("bike_id", req.BikeID).Msg("Attempting to reserve bike")
+
+	reservation, err := h.reservationService.ReserveBike(r.Context(), userID, req.BikeID)
+	if err != nil {
+		logServiceError(&log, err).Int("user_id", userID).Int("bike_id", req.BikeID).Msg("Failed to reserve bike")
+		types.WriteProblem(w, err, "Error reserving bike")
+		return
+	}
+
+	log.Info().Int("reservation_id", reservation.ID).Int("user_id", userID).Int("bike_id", req.BikeID).Time("expires_at", reservation.ExpiresAt).Msg("Bike reserved successfully")
+	types.WriteSuccess(w, "Bike reserved successfully", reservation)
+}
+
+// CancelReservation godoc
+// @Summary Cancel reservation
+// @Description Release the authenticated user's active reservation
+// @Tags rentals
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} types.SuccessResponse{data=models.Reservation} "Reservation cancelled successfully"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 409 {object} types.Problem "No active reservation"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /rentals/reserve [delete]
+func (h *ReservationHandler) CancelReservation(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	claims, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	userID := claims.Sub
+
+	reservation, err := h.reservationService.CancelReservation(r.Context(), userID)
+	if err != nil {
+		logServiceError(&log, err).Int("user_id", userID).Msg("Failed to cancel reservation")
+		types.WriteProblem(w, err, "Error cancelling reservation")
+		return
+	}
+
+	log.Info().Int("reservation_id", reservation.ID).Int("user_id", userID).Msg("Reservation cancelled successfully")
+	types.WriteSuccess(w, "Reservation cancelled successfully", reservation)
+}
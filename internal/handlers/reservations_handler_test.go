@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockReservationService struct {
+	ReserveBikeFunc       func(userID, bikeID int) (*models.Reservation, error)
+	CancelReservationFunc func(userID int) (*models.Reservation, error)
+}
+
+func (m *MockReservationService) ReserveBike(ctx context.Context, userID, bikeID int) (*models.Reservation, error) {
+	return m.ReserveBikeFunc(userID, bikeID)
+}
+
+func (m *MockReservationService) CancelReservation(ctx context.Context, userID int) (*models.Reservation, error) {
+	return m.CancelReservationFunc(userID)
+}
+
+var reservationTestUser = &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
+
+func TestReservationHandler_ReserveBike_Success(t *testing.T) {
+	mockService := &MockReservationService{
+		ReserveBikeFunc: func(userID, bikeID int) (*models.Reservation, error) {
+			return &models.Reservation{ID: 1, UserID: userID, BikeID: bikeID, Status: models.ReservationStatusActive, ExpiresAt: time.Now().Add(10 * time.Minute)}, nil
+		},
+	}
+
+	handler := &ReservationHandler{reservationService: mockService}
+	body, _ := json.Marshal(map[string]int{"bike_id": 3})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/rentals/reserve", bytes.NewReader(body))
+	req = withUser(req, reservationTestUser)
+	w := httptest.NewRecorder()
+
+	handler.ReserveBike(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestReservationHandler_ReserveBike_NoAuthHeader(t *testing.T) {
+	handler := &ReservationHandler{reservationService: &MockReservationService{}}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/rentals/reserve", nil)
+	w := httptest.NewRecorder()
+
+	handler.ReserveBike(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestReservationHandler_ReserveBike_InvalidBikeID(t *testing.T) {
+	handler := &ReservationHandler{reservationService: &MockReservationService{}}
+	body, _ := json.Marshal(map[string]int{"bike_id": 0})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/rentals/reserve", bytes.NewReader(body))
+	req = withUser(req, reservationTestUser)
+	w := httptest.NewRecorder()
+
+	handler.ReserveBike(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestReservationHandler_ReserveBike_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"Bike reserved", constants.ErrBikeReserved, http.StatusConflict},
+		{"Bike not available", constants.ErrBikeNotAvailable, http.StatusConflict},
+		{"User has reservation", constants.ErrUserHasActiveReservation, http.StatusConflict},
+		{"User has rental", constants.ErrUserHasActiveRental, http.StatusConflict},
+		{"Bike not found", constants.ErrBikeNotFound, http.StatusNotFound},
+		{"Internal error", errors.New("database error"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockReservationService{
+				ReserveBikeFunc: func(userID, bikeID int) (*models.Reservation, error) {
+					return nil, tt.err
+				},
+			}
+
+			handler := &ReservationHandler{reservationService: mockService}
+			body, _ := json.Marshal(map[string]int{"bike_id": 3})
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/rentals/reserve", bytes.NewReader(body))
+			req = withUser(req, reservationTestUser)
+			w := httptest.NewRecorder()
+
+			handler.ReserveBike(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestReservationHandler_CancelReservation_Success(t *testing.T) {
+	mockService := &MockReservationService{
+		CancelReservationFunc: func(userID int) (*models.Reservation, error) {
+			return &models.Reservation{ID: 1, UserID: userID, Status: models.ReservationStatusCancelled}, nil
+		},
+	}
+
+	handler := &ReservationHandler{reservationService: mockService}
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/rentals/reserve", nil)
+	req = withUser(req, reservationTestUser)
+	w := httptest.NewRecorder()
+
+	handler.CancelReservation(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestReservationHandler_CancelReservation_NoActiveReservation(t *testing.T) {
+	mockService := &MockReservationService{
+		CancelReservationFunc: func(userID int) (*models.Reservation, error) {
+			return nil, constants.ErrNoActiveReservation
+		},
+	}
+
+	handler := &ReservationHandler{reservationService: mockService}
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/rentals/reserve", nil)
+	req = withUser(req, reservationTestUser)
+	w := httptest.NewRecorder()
+
+	handler.CancelReservation(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
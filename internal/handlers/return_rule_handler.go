@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Nimirandad/bike-rental-service/internal/logger"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/services"
+	"github.com/Nimirandad/bike-rental-service/internal/types"
+	"github.com/Nimirandad/bike-rental-service/internal/utils"
+)
+
+type ReturnRuleService interface {
+	GetReturnRule(ctx context.Context) (*models.ReturnDistanceRule, error)
+	UpdateReturnRule(ctx context.Context, rule *models.ReturnDistanceRule) (*models.ReturnDistanceRule, error)
+}
+
+type ReturnRuleHandler struct {
+	returnRuleService ReturnRuleService
+}
+
+func NewReturnRuleHandler(returnRuleService *services.ReturnRuleService) *ReturnRuleHandler {
+	return &ReturnRuleHandler{
+		returnRuleService: returnRuleService,
+	}
+}
+
+// GetReturnRule godoc
+// @Summary Get return distance rule (Admin)
+// @Description Get the rule limiting how far from the start or from a station a rental may end (requires admin authentication)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} types.SuccessResponse{data=models.ReturnDistanceRule} "Return rule retrieved successfully"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 403 {object} types.Problem "Forbidden - admin role lacks permission"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /admin/return-rule [get]
+func (h *ReturnRuleHandler) GetReturnRule(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	if _, ok := currentAdmin(w, r); !ok {
+		return
+	}
+
+	rule, err := h.returnRuleService.GetReturnRule(r.Context())
+	if err != nil {
+		logServiceError(&log, err).Msg("Error retrieving return rule")
+		types.WriteProblem(w, err, "Error retrieving return rule")
+		return
+	}
+
+	types.WriteSuccess(w, "Return rule retrieved successfully", rule)
+}
+
+// UpdateReturnRule godoc
+// @Summary Update return distance rule (Admin)
+// @Description Replace the rule limiting where rentals may end. It applies to rentals ending from then on (requires admin authentication)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param rule body types.ReturnRuleRequest true "Return rule"
+// @Security BearerAuth
+// @Success 200 {object} types.SuccessResponse{data=models.ReturnDistanceRule} "Return rule updated successfully"
+// @Failure 400 {object} types.Problem "Validation failed"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 403 {object} types.Problem "Forbidden - admin role lacks permission"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /admin/return-rule [put]
+func (h *ReturnRuleHandler) UpdateReturnRule(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	claims, ok := currentAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	var req types.ReturnRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Warn().Err(err).Msg("Failed to decode return rule request")
+		types.WriteError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	rule := &models.ReturnDistanceRule{Mode: models.ReturnDistanceMode(req.Mode), MaxDistanceKm: req.MaxDistanceKm}
+	if validationErrors := utils.ValidateReturnRule(rule); len(validationErrors) > 0 {
+		log.Warn().Interface("errors", validationErrors).Msg("Return rule validation failed")
+		types.WriteValidationErrors(w, validationErrors)
+		return
+	}
+
+	updated, err := h.returnRuleService.UpdateReturnRule(r.Context(), rule)
+	if err != nil {
+		logServiceError(&log, err).Str("mode", req.Mode).Msg("Error updating return rule")
+		types.WriteProblem(w, err, "Error updating return rule")
+		return
+	}
+
+	log.Info().Int("admin_id", claims.Sub).Str("mode", string(updated.Mode)).Float64("max_distance_km", updated.MaxDistanceKm).Msg("Return rule updated successfully")
+	types.WriteSuccess(w, "Return rule updated successfully", updated)
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockReturnRuleService struct {
+	GetReturnRuleFunc    func() (*models.ReturnDistanceRule, error)
+	UpdateReturnRuleFunc func(rule *models.ReturnDistanceRule) (*models.ReturnDistanceRule, error)
+}
+
+func (m *MockReturnRuleService) GetReturnRule(ctx context.Context) (*models.ReturnDistanceRule, error) {
+	return m.GetReturnRuleFunc()
+}
+
+func (m *MockReturnRuleService) UpdateReturnRule(ctx context.Context, rule *models.ReturnDistanceRule) (*models.ReturnDistanceRule, error) {
+	return m.UpdateReturnRuleFunc(rule)
+}
+
+func TestReturnRuleHandler_GetReturnRule(t *testing.T) {
+	mockService := &MockReturnRuleService{
+		GetReturnRuleFunc: func() (*models.ReturnDistanceRule, error) {
+			return &models.ReturnDistanceRule{Mode: models.ReturnDistanceFromStart, MaxDistanceKm: 5}, nil
+		},
+	}
+
+	handler := &ReturnRuleHandler{returnRuleService: mockService}
+	req := httptest.NewRequest(http.MethodGet, "/admin/return-rule", nil)
+	req = withAdmin(req, models.AdminRoleSupport)
+	w := httptest.NewRecorder()
+
+	handler.GetReturnRule(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data models.ReturnDistanceRule `json:"data"`
+	}
+	json.NewDecoder(w.Body).Decode(&response)
+	assert.Equal(t, models.ReturnDistanceFromStart, response.Data.Mode)
+	assert.Equal(t, 5.0, response.Data.MaxDistanceKm)
+}
+
+func TestReturnRuleHandler_GetReturnRule_ServiceError(t *testing.T) {
+	mockService := &MockReturnRuleService{
+		GetReturnRuleFunc: func() (*models.ReturnDistanceRule, error) {
+			return nil, errors.New("database error")
+		},
+	}
+
+	handler := &ReturnRuleHandler{returnRuleService: mockService}
+	req := httptest.NewRequest(http.MethodGet, "/admin/return-rule", nil)
+	req = withAdmin(req, models.AdminRoleSupport)
+	w := httptest.NewRecorder()
+
+	handler.GetReturnRule(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestReturnRuleHandler_UpdateReturnRule(t *testing.T) {
+	now := time.Now()
+	mockService := &MockReturnRuleService{
+		UpdateReturnRuleFunc: func(rule *models.ReturnDistanceRule) (*models.ReturnDistanceRule, error) {
+			assert.Equal(t, models.ReturnDistanceFromStation, rule.Mode)
+			assert.Equal(t, 0.5, rule.MaxDistanceKm)
+			rule.UpdatedAt = &now
+			return rule, nil
+		},
+	}
+
+	handler := &ReturnRuleHandler{returnRuleService: mockService}
+	req := httptest.NewRequest(http.MethodPut, "/admin/return-rule", strings.NewReader(`{"mode": "from_station", "max_distance_km": 0.5}`))
+	req = withAdmin(req, models.AdminRoleFleet)
+	w := httptest.NewRecorder()
+
+	handler.UpdateReturnRule(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestReturnRuleHandler_UpdateReturnRule_ValidationError(t *testing.T) {
+	handler := &ReturnRuleHandler{returnRuleService: &MockReturnRuleService{}}
+	req := httptest.NewRequest(http.MethodPut, "/admin/return-rule", strings.NewReader(`{"mode": "from_start"}`))
+	req = withAdmin(req, models.AdminRoleFleet)
+	w := httptest.NewRecorder()
+
+	handler.UpdateReturnRule(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var problem types.Problem
+	json.NewDecoder(w.Body).Decode(&problem)
+	assert.Equal(t, "validation_failed", problem.Code)
+	assert.Contains(t, problem.Details, "max_distance_km")
+}
+
+func TestReturnRuleHandler_UpdateReturnRule_InvalidJSON(t *testing.T) {
+	handler := &ReturnRuleHandler{returnRuleService: &MockReturnRuleService{}}
+	req := httptest.NewRequest(http.MethodPut, "/admin/return-rule", strings.NewReader(`{invalid`))
+	req = withAdmin(req, models.AdminRoleFleet)
+	w := httptest.NewRecorder()
+
+	handler.UpdateReturnRule(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
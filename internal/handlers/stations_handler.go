@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/logger"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/services"
+	"github.com/Nimirandad/bike-rental-service/internal/types"
+	"github.com/Nimirandad/bike-rental-service/internal/utils"
+)
+
+type StationService interface {
+	CreateStation(ctx context.Context, station *models.Station) (*models.Station, error)
+	GetAllStations(ctx context.Context, page, limit int) ([]*models.Station, int, error)
+	GetStationByID(ctx context.Context, stationID int) (*models.Station, error)
+	UpdateStation(ctx context.Context, station *models.Station) (*models.Station, error)
+	DeleteStation(ctx context.Context, stationID int) error
+}
+
+type StationHandler struct {
+	stationService StationService
+}
+
+func NewStationHandler(stationService *services.StationService) *StationHandler {
+	return &StationHandler{
+		stationService: stationService,
+	}
+}
+
+// GetStations godoc
+// @Summary List stations
+// @Description Get paginated list of docking stations with available bikes and free docks
+// @Tags stations
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page (max 100)" default(20)
+// @Success 200 {object} types.PaginatedResponse{data=[]models.Station} "Stations retrieved successfully"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /stations [get]
+func (h *StationHandler) GetStations(w http.ResponseWriter, r *http.Request) {
+	h.listStations(w, r)
+}
+
+// GetAllStations godoc
+// @Summary Get all stations (Admin)
+// @Description Get paginated list of stations with their areas and occupancy (requires admin authentication)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page (max 100)" default(20)
+// @Security BearerAuth
+// @Success 200 {object} types.PaginatedResponse{data=[]models.Station} "Stations retrieved successfully"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 403 {object} types.Problem "Forbidden - admin role lacks permission"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /admin/stations [get]
+func (h *StationHandler) GetAllStations(w http.ResponseWriter, r *http.Request) {
+	if _, ok := currentAdmin(w, r); !ok {
+		return
+	}
+
+	h.listStations(w, r)
+}
+
+func (h *StationHandler) listStations(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	page := constants.DefaultPage
+	if pageParam := r.URL.Query().Get("page"); pageParam != "" {
+		if p, err := strconv.Atoi(pageParam); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	limit := constants.DefaultLimit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		if l, err := strconv.Atoi(limitParam); err == nil && l > 0 && l <= constants.MaxLimit {
+			limit = l
+		}
+	}
+
+	stations, total, err := h.stationService.GetAllStations(r.Context(), page, limit)
+	if err != nil {
+		logServiceError(&log, err).Int("page", page).Int("limit", limit).Msg("Error retrieving stations")
+		types.WriteProblem(w, err, "Error retrieving stations")
+		return
+	}
+
+	log.Info().Int("total", total).Int("returned", len(stations)).Msg("Stations retrieved successfully")
+	types.WritePaginatedSuccess(w, "Stations retrieved successfully", stations, total, page, limit)
+}
+
+// CreateStation godoc
+// @Summary Create a station (Admin)
+// @Description Create a docking station with a capacity and either a radius or a polygon (requires admin authentication)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param station body types.StationRequest true "Station data"
+// @Security BearerAuth
+// @Success 200 {object} types.SuccessResponse{data=models.Station} "Station created successfully"
+// @Failure 400 {object} types.Problem "Validation failed"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 403 {object} types.Problem "Forbidden - admin role lacks permission"
+// @Failure 409 {object} types.Problem "Station name already taken"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /admin/stations [post]
+func (h *StationHandler) CreateStation(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	claims, ok := currentAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	var req types.StationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Warn().Err(err).Msg("Failed to decode create station request")
+		types.WriteError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	station := &models.Station{}
+	applyStationRequest(station, &req)
+
+	if validationErrors := utils.ValidateStation(station); len(validationErrors) > 0 {
+		log.Warn().Interface("errors", validationErrors).Msg("Station validation failed")
+		types.WriteValidationErrors(w, validationErrors)
+		return
+	}
+
+	created, err := h.stationService.CreateStation(r.Context(), station)
+	if err != nil {
+		logServiceError(&log, err).Str("name", station.Name).Msg("Error creating station")
+		types.WriteProblem(w, err, "Error creating station")
+		return
+	}
+
+	log.Info().Int("admin_id", claims.Sub).Int("station_id", created.ID).Str("name", created.Name).Msg("Station created successfully")
+	types.WriteSuccess(w, "Station created successfully", created)
+}
+
+// GetStation godoc
+// @Summary Get station details (Admin)
+// @Description Get a single station (requires admin authentication)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param station-id path int true "Station ID"
+// @Security BearerAuth
+// @Success 200 {object} types.SuccessResponse{data=models.Station} "Station retrieved successfully"
+// @Failure 400 {object} types.Problem "Invalid station ID"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 403 {object} types.Problem "Forbidden - admin role lacks permission"
+// @Failure 404 {object} types.Problem "Station not found"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /admin/stations/{station-id} [get]
+func (h *StationHandler) GetStation(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	_, ok := currentAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	stationIDStr := r.PathValue("station-id")
+	stationID, err := strconv.Atoi(stationIDStr)
+	if err != nil || stationID <= 0 {
+		log.Warn().Str("station_id", stationIDStr).Msg("Invalid station ID")
+		types.WriteError(w, http.StatusBadRequest, "Invalid station ID")
+		return
+	}
+
+	station, err := h.stationService.GetStationByID(r.Context(), stationID)
+	if err != nil {
+		logServiceError(&log, err).Int("station_id", stationID).Msg("Error retrieving station")
+		types.WriteProblem(w, err, "Error retrieving station")
+		return
+	}
+
+	types.WriteSuccess(w, "Station retrieved successfully", station)
+}
+
+// UpdateStation godoc
+// @Summary Update station (Admin)
+// @Description Update the fields provided on a station (requires admin authentication)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param station-id path int true "Station ID"
+// @Param station body types.StationRequest true "Station update data"
+// @Security BearerAuth
+// @Success 200 {object} types.SuccessResponse{data=models.Station} "Station updated successfully"
+// @Failure 400 {object} types.Problem "Invalid station ID or update data"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 403 {object} types.Problem "Forbidden - admin role lacks permission"
+// @Failure 404 {object} types.Problem "Station not found"
+// @Failure 409 {object} types.Problem "Station name already taken"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /admin/stations/{station-id} [patch]
+func (h *StationHandler) UpdateStation(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	claims, ok := currentAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	stationIDStr := r.PathValue("station-id")
+	stationID, err := strconv.Atoi(stationIDStr)
+	if err != nil || stationID <= 0 {
+		log.Warn().Str("station_id", stationIDStr).Msg("Invalid station ID in update request")
+		types.WriteError(w, http.StatusBadRequest, "Invalid station ID")
+		return
+	}
+
+	var req types.StationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Warn().Err(err).Int("station_id", stationID).Msg("Failed to decode update station request")
+		types.WriteError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	station, err := h.stationService.GetStationByID(r.Context(), stationID)
+	if err != nil {
+		logServiceError(&log, err).Int("station_id", stationID).Msg("Error retrieving station for update")
+		types.WriteProblem(w, err, "Error updating station")
+		return
+	}
+
+	applyStationRequest(station, &req)
+
+	if validationErrors := utils.ValidateStation(station); len(validationErrors) > 0 {
+		log.Warn().Interface("errors", validationErrors).Int("station_id", stationID).Msg("Station update validation failed")
+		types.WriteValidationErrors(w, validationErrors)
+		return
+	}
+
+	updated, err := h.stationService.UpdateStation(r.Context(), station)
+	if err != nil {
+		logServiceError(&log, err).Int("station_id", stationID).Msg("Error updating station")
+		types.WriteProblem(w, err, "Error updating station")
+		return
+	}
+
+	log.Info().Int("admin_id", claims.Sub).Int("station_id", stationID).Msg("Station updated successfully")
+	types.WriteSuccess(w, "Station updated successfully", updated)
+}
+
+// DeleteStation godoc
+// @Summary Delete station (Admin)
+// @Description Delete a station with no bikes parked at it (requires admin authentication)
+// @Tags admin
+// @Produce json
+// @Param station-id path int true "Station ID"
+// @Security BearerAuth
+// @Success 200 {object} types.SuccessResponse "Station deleted successfully"
+// @Failure 400 {object} types.Problem "Invalid station ID"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 403 {object} types.Problem "Forbidden - admin role lacks permission"
+// @Failure 404 {object} types.Problem "Station not found"
+// @Failure 409 {object} types.Problem "Station has bikes parked at it"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /admin/stations/{station-id} [delete]
+func (h *StationHandler) DeleteStation(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	claims, ok := currentAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	stationIDStr := r.PathValue("station-id")
+	stationID, err := strconv.Atoi(stationIDStr)
+	if err != nil || stationID <= 0 {
+		log.Warn().Str("station_id", stationIDStr).Msg("Invalid station ID in delete request")
+		types.WriteError(w, http.StatusBadRequest, "Invalid station ID")
+		return
+	}
+
+	if err := h.stationService.DeleteStation(r.Context(), stationID); err != nil {
+		logServiceError(&log, err).Int("station_id", stationID).Msg("Error deleting station")
+		types.WriteProblem(w, err, "Error deleting station")
+		return
+	}
+
+	log.Info().Int("admin_id", claims.Sub).Int("station_id", stationID).Msg("Station deleted successfully")
+	types.WriteSuccess(w, "Station deleted successfully", nil)
+}
+
+// applyStationRequest copies the fields present in req onto station. A
+// radius replaces the polygon and a polygon replaces the radius.
+func applyStationRequest(station *models.Station, req *types.StationRequest) {
+	if req.Name != nil {
+		station.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Latitude != nil {
+		station.Latitude = *req.Latitude
+	}
+	if req.Longitude != nil {
+		station.Longitude = *req.Longitude
+	}
+	if req.RadiusMeters != nil {
+		station.RadiusMeters = req.RadiusMeters
+		station.Polygon = nil
+	}
+	if req.Polygon != nil {
+		station.Polygon = *req.Polygon
+		station.RadiusMeters = nil
+	}
+	if req.Capacity != nil {
+		station.Capacity = *req.Capacity
+	}
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockStationService struct {
+	CreateStationFunc  func(station *models.Station) (*models.Station, error)
+	GetAllStationsFunc func(page, limit int) ([]*models.Station, int, error)
+	GetStationByIDFunc func(stationID int) (*models.Station, error)
+	UpdateStationFunc  func(station *models.Station) (*models.Station, error)
+	DeleteStationFunc  func(stationID int) error
+}
+
+func (m *MockStationService) CreateStation(ctx context.Context, station *models.Station) (*models.Station, error) {
+	return m.CreateStationFunc(station)
+}
+
+func (m *MockStationService) GetAllStations(ctx context.Context, page, limit int) ([]*models.Station, int, error) {
+	return m.GetAllStationsFunc(page, limit)
+}
+
+func (m *MockStationService) GetStationByID(ctx context.Context, stationID int) (*models.Station, error) {
+	return m.GetStationByIDFunc(stationID)
+}
+
+func (m *MockStationService) UpdateStation(ctx context.Context, station *models.Station) (*models.Station, error) {
+	return m.UpdateStationFunc(station)
+}
+
+func (m *MockStationService) DeleteStation(ctx context.Context, stationID int) error {
+	return m.DeleteStationFunc(stationID)
+}
+
+func TestStationHandler_GetStations_Public(t *testing.T) {
+	mockService := &MockStationService{
+		GetAllStationsFunc: func(page, limit int) ([]*models.Station, int, error) {
+			assert.Equal(t, 2, page)
+			assert.Equal(t, 5, limit)
+			return []*models.Station{{ID: 1, Name: "Sol", Capacity: 10, AvailableBikes: 3, FreeDocks: 6}}, 6, nil
+		},
+	}
+
+	handler := &StationHandler{stationService: mockService}
+	req := httptest.NewRequest(http.MethodGet, "/stations?page=2&limit=5", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetStations(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&resp)
+	station := resp["data"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, 3.0, station["available_bikes"])
+	assert.Equal(t, 6.0, station["free_docks"])
+}
+
+func TestStationHandler_GetAllStations_Unauthorized(t *testing.T) {
+	handler := &StationHandler{stationService: &MockStationService{}}
+	req := httptest.NewRequest(http.MethodGet, "/admin/stations", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetAllStations(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestStationHandler_CreateStation_Success(t *testing.T) {
+	mockService := &MockStationService{
+		CreateStationFunc: func(station *models.Station) (*models.Station, error) {
+			assert.Equal(t, "Sol", station.Name)
+			assert.Equal(t, 50.0, *station.RadiusMeters)
+			assert.Equal(t, 10, station.Capacity)
+			station.ID = 1
+			return station, nil
+		},
+	}
+
+	handler := &StationHandler{stationService: mockService}
+	body, _ := json.Marshal(map[string]interface{}{"name": " Sol ", "latitude": 40.4168, "longitude": -3.7038, "radius_meters": 50, "capacity": 10})
+	req := httptest.NewRequest(http.MethodPost, "/admin/stations", bytes.NewReader(body))
+	req = withAdmin(req, models.AdminRoleFleet)
+	w := httptest.NewRecorder()
+
+	handler.CreateStation(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestStationHandler_CreateStation_ValidationError(t *testing.T) {
+	handler := &StationHandler{stationService: &MockStationService{}}
+	body, _ := json.Marshal(map[string]interface{}{"latitude": 40.4168, "longitude": -3.7038})
+	req := httptest.NewRequest(http.MethodPost, "/admin/stations", bytes.NewReader(body))
+	req = withAdmin(req, models.AdminRoleFleet)
+	w := httptest.NewRecorder()
+
+	handler.CreateStation(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var resp types.Problem
+	json.NewDecoder(w.Body).Decode(&resp)
+	assert.Contains(t, resp.Details, "name")
+	assert.Contains(t, resp.Details, "capacity")
+	assert.Contains(t, resp.Details, "area")
+}
+
+func TestStationHandler_CreateStation_NameTaken(t *testing.T) {
+	mockService := &MockStationService{
+		CreateStationFunc: func(station *models.Station) (*models.Station, error) {
+			return nil, constants.ErrStationNameTaken
+		},
+	}
+
+	handler := &StationHandler{stationService: mockService}
+	body, _ := json.Marshal(map[string]interface{}{"name": "Sol", "latitude": 40.4168, "longitude": -3.7038, "radius_meters": 50, "capacity": 10})
+	req := httptest.NewRequest(http.MethodPost, "/admin/stations", bytes.NewReader(body))
+	req = withAdmin(req, models.AdminRoleFleet)
+	w := httptest.NewRecorder()
+
+	handler.CreateStation(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestStationHandler_GetStation_NotFound(t *testing.T) {
+	mockService := &MockStationService{
+		GetStationByIDFunc: func(stationID int) (*models.Station, error) {
+			return nil, constants.ErrStationNotFound
+		},
+	}
+
+	handler := &StationHandler{stationService: mockService}
+	req := httptest.NewRequest(http.MethodGet, "/admin/stations/99", nil)
+	req.SetPathValue("station-id", "99")
+	req = withAdmin(req, models.AdminRoleSupport)
+	w := httptest.NewRecorder()
+
+	handler.GetStation(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestStationHandler_UpdateStation_PolygonReplacesRadius(t *testing.T) {
+	radius := 50.0
+	mockService := &MockStationService{
+		GetStationByIDFunc: func(stationID int) (*models.Station, error) {
+			return &models.Station{ID: stationID, Name: "Sol", Latitude: 40.4155, Longitude: -3.7074, RadiusMeters: &radius, Capacity: 10}, nil
+		},
+		UpdateStationFunc: func(station *models.Station) (*models.Station, error) {
+			assert.Equal(t, "Sol", station.Name)
+			assert.Nil(t, station.RadiusMeters)
+			assert.Len(t, station.Polygon, 3)
+			return station, nil
+		},
+	}
+
+	handler := &StationHandler{stationService: mockService}
+	body, _ := json.Marshal(map[string]interface{}{"polygon": []map[string]float64{
+		{"latitude": 40.4154, "longitude": -3.7075},
+		{"latitude": 40.4154, "longitude": -3.7073},
+		{"latitude": 40.4156, "longitude": -3.7074},
+	}})
+	req := httptest.NewRequest(http.MethodPatch, "/admin/stations/1", bytes.NewReader(body))
+	req.SetPathValue("station-id", "1")
+	req = withAdmin(req, models.AdminRoleFleet)
+	w := httptest.NewRecorder()
+
+	handler.UpdateStation(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestStationHandler_UpdateStation_InvalidID(t *testing.T) {
+	handler := &StationHandler{stationService: &MockStationService{}}
+	req := httptest.NewRequest(http.MethodPatch, "/admin/stations/abc", bytes.NewReader([]byte(`{}`)))
+	req.SetPathValue("station-id", "abc")
+	req = withAdmin(req, models.AdminRoleFleet)
+	w := httptest.NewRecorder()
+
+	handler.UpdateStation(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestStationHandler_DeleteStation_InUse(t *testing.T) {
+	mockService := &MockStationService{
+		DeleteStationFunc: func(stationID int) error {
+			return constants.ErrStationInUse
+		},
+	}
+
+	handler := &StationHandler{stationService: mockService}
+	req := httptest.NewRequest(http.MethodDelete, "/admin/stations/1", nil)
+	req.SetPathValue("station-id", "1")
+	req = withAdmin(req, models.AdminRoleFleet)
+	w := httptest.NewRecorder()
+
+	handler.DeleteStation(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
@@ -1,10 +1,11 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"time"
 
-	"github.com/Nimirandad/bike-rental-service/internal/constants"
 	"github.com/Nimirandad/bike-rental-service/internal/logger"
 	"github.com/Nimirandad/bike-rental-service/internal/models"
 	"github.com/Nimirandad/bike-rental-service/internal/services"
@@ -13,34 +14,51 @@ import (
 )
 
 type UserService interface {
-	RegisterUser(email, password, firstName, lastName string) (*models.User, error)
-	Login(email, password string) (*models.User, error)
-	GetByID(userID int) (*models.User, error)
-	UpdateUser(userID int, email, firstName, lastName *string) (*models.User, error)
+	RegisterUser(ctx context.Context, email, password, firstName, lastName string) (*models.User, error)
+	Login(ctx context.Context, email, password string) (*models.User, error)
+	GetByID(ctx context.Context, userID int) (*models.User, error)
+	UpdateUser(ctx context.Context, userID int, email, firstName, lastName *string) (*models.User, error)
+}
+
+type TokenService interface {
+	IssueTokens(ctx context.Context, user *models.User) (*models.TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*models.TokenPair, error)
+	Logout(ctx context.Context, userID int, accessJTI string, accessExpiresAt time.Time, refreshToken string) error
+	LogoutAll(ctx context.Context, userID int, accessJTI string, accessExpiresAt time.Time) error
 }
 
 type UserHandler struct {
-	userService UserService
+	userService    UserService
+	tokenService   TokenService
+	accountService AccountService
 }
 
-func NewUserHandler(userService *services.UserService) *UserHandler {
-	return &UserHandler{userService: userService}
+func NewUserHandler(userService *services.UserService, tokenService *services.TokenService, accountService *services.AccountService) *UserHandler {
+	return &UserHandler{userService: userService, tokenService: tokenService, accountService: accountService}
+}
+
+func newLoginResponse(pair *models.TokenPair) types.LoginResponse {
+	return types.LoginResponse{
+		Token:        pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		ExpiresIn:    int(time.Until(pair.AccessExpiresAt).Seconds()),
+	}
 }
 
 // RegisterUser godoc
 // @Summary Register a new user
-// @Description Register a new user with email, password, first name and last name
+// @Description Register a new user with email, password, first name and last name. A verification link is emailed to the new address
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param user body types.RegisterUserRequest true "User registration data"
 // @Success 200 {object} types.SuccessResponse{data=models.User} "User registered successfully"
-// @Failure 400 {object} types.ErrorResponse "Invalid request payload"
-// @Failure 409 {object} types.ErrorResponse "Email already exists"
-// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 400 {object} types.Problem "Invalid request payload"
+// @Failure 409 {object} types.Problem "Email already exists"
+// @Failure 500 {object} types.Problem "Internal server error"
 // @Router /users/register [post]
 func (h *UserHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
-	log := logger.Get()
+	log := logger.FromContext(r.Context())
 	var req types.RegisterUserRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -57,36 +75,37 @@ func (h *UserHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.userService.RegisterUser(req.Email, req.Password, req.FirstName, req.LastName)
+	user, err := h.userService.RegisterUser(r.Context(), req.Email, req.Password, req.FirstName, req.LastName)
 	if err != nil {
-		if err == constants.ErrEmailAlreadyExists {
-			log.Warn().Str("email", req.Email).Msg("Registration failed: email already exists")
-			types.WriteError(w, http.StatusConflict, err.Error())
-			return
-		}
-		log.Error().Err(err).Str("email", req.Email).Msg("Failed to register user")
-		types.WriteError(w, http.StatusInternalServerError, err.Error())
+		logServiceError(&log, err).Str("email", req.Email).Msg("Failed to register user")
+		types.WriteProblem(w, err, "Error registering user")
 		return
 	}
 
+	// The account is usable right away, so failing to queue the email must not
+	// fail the registration; the user can ask for a new link later.
+	if err := h.accountService.RequestEmailVerification(r.Context(), user.ID); err != nil {
+		log.Error().Err(err).Int("user_id", user.ID).Msg("Failed to queue verification email")
+	}
+
 	log.Info().Int("user_id", user.ID).Str("email", user.Email).Msg("User registered successfully")
 	types.WriteSuccess(w, "User registered successfully", user)
 }
 
 // LoginUser godoc
 // @Summary User login
-// @Description Authenticate user and return JWT token
+// @Description Authenticate user and return a short-lived JWT access token and a refresh token
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param credentials body types.LoginRequest true "Login credentials"
 // @Success 200 {object} types.SuccessResponse{data=types.LoginResponse} "Login successful with JWT token"
-// @Failure 400 {object} types.ErrorResponse "Invalid request payload"
-// @Failure 401 {object} types.ErrorResponse "Invalid credentials"
-// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 400 {object} types.Problem "Invalid request payload"
+// @Failure 401 {object} types.Problem "Invalid credentials"
+// @Failure 500 {object} types.Problem "Internal server error"
 // @Router /users/login [post]
 func (h *UserHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
-	log := logger.Get()
+	log := logger.FromContext(r.Context())
 	var req types.LoginRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -103,75 +122,156 @@ func (h *UserHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.userService.Login(req.Email, req.Password)
+	user, err := h.userService.Login(r.Context(), req.Email, req.Password)
 	if err != nil {
-		if err == constants.ErrInvalidCredentials {
-			log.Warn().Str("email", req.Email).Msg("Login failed: invalid credentials")
-			types.WriteError(w, http.StatusUnauthorized, "Invalid email or password")
-			return
-		}
-		log.Error().Err(err).Str("email", req.Email).Msg("Login error")
-		types.WriteError(w, http.StatusInternalServerError, "Internal server error")
+		logServiceError(&log, err).Str("email", req.Email).Msg("Login error")
+		types.WriteProblem(w, err, "Internal server error")
 		return
 	}
 
-	token, err := utils.GenerateJWT(user)
+	pair, err := h.tokenService.IssueTokens(r.Context(), user)
 	if err != nil {
-		log.Error().Err(err).Int("user_id", user.ID).Msg("Failed to generate JWT token")
-		types.WriteError(w, http.StatusInternalServerError, "Error generating token")
+		logServiceError(&log, err).Int("user_id", user.ID).Msg("Failed to generate tokens")
+		types.WriteProblem(w, err, "Error generating token")
 		return
 	}
 
 	log.Info().Int("user_id", user.ID).Str("email", user.Email).Msg("Login successful")
 
-	loginResponse := types.LoginResponse{
-		Token: token,
+	types.WriteSuccess(w, "Login successful", newLoginResponse(pair))
+}
+
+// RefreshToken godoc
+// @Summary Refresh access token
+// @Description Exchange a refresh token for a new access token and refresh token. Each refresh token can be used once; reusing one revokes every session derived from the same login
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param token body types.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} types.SuccessResponse{data=types.LoginResponse} "Token refreshed successfully"
+// @Failure 400 {object} types.Problem "Invalid request payload"
+// @Failure 401 {object} types.Problem "Invalid, expired or reused refresh token"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /users/token/refresh [post]
+func (h *UserHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+	var req types.RefreshTokenRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error().Err(err).Msg("Failed to decode refresh token request")
+		types.WriteError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if req.RefreshToken == "" {
+		types.WriteValidationErrors(w, map[string]string{"refresh_token": "Refresh token is required"})
+		return
+	}
+
+	pair, err := h.tokenService.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		logServiceError(&log, err).Msg("Failed to refresh token")
+		types.WriteProblem(w, err, "Internal server error")
+		return
 	}
 
-	types.WriteSuccess(w, "Login successful", loginResponse)
+	log.Info().Msg("Token refreshed successfully")
+	types.WriteSuccess(w, "Token refreshed successfully", newLoginResponse(pair))
 }
 
-// GetUserProfile godoc
-// @Summary Get user profile
-// @Description Get authenticated user's profile information
+// Logout godoc
+// @Summary Log out
+// @Description Revoke the current access token. When a refresh token is sent, the session it belongs to is revoked too
 // @Tags users
 // @Accept json
 // @Produce json
+// @Param token body types.RefreshTokenRequest false "Refresh token of the session to end"
 // @Security BearerAuth
-// @Success 200 {object} types.SuccessResponse{data=models.User} "User profile retrieved successfully"
-// @Failure 401 {object} types.ErrorResponse "Unauthorized - missing or invalid token"
-// @Failure 404 {object} types.ErrorResponse "User not found"
-// @Router /users/profile [get]
-func (h *UserHandler) GetUserProfile(w http.ResponseWriter, r *http.Request) {
-	log := logger.Get()
+// @Success 200 {object} types.SuccessResponse "Logged out successfully"
+// @Failure 400 {object} types.Problem "Invalid request payload"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /users/logout [post]
+func (h *UserHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	claims, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req types.RefreshTokenRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Warn().Err(err).Int("user_id", claims.Sub).Msg("Failed to decode logout request")
+			types.WriteError(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+	}
 
-	authHeader := r.Header.Get("Authorization")
-	if authHeader == "" {
-		log.Warn().Msg("Attempt to get profile without authorization header")
-		types.WriteError(w, http.StatusUnauthorized, "Authorization header is required")
+	if err := h.tokenService.Logout(r.Context(), claims.Sub, claims.ID, claims.ExpiresAt.Time, req.RefreshToken); err != nil {
+		logServiceError(&log, err).Int("user_id", claims.Sub).Msg("Failed to log out")
+		types.WriteProblem(w, err, "Internal server error")
 		return
 	}
 
-	tokenString, err := utils.ExtractTokenFromHeader(authHeader)
-	if err != nil {
-		log.Warn().Err(err).Msg("Invalid authorization header format")
-		types.WriteError(w, http.StatusUnauthorized, err.Error())
+	log.Info().Int("user_id", claims.Sub).Msg("User logged out")
+	types.WriteSuccess(w, "Logged out successfully", nil)
+}
+
+// LogoutAll godoc
+// @Summary Log out everywhere
+// @Description Revoke every refresh token of the authenticated user and the access tokens issued with them
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} types.SuccessResponse "Logged out from all sessions"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 500 {object} types.Problem "Internal server error"
+// @Router /users/logout-all [post]
+func (h *UserHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	claims, ok := currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	claims, err := utils.ValidateJWT(tokenString)
-	if err != nil {
-		log.Warn().Err(err).Msg("Invalid or expired token for get profile")
-		types.WriteError(w, http.StatusUnauthorized, "Invalid or expired token")
+	if err := h.tokenService.LogoutAll(r.Context(), claims.Sub, claims.ID, claims.ExpiresAt.Time); err != nil {
+		logServiceError(&log, err).Int("user_id", claims.Sub).Msg("Failed to log out from all sessions")
+		types.WriteProblem(w, err, "Internal server error")
+		return
+	}
+
+	log.Info().Int("user_id", claims.Sub).Msg("User logged out from all sessions")
+	types.WriteSuccess(w, "Logged out from all sessions", nil)
+}
+
+// GetUserProfile godoc
+// @Summary Get user profile
+// @Description Get authenticated user's profile information
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} types.SuccessResponse{data=models.User} "User profile retrieved successfully"
+// @Failure 401 {object} types.Problem "Unauthorized - missing or invalid token"
+// @Failure 404 {object} types.Problem "User not found"
+// @Router /users/profile [get]
+func (h *UserHandler) GetUserProfile(w http.ResponseWriter, r *http.Request) {
+	log := logger.FromContext(r.Context())
+
+	claims, ok := currentUser(w, r)
+	if !ok {
 		return
 	}
 
 	log.Info().Int("user_id", claims.Sub).Msg("Fetching user profile")
 
-	user, err := h.userService.GetByID(claims.Sub)
+	user, err := h.userService.GetByID(r.Context(), claims.Sub)
 	if err != nil {
-		log.Error().Err(err).Int("user_id", claims.Sub).Msg("User not found")
-		types.WriteError(w, http.StatusNotFound, "User not found")
+		logServiceError(&log, err).Int("user_id", claims.Sub).Msg("Error retrieving user profile")
+		types.WriteProblem(w, err, "Error retrieving user profile")
 		return
 	}
 
@@ -188,32 +288,16 @@ func (h *UserHandler) GetUserProfile(w http.ResponseWriter, r *http.Request) {
 // @Param user body types.UpdateUserRequest true "Profile update data"
 // @Security BearerAuth
 // @Success 200 {object} types.SuccessResponse{data=models.User} "Profile updated successfully"
-// @Failure 400 {object} types.ErrorResponse "Invalid request payload or validation errors"
-// @Failure 401 {object} types.ErrorResponse "Unauthorized"
-// @Failure 409 {object} types.ErrorResponse "Email already in use"
-// @Failure 500 {object} types.ErrorResponse "Internal server error"
+// @Failure 400 {object} types.Problem "Invalid request payload or validation errors"
+// @Failure 401 {object} types.Problem "Unauthorized"
+// @Failure 409 {object} types.Problem "Email already in use"
+// @Failure 500 {object} types.Problem "Internal server error"
 // @Router /users/profile [patch]
 func (h *UserHandler) UpdateUserProfile(w http.ResponseWriter, r *http.Request) {
-	log := logger.Get()
-
-	authHeader := r.Header.Get("Authorization")
-	if authHeader == "" {
-		log.Warn().Msg("Attempt to update profile without authorization header")
-		types.WriteError(w, http.StatusUnauthorized, "Authorization header is required")
-		return
-	}
+	log := logger.FromContext(r.Context())
 
-	tokenString, err := utils.ExtractTokenFromHeader(authHeader)
-	if err != nil {
-		log.Warn().Err(err).Msg("Invalid authorization header format")
-		types.WriteError(w, http.StatusUnauthorized, err.Error())
-		return
-	}
-
-	claims, err := utils.ValidateJWT(tokenString)
-	if err != nil {
-		log.Warn().Err(err).Msg("Invalid or expired token for update profile")
-		types.WriteError(w, http.StatusUnauthorized, "Invalid or expired token")
+	claims, ok := currentUser(w, r)
+	if !ok {
 		return
 	}
 
@@ -238,15 +322,10 @@ func (h *UserHandler) UpdateUserProfile(w http.ResponseWriter, r *http.Request)
 
 	log.Info().Int("user_id", claims.Sub).Msg("Attempting to update user profile")
 
-	user, err := h.userService.UpdateUser(claims.Sub, req.Email, req.FirstName, req.LastName)
+	user, err := h.userService.UpdateUser(r.Context(), claims.Sub, req.Email, req.FirstName, req.LastName)
 	if err != nil {
-		if err == constants.ErrEmailAlreadyExists {
-			log.Warn().Int("user_id", claims.Sub).Msg("Email already in use")
-			types.WriteError(w, http.StatusConflict, "Email already in use")
-			return
-		}
-		log.Error().Err(err).Int("user_id", claims.Sub).Msg("Error updating profile")
-		types.WriteError(w, http.StatusInternalServerError, "Error updating profile")
+		logServiceError(&log, err).Int("user_id", claims.Sub).Msg("Error updating profile")
+		types.WriteProblem(w, err, "Error updating profile")
 		return
 	}
 
@@ -2,16 +2,18 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"testing"
+	"time"
 
 	"github.com/Nimirandad/bike-rental-service/internal/constants"
 	"github.com/Nimirandad/bike-rental-service/internal/models"
-	"github.com/Nimirandad/bike-rental-service/internal/utils"
+	"github.com/Nimirandad/bike-rental-service/internal/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -22,22 +24,53 @@ type MockUserService2 struct {
 	UpdateUserFunc   func(userID int, email, firstName, lastName *string) (*models.User, error)
 }
 
-func (m *MockUserService2) RegisterUser(email, password, firstName, lastName string) (*models.User, error) {
+func (m *MockUserService2) RegisterUser(ctx context.Context, email, password, firstName, lastName string) (*models.User, error) {
 	return m.RegisterUserFunc(email, password, firstName, lastName)
 }
 
-func (m *MockUserService2) Login(email, password string) (*models.User, error) {
+func (m *MockUserService2) Login(ctx context.Context, email, password string) (*models.User, error) {
 	return m.LoginFunc(email, password)
 }
 
-func (m *MockUserService2) GetByID(userID int) (*models.User, error) {
+func (m *MockUserService2) GetByID(ctx context.Context, userID int) (*models.User, error) {
 	return m.GetByIDFunc(userID)
 }
 
-func (m *MockUserService2) UpdateUser(userID int, email, firstName, lastName *string) (*models.User, error) {
+func (m *MockUserService2) UpdateUser(ctx context.Context, userID int, email, firstName, lastName *string) (*models.User, error) {
 	return m.UpdateUserFunc(userID, email, firstName, lastName)
 }
 
+type MockTokenService struct {
+	IssueTokensFunc func(user *models.User) (*models.TokenPair, error)
+	RefreshFunc     func(refreshToken string) (*models.TokenPair, error)
+	LogoutFunc      func(userID int, accessJTI string, accessExpiresAt time.Time, refreshToken string) error
+	LogoutAllFunc   func(userID int, accessJTI string, accessExpiresAt time.Time) error
+}
+
+func (m *MockTokenService) IssueTokens(ctx context.Context, user *models.User) (*models.TokenPair, error) {
+	return m.IssueTokensFunc(user)
+}
+
+func (m *MockTokenService) Refresh(ctx context.Context, refreshToken string) (*models.TokenPair, error) {
+	return m.RefreshFunc(refreshToken)
+}
+
+func (m *MockTokenService) Logout(ctx context.Context, userID int, accessJTI string, accessExpiresAt time.Time, refreshToken string) error {
+	return m.LogoutFunc(userID, accessJTI, accessExpiresAt, refreshToken)
+}
+
+func (m *MockTokenService) LogoutAll(ctx context.Context, userID int, accessJTI string, accessExpiresAt time.Time) error {
+	return m.LogoutAllFunc(userID, accessJTI, accessExpiresAt)
+}
+
+func testTokenPair() *models.TokenPair {
+	return &models.TokenPair{
+		AccessToken:     "access-token",
+		AccessExpiresAt: time.Now().Add(15 * time.Minute),
+		RefreshToken:    "refresh-token",
+	}
+}
+
 func TestUserHandler_RegisterUser_Success(t *testing.T) {
 	mockService := &MockUserService2{
 		RegisterUserFunc: func(email, password, firstName, lastName string) (*models.User, error) {
@@ -45,7 +78,37 @@ func TestUserHandler_RegisterUser_Success(t *testing.T) {
 		},
 	}
 
-	handler := &UserHandler{userService: mockService}
+	var verifiedUserID int
+	mockAccounts := &MockAccountService{
+		RequestEmailVerificationFunc: func(userID int) error {
+			verifiedUserID = userID
+			return nil
+		},
+	}
+
+	handler := &UserHandler{userService: mockService, accountService: mockAccounts}
+	body, _ := json.Marshal(map[string]string{"email": "test@example.com", "password": "password123", "first_name": "John", "last_name": "Doe"})
+	req := httptest.NewRequest(http.MethodPost, "/api/register", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.RegisterUser(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, verifiedUserID)
+}
+
+func TestUserHandler_RegisterUser_VerificationEmailFails(t *testing.T) {
+	mockService := &MockUserService2{
+		RegisterUserFunc: func(email, password, firstName, lastName string) (*models.User, error) {
+			return &models.User{ID: 1, Email: email, FirstName: firstName, LastName: lastName}, nil
+		},
+	}
+	mockAccounts := &MockAccountService{
+		RequestEmailVerificationFunc: func(userID int) error {
+			return errors.New("database error")
+		},
+	}
+
+	handler := &UserHandler{userService: mockService, accountService: mockAccounts}
 	body, _ := json.Marshal(map[string]string{"email": "test@example.com", "password": "password123", "first_name": "John", "last_name": "Doe"})
 	req := httptest.NewRequest(http.MethodPost, "/api/register", bytes.NewReader(body))
 	w := httptest.NewRecorder()
@@ -106,22 +169,162 @@ func TestUserHandler_RegisterUser_InternalError(t *testing.T) {
 }
 
 func TestUserHandler_LoginUser_Success(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
-
 	mockService := &MockUserService2{
 		LoginFunc: func(email, password string) (*models.User, error) {
 			return &models.User{ID: 1, Email: email, FirstName: "John", LastName: "Doe"}, nil
 		},
 	}
+	mockTokens := &MockTokenService{
+		IssueTokensFunc: func(user *models.User) (*models.TokenPair, error) {
+			return testTokenPair(), nil
+		},
+	}
 
-	handler := &UserHandler{userService: mockService}
+	handler := &UserHandler{userService: mockService, tokenService: mockTokens}
 	body, _ := json.Marshal(map[string]string{"email": "test@example.com", "password": "password123"})
 	req := httptest.NewRequest(http.MethodPost, "/api/login", bytes.NewReader(body))
 	w := httptest.NewRecorder()
 
 	handler.LoginUser(w, req)
 	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data types.LoginResponse `json:"data"`
+	}
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, "access-token", response.Data.Token)
+	assert.Equal(t, "refresh-token", response.Data.RefreshToken)
+	assert.InDelta(t, 900, response.Data.ExpiresIn, 2)
+}
+
+func TestUserHandler_LoginUser_TokenError(t *testing.T) {
+	mockService := &MockUserService2{
+		LoginFunc: func(email, password string) (*models.User, error) {
+			return &models.User{ID: 1, Email: email}, nil
+		},
+	}
+	mockTokens := &MockTokenService{
+		IssueTokensFunc: func(user *models.User) (*models.TokenPair, error) {
+			return nil, errors.New("database error")
+		},
+	}
+
+	handler := &UserHandler{userService: mockService, tokenService: mockTokens}
+	body, _ := json.Marshal(map[string]string{"email": "test@example.com", "password": "password123"})
+	req := httptest.NewRequest(http.MethodPost, "/api/login", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.LoginUser(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestUserHandler_RefreshToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{"Success", `{"refresh_token":"refresh-token"}`, nil, http.StatusOK},
+		{"Invalid JSON", `invalid`, nil, http.StatusBadRequest},
+		{"Missing token", `{}`, nil, http.StatusBadRequest},
+		{"Invalid token", `{"refresh_token":"unknown"}`, constants.ErrInvalidRefreshToken, http.StatusUnauthorized},
+		{"Reused token", `{"refresh_token":"refresh-token"}`, constants.ErrRefreshTokenReused, http.StatusUnauthorized},
+		{"Service error", `{"refresh_token":"refresh-token"}`, errors.New("database error"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTokens := &MockTokenService{
+				RefreshFunc: func(refreshToken string) (*models.TokenPair, error) {
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return testTokenPair(), nil
+				},
+			}
+
+			handler := &UserHandler{tokenService: mockTokens}
+			req := httptest.NewRequest(http.MethodPost, "/api/users/token/refresh", bytes.NewReader([]byte(tt.body)))
+			w := httptest.NewRecorder()
+
+			handler.RefreshToken(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestUserHandler_Logout(t *testing.T) {
+	testUser := &models.User{ID: 1, Email: "test@example.com"}
+
+	var gotJTI, gotRefresh string
+	mockTokens := &MockTokenService{
+		LogoutFunc: func(userID int, accessJTI string, accessExpiresAt time.Time, refreshToken string) error {
+			gotJTI, gotRefresh = accessJTI, refreshToken
+			return nil
+		},
+	}
+	handler := &UserHandler{tokenService: mockTokens}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/users/logout", bytes.NewReader([]byte(`{"refresh_token":"refresh-token"}`)))
+	req = withUser(req, testUser)
+	w := httptest.NewRecorder()
+
+	handler.Logout(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "test-jti", gotJTI)
+	assert.Equal(t, "refresh-token", gotRefresh)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/users/logout", nil)
+	req = withUser(req, testUser)
+	w = httptest.NewRecorder()
+
+	handler.Logout(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, gotRefresh)
+}
+
+func TestUserHandler_Logout_InvalidJSON(t *testing.T) {
+	handler := &UserHandler{}
+	req := httptest.NewRequest(http.MethodPost, "/api/users/logout", bytes.NewReader([]byte("invalid")))
+	req = withUser(req, &models.User{ID: 1})
+	w := httptest.NewRecorder()
+
+	handler.Logout(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestUserHandler_LogoutAll(t *testing.T) {
+	var gotUserID int
+	mockTokens := &MockTokenService{
+		LogoutAllFunc: func(userID int, accessJTI string, accessExpiresAt time.Time) error {
+			gotUserID = userID
+			return nil
+		},
+	}
+	handler := &UserHandler{tokenService: mockTokens}
+
+	req := withUser(httptest.NewRequest(http.MethodPost, "/api/users/logout-all", nil), &models.User{ID: 3})
+	w := httptest.NewRecorder()
+
+	handler.LogoutAll(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 3, gotUserID)
+}
+
+func TestUserHandler_LogoutAll_ServiceError(t *testing.T) {
+	mockTokens := &MockTokenService{
+		LogoutAllFunc: func(userID int, accessJTI string, accessExpiresAt time.Time) error {
+			return errors.New("database error")
+		},
+	}
+	handler := &UserHandler{tokenService: mockTokens}
+
+	req := withUser(httptest.NewRequest(http.MethodPost, "/api/users/logout-all", nil), &models.User{ID: 3})
+	w := httptest.NewRecorder()
+
+	handler.LogoutAll(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
 
 func TestUserHandler_LoginUser_InvalidCredentials(t *testing.T) {
@@ -157,11 +360,7 @@ func TestUserHandler_LoginUser_ServiceError(t *testing.T) {
 }
 
 func TestUserHandler_GetUserProfile_Success(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
-
 	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
-	token, _ := utils.GenerateJWT(testUser)
 
 	mockService := &MockUserService2{
 		GetByIDFunc: func(userID int) (*models.User, error) {
@@ -171,7 +370,7 @@ func TestUserHandler_GetUserProfile_Success(t *testing.T) {
 
 	handler := &UserHandler{userService: mockService}
 	req := httptest.NewRequest(http.MethodGet, "/api/profile", nil)
-	req.Header.Set("Authorization", "Bearer "+token)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.GetUserProfile(w, req)
@@ -187,32 +386,18 @@ func TestUserHandler_GetUserProfile_NoAuthHeader(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
 
-func TestUserHandler_GetUserProfile_InvalidToken(t *testing.T) {
-	handler := &UserHandler{}
-	req := httptest.NewRequest(http.MethodGet, "/api/profile", nil)
-	req.Header.Set("Authorization", "Bearer invalid")
-	w := httptest.NewRecorder()
-
-	handler.GetUserProfile(w, req)
-	assert.Equal(t, http.StatusUnauthorized, w.Code)
-}
-
 func TestUserHandler_GetUserProfile_UserNotFound(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
-
 	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
-	token, _ := utils.GenerateJWT(testUser)
 
 	mockService := &MockUserService2{
 		GetByIDFunc: func(userID int) (*models.User, error) {
-			return nil, errors.New("not found")
+			return nil, fmt.Errorf("user with id %d: %w", userID, constants.ErrUserNotFound)
 		},
 	}
 
 	handler := &UserHandler{userService: mockService}
 	req := httptest.NewRequest(http.MethodGet, "/api/profile", nil)
-	req.Header.Set("Authorization", "Bearer "+token)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.GetUserProfile(w, req)
@@ -220,11 +405,7 @@ func TestUserHandler_GetUserProfile_UserNotFound(t *testing.T) {
 }
 
 func TestUserHandler_UpdateUserProfile_Success(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
-
 	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
-	token, _ := utils.GenerateJWT(testUser)
 
 	mockService := &MockUserService2{
 		UpdateUserFunc: func(userID int, email, firstName, lastName *string) (*models.User, error) {
@@ -235,7 +416,7 @@ func TestUserHandler_UpdateUserProfile_Success(t *testing.T) {
 	handler := &UserHandler{userService: mockService}
 	body, _ := json.Marshal(map[string]string{"first_name": "Johnny"})
 	req := httptest.NewRequest(http.MethodPut, "/api/profile", bytes.NewReader(body))
-	req.Header.Set("Authorization", "Bearer "+token)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.UpdateUserProfile(w, req)
@@ -243,16 +424,12 @@ func TestUserHandler_UpdateUserProfile_Success(t *testing.T) {
 }
 
 func TestUserHandler_UpdateUserProfile_NoFields(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
-
 	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
-	token, _ := utils.GenerateJWT(testUser)
 
 	handler := &UserHandler{}
 	body, _ := json.Marshal(map[string]interface{}{})
 	req := httptest.NewRequest(http.MethodPut, "/api/profile", bytes.NewReader(body))
-	req.Header.Set("Authorization", "Bearer "+token)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.UpdateUserProfile(w, req)
@@ -260,11 +437,7 @@ func TestUserHandler_UpdateUserProfile_NoFields(t *testing.T) {
 }
 
 func TestUserHandler_UpdateUserProfile_EmailExists(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
-
 	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
-	token, _ := utils.GenerateJWT(testUser)
 
 	mockService := &MockUserService2{
 		UpdateUserFunc: func(userID int, email, firstName, lastName *string) (*models.User, error) {
@@ -275,7 +448,7 @@ func TestUserHandler_UpdateUserProfile_EmailExists(t *testing.T) {
 	handler := &UserHandler{userService: mockService}
 	body, _ := json.Marshal(map[string]interface{}{"email": "existing@example.com"})
 	req := httptest.NewRequest(http.MethodPut, "/api/profile", bytes.NewReader(body))
-	req.Header.Set("Authorization", "Bearer "+token)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.UpdateUserProfile(w, req)
@@ -283,11 +456,7 @@ func TestUserHandler_UpdateUserProfile_EmailExists(t *testing.T) {
 }
 
 func TestUserHandler_UpdateUserProfile_InternalError(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
-
 	testUser := &models.User{ID: 1, Email: "test@example.com", FirstName: "John", LastName: "Doe"}
-	token, _ := utils.GenerateJWT(testUser)
 
 	mockService := &MockUserService2{
 		UpdateUserFunc: func(userID int, email, firstName, lastName *string) (*models.User, error) {
@@ -298,7 +467,7 @@ func TestUserHandler_UpdateUserProfile_InternalError(t *testing.T) {
 	handler := &UserHandler{userService: mockService}
 	body, _ := json.Marshal(map[string]string{"first_name": "Johnny"})
 	req := httptest.NewRequest(http.MethodPut, "/api/profile", bytes.NewReader(body))
-	req.Header.Set("Authorization", "Bearer "+token)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.UpdateUserProfile(w, req)
@@ -325,15 +494,11 @@ func TestUserHandler_LoginUser_InvalidJSON(t *testing.T) {
 }
 
 func TestUserHandler_UpdateUserProfile_InvalidJSON(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
-
 	testUser := &models.User{ID: 1, Email: "test@example.com"}
-	token, _ := utils.GenerateJWT(testUser)
 
 	handler := &UserHandler{}
 	req := httptest.NewRequest(http.MethodPut, "/api/profile", bytes.NewReader([]byte("invalid")))
-	req.Header.Set("Authorization", "Bearer "+token)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.UpdateUserProfile(w, req)
@@ -341,16 +506,12 @@ func TestUserHandler_UpdateUserProfile_InvalidJSON(t *testing.T) {
 }
 
 func TestUserHandler_UpdateUserProfile_ValidationError(t *testing.T) {
-	os.Setenv("JWT_SECRET", "test-secret")
-	defer os.Unsetenv("JWT_SECRET")
-
 	testUser := &models.User{ID: 1, Email: "test@example.com"}
-	token, _ := utils.GenerateJWT(testUser)
 
 	handler := &UserHandler{}
 	body, _ := json.Marshal(map[string]string{"email": "invalid-email"})
 	req := httptest.NewRequest(http.MethodPut, "/api/profile", bytes.NewReader(body))
-	req.Header.Set("Authorization", "Bearer "+token)
+	req = withUser(req, testUser)
 	w := httptest.NewRecorder()
 
 	handler.UpdateUserProfile(w, req)
@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"context"
 	"os"
 	"time"
 
@@ -34,3 +35,21 @@ func GetWithContext(fields map[string]interface{}) zerolog.Logger {
 	}
 	return logger
 }
+
+type contextKey struct{}
+
+// WithContext returns a copy of ctx carrying logger, so code further down the
+// request can log with the same fields (request ID, trace ID) through
+// FromContext.
+func WithContext(ctx context.Context, logger zerolog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger stored by WithContext, or Get() when ctx has
+// none, such as in background workers.
+func FromContext(ctx context.Context) zerolog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(zerolog.Logger); ok {
+		return logger
+	}
+	return Get()
+}
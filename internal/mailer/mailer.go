@@ -0,0 +1,31 @@
+// Package mailer delivers transactional email. Messages are not sent from
+// request handlers directly: they are queued in the email outbox and handed
+// to a Mailer by the outbox worker, so a mail server outage only delays them.
+package mailer
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// validate rejects messages whose headers could be used to inject extra
+// headers or recipients.
+func (m Message) validate() error {
+	if m.To == "" {
+		return fmt.Errorf("message has no recipient")
+	}
+	if strings.ContainsAny(m.To, "\r\n") || strings.ContainsAny(m.Subject, "\r\n") {
+		return fmt.Errorf("message headers must not contain line breaks")
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package mailer
+
+import (
+	"errors"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSMTPMailer_Send(t *testing.T) {
+	m := NewSMTPMailer("smtp.example.com", 587, "user", "pass", "no-reply@example.com")
+
+	var gotAddr, gotFrom string
+	var gotTo []string
+	var gotMsg []byte
+	m.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotFrom, gotTo, gotMsg = addr, from, to, msg
+		return nil
+	}
+
+	err := m.Send(Message{To: "rider@example.com", Subject: "Hello", Body: "line one\nline two"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "smtp.example.com:587", gotAddr)
+	assert.Equal(t, "no-reply@example.com", gotFrom)
+	assert.Equal(t, []string{"rider@example.com"}, gotTo)
+	assert.Contains(t, string(gotMsg), "Subject: Hello\r\n")
+	assert.True(t, strings.HasSuffix(string(gotMsg), "\r\n\r\nline one\r\nline two"))
+}
+
+func TestSMTPMailer_Send_Errors(t *testing.T) {
+	m := NewSMTPMailer("smtp.example.com", 587, "", "", "no-reply@example.com")
+	m.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		return errors.New("connection refused")
+	}
+
+	assert.Error(t, m.Send(Message{To: "rider@example.com", Subject: "Hello"}))
+	assert.Error(t, m.Send(Message{To: "rider@example.com", Subject: "Hello\r\nBcc: victim@example.com"}))
+	assert.Error(t, m.Send(Message{Subject: "Hello"}))
+}
+
+func TestLogMailer_Send_WritesFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	m := NewLogMailer(dir)
+
+	assert.NoError(t, m.Send(Message{To: "rider@example.com", Subject: "Hello", Body: "Open https://example.com"}))
+
+	files, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	content, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "Subject: Hello")
+	assert.Contains(t, string(content), "Open https://example.com")
+}
+
+func TestLogMailer_Send_WithoutDir(t *testing.T) {
+	assert.NoError(t, NewLogMailer("").Send(Message{To: "rider@example.com", Subject: "Hello"}))
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/Nimirandad/bike-rental-service/internal/logger"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type BikeCounter interface {
+	CountAvailable(ctx context.Context) (int, error)
+}
+
+type RentalCounter interface {
+	CountActive(ctx context.Context) (int, error)
+}
+
+// Collector reports gauges read from the database on every scrape, so they
+// stay correct across restarts and with several API instances.
+type Collector struct {
+	bikes   BikeCounter
+	rentals RentalCounter
+
+	availableBikes *prometheus.Desc
+	activeRentals  *prometheus.Desc
+}
+
+func NewCollector(bikes BikeCounter, rentals RentalCounter) *Collector {
+	return &Collector{
+		bikes:   bikes,
+		rentals: rentals,
+		availableBikes: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "bikes_available"),
+			"Bikes currently available to rent.",
+			nil, nil,
+		),
+		activeRentals: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "rentals_active"),
+			"Rentals currently running or paused.",
+			nil, nil,
+		),
+	}
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.availableBikes
+	ch <- c.activeRentals
+}
+
+// Collect skips a gauge whose query fails instead of failing the whole
+// scrape, so HTTP and runtime metrics are still reported during a database
+// outage.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	log := logger.Get()
+	ctx := context.Background()
+
+	if available, err := c.bikes.CountAvailable(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to collect available bikes metric")
+	} else {
+		ch <- prometheus.MustNewConstMetric(c.availableBikes, prometheus.GaugeValue, float64(available))
+	}
+
+	if active, err := c.rentals.CountActive(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to collect active rentals metric")
+	} else {
+		ch <- prometheus.MustNewConstMetric(c.activeRentals, prometheus.GaugeValue, float64(active))
+	}
+}
@@ -0,0 +1,115 @@
+// Package metrics holds the Prometheus metrics exposed on /metrics. HTTP and
+// business counters are package-level and recorded where the event happens;
+// values that live in the database, such as active rentals, are read by a
+// Collector at scrape time.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "bike_rental"
+
+// Return rejection reasons used as the reason label of ReturnsRejected.
+const (
+	ReasonLocationTooFar = "location_too_far"
+	ReasonOutsideStation = "outside_station"
+	ReasonStationFull    = "station_full"
+	ReasonOutsideArea    = "outside_operating_area"
+	ReasonNoParkingZone  = "no_parking_zone"
+)
+
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route pattern and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	RentalsStarted = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rentals_started_total",
+		Help:      "Rentals started.",
+	})
+
+	RentalsClosed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rentals_closed_total",
+		Help:      "Rentals closed, by final status (ended or cancelled).",
+	}, []string{"status"})
+
+	Revenue = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "revenue_euros_total",
+		Help:      "Total amount charged for ended rentals, in euros.",
+	})
+
+	Refunds = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "refunds_euros_total",
+		Help:      "Total amount refunded for ended rentals, in euros.",
+	})
+
+	ReturnsRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "returns_rejected_total",
+		Help:      "Attempts to end a rental that were rejected, by reason.",
+	}, []string{"reason"})
+)
+
+// Registry holds the package-level metrics plus the Go runtime and process
+// collectors.
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPRequestDuration,
+		RentalsStarted,
+		RentalsClosed,
+		Revenue,
+		Refunds,
+		ReturnsRejected,
+	)
+}
+
+// RecordRentalClosed counts a rental that reached status ended or cancelled
+// and adds its cost to the revenue total.
+func RecordRentalClosed(rental *models.Rental) {
+	RentalsClosed.WithLabelValues(string(rental.Status)).Inc()
+	if rental.Cost != nil && *rental.Cost > 0 {
+		Revenue.Add(*rental.Cost)
+	}
+}
+
+// RecordRefund adds the amount given back for a refunded rental.
+func RecordRefund(amount float64) {
+	if amount > 0 {
+		Refunds.Add(amount)
+	}
+}
+
+// Handler serves the metrics in Registry together with extra, which are
+// registered on a registry of their own so that building more than one
+// handler (one per server in tests) never registers a collector twice.
+func Handler(extra ...prometheus.Collector) http.Handler {
+	local := prometheus.NewRegistry()
+	local.MustRegister(extra...)
+
+	return promhttp.HandlerFor(prometheus.Gatherers{Registry, local}, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubCounts struct {
+	available int
+	active    int
+	err       error
+}
+
+func (s stubCounts) CountAvailable(ctx context.Context) (int, error) { return s.available, s.err }
+func (s stubCounts) CountActive(ctx context.Context) (int, error)    { return s.active, s.err }
+
+func scrape(t *testing.T, handler http.Handler) string {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("scrape returned %d: %s", w.Code, w.Body.String())
+	}
+	return w.Body.String()
+}
+
+func TestHandler_ExposesRegistryAndExtraCollectors(t *testing.T) {
+	counts := stubCounts{available: 7, active: 2}
+
+	body := scrape(t, Handler(NewCollector(counts, counts)))
+
+	assert.Contains(t, body, "bike_rental_bikes_available 7")
+	assert.Contains(t, body, "bike_rental_rentals_active 2")
+	assert.Contains(t, body, "go_goroutines")
+}
+
+func TestHandler_CanBeBuiltMoreThanOnce(t *testing.T) {
+	counts := stubCounts{available: 1}
+
+	scrape(t, Handler(NewCollector(counts, counts)))
+	body := scrape(t, Handler(NewCollector(counts, counts)))
+
+	assert.Contains(t, body, "bike_rental_bikes_available 1")
+}
+
+func TestCollector_SkipsGaugesThatFail(t *testing.T) {
+	counts := stubCounts{err: errors.New("database is down")}
+
+	body := scrape(t, Handler(NewCollector(counts, counts)))
+
+	assert.NotContains(t, body, "bike_rental_bikes_available ")
+	assert.NotContains(t, body, "bike_rental_rentals_active ")
+	assert.Contains(t, body, "go_goroutines")
+}
+
+func TestRecordRentalClosed(t *testing.T) {
+	ended := testutil.ToFloat64(RentalsClosed.WithLabelValues("ended"))
+	cancelled := testutil.ToFloat64(RentalsClosed.WithLabelValues("cancelled"))
+	revenue := testutil.ToFloat64(Revenue)
+
+	cost := 2.5
+	RecordRentalClosed(&models.Rental{Status: models.RentalStatusEnded, Cost: &cost})
+	RecordRentalClosed(&models.Rental{Status: models.RentalStatusCancelled})
+
+	assert.Equal(t, ended+1, testutil.ToFloat64(RentalsClosed.WithLabelValues("ended")))
+	assert.Equal(t, cancelled+1, testutil.ToFloat64(RentalsClosed.WithLabelValues("cancelled")))
+	assert.InDelta(t, revenue+2.5, testutil.ToFloat64(Revenue), 0.0001)
+}
+
+func TestRecordRefund(t *testing.T) {
+	refunds := testutil.ToFloat64(Refunds)
+
+	RecordRefund(4.25)
+	RecordRefund(0)
+
+	assert.InDelta(t, refunds+4.25, testutil.ToFloat64(Refunds), 0.0001)
+}
@@ -0,0 +1,88 @@
+package models
+
+import "time"
+
+// AdminRole decides which admin endpoints an operator may use.
+type AdminRole string
+
+const (
+	AdminRoleSupport    AdminRole = "support"
+	AdminRoleFleet      AdminRole = "fleet"
+	AdminRoleFinance    AdminRole = "finance"
+	AdminRoleSuperadmin AdminRole = "superadmin"
+)
+
+// Permission is an action on the admin API guarded per route.
+type Permission string
+
+const (
+	PermissionViewBikes        Permission = "bikes:read"
+	PermissionManageBikes      Permission = "bikes:write"
+	PermissionViewUsers        Permission = "users:read"
+	PermissionManageUsers      Permission = "users:write"
+	PermissionViewRentals      Permission = "rentals:read"
+	PermissionManageRentals    Permission = "rentals:write"
+	PermissionViewPricing      Permission = "pricing:read"
+	PermissionManagePricing    Permission = "pricing:write"
+	PermissionViewStations     Permission = "stations:read"
+	PermissionManageStations   Permission = "stations:write"
+	PermissionViewGeofences    Permission = "geofences:read"
+	PermissionManageGeofences  Permission = "geofences:write"
+	PermissionViewReturnRule   Permission = "return_rule:read"
+	PermissionManageReturnRule Permission = "return_rule:write"
+	PermissionManageAdmins     Permission = "admins:write"
+	PermissionViewMetrics      Permission = "metrics:read"
+)
+
+// rolePermissions lists what each role may do. Superadmins may do anything.
+var rolePermissions = map[AdminRole][]Permission{
+	AdminRoleSupport: {
+		PermissionViewBikes, PermissionViewUsers, PermissionManageUsers, PermissionViewRentals, PermissionViewStations,
+		PermissionViewGeofences, PermissionViewReturnRule,
+	},
+	AdminRoleFleet: {
+		PermissionViewBikes, PermissionManageBikes, PermissionViewRentals, PermissionManageRentals, PermissionViewPricing,
+		PermissionViewStations, PermissionManageStations, PermissionViewGeofences, PermissionManageGeofences,
+		PermissionViewReturnRule, PermissionManageReturnRule, PermissionViewMetrics,
+	},
+	AdminRoleFinance: {
+		PermissionViewBikes, PermissionViewUsers, PermissionViewRentals, PermissionManageRentals,
+		PermissionViewPricing, PermissionManagePricing, PermissionViewStations, PermissionViewGeofences,
+		PermissionViewReturnRule, PermissionViewMetrics,
+	},
+	AdminRoleSuperadmin: nil,
+}
+
+func (r AdminRole) IsValid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Can reports whether the role grants permission.
+func (r AdminRole) Can(permission Permission) bool {
+	if r == AdminRoleSuperadmin {
+		return true
+	}
+	for _, granted := range rolePermissions[r] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+type Admin struct {
+	ID    int       `json:"id"`
+	Email string    `json:"email"`
+	Name  string    `json:"name"`
+	Role  AdminRole `json:"role"`
+	// DisabledAt is set while the account is disabled. Disabled admins can
+	// neither log in nor use tokens issued before they were disabled.
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func (a *Admin) TableName() string {
+	return "admins"
+}
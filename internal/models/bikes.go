@@ -2,16 +2,62 @@ package models
 
 import "time"
 
+// BikeType is the kind of bike. Only e-bikes report a battery level.
+type BikeType string
+
+const (
+	BikeTypeClassic BikeType = "classic"
+	BikeTypeEBike   BikeType = "ebike"
+	BikeTypeCargo   BikeType = "cargo"
+)
+
+// BikeTypes lists every bike type, in display order.
+var BikeTypes = []BikeType{BikeTypeClassic, BikeTypeEBike, BikeTypeCargo}
+
+func (t BikeType) IsValid() bool {
+	switch t {
+	case BikeTypeClassic, BikeTypeEBike, BikeTypeCargo:
+		return true
+	}
+	return false
+}
+
 type Bike struct {
 	ID             int       `json:"id"`
+	Type           BikeType  `json:"type"`
 	IsAvailable    bool      `json:"is_available"`
 	Latitude       float64   `json:"latitude"`
 	Longitude      float64   `json:"longitude"`
 	PricePerMinute float64   `json:"price_per_minute"`
+	PricePlanID    *int      `json:"price_plan_id,omitempty"`
+	StationID      *int      `json:"station_id,omitempty"`
+	BatteryLevel   *int      `json:"battery_level,omitempty"`
+	RangeKm        *float64  `json:"range_km,omitempty"`
+	DistanceKm     *float64  `json:"distance_km,omitempty"`
 	CreatedAt      time.Time `json:"-"`
 	UpdatedAt      time.Time `json:"-"`
 }
 
 func (b *Bike) TableName() string {
 	return "bikes"
-}
\ No newline at end of file
+}
+
+// BikeFilter narrows the bikes offered to riders. An empty Type matches every
+// type. E-bikes whose battery level is known and below MinBatteryLevel are
+// left out.
+type BikeFilter struct {
+	Type            BikeType
+	MinBatteryLevel int
+}
+
+// BikeTypePrice is the price per minute given to new bikes of a type when
+// none is set explicitly.
+type BikeTypePrice struct {
+	Type           BikeType  `json:"type"`
+	PricePerMinute float64   `json:"price_per_minute"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (p *BikeTypePrice) TableName() string {
+	return "bike_type_prices"
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusSent    = "sent"
+	OutboxStatusFailed  = "failed"
+)
+
+// OutboxEmail is an email waiting to be delivered, or the record of one that
+// was. Pending emails are retried with backoff until NextAttemptAt and are
+// marked failed after too many attempts.
+type OutboxEmail struct {
+	ID            int        `json:"id"`
+	Recipient     string     `json:"recipient"`
+	Subject       string     `json:"subject"`
+	Body          string     `json:"-"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	LastError     *string    `json:"last_error,omitempty"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (e *OutboxEmail) TableName() string {
+	return "email_outbox"
+}
@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// GeofenceKind is what a geofence regulates.
+type GeofenceKind string
+
+const (
+	// GeofenceOperatingArea is where bikes may be ridden and parked. With no
+	// operating areas defined the service operates everywhere.
+	GeofenceOperatingArea GeofenceKind = "operating_area"
+	// GeofenceNoParking is where rentals may not end and bikes may not be
+	// placed.
+	GeofenceNoParking GeofenceKind = "no_parking"
+	// GeofenceSlow is where riders must keep to a reduced speed.
+	GeofenceSlow GeofenceKind = "slow"
+)
+
+func (k GeofenceKind) IsValid() bool {
+	switch k {
+	case GeofenceOperatingArea, GeofenceNoParking, GeofenceSlow:
+		return true
+	}
+	return false
+}
+
+// Geofence is a single polygon. Rings[0] is the outer boundary and any
+// further rings are holes, as in a GeoJSON Polygon. The bounding box is
+// stored so candidates can be found with a plain range query.
+type Geofence struct {
+	ID            int          `json:"id"`
+	Kind          GeofenceKind `json:"kind"`
+	Name          string       `json:"name"`
+	Rings         [][]GeoPoint `json:"rings"`
+	SpeedLimitKmh *float64     `json:"speed_limit_kmh,omitempty"`
+	MinLatitude   float64      `json:"-"`
+	MaxLatitude   float64      `json:"-"`
+	MinLongitude  float64      `json:"-"`
+	MaxLongitude  float64      `json:"-"`
+	CreatedAt     time.Time    `json:"created_at"`
+}
+
+// SetBounds computes the bounding box from the outer ring.
+func (g *Geofence) SetBounds() {
+	if len(g.Rings) == 0 || len(g.Rings[0]) == 0 {
+		return
+	}
+
+	first := g.Rings[0][0]
+	g.MinLatitude, g.MaxLatitude = first.Latitude, first.Latitude
+	g.MinLongitude, g.MaxLongitude = first.Longitude, first.Longitude
+	for _, point := range g.Rings[0][1:] {
+		g.MinLatitude = min(g.MinLatitude, point.Latitude)
+		g.MaxLatitude = max(g.MaxLatitude, point.Latitude)
+		g.MinLongitude = min(g.MinLongitude, point.Longitude)
+		g.MaxLongitude = max(g.MaxLongitude, point.Longitude)
+	}
+}
+
+func (g *Geofence) TableName() string {
+	return "geofences"
+}
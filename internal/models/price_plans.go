@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+type PricePlan struct {
+	ID                   int        `json:"id"`
+	Name                 string     `json:"name"`
+	UnlockFee            float64    `json:"unlock_fee"`
+	PricePerMinute       *float64   `json:"price_per_minute,omitempty"`
+	FreeMinutes          int        `json:"free_minutes"`
+	DailyCap             *float64   `json:"daily_cap,omitempty"`
+	NightMultiplier      float64    `json:"night_multiplier"`
+	NightStartHour       int        `json:"night_start_hour"`
+	NightEndHour         int        `json:"night_end_hour"`
+	WeekendMultiplier    float64    `json:"weekend_multiplier"`
+	Timezone             string     `json:"timezone"`
+	PausedPricePerMinute *float64   `json:"paused_price_per_minute,omitempty"`
+	ZoneRates            []ZoneRate `json:"zone_rates"`
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+}
+
+func (p *PricePlan) TableName() string {
+	return "price_plans"
+}
+
+// ZoneRateApplyTo is the end of a trip that a zone rate looks at.
+type ZoneRateApplyTo string
+
+const (
+	ZoneRateStart ZoneRateApplyTo = "start"
+	ZoneRateEnd   ZoneRateApplyTo = "end"
+)
+
+func (a ZoneRateApplyTo) IsValid() bool {
+	return a == ZoneRateStart || a == ZoneRateEnd
+}
+
+// ZoneRate adjusts a price plan for trips that start or end in a station's
+// area. Fee is charged once on top of the plan's price and Multiplier scales
+// the per-minute price of the ride time.
+type ZoneRate struct {
+	ID         int             `json:"id"`
+	StationID  int             `json:"station_id"`
+	ApplyTo    ZoneRateApplyTo `json:"apply_to"`
+	Fee        float64         `json:"fee"`
+	Multiplier float64         `json:"multiplier"`
+	// Station holds the name and area of the zone, loaded with the rate so
+	// a trip can be placed in it.
+	Station *Station `json:"-"`
+}
+
+func (z *ZoneRate) TableName() string {
+	return "price_plan_zone_rates"
+}
+
+type CostLineItem struct {
+	Code        string  `json:"code"`
+	Description string  `json:"description"`
+	Quantity    int     `json:"quantity,omitempty"`
+	UnitPrice   float64 `json:"unit_price,omitempty"`
+	Amount      float64 `json:"amount"`
+}
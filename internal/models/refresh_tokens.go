@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// RefreshToken is a stored refresh token. Only the SHA-256 hash of the token
+// is kept. Tokens created by rotating one another share a FamilyID, so reuse
+// of an already rotated token can revoke the whole chain.
+type RefreshToken struct {
+	ID              int        `json:"id"`
+	UserID          int        `json:"user_id"`
+	FamilyID        string     `json:"family_id"`
+	TokenHash       string     `json:"-"`
+	AccessJTI       string     `json:"access_jti"`
+	AccessExpiresAt time.Time  `json:"access_expires_at"`
+	ExpiresAt       time.Time  `json:"expires_at"`
+	UsedAt          *time.Time `json:"used_at,omitempty"`
+	RevokedAt       *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+func (t *RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// TokenPair is the access and refresh token handed to a rider on login and
+// on every refresh.
+type TokenPair struct {
+	AccessToken     string
+	AccessExpiresAt time.Time
+	RefreshToken    string
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// RentalPoint is a location reported by the bike or the rider's app during a
+// rental.
+type RentalPoint struct {
+	ID         int       `json:"-"`
+	RentalID   int       `json:"-"`
+	Latitude   float64   `json:"latitude"`
+	Longitude  float64   `json:"longitude"`
+	RecordedAt time.Time `json:"recorded_at"`
+	CreatedAt  time.Time `json:"-"`
+}
+
+func (p *RentalPoint) TableName() string {
+	return "rental_points"
+}
+
+// RentalRoute is the path ridden during a rental: its start location followed
+// by the reported points in the order they were recorded. An ended rental's
+// last point is where it ended. DistanceKm is the length of the path.
+type RentalRoute struct {
+	RentalID   int           `json:"rental_id"`
+	Status     RentalStatus  `json:"status"`
+	DistanceKm float64       `json:"distance_km"`
+	Points     []RentalPoint `json:"points"`
+}
+
+// GeoPoints returns the route's points without their timestamps.
+func (r *RentalRoute) GeoPoints() []GeoPoint {
+	points := make([]GeoPoint, len(r.Points))
+	for i, point := range r.Points {
+		points[i] = GeoPoint{Latitude: point.Latitude, Longitude: point.Longitude}
+	}
+	return points
+}
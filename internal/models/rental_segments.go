@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// RentalSegment is one running or paused interval of a rental. The open
+// segment of an active rental has no EndedAt.
+type RentalSegment struct {
+	ID        int          `json:"id"`
+	RentalID  int          `json:"rental_id"`
+	State     RentalStatus `json:"state"`
+	StartedAt time.Time    `json:"started_at"`
+	EndedAt   *time.Time   `json:"ended_at,omitempty"`
+	CreatedAt time.Time    `json:"-"`
+}
+
+func (s *RentalSegment) TableName() string {
+	return "rental_segments"
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+)
+
+// RentalStatus is the lifecycle state of a rental. Changes between states
+// must follow rentalTransitions.
+type RentalStatus string
+
+const (
+	RentalStatusRunning   RentalStatus = "running"
+	RentalStatusPaused    RentalStatus = "paused"
+	RentalStatusEnded     RentalStatus = "ended"
+	RentalStatusCancelled RentalStatus = "cancelled"
+	RentalStatusRefunded  RentalStatus = "refunded"
+)
+
+// rentalTransitions lists, for each status, the statuses it may move to.
+// Cancelled and refunded rentals are final.
+var rentalTransitions = map[RentalStatus][]RentalStatus{
+	RentalStatusRunning:   {RentalStatusPaused, RentalStatusEnded, RentalStatusCancelled},
+	RentalStatusPaused:    {RentalStatusRunning, RentalStatusEnded, RentalStatusCancelled},
+	RentalStatusEnded:     {RentalStatusRefunded},
+	RentalStatusCancelled: {},
+	RentalStatusRefunded:  {},
+}
+
+// AllowedTransitions returns the statuses s may move to.
+func (s RentalStatus) AllowedTransitions() []RentalStatus {
+	allowed := make([]RentalStatus, len(rentalTransitions[s]))
+	copy(allowed, rentalTransitions[s])
+	return allowed
+}
+
+func (s RentalStatus) CanTransitionTo(to RentalStatus) bool {
+	for _, allowed := range rentalTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// RentalTransitionError reports a status change the state machine does not
+// allow. It matches constants.ErrInvalidRentalTransition with errors.Is.
+type RentalTransitionError struct {
+	From    RentalStatus
+	To      RentalStatus
+	Allowed []RentalStatus
+}
+
+func NewRentalTransitionError(from, to RentalStatus) *RentalTransitionError {
+	return &RentalTransitionError{From: from, To: to, Allowed: from.AllowedTransitions()}
+}
+
+func (e *RentalTransitionError) Error() string {
+	return fmt.Sprintf("cannot change rental status from %q to %q", e.From, e.To)
+}
+
+func (e *RentalTransitionError) Unwrap() error {
+	return constants.ErrInvalidRentalTransition
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+const (
+	ReservationStatusActive    = "active"
+	ReservationStatusConverted = "converted"
+	ReservationStatusCancelled = "cancelled"
+	ReservationStatusExpired   = "expired"
+)
+
+type Reservation struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	BikeID    int       `json:"bike_id"`
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+	RentalID  *int      `json:"rental_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (r *Reservation) TableName() string {
+	return "reservations"
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// ReturnDistanceMode is what the end of a rental is measured against.
+type ReturnDistanceMode string
+
+const (
+	// ReturnDistanceNone puts no limit on where a rental ends.
+	ReturnDistanceNone ReturnDistanceMode = "none"
+	// ReturnDistanceFromStart limits the distance from where the rental
+	// started.
+	ReturnDistanceFromStart ReturnDistanceMode = "from_start"
+	// ReturnDistanceFromStation limits the distance from the nearest station.
+	ReturnDistanceFromStation ReturnDistanceMode = "from_station"
+)
+
+func (m ReturnDistanceMode) IsValid() bool {
+	switch m {
+	case ReturnDistanceNone, ReturnDistanceFromStart, ReturnDistanceFromStation:
+		return true
+	}
+	return false
+}
+
+// ReturnDistanceRule limits how far from a reference point a rental may end.
+// MaxDistanceKm is ignored in ReturnDistanceNone mode. UpdatedAt is nil while
+// the rule configured at startup is in effect.
+type ReturnDistanceRule struct {
+	Mode          ReturnDistanceMode `json:"mode"`
+	MaxDistanceKm float64            `json:"max_distance_km"`
+	UpdatedAt     *time.Time         `json:"updated_at,omitempty"`
+}
+
+func (r *ReturnDistanceRule) TableName() string {
+	return "return_distance_rule"
+}
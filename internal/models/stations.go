@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// GeoPoint is a vertex of a station polygon.
+type GeoPoint struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Station is a docking station or designated return zone. Its area is either
+// a circle of RadiusMeters around Latitude/Longitude or, when Polygon is set,
+// the polygon; Latitude/Longitude is then only where the station is shown on
+// a map.
+type Station struct {
+	ID           int        `json:"id"`
+	Name         string     `json:"name"`
+	Latitude     float64    `json:"latitude"`
+	Longitude    float64    `json:"longitude"`
+	RadiusMeters *float64   `json:"radius_meters,omitempty"`
+	Polygon      []GeoPoint `json:"polygon,omitempty"`
+	Capacity     int        `json:"capacity"`
+	// ParkedBikes, AvailableBikes and FreeDocks are computed from the bikes
+	// linked to the station when it is read.
+	ParkedBikes    int       `json:"parked_bikes"`
+	AvailableBikes int       `json:"available_bikes"`
+	FreeDocks      int       `json:"free_docks"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (s *Station) TableName() string {
+	return "stations"
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Purposes of the single-use tokens emailed to users. The purpose is also the
+// audience of the signed token, so a token cannot be used for another action.
+const (
+	UserTokenPurposePasswordReset     = "password_reset"
+	UserTokenPurposeEmailVerification = "email_verification"
+)
+
+// UserToken tracks a signed single-use token by its jti. A token is accepted
+// only while it is unused and unexpired.
+type UserToken struct {
+	JTI       string     `json:"jti"`
+	UserID    int        `json:"user_id"`
+	Purpose   string     `json:"purpose"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (t *UserToken) TableName() string {
+	return "user_tokens"
+}
@@ -0,0 +1,170 @@
+// Package pricing computes what a rental costs. A PricingPolicy turns a trip
+// into a Quote made of itemised line items so the breakdown can be stored
+// with the rental and shown to the rider.
+//
+// A Trip carries where it started and ended as well as when, so a policy can
+// price by zone. RulePolicy applies the zone rates of its price plan; the
+// other built-in policies only look at time.
+package pricing
+
+import (
+	"math"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+)
+
+const (
+	LineItemUnlock       = "unlock"
+	LineItemFreeMinutes  = "free_minutes"
+	LineItemTime         = "time"
+	LineItemNight        = "night"
+	LineItemWeekend      = "weekend"
+	LineItemWeekendNight = "weekend_night"
+	LineItemDailyCap     = "daily_cap"
+	LineItemPaused       = "paused"
+	LineItemOutOfStation = "out_of_station"
+	LineItemNoParking    = "no_parking"
+	LineItemRefund       = "refund"
+	LineItemZone         = "zone"
+)
+
+// Pause is an interval during which the rider had the bike locked without
+// ending the rental.
+type Pause struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Location is a point on the map in decimal degrees.
+type Location struct {
+	Latitude  float64
+	Longitude float64
+}
+
+type Trip struct {
+	StartTime time.Time
+	EndTime   time.Time
+	Pauses    []Pause
+	Start     Location
+	// End is nil when the rental was closed without an end location, as
+	// when an admin ends it.
+	End *Location
+}
+
+// Minutes returns the billable duration, rounded up to the next started minute.
+func (t Trip) Minutes() int {
+	minutes := int(math.Ceil(t.EndTime.Sub(t.StartTime).Minutes()))
+	if minutes < 0 {
+		return 0
+	}
+	return minutes
+}
+
+// IsPaused reports whether the minute starting at at falls within a pause.
+func (t Trip) IsPaused(at time.Time) bool {
+	for _, pause := range t.Pauses {
+		if !at.Before(pause.Start) && at.Before(pause.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// PausedMinutes returns how many of the trip's billable minutes start while
+// the bike was paused.
+func (t Trip) PausedMinutes() int {
+	if len(t.Pauses) == 0 {
+		return 0
+	}
+
+	paused := 0
+	for i := 0; i < t.Minutes(); i++ {
+		if t.IsPaused(t.StartTime.Add(time.Duration(i) * time.Minute)) {
+			paused++
+		}
+	}
+	return paused
+}
+
+type Quote struct {
+	Minutes   int
+	Total     float64
+	LineItems []models.CostLineItem
+}
+
+// Fee is a flat charge on top of the time-based price, such as a return
+// surcharge or a parking fine.
+type Fee struct {
+	Code        string
+	Description string
+	Amount      float64
+}
+
+// AddFee appends fee to the quote.
+func (q *Quote) AddFee(fee Fee) {
+	q.LineItems = append(q.LineItems, models.CostLineItem{
+		Code:        fee.Code,
+		Description: fee.Description,
+		Quantity:    1,
+		UnitPrice:   fee.Amount,
+		Amount:      roundCents(fee.Amount),
+	})
+	q.Total = roundCents(q.Total + fee.Amount)
+}
+
+type PricingPolicy interface {
+	Quote(trip Trip) Quote
+}
+
+// PerMinutePolicy charges a flat rate for every started minute, and a
+// separate rate for minutes the bike spent paused.
+type PerMinutePolicy struct {
+	PricePerMinute       float64
+	PausedPricePerMinute float64
+}
+
+func NewPerMinutePolicy(pricePerMinute, pausedPricePerMinute float64) *PerMinutePolicy {
+	return &PerMinutePolicy{PricePerMinute: pricePerMinute, PausedPricePerMinute: pausedPricePerMinute}
+}
+
+func (p *PerMinutePolicy) Quote(trip Trip) Quote {
+	minutes := trip.Minutes()
+	pausedMinutes := trip.PausedMinutes()
+	amount := roundCents(float64(minutes-pausedMinutes) * p.PricePerMinute)
+
+	lineItems := []models.CostLineItem{{
+		Code:        LineItemTime,
+		Description: "Ride time",
+		Quantity:    minutes - pausedMinutes,
+		UnitPrice:   p.PricePerMinute,
+		Amount:      amount,
+	}}
+	total := amount
+
+	if pausedMinutes > 0 {
+		item := pausedLineItem(pausedMinutes, p.PausedPricePerMinute)
+		lineItems = append(lineItems, item)
+		total += item.Amount
+	}
+
+	return Quote{
+		Minutes:   minutes,
+		Total:     roundCents(total),
+		LineItems: lineItems,
+	}
+}
+
+func pausedLineItem(minutes int, pricePerMinute float64) models.CostLineItem {
+	return models.CostLineItem{
+		Code:        LineItemPaused,
+		Description: "Paused time",
+		Quantity:    minutes,
+		UnitPrice:   pricePerMinute,
+		Amount:      roundCents(float64(minutes) * pricePerMinute),
+	}
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
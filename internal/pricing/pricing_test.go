@@ -0,0 +1,143 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrip_Minutes(t *testing.T) {
+	start := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 0, Trip{StartTime: start, EndTime: start}.Minutes())
+	assert.Equal(t, 1, Trip{StartTime: start, EndTime: start.Add(10 * time.Second)}.Minutes())
+	assert.Equal(t, 2, Trip{StartTime: start, EndTime: start.Add(61 * time.Second)}.Minutes())
+	assert.Equal(t, 0, Trip{StartTime: start, EndTime: start.Add(-time.Minute)}.Minutes())
+}
+
+func TestPerMinutePolicy_Quote(t *testing.T) {
+	start := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	policy := NewPerMinutePolicy(0.5, 0)
+
+	quote := policy.Quote(Trip{StartTime: start, EndTime: start.Add(9*time.Minute + 30*time.Second)})
+
+	assert.Equal(t, 10, quote.Minutes)
+	assert.Equal(t, 5.0, quote.Total)
+	assert.Len(t, quote.LineItems, 1)
+	assert.Equal(t, LineItemTime, quote.LineItems[0].Code)
+	assert.Equal(t, 10, quote.LineItems[0].Quantity)
+	assert.Equal(t, 0.5, quote.LineItems[0].UnitPrice)
+	assert.Equal(t, 5.0, quote.LineItems[0].Amount)
+}
+
+func TestPerMinutePolicy_QuoteRoundsToCents(t *testing.T) {
+	start := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	policy := NewPerMinutePolicy(0.1, 0)
+
+	quote := policy.Quote(Trip{StartTime: start, EndTime: start.Add(3 * time.Minute)})
+
+	assert.Equal(t, 0.3, quote.Total)
+}
+
+func TestTrip_PausedMinutes(t *testing.T) {
+	start := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	trip := Trip{
+		StartTime: start,
+		EndTime:   start.Add(30 * time.Minute),
+		Pauses: []Pause{
+			{Start: start.Add(5 * time.Minute), End: start.Add(12 * time.Minute)},
+			{Start: start.Add(20 * time.Minute), End: start.Add(23 * time.Minute)},
+		},
+	}
+
+	assert.Equal(t, 10, trip.PausedMinutes())
+	assert.True(t, trip.IsPaused(start.Add(5*time.Minute)))
+	assert.False(t, trip.IsPaused(start.Add(12*time.Minute)))
+	assert.Equal(t, 0, Trip{StartTime: start, EndTime: start.Add(time.Hour)}.PausedMinutes())
+}
+
+func TestPerMinutePolicy_QuoteBillsPausedTimeSeparately(t *testing.T) {
+	start := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	policy := NewPerMinutePolicy(0.5, 0.1)
+
+	quote := policy.Quote(Trip{
+		StartTime: start,
+		EndTime:   start.Add(30 * time.Minute),
+		Pauses:    []Pause{{Start: start.Add(10 * time.Minute), End: start.Add(20 * time.Minute)}},
+	})
+
+	assert.Equal(t, 30, quote.Minutes)
+	assert.Equal(t, 11.0, quote.Total)
+	assert.Len(t, quote.LineItems, 2)
+	assert.Equal(t, 20, quote.LineItems[0].Quantity)
+	assert.Equal(t, 10.0, quote.LineItems[0].Amount)
+	assert.Equal(t, LineItemPaused, quote.LineItems[1].Code)
+	assert.Equal(t, 10, quote.LineItems[1].Quantity)
+	assert.Equal(t, 1.0, quote.LineItems[1].Amount)
+}
+
+func TestQuote_AddFee(t *testing.T) {
+	start := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	quote := NewPerMinutePolicy(0.5, 0).Quote(Trip{StartTime: start, EndTime: start.Add(4 * time.Minute)})
+
+	quote.AddFee(Fee{Code: LineItemOutOfStation, Description: "Return outside a station", Amount: 2.5})
+
+	assert.Equal(t, 4.5, quote.Total)
+	assert.Len(t, quote.LineItems, 2)
+	assert.Equal(t, LineItemOutOfStation, quote.LineItems[1].Code)
+	assert.Equal(t, 1, quote.LineItems[1].Quantity)
+	assert.Equal(t, 2.5, quote.LineItems[1].Amount)
+}
+
+// zonePolicy shows how a policy prices by zone: trips ending inside the box
+// pay a surcharge on top of per-minute pricing.
+type zonePolicy struct {
+	perMinute        *PerMinutePolicy
+	minLat, maxLat   float64
+	minLong, maxLong float64
+	surcharge        float64
+}
+
+func (p zonePolicy) Quote(trip Trip) Quote {
+	quote := p.perMinute.Quote(trip)
+	if trip.End == nil {
+		return quote
+	}
+
+	end := *trip.End
+	if end.Latitude >= p.minLat && end.Latitude <= p.maxLat && end.Longitude >= p.minLong && end.Longitude <= p.maxLong {
+		quote.AddFee(Fee{Code: LineItemZone, Description: "City centre", Amount: p.surcharge})
+	}
+	return quote
+}
+
+func TestTrip_LocationsAllowZonePricing(t *testing.T) {
+	start := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	var policy PricingPolicy = zonePolicy{
+		perMinute: NewPerMinutePolicy(0.5, 0),
+		minLat:    40.40,
+		maxLat:    40.43,
+		minLong:   -3.72,
+		maxLong:   -3.68,
+		surcharge: 1.0,
+	}
+	trip := func(end *Location) Trip {
+		return Trip{
+			StartTime: start,
+			EndTime:   start.Add(10 * time.Minute),
+			Start:     Location{Latitude: 40.45, Longitude: -3.75},
+			End:       end,
+		}
+	}
+
+	inside := policy.Quote(trip(&Location{Latitude: 40.4168, Longitude: -3.7038}))
+	assert.Equal(t, 6.0, inside.Total)
+	assert.Equal(t, LineItemZone, inside.LineItems[len(inside.LineItems)-1].Code)
+
+	outside := policy.Quote(trip(&Location{Latitude: 40.50, Longitude: -3.60}))
+	assert.Equal(t, 5.0, outside.Total)
+
+	unknown := policy.Quote(trip(nil))
+	assert.Equal(t, 5.0, unknown.Total)
+}
@@ -0,0 +1,242 @@
+package pricing
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/utils"
+)
+
+// RulePolicy prices a trip according to a stored price plan: an unlock fee,
+// a number of free minutes, night and weekend multipliers evaluated in the
+// plan's timezone, and an optional cap on time charges per 24 hours of riding.
+// Paused minutes are billed at a flat paused rate and are neither free nor
+// subject to multipliers or the daily cap. Zone rates add a fee and scale the
+// per-minute price when the trip starts or ends inside their station's area.
+type RulePolicy struct {
+	plan                 *models.PricePlan
+	pricePerMinute       float64
+	pausedPricePerMinute float64
+	location             *time.Location
+}
+
+// NewRulePolicy builds a policy from plan. When the plan has no per-minute
+// or paused price of its own, the fallbacks (usually the bike's price and the
+// service-wide paused rate) are used.
+func NewRulePolicy(plan *models.PricePlan, fallbackPricePerMinute, fallbackPausedPricePerMinute float64) *RulePolicy {
+	pricePerMinute := fallbackPricePerMinute
+	if plan.PricePerMinute != nil {
+		pricePerMinute = *plan.PricePerMinute
+	}
+
+	pausedPricePerMinute := fallbackPausedPricePerMinute
+	if plan.PausedPricePerMinute != nil {
+		pausedPricePerMinute = *plan.PausedPricePerMinute
+	}
+
+	location, err := time.LoadLocation(plan.Timezone)
+	if err != nil || plan.Timezone == "" {
+		location = time.UTC
+	}
+
+	return &RulePolicy{
+		plan:                 plan,
+		pricePerMinute:       pricePerMinute,
+		pausedPricePerMinute: pausedPricePerMinute,
+		location:             location,
+	}
+}
+
+type minuteBucket struct {
+	code        string
+	description string
+	multiplier  float64
+	quantity    int
+	amount      float64
+}
+
+func (p *RulePolicy) Quote(trip Trip) Quote {
+	minutes := trip.Minutes()
+	pausedMinutes := trip.PausedMinutes()
+	lineItems := []models.CostLineItem{}
+	total := 0.0
+
+	if p.plan.UnlockFee > 0 {
+		lineItems = append(lineItems, models.CostLineItem{
+			Code:        LineItemUnlock,
+			Description: "Unlock fee",
+			Amount:      roundCents(p.plan.UnlockFee),
+		})
+		total += roundCents(p.plan.UnlockFee)
+	}
+
+	zoneMultiplier, zoneFees := p.zoneRates(trip)
+	for _, item := range zoneFees {
+		lineItems = append(lineItems, item)
+		total += item.Amount
+	}
+
+	freeMinutes := p.plan.FreeMinutes
+	if freeMinutes > minutes-pausedMinutes {
+		freeMinutes = minutes - pausedMinutes
+	}
+	if freeMinutes > 0 {
+		lineItems = append(lineItems, models.CostLineItem{
+			Code:        LineItemFreeMinutes,
+			Description: "Free minutes",
+			Quantity:    freeMinutes,
+			Amount:      0,
+		})
+	}
+
+	weekendMultiplier := multiplierOrOne(p.plan.WeekendMultiplier)
+	nightMultiplier := multiplierOrOne(p.plan.NightMultiplier)
+	buckets := []*minuteBucket{
+		{code: LineItemTime, description: "Ride time", multiplier: 1},
+		{code: LineItemNight, description: "Night ride time", multiplier: nightMultiplier},
+		{code: LineItemWeekend, description: "Weekend ride time", multiplier: weekendMultiplier},
+		{code: LineItemWeekendNight, description: "Weekend night ride time", multiplier: weekendMultiplier * nightMultiplier},
+	}
+
+	// Time charges are tracked per 24h period from the start of the trip so
+	// the daily cap can be applied to each period independently.
+	chargesPerDay := map[int]float64{}
+	ridden := 0
+	for i := 0; i < minutes; i++ {
+		at := trip.StartTime.Add(time.Duration(i) * time.Minute)
+		if trip.IsPaused(at) {
+			continue
+		}
+		ridden++
+		if ridden <= freeMinutes {
+			continue
+		}
+		at = at.In(p.location)
+
+		bucket := buckets[0]
+		night := p.isNight(at)
+		weekend := at.Weekday() == time.Saturday || at.Weekday() == time.Sunday
+		switch {
+		case weekend && night:
+			bucket = buckets[3]
+		case weekend:
+			bucket = buckets[2]
+		case night:
+			bucket = buckets[1]
+		}
+
+		charge := p.pricePerMinute * bucket.multiplier * zoneMultiplier
+		bucket.quantity++
+		bucket.amount += charge
+		chargesPerDay[i/(24*60)] += charge
+	}
+
+	for _, bucket := range buckets {
+		if bucket.quantity == 0 {
+			continue
+		}
+		amount := roundCents(bucket.amount)
+		lineItems = append(lineItems, models.CostLineItem{
+			Code:        bucket.code,
+			Description: bucket.description,
+			Quantity:    bucket.quantity,
+			UnitPrice:   roundCents(p.pricePerMinute * bucket.multiplier * zoneMultiplier),
+			Amount:      amount,
+		})
+		total += amount
+	}
+
+	if pausedMinutes > 0 {
+		item := pausedLineItem(pausedMinutes, p.pausedPricePerMinute)
+		lineItems = append(lineItems, item)
+		total += item.Amount
+	}
+
+	if p.plan.DailyCap != nil {
+		discount := 0.0
+		for _, charges := range chargesPerDay {
+			if charges > *p.plan.DailyCap {
+				discount += charges - *p.plan.DailyCap
+			}
+		}
+		if discount > 0 {
+			discount = roundCents(discount)
+			lineItems = append(lineItems, models.CostLineItem{
+				Code:        LineItemDailyCap,
+				Description: "Daily cap",
+				Amount:      -discount,
+			})
+			total -= discount
+		}
+	}
+
+	return Quote{
+		Minutes:   minutes,
+		Total:     roundCents(total),
+		LineItems: lineItems,
+	}
+}
+
+// zoneRates returns the product of the multipliers of the plan's zone rates
+// that match the trip, and a line item for each matching fee. A start rate
+// matches when the trip started inside the station's area and an end rate
+// when it ended there; a trip without an end location matches no end rate.
+func (p *RulePolicy) zoneRates(trip Trip) (float64, []models.CostLineItem) {
+	multiplier := 1.0
+	fees := []models.CostLineItem{}
+
+	for _, rate := range p.plan.ZoneRates {
+		if rate.Station == nil {
+			continue
+		}
+
+		var at *Location
+		description := "Start zone fee"
+		switch rate.ApplyTo {
+		case models.ZoneRateStart:
+			at = &trip.Start
+		case models.ZoneRateEnd:
+			at = trip.End
+			description = "End zone fee"
+		}
+		if at == nil || !utils.StationContains(rate.Station, at.Latitude, at.Longitude) {
+			continue
+		}
+
+		multiplier *= multiplierOrOne(rate.Multiplier)
+		if rate.Fee > 0 {
+			fees = append(fees, models.CostLineItem{
+				Code:        LineItemZone,
+				Description: fmt.Sprintf("%s (%s)", description, rate.Station.Name),
+				Quantity:    1,
+				UnitPrice:   roundCents(rate.Fee),
+				Amount:      roundCents(rate.Fee),
+			})
+		}
+	}
+
+	return multiplier, fees
+}
+
+// isNight reports whether at falls in the plan's night window. The window may
+// wrap around midnight (e.g. 22 to 6); equal start and end hours disable it.
+func (p *RulePolicy) isNight(at time.Time) bool {
+	start, end := p.plan.NightStartHour, p.plan.NightEndHour
+	if start == end {
+		return false
+	}
+
+	hour := at.Hour()
+	if start < end {
+		return hour >= start && hour < end
+	}
+	return hour >= start || hour < end
+}
+
+func multiplierOrOne(multiplier float64) float64 {
+	if multiplier <= 0 {
+		return 1
+	}
+	return multiplier
+}
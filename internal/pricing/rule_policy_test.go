@@ -0,0 +1,295 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
+
+func lineItem(quote Quote, code string) *models.CostLineItem {
+	for i := range quote.LineItems {
+		if quote.LineItems[i].Code == code {
+			return &quote.LineItems[i]
+		}
+	}
+	return nil
+}
+
+// Wednesday at noon UTC
+var weekdayNoon = time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+func TestRulePolicy_UnlockFeeAndFreeMinutes(t *testing.T) {
+	plan := &models.PricePlan{
+		UnlockFee:      1.0,
+		PricePerMinute: float64Ptr(0.25),
+		FreeMinutes:    5,
+		Timezone:       "UTC",
+	}
+	policy := NewRulePolicy(plan, 0.5, 0)
+
+	quote := policy.Quote(Trip{StartTime: weekdayNoon, EndTime: weekdayNoon.Add(15 * time.Minute)})
+
+	assert.Equal(t, 15, quote.Minutes)
+	assert.Equal(t, 3.5, quote.Total)
+	assert.Equal(t, 1.0, lineItem(quote, LineItemUnlock).Amount)
+	assert.Equal(t, 5, lineItem(quote, LineItemFreeMinutes).Quantity)
+	assert.Equal(t, 10, lineItem(quote, LineItemTime).Quantity)
+	assert.Equal(t, 2.5, lineItem(quote, LineItemTime).Amount)
+}
+
+func TestRulePolicy_FreeMinutesCoverWholeTrip(t *testing.T) {
+	plan := &models.PricePlan{FreeMinutes: 30, Timezone: "UTC"}
+	policy := NewRulePolicy(plan, 0.5, 0)
+
+	quote := policy.Quote(Trip{StartTime: weekdayNoon, EndTime: weekdayNoon.Add(10 * time.Minute)})
+
+	assert.Equal(t, 0.0, quote.Total)
+	assert.Equal(t, 10, lineItem(quote, LineItemFreeMinutes).Quantity)
+	assert.Nil(t, lineItem(quote, LineItemTime))
+}
+
+func TestRulePolicy_FallsBackToBikePrice(t *testing.T) {
+	plan := &models.PricePlan{Timezone: "UTC"}
+	policy := NewRulePolicy(plan, 0.4, 0)
+
+	quote := policy.Quote(Trip{StartTime: weekdayNoon, EndTime: weekdayNoon.Add(10 * time.Minute)})
+
+	assert.Equal(t, 4.0, quote.Total)
+	assert.Equal(t, 0.4, lineItem(quote, LineItemTime).UnitPrice)
+}
+
+func TestRulePolicy_NightWindowWrapsMidnight(t *testing.T) {
+	plan := &models.PricePlan{
+		PricePerMinute:  float64Ptr(0.5),
+		NightMultiplier: 2,
+		NightStartHour:  22,
+		NightEndHour:    6,
+		Timezone:        "UTC",
+	}
+	policy := NewRulePolicy(plan, 0.5, 0)
+	start := time.Date(2024, 1, 10, 21, 50, 0, 0, time.UTC)
+
+	quote := policy.Quote(Trip{StartTime: start, EndTime: start.Add(20 * time.Minute)})
+
+	assert.Equal(t, 10, lineItem(quote, LineItemTime).Quantity)
+	assert.Equal(t, 10, lineItem(quote, LineItemNight).Quantity)
+	assert.Equal(t, 1.0, lineItem(quote, LineItemNight).UnitPrice)
+	assert.Equal(t, 15.0, quote.Total)
+}
+
+func TestRulePolicy_NightWindowUsesPlanTimezone(t *testing.T) {
+	plan := &models.PricePlan{
+		PricePerMinute:  float64Ptr(1),
+		NightMultiplier: 2,
+		NightStartHour:  22,
+		NightEndHour:    6,
+		Timezone:        "Asia/Tokyo",
+	}
+	policy := NewRulePolicy(plan, 1, 0)
+	// 14:00 UTC is 23:00 in Tokyo
+	start := time.Date(2024, 1, 10, 14, 0, 0, 0, time.UTC)
+
+	quote := policy.Quote(Trip{StartTime: start, EndTime: start.Add(10 * time.Minute)})
+
+	assert.Equal(t, 10, lineItem(quote, LineItemNight).Quantity)
+	assert.Nil(t, lineItem(quote, LineItemTime))
+	assert.Equal(t, 20.0, quote.Total)
+}
+
+func TestRulePolicy_WeekendMultiplier(t *testing.T) {
+	plan := &models.PricePlan{
+		PricePerMinute:    float64Ptr(0.5),
+		WeekendMultiplier: 1.5,
+		NightMultiplier:   2,
+		NightStartHour:    22,
+		NightEndHour:      6,
+		Timezone:          "UTC",
+	}
+	policy := NewRulePolicy(plan, 0.5, 0)
+	saturday := time.Date(2024, 1, 13, 21, 55, 0, 0, time.UTC)
+
+	quote := policy.Quote(Trip{StartTime: saturday, EndTime: saturday.Add(10 * time.Minute)})
+
+	assert.Equal(t, 5, lineItem(quote, LineItemWeekend).Quantity)
+	assert.Equal(t, 0.75, lineItem(quote, LineItemWeekend).UnitPrice)
+	assert.Equal(t, 5, lineItem(quote, LineItemWeekendNight).Quantity)
+	assert.Equal(t, 1.5, lineItem(quote, LineItemWeekendNight).UnitPrice)
+	assert.Equal(t, 11.25, quote.Total)
+}
+
+func TestRulePolicy_DailyCap(t *testing.T) {
+	plan := &models.PricePlan{
+		UnlockFee:      1,
+		PricePerMinute: float64Ptr(0.5),
+		DailyCap:       float64Ptr(20),
+		Timezone:       "UTC",
+	}
+	policy := NewRulePolicy(plan, 0.5, 0)
+
+	quote := policy.Quote(Trip{StartTime: weekdayNoon, EndTime: weekdayNoon.Add(25 * time.Hour)})
+
+	assert.Equal(t, 1500, quote.Minutes)
+	// Both periods exceed the cap (720 and 30), so each is charged 20, plus unlock
+	assert.Equal(t, 41.0, quote.Total)
+	assert.Equal(t, -710.0, lineItem(quote, LineItemDailyCap).Amount)
+}
+
+func TestRulePolicy_DailyCapNotReached(t *testing.T) {
+	plan := &models.PricePlan{
+		PricePerMinute: float64Ptr(0.5),
+		DailyCap:       float64Ptr(20),
+		Timezone:       "UTC",
+	}
+	policy := NewRulePolicy(plan, 0.5, 0)
+
+	quote := policy.Quote(Trip{StartTime: weekdayNoon, EndTime: weekdayNoon.Add(30 * time.Minute)})
+
+	assert.Equal(t, 15.0, quote.Total)
+	assert.Nil(t, lineItem(quote, LineItemDailyCap))
+}
+
+func TestRulePolicy_InvalidTimezoneFallsBackToUTC(t *testing.T) {
+	plan := &models.PricePlan{
+		PricePerMinute:  float64Ptr(1),
+		NightMultiplier: 2,
+		NightStartHour:  0,
+		NightEndHour:    6,
+		Timezone:        "Not/AZone",
+	}
+	policy := NewRulePolicy(plan, 1, 0)
+	start := time.Date(2024, 1, 10, 1, 0, 0, 0, time.UTC)
+
+	quote := policy.Quote(Trip{StartTime: start, EndTime: start.Add(time.Minute)})
+
+	assert.Equal(t, 1, lineItem(quote, LineItemNight).Quantity)
+}
+
+func TestRulePolicy_PausedMinutes(t *testing.T) {
+	plan := &models.PricePlan{
+		PricePerMinute:  float64Ptr(0.5),
+		FreeMinutes:     5,
+		NightMultiplier: 2,
+		NightStartHour:  12,
+		NightEndHour:    13,
+		Timezone:        "UTC",
+	}
+	policy := NewRulePolicy(plan, 1, 0.2)
+
+	quote := policy.Quote(Trip{
+		StartTime: weekdayNoon,
+		EndTime:   weekdayNoon.Add(20 * time.Minute),
+		Pauses:    []Pause{{Start: weekdayNoon.Add(2 * time.Minute), End: weekdayNoon.Add(12 * time.Minute)}},
+	})
+
+	assert.Equal(t, 20, quote.Minutes)
+	assert.Equal(t, 5, lineItem(quote, LineItemFreeMinutes).Quantity)
+	assert.Equal(t, 5, lineItem(quote, LineItemNight).Quantity)
+	assert.Equal(t, 5.0, lineItem(quote, LineItemNight).Amount)
+	assert.Equal(t, 10, lineItem(quote, LineItemPaused).Quantity)
+	assert.Equal(t, 2.0, lineItem(quote, LineItemPaused).Amount)
+	assert.Equal(t, 7.0, quote.Total)
+}
+
+func TestRulePolicy_PlanPausedPriceOverridesFallback(t *testing.T) {
+	plan := &models.PricePlan{PausedPricePerMinute: float64Ptr(0.05), Timezone: "UTC"}
+	policy := NewRulePolicy(plan, 0.5, 0.2)
+
+	quote := policy.Quote(Trip{
+		StartTime: weekdayNoon,
+		EndTime:   weekdayNoon.Add(10 * time.Minute),
+		Pauses:    []Pause{{Start: weekdayNoon, End: weekdayNoon.Add(10 * time.Minute)}},
+	})
+
+	assert.Equal(t, 0.5, quote.Total)
+	assert.Equal(t, 0.05, lineItem(quote, LineItemPaused).UnitPrice)
+	assert.Nil(t, lineItem(quote, LineItemTime))
+}
+
+// solStation is a 200 m radius station around Puerta del Sol.
+var solStation = &models.Station{ID: 1, Name: "Sol", Latitude: 40.4168, Longitude: -3.7038, RadiusMeters: float64Ptr(200)}
+
+var (
+	insideSol  = Location{Latitude: 40.4170, Longitude: -3.7035}
+	outsideSol = Location{Latitude: 40.4300, Longitude: -3.6800}
+)
+
+func zoneTrip(start Location, end *Location) Trip {
+	return Trip{StartTime: weekdayNoon, EndTime: weekdayNoon.Add(10 * time.Minute), Start: start, End: end}
+}
+
+func TestRulePolicy_ZoneRateInsideAndOutsideZone(t *testing.T) {
+	plan := &models.PricePlan{
+		PricePerMinute: float64Ptr(0.2),
+		Timezone:       "UTC",
+		ZoneRates: []models.ZoneRate{
+			{StationID: 1, ApplyTo: models.ZoneRateStart, Fee: 0.5, Multiplier: 1.5, Station: solStation},
+		},
+	}
+	policy := NewRulePolicy(plan, 0.5, 0)
+
+	inside := policy.Quote(zoneTrip(insideSol, &outsideSol))
+	assert.Equal(t, 3.5, inside.Total)
+	assert.Equal(t, 0.3, lineItem(inside, LineItemTime).UnitPrice)
+	assert.Equal(t, 3.0, lineItem(inside, LineItemTime).Amount)
+	zone := lineItem(inside, LineItemZone)
+	assert.Equal(t, "Start zone fee (Sol)", zone.Description)
+	assert.Equal(t, 0.5, zone.Amount)
+
+	outside := policy.Quote(zoneTrip(outsideSol, &insideSol))
+	assert.Equal(t, 2.0, outside.Total)
+	assert.Equal(t, 0.2, lineItem(outside, LineItemTime).UnitPrice)
+	assert.Nil(t, lineItem(outside, LineItemZone))
+}
+
+func TestRulePolicy_ZoneRateAtTripEnd(t *testing.T) {
+	plan := &models.PricePlan{
+		PricePerMinute: float64Ptr(0.2),
+		Timezone:       "UTC",
+		ZoneRates: []models.ZoneRate{
+			{StationID: 1, ApplyTo: models.ZoneRateEnd, Multiplier: 0.5, Station: solStation},
+		},
+	}
+	policy := NewRulePolicy(plan, 0.5, 0)
+
+	inside := policy.Quote(zoneTrip(outsideSol, &insideSol))
+	assert.Equal(t, 1.0, inside.Total)
+	assert.Nil(t, lineItem(inside, LineItemZone))
+
+	outside := policy.Quote(zoneTrip(insideSol, &outsideSol))
+	assert.Equal(t, 2.0, outside.Total)
+
+	// A rental closed without an end location matches no end rate.
+	unknown := policy.Quote(zoneTrip(insideSol, nil))
+	assert.Equal(t, 2.0, unknown.Total)
+}
+
+func TestRulePolicy_ZoneRatesCombine(t *testing.T) {
+	plan := &models.PricePlan{
+		PricePerMinute: float64Ptr(0.2),
+		FreeMinutes:    5,
+		Timezone:       "UTC",
+		ZoneRates: []models.ZoneRate{
+			{StationID: 1, ApplyTo: models.ZoneRateStart, Fee: 0.25, Multiplier: 2, Station: solStation},
+			{StationID: 1, ApplyTo: models.ZoneRateEnd, Fee: 0.75, Multiplier: 1.5, Station: solStation},
+		},
+	}
+	policy := NewRulePolicy(plan, 0.5, 0)
+
+	quote := policy.Quote(zoneTrip(insideSol, &insideSol))
+
+	// Five free minutes, then five at 0.2 * 2 * 1.5, plus both fees.
+	assert.Equal(t, 4.0, quote.Total)
+	assert.Equal(t, 5, lineItem(quote, LineItemFreeMinutes).Quantity)
+	assert.Equal(t, 0.6, lineItem(quote, LineItemTime).UnitPrice)
+	assert.Equal(t, 3.0, lineItem(quote, LineItemTime).Amount)
+	assert.Equal(t, []models.CostLineItem{
+		{Code: LineItemZone, Description: "Start zone fee (Sol)", Quantity: 1, UnitPrice: 0.25, Amount: 0.25},
+		{Code: LineItemZone, Description: "End zone fee (Sol)", Quantity: 1, UnitPrice: 0.75, Amount: 0.75},
+	}, quote.LineItems[:2])
+}
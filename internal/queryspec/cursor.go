@@ -0,0 +1,104 @@
+package queryspec
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"slices"
+)
+
+// ParamCursor switches a list endpoint from page/limit to keyset pagination.
+// An empty value requests the first page.
+const ParamCursor = "cursor"
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is a position in an id-ordered list. Rows strictly after ID are
+// returned, or strictly before it when Before is set. The zero value starts
+// at the beginning of the list.
+type Cursor struct {
+	ID     int  `json:"id"`
+	Before bool `json:"before,omitempty"`
+}
+
+// Encode returns the opaque token handed to clients.
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a token produced by Encode. An empty token is the
+// first page.
+func DecodeCursor(token string) (Cursor, error) {
+	var c Cursor
+	if token == "" {
+		return c, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return c, errInvalidCursor
+	}
+	if err := json.Unmarshal(data, &c); err != nil || c.ID <= 0 {
+		return Cursor{}, errInvalidCursor
+	}
+
+	return c, nil
+}
+
+// ParseCursor reads the cursor parameter. It returns nil when the request
+// uses page/limit pagination.
+func ParseCursor(values url.Values) (*Cursor, error) {
+	if _, ok := values[ParamCursor]; !ok {
+		return nil, nil
+	}
+
+	c, err := DecodeCursor(values.Get(ParamCursor))
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Cursors are the tokens for the pages around the current one. An empty
+// token means there is no page in that direction.
+type Cursors struct {
+	Next string
+	Prev string
+}
+
+// Paginate trims items, fetched with a limit of limit+1 in the direction of
+// cursor, to one page in list order and returns the cursors around it.
+func Paginate[T any](items []T, cursor Cursor, limit int, id func(T) int) ([]T, Cursors) {
+	more := len(items) > limit
+	if more {
+		items = items[:limit]
+	}
+	if cursor.Before {
+		slices.Reverse(items)
+	}
+
+	var cursors Cursors
+	if len(items) == 0 {
+		return items, cursors
+	}
+
+	first, last := id(items[0]), id(items[len(items)-1])
+
+	// Walking forwards, rows before this page exist whenever we started
+	// from a cursor; walking backwards, rows after it always do.
+	hasNext, hasPrev := more, cursor.ID != 0
+	if cursor.Before {
+		hasNext, hasPrev = true, more
+	}
+
+	if hasNext {
+		cursors.Next = Cursor{ID: last}.Encode()
+	}
+	if hasPrev {
+		cursors.Prev = Cursor{ID: first, Before: true}.Encode()
+	}
+
+	return items, cursors
+}
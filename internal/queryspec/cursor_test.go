@@ -0,0 +1,95 @@
+package queryspec
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeCursor(t *testing.T) {
+	t.Run("Round trip", func(t *testing.T) {
+		c, err := DecodeCursor(Cursor{ID: 42, Before: true}.Encode())
+
+		assert.NoError(t, err)
+		assert.Equal(t, Cursor{ID: 42, Before: true}, c)
+	})
+
+	t.Run("Empty token is the first page", func(t *testing.T) {
+		c, err := DecodeCursor("")
+
+		assert.NoError(t, err)
+		assert.Equal(t, Cursor{}, c)
+	})
+
+	t.Run("Invalid tokens", func(t *testing.T) {
+		for _, token := range []string{"not base64!", "bm90IGpzb24", Cursor{ID: -1}.Encode()} {
+			_, err := DecodeCursor(token)
+			assert.Error(t, err, token)
+		}
+	})
+}
+
+func TestParse_Cursor(t *testing.T) {
+	t.Run("Absent cursor keeps page/limit", func(t *testing.T) {
+		spec, errs := Parse(url.Values{"page": {"2"}}, Rentals)
+
+		assert.Nil(t, errs)
+		assert.Nil(t, spec.Cursor)
+	})
+
+	t.Run("Empty cursor starts keyset pagination", func(t *testing.T) {
+		spec, errs := Parse(url.Values{"cursor": {""}, "sort": {"-id"}}, Rentals)
+
+		assert.Nil(t, errs)
+		assert.Equal(t, &Cursor{}, spec.Cursor)
+		assert.True(t, spec.Descending())
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		_, errs := Parse(url.Values{"cursor": {"garbage"}}, Rentals)
+
+		assert.Contains(t, errs, ParamCursor)
+	})
+
+	t.Run("Cursor with non-id sort", func(t *testing.T) {
+		_, errs := Parse(url.Values{"cursor": {""}, "sort": {"start_time"}}, Rentals)
+
+		assert.Contains(t, errs, ParamSort)
+	})
+}
+
+func TestPaginate(t *testing.T) {
+	id := func(n int) int { return n }
+
+	t.Run("First page with more rows", func(t *testing.T) {
+		items, cursors := Paginate([]int{1, 2, 3}, Cursor{}, 2, id)
+
+		assert.Equal(t, []int{1, 2}, items)
+		assert.Equal(t, Cursor{ID: 2}.Encode(), cursors.Next)
+		assert.Empty(t, cursors.Prev)
+	})
+
+	t.Run("Last page", func(t *testing.T) {
+		items, cursors := Paginate([]int{5, 6}, Cursor{ID: 4}, 2, id)
+
+		assert.Equal(t, []int{5, 6}, items)
+		assert.Empty(t, cursors.Next)
+		assert.Equal(t, Cursor{ID: 5, Before: true}.Encode(), cursors.Prev)
+	})
+
+	t.Run("Walking backwards restores list order", func(t *testing.T) {
+		items, cursors := Paginate([]int{4, 3, 2}, Cursor{ID: 5, Before: true}, 2, id)
+
+		assert.Equal(t, []int{3, 4}, items)
+		assert.Equal(t, Cursor{ID: 4}.Encode(), cursors.Next)
+		assert.Equal(t, Cursor{ID: 3, Before: true}.Encode(), cursors.Prev)
+	})
+
+	t.Run("Empty page", func(t *testing.T) {
+		items, cursors := Paginate([]int{}, Cursor{ID: 9}, 2, id)
+
+		assert.Empty(t, items)
+		assert.Equal(t, Cursors{}, cursors)
+	})
+}
@@ -0,0 +1,288 @@
+// Package queryspec parses the filter, search and sort parameters accepted by
+// list endpoints. Every parameter is checked against a per-resource whitelist,
+// so a parsed Spec only ever names known columns and carries typed values that
+// repositories can bind as query parameters.
+package queryspec
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Op is a comparison operator, written as field[op] in the query string.
+// A bare field means OpEq.
+type Op string
+
+const (
+	OpEq  Op = "eq"
+	OpGt  Op = "gt"
+	OpGte Op = "gte"
+	OpLt  Op = "lt"
+	OpLte Op = "lte"
+)
+
+// SQL returns the comparison operator for op.
+func (op Op) SQL() string {
+	switch op {
+	case OpGt:
+		return ">"
+	case OpGte:
+		return ">="
+	case OpLt:
+		return "<"
+	case OpLte:
+		return "<="
+	default:
+		return "="
+	}
+}
+
+// Type controls how a filter value is parsed.
+type Type int
+
+const (
+	TypeString Type = iota
+	TypeInt
+	TypeBool
+	TypeTime
+)
+
+// Reserved parameters are never treated as filters.
+const (
+	ParamSearch = "q"
+	ParamSort   = "sort"
+	ParamPage   = "page"
+	ParamLimit  = "limit"
+)
+
+// Field is a filterable field of a resource.
+type Field struct {
+	Column string
+	Type   Type
+	// Ops lists the accepted operators. Empty means equality only.
+	Ops []Op
+	// Values restricts string fields to a fixed set, e.g. rental statuses.
+	Values []string
+}
+
+// Resource is the whitelist for one list endpoint.
+type Resource struct {
+	Fields map[string]Field
+	// Sorts maps sortable field names to their columns.
+	Sorts map[string]string
+	// Search lists the columns matched by q. Empty means q is rejected.
+	Search []string
+}
+
+// Filter is a single validated condition.
+type Filter struct {
+	Column string
+	Op     Op
+	Value  interface{}
+}
+
+// Sort orders results by Column.
+type Sort struct {
+	Column string
+	Desc   bool
+}
+
+// Spec is the parsed form of a list request. The zero value means no
+// filtering and the default order.
+type Spec struct {
+	Filters []Filter
+	// Search is the lower-cased q term; SearchColumns are the columns it
+	// is matched against.
+	Search        string
+	SearchColumns []string
+	Sorts         []Sort
+	// Cursor is set when the request uses keyset pagination.
+	Cursor *Cursor
+}
+
+// Parse validates values against resource. The returned map holds one
+// message per offending parameter and is nil when the query is valid.
+func Parse(values url.Values, resource Resource) (*Spec, map[string]string) {
+	spec := &Spec{}
+	errs := map[string]string{}
+
+	for key, vals := range values {
+		switch key {
+		case ParamPage, ParamLimit:
+			continue
+		case ParamSearch:
+			parseSearch(spec, vals, resource, errs)
+		case ParamSort:
+			parseSort(spec, vals, resource, errs)
+		case ParamCursor:
+			cursor, err := ParseCursor(values)
+			if err != nil {
+				errs[ParamCursor] = "Invalid cursor"
+				continue
+			}
+			spec.Cursor = cursor
+		default:
+			parseFilter(spec, key, vals, resource, errs)
+		}
+	}
+
+	if spec.Cursor != nil && !spec.SortsByIDOnly() {
+		errs[ParamSort] = "Cursor pagination only supports sort=id or sort=-id"
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	// Map iteration order is random; keep the generated SQL stable.
+	slices.SortStableFunc(spec.Filters, func(a, b Filter) int {
+		if c := strings.Compare(a.Column, b.Column); c != 0 {
+			return c
+		}
+		return strings.Compare(string(a.Op), string(b.Op))
+	})
+
+	return spec, nil
+}
+
+// SortsByIDOnly reports whether the list is ordered by id alone, the only
+// order keyset cursors can walk.
+func (s *Spec) SortsByIDOnly() bool {
+	return len(s.Sorts) == 0 || (len(s.Sorts) == 1 && s.Sorts[0].Column == "id")
+}
+
+// Descending reports whether an id-ordered list runs from newest to oldest.
+func (s *Spec) Descending() bool {
+	return len(s.Sorts) == 1 && s.Sorts[0].Column == "id" && s.Sorts[0].Desc
+}
+
+func parseSearch(spec *Spec, vals []string, resource Resource, errs map[string]string) {
+	if len(resource.Search) == 0 {
+		errs[ParamSearch] = "Search is not supported for this resource"
+		return
+	}
+
+	term := strings.TrimSpace(vals[len(vals)-1])
+	if term == "" {
+		return
+	}
+
+	spec.Search = strings.ToLower(term)
+	spec.SearchColumns = resource.Search
+}
+
+func parseSort(spec *Spec, vals []string, resource Resource, errs map[string]string) {
+	seen := map[string]bool{}
+
+	for _, name := range strings.Split(vals[len(vals)-1], ",") {
+		name = strings.TrimSpace(name)
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+
+		column, ok := resource.Sorts[name]
+		if !ok {
+			errs[ParamSort] = fmt.Sprintf("Cannot sort by %q; allowed fields: %s", name, strings.Join(sortedKeys(resource.Sorts), ", "))
+			return
+		}
+		if seen[name] {
+			errs[ParamSort] = fmt.Sprintf("Field %q is listed more than once", name)
+			return
+		}
+		seen[name] = true
+
+		spec.Sorts = append(spec.Sorts, Sort{Column: column, Desc: desc})
+	}
+}
+
+func parseFilter(spec *Spec, key string, vals []string, resource Resource, errs map[string]string) {
+	name, op := key, OpEq
+	if i := strings.IndexByte(key, '['); i > 0 && strings.HasSuffix(key, "]") {
+		name, op = key[:i], Op(key[i+1:len(key)-1])
+	}
+
+	field, ok := resource.Fields[name]
+	if !ok {
+		errs[key] = "Unknown filter"
+		return
+	}
+
+	if !allowsOp(field, op) {
+		errs[key] = fmt.Sprintf("Operator %q is not supported for %s", op, name)
+		return
+	}
+
+	for _, raw := range vals {
+		value, msg := parseValue(field, raw)
+		if msg != "" {
+			errs[key] = msg
+			return
+		}
+		spec.Filters = append(spec.Filters, Filter{Column: field.Column, Op: op, Value: value})
+	}
+}
+
+func allowsOp(field Field, op Op) bool {
+	if len(field.Ops) == 0 {
+		return op == OpEq
+	}
+	return slices.Contains(field.Ops, op)
+}
+
+func parseValue(field Field, raw string) (interface{}, string) {
+	raw = strings.TrimSpace(raw)
+
+	switch field.Type {
+	case TypeInt:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, "Must be an integer"
+		}
+		return n, ""
+
+	case TypeBool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, "Must be true or false"
+		}
+		// Booleans are stored as INTEGER on both backends.
+		if b {
+			return 1, ""
+		}
+		return 0, ""
+
+	case TypeTime:
+		t, err := parseTime(raw)
+		if err != nil {
+			return nil, "Must be an RFC 3339 timestamp or a YYYY-MM-DD date"
+		}
+		return t, ""
+
+	default:
+		if len(field.Values) > 0 && !slices.Contains(field.Values, raw) {
+			return nil, "Must be one of: " + strings.Join(field.Values, ", ")
+		}
+		return raw, ""
+	}
+}
+
+// parseTime accepts RFC 3339 timestamps and plain dates, which are taken as
+// midnight UTC. Values are normalised to UTC, the zone timestamps are stored in.
+func parseTime(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t.UTC(), nil
+	}
+	return time.Parse(time.DateOnly, raw)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,81 @@
+package queryspec
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("Empty query", func(t *testing.T) {
+		spec, errs := Parse(url.Values{"page": {"2"}, "limit": {"10"}}, Rentals)
+
+		assert.Nil(t, errs)
+		assert.Empty(t, spec.Filters)
+		assert.Empty(t, spec.Sorts)
+		assert.Empty(t, spec.Search)
+	})
+
+	t.Run("Filters, operators and sort", func(t *testing.T) {
+		values, _ := url.ParseQuery("status=running&bike_id=42&start_time[gte]=2026-10-16&start_time[lt]=2026-10-17T00:00:00%2B02:00&sort=-start_time,id")
+
+		spec, errs := Parse(values, Rentals)
+
+		assert.Nil(t, errs)
+		assert.Equal(t, []Filter{
+			{Column: "bike_id", Op: OpEq, Value: 42},
+			{Column: "start_time", Op: OpGte, Value: time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)},
+			{Column: "start_time", Op: OpLt, Value: time.Date(2026, 10, 16, 22, 0, 0, 0, time.UTC)},
+			{Column: "status", Op: OpEq, Value: "running"},
+		}, spec.Filters)
+		assert.Equal(t, []Sort{{Column: "start_time", Desc: true}, {Column: "id"}}, spec.Sorts)
+	})
+
+	t.Run("Booleans are bound as integers", func(t *testing.T) {
+		spec, errs := Parse(url.Values{"is_available": {"false"}}, Bikes)
+
+		assert.Nil(t, errs)
+		assert.Equal(t, []Filter{{Column: "is_available", Op: OpEq, Value: 0}}, spec.Filters)
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		spec, errs := Parse(url.Values{"q": {" @ACME "}}, Users)
+
+		assert.Nil(t, errs)
+		assert.Equal(t, "@acme", spec.Search)
+		assert.Equal(t, []string{"email", "first_name", "last_name"}, spec.SearchColumns)
+	})
+
+	t.Run("Invalid parameters", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			query    string
+			resource Resource
+			field    string
+		}{
+			{"Unknown field", "password=x", Users, "password"},
+			{"Unsupported operator", "status[gt]=running", Rentals, "status[gt]"},
+			{"Unknown operator", "start_time[like]=2026-10-16", Rentals, "start_time[like]"},
+			{"Bad integer", "user_id=abc", Rentals, "user_id"},
+			{"Bad boolean", "is_available=maybe", Bikes, "is_available"},
+			{"Bad time", "start_time[gte]=yesterday", Rentals, "start_time[gte]"},
+			{"Unknown status", "status=lost", Rentals, "status"},
+			{"Unknown sort field", "sort=-password", Users, "sort"},
+			{"Duplicate sort field", "sort=id,-id", Users, "sort"},
+			{"Search not supported", "q=abc", Rentals, "q"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				values, _ := url.ParseQuery(tt.query)
+
+				spec, errs := Parse(values, tt.resource)
+
+				assert.Nil(t, spec)
+				assert.Contains(t, errs, tt.field)
+			})
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package queryspec
+
+import "github.com/Nimirandad/bike-rental-service/internal/models"
+
+var rangeOps = []Op{OpEq, OpGt, OpGte, OpLt, OpLte}
+
+// Bikes is the whitelist for GET /admin/bikes.
+var Bikes = Resource{
+	Fields: map[string]Field{
+		"is_available":  {Column: "is_available", Type: TypeBool},
+		"price_plan_id": {Column: "price_plan_id", Type: TypeInt},
+		"type": {Column: "type", Type: TypeString, Values: []string{
+			string(models.BikeTypeClassic),
+			string(models.BikeTypeEBike),
+			string(models.BikeTypeCargo),
+		}},
+		"battery_level": {Column: "battery_level", Type: TypeInt, Ops: rangeOps},
+		"created_at":    {Column: "created_at", Type: TypeTime, Ops: rangeOps},
+	},
+	Sorts: map[string]string{
+		"id":               "id",
+		"price_per_minute": "price_per_minute",
+		"battery_level":    "battery_level",
+		"created_at":       "created_at",
+		"updated_at":       "updated_at",
+	},
+}
+
+// Users is the whitelist for GET /admin/users. q matches email and names.
+var Users = Resource{
+	Fields: map[string]Field{
+		"email":      {Column: "email", Type: TypeString},
+		"created_at": {Column: "created_at", Type: TypeTime, Ops: rangeOps},
+	},
+	Sorts: map[string]string{
+		"id":         "id",
+		"email":      "email",
+		"first_name": "first_name",
+		"last_name":  "last_name",
+		"created_at": "created_at",
+	},
+	Search: []string{"email", "first_name", "last_name"},
+}
+
+// Rentals is the whitelist for GET /admin/rentals.
+var Rentals = Resource{
+	Fields: map[string]Field{
+		"status": {Column: "status", Type: TypeString, Values: []string{
+			string(models.RentalStatusRunning),
+			string(models.RentalStatusPaused),
+			string(models.RentalStatusEnded),
+			string(models.RentalStatusCancelled),
+			string(models.RentalStatusRefunded),
+		}},
+		"user_id":    {Column: "user_id", Type: TypeInt},
+		"bike_id":    {Column: "bike_id", Type: TypeInt},
+		"start_time": {Column: "start_time", Type: TypeTime, Ops: rangeOps},
+		"end_time":   {Column: "end_time", Type: TypeTime, Ops: rangeOps},
+	},
+	Sorts: map[string]string{
+		"id":         "id",
+		"start_time": "start_time",
+		"end_time":   "end_time",
+		"cost":       "cost",
+	},
+}
@@ -0,0 +1,209 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+)
+
+const adminAccountColumns = `id, email, name, role, disabled_at, created_at, updated_at`
+
+func scanAdminAccount(row rowScanner) (*models.Admin, error) {
+	var admin models.Admin
+	err := row.Scan(&admin.ID, &admin.Email, &admin.Name, &admin.Role, &admin.DisabledAt, &admin.CreatedAt, &admin.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &admin, nil
+}
+
+// AdminAccountRepository stores the operators allowed to use the admin API.
+type AdminAccountRepository struct {
+	db DBTX
+}
+
+func NewAdminAccountRepository(db DBTX) *AdminAccountRepository {
+	return &AdminAccountRepository{db: db}
+}
+
+func (r *AdminAccountRepository) Create(ctx context.Context, email, hashedPassword, name string, role models.AdminRole) (*models.Admin, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	adminID, err := insertReturningID(
+		ctx,
+		r.db,
+		"INSERT INTO admins (email, hashed_password, name, role) VALUES (?, ?, ?, ?)",
+		email, hashedPassword, name, role,
+	)
+	if isUniqueViolation(err) {
+		return nil, fmt.Errorf("error creating admin: %w", constants.ErrEmailAlreadyExists)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error creating admin: %w", err)
+	}
+
+	return r.GetByID(ctx, adminID)
+}
+
+func (r *AdminAccountRepository) GetByID(ctx context.Context, adminID int) (*models.Admin, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	admin, err := scanAdminAccount(r.db.QueryRowContext(
+		ctx,
+		`SELECT `+adminAccountColumns+` FROM admins WHERE id = ?`,
+		adminID,
+	))
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("admin with id %d: %w", adminID, constants.ErrAdminNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding admin: %w", err)
+	}
+
+	return admin, nil
+}
+
+func (r *AdminAccountRepository) GetPasswordHashByEmail(ctx context.Context, email string) (string, *models.Admin, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	var admin models.Admin
+	var hashedPassword string
+
+	err := r.db.QueryRowContext(
+		ctx,
+		"SELECT id, email, hashed_password, name, role, disabled_at, created_at, updated_at FROM admins WHERE email = ?",
+		email,
+	).Scan(&admin.ID, &admin.Email, &hashedPassword, &admin.Name, &admin.Role, &admin.DisabledAt, &admin.CreatedAt, &admin.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return "", nil, fmt.Errorf("admin with email %s: %w", email, constants.ErrAdminNotFound)
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("error finding admin credentials: %w", err)
+	}
+
+	return hashedPassword, &admin, nil
+}
+
+func (r *AdminAccountRepository) CountAll(ctx context.Context) (int, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	var count int
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM admins").Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error counting admins: %w", err)
+	}
+	return count, nil
+}
+
+// CountEnabledByRole counts the admins with role that are not disabled.
+func (r *AdminAccountRepository) CountEnabledByRole(ctx context.Context, role models.AdminRole) (int, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	var count int
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM admins WHERE role = ? AND disabled_at IS NULL", role).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error counting admins: %w", err)
+	}
+	return count, nil
+}
+
+func (r *AdminAccountRepository) GetAll(ctx context.Context, page, limit int) ([]*models.Admin, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	offset := (page - 1) * limit
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT `+adminAccountColumns+` FROM admins ORDER BY id ASC LIMIT ? OFFSET ?`,
+		limit, offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying admins: %w", err)
+	}
+	defer rows.Close()
+
+	admins := []*models.Admin{}
+	for rows.Next() {
+		admin, err := scanAdminAccount(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning admin: %w", err)
+		}
+		admins = append(admins, admin)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating admins: %w", err)
+	}
+
+	return admins, nil
+}
+
+// Update changes the given fields. disabled sets disabled_at to now or clears
+// it; an account that is already disabled keeps its original timestamp.
+func (r *AdminAccountRepository) Update(ctx context.Context, adminID int, name *string, role *models.AdminRole, hashedPassword *string, disabled *bool) (*models.Admin, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	query := "UPDATE admins SET "
+	args := []interface{}{}
+	updates := []string{}
+
+	if name != nil {
+		updates = append(updates, "name = ?")
+		args = append(args, *name)
+	}
+
+	if role != nil {
+		updates = append(updates, "role = ?")
+		args = append(args, *role)
+	}
+
+	if hashedPassword != nil {
+		updates = append(updates, "hashed_password = ?")
+		args = append(args, *hashedPassword)
+	}
+
+	if disabled != nil {
+		if *disabled {
+			updates = append(updates, "disabled_at = COALESCE(disabled_at, CURRENT_TIMESTAMP)")
+		} else {
+			updates = append(updates, "disabled_at = NULL")
+		}
+	}
+
+	if len(updates) == 0 {
+		return r.GetByID(ctx, adminID)
+	}
+
+	updates = append(updates, "updated_at = CURRENT_TIMESTAMP")
+
+	query += strings.Join(updates, ", ") + " WHERE id = ?"
+	args = append(args, adminID)
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error updating admin: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("error updating admin: %w", err)
+	}
+	if affected == 0 {
+		return nil, fmt.Errorf("admin with id %d: %w", adminID, constants.ErrAdminNotFound)
+	}
+
+	return r.GetByID(ctx, adminID)
+}
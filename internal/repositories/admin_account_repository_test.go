@@ -0,0 +1,194 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var adminAccountRowColumns = []string{"id", "email", "name", "role", "disabled_at", "created_at", "updated_at"}
+
+func TestAdminAccountRepository_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewAdminAccountRepository(db)
+	now := time.Now()
+
+	t.Run("Successfully create admin", func(t *testing.T) {
+		mock.ExpectQuery("INSERT INTO admins (.+) RETURNING id").
+			WithArgs("ops@example.com", "hashed", "Ops", models.AdminRoleFleet).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+		mock.ExpectQuery("SELECT id, email, name, role").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows(adminAccountRowColumns).
+				AddRow(1, "ops@example.com", "Ops", "fleet", nil, now, now))
+
+		admin, err := repo.Create(t.Context(), "ops@example.com", "hashed", "Ops", models.AdminRoleFleet)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, admin.ID)
+		assert.Equal(t, models.AdminRoleFleet, admin.Role)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Duplicate email", func(t *testing.T) {
+		mock.ExpectQuery("INSERT INTO admins (.+) RETURNING id").
+			WillReturnError(errors.New("UNIQUE constraint failed: admins.email"))
+
+		admin, err := repo.Create(t.Context(), "ops@example.com", "hashed", "Ops", models.AdminRoleFleet)
+
+		assert.Nil(t, admin)
+		assert.True(t, errors.Is(err, constants.ErrEmailAlreadyExists))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAdminAccountRepository_GetByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewAdminAccountRepository(db)
+
+	t.Run("Admin not found", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, email, name, role").
+			WithArgs(99).
+			WillReturnError(sql.ErrNoRows)
+
+		admin, err := repo.GetByID(t.Context(), 99)
+
+		assert.Nil(t, admin)
+		assert.True(t, errors.Is(err, constants.ErrAdminNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAdminAccountRepository_GetPasswordHashByEmail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewAdminAccountRepository(db)
+	now := time.Now()
+
+	t.Run("Returns hash and admin", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, email, hashed_password").
+			WithArgs("root@example.com").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "hashed_password", "name", "role", "disabled_at", "created_at", "updated_at"}).
+				AddRow(1, "root@example.com", "hashed", "Root", "superadmin", nil, now, now))
+
+		hash, admin, err := repo.GetPasswordHashByEmail(t.Context(), "root@example.com")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "hashed", hash)
+		assert.Equal(t, models.AdminRoleSuperadmin, admin.Role)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Unknown email", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, email, hashed_password").
+			WithArgs("nobody@example.com").
+			WillReturnError(sql.ErrNoRows)
+
+		_, admin, err := repo.GetPasswordHashByEmail(t.Context(), "nobody@example.com")
+
+		assert.Nil(t, admin)
+		assert.True(t, errors.Is(err, constants.ErrAdminNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAdminAccountRepository_CountEnabledByRole(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewAdminAccountRepository(db)
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM admins WHERE role = \\? AND disabled_at IS NULL").
+		WithArgs(models.AdminRoleSuperadmin).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	count, err := repo.CountEnabledByRole(t.Context(), models.AdminRoleSuperadmin)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAdminAccountRepository_Update(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewAdminAccountRepository(db)
+	now := time.Now()
+	role := models.AdminRoleFinance
+
+	t.Run("Successfully update role", func(t *testing.T) {
+		mock.ExpectExec("UPDATE admins SET role = \\?").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		mock.ExpectQuery("SELECT id, email, name, role").
+			WithArgs(2).
+			WillReturnRows(sqlmock.NewRows(adminAccountRowColumns).
+				AddRow(2, "ops@example.com", "Ops", "finance", nil, now, now))
+
+		admin, err := repo.Update(t.Context(), 2, nil, &role, nil, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.AdminRoleFinance, admin.Role)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Disable and enable", func(t *testing.T) {
+		disabled := true
+		mock.ExpectExec("UPDATE admins SET disabled_at = COALESCE\\(disabled_at, CURRENT_TIMESTAMP\\), updated_at = CURRENT_TIMESTAMP WHERE id = \\?").
+			WithArgs(2).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT id, email, name, role").
+			WithArgs(2).
+			WillReturnRows(sqlmock.NewRows(adminAccountRowColumns).
+				AddRow(2, "ops@example.com", "Ops", "finance", now, now, now))
+
+		admin, err := repo.Update(t.Context(), 2, nil, nil, nil, &disabled)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, admin.DisabledAt)
+
+		disabled = false
+		mock.ExpectExec("UPDATE admins SET disabled_at = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = \\?").
+			WithArgs(2).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT id, email, name, role").
+			WithArgs(2).
+			WillReturnRows(sqlmock.NewRows(adminAccountRowColumns).
+				AddRow(2, "ops@example.com", "Ops", "finance", nil, now, now))
+
+		admin, err = repo.Update(t.Context(), 2, nil, nil, nil, &disabled)
+
+		assert.NoError(t, err)
+		assert.Nil(t, admin.DisabledAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Admin not found", func(t *testing.T) {
+		mock.ExpectExec("UPDATE admins SET role = \\?").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		admin, err := repo.Update(t.Context(), 99, nil, &role, nil, nil)
+
+		assert.Nil(t, admin)
+		assert.True(t, errors.Is(err, constants.ErrAdminNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -1,98 +1,100 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
 
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
 	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/queryspec"
 )
 
 type AdminRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewAdminRepository(db *sql.DB) *AdminRepository {
+func NewAdminRepository(db DBTX) *AdminRepository {
 	return &AdminRepository{db: db}
 }
 
-func (r *AdminRepository) CreateBike(latitude, longitude, pricePerMinute float64) (*models.Bike, error) {
-	result, err := r.db.Exec(
-		"INSERT INTO bikes (is_available, latitude, longitude, price_per_minute) VALUES (?, ?, ?, ?)",
-		1, latitude, longitude, pricePerMinute,
+func (r *AdminRepository) CreateBike(ctx context.Context, latitude, longitude, pricePerMinute float64, pricePlanID *int, bikeType models.BikeType, batteryLevel *int) (*models.Bike, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	bikeID, err := insertReturningID(
+		ctx,
+		r.db,
+		"INSERT INTO bikes (is_available, latitude, longitude, price_per_minute, price_plan_id, type, battery_level) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		1, latitude, longitude, pricePerMinute, pricePlanID, string(bikeType), batteryLevel,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error creating bike: %w", err)
 	}
 
-	bikeID, _ := result.LastInsertId()
-	return r.GetBikeByID(int(bikeID))
+	return r.GetBikeByID(ctx, bikeID)
 }
 
-func (r *AdminRepository) GetBikeByID(bikeID int) (*models.Bike, error) {
-	var bike models.Bike
-	var isAvailable int
+func (r *AdminRepository) GetBikeByID(ctx context.Context, bikeID int) (*models.Bike, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
 
-	err := r.db.QueryRow(
-		"SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at FROM bikes WHERE id = ?",
-		bikeID,
-	).Scan(&bike.ID, &isAvailable, &bike.Latitude, &bike.Longitude, &bike.PricePerMinute, &bike.CreatedAt, &bike.UpdatedAt)
+	bike, err := scanBike(r.db.QueryRowContext(ctx, "SELECT "+bikeColumns+" FROM bikes WHERE id = ?", bikeID))
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("bike with id %d not found", bikeID)
+		return nil, fmt.Errorf("bike with id %d: %w", bikeID, constants.ErrBikeNotFound)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("error finding bike: %w", err)
 	}
 
-	bike.IsAvailable = isAvailable == 1
-	return &bike, nil
+	return bike, nil
 }
 
-func (r *AdminRepository) CountAll() (int, error) {
+func (r *AdminRepository) CountAll(ctx context.Context, spec *queryspec.Spec) (int, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	where, args := whereClause(spec)
+
 	var count int
-	err := r.db.QueryRow("SELECT COUNT(*) FROM bikes").Scan(&count)
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM bikes"+where, args...).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("error counting bikes: %w", err)
 	}
 	return count, nil
 }
 
-func (r *AdminRepository) GetAllBikes(page, limit int) ([]*models.Bike, error) {
-	offset := (page - 1) * limit
+// GetAllBikes returns one page of the bikes matching spec. page is
+// ignored when spec carries a cursor.
+func (r *AdminRepository) GetAllBikes(ctx context.Context, spec *queryspec.Spec, page, limit int) ([]*models.Bike, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	where, args := whereClause(spec)
+	paging, pagingArgs := limitClause(spec, page, limit)
 
-	rows, err := r.db.Query(
-		"SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at FROM bikes ORDER BY id ASC LIMIT ? OFFSET ?",
-		limit, offset,
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT "+bikeColumns+" FROM bikes"+where+orderByClause(spec)+paging,
+		append(args, pagingArgs...)...,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error querying bikes: %w", err)
 	}
-	defer rows.Close()
-
-	bikes := []*models.Bike{}
-	for rows.Next() {
-		var bike models.Bike
-		var isAvailable int
-
-		err := rows.Scan(&bike.ID, &isAvailable, &bike.Latitude, &bike.Longitude, &bike.PricePerMinute, &bike.CreatedAt, &bike.UpdatedAt)
-		if err != nil {
-			return nil, fmt.Errorf("error scanning bike: %w", err)
-		}
-
-		bike.IsAvailable = isAvailable == 1
-		bikes = append(bikes, &bike)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating bikes: %w", err)
-	}
 
-	return bikes, nil
+	return scanBikes(rows)
 }
 
-func (r *AdminRepository) UpdateBike(bikeID int, latitude, longitude *float64, isAvailable *bool, pricePerMinute *float64) (*models.Bike, error) {
-	_, err := r.GetBikeByID(bikeID)
+// UpdateBike applies the non-nil fields. A pricePlanID of 0 detaches the bike
+// from its price plan. Changing the type to one without a battery clears the
+// battery level.
+func (r *AdminRepository) UpdateBike(ctx context.Context, bikeID int, latitude, longitude *float64, isAvailable *bool, pricePerMinute *float64, pricePlanID *int, bikeType *models.BikeType, batteryLevel *int) (*models.Bike, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	_, err := r.GetBikeByID(ctx, bikeID)
 	if err != nil {
 		return nil, err
 	}
@@ -127,6 +129,28 @@ func (r *AdminRepository) UpdateBike(bikeID int, latitude, longitude *float64, i
 		params = append(params, *pricePerMinute)
 	}
 
+	if pricePlanID != nil {
+		updates = append(updates, "price_plan_id = ?")
+		if *pricePlanID == 0 {
+			params = append(params, nil)
+		} else {
+			params = append(params, *pricePlanID)
+		}
+	}
+
+	if bikeType != nil {
+		updates = append(updates, "type = ?")
+		params = append(params, string(*bikeType))
+	}
+
+	switch {
+	case batteryLevel != nil:
+		updates = append(updates, "battery_level = ?")
+		params = append(params, *batteryLevel)
+	case bikeType != nil && *bikeType != models.BikeTypeEBike:
+		updates = append(updates, "battery_level = NULL")
+	}
+
 	updates = append(updates, "updated_at = CURRENT_TIMESTAMP")
 
 	if len(params) == 0 {
@@ -142,20 +166,27 @@ func (r *AdminRepository) UpdateBike(bikeID int, latitude, longitude *float64, i
 	query += " WHERE id = ?"
 	params = append(params, bikeID)
 
-	_, err = r.db.Exec(query, params...)
+	_, err = r.db.ExecContext(ctx, query, params...)
 	if err != nil {
 		return nil, fmt.Errorf("error updating bike: %w", err)
 	}
 
-	return r.GetBikeByID(bikeID)
+	return r.GetBikeByID(ctx, bikeID)
 }
 
-func (r *AdminRepository) GetAllUsers(page, limit int) ([]*models.User, error) {
-	offset := (page - 1) * limit
+// GetAllUsers returns one page of the users matching spec. page is
+// ignored when spec carries a cursor.
+func (r *AdminRepository) GetAllUsers(ctx context.Context, spec *queryspec.Spec, page, limit int) ([]*models.User, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	where, args := whereClause(spec)
+	paging, pagingArgs := limitClause(spec, page, limit)
 
-	rows, err := r.db.Query(
-		"SELECT id, email, first_name, last_name, created_at FROM users ORDER BY id ASC LIMIT ? OFFSET ?",
-		limit, offset,
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT "+userColumns+" FROM users"+where+orderByClause(spec)+paging,
+		append(args, pagingArgs...)...,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error querying users: %w", err)
@@ -164,12 +195,11 @@ func (r *AdminRepository) GetAllUsers(page, limit int) ([]*models.User, error) {
 
 	users := []*models.User{}
 	for rows.Next() {
-		var user models.User
-		err := rows.Scan(&user.ID, &user.Email, &user.FirstName, &user.LastName, &user.CreatedAt)
+		user, err := scanUser(rows)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning user: %w", err)
 		}
-		users = append(users, &user)
+		users = append(users, user)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -179,43 +209,56 @@ func (r *AdminRepository) GetAllUsers(page, limit int) ([]*models.User, error) {
 	return users, nil
 }
 
-func (r *AdminRepository) CountAllUsers() (int, error) {
+func (r *AdminRepository) CountAllUsers(ctx context.Context, spec *queryspec.Spec) (int, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	where, args := whereClause(spec)
+
 	var count int
-	err := r.db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count)
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users"+where, args...).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("error counting users: %w", err)
 	}
 	return count, nil
 }
 
-func (r *AdminRepository) GetUserByID(userID int) (*models.User, error) {
-	var user models.User
+func (r *AdminRepository) GetUserByID(ctx context.Context, userID int) (*models.User, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
 
-	err := r.db.QueryRow(
-		"SELECT id, email, first_name, last_name, created_at FROM users WHERE id = ?",
+	user, err := scanUser(r.db.QueryRowContext(
+		ctx,
+		"SELECT "+userColumns+" FROM users WHERE id = ?",
 		userID,
-	).Scan(&user.ID, &user.Email, &user.FirstName, &user.LastName, &user.CreatedAt)
+	))
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("user with id %d not found", userID)
+		return nil, fmt.Errorf("user with id %d: %w", userID, constants.ErrUserNotFound)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("error finding user: %w", err)
 	}
 
-	return &user, nil
+	return user, nil
 }
 
-func (r *AdminRepository) EmailExistsByOtherUser(email string, userID int) (bool, error) {
+func (r *AdminRepository) EmailExistsByOtherUser(ctx context.Context, email string, userID int) (bool, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
 	var exists bool
-	err := r.db.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE email = ? AND id != ?)", email, userID).Scan(&exists)
+	err := r.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM users WHERE email = ? AND id != ?)", email, userID).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("error checking email existence: %w", err)
 	}
 	return exists, nil
 }
 
-func (r *AdminRepository) UpdateUser(userID int, email, firstName, lastName, hashedPassword *string) (*models.User, error) {
+func (r *AdminRepository) UpdateUser(ctx context.Context, userID int, email, firstName, lastName, hashedPassword *string) (*models.User, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
 	query := "UPDATE users SET "
 	args := []interface{}{}
 	updates := []string{}
@@ -241,7 +284,7 @@ func (r *AdminRepository) UpdateUser(userID int, email, firstName, lastName, has
 	}
 
 	if len(updates) == 0 {
-		return r.GetUserByID(userID)
+		return r.GetUserByID(ctx, userID)
 	}
 
 	updates = append(updates, "updated_at = CURRENT_TIMESTAMP")
@@ -249,144 +292,67 @@ func (r *AdminRepository) UpdateUser(userID int, email, firstName, lastName, has
 	query += strings.Join(updates, ", ") + " WHERE id = ?"
 	args = append(args, userID)
 
-	_, err := r.db.Exec(query, args...)
+	_, err := r.db.ExecContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("error updating user: %w", err)
 	}
 
-	return r.GetUserByID(userID)
+	return r.GetUserByID(ctx, userID)
 }
 
-func (r *AdminRepository) GetAllRentals(page, limit int) ([]*models.Rental, error) {
-	offset := (page - 1) * limit
+// GetAllRentals returns one page of the rentals matching spec. page is
+// ignored when spec carries a cursor.
+func (r *AdminRepository) GetAllRentals(ctx context.Context, spec *queryspec.Spec, page, limit int) ([]*models.Rental, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	where, args := whereClause(spec)
+	paging, pagingArgs := limitClause(spec, page, limit)
 
-	rows, err := r.db.Query(
-		`SELECT id, user_id, bike_id, status, start_time, end_time, start_latitude, 
-		start_longitude, end_latitude, end_longitude, duration_minutes, cost, created_at, updated_at 
-		FROM rentals ORDER BY id ASC LIMIT ? OFFSET ?`,
-		limit, offset,
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT `+rentalColumns+` 
+		FROM rentals`+where+orderByClause(spec)+paging,
+		append(args, pagingArgs...)...,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error querying rentals: %w", err)
 	}
-	defer rows.Close()
-
-	rentals := []*models.Rental{}
-	for rows.Next() {
-		var rental models.Rental
-		var endTime sql.NullTime
-		var endLat, endLong sql.NullFloat64
-		var durationMinutes sql.NullInt64
-		var cost sql.NullFloat64
-
-		err := rows.Scan(
-			&rental.ID, &rental.UserID, &rental.BikeID, &rental.Status,
-			&rental.StartTime, &endTime, &rental.StartLatitude,
-			&rental.StartLongitude, &endLat, &endLong,
-			&durationMinutes, &cost,
-			&rental.CreatedAt, &rental.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("error scanning rental: %w", err)
-		}
 
-		if endTime.Valid {
-			rental.EndTime = endTime.Time
-		}
-		if endLat.Valid {
-			rental.EndLatitude = endLat.Float64
-		}
-		if endLong.Valid {
-			rental.EndLongitude = endLong.Float64
-		}
-		if durationMinutes.Valid {
-			dur := int(durationMinutes.Int64)
-			rental.DurationMinutes = &dur
-		}
-		if cost.Valid {
-			c := cost.Float64
-			rental.Cost = &c
-		}
-
-		rentals = append(rentals, &rental)
-	}
+	return scanRentals(rows)
+}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating rentals: %w", err)
-	}
+func (r *AdminRepository) CountAllRentals(ctx context.Context, spec *queryspec.Spec) (int, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
 
-	return rentals, nil
-}
+	where, args := whereClause(spec)
 
-func (r *AdminRepository) CountAllRentals() (int, error) {
 	var count int
-	err := r.db.QueryRow("SELECT COUNT(*) FROM rentals").Scan(&count)
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM rentals"+where, args...).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("error counting rentals: %w", err)
 	}
 	return count, nil
 }
 
-func (r *AdminRepository) GetRentalByID(rentalID int) (*models.Rental, error) {
-	var rental models.Rental
-	var endTime sql.NullTime
-	var endLat, endLong sql.NullFloat64
-	var durationMinutes sql.NullInt64
-	var cost sql.NullFloat64
+func (r *AdminRepository) GetRentalByID(ctx context.Context, rentalID int) (*models.Rental, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
 
-	err := r.db.QueryRow(
-		`SELECT id, user_id, bike_id, status, start_time, end_time, start_latitude, 
-		start_longitude, end_latitude, end_longitude, duration_minutes, cost, created_at, updated_at 
+	rental, err := scanRental(r.db.QueryRowContext(
+		ctx,
+		`SELECT `+rentalColumns+` 
 		FROM rentals WHERE id = ?`,
 		rentalID,
-	).Scan(
-		&rental.ID, &rental.UserID, &rental.BikeID, &rental.Status,
-		&rental.StartTime, &endTime, &rental.StartLatitude,
-		&rental.StartLongitude, &endLat, &endLong,
-		&durationMinutes, &cost,
-		&rental.CreatedAt, &rental.UpdatedAt,
-	)
+	))
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("rental with id %d not found", rentalID)
+		return nil, fmt.Errorf("rental with id %d: %w", rentalID, constants.ErrRentalNotFound)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("error finding rental: %w", err)
 	}
 
-	if endTime.Valid {
-		rental.EndTime = endTime.Time
-	}
-	if endLat.Valid {
-		rental.EndLatitude = endLat.Float64
-	}
-	if endLong.Valid {
-		rental.EndLongitude = endLong.Float64
-	}
-	if durationMinutes.Valid {
-		dur := int(durationMinutes.Int64)
-		rental.DurationMinutes = &dur
-	}
-	if cost.Valid {
-		c := cost.Float64
-		rental.Cost = &c
-	}
-
-	return &rental, nil
+	return rental, nil
 }
-
-func (r *AdminRepository) UpdateRental(rentalID int, status *string) (*models.Rental, error) {
-	if status == nil {
-		return r.GetRentalByID(rentalID)
-	}
-
-	_, err := r.db.Exec(
-		"UPDATE rentals SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
-		*status, rentalID,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("error updating rental: %w", err)
-	}
-
-	return r.GetRentalByID(rentalID)
-}
\ No newline at end of file
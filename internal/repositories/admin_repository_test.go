@@ -1,10 +1,14 @@
 package repositories
 
 import (
+	"net/url"
+	"regexp"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/queryspec"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -17,16 +21,16 @@ func TestAdminRepository_CreateBike(t *testing.T) {
 	now := time.Now()
 
 	t.Run("Successfully create bike", func(t *testing.T) {
-		mock.ExpectExec("INSERT INTO bikes").
-			WithArgs(1, 40.7128, -74.0060, 0.5).
-			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery("INSERT INTO bikes (.+) RETURNING id").
+			WithArgs(1, 40.7128, -74.0060, 0.5, nil, "classic", nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 
-		mock.ExpectQuery("SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at FROM bikes WHERE id = ?").
+		mock.ExpectQuery("SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at, price_plan_id, station_id, type, battery_level FROM bikes WHERE id = ?").
 			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "is_available", "latitude", "longitude", "price_per_minute", "created_at", "updated_at"}).
-				AddRow(1, 1, 40.7128, -74.0060, 0.5, now, now))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "is_available", "latitude", "longitude", "price_per_minute", "created_at", "updated_at", "price_plan_id", "station_id", "type", "battery_level"}).
+				AddRow(1, 1, 40.7128, -74.0060, 0.5, now, now, nil, nil, "classic", nil))
 
-		bike, err := repo.CreateBike(40.7128, -74.0060, 0.5)
+		bike, err := repo.CreateBike(t.Context(), 40.7128, -74.0060, 0.5, nil, models.BikeTypeClassic, nil)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, bike)
@@ -49,21 +53,21 @@ func TestAdminRepository_UpdateBike(t *testing.T) {
 	available := false
 
 	t.Run("Update multiple fields", func(t *testing.T) {
-		mock.ExpectQuery("SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at FROM bikes WHERE id = ?").
+		mock.ExpectQuery("SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at, price_plan_id, station_id, type, battery_level FROM bikes WHERE id = ?").
 			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "is_available", "latitude", "longitude", "price_per_minute", "created_at", "updated_at"}).
-				AddRow(1, 1, 40.7128, -74.0060, 0.5, now, now))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "is_available", "latitude", "longitude", "price_per_minute", "created_at", "updated_at", "price_plan_id", "station_id", "type", "battery_level"}).
+				AddRow(1, 1, 40.7128, -74.0060, 0.5, now, now, nil, nil, "classic", nil))
 
 		mock.ExpectExec("UPDATE bikes SET").
 			WithArgs(newLat, 0, newPrice, 1).
 			WillReturnResult(sqlmock.NewResult(0, 1))
 
-		mock.ExpectQuery("SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at FROM bikes WHERE id = ?").
+		mock.ExpectQuery("SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at, price_plan_id, station_id, type, battery_level FROM bikes WHERE id = ?").
 			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "is_available", "latitude", "longitude", "price_per_minute", "created_at", "updated_at"}).
-				AddRow(1, 0, newLat, -74.0060, newPrice, now, now))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "is_available", "latitude", "longitude", "price_per_minute", "created_at", "updated_at", "price_plan_id", "station_id", "type", "battery_level"}).
+				AddRow(1, 0, newLat, -74.0060, newPrice, now, now, nil, nil, "classic", nil))
 
-		bike, err := repo.UpdateBike(1, &newLat, nil, &available, &newPrice)
+		bike, err := repo.UpdateBike(t.Context(), 1, &newLat, nil, &available, &newPrice, nil, nil, nil)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, bike)
@@ -71,12 +75,12 @@ func TestAdminRepository_UpdateBike(t *testing.T) {
 	})
 
 	t.Run("No fields to update", func(t *testing.T) {
-		mock.ExpectQuery("SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at FROM bikes WHERE id = ?").
+		mock.ExpectQuery("SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at, price_plan_id, station_id, type, battery_level FROM bikes WHERE id = ?").
 			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "is_available", "latitude", "longitude", "price_per_minute", "created_at", "updated_at"}).
-				AddRow(1, 1, 40.7128, -74.0060, 0.5, now, now))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "is_available", "latitude", "longitude", "price_per_minute", "created_at", "updated_at", "price_plan_id", "station_id", "type", "battery_level"}).
+				AddRow(1, 1, 40.7128, -74.0060, 0.5, now, now, nil, nil, "classic", nil))
 
-		bike, err := repo.UpdateBike(1, nil, nil, nil, nil)
+		bike, err := repo.UpdateBike(t.Context(), 1, nil, nil, nil, nil, nil, nil, nil)
 
 		assert.Error(t, err)
 		assert.Nil(t, bike)
@@ -94,15 +98,15 @@ func TestAdminRepository_GetAllBikes(t *testing.T) {
 	now := time.Now()
 
 	t.Run("Get bikes with pagination", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"id", "is_available", "latitude", "longitude", "price_per_minute", "created_at", "updated_at"}).
-			AddRow(1, 1, 40.7128, -74.0060, 0.5, now, now).
-			AddRow(2, 0, 40.7138, -74.0070, 0.6, now, now)
+		rows := sqlmock.NewRows([]string{"id", "is_available", "latitude", "longitude", "price_per_minute", "created_at", "updated_at", "price_plan_id", "station_id", "type", "battery_level"}).
+			AddRow(1, 1, 40.7128, -74.0060, 0.5, now, now, nil, nil, "classic", nil).
+			AddRow(2, 0, 40.7138, -74.0070, 0.6, now, now, nil, nil, "classic", nil)
 
-		mock.ExpectQuery("SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at FROM bikes ORDER BY id ASC LIMIT \\? OFFSET \\?").
+		mock.ExpectQuery("SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at, price_plan_id, station_id, type, battery_level FROM bikes ORDER BY id ASC LIMIT \\? OFFSET \\?").
 			WithArgs(10, 0).
 			WillReturnRows(rows)
 
-		bikes, err := repo.GetAllBikes(1, 10)
+		bikes, err := repo.GetAllBikes(t.Context(), nil, 1, 10)
 
 		assert.NoError(t, err)
 		assert.Len(t, bikes, 2)
@@ -119,20 +123,38 @@ func TestAdminRepository_GetAllUsers(t *testing.T) {
 	now := time.Now()
 
 	t.Run("Get users with pagination", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"id", "email", "first_name", "last_name", "created_at"}).
-			AddRow(1, "test1@example.com", "John", "Doe", now).
-			AddRow(2, "test2@example.com", "Jane", "Smith", now)
+		rows := sqlmock.NewRows([]string{"id", "email", "first_name", "last_name", "email_verified_at", "created_at"}).
+			AddRow(1, "test1@example.com", "John", "Doe", nil, now).
+			AddRow(2, "test2@example.com", "Jane", "Smith", nil, now)
 
-		mock.ExpectQuery("SELECT id, email, first_name, last_name, created_at FROM users ORDER BY id ASC LIMIT \\? OFFSET \\?").
+		mock.ExpectQuery("SELECT id, email, first_name, last_name, email_verified_at, created_at FROM users ORDER BY id ASC LIMIT \\? OFFSET \\?").
 			WithArgs(10, 0).
 			WillReturnRows(rows)
 
-		users, err := repo.GetAllUsers(1, 10)
+		users, err := repo.GetAllUsers(t.Context(), nil, 1, 10)
 
 		assert.NoError(t, err)
 		assert.Len(t, users, 2)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
+
+	t.Run("Search matches wildcards literally", func(t *testing.T) {
+		spec, errs := queryspec.Parse(url.Values{"q": {"50%_Off"}, "sort": {"-created_at"}}, queryspec.Users)
+		assert.Nil(t, errs)
+
+		rows := sqlmock.NewRows([]string{"id", "email", "first_name", "last_name", "email_verified_at", "created_at"}).
+			AddRow(1, "50%_off@example.com", "John", "Doe", nil, now)
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, email, first_name, last_name, email_verified_at, created_at FROM users WHERE (LOWER(email) LIKE ? ESCAPE '\' OR LOWER(first_name) LIKE ? ESCAPE '\' OR LOWER(last_name) LIKE ? ESCAPE '\') ORDER BY created_at DESC, id ASC LIMIT ? OFFSET ?`)).
+			WithArgs(`%50\%\_off%`, `%50\%\_off%`, `%50\%\_off%`, 10, 0).
+			WillReturnRows(rows)
+
+		users, err := repo.GetAllUsers(t.Context(), spec, 1, 10)
+
+		assert.NoError(t, err)
+		assert.Len(t, users, 1)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 }
 
 func TestAdminRepository_UpdateUser(t *testing.T) {
@@ -151,12 +173,12 @@ func TestAdminRepository_UpdateUser(t *testing.T) {
 			WithArgs(newEmail, newFirstName, 1).
 			WillReturnResult(sqlmock.NewResult(0, 1))
 
-		mock.ExpectQuery("SELECT id, email, first_name, last_name, created_at FROM users WHERE id = ?").
+		mock.ExpectQuery("SELECT id, email, first_name, last_name, email_verified_at, created_at FROM users WHERE id = ?").
 			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "first_name", "last_name", "created_at"}).
-				AddRow(1, newEmail, newFirstName, "Doe", now))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "first_name", "last_name", "email_verified_at", "created_at"}).
+				AddRow(1, newEmail, newFirstName, "Doe", nil, now))
 
-		user, err := repo.UpdateUser(1, &newEmail, &newFirstName, nil, nil)
+		user, err := repo.UpdateUser(t.Context(), 1, &newEmail, &newFirstName, nil, nil)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, user)
@@ -174,47 +196,37 @@ func TestAdminRepository_GetAllRentals(t *testing.T) {
 	now := time.Now()
 
 	t.Run("Get rentals with pagination", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"id", "user_id", "bike_id", "status", "start_time", "end_time", "start_latitude", "start_longitude", "end_latitude", "end_longitude", "duration_minutes", "cost", "created_at", "updated_at"}).
-			AddRow(1, 1, 10, "running", now, nil, 40.7128, -74.0060, nil, nil, nil, nil, now, now).
-			AddRow(2, 2, 11, "ended", now, now, 40.7128, -74.0060, 40.7200, -74.0100, 30, 15.0, now, now)
+		rows := sqlmock.NewRows([]string{"id", "user_id", "bike_id", "status", "start_time", "end_time", "start_latitude", "start_longitude", "end_latitude", "end_longitude", "duration_minutes", "cost", "created_at", "updated_at", "cost_breakdown", "distance_km"}).
+			AddRow(1, 1, 10, "running", now, nil, 40.7128, -74.0060, nil, nil, nil, nil, now, now, nil, nil).
+			AddRow(2, 2, 11, "ended", now, now, 40.7128, -74.0060, 40.7200, -74.0100, 30, 15.0, now, now, nil, nil)
 
 		mock.ExpectQuery("SELECT id, user_id, bike_id, status").
 			WithArgs(10, 0).
 			WillReturnRows(rows)
 
-		rentals, err := repo.GetAllRentals(1, 10)
+		rentals, err := repo.GetAllRentals(t.Context(), nil, 1, 10)
 
 		assert.NoError(t, err)
 		assert.Len(t, rentals, 2)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
-}
-
-func TestAdminRepository_UpdateRental(t *testing.T) {
-	db, mock, err := sqlmock.New()
-	assert.NoError(t, err)
-	defer db.Close()
 
-	repo := NewAdminRepository(db)
-	now := time.Now()
+	t.Run("Filter and sort rentals", func(t *testing.T) {
+		values, _ := url.ParseQuery("status=running&bike_id=42&start_time[gte]=2026-10-16&sort=-start_time")
+		spec, errs := queryspec.Parse(values, queryspec.Rentals)
+		assert.Nil(t, errs)
 
-	newStatus := "ended"
-
-	t.Run("Update rental status", func(t *testing.T) {
-		mock.ExpectExec("UPDATE rentals SET status = \\?, updated_at = CURRENT_TIMESTAMP WHERE id = \\?").
-			WithArgs(newStatus, 1).
-			WillReturnResult(sqlmock.NewResult(0, 1))
+		rows := sqlmock.NewRows([]string{"id", "user_id", "bike_id", "status", "start_time", "end_time", "start_latitude", "start_longitude", "end_latitude", "end_longitude", "duration_minutes", "cost", "created_at", "updated_at", "cost_breakdown", "distance_km"}).
+			AddRow(1, 1, 42, "running", now, nil, 40.7128, -74.0060, nil, nil, nil, nil, now, now, nil, nil)
 
-		mock.ExpectQuery("SELECT id, user_id, bike_id, status").
-			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "bike_id", "status", "start_time", "end_time", "start_latitude", "start_longitude", "end_latitude", "end_longitude", "duration_minutes", "cost", "created_at", "updated_at"}).
-				AddRow(1, 1, 10, newStatus, now, now, 40.7128, -74.0060, 40.7200, -74.0100, 30, 15.0, now, now))
+		mock.ExpectQuery(`FROM rentals WHERE bike_id = \? AND start_time >= \? AND status = \? ORDER BY start_time DESC, id ASC LIMIT \? OFFSET \?`).
+			WithArgs(42, time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), "running", 20, 20).
+			WillReturnRows(rows)
 
-		rental, err := repo.UpdateRental(1, &newStatus)
+		rentals, err := repo.GetAllRentals(t.Context(), spec, 2, 20)
 
 		assert.NoError(t, err)
-		assert.NotNil(t, rental)
-		assert.Equal(t, newStatus, rental.Status)
+		assert.Len(t, rentals, 1)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
@@ -230,7 +242,7 @@ func TestAdminRepository_CountMethods(t *testing.T) {
 		mock.ExpectQuery("SELECT COUNT").
 			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(10))
 
-		count, err := repo.CountAll()
+		count, err := repo.CountAll(t.Context(), nil)
 
 		assert.NoError(t, err)
 		assert.Equal(t, 10, count)
@@ -241,7 +253,7 @@ func TestAdminRepository_CountMethods(t *testing.T) {
 		mock.ExpectQuery("SELECT COUNT").
 			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
 
-		count, err := repo.CountAllUsers()
+		count, err := repo.CountAllUsers(t.Context(), nil)
 
 		assert.NoError(t, err)
 		assert.Equal(t, 5, count)
@@ -252,12 +264,27 @@ func TestAdminRepository_CountMethods(t *testing.T) {
 		mock.ExpectQuery("SELECT COUNT").
 			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(20))
 
-		count, err := repo.CountAllRentals()
+		count, err := repo.CountAllRentals(t.Context(), nil)
 
 		assert.NoError(t, err)
 		assert.Equal(t, 20, count)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
+
+	t.Run("CountAllRentals with filters", func(t *testing.T) {
+		spec, errs := queryspec.Parse(url.Values{"user_id": {"7"}}, queryspec.Rentals)
+		assert.Nil(t, errs)
+
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM rentals WHERE user_id = \?`).
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+		count, err := repo.CountAllRentals(t.Context(), spec)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, count)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 }
 
 func TestAdminRepository_EmailExistsByOtherUser(t *testing.T) {
@@ -272,7 +299,7 @@ func TestAdminRepository_EmailExistsByOtherUser(t *testing.T) {
 			WithArgs("test@example.com", 1).
 			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(1))
 
-		exists, err := repo.EmailExistsByOtherUser("test@example.com", 1)
+		exists, err := repo.EmailExistsByOtherUser(t.Context(), "test@example.com", 1)
 
 		assert.NoError(t, err)
 		assert.True(t, exists)
@@ -284,7 +311,7 @@ func TestAdminRepository_EmailExistsByOtherUser(t *testing.T) {
 			WithArgs("newuser@example.com", 2).
 			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(0))
 
-		exists, err := repo.EmailExistsByOtherUser("newuser@example.com", 2)
+		exists, err := repo.EmailExistsByOtherUser(t.Context(), "newuser@example.com", 2)
 
 		assert.NoError(t, err)
 		assert.False(t, exists)
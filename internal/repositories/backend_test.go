@@ -0,0 +1,622 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/database"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// The tests in this file run the repositories against real databases, once
+// per backend, to catch SQL that only one dialect accepts. SQLite always
+// runs. Postgres runs against TEST_DATABASE_URL when it is set, or against a
+// throwaway cluster started from the initdb and pg_ctl binaries found in
+// PG_BIN or on PATH. Without either, the Postgres variants are skipped, or
+// fail when TEST_REQUIRE_POSTGRES is set, as it is in CI, so a missing server
+// cannot pass for a green run.
+
+var (
+	postgresURL      string
+	postgresSkip     string
+	postgresRequired = os.Getenv("TEST_REQUIRE_POSTGRES") != ""
+	postgresSchemas  atomic.Int64
+)
+
+func TestMain(m *testing.M) {
+	stop := setupPostgres()
+	code := m.Run()
+	stop()
+	os.Exit(code)
+}
+
+// setupPostgres picks the Postgres server for the backend tests and returns a
+// function that shuts down any server it started.
+func setupPostgres() func() {
+	if postgresURL = os.Getenv("TEST_DATABASE_URL"); postgresURL != "" {
+		return func() {}
+	}
+
+	url, stop, err := startLocalPostgres()
+	if err != nil {
+		postgresSkip = err.Error()
+		return func() {}
+	}
+
+	postgresURL = url
+	return stop
+}
+
+// startLocalPostgres initialises a cluster in a temporary directory and
+// starts it on a free local port.
+func startLocalPostgres() (string, func(), error) {
+	initdb, err := findPostgresBinary("initdb")
+	if err != nil {
+		return "", nil, err
+	}
+	pgCtl, err := findPostgresBinary("pg_ctl")
+	if err != nil {
+		return "", nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "bike-rental-pg")
+	if err != nil {
+		return "", nil, err
+	}
+	dataDir := filepath.Join(dir, "data")
+
+	if out, err := exec.Command(initdb, "-D", dataDir, "-U", "postgres", "-A", "trust", "--no-sync").CombinedOutput(); err != nil {
+		os.RemoveAll(dir)
+		return "", nil, fmt.Errorf("initdb failed: %v: %s", err, out)
+	}
+
+	port, err := freePort()
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", nil, err
+	}
+
+	options := fmt.Sprintf("-p %d -k %s -c listen_addresses=127.0.0.1 -c fsync=off", port, dir)
+	if out, err := exec.Command(pgCtl, "-D", dataDir, "-o", options, "-w", "start").CombinedOutput(); err != nil {
+		os.RemoveAll(dir)
+		return "", nil, fmt.Errorf("pg_ctl start failed: %v: %s", err, out)
+	}
+
+	stop := func() {
+		_ = exec.Command(pgCtl, "-D", dataDir, "-m", "immediate", "stop").Run()
+		os.RemoveAll(dir)
+	}
+
+	return fmt.Sprintf("postgres://postgres@127.0.0.1:%d/postgres?sslmode=disable", port), stop, nil
+}
+
+func findPostgresBinary(name string) (string, error) {
+	if dir := os.Getenv("PG_BIN"); dir != "" {
+		return filepath.Join(dir, name), nil
+	}
+
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", fmt.Errorf("set TEST_DATABASE_URL or put %s on PATH to run the Postgres tests", name)
+	}
+	return path, nil
+}
+
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// forEachBackend runs fn against a freshly migrated database on every
+// backend.
+func forEachBackend(t *testing.T, fn func(t *testing.T, db *database.DB)) {
+	t.Run(database.DriverSQLite, func(t *testing.T) {
+		db, err := database.Connect(database.DriverSQLite, filepath.Join(t.TempDir(), "test.db"), true)
+		if err != nil {
+			t.Fatalf("failed to open SQLite database: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		fn(t, db)
+	})
+
+	t.Run(database.DriverPostgres, func(t *testing.T) {
+		if postgresURL == "" && postgresRequired {
+			t.Fatalf("TEST_REQUIRE_POSTGRES is set but no Postgres server is available: %s", postgresSkip)
+		}
+		if postgresURL == "" {
+			t.Skip(postgresSkip)
+		}
+
+		fn(t, openPostgresSchema(t))
+	})
+}
+
+// openPostgresSchema connects to a new schema so every test starts from an
+// empty, migrated database. The schema is dropped when the test ends.
+func openPostgresSchema(t *testing.T) *database.DB {
+	t.Helper()
+
+	admin, err := sql.Open("pgx", postgresURL)
+	if err != nil {
+		t.Fatalf("failed to open Postgres: %v", err)
+	}
+	defer admin.Close()
+
+	schema := fmt.Sprintf("repo_test_%d_%d", os.Getpid(), postgresSchemas.Add(1))
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		cleanup, err := sql.Open("pgx", postgresURL)
+		if err != nil {
+			return
+		}
+		defer cleanup.Close()
+		_, _ = cleanup.Exec("DROP SCHEMA " + schema + " CASCADE")
+	})
+
+	db, err := database.Connect(database.DriverPostgres, withSearchPath(t, postgresURL, schema), true)
+	if err != nil {
+		t.Fatalf("failed to open Postgres schema: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func withSearchPath(t *testing.T, dsn, schema string) string {
+	t.Helper()
+
+	if !strings.Contains(dsn, "://") {
+		return dsn + " search_path=" + schema
+	}
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatalf("invalid TEST_DATABASE_URL: %v", err)
+	}
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+func createBackendUser(t *testing.T, db *database.DB, email string) *models.User {
+	t.Helper()
+
+	user, err := NewUserRepository(db).Create(t.Context(), email, "hashed", "Test", "Rider")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	return user
+}
+
+func createBackendBike(t *testing.T, db *database.DB, latitude, longitude float64) *models.Bike {
+	t.Helper()
+
+	bike, err := NewAdminRepository(db).CreateBike(t.Context(), latitude, longitude, 0.5, nil, models.BikeTypeClassic, nil)
+	if err != nil {
+		t.Fatalf("failed to create bike: %v", err)
+	}
+	return bike
+}
+
+func TestBackend_Users(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *database.DB) {
+		repo := NewUserRepository(db)
+
+		user := createBackendUser(t, db, "rider@example.com")
+		assert.Positive(t, user.ID)
+		assert.Equal(t, "rider@example.com", user.Email)
+		assert.Nil(t, user.EmailVerifiedAt)
+
+		exists, err := repo.EmailExists(t.Context(), "rider@example.com")
+		assert.NoError(t, err)
+		assert.True(t, exists)
+
+		exists, err = repo.EmailExistsByOtherUser(t.Context(), "rider@example.com", user.ID)
+		assert.NoError(t, err)
+		assert.False(t, exists)
+
+		verifiedAt := time.Now().UTC().Truncate(time.Second)
+		assert.NoError(t, repo.MarkEmailVerified(t.Context(), user.ID, verifiedAt))
+
+		email := "new@example.com"
+		updated, err := repo.Update(t.Context(), user.ID, &email, nil, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, email, updated.Email)
+		assert.Nil(t, updated.EmailVerifiedAt, "a new email has to be verified again")
+	})
+}
+
+func TestBackend_AdminAccountsRejectDuplicateEmail(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *database.DB) {
+		repo := NewAdminAccountRepository(db)
+
+		admin, err := repo.Create(t.Context(), "ops@example.com", "hashed", "Ops", models.AdminRoleFleet)
+		assert.NoError(t, err)
+		assert.Equal(t, models.AdminRoleFleet, admin.Role)
+
+		_, err = repo.Create(t.Context(), "ops@example.com", "hashed", "Ops", models.AdminRoleFleet)
+		assert.ErrorIs(t, err, constants.ErrEmailAlreadyExists)
+	})
+}
+
+func TestBackend_BikeAvailability(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *database.DB) {
+		repo := NewBikeRepository(db)
+
+		bike := createBackendBike(t, db, 51.5074, -0.1278)
+		createBackendBike(t, db, 53.4808, -2.2426)
+		assert.True(t, bike.IsAvailable)
+
+		nearby, err := repo.GetAvailableInBounds(t.Context(), models.BikeFilter{}, 51.4, 51.6, -0.2, -0.1)
+		assert.NoError(t, err)
+		assert.Len(t, nearby, 1)
+
+		claimed, err := repo.ClaimAvailable(t.Context(), bike.ID)
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+
+		claimed, err = repo.ClaimAvailable(t.Context(), bike.ID)
+		assert.NoError(t, err)
+		assert.False(t, claimed, "a bike can only be claimed once")
+
+		count, err := repo.CountAvailable(t.Context())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		assert.NoError(t, repo.UpdateAvailability(t.Context(), bike.ID, true))
+		bike, err = repo.GetByID(t.Context(), bike.ID)
+		assert.NoError(t, err)
+		assert.True(t, bike.IsAvailable)
+	})
+}
+
+func TestBackend_BikeTypes(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *database.DB) {
+		repo := NewBikeRepository(db)
+		admin := NewAdminRepository(db)
+		prices := NewBikeTypePriceRepository(db)
+
+		low, full := 10, 90
+		createBackendBike(t, db, 51.5074, -0.1278)
+		_, err := admin.CreateBike(t.Context(), 51.5080, -0.1280, 0.8, nil, models.BikeTypeEBike, &low)
+		assert.NoError(t, err)
+		charged, err := admin.CreateBike(t.Context(), 51.5090, -0.1290, 0.8, nil, models.BikeTypeEBike, &full)
+		assert.NoError(t, err)
+		assert.Equal(t, models.BikeTypeEBike, charged.Type)
+		if assert.NotNil(t, charged.RangeKm) {
+			assert.Equal(t, 54.0, *charged.RangeKm)
+		}
+
+		filter := models.BikeFilter{Type: models.BikeTypeEBike, MinBatteryLevel: 20}
+		count, err := repo.CountAvailableMatching(t.Context(), filter)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		bikes, err := repo.GetAvailable(t.Context(), models.BikeFilter{MinBatteryLevel: 20}, 1, 10)
+		assert.NoError(t, err)
+		assert.Len(t, bikes, 2, "the classic bike and the charged e-bike")
+
+		bikes, err = repo.GetAvailableInBounds(t.Context(), filter, 51.4, 51.6, -0.2, -0.1)
+		assert.NoError(t, err)
+		if assert.Len(t, bikes, 1) {
+			assert.Equal(t, charged.ID, bikes[0].ID)
+		}
+
+		classic := models.BikeTypeClassic
+		updated, err := admin.UpdateBike(t.Context(), charged.ID, nil, nil, nil, nil, nil, &classic, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, models.BikeTypeClassic, updated.Type)
+		assert.Nil(t, updated.BatteryLevel, "a classic bike has no battery")
+
+		price, err := prices.GetByType(t.Context(), models.BikeTypeCargo)
+		assert.NoError(t, err)
+		assert.Equal(t, 0.5, price.PricePerMinute)
+
+		price, err = prices.Save(t.Context(), models.BikeTypeCargo, 0.9)
+		assert.NoError(t, err)
+		assert.Equal(t, 0.9, price.PricePerMinute)
+
+		all, err := prices.GetAll(t.Context())
+		assert.NoError(t, err)
+		assert.Len(t, all, 3)
+	})
+}
+
+func TestBackend_RentalsAndReservations(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *database.DB) {
+		rentals := NewRentalRepository(db)
+		reservations := NewReservationRepository(db)
+
+		rider := createBackendUser(t, db, "rider@example.com")
+		other := createBackendUser(t, db, "other@example.com")
+		bike := createBackendBike(t, db, 51.5074, -0.1278)
+		spare := createBackendBike(t, db, 51.5155, -0.0922)
+		startedAt := time.Now().UTC().Truncate(time.Second)
+
+		rental, err := rentals.Create(t.Context(), rider.ID, bike.ID, bike.Latitude, bike.Longitude, startedAt)
+		assert.NoError(t, err)
+		assert.Equal(t, models.RentalStatusRunning, rental.Status)
+
+		_, err = rentals.Create(t.Context(), rider.ID, spare.ID, spare.Latitude, spare.Longitude, startedAt)
+		assert.ErrorIs(t, err, constants.ErrUserHasActiveRental)
+
+		endLat, endLong := 51.51, -0.12
+		ended, err := rentals.Close(t.Context(), rental.ID, models.RentalStatusEnded, startedAt.Add(12*time.Minute), &endLat, &endLong, nil, 12, 6.5, []models.CostLineItem{{Description: "Ride", Amount: 6.5}})
+		assert.NoError(t, err)
+		assert.Equal(t, models.RentalStatusEnded, ended.Status)
+		assert.Len(t, ended.CostBreakdown, 1)
+
+		expiresAt := time.Now().UTC().Add(10 * time.Minute).Truncate(time.Second)
+		reservation, err := reservations.Create(t.Context(), rider.ID, spare.ID, expiresAt)
+		assert.NoError(t, err)
+		assert.Equal(t, models.ReservationStatusActive, reservation.Status)
+
+		_, err = reservations.Create(t.Context(), other.ID, spare.ID, expiresAt)
+		assert.ErrorIs(t, err, constants.ErrBikeReserved)
+
+		expired, err := reservations.GetExpired(t.Context(), expiresAt.Add(time.Minute))
+		assert.NoError(t, err)
+		assert.Len(t, expired, 1)
+	})
+}
+
+func TestBackend_TokenRevocation(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *database.DB) {
+		refreshTokens := NewRefreshTokenRepository(db)
+		revokedTokens := NewRevokedTokenRepository(db)
+
+		user := createBackendUser(t, db, "rider@example.com")
+		now := time.Now().UTC().Truncate(time.Second)
+
+		_, err := refreshTokens.Create(t.Context(), user.ID, "family", "hash", "access-jti", now.Add(15*time.Minute), now.Add(24*time.Hour))
+		assert.NoError(t, err)
+
+		assert.NoError(t, revokedTokens.RevokeFamilyAccessTokens(t.Context(), "family", now))
+		assert.NoError(t, revokedTokens.RevokeFamilyAccessTokens(t.Context(), "family", now), "revoking twice is a no-op")
+
+		revoked, err := revokedTokens.IsRevoked(t.Context(), "access-jti")
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+
+		assert.NoError(t, refreshTokens.RevokeFamily(t.Context(), "family", now))
+		token, err := refreshTokens.GetByHash(t.Context(), "hash")
+		assert.NoError(t, err)
+		assert.NotNil(t, token.RevokedAt)
+	})
+}
+
+func TestBackend_TokenCleanup(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *database.DB) {
+		refreshTokens := NewRefreshTokenRepository(db)
+		revokedTokens := NewRevokedTokenRepository(db)
+
+		user := createBackendUser(t, db, "rider@example.com")
+		now := time.Now().UTC().Truncate(time.Second)
+
+		_, err := refreshTokens.Create(t.Context(), user.ID, "old", "expired-hash", "expired-jti", now.Add(-2*time.Hour), now.Add(-time.Hour))
+		assert.NoError(t, err)
+		_, err = refreshTokens.Create(t.Context(), user.ID, "new", "live-hash", "live-jti", now.Add(15*time.Minute), now.Add(24*time.Hour))
+		assert.NoError(t, err)
+		assert.NoError(t, revokedTokens.Revoke(t.Context(), "expired-jti", user.ID, now.Add(-2*time.Hour)))
+		assert.NoError(t, revokedTokens.Revoke(t.Context(), "live-jti", user.ID, now.Add(15*time.Minute)))
+
+		deleted, err := refreshTokens.DeleteExpired(t.Context(), now)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+		deleted, err = revokedTokens.DeleteExpired(t.Context(), now)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		_, err = refreshTokens.GetByHash(t.Context(), "expired-hash")
+		assert.ErrorIs(t, err, constants.ErrInvalidRefreshToken)
+		_, err = refreshTokens.GetByHash(t.Context(), "live-hash")
+		assert.NoError(t, err)
+
+		revoked, err := revokedTokens.IsRevoked(t.Context(), "expired-jti")
+		assert.NoError(t, err)
+		assert.False(t, revoked)
+		revoked, err = revokedTokens.IsRevoked(t.Context(), "live-jti")
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+}
+
+func TestBackend_UnitOfWorkRollsBack(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *database.DB) {
+		bike := createBackendBike(t, db, 51.5074, -0.1278)
+
+		err := NewUnitOfWork(db).WithTx(t.Context(), func(tx *Tx) error {
+			if _, err := tx.Bikes.ClaimAvailable(t.Context(), bike.ID); err != nil {
+				return err
+			}
+			return constants.ErrBikeNotAvailable
+		})
+		assert.ErrorIs(t, err, constants.ErrBikeNotAvailable)
+
+		bike, err = NewBikeRepository(db).GetByID(t.Context(), bike.ID)
+		assert.NoError(t, err)
+		assert.True(t, bike.IsAvailable, "the claim should have been rolled back")
+	})
+}
+
+func TestBackend_EmailOutbox(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *database.DB) {
+		repo := NewEmailOutboxRepository(db)
+		now := time.Now().UTC().Truncate(time.Second)
+
+		assert.NoError(t, repo.Enqueue(t.Context(), "rider@example.com", "Subject", "Body", now))
+
+		leaseUntil := now.Add(10 * time.Minute)
+		due, err := repo.ClaimDue(t.Context(), now, leaseUntil, 10)
+		assert.NoError(t, err)
+		assert.Len(t, due, 1)
+
+		again, err := repo.ClaimDue(t.Context(), now, leaseUntil, 10)
+		assert.NoError(t, err)
+		assert.Empty(t, again, "a claimed email is not handed out twice")
+
+		expired, err := repo.ClaimDue(t.Context(), leaseUntil, leaseUntil.Add(10*time.Minute), 10)
+		assert.NoError(t, err)
+		if assert.Len(t, expired, 1, "an unfinished claim is retried after its lease") {
+			assert.NoError(t, repo.MarkAttemptFailed(t.Context(), expired[0].ID, 1, models.OutboxStatusPending, "timeout", now.Add(time.Hour)))
+		}
+
+		due, err = repo.ClaimDue(t.Context(), leaseUntil, leaseUntil, 10)
+		assert.NoError(t, err)
+		assert.Empty(t, due, "a failed email waits for its next attempt")
+	})
+}
+
+func TestBackend_EmailOutbox_ConcurrentClaims(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *database.DB) {
+		repo := NewEmailOutboxRepository(db)
+		now := time.Now().UTC().Truncate(time.Second)
+
+		const emails = 20
+		for i := 0; i < emails; i++ {
+			assert.NoError(t, repo.Enqueue(t.Context(), "rider@example.com", "Subject", "Body", now))
+		}
+
+		const workers = 4
+		claimed := make(chan int, emails*workers)
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					batch, err := repo.ClaimDue(t.Context(), now, now.Add(time.Hour), 3)
+					if err != nil {
+						t.Errorf("claim failed: %v", err)
+						return
+					}
+					if len(batch) == 0 {
+						return
+					}
+					for _, email := range batch {
+						claimed <- email.ID
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		close(claimed)
+
+		seen := map[int]int{}
+		for id := range claimed {
+			seen[id]++
+		}
+		assert.Len(t, seen, emails)
+		for id, count := range seen {
+			assert.Equal(t, 1, count, "email %d was claimed more than once", id)
+		}
+	})
+}
+
+func TestBackend_ParkAt_ConcurrentReturnsToStation(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *database.DB) {
+		radius := 50.0
+		station, err := NewStationRepository(db).Create(t.Context(), &models.Station{
+			Name: "Sol", Latitude: 40.42, Longitude: -3.7, RadiusMeters: &radius, Capacity: 3,
+		})
+		assert.NoError(t, err)
+
+		const bikes = 12
+		bikeIDs := make([]int, bikes)
+		for i := range bikeIDs {
+			bikeIDs[i] = createBackendBike(t, db, 40.41, -3.71).ID
+		}
+
+		// Each return parks inside its own transaction, as EndRental does,
+		// so only the station lock keeps them from all seeing a free dock.
+		uow := NewUnitOfWork(db)
+		errs := make(chan error, bikes)
+		var wg sync.WaitGroup
+		for _, bikeID := range bikeIDs {
+			wg.Add(1)
+			go func(bikeID int) {
+				defer wg.Done()
+				errs <- uow.WithTx(t.Context(), func(tx *Tx) error {
+					return tx.Bikes.ParkAt(t.Context(), bikeID, &station.ID, 40.42, -3.7)
+				})
+			}(bikeID)
+		}
+		wg.Wait()
+		close(errs)
+
+		parked := 0
+		for err := range errs {
+			if err == nil {
+				parked++
+				continue
+			}
+			assert.ErrorIs(t, err, constants.ErrStationFull)
+		}
+		assert.Equal(t, 3, parked)
+
+		full, err := NewStationRepository(db).GetByID(t.Context(), station.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, full.ParkedBikes)
+		assert.Equal(t, 0, full.FreeDocks)
+	})
+}
+
+func TestBackend_PricePlanZoneRates(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db *database.DB) {
+		radius := 50.0
+		station, err := NewStationRepository(db).Create(t.Context(), &models.Station{
+			Name: "Sol", Latitude: 40.42, Longitude: -3.7, RadiusMeters: &radius, Capacity: 3,
+		})
+		assert.NoError(t, err)
+
+		plans := NewPricePlanRepository(db)
+		plan, err := plans.Create(t.Context(), &models.PricePlan{
+			Name: "Centro", NightMultiplier: 1, WeekendMultiplier: 1, Timezone: "UTC",
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, plan.ZoneRates)
+
+		rates := []models.ZoneRate{
+			{StationID: station.ID, ApplyTo: models.ZoneRateStart, Fee: 0.5, Multiplier: 1},
+			{StationID: station.ID, ApplyTo: models.ZoneRateEnd, Multiplier: 1.25},
+		}
+		assert.NoError(t, plans.ReplaceZoneRates(t.Context(), plan.ID, rates))
+
+		stored, err := plans.GetByID(t.Context(), plan.ID)
+		assert.NoError(t, err)
+		assert.Len(t, stored.ZoneRates, 2)
+		assert.Equal(t, 1.25, stored.ZoneRates[1].Multiplier)
+		assert.Equal(t, "Sol", stored.ZoneRates[1].Station.Name)
+
+		err = plans.ReplaceZoneRates(t.Context(), plan.ID, []models.ZoneRate{rates[0], rates[0]})
+		assert.ErrorIs(t, err, constants.ErrDuplicateZoneRate)
+
+		err = NewStationRepository(db).Delete(t.Context(), station.ID)
+		assert.Equal(t, constants.ErrStationHasZoneRates, err)
+
+		assert.NoError(t, plans.Delete(t.Context(), plan.ID))
+		assert.NoError(t, NewStationRepository(db).Delete(t.Context(), station.ID))
+	})
+}
@@ -1,89 +1,211 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"math"
 
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
 	"github.com/Nimirandad/bike-rental-service/internal/models"
 )
 
+const bikeColumns = "id, is_available, latitude, longitude, price_per_minute, created_at, updated_at, price_plan_id, station_id, type, battery_level"
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBike(row rowScanner) (*models.Bike, error) {
+	var bike models.Bike
+	var isAvailable int
+	var pricePlanID, stationID, batteryLevel sql.NullInt64
+
+	err := row.Scan(&bike.ID, &isAvailable, &bike.Latitude, &bike.Longitude, &bike.PricePerMinute, &bike.CreatedAt, &bike.UpdatedAt, &pricePlanID, &stationID, &bike.Type, &batteryLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	bike.IsAvailable = isAvailable == 1
+	if pricePlanID.Valid {
+		id := int(pricePlanID.Int64)
+		bike.PricePlanID = &id
+	}
+	if stationID.Valid {
+		id := int(stationID.Int64)
+		bike.StationID = &id
+	}
+	if batteryLevel.Valid {
+		level := int(batteryLevel.Int64)
+		bike.BatteryLevel = &level
+		if bike.Type == models.BikeTypeEBike {
+			rangeKm := math.Round(float64(level)/100*constants.EBikeFullChargeRangeKm*10) / 10
+			bike.RangeKm = &rangeKm
+		}
+	}
+
+	return &bike, nil
+}
+
+func scanBikes(rows *sql.Rows) ([]*models.Bike, error) {
+	defer rows.Close()
+
+	bikes := []*models.Bike{}
+	for rows.Next() {
+		bike, err := scanBike(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning bike: %w", err)
+		}
+		bikes = append(bikes, bike)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating bikes: %w", err)
+	}
+
+	return bikes, nil
+}
+
 type BikeRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewBikeRepository(db *sql.DB) *BikeRepository {
+func NewBikeRepository(db DBTX) *BikeRepository {
 	return &BikeRepository{db: db}
 }
 
-func (r *BikeRepository) CountAvailable() (int, error) {
+// availableWhere selects the available bikes that match filter.
+func availableWhere(filter models.BikeFilter) (string, []interface{}) {
+	where := " WHERE is_available = 1"
+	args := []interface{}{}
+
+	if filter.Type != "" {
+		where += " AND type = ?"
+		args = append(args, string(filter.Type))
+	}
+	if filter.MinBatteryLevel > 0 {
+		where += " AND (type <> ? OR battery_level IS NULL OR battery_level >= ?)"
+		args = append(args, string(models.BikeTypeEBike), filter.MinBatteryLevel)
+	}
+
+	return where, args
+}
+
+func (r *BikeRepository) CountAvailable(ctx context.Context) (int, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
 	var count int
-	err := r.db.QueryRow("SELECT COUNT(*) FROM bikes WHERE is_available = 1").Scan(&count)
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM bikes WHERE is_available = 1").Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("error counting available bikes: %w", err)
 	}
 	return count, nil
 }
 
-func (r *BikeRepository) GetAvailable(page, limit int) ([]*models.Bike, error) {
+// CountAvailableMatching counts the available bikes that match filter.
+func (r *BikeRepository) CountAvailableMatching(ctx context.Context, filter models.BikeFilter) (int, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	where, args := availableWhere(filter)
+
+	var count int
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM bikes"+where, args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error counting available bikes: %w", err)
+	}
+	return count, nil
+}
+
+func (r *BikeRepository) GetAvailable(ctx context.Context, filter models.BikeFilter, page, limit int) ([]*models.Bike, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
 	offset := (page - 1) * limit
+	where, args := availableWhere(filter)
 
-	rows, err := r.db.Query(
-		"SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at FROM bikes WHERE is_available = 1 LIMIT ? OFFSET ?",
-		limit, offset,
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT "+bikeColumns+" FROM bikes"+where+" LIMIT ? OFFSET ?",
+		append(args, limit, offset)...,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error querying available bikes: %w", err)
 	}
-	defer rows.Close()
 
-	bikes := []*models.Bike{}
-	for rows.Next() {
-		var bike models.Bike
-		var isAvailable int
+	return scanBikes(rows)
+}
 
-		err := rows.Scan(&bike.ID, &isAvailable, &bike.Latitude, &bike.Longitude, &bike.PricePerMinute, &bike.CreatedAt, &bike.UpdatedAt)
-		if err != nil {
-			return nil, fmt.Errorf("error scanning bike: %w", err)
-		}
+func (r *BikeRepository) GetAvailableInBounds(ctx context.Context, filter models.BikeFilter, minLat, maxLat, minLong, maxLong float64) ([]*models.Bike, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
 
-		bike.IsAvailable = isAvailable == 1
-		bikes = append(bikes, &bike)
-	}
+	where, args := availableWhere(filter)
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating bikes: %w", err)
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT "+bikeColumns+" FROM bikes"+where+" AND latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ?",
+		append(args, minLat, maxLat, minLong, maxLong)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying nearby bikes: %w", err)
 	}
 
-	return bikes, nil
+	return scanBikes(rows)
 }
 
-func (r *BikeRepository) GetByID(bikeID int) (*models.Bike, error) {
-	var bike models.Bike
-	var isAvailable int
+func (r *BikeRepository) GetByID(ctx context.Context, bikeID int) (*models.Bike, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
 
-	err := r.db.QueryRow(
-		"SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at FROM bikes WHERE id = ?",
-		bikeID,
-	).Scan(&bike.ID, &isAvailable, &bike.Latitude, &bike.Longitude, &bike.PricePerMinute, &bike.CreatedAt, &bike.UpdatedAt)
+	bike, err := scanBike(r.db.QueryRowContext(ctx, "SELECT "+bikeColumns+" FROM bikes WHERE id = ?", bikeID))
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("bike with id %d not found", bikeID)
+		return nil, fmt.Errorf("bike with id %d: %w", bikeID, constants.ErrBikeNotFound)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("error finding bike: %w", err)
 	}
 
-	bike.IsAvailable = isAvailable == 1
-	return &bike, nil
+	return bike, nil
 }
 
-func (r *BikeRepository) UpdateAvailability(bikeID int, isAvailable bool) error {
+// ClaimAvailable marks the bike as unavailable only if it is currently
+// available. It reports false when the bike is missing or already taken.
+func (r *BikeRepository) ClaimAvailable(ctx context.Context, bikeID int) (bool, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	result, err := r.db.ExecContext(
+		ctx,
+		"UPDATE bikes SET is_available = 0, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND is_available = 1",
+		bikeID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("error claiming bike: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error claiming bike: %w", err)
+	}
+
+	return affected == 1, nil
+}
+
+func (r *BikeRepository) UpdateAvailability(ctx context.Context, bikeID int, isAvailable bool) error {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
 	availableInt := 0
 	if isAvailable {
 		availableInt = 1
 	}
 
-	_, err := r.db.Exec(
+	_, err := r.db.ExecContext(
+		ctx,
 		"UPDATE bikes SET is_available = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
 		availableInt, bikeID,
 	)
@@ -92,4 +214,71 @@ func (r *BikeRepository) UpdateAvailability(bikeID int, isAvailable bool) error
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// Park records the station the bike is parked at. A nil stationID means the
+// bike is free-floating or out on a rental.
+func (r *BikeRepository) Park(ctx context.Context, bikeID int, stationID *int) error {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	_, err := r.db.ExecContext(
+		ctx,
+		"UPDATE bikes SET station_id = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+		stationID, bikeID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating bike station: %w", err)
+	}
+
+	return nil
+}
+
+// ParkAt records where a returned bike was left: its new coordinates and the
+// station it is parked at, or nil when it is free-floating. A bike is only
+// parked at a station with a free dock; otherwise ParkAt returns
+// constants.ErrStationFull.
+func (r *BikeRepository) ParkAt(ctx context.Context, bikeID int, stationID *int, latitude, longitude float64) error {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	if stationID == nil {
+		_, err := r.db.ExecContext(
+			ctx,
+			"UPDATE bikes SET station_id = NULL, latitude = ?, longitude = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+			latitude, longitude, bikeID,
+		)
+		if err != nil {
+			return fmt.Errorf("error updating bike location: %w", err)
+		}
+		return nil
+	}
+
+	// Writing the station row locks it until the transaction ends, so on
+	// Postgres concurrent returns to the station wait here and then count
+	// each other's bikes. SQLite transactions already hold the write lock.
+	_, err := r.db.ExecContext(ctx, "UPDATE stations SET updated_at = updated_at WHERE id = ?", *stationID)
+	if err != nil {
+		return fmt.Errorf("error locking station: %w", err)
+	}
+
+	result, err := r.db.ExecContext(
+		ctx,
+		`UPDATE bikes SET station_id = ?, latitude = ?, longitude = ?, updated_at = CURRENT_TIMESTAMP 
+		WHERE id = ? AND (SELECT COUNT(*) FROM bikes WHERE station_id = ? AND id <> ?) < (SELECT capacity FROM stations WHERE id = ?)`,
+		*stationID, latitude, longitude, bikeID, *stationID, bikeID, *stationID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating bike location: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error updating bike location: %w", err)
+	}
+	if affected == 0 {
+		return constants.ErrStationFull
+	}
+
+	return nil
+}
@@ -6,6 +6,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
 )
@@ -21,7 +24,7 @@ func TestBikeRepository_CountAvailable(t *testing.T) {
 		mock.ExpectQuery("SELECT COUNT").
 			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
 
-		count, err := repo.CountAvailable()
+		count, err := repo.CountAvailable(t.Context())
 
 		assert.NoError(t, err)
 		assert.Equal(t, 5, count)
@@ -32,7 +35,7 @@ func TestBikeRepository_CountAvailable(t *testing.T) {
 		mock.ExpectQuery("SELECT COUNT").
 			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 
-		count, err := repo.CountAvailable()
+		count, err := repo.CountAvailable(t.Context())
 
 		assert.NoError(t, err)
 		assert.Equal(t, 0, count)
@@ -43,7 +46,7 @@ func TestBikeRepository_CountAvailable(t *testing.T) {
 		mock.ExpectQuery("SELECT COUNT").
 			WillReturnError(fmt.Errorf("database error"))
 
-		count, err := repo.CountAvailable()
+		count, err := repo.CountAvailable(t.Context())
 
 		assert.Error(t, err)
 		assert.Equal(t, 0, count)
@@ -61,15 +64,15 @@ func TestBikeRepository_GetAvailable(t *testing.T) {
 	now := time.Now()
 
 	t.Run("Successfully get available bikes - first page", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"id", "is_available", "latitude", "longitude", "price_per_minute", "created_at", "updated_at"}).
-			AddRow(1, 1, 40.7128, -74.0060, 0.5, now, now).
-			AddRow(2, 1, 40.7138, -74.0070, 0.6, now, now)
+		rows := sqlmock.NewRows([]string{"id", "is_available", "latitude", "longitude", "price_per_minute", "created_at", "updated_at", "price_plan_id", "station_id", "type", "battery_level"}).
+			AddRow(1, 1, 40.7128, -74.0060, 0.5, now, now, nil, nil, "classic", nil).
+			AddRow(2, 1, 40.7138, -74.0070, 0.6, now, now, nil, nil, "classic", nil)
 
-		mock.ExpectQuery("SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at FROM bikes WHERE is_available = 1 LIMIT \\? OFFSET \\?").
+		mock.ExpectQuery("SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at, price_plan_id, station_id, type, battery_level FROM bikes WHERE is_available = 1 LIMIT \\? OFFSET \\?").
 			WithArgs(10, 0).
 			WillReturnRows(rows)
 
-		bikes, err := repo.GetAvailable(1, 10)
+		bikes, err := repo.GetAvailable(t.Context(), models.BikeFilter{}, 1, 10)
 
 		assert.NoError(t, err)
 		assert.Len(t, bikes, 2)
@@ -82,14 +85,14 @@ func TestBikeRepository_GetAvailable(t *testing.T) {
 	})
 
 	t.Run("Successfully get available bikes - second page", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"id", "is_available", "latitude", "longitude", "price_per_minute", "created_at", "updated_at"}).
-			AddRow(11, 1, 40.7200, -74.0100, 0.7, now, now)
+		rows := sqlmock.NewRows([]string{"id", "is_available", "latitude", "longitude", "price_per_minute", "created_at", "updated_at", "price_plan_id", "station_id", "type", "battery_level"}).
+			AddRow(11, 1, 40.7200, -74.0100, 0.7, now, now, nil, nil, "classic", nil)
 
-		mock.ExpectQuery("SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at FROM bikes WHERE is_available = 1 LIMIT \\? OFFSET \\?").
+		mock.ExpectQuery("SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at, price_plan_id, station_id, type, battery_level FROM bikes WHERE is_available = 1 LIMIT \\? OFFSET \\?").
 			WithArgs(10, 10).
 			WillReturnRows(rows)
 
-		bikes, err := repo.GetAvailable(2, 10)
+		bikes, err := repo.GetAvailable(t.Context(), models.BikeFilter{}, 2, 10)
 
 		assert.NoError(t, err)
 		assert.Len(t, bikes, 1)
@@ -97,14 +100,32 @@ func TestBikeRepository_GetAvailable(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
+	t.Run("Filters by type and battery level", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "is_available", "latitude", "longitude", "price_per_minute", "created_at", "updated_at", "price_plan_id", "station_id", "type", "battery_level"}).
+			AddRow(3, 1, 40.7128, -74.0060, 0.8, now, now, nil, nil, "ebike", 75)
+
+		mock.ExpectQuery("SELECT (.+) FROM bikes WHERE is_available = 1 AND type = \\? AND \\(type <> \\? OR battery_level IS NULL OR battery_level >= \\?\\) LIMIT \\? OFFSET \\?").
+			WithArgs("ebike", "ebike", 20, 10, 0).
+			WillReturnRows(rows)
+
+		bikes, err := repo.GetAvailable(t.Context(), models.BikeFilter{Type: models.BikeTypeEBike, MinBatteryLevel: 20}, 1, 10)
+
+		assert.NoError(t, err)
+		assert.Len(t, bikes, 1)
+		assert.Equal(t, models.BikeTypeEBike, bikes[0].Type)
+		assert.Equal(t, 75, *bikes[0].BatteryLevel)
+		assert.Equal(t, 45.0, *bikes[0].RangeKm)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
 	t.Run("Empty result", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"id", "is_available", "latitude", "longitude", "price_per_minute", "created_at", "updated_at"})
+		rows := sqlmock.NewRows([]string{"id", "is_available", "latitude", "longitude", "price_per_minute", "created_at", "updated_at", "price_plan_id", "station_id", "type", "battery_level"})
 
-		mock.ExpectQuery("SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at FROM bikes WHERE is_available = 1 LIMIT \\? OFFSET \\?").
+		mock.ExpectQuery("SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at, price_plan_id, station_id, type, battery_level FROM bikes WHERE is_available = 1 LIMIT \\? OFFSET \\?").
 			WithArgs(10, 0).
 			WillReturnRows(rows)
 
-		bikes, err := repo.GetAvailable(1, 10)
+		bikes, err := repo.GetAvailable(t.Context(), models.BikeFilter{}, 1, 10)
 
 		assert.NoError(t, err)
 		assert.Len(t, bikes, 0)
@@ -112,11 +133,11 @@ func TestBikeRepository_GetAvailable(t *testing.T) {
 	})
 
 	t.Run("Query error", func(t *testing.T) {
-		mock.ExpectQuery("SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at FROM bikes WHERE is_available = 1 LIMIT \\? OFFSET \\?").
+		mock.ExpectQuery("SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at, price_plan_id, station_id, type, battery_level FROM bikes WHERE is_available = 1 LIMIT \\? OFFSET \\?").
 			WithArgs(10, 0).
 			WillReturnError(fmt.Errorf("database error"))
 
-		bikes, err := repo.GetAvailable(1, 10)
+		bikes, err := repo.GetAvailable(t.Context(), models.BikeFilter{}, 1, 10)
 
 		assert.Error(t, err)
 		assert.Nil(t, bikes)
@@ -125,14 +146,14 @@ func TestBikeRepository_GetAvailable(t *testing.T) {
 	})
 
 	t.Run("Scan error", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"id", "is_available", "latitude", "longitude", "price_per_minute", "created_at", "updated_at"}).
-			AddRow(1, "invalid", 40.7128, -74.0060, 0.5, now, now)
+		rows := sqlmock.NewRows([]string{"id", "is_available", "latitude", "longitude", "price_per_minute", "created_at", "updated_at", "price_plan_id", "station_id", "type", "battery_level"}).
+			AddRow(1, "invalid", 40.7128, -74.0060, 0.5, now, now, nil, nil, "classic", nil)
 
-		mock.ExpectQuery("SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at FROM bikes WHERE is_available = 1 LIMIT \\? OFFSET \\?").
+		mock.ExpectQuery("SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at, price_plan_id, station_id, type, battery_level FROM bikes WHERE is_available = 1 LIMIT \\? OFFSET \\?").
 			WithArgs(10, 0).
 			WillReturnRows(rows)
 
-		bikes, err := repo.GetAvailable(1, 10)
+		bikes, err := repo.GetAvailable(t.Context(), models.BikeFilter{}, 1, 10)
 
 		assert.Error(t, err)
 		assert.Nil(t, bikes)
@@ -141,6 +162,44 @@ func TestBikeRepository_GetAvailable(t *testing.T) {
 	})
 }
 
+func TestBikeRepository_GetAvailableInBounds(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBikeRepository(db)
+	now := time.Now()
+
+	t.Run("Successfully get bikes inside the box", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "is_available", "latitude", "longitude", "price_per_minute", "created_at", "updated_at", "price_plan_id", "station_id", "type", "battery_level"}).
+			AddRow(1, 1, 51.5080, -0.1280, 0.5, now, now, nil, nil, "classic", nil)
+
+		mock.ExpectQuery("SELECT (.+) FROM bikes WHERE is_available = 1 AND latitude BETWEEN \\? AND \\? AND longitude BETWEEN \\? AND \\?").
+			WithArgs(51.5, 51.6, -0.2, -0.1).
+			WillReturnRows(rows)
+
+		bikes, err := repo.GetAvailableInBounds(t.Context(), models.BikeFilter{}, 51.5, 51.6, -0.2, -0.1)
+
+		assert.NoError(t, err)
+		assert.Len(t, bikes, 1)
+		assert.Equal(t, 1, bikes[0].ID)
+		assert.True(t, bikes[0].IsAvailable)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Query error", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM bikes WHERE is_available = 1 AND latitude BETWEEN").
+			WillReturnError(fmt.Errorf("database error"))
+
+		bikes, err := repo.GetAvailableInBounds(t.Context(), models.BikeFilter{}, 51.5, 51.6, -0.2, -0.1)
+
+		assert.Error(t, err)
+		assert.Nil(t, bikes)
+		assert.Contains(t, err.Error(), "error querying nearby bikes")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestBikeRepository_GetByID(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -150,12 +209,12 @@ func TestBikeRepository_GetByID(t *testing.T) {
 	now := time.Now()
 
 	t.Run("Bike found - available", func(t *testing.T) {
-		mock.ExpectQuery("SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at FROM bikes WHERE id = ?").
+		mock.ExpectQuery("SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at, price_plan_id, station_id, type, battery_level FROM bikes WHERE id = ?").
 			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "is_available", "latitude", "longitude", "price_per_minute", "created_at", "updated_at"}).
-				AddRow(1, 1, 40.7128, -74.0060, 0.5, now, now))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "is_available", "latitude", "longitude", "price_per_minute", "created_at", "updated_at", "price_plan_id", "station_id", "type", "battery_level"}).
+				AddRow(1, 1, 40.7128, -74.0060, 0.5, now, now, nil, nil, "classic", nil))
 
-		bike, err := repo.GetByID(1)
+		bike, err := repo.GetByID(t.Context(), 1)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, bike)
@@ -168,12 +227,12 @@ func TestBikeRepository_GetByID(t *testing.T) {
 	})
 
 	t.Run("Bike found - not available", func(t *testing.T) {
-		mock.ExpectQuery("SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at FROM bikes WHERE id = ?").
+		mock.ExpectQuery("SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at, price_plan_id, station_id, type, battery_level FROM bikes WHERE id = ?").
 			WithArgs(2).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "is_available", "latitude", "longitude", "price_per_minute", "created_at", "updated_at"}).
-				AddRow(2, 0, 40.7138, -74.0070, 0.6, now, now))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "is_available", "latitude", "longitude", "price_per_minute", "created_at", "updated_at", "price_plan_id", "station_id", "type", "battery_level"}).
+				AddRow(2, 0, 40.7138, -74.0070, 0.6, now, now, nil, nil, "classic", nil))
 
-		bike, err := repo.GetByID(2)
+		bike, err := repo.GetByID(t.Context(), 2)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, bike)
@@ -183,24 +242,25 @@ func TestBikeRepository_GetByID(t *testing.T) {
 	})
 
 	t.Run("Bike not found", func(t *testing.T) {
-		mock.ExpectQuery("SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at FROM bikes WHERE id = ?").
+		mock.ExpectQuery("SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at, price_plan_id, station_id, type, battery_level FROM bikes WHERE id = ?").
 			WithArgs(999).
 			WillReturnError(sql.ErrNoRows)
 
-		bike, err := repo.GetByID(999)
+		bike, err := repo.GetByID(t.Context(), 999)
 
 		assert.Error(t, err)
 		assert.Nil(t, bike)
 		assert.Contains(t, err.Error(), "not found")
+		assert.ErrorIs(t, err, constants.ErrBikeNotFound)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	t.Run("Database error", func(t *testing.T) {
-		mock.ExpectQuery("SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at FROM bikes WHERE id = ?").
+		mock.ExpectQuery("SELECT id, is_available, latitude, longitude, price_per_minute, created_at, updated_at, price_plan_id, station_id, type, battery_level FROM bikes WHERE id = ?").
 			WithArgs(1).
 			WillReturnError(fmt.Errorf("database error"))
 
-		bike, err := repo.GetByID(1)
+		bike, err := repo.GetByID(t.Context(), 1)
 
 		assert.Error(t, err)
 		assert.Nil(t, bike)
@@ -209,6 +269,51 @@ func TestBikeRepository_GetByID(t *testing.T) {
 	})
 }
 
+func TestBikeRepository_ClaimAvailable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBikeRepository(db)
+
+	t.Run("Bike claimed", func(t *testing.T) {
+		mock.ExpectExec("UPDATE bikes SET is_available = 0, updated_at = CURRENT_TIMESTAMP WHERE id = \\? AND is_available = 1").
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		claimed, err := repo.ClaimAvailable(t.Context(), 1)
+
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Bike already taken", func(t *testing.T) {
+		mock.ExpectExec("UPDATE bikes SET is_available = 0, updated_at = CURRENT_TIMESTAMP WHERE id = \\? AND is_available = 1").
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		claimed, err := repo.ClaimAvailable(t.Context(), 1)
+
+		assert.NoError(t, err)
+		assert.False(t, claimed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		mock.ExpectExec("UPDATE bikes SET is_available = 0").
+			WithArgs(1).
+			WillReturnError(fmt.Errorf("database error"))
+
+		claimed, err := repo.ClaimAvailable(t.Context(), 1)
+
+		assert.Error(t, err)
+		assert.False(t, claimed)
+		assert.Contains(t, err.Error(), "error claiming bike")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestBikeRepository_UpdateAvailability(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -221,7 +326,7 @@ func TestBikeRepository_UpdateAvailability(t *testing.T) {
 			WithArgs(1, 1).
 			WillReturnResult(sqlmock.NewResult(0, 1))
 
-		err := repo.UpdateAvailability(1, true)
+		err := repo.UpdateAvailability(t.Context(), 1, true)
 
 		assert.NoError(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
@@ -232,7 +337,7 @@ func TestBikeRepository_UpdateAvailability(t *testing.T) {
 			WithArgs(0, 1).
 			WillReturnResult(sqlmock.NewResult(0, 1))
 
-		err := repo.UpdateAvailability(1, false)
+		err := repo.UpdateAvailability(t.Context(), 1, false)
 
 		assert.NoError(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
@@ -243,10 +348,111 @@ func TestBikeRepository_UpdateAvailability(t *testing.T) {
 			WithArgs(1, 1).
 			WillReturnError(fmt.Errorf("database error"))
 
-		err := repo.UpdateAvailability(1, true)
+		err := repo.UpdateAvailability(t.Context(), 1, true)
 
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "error updating bike availability")
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
-}
\ No newline at end of file
+}
+
+func TestBikeRepository_Park(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBikeRepository(db)
+	stationID := 3
+
+	t.Run("Park at station", func(t *testing.T) {
+		mock.ExpectExec("UPDATE bikes SET station_id = \\?, updated_at = CURRENT_TIMESTAMP WHERE id = \\?").
+			WithArgs(3, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.Park(t.Context(), 1, &stationID)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		mock.ExpectExec("UPDATE bikes SET station_id = \\?").
+			WillReturnError(fmt.Errorf("database error"))
+
+		err := repo.Park(t.Context(), 1, nil)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "error updating bike station")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBikeRepository_ParkAt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBikeRepository(db)
+	stationID := 3
+
+	t.Run("Park at station", func(t *testing.T) {
+		mock.ExpectExec("UPDATE stations SET updated_at = updated_at WHERE id = \\?").
+			WithArgs(3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE bikes SET station_id = \\?, latitude = \\?, longitude = \\?, updated_at = CURRENT_TIMESTAMP (.+) < \\(SELECT capacity FROM stations WHERE id = \\?\\)").
+			WithArgs(3, 40.42, -3.7, 1, 3, 1, 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.ParkAt(t.Context(), 1, &stationID, 40.42, -3.7)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Station full", func(t *testing.T) {
+		mock.ExpectExec("UPDATE stations SET updated_at").
+			WithArgs(3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE bikes SET station_id = \\?").
+			WithArgs(3, 40.42, -3.7, 1, 3, 1, 3).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.ParkAt(t.Context(), 1, &stationID, 40.42, -3.7)
+
+		assert.Equal(t, constants.ErrStationFull, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Station lock error", func(t *testing.T) {
+		mock.ExpectExec("UPDATE stations SET updated_at").
+			WillReturnError(fmt.Errorf("database error"))
+
+		err := repo.ParkAt(t.Context(), 1, &stationID, 40.42, -3.7)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "error locking station")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Park free-floating", func(t *testing.T) {
+		mock.ExpectExec("UPDATE bikes SET station_id = NULL, latitude = \\?, longitude = \\?, updated_at = CURRENT_TIMESTAMP WHERE id = \\?").
+			WithArgs(40.42, -3.7, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.ParkAt(t.Context(), 1, nil, 40.42, -3.7)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		mock.ExpectExec("UPDATE bikes SET station_id = NULL, latitude = \\?").
+			WillReturnError(fmt.Errorf("database error"))
+
+		err := repo.ParkAt(t.Context(), 1, nil, 40.42, -3.7)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "error updating bike location")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+)
+
+type BikeTypePriceRepository struct {
+	db DBTX
+}
+
+func NewBikeTypePriceRepository(db DBTX) *BikeTypePriceRepository {
+	return &BikeTypePriceRepository{db: db}
+}
+
+func (r *BikeTypePriceRepository) GetAll(ctx context.Context) ([]*models.BikeTypePrice, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, "SELECT type, price_per_minute, updated_at FROM bike_type_prices ORDER BY type")
+	if err != nil {
+		return nil, fmt.Errorf("error querying bike type prices: %w", err)
+	}
+	defer rows.Close()
+
+	prices := []*models.BikeTypePrice{}
+	for rows.Next() {
+		var price models.BikeTypePrice
+		if err := rows.Scan(&price.Type, &price.PricePerMinute, &price.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning bike type price: %w", err)
+		}
+		prices = append(prices, &price)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating bike type prices: %w", err)
+	}
+
+	return prices, nil
+}
+
+// GetByType returns the default price of a bike type, or nil if it has none.
+func (r *BikeTypePriceRepository) GetByType(ctx context.Context, bikeType models.BikeType) (*models.BikeTypePrice, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	var price models.BikeTypePrice
+	err := r.db.QueryRowContext(
+		ctx,
+		"SELECT type, price_per_minute, updated_at FROM bike_type_prices WHERE type = ?",
+		string(bikeType),
+	).Scan(&price.Type, &price.PricePerMinute, &price.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding bike type price: %w", err)
+	}
+
+	return &price, nil
+}
+
+// Save sets the default price of a bike type and returns it as stored.
+func (r *BikeTypePriceRepository) Save(ctx context.Context, bikeType models.BikeType, pricePerMinute float64) (*models.BikeTypePrice, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	_, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO bike_type_prices (type, price_per_minute, updated_at) VALUES (?, ?, CURRENT_TIMESTAMP) 
+		ON CONFLICT (type) DO UPDATE SET price_per_minute = excluded.price_per_minute, updated_at = excluded.updated_at`,
+		string(bikeType), pricePerMinute,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error saving bike type price: %w", err)
+	}
+
+	return r.GetByType(ctx, bikeType)
+}
@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBikeTypePriceRepository_GetAll(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBikeTypePriceRepository(db)
+	now := time.Now()
+
+	t.Run("Returns every price", func(t *testing.T) {
+		mock.ExpectQuery("SELECT type, price_per_minute, updated_at FROM bike_type_prices ORDER BY type").
+			WillReturnRows(sqlmock.NewRows([]string{"type", "price_per_minute", "updated_at"}).
+				AddRow("cargo", 0.9, now).
+				AddRow("classic", 0.5, now))
+
+		prices, err := repo.GetAll(t.Context())
+
+		assert.NoError(t, err)
+		assert.Len(t, prices, 2)
+		assert.Equal(t, models.BikeTypeCargo, prices[0].Type)
+		assert.Equal(t, 0.9, prices[0].PricePerMinute)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		mock.ExpectQuery("SELECT type, price_per_minute, updated_at FROM bike_type_prices").
+			WillReturnError(errors.New("database error"))
+
+		prices, err := repo.GetAll(t.Context())
+
+		assert.Error(t, err)
+		assert.Nil(t, prices)
+		assert.Contains(t, err.Error(), "error querying bike type prices")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBikeTypePriceRepository_GetByType(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBikeTypePriceRepository(db)
+
+	t.Run("Returns the stored price", func(t *testing.T) {
+		mock.ExpectQuery("SELECT type, price_per_minute, updated_at FROM bike_type_prices WHERE type = ?").
+			WithArgs("ebike").
+			WillReturnRows(sqlmock.NewRows([]string{"type", "price_per_minute", "updated_at"}).AddRow("ebike", 0.75, time.Now()))
+
+		price, err := repo.GetByType(t.Context(), models.BikeTypeEBike)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0.75, price.PricePerMinute)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Returns nil when the type has no price", func(t *testing.T) {
+		mock.ExpectQuery("SELECT type, price_per_minute, updated_at FROM bike_type_prices WHERE type = ?").
+			WithArgs("cargo").
+			WillReturnRows(sqlmock.NewRows([]string{"type", "price_per_minute", "updated_at"}))
+
+		price, err := repo.GetByType(t.Context(), models.BikeTypeCargo)
+
+		assert.NoError(t, err)
+		assert.Nil(t, price)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBikeTypePriceRepository_Save(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBikeTypePriceRepository(db)
+
+	t.Run("Upserts the price and reads it back", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO bike_type_prices (.+) ON CONFLICT \\(type\\) DO UPDATE").
+			WithArgs("ebike", 0.8).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery("SELECT type, price_per_minute, updated_at FROM bike_type_prices WHERE type = ?").
+			WithArgs("ebike").
+			WillReturnRows(sqlmock.NewRows([]string{"type", "price_per_minute", "updated_at"}).AddRow("ebike", 0.8, time.Now()))
+
+		price, err := repo.Save(t.Context(), models.BikeTypeEBike, 0.8)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.BikeTypeEBike, price.Type)
+		assert.Equal(t, 0.8, price.PricePerMinute)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO bike_type_prices").
+			WillReturnError(errors.New("CHECK constraint failed"))
+
+		price, err := repo.Save(t.Context(), "scooter", 0.8)
+
+		assert.Error(t, err)
+		assert.Nil(t, price)
+		assert.Contains(t, err.Error(), "error saving bike type price")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+)
+
+const outboxColumns = "id, recipient, subject, body, status, attempts, last_error, next_attempt_at, sent_at, created_at, updated_at"
+
+func scanOutboxEmail(row rowScanner) (*models.OutboxEmail, error) {
+	var email models.OutboxEmail
+	var lastError sql.NullString
+	var sentAt sql.NullTime
+
+	err := row.Scan(
+		&email.ID, &email.Recipient, &email.Subject, &email.Body, &email.Status, &email.Attempts,
+		&lastError, &email.NextAttemptAt, &sentAt, &email.CreatedAt, &email.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastError.Valid {
+		email.LastError = &lastError.String
+	}
+	if sentAt.Valid {
+		email.SentAt = &sentAt.Time
+	}
+
+	return &email, nil
+}
+
+type EmailOutboxRepository struct {
+	db DBTX
+}
+
+func NewEmailOutboxRepository(db DBTX) *EmailOutboxRepository {
+	return &EmailOutboxRepository{db: db}
+}
+
+// Enqueue stores an email for delivery from now on.
+func (r *EmailOutboxRepository) Enqueue(ctx context.Context, recipient, subject, body string, now time.Time) error {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	_, err := r.db.ExecContext(
+		ctx,
+		"INSERT INTO email_outbox (recipient, subject, body, status, next_attempt_at) VALUES (?, ?, ?, ?, ?)",
+		recipient, subject, body, models.OutboxStatusPending, now,
+	)
+	if err != nil {
+		return fmt.Errorf("error queueing email: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimDue claims up to limit pending emails whose next attempt is due and
+// returns them oldest first. Claiming moves next_attempt_at to leaseUntil in
+// the same statement, so workers sharing the database never pick up the same
+// email: the outer conditions are re-checked against rows another worker
+// updated first. If the claiming worker dies before recording the outcome,
+// the email becomes due again when the lease ends.
+func (r *EmailOutboxRepository) ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*models.OutboxEmail, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		`UPDATE email_outbox SET next_attempt_at = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE status = ? AND next_attempt_at <= ? AND id IN (
+			SELECT id FROM email_outbox WHERE status = ? AND next_attempt_at <= ? ORDER BY id ASC LIMIT ?
+		)
+		RETURNING `+outboxColumns,
+		leaseUntil, models.OutboxStatusPending, now, models.OutboxStatusPending, now, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error claiming emails: %w", err)
+	}
+	defer rows.Close()
+
+	emails := []*models.OutboxEmail{}
+	for rows.Next() {
+		email, err := scanOutboxEmail(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning email: %w", err)
+		}
+		emails = append(emails, email)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating email outbox: %w", err)
+	}
+
+	// RETURNING does not preserve the subquery's order.
+	sort.Slice(emails, func(i, j int) bool { return emails[i].ID < emails[j].ID })
+
+	return emails, nil
+}
+
+func (r *EmailOutboxRepository) MarkSent(ctx context.Context, emailID, attempts int, sentAt time.Time) error {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	_, err := r.db.ExecContext(
+		ctx,
+		"UPDATE email_outbox SET status = ?, attempts = ?, sent_at = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+		models.OutboxStatusSent, attempts, sentAt, emailID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating email: %w", err)
+	}
+
+	return nil
+}
+
+// MarkAttemptFailed records a failed delivery. The email stays pending until
+// nextAttemptAt, or is moved to failed when status says so.
+func (r *EmailOutboxRepository) MarkAttemptFailed(ctx context.Context, emailID, attempts int, status, lastError string, nextAttemptAt time.Time) error {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	_, err := r.db.ExecContext(
+		ctx,
+		"UPDATE email_outbox SET status = ?, attempts = ?, last_error = ?, next_attempt_at = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+		status, attempts, lastError, nextAttemptAt, emailID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating email: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var outboxRowColumns = []string{"id", "recipient", "subject", "body", "status", "attempts", "last_error", "next_attempt_at", "sent_at", "created_at", "updated_at"}
+
+func TestEmailOutboxRepository_Enqueue(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewEmailOutboxRepository(db)
+	now := time.Now()
+
+	mock.ExpectExec("INSERT INTO email_outbox").
+		WithArgs("rider@example.com", "Subject", "Body", "pending", now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	assert.NoError(t, repo.Enqueue(t.Context(), "rider@example.com", "Subject", "Body", now))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEmailOutboxRepository_ClaimDue(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewEmailOutboxRepository(db)
+	now := time.Now()
+	leaseUntil := now.Add(10 * time.Minute)
+
+	t.Run("Claims due emails oldest first", func(t *testing.T) {
+		mock.ExpectQuery("UPDATE email_outbox SET next_attempt_at = \\?, updated_at = CURRENT_TIMESTAMP\\s+WHERE status = \\? AND next_attempt_at <= \\? AND id IN \\(\\s+SELECT id FROM email_outbox WHERE status = \\? AND next_attempt_at <= \\? ORDER BY id ASC LIMIT \\?\\s+\\)\\s+RETURNING (.+)").
+			WithArgs(leaseUntil, "pending", now, "pending", now, 50).
+			WillReturnRows(sqlmock.NewRows(outboxRowColumns).
+				AddRow(2, "other@example.com", "Subject", "Body", "pending", 2, "timeout", leaseUntil, nil, now, now).
+				AddRow(1, "rider@example.com", "Subject", "Body", "pending", 0, nil, leaseUntil, nil, now, now))
+
+		emails, err := repo.ClaimDue(t.Context(), now, leaseUntil, 50)
+
+		assert.NoError(t, err)
+		if assert.Len(t, emails, 2) {
+			assert.Equal(t, 1, emails[0].ID)
+			assert.Nil(t, emails[0].LastError)
+			assert.Equal(t, "timeout", *emails[1].LastError)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		mock.ExpectQuery("UPDATE email_outbox").
+			WillReturnError(errors.New("database error"))
+
+		emails, err := repo.ClaimDue(t.Context(), now, leaseUntil, 50)
+
+		assert.Nil(t, emails)
+		assert.Contains(t, err.Error(), "error claiming emails")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestEmailOutboxRepository_MarkDelivery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewEmailOutboxRepository(db)
+	now := time.Now()
+	retryAt := now.Add(time.Minute)
+
+	mock.ExpectExec("UPDATE email_outbox SET status = \\?, attempts = \\?, sent_at = \\?").
+		WithArgs("sent", 1, now, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE email_outbox SET status = \\?, attempts = \\?, last_error = \\?, next_attempt_at = \\?").
+		WithArgs("pending", 2, "timeout", retryAt, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.MarkSent(t.Context(), 1, 1, now))
+	assert.NoError(t, repo.MarkAttemptFailed(t.Context(), 2, 2, "pending", "timeout", retryAt))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
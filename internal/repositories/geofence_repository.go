@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+)
+
+const geofenceColumns = "id, kind, name, rings, speed_limit_kmh, min_latitude, max_latitude, min_longitude, max_longitude, created_at"
+
+func scanGeofence(row rowScanner) (*models.Geofence, error) {
+	var geofence models.Geofence
+	var rings string
+	var speedLimit sql.NullFloat64
+
+	err := row.Scan(
+		&geofence.ID, &geofence.Kind, &geofence.Name, &rings, &speedLimit,
+		&geofence.MinLatitude, &geofence.MaxLatitude, &geofence.MinLongitude, &geofence.MaxLongitude, &geofence.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(rings), &geofence.Rings); err != nil {
+		return nil, fmt.Errorf("error decoding geofence rings: %w", err)
+	}
+	if speedLimit.Valid {
+		limit := speedLimit.Float64
+		geofence.SpeedLimitKmh = &limit
+	}
+
+	return &geofence, nil
+}
+
+type GeofenceRepository struct {
+	db DBTX
+}
+
+func NewGeofenceRepository(db DBTX) *GeofenceRepository {
+	return &GeofenceRepository{db: db}
+}
+
+func (r *GeofenceRepository) Create(ctx context.Context, geofence *models.Geofence) error {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	rings, err := json.Marshal(geofence.Rings)
+	if err != nil {
+		return fmt.Errorf("error encoding geofence rings: %w", err)
+	}
+
+	geofence.ID, err = insertReturningID(
+		ctx,
+		r.db,
+		`INSERT INTO geofences (kind, name, rings, speed_limit_kmh, min_latitude, max_latitude, min_longitude, max_longitude) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		geofence.Kind, geofence.Name, string(rings), geofence.SpeedLimitKmh,
+		geofence.MinLatitude, geofence.MaxLatitude, geofence.MinLongitude, geofence.MaxLongitude,
+	)
+	if err != nil {
+		return fmt.Errorf("error creating geofence: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteByKind removes every geofence of kind, so an upload can replace them.
+func (r *GeofenceRepository) DeleteByKind(ctx context.Context, kind models.GeofenceKind) error {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM geofences WHERE kind = ?", kind); err != nil {
+		return fmt.Errorf("error deleting geofences: %w", err)
+	}
+	return nil
+}
+
+// GetAll returns the geofences of kind, or of every kind when kind is empty.
+func (r *GeofenceRepository) GetAll(ctx context.Context, kind models.GeofenceKind) ([]*models.Geofence, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	query := "SELECT " + geofenceColumns + " FROM geofences"
+	args := []interface{}{}
+	if kind != "" {
+		query += " WHERE kind = ?"
+		args = append(args, kind)
+	}
+	query += " ORDER BY kind ASC, id ASC"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying geofences: %w", err)
+	}
+
+	return scanGeofences(rows)
+}
+
+// GetByBoundsContaining returns the geofences whose bounding box contains
+// the point. It is a prefilter; callers test the polygons themselves.
+func (r *GeofenceRepository) GetByBoundsContaining(ctx context.Context, latitude, longitude float64) ([]*models.Geofence, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT `+geofenceColumns+` FROM geofences 
+		WHERE min_latitude <= ? AND max_latitude >= ? AND min_longitude <= ? AND max_longitude >= ? ORDER BY id ASC`,
+		latitude, latitude, longitude, longitude,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying geofences: %w", err)
+	}
+
+	return scanGeofences(rows)
+}
+
+func (r *GeofenceRepository) CountByKind(ctx context.Context, kind models.GeofenceKind) (int, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	var count int
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM geofences WHERE kind = ?", kind).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error counting geofences: %w", err)
+	}
+	return count, nil
+}
+
+func scanGeofences(rows *sql.Rows) ([]*models.Geofence, error) {
+	defer rows.Close()
+
+	geofences := []*models.Geofence{}
+	for rows.Next() {
+		geofence, err := scanGeofence(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning geofence: %w", err)
+		}
+		geofences = append(geofences, geofence)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating geofences: %w", err)
+	}
+
+	return geofences, nil
+}
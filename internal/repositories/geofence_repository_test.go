@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var geofenceRowColumns = []string{"id", "kind", "name", "rings", "speed_limit_kmh", "min_latitude", "max_latitude", "min_longitude", "max_longitude", "created_at"}
+
+const geofenceRings = `[[{"latitude":40.42,"longitude":-3.71},{"latitude":40.42,"longitude":-3.7},{"latitude":40.43,"longitude":-3.7},{"latitude":40.42,"longitude":-3.71}]]`
+
+func TestGeofenceRepository_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewGeofenceRepository(db)
+	var rings [][]models.GeoPoint
+	assert.NoError(t, json.Unmarshal([]byte(geofenceRings), &rings))
+
+	t.Run("Successfully create geofence", func(t *testing.T) {
+		mock.ExpectQuery("INSERT INTO geofences (.+) RETURNING id").
+			WithArgs(models.GeofenceNoParking, "Centro", geofenceRings, nil, 40.42, 40.43, -3.71, -3.7).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+
+		geofence := &models.Geofence{Kind: models.GeofenceNoParking, Name: "Centro", Rings: rings}
+		geofence.SetBounds()
+		err := repo.Create(t.Context(), geofence)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 4, geofence.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Insert error", func(t *testing.T) {
+		mock.ExpectQuery("INSERT INTO geofences (.+) RETURNING id").
+			WillReturnError(errors.New("CHECK constraint failed"))
+
+		err := repo.Create(t.Context(), &models.Geofence{Kind: "unknown", Rings: rings})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "error creating geofence")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGeofenceRepository_GetAll(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewGeofenceRepository(db)
+	now := time.Now()
+
+	t.Run("Filter by kind", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM geofences WHERE kind = \\? ORDER BY kind ASC, id ASC").
+			WithArgs(models.GeofenceSlow).
+			WillReturnRows(sqlmock.NewRows(geofenceRowColumns).
+				AddRow(1, "slow", "Centro", geofenceRings, 10.0, 40.42, 40.43, -3.71, -3.7, now))
+
+		geofences, err := repo.GetAll(t.Context(), models.GeofenceSlow)
+
+		assert.NoError(t, err)
+		assert.Len(t, geofences, 1)
+		assert.Equal(t, models.GeofenceSlow, geofences[0].Kind)
+		assert.Equal(t, 10.0, *geofences[0].SpeedLimitKmh)
+		assert.Len(t, geofences[0].Rings[0], 4)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("All kinds", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM geofences ORDER BY kind ASC, id ASC").
+			WillReturnRows(sqlmock.NewRows(geofenceRowColumns))
+
+		geofences, err := repo.GetAll(t.Context(), "")
+
+		assert.NoError(t, err)
+		assert.Empty(t, geofences)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGeofenceRepository_GetByBoundsContaining(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewGeofenceRepository(db)
+
+	mock.ExpectQuery("SELECT (.+) FROM geofences WHERE min_latitude <= \\? AND max_latitude >= \\? AND min_longitude <= \\? AND max_longitude >= \\?").
+		WithArgs(40.425, 40.425, -3.705, -3.705).
+		WillReturnError(errors.New("query error"))
+
+	geofences, err := repo.GetByBoundsContaining(t.Context(), 40.425, -3.705)
+
+	assert.Error(t, err)
+	assert.Nil(t, geofences)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGeofenceRepository_DeleteByKind(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewGeofenceRepository(db)
+
+	mock.ExpectExec("DELETE FROM geofences WHERE kind = \\?").
+		WithArgs(models.GeofenceNoParking).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	assert.NoError(t, repo.DeleteByKind(t.Context(), models.GeofenceNoParking))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
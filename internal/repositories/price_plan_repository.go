@@ -0,0 +1,308 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+)
+
+const pricePlanColumns = `id, name, unlock_fee, price_per_minute, free_minutes, daily_cap, night_multiplier, 
+		night_start_hour, night_end_hour, weekend_multiplier, timezone, created_at, updated_at, paused_price_per_minute`
+
+func scanPricePlan(row rowScanner) (*models.PricePlan, error) {
+	var plan models.PricePlan
+	var pricePerMinute, dailyCap, pausedPricePerMinute sql.NullFloat64
+
+	err := row.Scan(
+		&plan.ID, &plan.Name, &plan.UnlockFee, &pricePerMinute, &plan.FreeMinutes, &dailyCap,
+		&plan.NightMultiplier, &plan.NightStartHour, &plan.NightEndHour, &plan.WeekendMultiplier,
+		&plan.Timezone, &plan.CreatedAt, &plan.UpdatedAt, &pausedPricePerMinute,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if pricePerMinute.Valid {
+		p := pricePerMinute.Float64
+		plan.PricePerMinute = &p
+	}
+	if dailyCap.Valid {
+		c := dailyCap.Float64
+		plan.DailyCap = &c
+	}
+	if pausedPricePerMinute.Valid {
+		p := pausedPricePerMinute.Float64
+		plan.PausedPricePerMinute = &p
+	}
+
+	return &plan, nil
+}
+
+// zoneRateColumns reads a zone rate with its station's name and area.
+const zoneRateColumns = `z.id, z.price_plan_id, z.station_id, z.apply_to, z.fee, z.multiplier, 
+		s.name, s.latitude, s.longitude, s.radius_meters, s.polygon`
+
+func scanZoneRate(row rowScanner) (planID int, rate models.ZoneRate, err error) {
+	station := &models.Station{}
+	var radiusMeters sql.NullFloat64
+	var polygon sql.NullString
+
+	err = row.Scan(
+		&rate.ID, &planID, &rate.StationID, &rate.ApplyTo, &rate.Fee, &rate.Multiplier,
+		&station.Name, &station.Latitude, &station.Longitude, &radiusMeters, &polygon,
+	)
+	if err != nil {
+		return 0, rate, err
+	}
+
+	station.ID = rate.StationID
+	if radiusMeters.Valid {
+		r := radiusMeters.Float64
+		station.RadiusMeters = &r
+	}
+	if polygon.Valid && polygon.String != "" {
+		if err := json.Unmarshal([]byte(polygon.String), &station.Polygon); err != nil {
+			return 0, rate, fmt.Errorf("error decoding station polygon: %w", err)
+		}
+	}
+	rate.Station = station
+
+	return planID, rate, nil
+}
+
+type PricePlanRepository struct {
+	db DBTX
+}
+
+func NewPricePlanRepository(db DBTX) *PricePlanRepository {
+	return &PricePlanRepository{db: db}
+}
+
+func (r *PricePlanRepository) Create(ctx context.Context, plan *models.PricePlan) (*models.PricePlan, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	planID, err := insertReturningID(
+		ctx,
+		r.db,
+		`INSERT INTO price_plans (name, unlock_fee, price_per_minute, free_minutes, daily_cap, night_multiplier, 
+		night_start_hour, night_end_hour, weekend_multiplier, timezone, paused_price_per_minute) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		plan.Name, plan.UnlockFee, plan.PricePerMinute, plan.FreeMinutes, plan.DailyCap, plan.NightMultiplier,
+		plan.NightStartHour, plan.NightEndHour, plan.WeekendMultiplier, plan.Timezone, plan.PausedPricePerMinute,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating price plan: %w", err)
+	}
+
+	return r.GetByID(ctx, planID)
+}
+
+func (r *PricePlanRepository) GetByID(ctx context.Context, planID int) (*models.PricePlan, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	plan, err := scanPricePlan(r.db.QueryRowContext(
+		ctx,
+		`SELECT `+pricePlanColumns+` 
+		FROM price_plans WHERE id = ?`,
+		planID,
+	))
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("price plan with id %d: %w", planID, constants.ErrPricePlanNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding price plan: %w", err)
+	}
+
+	if err := r.loadZoneRates(ctx, plan); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+func (r *PricePlanRepository) CountAll(ctx context.Context) (int, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	var count int
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM price_plans").Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error counting price plans: %w", err)
+	}
+	return count, nil
+}
+
+func (r *PricePlanRepository) GetAll(ctx context.Context, page, limit int) ([]*models.PricePlan, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	offset := (page - 1) * limit
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT `+pricePlanColumns+` 
+		FROM price_plans ORDER BY id ASC LIMIT ? OFFSET ?`,
+		limit, offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying price plans: %w", err)
+	}
+	defer rows.Close()
+
+	plans := []*models.PricePlan{}
+	for rows.Next() {
+		plan, err := scanPricePlan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning price plan: %w", err)
+		}
+		plans = append(plans, plan)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating price plans: %w", err)
+	}
+
+	if err := r.loadZoneRates(ctx, plans...); err != nil {
+		return nil, err
+	}
+
+	return plans, nil
+}
+
+// loadZoneRates fills in the zone rates of plans with a single query.
+func (r *PricePlanRepository) loadZoneRates(ctx context.Context, plans ...*models.PricePlan) error {
+	if len(plans) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*models.PricePlan, len(plans))
+	args := make([]interface{}, 0, len(plans))
+	for _, plan := range plans {
+		plan.ZoneRates = []models.ZoneRate{}
+		byID[plan.ID] = plan
+		args = append(args, plan.ID)
+	}
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT `+zoneRateColumns+` 
+		FROM price_plan_zone_rates z JOIN stations s ON s.id = z.station_id 
+		WHERE z.price_plan_id IN (`+strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")+`) ORDER BY z.id ASC`,
+		args...,
+	)
+	if err != nil {
+		return fmt.Errorf("error querying zone rates: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		planID, rate, err := scanZoneRate(rows)
+		if err != nil {
+			return fmt.Errorf("error scanning zone rate: %w", err)
+		}
+		byID[planID].ZoneRates = append(byID[planID].ZoneRates, rate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating zone rates: %w", err)
+	}
+
+	return nil
+}
+
+// ReplaceZoneRates swaps the plan's zone rates for rates. Run it in the same
+// transaction as the plan write so readers never see a partial set.
+func (r *PricePlanRepository) ReplaceZoneRates(ctx context.Context, planID int, rates []models.ZoneRate) error {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM price_plan_zone_rates WHERE price_plan_id = ?", planID); err != nil {
+		return fmt.Errorf("error deleting zone rates: %w", err)
+	}
+
+	for _, rate := range rates {
+		_, err := r.db.ExecContext(
+			ctx,
+			"INSERT INTO price_plan_zone_rates (price_plan_id, station_id, apply_to, fee, multiplier) VALUES (?, ?, ?, ?, ?)",
+			planID, rate.StationID, rate.ApplyTo, rate.Fee, rate.Multiplier,
+		)
+		if isUniqueViolation(err) {
+			return constants.ErrDuplicateZoneRate
+		}
+		if err != nil {
+			return fmt.Errorf("error creating zone rate: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Update writes the full plan. Callers merge partial updates onto the stored
+// plan first so nullable fields can be cleared explicitly.
+func (r *PricePlanRepository) Update(ctx context.Context, plan *models.PricePlan) (*models.PricePlan, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	result, err := r.db.ExecContext(
+		ctx,
+		`UPDATE price_plans SET name = ?, unlock_fee = ?, price_per_minute = ?, free_minutes = ?, daily_cap = ?, 
+		night_multiplier = ?, night_start_hour = ?, night_end_hour = ?, weekend_multiplier = ?, timezone = ?, 
+		paused_price_per_minute = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		plan.Name, plan.UnlockFee, plan.PricePerMinute, plan.FreeMinutes, plan.DailyCap, plan.NightMultiplier,
+		plan.NightStartHour, plan.NightEndHour, plan.WeekendMultiplier, plan.Timezone, plan.PausedPricePerMinute, plan.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error updating price plan: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("error updating price plan: %w", err)
+	}
+	if affected == 0 {
+		return nil, fmt.Errorf("price plan with id %d: %w", plan.ID, constants.ErrPricePlanNotFound)
+	}
+
+	return r.GetByID(ctx, plan.ID)
+}
+
+// Delete removes a plan that no bike references, along with its zone rates.
+func (r *PricePlanRepository) Delete(ctx context.Context, planID int) error {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	var inUse bool
+	err := r.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM bikes WHERE price_plan_id = ?)", planID).Scan(&inUse)
+	if err != nil {
+		return fmt.Errorf("error checking price plan usage: %w", err)
+	}
+	if inUse {
+		return constants.ErrPricePlanInUse
+	}
+
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM price_plan_zone_rates WHERE price_plan_id = ?", planID); err != nil {
+		return fmt.Errorf("error deleting zone rates: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM price_plans WHERE id = ?", planID)
+	if err != nil {
+		return fmt.Errorf("error deleting price plan: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error deleting price plan: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("price plan with id %d: %w", planID, constants.ErrPricePlanNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,236 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var pricePlanRowColumns = []string{"id", "name", "unlock_fee", "price_per_minute", "free_minutes", "daily_cap", "night_multiplier", "night_start_hour", "night_end_hour", "weekend_multiplier", "timezone", "created_at", "updated_at", "paused_price_per_minute"}
+
+var zoneRateRowColumns = []string{"id", "price_plan_id", "station_id", "apply_to", "fee", "multiplier", "name", "latitude", "longitude", "radius_meters", "polygon"}
+
+func TestPricePlanRepository_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPricePlanRepository(db)
+	now := time.Now()
+	dailyCap := 15.0
+
+	t.Run("Successfully create price plan", func(t *testing.T) {
+		mock.ExpectQuery("INSERT INTO price_plans (.+) RETURNING id").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+		mock.ExpectQuery("SELECT id, name, unlock_fee").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows(pricePlanRowColumns).
+				AddRow(1, "Standard", 1.0, nil, 10, 15.0, 1.5, 22, 6, 1.0, "Europe/London", now, now, nil))
+		mock.ExpectQuery("SELECT (.+) FROM price_plan_zone_rates").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows(zoneRateRowColumns))
+
+		plan, err := repo.Create(t.Context(), &models.PricePlan{
+			Name: "Standard", UnlockFee: 1.0, FreeMinutes: 10, DailyCap: &dailyCap,
+			NightMultiplier: 1.5, NightStartHour: 22, NightEndHour: 6, WeekendMultiplier: 1.0, Timezone: "Europe/London",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, plan.ID)
+		assert.Nil(t, plan.PricePerMinute)
+		assert.NotNil(t, plan.DailyCap)
+		assert.Equal(t, 15.0, *plan.DailyCap)
+		assert.NotNil(t, plan.ZoneRates)
+		assert.Empty(t, plan.ZoneRates)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Insert error", func(t *testing.T) {
+		mock.ExpectQuery("INSERT INTO price_plans (.+) RETURNING id").
+			WillReturnError(errors.New("UNIQUE constraint failed: price_plans.name"))
+
+		plan, err := repo.Create(t.Context(), &models.PricePlan{Name: "Standard"})
+
+		assert.Error(t, err)
+		assert.Nil(t, plan)
+		assert.Contains(t, err.Error(), "error creating price plan")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPricePlanRepository_GetByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPricePlanRepository(db)
+
+	t.Run("Price plan not found", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, name, unlock_fee").
+			WithArgs(99).
+			WillReturnError(sql.ErrNoRows)
+
+		plan, err := repo.GetByID(t.Context(), 99)
+
+		assert.ErrorIs(t, err, constants.ErrPricePlanNotFound)
+		assert.Nil(t, plan)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPricePlanRepository_GetAll(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPricePlanRepository(db)
+	now := time.Now()
+
+	t.Run("Successfully get price plans", func(t *testing.T) {
+		rows := sqlmock.NewRows(pricePlanRowColumns).
+			AddRow(1, "Standard", 1.0, nil, 0, nil, 1.0, 0, 0, 1.0, "UTC", now, now, nil).
+			AddRow(2, "Commuter", 0.0, 0.2, 0, 10.0, 1.0, 0, 0, 1.0, "UTC", now, now, nil)
+
+		mock.ExpectQuery("SELECT (.+) FROM price_plans ORDER BY id ASC LIMIT \\? OFFSET \\?").
+			WithArgs(10, 0).
+			WillReturnRows(rows)
+		mock.ExpectQuery("SELECT (.+) FROM price_plan_zone_rates (.+) IN \\(\\?, \\?\\)").
+			WithArgs(1, 2).
+			WillReturnRows(sqlmock.NewRows(zoneRateRowColumns).
+				AddRow(7, 2, 3, "end", 0.5, 1.2, "Sol", 40.4168, -3.7038, 150.0, nil))
+
+		plans, err := repo.GetAll(t.Context(), 1, 10)
+
+		assert.NoError(t, err)
+		assert.Len(t, plans, 2)
+		assert.Equal(t, 0.2, *plans[1].PricePerMinute)
+		assert.Empty(t, plans[0].ZoneRates)
+		assert.Len(t, plans[1].ZoneRates, 1)
+		assert.Equal(t, models.ZoneRateEnd, plans[1].ZoneRates[0].ApplyTo)
+		assert.Equal(t, 1.2, plans[1].ZoneRates[0].Multiplier)
+		assert.Equal(t, "Sol", plans[1].ZoneRates[0].Station.Name)
+		assert.Equal(t, 150.0, *plans[1].ZoneRates[0].Station.RadiusMeters)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPricePlanRepository_Update(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPricePlanRepository(db)
+
+	t.Run("Price plan not found", func(t *testing.T) {
+		mock.ExpectExec("UPDATE price_plans SET").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		plan, err := repo.Update(t.Context(), &models.PricePlan{ID: 99, Name: "Gone", Timezone: "UTC"})
+
+		assert.ErrorIs(t, err, constants.ErrPricePlanNotFound)
+		assert.Nil(t, plan)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPricePlanRepository_Delete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPricePlanRepository(db)
+
+	t.Run("Successfully delete price plan", func(t *testing.T) {
+		mock.ExpectQuery("SELECT EXISTS").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectExec("DELETE FROM price_plan_zone_rates WHERE price_plan_id = ?").
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("DELETE FROM price_plans WHERE id = ?").
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.Delete(t.Context(), 1)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Price plan in use", func(t *testing.T) {
+		mock.ExpectQuery("SELECT EXISTS").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		err := repo.Delete(t.Context(), 1)
+
+		assert.Equal(t, constants.ErrPricePlanInUse, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Price plan not found", func(t *testing.T) {
+		mock.ExpectQuery("SELECT EXISTS").
+			WithArgs(99).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectExec("DELETE FROM price_plan_zone_rates WHERE price_plan_id = ?").
+			WithArgs(99).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("DELETE FROM price_plans WHERE id = ?").
+			WithArgs(99).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.Delete(t.Context(), 99)
+
+		assert.ErrorIs(t, err, constants.ErrPricePlanNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPricePlanRepository_ReplaceZoneRates(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPricePlanRepository(db)
+	rates := []models.ZoneRate{
+		{StationID: 3, ApplyTo: models.ZoneRateStart, Fee: 0.5, Multiplier: 1},
+		{StationID: 3, ApplyTo: models.ZoneRateEnd, Multiplier: 0.8},
+	}
+
+	t.Run("Successfully replace zone rates", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM price_plan_zone_rates WHERE price_plan_id = ?").
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO price_plan_zone_rates").
+			WithArgs(1, 3, models.ZoneRateStart, 0.5, 1.0).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO price_plan_zone_rates").
+			WithArgs(1, 3, models.ZoneRateEnd, 0.0, 0.8).
+			WillReturnResult(sqlmock.NewResult(2, 1))
+
+		err := repo.ReplaceZoneRates(t.Context(), 1, rates)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Duplicate zone rate", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM price_plan_zone_rates WHERE price_plan_id = ?").
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO price_plan_zone_rates").
+			WillReturnError(errors.New("UNIQUE constraint failed: price_plan_zone_rates.price_plan_id"))
+
+		err := repo.ReplaceZoneRates(t.Context(), 1, rates[:1])
+
+		assert.ErrorIs(t, err, constants.ErrDuplicateZoneRate)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
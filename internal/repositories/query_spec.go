@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"strings"
+
+	"github.com/Nimirandad/bike-rental-service/internal/queryspec"
+)
+
+// likeEscaper escapes the LIKE wildcards in a search term so it is matched
+// literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// whereClause turns spec into a WHERE clause and its arguments. Column names
+// come from the queryspec whitelists; every value is bound as a parameter.
+// A nil or empty spec yields an empty clause.
+func whereClause(spec *queryspec.Spec) (string, []interface{}) {
+	if spec == nil {
+		return "", nil
+	}
+
+	conditions := []string{}
+	args := []interface{}{}
+
+	for _, f := range spec.Filters {
+		conditions = append(conditions, f.Column+" "+f.Op.SQL()+" ?")
+		args = append(args, f.Value)
+	}
+
+	if spec.Search != "" {
+		pattern := "%" + likeEscaper.Replace(spec.Search) + "%"
+		matches := make([]string, 0, len(spec.SearchColumns))
+		for _, column := range spec.SearchColumns {
+			matches = append(matches, "LOWER("+column+`) LIKE ? ESCAPE '\'`)
+			args = append(args, pattern)
+		}
+		conditions = append(conditions, "("+strings.Join(matches, " OR ")+")")
+	}
+
+	if c := spec.Cursor; c != nil && c.ID != 0 {
+		// Rows after the cursor have larger ids in ascending order.
+		op := ">"
+		if c.Before != spec.Descending() {
+			op = "<"
+		}
+		conditions = append(conditions, "id "+op+" ?")
+		args = append(args, c.ID)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// orderByClause orders by the spec's sorts, falling back to id ascending.
+// id is unique, so it ends the list: it is appended when missing to keep
+// pages stable when sort values tie, and nothing after it is needed.
+// A cursor walking backwards reads the list in reverse from the cursor.
+func orderByClause(spec *queryspec.Spec) string {
+	if spec != nil && spec.Cursor != nil && spec.Cursor.Before {
+		if spec.Descending() {
+			return " ORDER BY id ASC"
+		}
+		return " ORDER BY id DESC"
+	}
+
+	keys := []string{}
+	if spec != nil {
+		for _, s := range spec.Sorts {
+			keys = append(keys, sortKey(s))
+			if s.Column == "id" {
+				return " ORDER BY " + strings.Join(keys, ", ")
+			}
+		}
+	}
+
+	return " ORDER BY " + strings.Join(append(keys, "id ASC"), ", ")
+}
+
+func sortKey(s queryspec.Sort) string {
+	if s.Desc {
+		return s.Column + " DESC"
+	}
+	return s.Column + " ASC"
+}
+
+// limitClause pages by offset, or fetches the first limit rows from the
+// cursor when the spec uses keyset pagination.
+func limitClause(spec *queryspec.Spec, page, limit int) (string, []interface{}) {
+	if spec != nil && spec.Cursor != nil {
+		return " LIMIT ?", []interface{}{limit}
+	}
+	return " LIMIT ? OFFSET ?", []interface{}{limit, (page - 1) * limit}
+}
@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+)
+
+const refreshTokenColumns = "id, user_id, family_id, token_hash, access_jti, access_expires_at, expires_at, used_at, revoked_at, created_at"
+
+func scanRefreshToken(row rowScanner) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	var usedAt, revokedAt sql.NullTime
+
+	err := row.Scan(
+		&token.ID, &token.UserID, &token.FamilyID, &token.TokenHash, &token.AccessJTI,
+		&token.AccessExpiresAt, &token.ExpiresAt, &usedAt, &revokedAt, &token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+
+	return &token, nil
+}
+
+type RefreshTokenRepository struct {
+	db DBTX
+}
+
+func NewRefreshTokenRepository(db DBTX) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+// Create stores a refresh token by its hash, together with the jti and expiry
+// of the access token issued alongside it.
+func (r *RefreshTokenRepository) Create(ctx context.Context, userID int, familyID, tokenHash, accessJTI string, accessExpiresAt, expiresAt time.Time) (*models.RefreshToken, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	_, err := r.db.ExecContext(
+		ctx,
+		"INSERT INTO refresh_tokens (user_id, family_id, token_hash, access_jti, access_expires_at, expires_at) VALUES (?, ?, ?, ?, ?, ?)",
+		userID, familyID, tokenHash, accessJTI, accessExpiresAt, expiresAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating refresh token: %w", err)
+	}
+
+	return r.GetByHash(ctx, tokenHash)
+}
+
+func (r *RefreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	token, err := scanRefreshToken(r.db.QueryRowContext(
+		ctx,
+		"SELECT "+refreshTokenColumns+" FROM refresh_tokens WHERE token_hash = ?",
+		tokenHash,
+	))
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("refresh token not found: %w", constants.ErrInvalidRefreshToken)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding refresh token: %w", err)
+	}
+
+	return token, nil
+}
+
+// MarkUsed records that a refresh token was rotated. It reports false when the
+// token was already used or revoked, so concurrent refreshes only succeed once.
+func (r *RefreshTokenRepository) MarkUsed(ctx context.Context, tokenID int, usedAt time.Time) (bool, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	result, err := r.db.ExecContext(
+		ctx,
+		"UPDATE refresh_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL AND revoked_at IS NULL",
+		usedAt, tokenID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("error updating refresh token: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error updating refresh token: %w", err)
+	}
+
+	return affected == 1, nil
+}
+
+// RevokeFamily revokes every token in a rotation family that is not already
+// revoked.
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, revokedAt time.Time) error {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	_, err := r.db.ExecContext(
+		ctx,
+		"UPDATE refresh_tokens SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL",
+		revokedAt, familyID,
+	)
+	if err != nil {
+		return fmt.Errorf("error revoking refresh token family: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeAllForUser revokes every refresh token the user holds.
+func (r *RefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID int, revokedAt time.Time) error {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	_, err := r.db.ExecContext(
+		ctx,
+		"UPDATE refresh_tokens SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL",
+		revokedAt, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("error revoking refresh tokens: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpired removes refresh tokens that expired at or before now and
+// returns how many it removed. The access tokens issued with them expired
+// earlier, so no revocation depends on them any more.
+func (r *RefreshTokenRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM refresh_tokens WHERE expires_at <= ?", now)
+	if err != nil {
+		return 0, fmt.Errorf("error deleting expired refresh tokens: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error deleting expired refresh tokens: %w", err)
+	}
+
+	return deleted, nil
+}
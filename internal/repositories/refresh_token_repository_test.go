@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+var refreshTokenRowColumns = []string{"id", "user_id", "family_id", "token_hash", "access_jti", "access_expires_at", "expires_at", "used_at", "revoked_at", "created_at"}
+
+func TestRefreshTokenRepository_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRefreshTokenRepository(db)
+	now := time.Now()
+	accessExpiresAt := now.Add(15 * time.Minute)
+	expiresAt := now.Add(30 * 24 * time.Hour)
+
+	mock.ExpectExec("INSERT INTO refresh_tokens").
+		WithArgs(1, "family", "hash", "jti", accessExpiresAt, expiresAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectQuery("SELECT (.+) FROM refresh_tokens WHERE token_hash = ?").
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows(refreshTokenRowColumns).
+			AddRow(1, 1, "family", "hash", "jti", accessExpiresAt, expiresAt, nil, nil, now))
+
+	token, err := repo.Create(t.Context(), 1, "family", "hash", "jti", accessExpiresAt, expiresAt)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, token.ID)
+	assert.Equal(t, "family", token.FamilyID)
+	assert.Nil(t, token.UsedAt)
+	assert.Nil(t, token.RevokedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRefreshTokenRepository_GetByHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRefreshTokenRepository(db)
+	now := time.Now()
+
+	t.Run("Used token", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM refresh_tokens WHERE token_hash = ?").
+			WithArgs("hash").
+			WillReturnRows(sqlmock.NewRows(refreshTokenRowColumns).
+				AddRow(2, 1, "family", "hash", "jti", now, now, now, nil, now))
+
+		token, err := repo.GetByHash(t.Context(), "hash")
+
+		assert.NoError(t, err)
+		assert.NotNil(t, token.UsedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Unknown token", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM refresh_tokens WHERE token_hash = ?").
+			WithArgs("unknown").
+			WillReturnRows(sqlmock.NewRows(refreshTokenRowColumns))
+
+		token, err := repo.GetByHash(t.Context(), "unknown")
+
+		assert.ErrorIs(t, err, constants.ErrInvalidRefreshToken)
+		assert.Nil(t, token)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRefreshTokenRepository_MarkUsed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRefreshTokenRepository(db)
+	now := time.Now()
+
+	mock.ExpectExec("UPDATE refresh_tokens SET used_at = \\? WHERE id = \\? AND used_at IS NULL AND revoked_at IS NULL").
+		WithArgs(now, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE refresh_tokens SET used_at").
+		WithArgs(now, 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	claimed, err := repo.MarkUsed(t.Context(), 1, now)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = repo.MarkUsed(t.Context(), 1, now)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRefreshTokenRepository_Revoke(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRefreshTokenRepository(db)
+	now := time.Now()
+
+	mock.ExpectExec("UPDATE refresh_tokens SET revoked_at = \\? WHERE family_id = \\? AND revoked_at IS NULL").
+		WithArgs(now, "family").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("UPDATE refresh_tokens SET revoked_at = \\? WHERE user_id = \\? AND revoked_at IS NULL").
+		WithArgs(now, 1).
+		WillReturnResult(sqlmock.NewResult(0, 5))
+
+	assert.NoError(t, repo.RevokeFamily(t.Context(), "family", now))
+	assert.NoError(t, repo.RevokeAllForUser(t.Context(), 1, now))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRefreshTokenRepository_DeleteExpired(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRefreshTokenRepository(db)
+	now := time.Now()
+
+	mock.ExpectExec("DELETE FROM refresh_tokens WHERE expires_at <= \\?").
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	deleted, err := repo.DeleteExpired(t.Context(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
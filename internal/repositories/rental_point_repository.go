@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+)
+
+const rentalPointColumns = "id, rental_id, latitude, longitude, recorded_at, created_at"
+
+type RentalPointRepository struct {
+	db DBTX
+}
+
+func NewRentalPointRepository(db DBTX) *RentalPointRepository {
+	return &RentalPointRepository{db: db}
+}
+
+// CreateBatch stores points for the rental in a single statement.
+func (r *RentalPointRepository) CreateBatch(ctx context.Context, rentalID int, points []models.RentalPoint) error {
+	if len(points) == 0 {
+		return nil
+	}
+
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	values := make([]string, len(points))
+	args := make([]interface{}, 0, len(points)*4)
+	for i, point := range points {
+		values[i] = "(?, ?, ?, ?)"
+		args = append(args, rentalID, point.Latitude, point.Longitude, point.RecordedAt)
+	}
+
+	_, err := r.db.ExecContext(
+		ctx,
+		"INSERT INTO rental_points (rental_id, latitude, longitude, recorded_at) VALUES "+strings.Join(values, ", "),
+		args...,
+	)
+	if err != nil {
+		return fmt.Errorf("error creating rental points: %w", err)
+	}
+
+	return nil
+}
+
+// GetByRental returns the rental's points in the order they were recorded,
+// which need not be the order they arrived in.
+func (r *RentalPointRepository) GetByRental(ctx context.Context, rentalID int) ([]models.RentalPoint, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT `+rentalPointColumns+` 
+		FROM rental_points WHERE rental_id = ? ORDER BY recorded_at ASC, id ASC`,
+		rentalID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying rental points: %w", err)
+	}
+	defer rows.Close()
+
+	points := []models.RentalPoint{}
+	for rows.Next() {
+		var point models.RentalPoint
+		err := rows.Scan(&point.ID, &point.RentalID, &point.Latitude, &point.Longitude, &point.RecordedAt, &point.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning rental point: %w", err)
+		}
+		points = append(points, point)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rental points: %w", err)
+	}
+
+	return points, nil
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRentalPointRepository_CreateBatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRentalPointRepository(db)
+	now := time.Now()
+
+	t.Run("Inserts all points in one statement", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO rental_points \\(rental_id, latitude, longitude, recorded_at\\) VALUES \\(\\?, \\?, \\?, \\?\\), \\(\\?, \\?, \\?, \\?\\)").
+			WithArgs(3, 40.41, -3.70, now, 3, 40.42, -3.71, now.Add(time.Second)).
+			WillReturnResult(sqlmock.NewResult(2, 2))
+
+		err := repo.CreateBatch(t.Context(), 3, []models.RentalPoint{
+			{Latitude: 40.41, Longitude: -3.70, RecordedAt: now},
+			{Latitude: 40.42, Longitude: -3.71, RecordedAt: now.Add(time.Second)},
+		})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("No points is a no-op", func(t *testing.T) {
+		err := repo.CreateBatch(t.Context(), 3, nil)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO rental_points").
+			WillReturnError(errors.New("database error"))
+
+		err := repo.CreateBatch(t.Context(), 3, []models.RentalPoint{{Latitude: 40.41, Longitude: -3.70, RecordedAt: now}})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "error creating rental points")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRentalPointRepository_GetByRental(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRentalPointRepository(db)
+	now := time.Now()
+	columns := []string{"id", "rental_id", "latitude", "longitude", "recorded_at", "created_at"}
+
+	t.Run("Returns points in recorded order", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM rental_points WHERE rental_id = \\? ORDER BY recorded_at ASC, id ASC").
+			WithArgs(3).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(1, 3, 40.41, -3.70, now, now).
+				AddRow(2, 3, 40.42, -3.71, now.Add(time.Second), now))
+
+		points, err := repo.GetByRental(t.Context(), 3)
+
+		assert.NoError(t, err)
+		assert.Len(t, points, 2)
+		assert.Equal(t, 40.42, points[1].Latitude)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM rental_points").
+			WillReturnError(errors.New("database error"))
+
+		points, err := repo.GetByRental(t.Context(), 3)
+
+		assert.Error(t, err)
+		assert.Nil(t, points)
+		assert.Contains(t, err.Error(), "error querying rental points")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -1,72 +1,38 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
 	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/queryspec"
 )
 
-type RentalRepository struct {
-	db *sql.DB
-}
-
-func NewRentalRepository(db *sql.DB) *RentalRepository {
-	return &RentalRepository{db: db}
-}
-
-func (r *RentalRepository) HasActiveRental(userID int) (bool, error) {
-	var count int
-	err := r.db.QueryRow(
-		"SELECT COUNT(*) FROM rentals WHERE user_id = ? AND status = 'running'",
-		userID,
-	).Scan(&count)
-	if err != nil {
-		return false, fmt.Errorf("error checking active rentals: %w", err)
-	}
-	return count > 0, nil
-}
-
-func (r *RentalRepository) Create(userID, bikeID int, startLat, startLong float64) (*models.Rental, error) {
-	result, err := r.db.Exec(
-		`INSERT INTO rentals (user_id, bike_id, status, start_time, start_latitude, start_longitude) 
-		VALUES (?, ?, 'running', ?, ?, ?)`,
-		userID, bikeID, time.Now(), startLat, startLong,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("error creating rental: %w", err)
-	}
+const rentalColumns = `id, user_id, bike_id, status, start_time, end_time, start_latitude, 
+		start_longitude, end_latitude, end_longitude, duration_minutes, cost, created_at, updated_at, cost_breakdown, distance_km`
 
-	rentalID, _ := result.LastInsertId()
-	return r.GetByID(int(rentalID))
-}
-
-func (r *RentalRepository) GetByID(rentalID int) (*models.Rental, error) {
+func scanRental(row rowScanner) (*models.Rental, error) {
 	var rental models.Rental
 	var endTime sql.NullTime
 	var endLat, endLong sql.NullFloat64
 	var durationMinutes sql.NullInt64
 	var cost sql.NullFloat64
+	var costBreakdown sql.NullString
+	var distanceKm sql.NullFloat64
 
-	err := r.db.QueryRow(
-		`SELECT id, user_id, bike_id, status, start_time, end_time, start_latitude, 
-		start_longitude, end_latitude, end_longitude, duration_minutes, cost, created_at, updated_at 
-		FROM rentals WHERE id = ?`,
-		rentalID,
-	).Scan(
+	err := row.Scan(
 		&rental.ID, &rental.UserID, &rental.BikeID, &rental.Status,
 		&rental.StartTime, &endTime, &rental.StartLatitude,
 		&rental.StartLongitude, &endLat, &endLong,
 		&durationMinutes, &cost,
-		&rental.CreatedAt, &rental.UpdatedAt,
+		&rental.CreatedAt, &rental.UpdatedAt, &costBreakdown, &distanceKm,
 	)
-
-	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("rental with id %d not found", rentalID)
-	}
 	if err != nil {
-		return nil, fmt.Errorf("error finding rental: %w", err)
+		return nil, err
 	}
 
 	if endTime.Valid {
@@ -86,99 +52,186 @@ func (r *RentalRepository) GetByID(rentalID int) (*models.Rental, error) {
 		c := cost.Float64
 		rental.Cost = &c
 	}
+	if distanceKm.Valid {
+		d := distanceKm.Float64
+		rental.DistanceKm = &d
+	}
+	if costBreakdown.Valid && costBreakdown.String != "" {
+		if err := json.Unmarshal([]byte(costBreakdown.String), &rental.CostBreakdown); err != nil {
+			return nil, fmt.Errorf("invalid cost breakdown: %w", err)
+		}
+	}
 
 	return &rental, nil
 }
 
-func (r *RentalRepository) GetActiveRentalsByUser(userID int, page, limit int) ([]*models.Rental, error) {
+func scanRentals(rows *sql.Rows) ([]*models.Rental, error) {
+	defer rows.Close()
+
+	rentals := []*models.Rental{}
+	for rows.Next() {
+		rental, err := scanRental(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning rental: %w", err)
+		}
+		rentals = append(rentals, rental)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rentals: %w", err)
+	}
+
+	return rentals, nil
+}
+
+type RentalRepository struct {
+	db DBTX
+}
+
+func NewRentalRepository(db DBTX) *RentalRepository {
+	return &RentalRepository{db: db}
+}
+
+func (r *RentalRepository) HasActiveRental(ctx context.Context, userID int) (bool, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	var count int
+	err := r.db.QueryRowContext(
+		ctx,
+		"SELECT COUNT(*) FROM rentals WHERE user_id = ? AND status IN ('running', 'paused')",
+		userID,
+	).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("error checking active rentals: %w", err)
+	}
+	return count > 0, nil
+}
+
+// Create starts a running rental of the bike at startTime and location.
+func (r *RentalRepository) Create(ctx context.Context, userID, bikeID int, startLat, startLong float64, startTime time.Time) (*models.Rental, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	rentalID, err := insertReturningID(
+		ctx,
+		r.db,
+		`INSERT INTO rentals (user_id, bike_id, status, start_time, start_latitude, start_longitude) 
+		VALUES (?, ?, 'running', ?, ?, ?)`,
+		userID, bikeID, startTime, startLat, startLong,
+	)
+	if isUniqueViolation(err) {
+		return nil, fmt.Errorf("error creating rental: %w", constants.ErrUserHasActiveRental)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error creating rental: %w", err)
+	}
+
+	return r.GetByID(ctx, rentalID)
+}
+
+func (r *RentalRepository) GetByID(ctx context.Context, rentalID int) (*models.Rental, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	rental, err := scanRental(r.db.QueryRowContext(
+		ctx,
+		`SELECT `+rentalColumns+` 
+		FROM rentals WHERE id = ?`,
+		rentalID,
+	))
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("rental with id %d: %w", rentalID, constants.ErrRentalNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding rental: %w", err)
+	}
+
+	return rental, nil
+}
+
+func (r *RentalRepository) GetActiveRentalsByUser(ctx context.Context, userID int, page, limit int) ([]*models.Rental, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
 	offset := (page - 1) * limit
 
-	rows, err := r.db.Query(
-		`SELECT id, user_id, bike_id, status, start_time, end_time, start_latitude, 
-		start_longitude, end_latitude, end_longitude, duration_minutes, cost, created_at, updated_at 
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT `+rentalColumns+` 
 		FROM rentals WHERE user_id = ? ORDER BY id DESC LIMIT ? OFFSET ?`,
 		userID, limit, offset,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error querying rentals: %w", err)
 	}
-	defer rows.Close()
 
-	rentals := []*models.Rental{}
-	for rows.Next() {
-		var rental models.Rental
-		var endTime sql.NullTime
-		var endLat, endLong sql.NullFloat64
-		var durationMinutes sql.NullInt64
-		var cost sql.NullFloat64
-
-		err := rows.Scan(
-			&rental.ID, &rental.UserID, &rental.BikeID, &rental.Status,
-			&rental.StartTime, &endTime, &rental.StartLatitude,
-			&rental.StartLongitude, &endLat, &endLong,
-			&durationMinutes, &cost,
-			&rental.CreatedAt, &rental.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("error scanning rental: %w", err)
-		}
+	return scanRentals(rows)
+}
 
-		if endTime.Valid {
-			rental.EndTime = endTime.Time
-		}
-		if endLat.Valid {
-			rental.EndLatitude = endLat.Float64
-		}
-		if endLong.Valid {
-			rental.EndLongitude = endLong.Float64
-		}
-		if durationMinutes.Valid {
-			dur := int(durationMinutes.Int64)
-			rental.DurationMinutes = &dur
-		}
-		if cost.Valid {
-			c := cost.Float64
-			rental.Cost = &c
-		}
+// GetRentalsByUserCursor returns up to limit of a user's rentals from
+// cursor, newest first.
+func (r *RentalRepository) GetRentalsByUserCursor(ctx context.Context, userID int, cursor queryspec.Cursor, limit int) ([]*models.Rental, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
 
-		rentals = append(rentals, &rental)
+	spec := &queryspec.Spec{
+		Filters: []queryspec.Filter{{Column: "user_id", Op: queryspec.OpEq, Value: userID}},
+		Sorts:   []queryspec.Sort{{Column: "id", Desc: true}},
+		Cursor:  &cursor,
 	}
+	where, args := whereClause(spec)
+	paging, pagingArgs := limitClause(spec, 1, limit)
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating rentals: %w", err)
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT `+rentalColumns+` 
+		FROM rentals`+where+orderByClause(spec)+paging,
+		append(args, pagingArgs...)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying rentals: %w", err)
 	}
 
-	return rentals, nil
+	return scanRentals(rows)
 }
 
-func (r *RentalRepository) CountByUser(userID int) (int, error) {
+func (r *RentalRepository) CountByUser(ctx context.Context, userID int) (int, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
 	var count int
-	err := r.db.QueryRow("SELECT COUNT(*) FROM rentals WHERE user_id = ?", userID).Scan(&count)
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM rentals WHERE user_id = ?", userID).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("error counting rentals: %w", err)
 	}
 	return count, nil
 }
 
-func (r *RentalRepository) GetActiveRentalByUser(userID int) (*models.Rental, error) {
-	var rental models.Rental
-	var endTime sql.NullTime
-	var endLat, endLong sql.NullFloat64
-	var durationMinutes sql.NullInt64
-	var cost sql.NullFloat64
+// CountActive returns how many rentals are running or paused.
+func (r *RentalRepository) CountActive(ctx context.Context) (int, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	var count int
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM rentals WHERE status IN ('running', 'paused')").Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error counting active rentals: %w", err)
+	}
+	return count, nil
+}
 
-	err := r.db.QueryRow(
-		`SELECT id, user_id, bike_id, status, start_time, end_time, start_latitude, 
-		start_longitude, end_latitude, end_longitude, duration_minutes, cost, created_at, updated_at 
-		FROM rentals WHERE user_id = ? AND status = 'running' LIMIT 1`,
+func (r *RentalRepository) GetActiveRentalByUser(ctx context.Context, userID int) (*models.Rental, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	rental, err := scanRental(r.db.QueryRowContext(
+		ctx,
+		`SELECT `+rentalColumns+` 
+		FROM rentals WHERE user_id = ? AND status IN ('running', 'paused') LIMIT 1`,
 		userID,
-	).Scan(
-		&rental.ID, &rental.UserID, &rental.BikeID, &rental.Status,
-		&rental.StartTime, &endTime, &rental.StartLatitude,
-		&rental.StartLongitude, &endLat, &endLong,
-		&durationMinutes, &cost,
-		&rental.CreatedAt, &rental.UpdatedAt,
-	)
+	))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -187,36 +240,93 @@ func (r *RentalRepository) GetActiveRentalByUser(userID int) (*models.Rental, er
 		return nil, fmt.Errorf("error finding active rental: %w", err)
 	}
 
-	if endTime.Valid {
-		rental.EndTime = endTime.Time
+	return rental, nil
+}
+
+// UpdateStatus moves the rental from one status to another. It reports false
+// when the rental was no longer in the from status.
+func (r *RentalRepository) UpdateStatus(ctx context.Context, rentalID int, from, to models.RentalStatus) (bool, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	result, err := r.db.ExecContext(
+		ctx,
+		"UPDATE rentals SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND status = ?",
+		to, rentalID, from,
+	)
+	if err != nil {
+		return false, fmt.Errorf("error updating rental status: %w", err)
 	}
-	if endLat.Valid {
-		rental.EndLatitude = endLat.Float64
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error updating rental status: %w", err)
 	}
-	if endLong.Valid {
-		rental.EndLongitude = endLong.Float64
+
+	return affected > 0, nil
+}
+
+// Refund moves an ended rental to refunded, replacing its cost and cost
+// breakdown. It reports false when the rental was no longer ended.
+func (r *RentalRepository) Refund(ctx context.Context, rentalID int, cost float64, costBreakdown []models.CostLineItem) (bool, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	encoded, err := json.Marshal(costBreakdown)
+	if err != nil {
+		return false, fmt.Errorf("error encoding cost breakdown: %w", err)
 	}
-	if durationMinutes.Valid {
-		dur := int(durationMinutes.Int64)
-		rental.DurationMinutes = &dur
+
+	result, err := r.db.ExecContext(
+		ctx,
+		"UPDATE rentals SET status = ?, cost = ?, cost_breakdown = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND status = ?",
+		models.RentalStatusRefunded, cost, string(encoded), rentalID, models.RentalStatusEnded,
+	)
+	if err != nil {
+		return false, fmt.Errorf("error refunding rental: %w", err)
 	}
-	if cost.Valid {
-		c := cost.Float64
-		rental.Cost = &c
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error refunding rental: %w", err)
 	}
 
-	return &rental, nil
+	return affected > 0, nil
 }
 
-func (r *RentalRepository) EndRental(rentalID int, endLat, endLong float64, durationMinutes int, cost float64) (*models.Rental, error) {
-	_, err := r.db.Exec(
-		`UPDATE rentals SET status = 'ended', end_time = ?, end_latitude = ?, 
-		end_longitude = ?, duration_minutes = ?, cost = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
-		time.Now(), endLat, endLong, durationMinutes, cost, rentalID,
+// Close moves an active (running or paused) rental to a final status at
+// endTime, recording its duration, ridden distance and cost. The end location
+// is optional since rentals closed by an admin have none.
+func (r *RentalRepository) Close(ctx context.Context, rentalID int, status models.RentalStatus, endTime time.Time, endLat, endLong, distanceKm *float64, durationMinutes int, cost float64, costBreakdown []models.CostLineItem) (*models.Rental, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	var breakdown interface{}
+	if len(costBreakdown) > 0 {
+		encoded, err := json.Marshal(costBreakdown)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding cost breakdown: %w", err)
+		}
+		breakdown = string(encoded)
+	}
+
+	result, err := r.db.ExecContext(
+		ctx,
+		`UPDATE rentals SET status = ?, end_time = ?, end_latitude = ?, 
+		end_longitude = ?, distance_km = ?, duration_minutes = ?, cost = ?, cost_breakdown = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND status IN ('running', 'paused')`,
+		status, endTime, endLat, endLong, distanceKm, durationMinutes, cost, breakdown, rentalID,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error ending rental: %w", err)
 	}
 
-	return r.GetByID(rentalID)
-}
\ No newline at end of file
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("error ending rental: %w", err)
+	}
+	if affected == 0 {
+		return nil, constants.ErrNoActiveRental
+	}
+
+	return r.GetByID(ctx, rentalID)
+}
@@ -2,11 +2,15 @@ package repositories
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/queryspec"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -22,7 +26,7 @@ func TestRentalRepository_HasActiveRental(t *testing.T) {
 			WithArgs(1).
 			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 
-		hasActive, err := repo.HasActiveRental(1)
+		hasActive, err := repo.HasActiveRental(t.Context(), 1)
 
 		assert.NoError(t, err)
 		assert.True(t, hasActive)
@@ -34,7 +38,7 @@ func TestRentalRepository_HasActiveRental(t *testing.T) {
 			WithArgs(1).
 			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 
-		hasActive, err := repo.HasActiveRental(1)
+		hasActive, err := repo.HasActiveRental(t.Context(), 1)
 
 		assert.NoError(t, err)
 		assert.False(t, hasActive)
@@ -46,7 +50,7 @@ func TestRentalRepository_HasActiveRental(t *testing.T) {
 			WithArgs(1).
 			WillReturnError(fmt.Errorf("database error"))
 
-		hasActive, err := repo.HasActiveRental(1)
+		hasActive, err := repo.HasActiveRental(t.Context(), 1)
 
 		assert.Error(t, err)
 		assert.False(t, hasActive)
@@ -64,38 +68,51 @@ func TestRentalRepository_Create(t *testing.T) {
 	now := time.Now()
 
 	t.Run("Successfully create rental", func(t *testing.T) {
-		mock.ExpectExec("INSERT INTO rentals").
-			WithArgs(1, 10, sqlmock.AnyArg(), 40.7128, -74.0060).
-			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery("INSERT INTO rentals (.+) RETURNING id").
+			WithArgs(1, 10, now, 40.7128, -74.0060).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 
 		mock.ExpectQuery("SELECT id, user_id, bike_id, status").
 			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "bike_id", "status", "start_time", "end_time", "start_latitude", "start_longitude", "end_latitude", "end_longitude", "duration_minutes", "cost", "created_at", "updated_at"}).
-				AddRow(1, 1, 10, "running", now, nil, 40.7128, -74.0060, nil, nil, nil, nil, now, now))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "bike_id", "status", "start_time", "end_time", "start_latitude", "start_longitude", "end_latitude", "end_longitude", "duration_minutes", "cost", "created_at", "updated_at", "cost_breakdown", "distance_km"}).
+				AddRow(1, 1, 10, "running", now, nil, 40.7128, -74.0060, nil, nil, nil, nil, now, now, nil, nil))
 
-		rental, err := repo.Create(1, 10, 40.7128, -74.0060)
+		rental, err := repo.Create(t.Context(), 1, 10, 40.7128, -74.0060, now)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, rental)
 		assert.Equal(t, 1, rental.ID)
 		assert.Equal(t, 1, rental.UserID)
 		assert.Equal(t, 10, rental.BikeID)
-		assert.Equal(t, "running", rental.Status)
+		assert.Equal(t, models.RentalStatusRunning, rental.Status)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	t.Run("Database error on insert", func(t *testing.T) {
-		mock.ExpectExec("INSERT INTO rentals").
-			WithArgs(1, 10, sqlmock.AnyArg(), 40.7128, -74.0060).
+		mock.ExpectQuery("INSERT INTO rentals (.+) RETURNING id").
+			WithArgs(1, 10, now, 40.7128, -74.0060).
 			WillReturnError(fmt.Errorf("database error"))
 
-		rental, err := repo.Create(1, 10, 40.7128, -74.0060)
+		rental, err := repo.Create(t.Context(), 1, 10, 40.7128, -74.0060, now)
 
 		assert.Error(t, err)
 		assert.Nil(t, rental)
 		assert.Contains(t, err.Error(), "error creating rental")
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
+
+	t.Run("User already has a running rental", func(t *testing.T) {
+		mock.ExpectQuery("INSERT INTO rentals (.+) RETURNING id").
+			WithArgs(1, 10, now, 40.7128, -74.0060).
+			WillReturnError(fmt.Errorf("constraint failed: UNIQUE constraint failed: rentals.user_id (2067)"))
+
+		rental, err := repo.Create(t.Context(), 1, 10, 40.7128, -74.0060, now)
+
+		assert.Error(t, err)
+		assert.Nil(t, rental)
+		assert.True(t, errors.Is(err, constants.ErrUserHasActiveRental))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 }
 
 func TestRentalRepository_GetByID(t *testing.T) {
@@ -109,15 +126,15 @@ func TestRentalRepository_GetByID(t *testing.T) {
 	t.Run("Rental found - running status", func(t *testing.T) {
 		mock.ExpectQuery("SELECT id, user_id, bike_id, status").
 			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "bike_id", "status", "start_time", "end_time", "start_latitude", "start_longitude", "end_latitude", "end_longitude", "duration_minutes", "cost", "created_at", "updated_at"}).
-				AddRow(1, 1, 10, "running", now, nil, 40.7128, -74.0060, nil, nil, nil, nil, now, now))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "bike_id", "status", "start_time", "end_time", "start_latitude", "start_longitude", "end_latitude", "end_longitude", "duration_minutes", "cost", "created_at", "updated_at", "cost_breakdown", "distance_km"}).
+				AddRow(1, 1, 10, "running", now, nil, 40.7128, -74.0060, nil, nil, nil, nil, now, now, nil, nil))
 
-		rental, err := repo.GetByID(1)
+		rental, err := repo.GetByID(t.Context(), 1)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, rental)
 		assert.Equal(t, 1, rental.ID)
-		assert.Equal(t, "running", rental.Status)
+		assert.Equal(t, models.RentalStatusRunning, rental.Status)
 		assert.Nil(t, rental.DurationMinutes)
 		assert.Nil(t, rental.Cost)
 		assert.NoError(t, mock.ExpectationsWereMet())
@@ -130,15 +147,15 @@ func TestRentalRepository_GetByID(t *testing.T) {
 
 		mock.ExpectQuery("SELECT id, user_id, bike_id, status").
 			WithArgs(2).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "bike_id", "status", "start_time", "end_time", "start_latitude", "start_longitude", "end_latitude", "end_longitude", "duration_minutes", "cost", "created_at", "updated_at"}).
-				AddRow(2, 1, 10, "ended", now, endTime, 40.7128, -74.0060, 40.7200, -74.0100, durationMinutes, cost, now, now))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "bike_id", "status", "start_time", "end_time", "start_latitude", "start_longitude", "end_latitude", "end_longitude", "duration_minutes", "cost", "created_at", "updated_at", "cost_breakdown", "distance_km"}).
+				AddRow(2, 1, 10, "ended", now, endTime, 40.7128, -74.0060, 40.7200, -74.0100, durationMinutes, cost, now, now, nil, 4.2))
 
-		rental, err := repo.GetByID(2)
+		rental, err := repo.GetByID(t.Context(), 2)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, rental)
 		assert.Equal(t, 2, rental.ID)
-		assert.Equal(t, "ended", rental.Status)
+		assert.Equal(t, models.RentalStatusEnded, rental.Status)
 		assert.Equal(t, endTime, rental.EndTime)
 		assert.Equal(t, 40.7200, rental.EndLatitude)
 		assert.Equal(t, -74.0100, rental.EndLongitude)
@@ -146,6 +163,9 @@ func TestRentalRepository_GetByID(t *testing.T) {
 		assert.Equal(t, durationMinutes, *rental.DurationMinutes)
 		assert.NotNil(t, rental.Cost)
 		assert.Equal(t, cost, *rental.Cost)
+		if assert.NotNil(t, rental.DistanceKm) {
+			assert.Equal(t, 4.2, *rental.DistanceKm)
+		}
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
@@ -154,7 +174,7 @@ func TestRentalRepository_GetByID(t *testing.T) {
 			WithArgs(999).
 			WillReturnError(sql.ErrNoRows)
 
-		rental, err := repo.GetByID(999)
+		rental, err := repo.GetByID(t.Context(), 999)
 
 		assert.Error(t, err)
 		assert.Nil(t, rental)
@@ -167,7 +187,7 @@ func TestRentalRepository_GetByID(t *testing.T) {
 			WithArgs(1).
 			WillReturnError(fmt.Errorf("database error"))
 
-		rental, err := repo.GetByID(1)
+		rental, err := repo.GetByID(t.Context(), 1)
 
 		assert.Error(t, err)
 		assert.Nil(t, rental)
@@ -185,35 +205,35 @@ func TestRentalRepository_GetActiveRentalsByUser(t *testing.T) {
 	now := time.Now()
 
 	t.Run("Successfully get rentals", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"id", "user_id", "bike_id", "status", "start_time", "end_time", "start_latitude", "start_longitude", "end_latitude", "end_longitude", "duration_minutes", "cost", "created_at", "updated_at"}).
-			AddRow(1, 1, 10, "running", now, nil, 40.7128, -74.0060, nil, nil, nil, nil, now, now).
-			AddRow(2, 1, 11, "ended", now.Add(-1*time.Hour), now, 40.7128, -74.0060, 40.7200, -74.0100, 30, 15.0, now, now)
+		rows := sqlmock.NewRows([]string{"id", "user_id", "bike_id", "status", "start_time", "end_time", "start_latitude", "start_longitude", "end_latitude", "end_longitude", "duration_minutes", "cost", "created_at", "updated_at", "cost_breakdown", "distance_km"}).
+			AddRow(1, 1, 10, "running", now, nil, 40.7128, -74.0060, nil, nil, nil, nil, now, now, nil, nil).
+			AddRow(2, 1, 11, "ended", now.Add(-1*time.Hour), now, 40.7128, -74.0060, 40.7200, -74.0100, 30, 15.0, now, now, nil, nil)
 
 		mock.ExpectQuery("SELECT id, user_id, bike_id, status").
 			WithArgs(1, 10, 0).
 			WillReturnRows(rows)
 
-		rentals, err := repo.GetActiveRentalsByUser(1, 1, 10)
+		rentals, err := repo.GetActiveRentalsByUser(t.Context(), 1, 1, 10)
 
 		assert.NoError(t, err)
 		assert.Len(t, rentals, 2)
 		assert.Equal(t, 1, rentals[0].ID)
-		assert.Equal(t, "running", rentals[0].Status)
+		assert.Equal(t, models.RentalStatusRunning, rentals[0].Status)
 		assert.Equal(t, 2, rentals[1].ID)
-		assert.Equal(t, "ended", rentals[1].Status)
+		assert.Equal(t, models.RentalStatusEnded, rentals[1].Status)
 		assert.NotNil(t, rentals[1].DurationMinutes)
 		assert.NotNil(t, rentals[1].Cost)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	t.Run("Empty result", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"id", "user_id", "bike_id", "status", "start_time", "end_time", "start_latitude", "start_longitude", "end_latitude", "end_longitude", "duration_minutes", "cost", "created_at", "updated_at"})
+		rows := sqlmock.NewRows([]string{"id", "user_id", "bike_id", "status", "start_time", "end_time", "start_latitude", "start_longitude", "end_latitude", "end_longitude", "duration_minutes", "cost", "created_at", "updated_at", "cost_breakdown", "distance_km"})
 
 		mock.ExpectQuery("SELECT id, user_id, bike_id, status").
 			WithArgs(1, 10, 0).
 			WillReturnRows(rows)
 
-		rentals, err := repo.GetActiveRentalsByUser(1, 1, 10)
+		rentals, err := repo.GetActiveRentalsByUser(t.Context(), 1, 1, 10)
 
 		assert.NoError(t, err)
 		assert.Len(t, rentals, 0)
@@ -225,7 +245,7 @@ func TestRentalRepository_GetActiveRentalsByUser(t *testing.T) {
 			WithArgs(1, 10, 0).
 			WillReturnError(fmt.Errorf("database error"))
 
-		rentals, err := repo.GetActiveRentalsByUser(1, 1, 10)
+		rentals, err := repo.GetActiveRentalsByUser(t.Context(), 1, 1, 10)
 
 		assert.Error(t, err)
 		assert.Nil(t, rentals)
@@ -234,6 +254,53 @@ func TestRentalRepository_GetActiveRentalsByUser(t *testing.T) {
 	})
 }
 
+func TestRentalRepository_GetRentalsByUserCursor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRentalRepository(db)
+	now := time.Now()
+	columns := []string{"id", "user_id", "bike_id", "status", "start_time", "end_time", "start_latitude", "start_longitude", "end_latitude", "end_longitude", "duration_minutes", "cost", "created_at", "updated_at", "cost_breakdown", "distance_km"}
+
+	t.Run("First page", func(t *testing.T) {
+		rows := sqlmock.NewRows(columns).
+			AddRow(7, 1, 10, "running", now, nil, 40.7128, -74.0060, nil, nil, nil, nil, now, now, nil, nil)
+
+		mock.ExpectQuery(`FROM rentals WHERE user_id = \? ORDER BY id DESC LIMIT \?`).
+			WithArgs(1, 3).
+			WillReturnRows(rows)
+
+		rentals, err := repo.GetRentalsByUserCursor(t.Context(), 1, queryspec.Cursor{}, 3)
+
+		assert.NoError(t, err)
+		assert.Len(t, rentals, 1)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Older rentals after the cursor", func(t *testing.T) {
+		mock.ExpectQuery(`WHERE user_id = \? AND id < \? ORDER BY id DESC LIMIT \?`).
+			WithArgs(1, 7, 3).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		_, err := repo.GetRentalsByUserCursor(t.Context(), 1, queryspec.Cursor{ID: 7}, 3)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Newer rentals before the cursor", func(t *testing.T) {
+		mock.ExpectQuery(`WHERE user_id = \? AND id > \? ORDER BY id ASC LIMIT \?`).
+			WithArgs(1, 7, 3).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		_, err := repo.GetRentalsByUserCursor(t.Context(), 1, queryspec.Cursor{ID: 7, Before: true}, 3)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestRentalRepository_CountByUser(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -246,7 +313,7 @@ func TestRentalRepository_CountByUser(t *testing.T) {
 			WithArgs(1).
 			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
 
-		count, err := repo.CountByUser(1)
+		count, err := repo.CountByUser(t.Context(), 1)
 
 		assert.NoError(t, err)
 		assert.Equal(t, 5, count)
@@ -258,7 +325,7 @@ func TestRentalRepository_CountByUser(t *testing.T) {
 			WithArgs(2).
 			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 
-		count, err := repo.CountByUser(2)
+		count, err := repo.CountByUser(t.Context(), 2)
 
 		assert.NoError(t, err)
 		assert.Equal(t, 0, count)
@@ -270,7 +337,7 @@ func TestRentalRepository_CountByUser(t *testing.T) {
 			WithArgs(1).
 			WillReturnError(fmt.Errorf("database error"))
 
-		count, err := repo.CountByUser(1)
+		count, err := repo.CountByUser(t.Context(), 1)
 
 		assert.Error(t, err)
 		assert.Equal(t, 0, count)
@@ -279,6 +346,37 @@ func TestRentalRepository_CountByUser(t *testing.T) {
 	})
 }
 
+func TestRentalRepository_CountActive(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRentalRepository(db)
+
+	t.Run("Successfully count active rentals", func(t *testing.T) {
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM rentals WHERE status IN").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+		count, err := repo.CountActive(t.Context())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, count)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		mock.ExpectQuery("SELECT COUNT").
+			WillReturnError(fmt.Errorf("database error"))
+
+		count, err := repo.CountActive(t.Context())
+
+		assert.Error(t, err)
+		assert.Equal(t, 0, count)
+		assert.Contains(t, err.Error(), "error counting active rentals")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestRentalRepository_GetActiveRentalByUser(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -290,15 +388,15 @@ func TestRentalRepository_GetActiveRentalByUser(t *testing.T) {
 	t.Run("Active rental found", func(t *testing.T) {
 		mock.ExpectQuery("SELECT id, user_id, bike_id, status").
 			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "bike_id", "status", "start_time", "end_time", "start_latitude", "start_longitude", "end_latitude", "end_longitude", "duration_minutes", "cost", "created_at", "updated_at"}).
-				AddRow(1, 1, 10, "running", now, nil, 40.7128, -74.0060, nil, nil, nil, nil, now, now))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "bike_id", "status", "start_time", "end_time", "start_latitude", "start_longitude", "end_latitude", "end_longitude", "duration_minutes", "cost", "created_at", "updated_at", "cost_breakdown", "distance_km"}).
+				AddRow(1, 1, 10, "running", now, nil, 40.7128, -74.0060, nil, nil, nil, nil, now, now, nil, nil))
 
-		rental, err := repo.GetActiveRentalByUser(1)
+		rental, err := repo.GetActiveRentalByUser(t.Context(), 1)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, rental)
 		assert.Equal(t, 1, rental.ID)
-		assert.Equal(t, "running", rental.Status)
+		assert.Equal(t, models.RentalStatusRunning, rental.Status)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
@@ -307,7 +405,7 @@ func TestRentalRepository_GetActiveRentalByUser(t *testing.T) {
 			WithArgs(2).
 			WillReturnError(sql.ErrNoRows)
 
-		rental, err := repo.GetActiveRentalByUser(2)
+		rental, err := repo.GetActiveRentalByUser(t.Context(), 2)
 
 		assert.NoError(t, err)
 		assert.Nil(t, rental)
@@ -319,7 +417,7 @@ func TestRentalRepository_GetActiveRentalByUser(t *testing.T) {
 			WithArgs(1).
 			WillReturnError(fmt.Errorf("database error"))
 
-		rental, err := repo.GetActiveRentalByUser(1)
+		rental, err := repo.GetActiveRentalByUser(t.Context(), 1)
 
 		assert.Error(t, err)
 		assert.Nil(t, rental)
@@ -328,49 +426,134 @@ func TestRentalRepository_GetActiveRentalByUser(t *testing.T) {
 	})
 }
 
-func TestRentalRepository_EndRental(t *testing.T) {
+func TestRentalRepository_Close(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
 	defer db.Close()
 
 	repo := NewRentalRepository(db)
 	now := time.Now()
+	endLat, endLong := 40.7200, -74.0100
+
+	t.Run("Successfully close rental", func(t *testing.T) {
+		breakdown := []models.CostLineItem{{Code: "time", Description: "Ride time", Quantity: 30, UnitPrice: 0.5, Amount: 15.0}}
+		encoded := `[{"code":"time","description":"Ride time","quantity":30,"unit_price":0.5,"amount":15}]`
 
-	t.Run("Successfully end rental", func(t *testing.T) {
-		mock.ExpectExec("UPDATE rentals SET status = 'ended'").
-			WithArgs(sqlmock.AnyArg(), 40.7200, -74.0100, 30, 15.0, 1).
+		mock.ExpectExec("UPDATE rentals SET status = \\?, end_time").
+			WithArgs("ended", now, 40.7200, -74.0100, nil, 30, 15.0, encoded, 1).
 			WillReturnResult(sqlmock.NewResult(0, 1))
 
 		mock.ExpectQuery("SELECT id, user_id, bike_id, status").
 			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "bike_id", "status", "start_time", "end_time", "start_latitude", "start_longitude", "end_latitude", "end_longitude", "duration_minutes", "cost", "created_at", "updated_at"}).
-				AddRow(1, 1, 10, "ended", now, now, 40.7128, -74.0060, 40.7200, -74.0100, 30, 15.0, now, now))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "bike_id", "status", "start_time", "end_time", "start_latitude", "start_longitude", "end_latitude", "end_longitude", "duration_minutes", "cost", "created_at", "updated_at", "cost_breakdown", "distance_km"}).
+				AddRow(1, 1, 10, "ended", now, now, 40.7128, -74.0060, 40.7200, -74.0100, 30, 15.0, now, now, encoded, nil))
 
-		rental, err := repo.EndRental(1, 40.7200, -74.0100, 30, 15.0)
+		rental, err := repo.Close(t.Context(), 1, models.RentalStatusEnded, now, &endLat, &endLong, nil, 30, 15.0, breakdown)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, rental)
 		assert.Equal(t, 1, rental.ID)
-		assert.Equal(t, "ended", rental.Status)
+		assert.Equal(t, models.RentalStatusEnded, rental.Status)
 		assert.Equal(t, 40.7200, rental.EndLatitude)
 		assert.Equal(t, -74.0100, rental.EndLongitude)
 		assert.NotNil(t, rental.DurationMinutes)
 		assert.Equal(t, 30, *rental.DurationMinutes)
 		assert.NotNil(t, rental.Cost)
 		assert.Equal(t, 15.0, *rental.Cost)
+		assert.Equal(t, breakdown, rental.CostBreakdown)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	t.Run("Update error", func(t *testing.T) {
-		mock.ExpectExec("UPDATE rentals SET status = 'ended'").
-			WithArgs(sqlmock.AnyArg(), 40.7200, -74.0100, 30, 15.0, 1).
+		mock.ExpectExec("UPDATE rentals SET status = \\?, end_time").
+			WithArgs("ended", now, 40.7200, -74.0100, nil, 30, 15.0, nil, 1).
 			WillReturnError(fmt.Errorf("database error"))
 
-		rental, err := repo.EndRental(1, 40.7200, -74.0100, 30, 15.0)
+		rental, err := repo.Close(t.Context(), 1, models.RentalStatusEnded, now, &endLat, &endLong, nil, 30, 15.0, nil)
 
 		assert.Error(t, err)
 		assert.Nil(t, rental)
 		assert.Contains(t, err.Error(), "error ending rental")
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
-}
\ No newline at end of file
+
+	t.Run("Rental no longer running", func(t *testing.T) {
+		mock.ExpectExec("UPDATE rentals SET status = \\?, end_time").
+			WithArgs("ended", now, 40.7200, -74.0100, nil, 30, 15.0, nil, 1).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		rental, err := repo.Close(t.Context(), 1, models.RentalStatusEnded, now, &endLat, &endLong, nil, 30, 15.0, nil)
+
+		assert.Equal(t, constants.ErrNoActiveRental, err)
+		assert.Nil(t, rental)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRentalRepository_Refund(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRentalRepository(db)
+	breakdown := []models.CostLineItem{
+		{Code: "time", Description: "Ride time", Quantity: 10, UnitPrice: 0.5, Amount: 5},
+		{Code: "refund", Description: "Refund", Amount: -5},
+	}
+
+	t.Run("Successfully refund rental", func(t *testing.T) {
+		mock.ExpectExec("UPDATE rentals SET status = \\?, cost = \\?, cost_breakdown").
+			WithArgs("refunded", 0.0, sqlmock.AnyArg(), 1, "ended").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		updated, err := repo.Refund(t.Context(), 1, 0, breakdown)
+
+		assert.NoError(t, err)
+		assert.True(t, updated)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Rental not ended", func(t *testing.T) {
+		mock.ExpectExec("UPDATE rentals SET status = \\?, cost = \\?, cost_breakdown").
+			WithArgs("refunded", 0.0, sqlmock.AnyArg(), 1, "ended").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		updated, err := repo.Refund(t.Context(), 1, 0, breakdown)
+
+		assert.NoError(t, err)
+		assert.False(t, updated)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRentalRepository_UpdateStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRentalRepository(db)
+
+	t.Run("Successfully update status", func(t *testing.T) {
+		mock.ExpectExec("UPDATE rentals SET status = \\?").
+			WithArgs("paused", 1, "running").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		updated, err := repo.UpdateStatus(t.Context(), 1, "running", "paused")
+
+		assert.NoError(t, err)
+		assert.True(t, updated)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Rental not in expected status", func(t *testing.T) {
+		mock.ExpectExec("UPDATE rentals SET status = \\?").
+			WithArgs("running", 1, "paused").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		updated, err := repo.UpdateStatus(t.Context(), 1, "paused", "running")
+
+		assert.NoError(t, err)
+		assert.False(t, updated)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
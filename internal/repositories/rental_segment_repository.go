@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+)
+
+const rentalSegmentColumns = `id, rental_id, state, started_at, ended_at, created_at`
+
+func scanRentalSegment(row rowScanner) (*models.RentalSegment, error) {
+	var segment models.RentalSegment
+	var endedAt sql.NullTime
+
+	err := row.Scan(&segment.ID, &segment.RentalID, &segment.State, &segment.StartedAt, &endedAt, &segment.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if endedAt.Valid {
+		t := endedAt.Time
+		segment.EndedAt = &t
+	}
+
+	return &segment, nil
+}
+
+type RentalSegmentRepository struct {
+	db DBTX
+}
+
+func NewRentalSegmentRepository(db DBTX) *RentalSegmentRepository {
+	return &RentalSegmentRepository{db: db}
+}
+
+// Open starts a new segment in state for the rental. Callers close the
+// previous segment first; a rental has at most one open segment.
+func (r *RentalSegmentRepository) Open(ctx context.Context, rentalID int, state models.RentalStatus, startedAt time.Time) error {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	_, err := r.db.ExecContext(
+		ctx,
+		"INSERT INTO rental_segments (rental_id, state, started_at) VALUES (?, ?, ?)",
+		rentalID, state, startedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error opening rental segment: %w", err)
+	}
+	return nil
+}
+
+// CloseOpen ends the rental's open segment, if any, at endedAt.
+func (r *RentalSegmentRepository) CloseOpen(ctx context.Context, rentalID int, endedAt time.Time) error {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	_, err := r.db.ExecContext(
+		ctx,
+		"UPDATE rental_segments SET ended_at = ? WHERE rental_id = ? AND ended_at IS NULL",
+		endedAt, rentalID,
+	)
+	if err != nil {
+		return fmt.Errorf("error closing rental segment: %w", err)
+	}
+	return nil
+}
+
+func (r *RentalSegmentRepository) GetByRental(ctx context.Context, rentalID int) ([]models.RentalSegment, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT `+rentalSegmentColumns+` 
+		FROM rental_segments WHERE rental_id = ? ORDER BY started_at ASC, id ASC`,
+		rentalID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying rental segments: %w", err)
+	}
+	defer rows.Close()
+
+	segments := []models.RentalSegment{}
+	for rows.Next() {
+		segment, err := scanRentalSegment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning rental segment: %w", err)
+		}
+		segments = append(segments, *segment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rental segments: %w", err)
+	}
+
+	return segments, nil
+}
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRentalSegmentRepository_Open(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRentalSegmentRepository(db)
+	now := time.Now()
+
+	t.Run("Successfully open segment", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO rental_segments").
+			WithArgs(1, "paused", now).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := repo.Open(t.Context(), 1, models.RentalStatusPaused, now)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Insert error", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO rental_segments").
+			WillReturnError(fmt.Errorf("database error"))
+
+		err := repo.Open(t.Context(), 1, models.RentalStatusPaused, now)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "error opening rental segment")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRentalSegmentRepository_CloseOpen(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRentalSegmentRepository(db)
+	now := time.Now()
+
+	mock.ExpectExec("UPDATE rental_segments SET ended_at = \\? WHERE rental_id = \\? AND ended_at IS NULL").
+		WithArgs(now, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.CloseOpen(t.Context(), 1, now)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRentalSegmentRepository_GetByRental(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRentalSegmentRepository(db)
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT id, rental_id, state, started_at, ended_at, created_at FROM rental_segments WHERE rental_id = \\?").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "rental_id", "state", "started_at", "ended_at", "created_at"}).
+			AddRow(1, 1, "running", now.Add(-10*time.Minute), now.Add(-5*time.Minute), now).
+			AddRow(2, 1, "paused", now.Add(-5*time.Minute), nil, now))
+
+	segments, err := repo.GetByRental(t.Context(), 1)
+
+	assert.NoError(t, err)
+	assert.Len(t, segments, 2)
+	assert.NotNil(t, segments[0].EndedAt)
+	assert.Equal(t, models.RentalStatusPaused, segments[1].State)
+	assert.Nil(t, segments[1].EndedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,203 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+)
+
+const reservationColumns = "id, user_id, bike_id, status, expires_at, rental_id, created_at, updated_at"
+
+func scanReservation(row rowScanner) (*models.Reservation, error) {
+	var reservation models.Reservation
+	var rentalID sql.NullInt64
+
+	err := row.Scan(
+		&reservation.ID, &reservation.UserID, &reservation.BikeID, &reservation.Status,
+		&reservation.ExpiresAt, &rentalID, &reservation.CreatedAt, &reservation.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if rentalID.Valid {
+		id := int(rentalID.Int64)
+		reservation.RentalID = &id
+	}
+
+	return &reservation, nil
+}
+
+type ReservationRepository struct {
+	db DBTX
+}
+
+func NewReservationRepository(db DBTX) *ReservationRepository {
+	return &ReservationRepository{db: db}
+}
+
+func (r *ReservationRepository) Create(ctx context.Context, userID, bikeID int, expiresAt time.Time) (*models.Reservation, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	reservationID, err := insertReturningID(
+		ctx,
+		r.db,
+		"INSERT INTO reservations (user_id, bike_id, status, expires_at) VALUES (?, ?, ?, ?)",
+		userID, bikeID, models.ReservationStatusActive, expiresAt,
+	)
+	if isUniqueViolation(err) {
+		// SQLite names the column, Postgres the index.
+		if strings.Contains(err.Error(), "reservations.bike_id") || strings.Contains(err.Error(), "idx_reservations_one_active_per_bike") {
+			return nil, fmt.Errorf("error creating reservation: %w", constants.ErrBikeReserved)
+		}
+		return nil, fmt.Errorf("error creating reservation: %w", constants.ErrUserHasActiveReservation)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error creating reservation: %w", err)
+	}
+
+	return r.GetByID(ctx, reservationID)
+}
+
+func (r *ReservationRepository) GetByID(ctx context.Context, reservationID int) (*models.Reservation, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	reservation, err := scanReservation(r.db.QueryRowContext(
+		ctx,
+		"SELECT "+reservationColumns+" FROM reservations WHERE id = ?",
+		reservationID,
+	))
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("reservation with id %d: %w", reservationID, constants.ErrReservationNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding reservation: %w", err)
+	}
+
+	return reservation, nil
+}
+
+// GetActiveByUser returns the user's active reservation, or nil if there is
+// none. The reservation may already be past its expiry if the expiry worker
+// has not run yet.
+func (r *ReservationRepository) GetActiveByUser(ctx context.Context, userID int) (*models.Reservation, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	return r.getActive(ctx, "user_id", userID)
+}
+
+// GetActiveByBike returns the bike's active reservation, or nil if there is none.
+func (r *ReservationRepository) GetActiveByBike(ctx context.Context, bikeID int) (*models.Reservation, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	return r.getActive(ctx, "bike_id", bikeID)
+}
+
+func (r *ReservationRepository) getActive(ctx context.Context, column string, id int) (*models.Reservation, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	reservation, err := scanReservation(r.db.QueryRowContext(
+		ctx,
+		"SELECT "+reservationColumns+" FROM reservations WHERE "+column+" = ? AND status = ? LIMIT 1",
+		id, models.ReservationStatusActive,
+	))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding active reservation: %w", err)
+	}
+
+	return reservation, nil
+}
+
+// GetExpired returns active reservations whose expiry is before now.
+func (r *ReservationRepository) GetExpired(ctx context.Context, now time.Time) ([]*models.Reservation, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT "+reservationColumns+" FROM reservations WHERE status = ? AND expires_at <= ? ORDER BY id ASC",
+		models.ReservationStatusActive, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying expired reservations: %w", err)
+	}
+	defer rows.Close()
+
+	reservations := []*models.Reservation{}
+	for rows.Next() {
+		reservation, err := scanReservation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning reservation: %w", err)
+		}
+		reservations = append(reservations, reservation)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reservations: %w", err)
+	}
+
+	return reservations, nil
+}
+
+// Close moves an active reservation to status. It reports false when the
+// reservation was no longer active, so concurrent closes only take effect once.
+func (r *ReservationRepository) Close(ctx context.Context, reservationID int, status string) (bool, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	result, err := r.db.ExecContext(
+		ctx,
+		"UPDATE reservations SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND status = ?",
+		status, reservationID, models.ReservationStatusActive,
+	)
+	if err != nil {
+		return false, fmt.Errorf("error updating reservation: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error updating reservation: %w", err)
+	}
+
+	return affected == 1, nil
+}
+
+// Convert marks an active reservation as converted into rentalID.
+func (r *ReservationRepository) Convert(ctx context.Context, reservationID, rentalID int) error {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	result, err := r.db.ExecContext(
+		ctx,
+		"UPDATE reservations SET status = ?, rental_id = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND status = ?",
+		models.ReservationStatusConverted, rentalID, reservationID, models.ReservationStatusActive,
+	)
+	if err != nil {
+		return fmt.Errorf("error converting reservation: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error converting reservation: %w", err)
+	}
+	if affected == 0 {
+		return constants.ErrNoActiveReservation
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+var reservationRowColumns = []string{"id", "user_id", "bike_id", "status", "expires_at", "rental_id", "created_at", "updated_at"}
+
+func TestReservationRepository_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewReservationRepository(db)
+	now := time.Now()
+	expiresAt := now.Add(10 * time.Minute)
+
+	t.Run("Successfully create reservation", func(t *testing.T) {
+		mock.ExpectQuery("INSERT INTO reservations (.+) RETURNING id").
+			WithArgs(1, 10, "active", expiresAt).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+		mock.ExpectQuery("SELECT (.+) FROM reservations WHERE id = ?").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows(reservationRowColumns).
+				AddRow(1, 1, 10, "active", expiresAt, nil, now, now))
+
+		reservation, err := repo.Create(t.Context(), 1, 10, expiresAt)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, reservation.ID)
+		assert.Equal(t, "active", reservation.Status)
+		assert.Nil(t, reservation.RentalID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Bike already reserved", func(t *testing.T) {
+		mock.ExpectQuery("INSERT INTO reservations (.+) RETURNING id").
+			WillReturnError(errors.New("constraint failed: UNIQUE constraint failed: reservations.bike_id (2067)"))
+
+		reservation, err := repo.Create(t.Context(), 2, 10, expiresAt)
+
+		assert.ErrorIs(t, err, constants.ErrBikeReserved)
+		assert.Nil(t, reservation)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Bike already reserved on Postgres", func(t *testing.T) {
+		mock.ExpectQuery("INSERT INTO reservations (.+) RETURNING id").
+			WillReturnError(&pgconn.PgError{Code: "23505", Message: `duplicate key value violates unique constraint "idx_reservations_one_active_per_bike"`})
+
+		reservation, err := repo.Create(t.Context(), 2, 10, expiresAt)
+
+		assert.ErrorIs(t, err, constants.ErrBikeReserved)
+		assert.Nil(t, reservation)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("User already has a reservation", func(t *testing.T) {
+		mock.ExpectQuery("INSERT INTO reservations (.+) RETURNING id").
+			WillReturnError(errors.New("constraint failed: UNIQUE constraint failed: reservations.user_id (2067)"))
+
+		reservation, err := repo.Create(t.Context(), 1, 11, expiresAt)
+
+		assert.ErrorIs(t, err, constants.ErrUserHasActiveReservation)
+		assert.Nil(t, reservation)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestReservationRepository_GetActiveByUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewReservationRepository(db)
+	now := time.Now()
+
+	t.Run("Active reservation found", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM reservations WHERE user_id = \\? AND status = \\?").
+			WithArgs(1, "active").
+			WillReturnRows(sqlmock.NewRows(reservationRowColumns).
+				AddRow(3, 1, 10, "active", now, nil, now, now))
+
+		reservation, err := repo.GetActiveByUser(t.Context(), 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, reservation.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("No active reservation", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM reservations WHERE user_id = \\? AND status = \\?").
+			WithArgs(1, "active").
+			WillReturnError(sql.ErrNoRows)
+
+		reservation, err := repo.GetActiveByUser(t.Context(), 1)
+
+		assert.NoError(t, err)
+		assert.Nil(t, reservation)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestReservationRepository_GetExpired(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewReservationRepository(db)
+	now := time.Now()
+
+	t.Run("Returns expired reservations", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM reservations WHERE status = \\? AND expires_at <= \\?").
+			WithArgs("active", now).
+			WillReturnRows(sqlmock.NewRows(reservationRowColumns).
+				AddRow(1, 1, 10, "active", now.Add(-time.Minute), nil, now, now).
+				AddRow(2, 2, 11, "active", now.Add(-time.Hour), nil, now, now))
+
+		reservations, err := repo.GetExpired(t.Context(), now)
+
+		assert.NoError(t, err)
+		assert.Len(t, reservations, 2)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestReservationRepository_Close(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewReservationRepository(db)
+
+	t.Run("Closes active reservation", func(t *testing.T) {
+		mock.ExpectExec("UPDATE reservations SET status = \\?").
+			WithArgs("cancelled", 1, "active").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		closed, err := repo.Close(t.Context(), 1, "cancelled")
+
+		assert.NoError(t, err)
+		assert.True(t, closed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Reservation no longer active", func(t *testing.T) {
+		mock.ExpectExec("UPDATE reservations SET status = \\?").
+			WithArgs("expired", 1, "active").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		closed, err := repo.Close(t.Context(), 1, "expired")
+
+		assert.NoError(t, err)
+		assert.False(t, closed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestReservationRepository_Convert(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewReservationRepository(db)
+
+	t.Run("Reservation no longer active", func(t *testing.T) {
+		mock.ExpectExec("UPDATE reservations SET status = \\?, rental_id = \\?").
+			WithArgs("converted", 5, 1, "active").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.Convert(t.Context(), 1, 5)
+
+		assert.Equal(t, constants.ErrNoActiveReservation, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+)
+
+type ReturnRuleRepository struct {
+	db DBTX
+}
+
+func NewReturnRuleRepository(db DBTX) *ReturnRuleRepository {
+	return &ReturnRuleRepository{db: db}
+}
+
+// Get returns the rule an admin has set, or nil if none has been set yet.
+func (r *ReturnRuleRepository) Get(ctx context.Context) (*models.ReturnDistanceRule, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	var rule models.ReturnDistanceRule
+	var updatedAt time.Time
+	err := r.db.QueryRowContext(
+		ctx,
+		"SELECT mode, max_distance_km, updated_at FROM return_distance_rule WHERE id = 1",
+	).Scan(&rule.Mode, &rule.MaxDistanceKm, &updatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding return distance rule: %w", err)
+	}
+
+	rule.UpdatedAt = &updatedAt
+	return &rule, nil
+}
+
+// Save replaces the stored rule and returns it as stored.
+func (r *ReturnRuleRepository) Save(ctx context.Context, rule *models.ReturnDistanceRule) (*models.ReturnDistanceRule, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	_, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO return_distance_rule (id, mode, max_distance_km, updated_at) VALUES (1, ?, ?, CURRENT_TIMESTAMP) 
+		ON CONFLICT (id) DO UPDATE SET mode = excluded.mode, max_distance_km = excluded.max_distance_km, updated_at = excluded.updated_at`,
+		rule.Mode, rule.MaxDistanceKm,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error saving return distance rule: %w", err)
+	}
+
+	return r.Get(ctx)
+}
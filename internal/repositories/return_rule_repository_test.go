@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReturnRuleRepository_Get(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewReturnRuleRepository(db)
+
+	t.Run("Returns the stored rule", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery("SELECT mode, max_distance_km, updated_at FROM return_distance_rule WHERE id = 1").
+			WillReturnRows(sqlmock.NewRows([]string{"mode", "max_distance_km", "updated_at"}).AddRow("from_station", 0.5, now))
+
+		rule, err := repo.Get(t.Context())
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.ReturnDistanceFromStation, rule.Mode)
+		assert.Equal(t, 0.5, rule.MaxDistanceKm)
+		assert.Equal(t, now, *rule.UpdatedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Returns nil when no rule is stored", func(t *testing.T) {
+		mock.ExpectQuery("SELECT mode, max_distance_km, updated_at FROM return_distance_rule").
+			WillReturnRows(sqlmock.NewRows([]string{"mode", "max_distance_km", "updated_at"}))
+
+		rule, err := repo.Get(t.Context())
+
+		assert.NoError(t, err)
+		assert.Nil(t, rule)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		mock.ExpectQuery("SELECT mode, max_distance_km, updated_at FROM return_distance_rule").
+			WillReturnError(errors.New("database error"))
+
+		rule, err := repo.Get(t.Context())
+
+		assert.Error(t, err)
+		assert.Nil(t, rule)
+		assert.Contains(t, err.Error(), "error finding return distance rule")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestReturnRuleRepository_Save(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewReturnRuleRepository(db)
+
+	t.Run("Upserts the rule and reads it back", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO return_distance_rule (.+) ON CONFLICT \\(id\\) DO UPDATE").
+			WithArgs(models.ReturnDistanceFromStart, 2.5).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery("SELECT mode, max_distance_km, updated_at FROM return_distance_rule").
+			WillReturnRows(sqlmock.NewRows([]string{"mode", "max_distance_km", "updated_at"}).AddRow("from_start", 2.5, time.Now()))
+
+		rule, err := repo.Save(t.Context(), &models.ReturnDistanceRule{Mode: models.ReturnDistanceFromStart, MaxDistanceKm: 2.5})
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.ReturnDistanceFromStart, rule.Mode)
+		assert.Equal(t, 2.5, rule.MaxDistanceKm)
+		assert.NotNil(t, rule.UpdatedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO return_distance_rule").
+			WillReturnError(errors.New("CHECK constraint failed"))
+
+		rule, err := repo.Save(t.Context(), &models.ReturnDistanceRule{Mode: "anywhere"})
+
+		assert.Error(t, err)
+		assert.Nil(t, rule)
+		assert.Contains(t, err.Error(), "error saving return distance rule")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RevokedTokenRepository stores the jti of access tokens that must be
+// rejected before they expire.
+type RevokedTokenRepository struct {
+	db DBTX
+}
+
+func NewRevokedTokenRepository(db DBTX) *RevokedTokenRepository {
+	return &RevokedTokenRepository{db: db}
+}
+
+// Revoke adds a single access token to the revocation list. Revoking the same
+// jti twice is not an error.
+func (r *RevokedTokenRepository) Revoke(ctx context.Context, jti string, userID int, expiresAt time.Time) error {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	_, err := r.db.ExecContext(
+		ctx,
+		"INSERT INTO revoked_tokens (jti, user_id, expires_at) VALUES (?, ?, ?) ON CONFLICT (jti) DO NOTHING",
+		jti, userID, expiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error revoking access token: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeFamilyAccessTokens revokes the still valid access tokens issued with
+// any refresh token of a rotation family.
+func (r *RevokedTokenRepository) RevokeFamilyAccessTokens(ctx context.Context, familyID string, now time.Time) error {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	_, err := r.db.ExecContext(
+		ctx,
+		"INSERT INTO revoked_tokens (jti, user_id, expires_at) "+
+			"SELECT access_jti, user_id, access_expires_at FROM refresh_tokens WHERE family_id = ? AND access_expires_at > ? "+
+			"ON CONFLICT (jti) DO NOTHING",
+		familyID, now,
+	)
+	if err != nil {
+		return fmt.Errorf("error revoking access tokens: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeUserAccessTokens revokes the still valid access tokens issued to the
+// user with any of their refresh tokens.
+func (r *RevokedTokenRepository) RevokeUserAccessTokens(ctx context.Context, userID int, now time.Time) error {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	_, err := r.db.ExecContext(
+		ctx,
+		"INSERT INTO revoked_tokens (jti, user_id, expires_at) "+
+			"SELECT access_jti, user_id, access_expires_at FROM refresh_tokens WHERE user_id = ? AND access_expires_at > ? "+
+			"ON CONFLICT (jti) DO NOTHING",
+		userID, now,
+	)
+	if err != nil {
+		return fmt.Errorf("error revoking access tokens: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpired removes revoked access tokens that expired at or before now,
+// which are rejected on their expiry alone, and returns how many it removed.
+func (r *RevokedTokenRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM revoked_tokens WHERE expires_at <= ?", now)
+	if err != nil {
+		return 0, fmt.Errorf("error deleting expired revoked tokens: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error deleting expired revoked tokens: %w", err)
+	}
+
+	return deleted, nil
+}
+
+func (r *RevokedTokenRepository) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = ?)", jti).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("error checking revoked token: %w", err)
+	}
+
+	return exists, nil
+}
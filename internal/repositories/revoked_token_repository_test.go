@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevokedTokenRepository_Revoke(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRevokedTokenRepository(db)
+	now := time.Now()
+
+	mock.ExpectExec("INSERT INTO revoked_tokens \\(jti, user_id, expires_at\\) VALUES \\(\\?, \\?, \\?\\) ON CONFLICT \\(jti\\) DO NOTHING").
+		WithArgs("jti", 1, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO revoked_tokens (.+) SELECT access_jti, user_id, access_expires_at FROM refresh_tokens WHERE family_id = \\?").
+		WithArgs("family", now).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO revoked_tokens (.+) SELECT access_jti, user_id, access_expires_at FROM refresh_tokens WHERE user_id = \\?").
+		WithArgs(1, now).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	assert.NoError(t, repo.Revoke(t.Context(), "jti", 1, now))
+	assert.NoError(t, repo.RevokeFamilyAccessTokens(t.Context(), "family", now))
+	assert.NoError(t, repo.RevokeUserAccessTokens(t.Context(), 1, now))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRevokedTokenRepository_IsRevoked(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRevokedTokenRepository(db)
+
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs("revoked").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs("live").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	revoked, err := repo.IsRevoked(t.Context(), "revoked")
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	revoked, err = repo.IsRevoked(t.Context(), "live")
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRevokedTokenRepository_DeleteExpired(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRevokedTokenRepository(db)
+	now := time.Now()
+
+	mock.ExpectExec("DELETE FROM revoked_tokens WHERE expires_at <= \\?").
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	deleted, err := repo.DeleteExpired(t.Context(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,257 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+)
+
+// stationColumns includes the bike counts, so every read reports the
+// station's current occupancy.
+const stationColumns = `id, name, latitude, longitude, radius_meters, polygon, capacity, created_at, updated_at, 
+		(SELECT COUNT(*) FROM bikes WHERE bikes.station_id = stations.id), 
+		(SELECT COUNT(*) FROM bikes WHERE bikes.station_id = stations.id AND bikes.is_available = 1)`
+
+func scanStation(row rowScanner) (*models.Station, error) {
+	var station models.Station
+	var radiusMeters sql.NullFloat64
+	var polygon sql.NullString
+
+	err := row.Scan(
+		&station.ID, &station.Name, &station.Latitude, &station.Longitude, &radiusMeters, &polygon,
+		&station.Capacity, &station.CreatedAt, &station.UpdatedAt, &station.ParkedBikes, &station.AvailableBikes,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if radiusMeters.Valid {
+		r := radiusMeters.Float64
+		station.RadiusMeters = &r
+	}
+	if polygon.Valid && polygon.String != "" {
+		if err := json.Unmarshal([]byte(polygon.String), &station.Polygon); err != nil {
+			return nil, fmt.Errorf("error decoding station polygon: %w", err)
+		}
+	}
+
+	station.FreeDocks = max(station.Capacity-station.ParkedBikes, 0)
+
+	return &station, nil
+}
+
+// encodePolygon returns the polygon as stored, or nil for a radius station.
+func encodePolygon(polygon []models.GeoPoint) (interface{}, error) {
+	if len(polygon) == 0 {
+		return nil, nil
+	}
+
+	encoded, err := json.Marshal(polygon)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding station polygon: %w", err)
+	}
+	return string(encoded), nil
+}
+
+type StationRepository struct {
+	db DBTX
+}
+
+func NewStationRepository(db DBTX) *StationRepository {
+	return &StationRepository{db: db}
+}
+
+func (r *StationRepository) Create(ctx context.Context, station *models.Station) (*models.Station, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	polygon, err := encodePolygon(station.Polygon)
+	if err != nil {
+		return nil, err
+	}
+
+	stationID, err := insertReturningID(
+		ctx,
+		r.db,
+		"INSERT INTO stations (name, latitude, longitude, radius_meters, polygon, capacity) VALUES (?, ?, ?, ?, ?, ?)",
+		station.Name, station.Latitude, station.Longitude, station.RadiusMeters, polygon, station.Capacity,
+	)
+	if isUniqueViolation(err) {
+		return nil, constants.ErrStationNameTaken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error creating station: %w", err)
+	}
+
+	return r.GetByID(ctx, stationID)
+}
+
+func (r *StationRepository) GetByID(ctx context.Context, stationID int) (*models.Station, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	station, err := scanStation(r.db.QueryRowContext(
+		ctx,
+		`SELECT `+stationColumns+` 
+		FROM stations WHERE id = ?`,
+		stationID,
+	))
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("station with id %d: %w", stationID, constants.ErrStationNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding station: %w", err)
+	}
+
+	return station, nil
+}
+
+func (r *StationRepository) CountAll(ctx context.Context) (int, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	var count int
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM stations").Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error counting stations: %w", err)
+	}
+	return count, nil
+}
+
+func (r *StationRepository) GetAll(ctx context.Context, page, limit int) ([]*models.Station, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	offset := (page - 1) * limit
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT `+stationColumns+` 
+		FROM stations ORDER BY id ASC LIMIT ? OFFSET ?`,
+		limit, offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying stations: %w", err)
+	}
+
+	return scanStations(rows)
+}
+
+// GetInBounds returns the stations whose anchor point lies in the box. It is
+// a prefilter for finding the station a point falls in, so the box must be
+// padded by the largest station extent.
+func (r *StationRepository) GetInBounds(ctx context.Context, minLat, maxLat, minLong, maxLong float64) ([]*models.Station, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT `+stationColumns+` 
+		FROM stations WHERE latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ? ORDER BY id ASC`,
+		minLat, maxLat, minLong, maxLong,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying nearby stations: %w", err)
+	}
+
+	return scanStations(rows)
+}
+
+func scanStations(rows *sql.Rows) ([]*models.Station, error) {
+	defer rows.Close()
+
+	stations := []*models.Station{}
+	for rows.Next() {
+		station, err := scanStation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning station: %w", err)
+		}
+		stations = append(stations, station)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating stations: %w", err)
+	}
+
+	return stations, nil
+}
+
+// Update writes the full station. Callers merge partial updates onto the
+// stored station first.
+func (r *StationRepository) Update(ctx context.Context, station *models.Station) (*models.Station, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	polygon, err := encodePolygon(station.Polygon)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.db.ExecContext(
+		ctx,
+		`UPDATE stations SET name = ?, latitude = ?, longitude = ?, radius_meters = ?, polygon = ?, capacity = ?, 
+		updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		station.Name, station.Latitude, station.Longitude, station.RadiusMeters, polygon, station.Capacity, station.ID,
+	)
+	if isUniqueViolation(err) {
+		return nil, constants.ErrStationNameTaken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error updating station: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("error updating station: %w", err)
+	}
+	if affected == 0 {
+		return nil, fmt.Errorf("station with id %d: %w", station.ID, constants.ErrStationNotFound)
+	}
+
+	return r.GetByID(ctx, station.ID)
+}
+
+// Delete removes a station with no bikes parked at it and no price plan zone
+// rates pointing at it.
+func (r *StationRepository) Delete(ctx context.Context, stationID int) error {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	var inUse bool
+	err := r.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM bikes WHERE station_id = ?)", stationID).Scan(&inUse)
+	if err != nil {
+		return fmt.Errorf("error checking station usage: %w", err)
+	}
+	if inUse {
+		return constants.ErrStationInUse
+	}
+
+	var hasZoneRates bool
+	err = r.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM price_plan_zone_rates WHERE station_id = ?)", stationID).Scan(&hasZoneRates)
+	if err != nil {
+		return fmt.Errorf("error checking station zone rates: %w", err)
+	}
+	if hasZoneRates {
+		return constants.ErrStationHasZoneRates
+	}
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM stations WHERE id = ?", stationID)
+	if err != nil {
+		return fmt.Errorf("error deleting station: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error deleting station: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("station with id %d: %w", stationID, constants.ErrStationNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var stationRowColumns = []string{"id", "name", "latitude", "longitude", "radius_meters", "polygon", "capacity", "created_at", "updated_at", "parked_bikes", "available_bikes"}
+
+func TestStationRepository_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewStationRepository(db)
+	now := time.Now()
+	polygon := []models.GeoPoint{
+		{Latitude: 40.415, Longitude: -3.708},
+		{Latitude: 40.415, Longitude: -3.707},
+		{Latitude: 40.416, Longitude: -3.707},
+	}
+	encoded := `[{"latitude":40.415,"longitude":-3.708},{"latitude":40.415,"longitude":-3.707},{"latitude":40.416,"longitude":-3.707}]`
+
+	t.Run("Successfully create polygon station", func(t *testing.T) {
+		mock.ExpectQuery("INSERT INTO stations (.+) RETURNING id").
+			WithArgs("Sol", 40.4155, -3.7074, nil, encoded, 3).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+		mock.ExpectQuery("SELECT id, name, latitude").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows(stationRowColumns).
+				AddRow(1, "Sol", 40.4155, -3.7074, nil, encoded, 3, now, now, 1, 1))
+
+		station, err := repo.Create(t.Context(), &models.Station{
+			Name: "Sol", Latitude: 40.4155, Longitude: -3.7074, Polygon: polygon, Capacity: 3,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, station.ID)
+		assert.Nil(t, station.RadiusMeters)
+		assert.Equal(t, polygon, station.Polygon)
+		assert.Equal(t, 1, station.ParkedBikes)
+		assert.Equal(t, 2, station.FreeDocks)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Name already taken", func(t *testing.T) {
+		mock.ExpectQuery("INSERT INTO stations (.+) RETURNING id").
+			WillReturnError(errors.New("UNIQUE constraint failed: stations.name"))
+
+		station, err := repo.Create(t.Context(), &models.Station{Name: "Sol"})
+
+		assert.Equal(t, constants.ErrStationNameTaken, err)
+		assert.Nil(t, station)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestStationRepository_GetByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewStationRepository(db)
+
+	t.Run("Station not found", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, name, latitude").
+			WithArgs(99).
+			WillReturnError(sql.ErrNoRows)
+
+		station, err := repo.GetByID(t.Context(), 99)
+
+		assert.ErrorIs(t, err, constants.ErrStationNotFound)
+		assert.Nil(t, station)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestStationRepository_GetInBounds(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewStationRepository(db)
+	now := time.Now()
+
+	t.Run("Full station has no free docks", func(t *testing.T) {
+		rows := sqlmock.NewRows(stationRowColumns).
+			AddRow(1, "Sol", 40.4155, -3.7074, 50.0, nil, 2, now, now, 3, 0)
+
+		mock.ExpectQuery("SELECT (.+) FROM stations WHERE latitude BETWEEN \\? AND \\? AND longitude BETWEEN \\? AND \\?").
+			WithArgs(40.0, 41.0, -4.0, -3.0).
+			WillReturnRows(rows)
+
+		stations, err := repo.GetInBounds(t.Context(), 40.0, 41.0, -4.0, -3.0)
+
+		assert.NoError(t, err)
+		assert.Len(t, stations, 1)
+		assert.Equal(t, 50.0, *stations[0].RadiusMeters)
+		assert.Equal(t, 0, stations[0].FreeDocks)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestStationRepository_Update(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewStationRepository(db)
+
+	t.Run("Station not found", func(t *testing.T) {
+		mock.ExpectExec("UPDATE stations SET").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		station, err := repo.Update(t.Context(), &models.Station{ID: 99, Name: "Gone"})
+
+		assert.ErrorIs(t, err, constants.ErrStationNotFound)
+		assert.Nil(t, station)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestStationRepository_Delete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewStationRepository(db)
+
+	t.Run("Successfully delete station", func(t *testing.T) {
+		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM bikes").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM price_plan_zone_rates").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectExec("DELETE FROM stations WHERE id = ?").
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.Delete(t.Context(), 1)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Bikes parked at station", func(t *testing.T) {
+		mock.ExpectQuery("SELECT EXISTS").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		err := repo.Delete(t.Context(), 1)
+
+		assert.Equal(t, constants.ErrStationInUse, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("Price plan zone rates use station", func(t *testing.T) {
+		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM bikes").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM price_plan_zone_rates").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		err := repo.Delete(t.Context(), 1)
+
+		assert.Equal(t, constants.ErrStationHasZoneRates, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Nimirandad/bike-rental-service/internal/database"
+)
+
+// DBTX is satisfied by *database.DB and *database.Tx, so the same repository
+// code can run directly against the pool or inside a transaction. Queries use
+// ? placeholders, which those types rebind for the configured dialect.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Tx groups the repositories bound to a single database transaction.
+type Tx struct {
+	Bikes         *BikeRepository
+	Rentals       *RentalRepository
+	PricePlans    *PricePlanRepository
+	Reservations  *ReservationRepository
+	Segments      *RentalSegmentRepository
+	Users         *UserRepository
+	RefreshTokens *RefreshTokenRepository
+	RevokedTokens *RevokedTokenRepository
+	UserTokens    *UserTokenRepository
+	Outbox        *EmailOutboxRepository
+	Stations      *StationRepository
+	Geofences     *GeofenceRepository
+	ReturnRules   *ReturnRuleRepository
+	RentalPoints  *RentalPointRepository
+}
+
+// NewTx binds every repository to db, normally a *database.Tx.
+func NewTx(db DBTX) *Tx {
+	return &Tx{
+		Bikes:         NewBikeRepository(db),
+		Rentals:       NewRentalRepository(db),
+		PricePlans:    NewPricePlanRepository(db),
+		Reservations:  NewReservationRepository(db),
+		Segments:      NewRentalSegmentRepository(db),
+		Users:         NewUserRepository(db),
+		RefreshTokens: NewRefreshTokenRepository(db),
+		RevokedTokens: NewRevokedTokenRepository(db),
+		UserTokens:    NewUserTokenRepository(db),
+		Outbox:        NewEmailOutboxRepository(db),
+		Stations:      NewStationRepository(db),
+		Geofences:     NewGeofenceRepository(db),
+		ReturnRules:   NewReturnRuleRepository(db),
+		RentalPoints:  NewRentalPointRepository(db),
+	}
+}
+
+type UnitOfWork struct {
+	db *database.DB
+}
+
+func NewUnitOfWork(db *database.DB) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+// WithTx runs fn inside a transaction bound to ctx. The transaction is committed when fn
+// returns nil and rolled back when it returns an error or panics.
+func (u *UnitOfWork) WithTx(ctx context.Context, fn func(tx *Tx) error) (err error) {
+	sqlTx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = sqlTx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			_ = sqlTx.Rollback()
+		}
+	}()
+
+	err = fn(NewTx(sqlTx))
+	if err != nil {
+		return err
+	}
+
+	if err = sqlTx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return nil
+}
+
+// queryTimeouter is implemented by *database.DB and *database.Tx, which carry
+// the configured per-query timeout.
+type queryTimeouter interface {
+	WithQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc)
+}
+
+// withQueryTimeout bounds a repository call by the timeout configured on db.
+// Repositories built on a plain *sql.DB, as in the sqlmock tests, only obey
+// the caller's context.
+func withQueryTimeout(ctx context.Context, db DBTX) (context.Context, context.CancelFunc) {
+	if t, ok := db.(queryTimeouter); ok {
+		return t.WithQueryTimeout(ctx)
+	}
+	return ctx, func() {}
+}
+
+func isUniqueViolation(err error) bool {
+	return database.IsUniqueViolation(err)
+}
+
+// insertReturningID runs an INSERT and returns the id of the new row. It uses
+// RETURNING rather than LastInsertId, which the Postgres driver does not
+// support.
+func insertReturningID(ctx context.Context, db DBTX, query string, args ...interface{}) (int, error) {
+	var id int
+	err := db.QueryRowContext(ctx, query+" RETURNING id", args...).Scan(&id)
+	return id, err
+}
@@ -0,0 +1,134 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/database"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitOfWork_WithTx(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	uow := NewUnitOfWork(database.NewDB(db, database.SQLite))
+
+	t.Run("Commits when fn succeeds", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE bikes SET is_available = 0").
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := uow.WithTx(t.Context(), func(tx *Tx) error {
+			claimed, err := tx.Bikes.ClaimAvailable(t.Context(), 1)
+			assert.True(t, claimed)
+			return err
+		})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Rolls back when fn fails", func(t *testing.T) {
+		fnErr := errors.New("fn error")
+
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		err := uow.WithTx(t.Context(), func(tx *Tx) error {
+			return fnErr
+		})
+
+		assert.Equal(t, fnErr, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Rolls back and re-panics when fn panics", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		assert.Panics(t, func() {
+			_ = uow.WithTx(t.Context(), func(tx *Tx) error {
+				panic("boom")
+			})
+		})
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Begin error", func(t *testing.T) {
+		mock.ExpectBegin().WillReturnError(fmt.Errorf("database error"))
+
+		err := uow.WithTx(t.Context(), func(tx *Tx) error {
+			t.Fatal("fn must not be called")
+			return nil
+		})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "error starting transaction")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Commit error", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectCommit().WillReturnError(fmt.Errorf("database error"))
+
+		err := uow.WithTx(t.Context(), func(tx *Tx) error {
+			return nil
+		})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "error committing transaction")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRepositories_QueryTimeout(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	pool := database.NewDB(db, database.SQLite)
+	pool.QueryTimeout = 20 * time.Millisecond
+
+	t.Run("Query on the pool fails once the timeout expires", func(t *testing.T) {
+		mock.ExpectQuery("SELECT COUNT").
+			WillDelayFor(time.Second).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+
+		_, err := NewBikeRepository(pool).CountAvailable(t.Context())
+
+		assert.ErrorIs(t, err, sqlmock.ErrCancelled)
+	})
+
+	t.Run("Transactions inherit the timeout", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE bikes SET is_available = 0").
+			WillDelayFor(time.Second).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectRollback()
+
+		err := NewUnitOfWork(pool).WithTx(t.Context(), func(tx *Tx) error {
+			_, err := tx.Bikes.ClaimAvailable(t.Context(), 1)
+			return err
+		})
+
+		assert.ErrorIs(t, err, sqlmock.ErrCancelled)
+	})
+
+	t.Run("Queries within the timeout succeed", func(t *testing.T) {
+		mock.ExpectQuery("SELECT COUNT").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+
+		count, err := NewBikeRepository(pool).CountAvailable(t.Context())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 5, count)
+	})
+}
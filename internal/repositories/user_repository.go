@@ -1,23 +1,49 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
 	"github.com/Nimirandad/bike-rental-service/internal/models"
 )
 
+const userColumns = "id, email, first_name, last_name, email_verified_at, created_at"
+
+func scanUser(row rowScanner) (*models.User, error) {
+	var user models.User
+	var emailVerifiedAt sql.NullTime
+
+	err := row.Scan(&user.ID, &user.Email, &user.FirstName, &user.LastName, &emailVerifiedAt, &user.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if emailVerifiedAt.Valid {
+		user.EmailVerifiedAt = &emailVerifiedAt.Time
+	}
+
+	return &user, nil
+}
+
 type UserRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewUserRepository(db *sql.DB) *UserRepository {
+func NewUserRepository(db DBTX) *UserRepository {
 	return &UserRepository{db: db}
 }
 
-func (r *UserRepository) Create(email, hashedPassword, firstName, lastName string) (*models.User, error) {
-	result, err := r.db.Exec(
+func (r *UserRepository) Create(ctx context.Context, email, hashedPassword, firstName, lastName string) (*models.User, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	userID, err := insertReturningID(
+		ctx,
+		r.db,
 		"INSERT INTO users (email, hashed_password, first_name, last_name) VALUES (?, ?, ?, ?)",
 		email, hashedPassword, firstName, lastName,
 	)
@@ -25,66 +51,76 @@ func (r *UserRepository) Create(email, hashedPassword, firstName, lastName strin
 		return nil, fmt.Errorf("error creating user: %w", err)
 	}
 
-	userID, _ := result.LastInsertId()
-	return r.GetByID(int(userID))
+	return r.GetByID(ctx, userID)
 }
 
-func (r *UserRepository) GetByID(userID int) (*models.User, error) {
-	var user models.User
+func (r *UserRepository) GetByID(ctx context.Context, userID int) (*models.User, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
 
-	err := r.db.QueryRow(
-		"SELECT id, email, first_name, last_name, created_at FROM users WHERE id = ?",
+	user, err := scanUser(r.db.QueryRowContext(
+		ctx,
+		"SELECT "+userColumns+" FROM users WHERE id = ?",
 		userID,
-	).Scan(&user.ID, &user.Email, &user.FirstName, &user.LastName, &user.CreatedAt)
+	))
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("user with id %d not found", userID)
+		return nil, fmt.Errorf("user with id %d: %w", userID, constants.ErrUserNotFound)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("error finding user: %w", err)
 	}
 
-	return &user, nil
+	return user, nil
 }
 
-func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
-	var user models.User
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
 
-	err := r.db.QueryRow(
-		"SELECT id, email, first_name, last_name, created_at FROM users WHERE email = ?",
+	user, err := scanUser(r.db.QueryRowContext(
+		ctx,
+		"SELECT "+userColumns+" FROM users WHERE email = ?",
 		email,
-	).Scan(&user.ID, &user.Email, &user.FirstName, &user.LastName, &user.CreatedAt)
+	))
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("user with email %s not found", email)
+		return nil, fmt.Errorf("user with email %s: %w", email, constants.ErrUserNotFound)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("error finding user by email: %w", err)
 	}
 
-	return &user, nil
+	return user, nil
 }
 
-func (r *UserRepository) EmailExists(email string) (bool, error) {
+func (r *UserRepository) EmailExists(ctx context.Context, email string) (bool, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
 	var exists bool
-	err := r.db.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE email = ?)", email).Scan(&exists)
+	err := r.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM users WHERE email = ?)", email).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("error checking email existence: %w", err)
 	}
 	return exists, nil
 }
 
-func (r *UserRepository) GetPasswordHashByEmail(email string) (string, *models.User, error) {
+func (r *UserRepository) GetPasswordHashByEmail(ctx context.Context, email string) (string, *models.User, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
 	var user models.User
 	var hashedPassword string
 
-	err := r.db.QueryRow(
+	err := r.db.QueryRowContext(
+		ctx,
 		"SELECT id, email, hashed_password, first_name, last_name, created_at FROM users WHERE email = ?",
 		email,
 	).Scan(&user.ID, &user.Email, &hashedPassword, &user.FirstName, &user.LastName, &user.CreatedAt)
 
 	if err == sql.ErrNoRows {
-		return "", nil, fmt.Errorf("user with email %s not found", email)
+		return "", nil, fmt.Errorf("user with email %s: %w", email, constants.ErrUserNotFound)
 	}
 	if err != nil {
 		return "", nil, fmt.Errorf("error finding user credentials: %w", err)
@@ -93,22 +129,29 @@ func (r *UserRepository) GetPasswordHashByEmail(email string) (string, *models.U
 	return hashedPassword, &user, nil
 }
 
-func (r *UserRepository) EmailExistsByOtherUser(email string, userID int) (bool, error) {
+func (r *UserRepository) EmailExistsByOtherUser(ctx context.Context, email string, userID int) (bool, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
 	var exists bool
-	err := r.db.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE email = ? AND id != ?)", email, userID).Scan(&exists)
+	err := r.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM users WHERE email = ? AND id != ?)", email, userID).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("error checking email existence: %w", err)
 	}
 	return exists, nil
 }
 
-func (r *UserRepository) Update(userID int, email, firstName, lastName *string) (*models.User, error) {
+func (r *UserRepository) Update(ctx context.Context, userID int, email, firstName, lastName *string) (*models.User, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
 	query := "UPDATE users SET "
 	args := []interface{}{}
 	updates := []string{}
 
+	// A new address has to be verified again.
 	if email != nil {
-		updates = append(updates, "email = ?")
+		updates = append(updates, "email = ?", "email_verified_at = NULL")
 		args = append(args, *email)
 	}
 
@@ -123,7 +166,7 @@ func (r *UserRepository) Update(userID int, email, firstName, lastName *string)
 	}
 
 	if len(updates) == 0 {
-		return r.GetByID(userID)
+		return r.GetByID(ctx, userID)
 	}
 
 	updates = append(updates, "updated_at = CURRENT_TIMESTAMP")
@@ -131,10 +174,44 @@ func (r *UserRepository) Update(userID int, email, firstName, lastName *string)
 	query += strings.Join(updates, ", ") + " WHERE id = ?"
 	args = append(args, userID)
 
-	_, err := r.db.Exec(query, args...)
+	_, err := r.db.ExecContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("error updating user: %w", err)
 	}
 
-	return r.GetByID(userID)
-}
\ No newline at end of file
+	return r.GetByID(ctx, userID)
+}
+
+// UpdatePassword replaces the user's password hash.
+func (r *UserRepository) UpdatePassword(ctx context.Context, userID int, hashedPassword string) error {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	_, err := r.db.ExecContext(
+		ctx,
+		"UPDATE users SET hashed_password = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+		hashedPassword, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating password: %w", err)
+	}
+
+	return nil
+}
+
+// MarkEmailVerified records that the user confirmed their current address.
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, userID int, verifiedAt time.Time) error {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	_, err := r.db.ExecContext(
+		ctx,
+		"UPDATE users SET email_verified_at = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+		verifiedAt, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("error verifying email: %w", err)
+	}
+
+	return nil
+}
@@ -6,6 +6,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
 )
@@ -18,16 +20,16 @@ func TestUserRepository_Create(t *testing.T) {
 	repo := NewUserRepository(db)
 
 	t.Run("Successful user creation", func(t *testing.T) {
-		mock.ExpectExec("INSERT INTO users").
+		mock.ExpectQuery("INSERT INTO users (.+) RETURNING id").
 			WithArgs("test@example.com", "hashedpwd", "John", "Doe").
-			WillReturnResult(sqlmock.NewResult(1, 1))
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 
-		mock.ExpectQuery("SELECT id, email, first_name, last_name, created_at FROM users WHERE id = ?").
+		mock.ExpectQuery("SELECT id, email, first_name, last_name, email_verified_at, created_at FROM users WHERE id = ?").
 			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "first_name", "last_name", "created_at"}).
-				AddRow(1, "test@example.com", "John", "Doe", time.Now()))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "first_name", "last_name", "email_verified_at", "created_at"}).
+				AddRow(1, "test@example.com", "John", "Doe", nil, time.Now()))
 
-		user, err := repo.Create("test@example.com", "hashedpwd", "John", "Doe")
+		user, err := repo.Create(t.Context(), "test@example.com", "hashedpwd", "John", "Doe")
 
 		assert.NoError(t, err)
 		assert.NotNil(t, user)
@@ -39,11 +41,11 @@ func TestUserRepository_Create(t *testing.T) {
 	})
 
 	t.Run("Database error on insert", func(t *testing.T) {
-		mock.ExpectExec("INSERT INTO users").
+		mock.ExpectQuery("INSERT INTO users (.+) RETURNING id").
 			WithArgs("test@example.com", "hashedpwd", "John", "Doe").
 			WillReturnError(fmt.Errorf("database error"))
 
-		user, err := repo.Create("test@example.com", "hashedpwd", "John", "Doe")
+		user, err := repo.Create(t.Context(), "test@example.com", "hashedpwd", "John", "Doe")
 
 		assert.Error(t, err)
 		assert.Nil(t, user)
@@ -61,12 +63,12 @@ func TestUserRepository_GetByID(t *testing.T) {
 	now := time.Now()
 
 	t.Run("User found", func(t *testing.T) {
-		mock.ExpectQuery("SELECT id, email, first_name, last_name, created_at FROM users WHERE id = ?").
+		mock.ExpectQuery("SELECT id, email, first_name, last_name, email_verified_at, created_at FROM users WHERE id = ?").
 			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "first_name", "last_name", "created_at"}).
-				AddRow(1, "test@example.com", "John", "Doe", now))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "first_name", "last_name", "email_verified_at", "created_at"}).
+				AddRow(1, "test@example.com", "John", "Doe", nil, now))
 
-		user, err := repo.GetByID(1)
+		user, err := repo.GetByID(t.Context(), 1)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, user)
@@ -78,24 +80,25 @@ func TestUserRepository_GetByID(t *testing.T) {
 	})
 
 	t.Run("User not found", func(t *testing.T) {
-		mock.ExpectQuery("SELECT id, email, first_name, last_name, created_at FROM users WHERE id = ?").
+		mock.ExpectQuery("SELECT id, email, first_name, last_name, email_verified_at, created_at FROM users WHERE id = ?").
 			WithArgs(999).
 			WillReturnError(sql.ErrNoRows)
 
-		user, err := repo.GetByID(999)
+		user, err := repo.GetByID(t.Context(), 999)
 
 		assert.Error(t, err)
 		assert.Nil(t, user)
 		assert.Contains(t, err.Error(), "not found")
+		assert.ErrorIs(t, err, constants.ErrUserNotFound)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	t.Run("Database error", func(t *testing.T) {
-		mock.ExpectQuery("SELECT id, email, first_name, last_name, created_at FROM users WHERE id = ?").
+		mock.ExpectQuery("SELECT id, email, first_name, last_name, email_verified_at, created_at FROM users WHERE id = ?").
 			WithArgs(1).
 			WillReturnError(fmt.Errorf("database error"))
 
-		user, err := repo.GetByID(1)
+		user, err := repo.GetByID(t.Context(), 1)
 
 		assert.Error(t, err)
 		assert.Nil(t, user)
@@ -113,12 +116,12 @@ func TestUserRepository_GetByEmail(t *testing.T) {
 	now := time.Now()
 
 	t.Run("User found", func(t *testing.T) {
-		mock.ExpectQuery("SELECT id, email, first_name, last_name, created_at FROM users WHERE email = ?").
+		mock.ExpectQuery("SELECT id, email, first_name, last_name, email_verified_at, created_at FROM users WHERE email = ?").
 			WithArgs("test@example.com").
-			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "first_name", "last_name", "created_at"}).
-				AddRow(1, "test@example.com", "John", "Doe", now))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "first_name", "last_name", "email_verified_at", "created_at"}).
+				AddRow(1, "test@example.com", "John", "Doe", nil, now))
 
-		user, err := repo.GetByEmail("test@example.com")
+		user, err := repo.GetByEmail(t.Context(), "test@example.com")
 
 		assert.NoError(t, err)
 		assert.NotNil(t, user)
@@ -128,24 +131,25 @@ func TestUserRepository_GetByEmail(t *testing.T) {
 	})
 
 	t.Run("User not found", func(t *testing.T) {
-		mock.ExpectQuery("SELECT id, email, first_name, last_name, created_at FROM users WHERE email = ?").
+		mock.ExpectQuery("SELECT id, email, first_name, last_name, email_verified_at, created_at FROM users WHERE email = ?").
 			WithArgs("notfound@example.com").
 			WillReturnError(sql.ErrNoRows)
 
-		user, err := repo.GetByEmail("notfound@example.com")
+		user, err := repo.GetByEmail(t.Context(), "notfound@example.com")
 
 		assert.Error(t, err)
 		assert.Nil(t, user)
 		assert.Contains(t, err.Error(), "not found")
+		assert.ErrorIs(t, err, constants.ErrUserNotFound)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	t.Run("Database error", func(t *testing.T) {
-		mock.ExpectQuery("SELECT id, email, first_name, last_name, created_at FROM users WHERE email = ?").
+		mock.ExpectQuery("SELECT id, email, first_name, last_name, email_verified_at, created_at FROM users WHERE email = ?").
 			WithArgs("test@example.com").
 			WillReturnError(fmt.Errorf("database error"))
 
-		user, err := repo.GetByEmail("test@example.com")
+		user, err := repo.GetByEmail(t.Context(), "test@example.com")
 
 		assert.Error(t, err)
 		assert.Nil(t, user)
@@ -166,7 +170,7 @@ func TestUserRepository_EmailExists(t *testing.T) {
 			WithArgs("test@example.com").
 			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
 
-		exists, err := repo.EmailExists("test@example.com")
+		exists, err := repo.EmailExists(t.Context(), "test@example.com")
 
 		assert.NoError(t, err)
 		assert.True(t, exists)
@@ -178,7 +182,7 @@ func TestUserRepository_EmailExists(t *testing.T) {
 			WithArgs("notfound@example.com").
 			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
 
-		exists, err := repo.EmailExists("notfound@example.com")
+		exists, err := repo.EmailExists(t.Context(), "notfound@example.com")
 
 		assert.NoError(t, err)
 		assert.False(t, exists)
@@ -190,7 +194,7 @@ func TestUserRepository_EmailExists(t *testing.T) {
 			WithArgs("test@example.com").
 			WillReturnError(fmt.Errorf("database error"))
 
-		exists, err := repo.EmailExists("test@example.com")
+		exists, err := repo.EmailExists(t.Context(), "test@example.com")
 
 		assert.Error(t, err)
 		assert.False(t, exists)
@@ -213,7 +217,7 @@ func TestUserRepository_GetPasswordHashByEmail(t *testing.T) {
 			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "hashed_password", "first_name", "last_name", "created_at"}).
 				AddRow(1, "test@example.com", "$2a$10$hashedpassword", "John", "Doe", now))
 
-		hash, user, err := repo.GetPasswordHashByEmail("test@example.com")
+		hash, user, err := repo.GetPasswordHashByEmail(t.Context(), "test@example.com")
 
 		assert.NoError(t, err)
 		assert.NotNil(t, user)
@@ -228,12 +232,13 @@ func TestUserRepository_GetPasswordHashByEmail(t *testing.T) {
 			WithArgs("notfound@example.com").
 			WillReturnError(sql.ErrNoRows)
 
-		hash, user, err := repo.GetPasswordHashByEmail("notfound@example.com")
+		hash, user, err := repo.GetPasswordHashByEmail(t.Context(), "notfound@example.com")
 
 		assert.Error(t, err)
 		assert.Nil(t, user)
 		assert.Empty(t, hash)
 		assert.Contains(t, err.Error(), "not found")
+		assert.ErrorIs(t, err, constants.ErrUserNotFound)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
@@ -242,7 +247,7 @@ func TestUserRepository_GetPasswordHashByEmail(t *testing.T) {
 			WithArgs("test@example.com").
 			WillReturnError(fmt.Errorf("database error"))
 
-		hash, user, err := repo.GetPasswordHashByEmail("test@example.com")
+		hash, user, err := repo.GetPasswordHashByEmail(t.Context(), "test@example.com")
 
 		assert.Error(t, err)
 		assert.Nil(t, user)
@@ -264,7 +269,7 @@ func TestUserRepository_EmailExistsByOtherUser(t *testing.T) {
 			WithArgs("test@example.com", 1).
 			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
 
-		exists, err := repo.EmailExistsByOtherUser("test@example.com", 1)
+		exists, err := repo.EmailExistsByOtherUser(t.Context(), "test@example.com", 1)
 
 		assert.NoError(t, err)
 		assert.True(t, exists)
@@ -276,7 +281,7 @@ func TestUserRepository_EmailExistsByOtherUser(t *testing.T) {
 			WithArgs("test@example.com", 1).
 			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
 
-		exists, err := repo.EmailExistsByOtherUser("test@example.com", 1)
+		exists, err := repo.EmailExistsByOtherUser(t.Context(), "test@example.com", 1)
 
 		assert.NoError(t, err)
 		assert.False(t, exists)
@@ -288,7 +293,7 @@ func TestUserRepository_EmailExistsByOtherUser(t *testing.T) {
 			WithArgs("test@example.com", 1).
 			WillReturnError(fmt.Errorf("database error"))
 
-		exists, err := repo.EmailExistsByOtherUser("test@example.com", 1)
+		exists, err := repo.EmailExistsByOtherUser(t.Context(), "test@example.com", 1)
 
 		assert.Error(t, err)
 		assert.False(t, exists)
@@ -310,16 +315,16 @@ func TestUserRepository_Update(t *testing.T) {
 		newFirstName := "Jane"
 		newLastName := "Smith"
 
-		mock.ExpectExec("UPDATE users SET email = \\?, first_name = \\?, last_name = \\?, updated_at = CURRENT_TIMESTAMP WHERE id = \\?").
+		mock.ExpectExec("UPDATE users SET email = \\?, email_verified_at = NULL, first_name = \\?, last_name = \\?, updated_at = CURRENT_TIMESTAMP WHERE id = \\?").
 			WithArgs(newEmail, newFirstName, newLastName, 1).
 			WillReturnResult(sqlmock.NewResult(0, 1))
 
-		mock.ExpectQuery("SELECT id, email, first_name, last_name, created_at FROM users WHERE id = ?").
+		mock.ExpectQuery("SELECT id, email, first_name, last_name, email_verified_at, created_at FROM users WHERE id = ?").
 			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "first_name", "last_name", "created_at"}).
-				AddRow(1, newEmail, newFirstName, newLastName, now))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "first_name", "last_name", "email_verified_at", "created_at"}).
+				AddRow(1, newEmail, newFirstName, newLastName, nil, now))
 
-		user, err := repo.Update(1, &newEmail, &newFirstName, &newLastName)
+		user, err := repo.Update(t.Context(), 1, &newEmail, &newFirstName, &newLastName)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, user)
@@ -332,16 +337,16 @@ func TestUserRepository_Update(t *testing.T) {
 	t.Run("Update only email", func(t *testing.T) {
 		newEmail := "newemail@example.com"
 
-		mock.ExpectExec("UPDATE users SET email = \\?, updated_at = CURRENT_TIMESTAMP WHERE id = \\?").
+		mock.ExpectExec("UPDATE users SET email = \\?, email_verified_at = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = \\?").
 			WithArgs(newEmail, 1).
 			WillReturnResult(sqlmock.NewResult(0, 1))
 
-		mock.ExpectQuery("SELECT id, email, first_name, last_name, created_at FROM users WHERE id = ?").
+		mock.ExpectQuery("SELECT id, email, first_name, last_name, email_verified_at, created_at FROM users WHERE id = ?").
 			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "first_name", "last_name", "created_at"}).
-				AddRow(1, newEmail, "John", "Doe", now))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "first_name", "last_name", "email_verified_at", "created_at"}).
+				AddRow(1, newEmail, "John", "Doe", nil, now))
 
-		user, err := repo.Update(1, &newEmail, nil, nil)
+		user, err := repo.Update(t.Context(), 1, &newEmail, nil, nil)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, user)
@@ -350,12 +355,12 @@ func TestUserRepository_Update(t *testing.T) {
 	})
 
 	t.Run("No fields to update", func(t *testing.T) {
-		mock.ExpectQuery("SELECT id, email, first_name, last_name, created_at FROM users WHERE id = ?").
+		mock.ExpectQuery("SELECT id, email, first_name, last_name, email_verified_at, created_at FROM users WHERE id = ?").
 			WithArgs(1).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "first_name", "last_name", "created_at"}).
-				AddRow(1, "test@example.com", "John", "Doe", now))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "first_name", "last_name", "email_verified_at", "created_at"}).
+				AddRow(1, "test@example.com", "John", "Doe", nil, now))
 
-		user, err := repo.Update(1, nil, nil, nil)
+		user, err := repo.Update(t.Context(), 1, nil, nil, nil)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, user)
@@ -365,11 +370,11 @@ func TestUserRepository_Update(t *testing.T) {
 	t.Run("Update error", func(t *testing.T) {
 		newEmail := "newemail@example.com"
 
-		mock.ExpectExec("UPDATE users SET email = \\?, updated_at = CURRENT_TIMESTAMP WHERE id = \\?").
+		mock.ExpectExec("UPDATE users SET email = \\?, email_verified_at = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = \\?").
 			WithArgs(newEmail, 1).
 			WillReturnError(fmt.Errorf("database error"))
 
-		user, err := repo.Update(1, &newEmail, nil, nil)
+		user, err := repo.Update(t.Context(), 1, &newEmail, nil, nil)
 
 		assert.Error(t, err)
 		assert.Nil(t, user)
@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type UserTokenRepository struct {
+	db DBTX
+}
+
+func NewUserTokenRepository(db DBTX) *UserTokenRepository {
+	return &UserTokenRepository{db: db}
+}
+
+func (r *UserTokenRepository) Create(ctx context.Context, jti string, userID int, purpose string, expiresAt time.Time) error {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	_, err := r.db.ExecContext(
+		ctx,
+		"INSERT INTO user_tokens (jti, user_id, purpose, expires_at) VALUES (?, ?, ?, ?)",
+		jti, userID, purpose, expiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error creating user token: %w", err)
+	}
+
+	return nil
+}
+
+// Use marks an unused, unexpired token as used and returns the user it was
+// issued to. It reports false when the token is unknown, expired or already
+// used, so each token succeeds only once even under concurrent requests.
+func (r *UserTokenRepository) Use(ctx context.Context, jti, purpose string, now time.Time) (int, bool, error) {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	result, err := r.db.ExecContext(
+		ctx,
+		"UPDATE user_tokens SET used_at = ? WHERE jti = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?",
+		now, jti, purpose, now,
+	)
+	if err != nil {
+		return 0, false, fmt.Errorf("error using user token: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, false, fmt.Errorf("error using user token: %w", err)
+	}
+	if affected == 0 {
+		return 0, false, nil
+	}
+
+	var userID int
+	if err := r.db.QueryRowContext(ctx, "SELECT user_id FROM user_tokens WHERE jti = ?", jti).Scan(&userID); err != nil {
+		return 0, false, fmt.Errorf("error finding user token: %w", err)
+	}
+
+	return userID, true, nil
+}
+
+// InvalidatePending marks the user's outstanding tokens for purpose as used,
+// so only the most recently emailed link works.
+func (r *UserTokenRepository) InvalidatePending(ctx context.Context, userID int, purpose string, now time.Time) error {
+	ctx, cancel := withQueryTimeout(ctx, r.db)
+	defer cancel()
+
+	_, err := r.db.ExecContext(
+		ctx,
+		"UPDATE user_tokens SET used_at = ? WHERE user_id = ? AND purpose = ? AND used_at IS NULL",
+		now, userID, purpose,
+	)
+	if err != nil {
+		return fmt.Errorf("error invalidating user tokens: %w", err)
+	}
+
+	return nil
+}
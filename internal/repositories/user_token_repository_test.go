@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserTokenRepository_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewUserTokenRepository(db)
+	expiresAt := time.Now().Add(time.Hour)
+
+	mock.ExpectExec("INSERT INTO user_tokens").
+		WithArgs("jti", 1, "password_reset", expiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.Create(t.Context(), "jti", 1, "password_reset", expiresAt))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserTokenRepository_Use(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewUserTokenRepository(db)
+	now := time.Now()
+
+	t.Run("Unused token", func(t *testing.T) {
+		mock.ExpectExec("UPDATE user_tokens SET used_at = \\? WHERE jti = \\? AND purpose = \\? AND used_at IS NULL AND expires_at > \\?").
+			WithArgs(now, "jti", "password_reset", now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT user_id FROM user_tokens WHERE jti = ?").
+			WithArgs("jti").
+			WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(7))
+
+		userID, ok, err := repo.Use(t.Context(), "jti", "password_reset", now)
+
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, 7, userID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Used or expired token", func(t *testing.T) {
+		mock.ExpectExec("UPDATE user_tokens SET used_at").
+			WithArgs(now, "jti", "password_reset", now).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		_, ok, err := repo.Use(t.Context(), "jti", "password_reset", now)
+
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUserTokenRepository_InvalidatePending(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewUserTokenRepository(db)
+	now := time.Now()
+
+	mock.ExpectExec("UPDATE user_tokens SET used_at = \\? WHERE user_id = \\? AND purpose = \\? AND used_at IS NULL").
+		WithArgs(now, 1, "email_verification").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	assert.NoError(t, repo.InvalidatePending(t.Context(), 1, "email_verification", now))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
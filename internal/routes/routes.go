@@ -1,7 +1,11 @@
 package routes
 
 import (
+	"time"
+
 	"github.com/Nimirandad/bike-rental-service/internal/handlers"
+	"github.com/Nimirandad/bike-rental-service/internal/metrics"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
 	"github.com/Nimirandad/bike-rental-service/internal/repositories"
 	"github.com/Nimirandad/bike-rental-service/internal/server"
 	"github.com/Nimirandad/bike-rental-service/internal/server/middlewares"
@@ -9,33 +13,145 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 
 	_ "github.com/Nimirandad/bike-rental-service/docs"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
 func RegisterRoutes(s *server.Server) {
+	s.Chi.Use(middlewares.Tracing)
+	s.Chi.Use(middlewares.RequestID)
 	s.Chi.Use(middlewares.CORS)
 	s.Chi.Use(middlewares.LoggingMiddleware)
+	s.Chi.Use(middlewares.Metrics)
 	s.Chi.Use(middleware.Recoverer)
+	s.Chi.NotFound(handlers.NotFound)
+	s.Chi.MethodNotAllowed(handlers.MethodNotAllowed)
 
 	userRepo := repositories.NewUserRepository(s.DB)
 	bikeRepo := repositories.NewBikeRepository(s.DB)
 	adminRepo := repositories.NewAdminRepository(s.DB)
 	rentalRepo := repositories.NewRentalRepository(s.DB)
+	pricePlanRepo := repositories.NewPricePlanRepository(s.DB)
+	bikeTypePriceRepo := repositories.NewBikeTypePriceRepository(s.DB)
+	stationRepo := repositories.NewStationRepository(s.DB)
+	geofenceRepo := repositories.NewGeofenceRepository(s.DB)
+	returnRuleRepo := repositories.NewReturnRuleRepository(s.DB)
+	adminAccountRepo := repositories.NewAdminAccountRepository(s.DB)
+	revokedTokenRepo := repositories.NewRevokedTokenRepository(s.DB)
+	uow := repositories.NewUnitOfWork(s.DB)
 
 	userService := services.NewUserService(userRepo)
-	bikeService := services.NewBikeService(bikeRepo)
-	rentalService := services.NewRentalService(rentalRepo, bikeRepo)
-	adminService := services.NewAdminService(adminRepo)
-	healthService := services.NewHealthService(s.DB)
+	tokenService := services.NewTokenService(
+		revokedTokenRepo,
+		uow,
+		time.Duration(s.Config.AccessTokenTTLMinutes)*time.Minute,
+		time.Duration(s.Config.RefreshTokenTTLDays)*24*time.Hour,
+	)
+	accountService := services.NewAccountService(
+		userRepo,
+		uow,
+		s.Config.AppBaseURL,
+		time.Duration(s.Config.PasswordResetTTLMinutes)*time.Minute,
+		time.Duration(s.Config.EmailVerificationTTLHours)*time.Hour,
+	)
+	bikeService := services.NewBikeService(bikeRepo, s.Config.EBikeMinBatteryPercent)
+	defaultReturnRule := services.DefaultReturnRule(s.Config.ReturnDistanceMode, s.Config.ReturnMaxDistanceKm)
+	rentalService := services.NewRentalService(rentalRepo, uow, s.Config.PausedPricePerMinute, services.StationRules{
+		Policy:          services.ReturnPolicy(s.Config.StationReturnPolicy),
+		OutOfStationFee: s.Config.OutOfStationFee,
+	}, services.ZoneRules{
+		NoParking:     services.NoParkingPolicy(s.Config.NoParkingPolicy),
+		NoParkingFine: s.Config.NoParkingFine,
+	}, defaultReturnRule)
+	reservationService := services.NewReservationService(rentalRepo, uow, s.Config.ReservationMinutes)
+	adminService := services.NewAdminService(adminRepo, pricePlanRepo, bikeTypePriceRepo, geofenceRepo, uow, s.Config.PausedPricePerMinute)
+	pricePlanService := services.NewPricePlanService(pricePlanRepo, uow)
+	bikeTypePriceService := services.NewBikeTypePriceService(bikeTypePriceRepo)
+	stationService := services.NewStationService(stationRepo)
+	geofenceService := services.NewGeofenceService(geofenceRepo, uow)
+	returnRuleService := services.NewReturnRuleService(returnRuleRepo, defaultReturnRule)
+	adminAccountService := services.NewAdminAccountService(adminAccountRepo)
+	healthService := services.NewHealthService(s.DB.DB)
 
-	userHandler := handlers.NewUserHandler(userService)
+	userHandler := handlers.NewUserHandler(userService, tokenService, accountService)
+	accountHandler := handlers.NewAccountHandler(accountService)
 	bikeHandler := handlers.NewBikeHandler(bikeService)
 	rentalHandler := handlers.NewRentalHandler(rentalService)
+	reservationHandler := handlers.NewReservationHandler(reservationService)
 	adminHandler := handlers.NewAdminHandler(adminService)
+	pricePlanHandler := handlers.NewPricePlanHandler(pricePlanService)
+	bikeTypePriceHandler := handlers.NewBikeTypePriceHandler(bikeTypePriceService)
+	stationHandler := handlers.NewStationHandler(stationService)
+	geofenceHandler := handlers.NewGeofenceHandler(geofenceService)
+	returnRuleHandler := handlers.NewReturnRuleHandler(returnRuleService)
+	adminAccountHandler := handlers.NewAdminAccountHandler(adminAccountService)
 	healthHandler := handlers.NewHealthHandler(healthService)
 
+	requireUser := middlewares.RequireUser(tokenService)
+	requireAdmin := middlewares.RequireAdmin(adminAccountService)
+
+	adminRoutes := func(r chi.Router) {
+		r.Post("/login", adminAccountHandler.LoginAdmin)
+
+		r.Route("/admins", func(r chi.Router) {
+			r.Use(requireAdmin(models.PermissionManageAdmins))
+			r.Get("/", adminAccountHandler.GetAllAdmins)
+			r.Post("/", adminAccountHandler.CreateAdmin)
+			r.Patch("/{admin-id}", adminAccountHandler.UpdateAdmin)
+		})
+
+		r.Route("/bikes", func(r chi.Router) {
+			r.With(requireAdmin(models.PermissionViewBikes)).Get("/", adminHandler.GetAllBikes)
+			r.With(requireAdmin(models.PermissionManageBikes)).Post("/", adminHandler.AddBike)
+			r.With(requireAdmin(models.PermissionManageBikes)).Patch("/{bike-id}", adminHandler.UpdateBike)
+		})
+
+		r.Route("/users", func(r chi.Router) {
+			r.With(requireAdmin(models.PermissionViewUsers)).Get("/", adminHandler.GetAllUsers)
+			r.With(requireAdmin(models.PermissionViewUsers)).Get("/{user-id}", adminHandler.GetUserDetails)
+			r.With(requireAdmin(models.PermissionManageUsers)).Patch("/{user-id}", adminHandler.UpdateUser)
+		})
+
+		r.Route("/rentals", func(r chi.Router) {
+			r.With(requireAdmin(models.PermissionViewRentals)).Get("/", adminHandler.GetAllRentals)
+			r.With(requireAdmin(models.PermissionViewRentals)).Get("/{rental-id}", adminHandler.GetRentalDetails)
+			r.With(requireAdmin(models.PermissionManageRentals)).Patch("/{rental-id}", adminHandler.UpdateRental)
+		})
+
+		r.Route("/pricing-plans", func(r chi.Router) {
+			r.With(requireAdmin(models.PermissionViewPricing)).Get("/", pricePlanHandler.GetAllPricePlans)
+			r.With(requireAdmin(models.PermissionManagePricing)).Post("/", pricePlanHandler.CreatePricePlan)
+			r.With(requireAdmin(models.PermissionViewPricing)).Get("/{plan-id}", pricePlanHandler.GetPricePlan)
+			r.With(requireAdmin(models.PermissionManagePricing)).Patch("/{plan-id}", pricePlanHandler.UpdatePricePlan)
+			r.With(requireAdmin(models.PermissionManagePricing)).Delete("/{plan-id}", pricePlanHandler.DeletePricePlan)
+		})
+
+		r.Route("/bike-types", func(r chi.Router) {
+			r.With(requireAdmin(models.PermissionViewPricing)).Get("/", bikeTypePriceHandler.GetBikeTypePrices)
+			r.With(requireAdmin(models.PermissionManagePricing)).Put("/{bike-type}", bikeTypePriceHandler.UpdateBikeTypePrice)
+		})
+
+		r.Route("/stations", func(r chi.Router) {
+			r.With(requireAdmin(models.PermissionViewStations)).Get("/", stationHandler.GetAllStations)
+			r.With(requireAdmin(models.PermissionManageStations)).Post("/", stationHandler.CreateStation)
+			r.With(requireAdmin(models.PermissionViewStations)).Get("/{station-id}", stationHandler.GetStation)
+			r.With(requireAdmin(models.PermissionManageStations)).Patch("/{station-id}", stationHandler.UpdateStation)
+			r.With(requireAdmin(models.PermissionManageStations)).Delete("/{station-id}", stationHandler.DeleteStation)
+		})
+
+		r.Route("/geofences", func(r chi.Router) {
+			r.With(requireAdmin(models.PermissionViewGeofences)).Get("/", geofenceHandler.GetAllGeofences)
+			r.With(requireAdmin(models.PermissionManageGeofences)).Put("/{kind}", geofenceHandler.ReplaceGeofences)
+		})
+
+		r.Route("/return-rule", func(r chi.Router) {
+			r.With(requireAdmin(models.PermissionViewReturnRule)).Get("/", returnRuleHandler.GetReturnRule)
+			r.With(requireAdmin(models.PermissionManageReturnRule)).Put("/", returnRuleHandler.UpdateReturnRule)
+		})
+	}
+
 	s.Chi.Get("/status", healthHandler.CheckHealth)
 	s.Chi.Get("/swagger/*", httpSwagger.WrapHandler)
 
@@ -43,39 +159,74 @@ func RegisterRoutes(s *server.Server) {
 		r.Route("/users", func(r chi.Router) {
 			r.Post("/register", userHandler.RegisterUser)
 			r.Post("/login", userHandler.LoginUser)
-			r.Get("/profile", userHandler.GetUserProfile)
-			r.Patch("/profile", userHandler.UpdateUserProfile)
+			r.Post("/token/refresh", userHandler.RefreshToken)
+			r.Post("/password/forgot", accountHandler.ForgotPassword)
+			r.Post("/password/reset", accountHandler.ResetPassword)
+			r.Post("/email/verify", accountHandler.VerifyEmail)
+
+			r.Group(func(r chi.Router) {
+				r.Use(requireUser)
+				r.Post("/logout", userHandler.Logout)
+				r.Post("/logout-all", userHandler.LogoutAll)
+				r.Post("/email/verify/resend", accountHandler.ResendVerification)
+				r.Get("/profile", userHandler.GetUserProfile)
+				r.Patch("/profile", userHandler.UpdateUserProfile)
+			})
 		})
 
 		r.Route("/bikes", func(r chi.Router) {
+			r.Use(requireUser)
 			r.Get("/available", bikeHandler.GetAvailableBikes)
 		})
 
+		r.Get("/stations", stationHandler.GetStations)
+		r.Get("/geofences", geofenceHandler.GetGeofences)
+
 		r.Route("/rentals", func(r chi.Router) {
+			r.Use(requireUser)
 			r.Post("/start", rentalHandler.StartRental)
 			r.Post("/end", rentalHandler.EndRental)
+			r.Post("/pause", rentalHandler.PauseRental)
+			r.Post("/resume", rentalHandler.ResumeRental)
+			r.Post("/track", rentalHandler.TrackRental)
 			r.Get("/history", rentalHandler.GetRentalHistory)
+			r.Get("/{rental-id}/route", rentalHandler.GetRentalRoute)
+			r.Post("/reserve", reservationHandler.ReserveBike)
+			r.Delete("/reserve", reservationHandler.CancelReservation)
 		})
 
-		r.Route("/admin", func(r chi.Router) {
-			r.Route("/bikes", func(r chi.Router) {
-				r.Get("/", adminHandler.GetAllBikes)
-				r.Post("/", adminHandler.AddBike)
-				r.Patch("/{bike-id}", adminHandler.UpdateBike)
-			})
+		// With a separate admin listener these routes only exist there, so the
+		// public router answers 404 for them.
+		if !s.Config.AdminListenerEnabled() {
+			r.Route("/admin", adminRoutes)
+		}
+	})
 
-			r.Route("/users", func(r chi.Router) {
-				r.Get("/", adminHandler.GetAllUsers)
-				r.Get("/{user-id}", adminHandler.GetUserDetails)
-				r.Patch("/{user-id}", adminHandler.UpdateUser)
-			})
+	if s.Config.AdminListenerEnabled() {
+		s.AdminChi.Use(middlewares.Tracing)
+		s.AdminChi.Use(middlewares.RequestID)
+		s.AdminChi.Use(middlewares.CORS)
+		s.AdminChi.Use(middlewares.LoggingMiddleware)
+		s.AdminChi.Use(middlewares.Metrics)
+		s.AdminChi.Use(middleware.Recoverer)
+		s.AdminChi.NotFound(handlers.NotFound)
+		s.AdminChi.MethodNotAllowed(handlers.MethodNotAllowed)
 
-			r.Route("/rentals", func(r chi.Router) {
-				r.Get("/", adminHandler.GetAllRentals)
-				r.Get("/{rental-id}", adminHandler.GetRentalDetails)
-				r.Patch("/{rental-id}", adminHandler.UpdateRental)
-			})
+		s.AdminChi.Get("/status", healthHandler.CheckHealth)
+		s.AdminChi.Route("/api/v1/admin", adminRoutes)
+	}
 
-		})
-	})
+	metricsHandler := metrics.Handler(
+		collectors.NewDBStatsCollector(s.DB.DB, "bike_rental"),
+		metrics.NewCollector(bikeRepo, rentalRepo),
+	)
+
+	// The admin listener is bound to a private address, so scrapers reach
+	// /metrics there without credentials. On the public port it needs an
+	// admin token like the rest of the admin API.
+	if s.Config.AdminListenerEnabled() {
+		s.AdminChi.Handle("/metrics", metricsHandler)
+	} else {
+		s.Chi.With(requireAdmin(models.PermissionViewMetrics)).Handle("/metrics", metricsHandler)
+	}
 }
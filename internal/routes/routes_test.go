@@ -0,0 +1,318 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Nimirandad/bike-rental-service/internal/config"
+	"github.com/Nimirandad/bike-rental-service/internal/database"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/repositories"
+	"github.com/Nimirandad/bike-rental-service/internal/server"
+	"github.com/Nimirandad/bike-rental-service/internal/services"
+	"github.com/Nimirandad/bike-rental-service/internal/types"
+	"github.com/Nimirandad/bike-rental-service/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestRouter registers every route on a server backed by a fresh SQLite
+// database with a bootstrap superadmin and one admin for each other role.
+func newTestRouter(t *testing.T) *server.Server {
+	t.Helper()
+
+	return newTestRouterWithAdminPort(t, "")
+}
+
+// newTestRouterWithAdminPort builds the router with the admin listener enabled
+// when adminPort is set.
+func newTestRouterWithAdminPort(t *testing.T, adminPort string) *server.Server {
+	t.Helper()
+
+	os.Setenv("JWT_SECRET", "test-secret")
+	t.Cleanup(func() { os.Unsetenv("JWT_SECRET") })
+
+	db, err := database.Connect(database.DriverSQLite, filepath.Join(t.TempDir(), "test.db"), true)
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	adminAccounts := services.NewAdminAccountService(repositories.NewAdminAccountRepository(db))
+	if err := adminAccounts.EnsureBootstrapAdmin(t.Context(), "root@example.com", "secret123"); err != nil {
+		t.Fatalf("failed to create bootstrap admin: %v", err)
+	}
+	for _, role := range []models.AdminRole{models.AdminRoleSupport, models.AdminRoleFleet, models.AdminRoleFinance} {
+		if _, err := adminAccounts.CreateAdmin(t.Context(), string(role)+"@example.com", "secret123", "Test", role); err != nil {
+			t.Fatalf("failed to create %s admin: %v", role, err)
+		}
+	}
+
+	cfg := &config.Config{
+		ReservationMinutes:    config.ReservationMinutes,
+		PausedPricePerMinute:  config.PausedPricePerMinute,
+		AccessTokenTTLMinutes: config.AccessTokenTTLMinutes,
+		RefreshTokenTTLDays:   config.RefreshTokenTTLDays,
+		AdminPort:             adminPort,
+	}
+	srv := server.NewServer(cfg, db)
+	RegisterRoutes(srv)
+	return srv
+}
+
+func serve(srv *server.Server, method, path, authHeader string, body interface{}) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+
+	req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	if authHeader != "" {
+		req.Header.Set("Authorization", authHeader)
+	}
+	w := httptest.NewRecorder()
+	srv.Chi.ServeHTTP(w, req)
+	return w
+}
+
+func riderAuthHeader(t *testing.T) string {
+	t.Helper()
+
+	token, err := utils.GenerateJWT(&models.User{ID: 1, Email: "rider@example.com", FirstName: "John", LastName: "Doe"})
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	return "Bearer " + token
+}
+
+// testAdminIDs are the ids newTestRouter gives the admin of each role.
+var testAdminIDs = map[models.AdminRole]int{
+	models.AdminRoleSuperadmin: 1,
+	models.AdminRoleSupport:    2,
+	models.AdminRoleFleet:      3,
+	models.AdminRoleFinance:    4,
+}
+
+// adminAuthHeader returns a token for the test admin that has role.
+func adminAuthHeader(t *testing.T, role models.AdminRole) string {
+	t.Helper()
+
+	token, err := utils.GenerateAdminJWT(&models.Admin{ID: testAdminIDs[role], Email: string(role) + "@example.com", Name: "Test", Role: role})
+	if err != nil {
+		t.Fatalf("failed to generate admin token: %v", err)
+	}
+	return "Bearer " + token
+}
+
+func TestRoutes_RiderRoutesRequireUserToken(t *testing.T) {
+	srv := newTestRouter(t)
+
+	assert.Equal(t, http.StatusUnauthorized, serve(srv, http.MethodGet, "/api/v1/rentals/history", "", nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(srv, http.MethodGet, "/api/v1/rentals/history", "Bearer invalid", nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(srv, http.MethodGet, "/api/v1/rentals/history", adminAuthHeader(t, models.AdminRoleSuperadmin), nil).Code)
+	assert.Equal(t, http.StatusOK, serve(srv, http.MethodGet, "/api/v1/rentals/history", riderAuthHeader(t), nil).Code)
+	assert.Equal(t, http.StatusOK, serve(srv, http.MethodGet, "/api/v1/bikes/available", riderAuthHeader(t), nil).Code)
+}
+
+func TestRoutes_ErrorsAreProblems(t *testing.T) {
+	srv := newTestRouter(t)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		auth   string
+		status int
+		code   string
+	}{
+		{"Missing token", http.MethodGet, "/api/v1/rentals/history", "", http.StatusUnauthorized, "missing_token"},
+		{"Unknown user", http.MethodGet, "/api/v1/admin/users/999", adminAuthHeader(t, models.AdminRoleSuperadmin), http.StatusNotFound, "user_not_found"},
+		{"Role lacks permission", http.MethodGet, "/api/v1/admin/admins", adminAuthHeader(t, models.AdminRoleSupport), http.StatusForbidden, "permission_denied"},
+		{"Unknown route", http.MethodGet, "/api/v1/nowhere", "", http.StatusNotFound, "not_found"},
+		{"Wrong method", http.MethodDelete, "/api/v1/users/login", "", http.StatusMethodNotAllowed, "method_not_allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(srv, tt.method, tt.path, tt.auth, nil)
+
+			var problem types.Problem
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, types.ProblemContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.status, problem.Status)
+			assert.Equal(t, tt.code, problem.Code)
+		})
+	}
+}
+
+func TestRoutes_PublicRoutesSkipAuth(t *testing.T) {
+	srv := newTestRouter(t)
+
+	w := serve(srv, http.MethodPost, "/api/v1/users/register", "", map[string]string{
+		"email": "rider@example.com", "password": "secret123", "first_name": "John", "last_name": "Doe",
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = serve(srv, http.MethodPost, "/api/v1/users/login", "", map[string]string{"email": "rider@example.com", "password": "secret123"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var login struct {
+		Data struct {
+			Token string `json:"token"`
+		} `json:"data"`
+	}
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&login))
+
+	w = serve(srv, http.MethodGet, "/api/v1/users/profile", "Bearer "+login.Data.Token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRoutes_RefreshAndLogout(t *testing.T) {
+	srv := newTestRouter(t)
+
+	serve(srv, http.MethodPost, "/api/v1/users/register", "", map[string]string{
+		"email": "rider@example.com", "password": "secret123", "first_name": "John", "last_name": "Doe",
+	})
+
+	login := func(w *httptest.ResponseRecorder) types.LoginResponse {
+		var response struct {
+			Data types.LoginResponse `json:"data"`
+		}
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		return response.Data
+	}
+
+	first := login(serve(srv, http.MethodPost, "/api/v1/users/login", "", map[string]string{"email": "rider@example.com", "password": "secret123"}))
+	assert.NotEmpty(t, first.RefreshToken)
+
+	second := login(serve(srv, http.MethodPost, "/api/v1/users/token/refresh", "", map[string]string{"refresh_token": first.RefreshToken}))
+	assert.Equal(t, http.StatusOK, serve(srv, http.MethodGet, "/api/v1/users/profile", "Bearer "+second.Token, nil).Code)
+
+	w := serve(srv, http.MethodPost, "/api/v1/users/logout", "Bearer "+second.Token, map[string]string{"refresh_token": second.RefreshToken})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	assert.Equal(t, http.StatusUnauthorized, serve(srv, http.MethodGet, "/api/v1/users/profile", "Bearer "+second.Token, nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(srv, http.MethodPost, "/api/v1/users/token/refresh", "", map[string]string{"refresh_token": second.RefreshToken}).Code)
+}
+
+func TestRoutes_AdminRoutesCheckRole(t *testing.T) {
+	srv := newTestRouter(t)
+
+	assert.Equal(t, http.StatusUnauthorized, serve(srv, http.MethodGet, "/api/v1/admin/users", "", nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(srv, http.MethodGet, "/api/v1/admin/users", riderAuthHeader(t), nil).Code)
+	assert.Equal(t, http.StatusForbidden, serve(srv, http.MethodGet, "/api/v1/admin/users", adminAuthHeader(t, models.AdminRoleFleet), nil).Code)
+	assert.Equal(t, http.StatusOK, serve(srv, http.MethodGet, "/api/v1/admin/users", adminAuthHeader(t, models.AdminRoleSupport), nil).Code)
+
+	body := map[string]interface{}{"latitude": 51.5, "longitude": -0.12}
+	assert.Equal(t, http.StatusForbidden, serve(srv, http.MethodPost, "/api/v1/admin/bikes", adminAuthHeader(t, models.AdminRoleSupport), body).Code)
+	assert.Equal(t, http.StatusForbidden, serve(srv, http.MethodPost, "/api/v1/admin/bikes", adminAuthHeader(t, models.AdminRoleFinance), body).Code)
+	assert.Equal(t, http.StatusOK, serve(srv, http.MethodPost, "/api/v1/admin/bikes", adminAuthHeader(t, models.AdminRoleFleet), body).Code)
+
+	plan := map[string]interface{}{"name": "Standard", "price_per_minute": 0.2}
+	assert.Equal(t, http.StatusForbidden, serve(srv, http.MethodPost, "/api/v1/admin/pricing-plans", adminAuthHeader(t, models.AdminRoleFleet), plan).Code)
+	assert.Equal(t, http.StatusOK, serve(srv, http.MethodPost, "/api/v1/admin/pricing-plans", adminAuthHeader(t, models.AdminRoleFinance), plan).Code)
+}
+
+func TestRoutes_AdminLoginAndAccountManagement(t *testing.T) {
+	srv := newTestRouter(t)
+
+	w := serve(srv, http.MethodPost, "/api/v1/admin/login", "", map[string]string{"email": "root@example.com", "password": "secret123"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var login struct {
+		Data struct {
+			Token string `json:"token"`
+		} `json:"data"`
+	}
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&login))
+	rootHeader := "Bearer " + login.Data.Token
+
+	assert.Equal(t, http.StatusOK, serve(srv, http.MethodGet, "/api/v1/admin/admins", rootHeader, nil).Code)
+	assert.Equal(t, http.StatusForbidden, serve(srv, http.MethodGet, "/api/v1/admin/admins", adminAuthHeader(t, models.AdminRoleFinance), nil).Code)
+
+	w = serve(srv, http.MethodPatch, "/api/v1/admin/admins/1", rootHeader, map[string]string{"role": "support"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestRoutes_AdminChangesApplyToIssuedTokens(t *testing.T) {
+	srv := newTestRouter(t)
+
+	fleetHeader := adminAuthHeader(t, models.AdminRoleFleet)
+	rootHeader := adminAuthHeader(t, models.AdminRoleSuperadmin)
+	fleetPath := "/api/v1/admin/admins/3"
+
+	assert.Equal(t, http.StatusOK, serve(srv, http.MethodGet, "/api/v1/admin/bikes", fleetHeader, nil).Code)
+
+	assert.Equal(t, http.StatusOK, serve(srv, http.MethodPatch, fleetPath, rootHeader, map[string]string{"role": "finance"}).Code)
+	body := map[string]interface{}{"latitude": 51.5, "longitude": -0.12}
+	assert.Equal(t, http.StatusForbidden, serve(srv, http.MethodPost, "/api/v1/admin/bikes", fleetHeader, body).Code, "the demotion applies to a token issued before it")
+
+	assert.Equal(t, http.StatusOK, serve(srv, http.MethodPatch, fleetPath, rootHeader, map[string]bool{"disabled": true}).Code)
+	w := serve(srv, http.MethodGet, "/api/v1/admin/bikes", fleetHeader, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	var problem types.Problem
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+	assert.Equal(t, "admin_disabled", problem.Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(srv, http.MethodPost, "/api/v1/admin/login", "", map[string]string{"email": "fleet@example.com", "password": "secret123"}).Code)
+
+	assert.Equal(t, http.StatusOK, serve(srv, http.MethodPatch, fleetPath, rootHeader, map[string]bool{"disabled": false}).Code)
+	assert.Equal(t, http.StatusOK, serve(srv, http.MethodGet, "/api/v1/admin/bikes", fleetHeader, nil).Code)
+}
+
+func TestRoutes_AdminListenerServesAdminRoutes(t *testing.T) {
+	srv := newTestRouterWithAdminPort(t, "9090")
+
+	login := map[string]string{"email": "root@example.com", "password": "secret123"}
+	assert.Equal(t, http.StatusNotFound, serve(srv, http.MethodPost, "/api/v1/admin/login", "", login).Code)
+	assert.Equal(t, http.StatusNotFound, serve(srv, http.MethodGet, "/api/v1/admin/users", adminAuthHeader(t, models.AdminRoleSupport), nil).Code)
+	assert.Equal(t, http.StatusOK, serve(srv, http.MethodGet, "/api/v1/bikes/available", riderAuthHeader(t), nil).Code)
+
+	serveAdmin := func(method, path, authHeader string, body interface{}) int {
+		var payload []byte
+		if body != nil {
+			payload, _ = json.Marshal(body)
+		}
+		req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+		if authHeader != "" {
+			req.Header.Set("Authorization", authHeader)
+		}
+		w := httptest.NewRecorder()
+		srv.AdminChi.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serveAdmin(http.MethodPost, "/api/v1/admin/login", "", login))
+	assert.Equal(t, http.StatusOK, serveAdmin(http.MethodGet, "/api/v1/admin/users", adminAuthHeader(t, models.AdminRoleSupport), nil))
+	assert.Equal(t, http.StatusForbidden, serveAdmin(http.MethodGet, "/api/v1/admin/users", adminAuthHeader(t, models.AdminRoleFleet), nil))
+	assert.Equal(t, http.StatusNotFound, serveAdmin(http.MethodGet, "/api/v1/bikes/available", riderAuthHeader(t), nil))
+
+	assert.Equal(t, http.StatusNotFound, serve(srv, http.MethodGet, "/metrics", "", nil).Code)
+	assert.Equal(t, http.StatusOK, serveAdmin(http.MethodGet, "/metrics", "", nil))
+}
+
+func TestRoutes_MetricsEndpoint(t *testing.T) {
+	srv := newTestRouter(t)
+
+	serve(srv, http.MethodGet, "/api/v1/bikes/available", riderAuthHeader(t), nil)
+
+	assert.Equal(t, http.StatusUnauthorized, serve(srv, http.MethodGet, "/metrics", "", nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(srv, http.MethodGet, "/metrics", riderAuthHeader(t), nil).Code)
+	assert.Equal(t, http.StatusForbidden, serve(srv, http.MethodGet, "/metrics", adminAuthHeader(t, models.AdminRoleSupport), nil).Code)
+	assert.Equal(t, http.StatusOK, serve(srv, http.MethodGet, "/metrics", adminAuthHeader(t, models.AdminRoleFinance), nil).Code)
+
+	w := serve(srv, http.MethodGet, "/metrics", adminAuthHeader(t, models.AdminRoleFleet), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	body := w.Body.String()
+	assert.Contains(t, body, `bike_rental_http_requests_total{method="GET",route="/api/v1/bikes/available",status="200"}`)
+	assert.Contains(t, body, "bike_rental_http_request_duration_seconds_bucket")
+	assert.Contains(t, body, "bike_rental_bikes_available 0")
+	assert.Contains(t, body, "bike_rental_rentals_active 0")
+	assert.Contains(t, body, `go_sql_max_open_connections{db_name="bike_rental"}`)
+}
@@ -0,0 +1,120 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/Nimirandad/bike-rental-service/internal/auth"
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/logger"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/types"
+	"github.com/Nimirandad/bike-rental-service/internal/utils"
+)
+
+// TokenRevocations reports whether a rider access token was revoked before
+// its expiry, e.g. by logging out.
+type TokenRevocations interface {
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// RequireUser rejects requests without a valid, unrevoked rider token and
+// stores the rider's claims in the request context for auth.UserFromContext.
+func RequireUser(revocations TokenRevocations) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			log := logger.FromContext(r.Context())
+
+			authHeader := r.Header.Get("Authorization")
+			if authHeader == "" {
+				log.Warn().Str("path", r.URL.Path).Msg("Missing authorization header")
+				types.WriteProblem(w, constants.ErrMissingToken, "")
+				return
+			}
+
+			tokenString, err := utils.ExtractTokenFromHeader(authHeader)
+			if err != nil {
+				log.Warn().Err(err).Str("path", r.URL.Path).Msg("Invalid authorization header format")
+				types.WriteProblem(w, constants.ErrInvalidToken, "")
+				return
+			}
+
+			claims, err := utils.ValidateJWT(tokenString)
+			if err != nil || claims.ID == "" || claims.ExpiresAt == nil {
+				log.Warn().Err(err).Str("path", r.URL.Path).Msg("Invalid or expired token")
+				types.WriteProblem(w, constants.ErrInvalidToken, "")
+				return
+			}
+
+			revoked, err := revocations.IsRevoked(r.Context(), claims.ID)
+			if err != nil {
+				log.Error().Err(err).Int("user_id", claims.Sub).Msg("Failed to check token revocation")
+				types.WriteProblem(w, err, "Internal server error")
+				return
+			}
+			if revoked {
+				log.Warn().Int("user_id", claims.Sub).Str("path", r.URL.Path).Msg("Revoked token used")
+				types.WriteProblem(w, constants.ErrTokenRevoked, "")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithUser(r.Context(), claims)))
+		})
+	}
+}
+
+// ActiveAdmins loads an admin account as it is now, failing with
+// constants.ErrAdminDisabled for disabled accounts.
+type ActiveAdmins interface {
+	GetActiveAdmin(ctx context.Context, adminID int) (*models.Admin, error)
+}
+
+// RequireAdmin returns a middleware factory for admin routes. The middleware
+// rejects requests without a valid admin token, from admins that no longer
+// exist or are disabled, or whose current role does not grant permission.
+// The role in the token is only informative: it is replaced by the one loaded
+// from admins, so demotions apply on the next request. The admin's claims are
+// stored in the request context for auth.AdminFromContext.
+func RequireAdmin(admins ActiveAdmins) func(permission models.Permission) func(http.Handler) http.Handler {
+	return func(permission models.Permission) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				log := logger.FromContext(r.Context())
+
+				claims, err := utils.ValidateAdminToken(r.Header.Get("Authorization"))
+				if err != nil {
+					log.Warn().Err(err).Str("path", r.URL.Path).Msg("Unauthorized admin access attempt")
+					w.Header().Set("WWW-Authenticate", `Bearer realm="Admin Access"`)
+					types.WriteProblem(w, constants.ErrInvalidToken, "")
+					return
+				}
+
+				admin, err := admins.GetActiveAdmin(r.Context(), claims.Sub)
+				switch {
+				case errors.Is(err, constants.ErrAdminNotFound):
+					log.Warn().Int("admin_id", claims.Sub).Str("path", r.URL.Path).Msg("Token of a deleted admin used")
+					types.WriteProblem(w, constants.ErrInvalidToken, "")
+					return
+				case errors.Is(err, constants.ErrAdminDisabled):
+					log.Warn().Int("admin_id", claims.Sub).Str("path", r.URL.Path).Msg("Disabled admin access attempt")
+					types.WriteProblem(w, constants.ErrAdminDisabled, "")
+					return
+				case err != nil:
+					log.Error().Err(err).Int("admin_id", claims.Sub).Msg("Failed to load admin")
+					types.WriteProblem(w, err, "Internal server error")
+					return
+				}
+				claims.Role = admin.Role
+
+				if !claims.Role.Can(permission) {
+					log.Warn().Int("admin_id", claims.Sub).Str("role", string(claims.Role)).Str("permission", string(permission)).Msg("Admin lacks permission")
+					types.WriteProblem(w, constants.ErrPermissionDenied, "")
+					return
+				}
+
+				next.ServeHTTP(w, r.WithContext(auth.WithAdmin(r.Context(), claims)))
+			})
+		}
+	}
+}
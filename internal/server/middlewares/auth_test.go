@@ -0,0 +1,170 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/auth"
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/utils"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// revokedJTIs is a TokenRevocations backed by a set of revoked token ids.
+type revokedJTIs map[string]bool
+
+func (r revokedJTIs) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	if r["error"] {
+		return false, errors.New("database error")
+	}
+	return r[jti], nil
+}
+
+func TestRequireUser(t *testing.T) {
+	os.Setenv("JWT_SECRET", "test-secret")
+	defer os.Unsetenv("JWT_SECRET")
+
+	userToken, _ := utils.GenerateJWT(&models.User{ID: 7, Email: "rider@example.com"})
+	revokedToken, revokedClaims, _ := utils.GenerateAccessToken(&models.User{ID: 7}, time.Minute)
+	noJTIToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, &utils.JWTClaims{
+		Sub:              7,
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))},
+	}).SignedString([]byte("test-secret"))
+	adminToken, _ := utils.GenerateAdminJWT(&models.Admin{ID: 1, Email: "root@example.com", Role: models.AdminRoleSuperadmin})
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+	}{
+		{"Missing header", "", http.StatusUnauthorized},
+		{"Invalid format", "InvalidFormat", http.StatusUnauthorized},
+		{"Invalid token", "Bearer invalid", http.StatusUnauthorized},
+		{"Admin token", "Bearer " + adminToken, http.StatusUnauthorized},
+		{"Token without jti", "Bearer " + noJTIToken, http.StatusUnauthorized},
+		{"Revoked token", "Bearer " + revokedToken, http.StatusUnauthorized},
+		{"Rider token", "Bearer " + userToken, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotUserID int
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				claims, ok := auth.UserFromContext(r.Context())
+				assert.True(t, ok)
+				gotUserID = claims.Sub
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/rentals/history", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			RequireUser(revokedJTIs{revokedClaims.ID: true})(next).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, 7, gotUserID)
+			}
+		})
+	}
+}
+
+func TestRequireUser_RevocationCheckFails(t *testing.T) {
+	os.Setenv("JWT_SECRET", "test-secret")
+	defer os.Unsetenv("JWT_SECRET")
+
+	userToken, _ := utils.GenerateJWT(&models.User{ID: 7})
+
+	req := httptest.NewRequest(http.MethodGet, "/rentals/history", nil)
+	req.Header.Set("Authorization", "Bearer "+userToken)
+	w := httptest.NewRecorder()
+
+	RequireUser(revokedJTIs{"error": true})(http.NotFoundHandler()).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+// adminAccounts is an ActiveAdmins backed by the current role of each admin.
+// Admins missing from the map do not exist.
+type adminAccounts map[int]models.AdminRole
+
+func (a adminAccounts) GetActiveAdmin(ctx context.Context, adminID int) (*models.Admin, error) {
+	role, ok := a[adminID]
+	switch {
+	case !ok:
+		return nil, constants.ErrAdminNotFound
+	case role == "disabled":
+		return nil, constants.ErrAdminDisabled
+	case role == "error":
+		return nil, errors.New("database error")
+	}
+	return &models.Admin{ID: adminID, Role: role}, nil
+}
+
+func TestRequireAdmin(t *testing.T) {
+	os.Setenv("JWT_SECRET", "test-secret")
+	defer os.Unsetenv("JWT_SECRET")
+
+	adminToken := func(adminID int, role models.AdminRole) string {
+		token, _ := utils.GenerateAdminJWT(&models.Admin{ID: adminID, Email: "ops@example.com", Role: role})
+		return "Bearer " + token
+	}
+	userToken, _ := utils.GenerateJWT(&models.User{ID: 7, Email: "rider@example.com"})
+
+	admins := adminAccounts{
+		1: models.AdminRoleSupport,
+		2: models.AdminRoleFleet,
+		3: models.AdminRoleSuperadmin,
+		4: "disabled",
+		5: "error",
+	}
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+		wantRole   models.AdminRole
+	}{
+		{"Missing header", "", http.StatusUnauthorized, ""},
+		{"Rider token", "Bearer " + userToken, http.StatusUnauthorized, ""},
+		{"Role without permission", adminToken(1, models.AdminRoleSupport), http.StatusForbidden, ""},
+		{"Role with permission", adminToken(2, models.AdminRoleFleet), http.StatusOK, models.AdminRoleFleet},
+		{"Superadmin", adminToken(3, models.AdminRoleSuperadmin), http.StatusOK, models.AdminRoleSuperadmin},
+		{"Demoted since login", adminToken(1, models.AdminRoleSuperadmin), http.StatusForbidden, ""},
+		{"Promoted since login", adminToken(2, models.AdminRoleSupport), http.StatusOK, models.AdminRoleFleet},
+		{"Disabled admin", adminToken(4, models.AdminRoleSuperadmin), http.StatusUnauthorized, ""},
+		{"Deleted admin", adminToken(9, models.AdminRoleSuperadmin), http.StatusUnauthorized, ""},
+		{"Lookup fails", adminToken(5, models.AdminRoleSuperadmin), http.StatusInternalServerError, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotRole models.AdminRole
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				claims, ok := auth.AdminFromContext(r.Context())
+				assert.True(t, ok)
+				gotRole = claims.Role
+			})
+
+			req := httptest.NewRequest(http.MethodPatch, "/admin/bikes/1", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			RequireAdmin(admins)(models.PermissionManageBikes)(next).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantRole, gotRole)
+		})
+	}
+}
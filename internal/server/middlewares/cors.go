@@ -10,8 +10,8 @@ func CORS(next http.Handler) http.Handler {
     return cors.Handler(cors.Options{
         AllowedOrigins:   []string{"https://*", "http://*"},
         AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-        AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-        ExposedHeaders:   []string{"Link"},
+        AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Request-ID", "traceparent", "tracestate"},
+        ExposedHeaders:   []string{"Link", "X-Request-ID"},
         AllowCredentials: false,
         MaxAge:           300,
     })(next)
@@ -33,7 +33,7 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 			statusCode:     http.StatusOK,
 		}
 
-		log := logger.Get()
+		log := logger.FromContext(r.Context())
 
 		log.Info().
 			Str("method", r.Method).
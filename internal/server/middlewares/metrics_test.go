@@ -0,0 +1,32 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Nimirandad/bike-rental-service/internal/metrics"
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics_LabelsRequestsByRoutePattern(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(Metrics)
+	r.Get("/bikes/{bike-id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	found := metrics.HTTPRequests.WithLabelValues(http.MethodGet, "/bikes/{bike-id}", "418")
+	unmatched := metrics.HTTPRequests.WithLabelValues(http.MethodGet, "unmatched", "404")
+	foundBefore := testutil.ToFloat64(found)
+	unmatchedBefore := testutil.ToFloat64(unmatched)
+
+	for _, path := range []string{"/bikes/1", "/bikes/2", "/nowhere"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, foundBefore+2, testutil.ToFloat64(found))
+	assert.Equal(t, unmatchedBefore+1, testutil.ToFloat64(unmatched))
+}
@@ -0,0 +1,62 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/Nimirandad/bike-rental-service/internal/logger"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLength = 128
+
+// RequestID tags every request with an ID, reusing the caller's X-Request-ID
+// when it is a reasonable value and generating one otherwise. The ID is echoed
+// in the response and added, with the trace ID when there is one, to a logger
+// stored on the request context for logger.FromContext.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+
+		fields := logger.Get().With().Str("request_id", requestID)
+
+		span := trace.SpanFromContext(r.Context())
+		if spanCtx := span.SpanContext(); spanCtx.HasTraceID() {
+			fields = fields.Str("trace_id", spanCtx.TraceID().String())
+			span.SetAttributes(attribute.String("http.request_id", requestID))
+		}
+
+		ctx := logger.WithContext(r.Context(), fields.Logger())
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// validRequestID accepts IDs of printable, header-safe characters so a client
+// cannot inject control characters or huge values into the logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		isAlphanumeric := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+		if !isAlphanumeric && c != '-' && c != '_' && c != '.' && c != ':' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
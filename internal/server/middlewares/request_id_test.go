@@ -0,0 +1,66 @@
+package middlewares
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Nimirandad/bike-rental-service/internal/logger"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// captureLogs sends the global logger to a buffer for the rest of the test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	previous := log.Logger
+	log.Logger = zerolog.New(&buf)
+	t.Cleanup(func() { log.Logger = previous })
+	return &buf
+}
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		wantSame bool
+	}{
+		{"Generated when missing", "", false},
+		{"Reuses caller ID", "req-42_a.b:c", true},
+		{"Replaces ID with unsafe characters", "bad id\nwith newline", false},
+		{"Replaces oversized ID", strings.Repeat("a", 129), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLogs(t)
+
+			handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				log := logger.FromContext(r.Context())
+				log.Info().Msg("handled")
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			requestID := w.Header().Get(RequestIDHeader)
+			assert.NotEmpty(t, requestID)
+			if tt.wantSame {
+				assert.Equal(t, tt.incoming, requestID)
+			} else {
+				assert.NotEqual(t, tt.incoming, requestID)
+				assert.Len(t, requestID, 32)
+			}
+			assert.Contains(t, logs.String(), `"request_id":"`+requestID+`"`)
+		})
+	}
+}
@@ -1,10 +1,14 @@
 package server
 
 import (
-	"database/sql"
+	"context"
+	"errors"
+	"net"
 	"net/http"
+	"time"
 
 	"github.com/Nimirandad/bike-rental-service/internal/config"
+	"github.com/Nimirandad/bike-rental-service/internal/database"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -13,22 +17,68 @@ type Server struct {
 	Chi      *chi.Mux
 	AdminChi *chi.Mux
 	Config   *config.Config
-	DB *sql.DB
+	DB       *database.DB
+
+	httpServer  *http.Server
+	adminServer *http.Server
 }
 
-func NewServer(cfg *config.Config, db *sql.DB) *Server {
-	return &Server{
+func NewServer(cfg *config.Config, db *database.DB) *Server {
+	s := &Server{
 		Chi:      chi.NewRouter(),
 		AdminChi: chi.NewRouter(),
 		Config:   cfg,
 		DB:       db,
 	}
+	s.httpServer = s.newHTTPServer(s.Chi)
+	s.adminServer = s.newHTTPServer(s.AdminChi)
+
+	return s
+}
+
+// newHTTPServer applies the configured timeouts so slow clients cannot hold
+// connections open indefinitely.
+func (s *Server) newHTTPServer(handler http.Handler) *http.Server {
+	return &http.Server{
+		Handler:           handler,
+		ReadTimeout:       time.Duration(s.Config.HTTPReadTimeoutSeconds) * time.Second,
+		ReadHeaderTimeout: time.Duration(s.Config.HTTPReadTimeoutSeconds) * time.Second,
+		WriteTimeout:      time.Duration(s.Config.HTTPWriteTimeoutSeconds) * time.Second,
+		IdleTimeout:       time.Duration(s.Config.HTTPIdleTimeoutSeconds) * time.Second,
+	}
+}
+
+// Start serves the public API on port, on every interface, until Shutdown is
+// called. It returns nil after a graceful shutdown.
+func (s *Server) Start(port string) error {
+	return listenAndServe(s.httpServer, "", port)
 }
 
-func (server *Server) Start(port string) error {
-	return http.ListenAndServe(":" + port, server.Chi)
+// StartAdmin serves the admin router on port, bound to ADMIN_BIND_ADDR, until
+// Shutdown is called.
+func (s *Server) StartAdmin(port string) error {
+	return listenAndServe(s.adminServer, s.Config.AdminBindAddr, port)
 }
 
-func (server *Server) StartAdmin(port string) error {
-	return http.ListenAndServe(":" + port, server.AdminChi)
-}
\ No newline at end of file
+// Serve serves the public API on an existing listener.
+func (s *Server) Serve(listener net.Listener) error {
+	return ignoreClosed(s.httpServer.Serve(listener))
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests to
+// finish, or for ctx to expire.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return errors.Join(s.httpServer.Shutdown(ctx), s.adminServer.Shutdown(ctx))
+}
+
+func listenAndServe(srv *http.Server, host, port string) error {
+	srv.Addr = net.JoinHostPort(host, port)
+	return ignoreClosed(srv.ListenAndServe())
+}
+
+func ignoreClosed(err error) error {
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
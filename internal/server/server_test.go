@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestConfig() *config.Config {
+	return &config.Config{
+		HTTPReadTimeoutSeconds:  config.HTTPReadTimeoutSeconds,
+		HTTPWriteTimeoutSeconds: config.HTTPWriteTimeoutSeconds,
+		HTTPIdleTimeoutSeconds:  config.HTTPIdleTimeoutSeconds,
+	}
+}
+
+func TestNewServer_AppliesTimeouts(t *testing.T) {
+	cfg := &config.Config{HTTPReadTimeoutSeconds: 5, HTTPWriteTimeoutSeconds: 10, HTTPIdleTimeoutSeconds: 20}
+
+	srv := NewServer(cfg, nil)
+
+	assert.Equal(t, 5*time.Second, srv.httpServer.ReadTimeout)
+	assert.Equal(t, 10*time.Second, srv.httpServer.WriteTimeout)
+	assert.Equal(t, 20*time.Second, srv.httpServer.IdleTimeout)
+	assert.Equal(t, 10*time.Second, srv.adminServer.WriteTimeout)
+}
+
+func TestShutdown_CompletesInFlightRequest(t *testing.T) {
+	srv := NewServer(newTestConfig(), nil)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	srv.Chi.Get("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.Write([]byte("done"))
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- srv.Serve(listener) }()
+
+	type result struct {
+		status int
+		body   string
+		err    error
+	}
+	responses := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String() + "/slow")
+		if err != nil {
+			responses <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		responses <- result{status: resp.StatusCode, body: string(body), err: err}
+	}()
+	<-started
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdownErr <- srv.Shutdown(ctx)
+	}()
+
+	// Shutdown must wait for the handler instead of returning straight away.
+	select {
+	case err := <-shutdownErr:
+		t.Fatalf("shutdown returned before the request finished: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+
+	res := <-responses
+	assert.NoError(t, res.err)
+	assert.Equal(t, http.StatusOK, res.status)
+	assert.Equal(t, "done", res.body)
+	assert.NoError(t, <-shutdownErr)
+	assert.NoError(t, <-serveErr)
+}
+
+func TestShutdown_RefusesNewConnections(t *testing.T) {
+	srv := NewServer(newTestConfig(), nil)
+	srv.Chi.Get("/ping", func(w http.ResponseWriter, r *http.Request) {})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	addr := listener.Addr().String()
+
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- srv.Serve(listener) }()
+
+	assert.NoError(t, srv.Shutdown(context.Background()))
+	assert.NoError(t, <-serveErr)
+
+	_, err = http.Get("http://" + addr + "/ping")
+	assert.Error(t, err)
+}
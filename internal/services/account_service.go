@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/repositories"
+	"github.com/Nimirandad/bike-rental-service/internal/utils"
+)
+
+type AccountUserRepository interface {
+	GetByID(ctx context.Context, userID int) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+}
+
+// AccountService handles the account flows that go through email: verifying
+// the user's address and resetting a forgotten password. Emails are queued in
+// the outbox in the same transaction that stores the token they carry.
+type AccountService struct {
+	userRepo        AccountUserRepository
+	uow             UnitOfWork
+	appBaseURL      string
+	resetTTL        time.Duration
+	verificationTTL time.Duration
+	now             func() time.Time
+}
+
+// NewAccountService builds the service. appBaseURL is where the links in the
+// emails point to; the page there posts the token back to the API.
+func NewAccountService(userRepo *repositories.UserRepository, uow *repositories.UnitOfWork, appBaseURL string, resetTTL, verificationTTL time.Duration) *AccountService {
+	return &AccountService{
+		userRepo:        userRepo,
+		uow:             uow,
+		appBaseURL:      strings.TrimRight(appBaseURL, "/"),
+		resetTTL:        resetTTL,
+		verificationTTL: verificationTTL,
+		now:             utcNow,
+	}
+}
+
+// RequestEmailVerification emails the user a link to confirm their address.
+// Earlier links stop working.
+func (s *AccountService) RequestEmailVerification(ctx context.Context, userID int) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return constants.ErrEmailAlreadyVerified
+	}
+
+	return s.sendToken(ctx, user, models.UserTokenPurposeEmailVerification, s.verificationTTL, "/verify-email", func(link string) (string, string) {
+		return "Confirm your email address", fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThe link expires in %s.\n",
+			user.FirstName, link, formatTTL(s.verificationTTL),
+		)
+	})
+}
+
+// VerifyEmail marks the address of the token's user as verified.
+func (s *AccountService) VerifyEmail(ctx context.Context, token string) error {
+	return s.useToken(ctx, token, models.UserTokenPurposeEmailVerification, func(tx *repositories.Tx, userID int, now time.Time) error {
+		return tx.Users.MarkEmailVerified(ctx, userID, now)
+	})
+}
+
+// RequestPasswordReset emails a reset link when email belongs to a user. An
+// unknown email is not an error, so the endpoint cannot be used to find out
+// which addresses are registered.
+func (s *AccountService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if errors.Is(err, constants.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.sendToken(ctx, user, models.UserTokenPurposePasswordReset, s.resetTTL, "/reset-password", func(link string) (string, string) {
+		return "Reset your password", fmt.Sprintf(
+			"Hi %s,\n\nSomeone asked to reset the password of your account. If it was you, open the link below to choose a new one:\n\n%s\n\nThe link expires in %s. If you did not ask for this, you can ignore this email.\n",
+			user.FirstName, link, formatTTL(s.resetTTL),
+		)
+	})
+}
+
+// ResetPassword sets a new password for the token's user and logs them out
+// of every session, since the old password may have been compromised.
+func (s *AccountService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	return s.useToken(ctx, token, models.UserTokenPurposePasswordReset, func(tx *repositories.Tx, userID int, now time.Time) error {
+		if err := tx.Users.UpdatePassword(ctx, userID, hashedPassword); err != nil {
+			return err
+		}
+		if err := tx.RevokedTokens.RevokeUserAccessTokens(ctx, userID, now); err != nil {
+			return err
+		}
+		return tx.RefreshTokens.RevokeAllForUser(ctx, userID, now)
+	})
+}
+
+// sendToken stores a new single-use token for purpose and queues the email
+// built by compose, which gets the link to path on the app with the token.
+func (s *AccountService) sendToken(ctx context.Context, user *models.User, purpose string, ttl time.Duration, path string, compose func(link string) (string, string)) error {
+	token, claims, err := utils.GenerateActionToken(user.ID, purpose, ttl)
+	if err != nil {
+		return err
+	}
+
+	subject, body := compose(s.appBaseURL + path + "?token=" + url.QueryEscape(token))
+
+	return s.uow.WithTx(ctx, func(tx *repositories.Tx) error {
+		now := s.now()
+
+		if err := tx.UserTokens.InvalidatePending(ctx, user.ID, purpose, now); err != nil {
+			return err
+		}
+		if err := tx.UserTokens.Create(ctx, claims.ID, user.ID, purpose, claims.ExpiresAt.Time.UTC()); err != nil {
+			return err
+		}
+
+		return tx.Outbox.Enqueue(ctx, user.Email, subject, body, now)
+	})
+}
+
+// useToken checks token and consumes it in the same transaction as apply.
+func (s *AccountService) useToken(ctx context.Context, token, purpose string, apply func(tx *repositories.Tx, userID int, now time.Time) error) error {
+	claims, err := utils.ValidateActionToken(token, purpose)
+	if err != nil {
+		return constants.ErrInvalidActionToken
+	}
+
+	err = s.uow.WithTx(ctx, func(tx *repositories.Tx) error {
+		now := s.now()
+
+		userID, ok, err := tx.UserTokens.Use(ctx, claims.ID, purpose, now)
+		if err != nil {
+			return err
+		}
+		if !ok || userID != claims.Sub {
+			return constants.ErrInvalidActionToken
+		}
+
+		return apply(tx, userID, now)
+	})
+	if errors.Is(err, constants.ErrInvalidActionToken) {
+		return constants.ErrInvalidActionToken
+	}
+	return err
+}
+
+// formatTTL renders a token lifetime for an email, in hours when it is a
+// whole number of hours and in minutes otherwise.
+func formatTTL(d time.Duration) string {
+	value, unit := int(d.Minutes()), "minute"
+	if d >= time.Hour && d%time.Hour == 0 {
+		value, unit = int(d.Hours()), "hour"
+	}
+	if value != 1 {
+		unit += "s"
+	}
+	return fmt.Sprintf("%d %s", value, unit)
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"net/url"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/database"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/repositories"
+	"github.com/Nimirandad/bike-rental-service/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAccountService(t *testing.T) (*AccountService, *database.DB, *models.User) {
+	t.Helper()
+
+	os.Setenv("JWT_SECRET", "test-secret")
+	t.Cleanup(func() { os.Unsetenv("JWT_SECRET") })
+
+	db := newTestDB(t)
+	user, err := NewUserService(repositories.NewUserRepository(db)).RegisterUser(t.Context(), "rider@example.com", "OldPass123", "Ada", "Lovelace")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	service := NewAccountService(repositories.NewUserRepository(db), repositories.NewUnitOfWork(db), "https://app.example.com/", time.Hour, 48*time.Hour)
+	return service, db, user
+}
+
+var tokenLinkPattern = regexp.MustCompile(`\?token=(\S+)`)
+
+// lastEmailToken returns the recipient and the token in the link of the most
+// recently queued email.
+func lastEmailToken(t *testing.T, db *database.DB) (string, string) {
+	t.Helper()
+
+	var recipient, body string
+	err := db.QueryRow("SELECT recipient, body FROM email_outbox ORDER BY id DESC LIMIT 1").Scan(&recipient, &body)
+	if err != nil {
+		t.Fatalf("no queued email: %v", err)
+	}
+
+	match := tokenLinkPattern.FindStringSubmatch(body)
+	if match == nil {
+		t.Fatalf("queued email has no token link: %q", body)
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatalf("invalid token in link: %v", err)
+	}
+	return recipient, token
+}
+
+func countQueuedEmails(t *testing.T, db *database.DB) int {
+	t.Helper()
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM email_outbox").Scan(&count); err != nil {
+		t.Fatalf("failed to count emails: %v", err)
+	}
+	return count
+}
+
+func TestAccountService_VerifyEmail(t *testing.T) {
+	service, db, user := newTestAccountService(t)
+
+	assert.NoError(t, service.RequestEmailVerification(t.Context(), user.ID))
+	recipient, token := lastEmailToken(t, db)
+	assert.Equal(t, "rider@example.com", recipient)
+
+	assert.NoError(t, service.VerifyEmail(t.Context(), token))
+
+	verified, err := repositories.NewUserRepository(db).GetByID(t.Context(), user.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, verified.EmailVerifiedAt)
+
+	assert.Equal(t, constants.ErrInvalidActionToken, service.VerifyEmail(t.Context(), token), "tokens are single-use")
+	assert.Equal(t, constants.ErrEmailAlreadyVerified, service.RequestEmailVerification(t.Context(), user.ID))
+}
+
+func TestAccountService_VerifyEmail_NewLinkReplacesOld(t *testing.T) {
+	service, db, user := newTestAccountService(t)
+
+	assert.NoError(t, service.RequestEmailVerification(t.Context(), user.ID))
+	_, oldToken := lastEmailToken(t, db)
+	assert.NoError(t, service.RequestEmailVerification(t.Context(), user.ID))
+	_, newToken := lastEmailToken(t, db)
+
+	assert.Equal(t, constants.ErrInvalidActionToken, service.VerifyEmail(t.Context(), oldToken))
+	assert.NoError(t, service.VerifyEmail(t.Context(), newToken))
+}
+
+func TestAccountService_RequestPasswordReset_UnknownEmail(t *testing.T) {
+	service, db, _ := newTestAccountService(t)
+
+	assert.NoError(t, service.RequestPasswordReset(t.Context(), "nobody@example.com"))
+	assert.Equal(t, 0, countQueuedEmails(t, db))
+}
+
+func TestAccountService_ResetPassword(t *testing.T) {
+	service, db, user := newTestAccountService(t)
+
+	tokens := NewTokenService(repositories.NewRevokedTokenRepository(db), repositories.NewUnitOfWork(db), 15*time.Minute, 24*time.Hour)
+	session, err := tokens.IssueTokens(t.Context(), user)
+	assert.NoError(t, err)
+
+	assert.NoError(t, service.RequestPasswordReset(t.Context(), "rider@example.com"))
+	_, token := lastEmailToken(t, db)
+
+	assert.NoError(t, service.ResetPassword(t.Context(), token, "NewPass123"))
+
+	users := NewUserService(repositories.NewUserRepository(db))
+	_, err = users.Login(t.Context(), "rider@example.com", "NewPass123")
+	assert.NoError(t, err)
+	_, err = users.Login(t.Context(), "rider@example.com", "OldPass123")
+	assert.Equal(t, constants.ErrInvalidCredentials, err)
+
+	_, err = tokens.Refresh(t.Context(), session.RefreshToken)
+	assert.Equal(t, constants.ErrInvalidRefreshToken, err, "existing sessions are logged out")
+
+	assert.Equal(t, constants.ErrInvalidActionToken, service.ResetPassword(t.Context(), token, "Another123"))
+}
+
+func TestAccountService_ResetPassword_RejectsOtherPurpose(t *testing.T) {
+	service, db, user := newTestAccountService(t)
+
+	assert.NoError(t, service.RequestEmailVerification(t.Context(), user.ID))
+	_, verificationToken := lastEmailToken(t, db)
+
+	assert.Equal(t, constants.ErrInvalidActionToken, service.ResetPassword(t.Context(), verificationToken, "NewPass123"))
+	assert.Equal(t, constants.ErrInvalidActionToken, service.ResetPassword(t.Context(), "not-a-token", "NewPass123"))
+
+	expired, _, err := utils.GenerateActionToken(user.ID, models.UserTokenPurposePasswordReset, -time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, constants.ErrInvalidActionToken, service.ResetPassword(t.Context(), expired, "NewPass123"))
+}
+
+func TestFormatTTL(t *testing.T) {
+	assert.Equal(t, "1 hour", formatTTL(time.Hour))
+	assert.Equal(t, "48 hours", formatTTL(48*time.Hour))
+	assert.Equal(t, "90 minutes", formatTTL(90*time.Minute))
+}
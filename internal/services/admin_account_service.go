@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/logger"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/repositories"
+	"github.com/Nimirandad/bike-rental-service/internal/utils"
+)
+
+type AdminAccountRepository interface {
+	Create(ctx context.Context, email, hashedPassword, name string, role models.AdminRole) (*models.Admin, error)
+	GetByID(ctx context.Context, adminID int) (*models.Admin, error)
+	GetPasswordHashByEmail(ctx context.Context, email string) (string, *models.Admin, error)
+	CountAll(ctx context.Context) (int, error)
+	CountEnabledByRole(ctx context.Context, role models.AdminRole) (int, error)
+	GetAll(ctx context.Context, page, limit int) ([]*models.Admin, error)
+	Update(ctx context.Context, adminID int, name *string, role *models.AdminRole, hashedPassword *string, disabled *bool) (*models.Admin, error)
+}
+
+type AdminAccountService struct {
+	adminAccountRepo AdminAccountRepository
+}
+
+func NewAdminAccountService(adminAccountRepo *repositories.AdminAccountRepository) *AdminAccountService {
+	return &AdminAccountService{adminAccountRepo: adminAccountRepo}
+}
+
+func (s *AdminAccountService) Login(ctx context.Context, email, password string) (*models.Admin, error) {
+	hashedPassword, admin, err := s.adminAccountRepo.GetPasswordHashByEmail(ctx, email)
+	if errors.Is(err, constants.ErrAdminNotFound) {
+		return nil, constants.ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !utils.VerifyPassword(password, hashedPassword) {
+		return nil, constants.ErrInvalidCredentials
+	}
+	if admin.DisabledAt != nil {
+		return nil, constants.ErrAdminDisabled
+	}
+
+	return admin, nil
+}
+
+// GetActiveAdmin returns the admin's current account, so each admin request
+// is authorized with the role it has now rather than the one in its token.
+// Disabled admins get ErrAdminDisabled.
+func (s *AdminAccountService) GetActiveAdmin(ctx context.Context, adminID int) (*models.Admin, error) {
+	admin, err := s.adminAccountRepo.GetByID(ctx, adminID)
+	if errors.Is(err, constants.ErrAdminNotFound) {
+		return nil, constants.ErrAdminNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if admin.DisabledAt != nil {
+		return nil, constants.ErrAdminDisabled
+	}
+
+	return admin, nil
+}
+
+func (s *AdminAccountService) CreateAdmin(ctx context.Context, email, password, name string, role models.AdminRole) (*models.Admin, error) {
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("error hashing password: %w", err)
+	}
+
+	admin, err := s.adminAccountRepo.Create(ctx, email, hashedPassword, name, role)
+	if errors.Is(err, constants.ErrEmailAlreadyExists) {
+		return nil, constants.ErrEmailAlreadyExists
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return admin, nil
+}
+
+func (s *AdminAccountService) GetAllAdmins(ctx context.Context, page, limit int) ([]*models.Admin, int, error) {
+	total, err := s.adminAccountRepo.CountAll(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	admins, err := s.adminAccountRepo.GetAll(ctx, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return admins, total, nil
+}
+
+// UpdateAdmin changes an admin's name, role or password, or disables or
+// re-enables the account. Role changes and disabling take effect on the
+// admin's next request. Demoting or disabling the last enabled superadmin is
+// refused so the admin API can never lock itself out.
+func (s *AdminAccountService) UpdateAdmin(ctx context.Context, adminID int, name *string, role *models.AdminRole, password *string, disabled *bool) (*models.Admin, error) {
+	current, err := s.adminAccountRepo.GetByID(ctx, adminID)
+	if errors.Is(err, constants.ErrAdminNotFound) {
+		return nil, constants.ErrAdminNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	demoted := role != nil && *role != models.AdminRoleSuperadmin
+	disabling := disabled != nil && *disabled
+	if current.Role == models.AdminRoleSuperadmin && current.DisabledAt == nil && (demoted || disabling) {
+		superadmins, err := s.adminAccountRepo.CountEnabledByRole(ctx, models.AdminRoleSuperadmin)
+		if err != nil {
+			return nil, err
+		}
+		if superadmins <= 1 {
+			return nil, constants.ErrLastSuperadmin
+		}
+	}
+
+	var hashedPassword *string
+	if password != nil {
+		hash, err := utils.HashPassword(*password)
+		if err != nil {
+			return nil, fmt.Errorf("error hashing password: %w", err)
+		}
+		hashedPassword = &hash
+	}
+
+	admin, err := s.adminAccountRepo.Update(ctx, adminID, name, role, hashedPassword, disabled)
+	if errors.Is(err, constants.ErrAdminNotFound) {
+		return nil, constants.ErrAdminNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return admin, nil
+}
+
+// EnsureBootstrapAdmin creates a superadmin with the given credentials when
+// no admin account exists yet, so a fresh deployment can log in to the admin
+// API. It does nothing when email or password is empty.
+func (s *AdminAccountService) EnsureBootstrapAdmin(ctx context.Context, email, password string) error {
+	if email == "" || password == "" {
+		return nil
+	}
+
+	count, err := s.adminAccountRepo.CountAll(ctx)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	if _, err := s.CreateAdmin(ctx, email, password, "Superadmin", models.AdminRoleSuperadmin); err != nil {
+		return err
+	}
+
+	log := logger.FromContext(ctx)
+	log.Info().Str("email", email).Msg("Bootstrap superadmin created")
+	return nil
+}
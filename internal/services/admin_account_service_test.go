@@ -0,0 +1,172 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAdminAccountService(t *testing.T) *AdminAccountService {
+	t.Helper()
+	return NewAdminAccountService(repositories.NewAdminAccountRepository(newTestDB(t)))
+}
+
+func TestAdminAccountService_Login(t *testing.T) {
+	service := newTestAdminAccountService(t)
+
+	created, err := service.CreateAdmin(t.Context(), "ops@example.com", "secret123", "Ops", models.AdminRoleFleet)
+	assert.NoError(t, err)
+
+	admin, err := service.Login(t.Context(), "ops@example.com", "secret123")
+	assert.NoError(t, err)
+	assert.Equal(t, created.ID, admin.ID)
+	assert.Equal(t, models.AdminRoleFleet, admin.Role)
+
+	_, err = service.Login(t.Context(), "ops@example.com", "wrong123")
+	assert.Equal(t, constants.ErrInvalidCredentials, err)
+
+	_, err = service.Login(t.Context(), "nobody@example.com", "secret123")
+	assert.Equal(t, constants.ErrInvalidCredentials, err)
+}
+
+func TestAdminAccountService_CreateAdmin_DuplicateEmail(t *testing.T) {
+	service := newTestAdminAccountService(t)
+
+	_, err := service.CreateAdmin(t.Context(), "ops@example.com", "secret123", "Ops", models.AdminRoleFleet)
+	assert.NoError(t, err)
+
+	_, err = service.CreateAdmin(t.Context(), "ops@example.com", "secret123", "Ops", models.AdminRoleSupport)
+	assert.Equal(t, constants.ErrEmailAlreadyExists, err)
+}
+
+func TestAdminAccountService_UpdateAdmin_KeepsLastSuperadmin(t *testing.T) {
+	service := newTestAdminAccountService(t)
+
+	root, err := service.CreateAdmin(t.Context(), "root@example.com", "secret123", "Root", models.AdminRoleSuperadmin)
+	assert.NoError(t, err)
+
+	support := models.AdminRoleSupport
+	_, err = service.UpdateAdmin(t.Context(), root.ID, nil, &support, nil, nil)
+	assert.Equal(t, constants.ErrLastSuperadmin, err)
+
+	_, err = service.CreateAdmin(t.Context(), "second@example.com", "secret123", "Second", models.AdminRoleSuperadmin)
+	assert.NoError(t, err)
+
+	updated, err := service.UpdateAdmin(t.Context(), root.ID, nil, &support, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, models.AdminRoleSupport, updated.Role)
+}
+
+func TestAdminAccountService_UpdateAdmin_Password(t *testing.T) {
+	service := newTestAdminAccountService(t)
+
+	admin, err := service.CreateAdmin(t.Context(), "ops@example.com", "secret123", "Ops", models.AdminRoleFleet)
+	assert.NoError(t, err)
+
+	password := "changed456"
+	_, err = service.UpdateAdmin(t.Context(), admin.ID, nil, nil, &password, nil)
+	assert.NoError(t, err)
+
+	_, err = service.Login(t.Context(), "ops@example.com", "secret123")
+	assert.Equal(t, constants.ErrInvalidCredentials, err)
+
+	_, err = service.Login(t.Context(), "ops@example.com", "changed456")
+	assert.NoError(t, err)
+}
+
+func TestAdminAccountService_UpdateAdmin_NotFound(t *testing.T) {
+	service := newTestAdminAccountService(t)
+
+	name := "Ghost"
+	_, err := service.UpdateAdmin(t.Context(), 99, &name, nil, nil, nil)
+	assert.Equal(t, constants.ErrAdminNotFound, err)
+}
+
+func TestAdminAccountService_EnsureBootstrapAdmin(t *testing.T) {
+	service := newTestAdminAccountService(t)
+
+	assert.NoError(t, service.EnsureBootstrapAdmin(t.Context(), "", ""))
+	_, total, err := service.GetAllAdmins(t.Context(), 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, total)
+
+	assert.NoError(t, service.EnsureBootstrapAdmin(t.Context(), "root@example.com", "secret123"))
+	admins, total, err := service.GetAllAdmins(t.Context(), 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, models.AdminRoleSuperadmin, admins[0].Role)
+
+	assert.NoError(t, service.EnsureBootstrapAdmin(t.Context(), "other@example.com", "secret123"))
+	_, total, err = service.GetAllAdmins(t.Context(), 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+}
+
+func TestAdminAccountService_DisableAdmin(t *testing.T) {
+	service := newTestAdminAccountService(t)
+
+	root, err := service.CreateAdmin(t.Context(), "root@example.com", "secret123", "Root", models.AdminRoleSuperadmin)
+	assert.NoError(t, err)
+	ops, err := service.CreateAdmin(t.Context(), "ops@example.com", "secret123", "Ops", models.AdminRoleFleet)
+	assert.NoError(t, err)
+
+	disabled, enabled := true, false
+
+	_, err = service.UpdateAdmin(t.Context(), root.ID, nil, nil, nil, &disabled)
+	assert.Equal(t, constants.ErrLastSuperadmin, err)
+
+	updated, err := service.UpdateAdmin(t.Context(), ops.ID, nil, nil, nil, &disabled)
+	assert.NoError(t, err)
+	assert.NotNil(t, updated.DisabledAt)
+
+	_, err = service.Login(t.Context(), "ops@example.com", "secret123")
+	assert.Equal(t, constants.ErrAdminDisabled, err)
+	_, err = service.GetActiveAdmin(t.Context(), ops.ID)
+	assert.Equal(t, constants.ErrAdminDisabled, err)
+
+	updated, err = service.UpdateAdmin(t.Context(), ops.ID, nil, nil, nil, &enabled)
+	assert.NoError(t, err)
+	assert.Nil(t, updated.DisabledAt)
+
+	admin, err := service.GetActiveAdmin(t.Context(), ops.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.AdminRoleFleet, admin.Role)
+}
+
+func TestAdminAccountService_GetActiveAdmin_SeesRoleChanges(t *testing.T) {
+	service := newTestAdminAccountService(t)
+
+	ops, err := service.CreateAdmin(t.Context(), "ops@example.com", "secret123", "Ops", models.AdminRoleFinance)
+	assert.NoError(t, err)
+
+	support := models.AdminRoleSupport
+	_, err = service.UpdateAdmin(t.Context(), ops.ID, nil, &support, nil, nil)
+	assert.NoError(t, err)
+
+	admin, err := service.GetActiveAdmin(t.Context(), ops.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.AdminRoleSupport, admin.Role)
+
+	_, err = service.GetActiveAdmin(t.Context(), 99)
+	assert.Equal(t, constants.ErrAdminNotFound, err)
+}
+
+func TestAdminAccountService_DisabledSuperadminDoesNotCount(t *testing.T) {
+	service := newTestAdminAccountService(t)
+
+	root, err := service.CreateAdmin(t.Context(), "root@example.com", "secret123", "Root", models.AdminRoleSuperadmin)
+	assert.NoError(t, err)
+	second, err := service.CreateAdmin(t.Context(), "second@example.com", "secret123", "Second", models.AdminRoleSuperadmin)
+	assert.NoError(t, err)
+
+	disabled := true
+	_, err = service.UpdateAdmin(t.Context(), second.ID, nil, nil, nil, &disabled)
+	assert.NoError(t, err)
+
+	support := models.AdminRoleSupport
+	_, err = service.UpdateAdmin(t.Context(), root.ID, nil, &support, nil, nil)
+	assert.Equal(t, constants.ErrLastSuperadmin, err)
+}
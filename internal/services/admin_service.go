@@ -1,48 +1,99 @@
 package services
 
 import (
+	"context"
+	"errors"
+
 	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/metrics"
 	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/queryspec"
 	"github.com/Nimirandad/bike-rental-service/internal/repositories"
 )
 
 type AdminRepository interface {
-	CreateBike(latitude, longitude, pricePerMinute float64) (*models.Bike, error)
-	GetAllBikes(page, limit int) ([]*models.Bike, error)
-	CountAll() (int, error)
-	UpdateBike(bikeID int, latitude, longitude *float64, isAvailable *bool, pricePerMinute *float64) (*models.Bike, error)
-	GetAllUsers(page, limit int) ([]*models.User, error)
-	CountAllUsers() (int, error)
-	GetUserByID(userID int) (*models.User, error)
-	EmailExistsByOtherUser(email string, userID int) (bool, error)
-	UpdateUser(userID int, email, firstName, lastName, hashedPassword *string) (*models.User, error)
-	GetAllRentals(page, limit int) ([]*models.Rental, error)
-	CountAllRentals() (int, error)
-	GetRentalByID(rentalID int) (*models.Rental, error)
-	UpdateRental(rentalID int, status *string) (*models.Rental, error)
+	CreateBike(ctx context.Context, latitude, longitude, pricePerMinute float64, pricePlanID *int, bikeType models.BikeType, batteryLevel *int) (*models.Bike, error)
+	GetBikeByID(ctx context.Context, bikeID int) (*models.Bike, error)
+	GetAllBikes(ctx context.Context, spec *queryspec.Spec, page, limit int) ([]*models.Bike, error)
+	CountAll(ctx context.Context, spec *queryspec.Spec) (int, error)
+	UpdateBike(ctx context.Context, bikeID int, latitude, longitude *float64, isAvailable *bool, pricePerMinute *float64, pricePlanID *int, bikeType *models.BikeType, batteryLevel *int) (*models.Bike, error)
+	GetAllUsers(ctx context.Context, spec *queryspec.Spec, page, limit int) ([]*models.User, error)
+	CountAllUsers(ctx context.Context, spec *queryspec.Spec) (int, error)
+	GetUserByID(ctx context.Context, userID int) (*models.User, error)
+	EmailExistsByOtherUser(ctx context.Context, email string, userID int) (bool, error)
+	UpdateUser(ctx context.Context, userID int, email, firstName, lastName, hashedPassword *string) (*models.User, error)
+	GetAllRentals(ctx context.Context, spec *queryspec.Spec, page, limit int) ([]*models.Rental, error)
+	CountAllRentals(ctx context.Context, spec *queryspec.Spec) (int, error)
+	GetRentalByID(ctx context.Context, rentalID int) (*models.Rental, error)
 }
 
 type AdminService struct {
-	adminRepo AdminRepository
+	adminRepo            AdminRepository
+	pricePlanRepo        PricePlanRepository
+	bikeTypePriceRepo    BikeTypePriceRepository
+	geofences            GeofenceLookup
+	uow                  UnitOfWork
+	pausedPricePerMinute float64
 }
 
-func NewAdminService(adminRepo *repositories.AdminRepository) *AdminService {
+func NewAdminService(adminRepo *repositories.AdminRepository, pricePlanRepo *repositories.PricePlanRepository, bikeTypePriceRepo *repositories.BikeTypePriceRepository, geofenceRepo *repositories.GeofenceRepository, uow *repositories.UnitOfWork, pausedPricePerMinute float64) *AdminService {
 	return &AdminService{
-		adminRepo: adminRepo,
+		adminRepo:            adminRepo,
+		pricePlanRepo:        pricePlanRepo,
+		bikeTypePriceRepo:    bikeTypePriceRepo,
+		geofences:            geofenceRepo,
+		uow:                  uow,
+		pausedPricePerMinute: pausedPricePerMinute,
 	}
 }
 
-func (s *AdminService) CreateBike(latitude, longitude, pricePerMinute float64) (*models.Bike, error) {
-	return s.adminRepo.CreateBike(latitude, longitude, pricePerMinute)
+// CreateBike places a new bike, which must be inside the operating area and
+// outside no-parking zones. Without a pricePerMinute the bike gets its type's
+// default price. Only e-bikes may have a battery level.
+func (s *AdminService) CreateBike(ctx context.Context, latitude, longitude float64, pricePerMinute *float64, pricePlanID *int, bikeType models.BikeType, batteryLevel *int) (*models.Bike, error) {
+	if batteryLevel != nil && bikeType != models.BikeTypeEBike {
+		return nil, constants.ErrBatteryNotSupported
+	}
+	if err := validatePlacement(ctx, s.geofences, latitude, longitude); err != nil {
+		return nil, err
+	}
+	if err := s.ensurePricePlanExists(ctx, pricePlanID); err != nil {
+		return nil, err
+	}
+
+	price, err := s.defaultPrice(ctx, bikeType, pricePerMinute)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.adminRepo.CreateBike(ctx, latitude, longitude, price, pricePlanID, bikeType, batteryLevel)
 }
 
-func (s *AdminService) GetAllBikes(page, limit int) ([]*models.Bike, int, error) {
-	total, err := s.adminRepo.CountAll()
+// defaultPrice returns pricePerMinute when it is set, and otherwise the
+// default price of the bike type, falling back to
+// constants.DefaultPricePerMinute for a type without one.
+func (s *AdminService) defaultPrice(ctx context.Context, bikeType models.BikeType, pricePerMinute *float64) (float64, error) {
+	if pricePerMinute != nil {
+		return *pricePerMinute, nil
+	}
+
+	typePrice, err := s.bikeTypePriceRepo.GetByType(ctx, bikeType)
+	if err != nil {
+		return 0, err
+	}
+	if typePrice == nil {
+		return constants.DefaultPricePerMinute, nil
+	}
+	return typePrice.PricePerMinute, nil
+}
+
+func (s *AdminService) GetAllBikes(ctx context.Context, spec *queryspec.Spec, page, limit int) ([]*models.Bike, int, error) {
+	total, err := s.adminRepo.CountAll(ctx, spec)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	bikes, err := s.adminRepo.GetAllBikes(page, limit)
+	bikes, err := s.adminRepo.GetAllBikes(ctx, spec, page, limit)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -50,17 +101,97 @@ func (s *AdminService) GetAllBikes(page, limit int) ([]*models.Bike, int, error)
 	return bikes, total, nil
 }
 
-func (s *AdminService) UpdateBike(bikeID int, latitude, longitude *float64, isAvailable *bool, pricePerMinute *float64) (*models.Bike, error) {
-	return s.adminRepo.UpdateBike(bikeID, latitude, longitude, isAvailable, pricePerMinute)
+// GetAllBikesByCursor returns one page of the bikes matching spec from
+// spec.Cursor, without counting the full list.
+func (s *AdminService) GetAllBikesByCursor(ctx context.Context, spec *queryspec.Spec, limit int) ([]*models.Bike, queryspec.Cursors, error) {
+	// One extra row tells whether another page follows.
+	bikes, err := s.adminRepo.GetAllBikes(ctx, spec, 1, limit+1)
+	if err != nil {
+		return nil, queryspec.Cursors{}, err
+	}
+
+	bikes, cursors := queryspec.Paginate(bikes, *spec.Cursor, limit, func(bike *models.Bike) int { return bike.ID })
+	return bikes, cursors, nil
+}
+
+// UpdateBike changes the fields provided. A move is checked against the
+// geofences like a new placement, and a battery level is only accepted for a
+// bike that is, or becomes, an e-bike.
+func (s *AdminService) UpdateBike(ctx context.Context, bikeID int, latitude, longitude *float64, isAvailable *bool, pricePerMinute *float64, pricePlanID *int, bikeType *models.BikeType, batteryLevel *int) (*models.Bike, error) {
+	if batteryLevel != nil {
+		if err := s.ensureEBike(ctx, bikeID, bikeType); err != nil {
+			return nil, err
+		}
+	}
+	if latitude != nil || longitude != nil {
+		if err := s.validateMove(ctx, bikeID, latitude, longitude); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.ensurePricePlanExists(ctx, pricePlanID); err != nil {
+		return nil, err
+	}
+	return s.adminRepo.UpdateBike(ctx, bikeID, latitude, longitude, isAvailable, pricePerMinute, pricePlanID, bikeType, batteryLevel)
+}
+
+// ensureEBike checks that the bike will be an e-bike after an update that
+// sets bikeType, or keeps its current type when bikeType is nil.
+func (s *AdminService) ensureEBike(ctx context.Context, bikeID int, bikeType *models.BikeType) error {
+	if bikeType == nil {
+		bike, err := s.adminRepo.GetBikeByID(ctx, bikeID)
+		if err != nil {
+			return err
+		}
+		bikeType = &bike.Type
+	}
+
+	if *bikeType != models.BikeTypeEBike {
+		return constants.ErrBatteryNotSupported
+	}
+	return nil
 }
 
-func (s *AdminService) GetAllUsers(page, limit int) ([]*models.User, int, error) {
-	total, err := s.adminRepo.CountAllUsers()
+// validateMove checks the bike's new location, keeping its current latitude
+// or longitude when only the other one changes.
+func (s *AdminService) validateMove(ctx context.Context, bikeID int, latitude, longitude *float64) error {
+	if latitude == nil || longitude == nil {
+		bike, err := s.adminRepo.GetBikeByID(ctx, bikeID)
+		if err != nil {
+			return err
+		}
+		if latitude == nil {
+			latitude = &bike.Latitude
+		}
+		if longitude == nil {
+			longitude = &bike.Longitude
+		}
+	}
+
+	return validatePlacement(ctx, s.geofences, *latitude, *longitude)
+}
+
+// ensurePricePlanExists checks a plan reference before it is stored on a bike.
+// A nil or zero ID means no plan and is always accepted; an unknown plan is
+// reported as ErrUnknownPricePlan, since the bike itself may well exist.
+func (s *AdminService) ensurePricePlanExists(ctx context.Context, pricePlanID *int) error {
+	if pricePlanID == nil || *pricePlanID == 0 {
+		return nil
+	}
+
+	_, err := s.pricePlanRepo.GetByID(ctx, *pricePlanID)
+	if errors.Is(err, constants.ErrPricePlanNotFound) {
+		return constants.ErrUnknownPricePlan
+	}
+	return err
+}
+
+func (s *AdminService) GetAllUsers(ctx context.Context, spec *queryspec.Spec, page, limit int) ([]*models.User, int, error) {
+	total, err := s.adminRepo.CountAllUsers(ctx, spec)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	users, err := s.adminRepo.GetAllUsers(page, limit)
+	users, err := s.adminRepo.GetAllUsers(ctx, spec, page, limit)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -68,13 +199,26 @@ func (s *AdminService) GetAllUsers(page, limit int) ([]*models.User, int, error)
 	return users, total, nil
 }
 
-func (s *AdminService) GetUserByID(userID int) (*models.User, error) {
-	return s.adminRepo.GetUserByID(userID)
+// GetAllUsersByCursor returns one page of the users matching spec from
+// spec.Cursor, without counting the full list.
+func (s *AdminService) GetAllUsersByCursor(ctx context.Context, spec *queryspec.Spec, limit int) ([]*models.User, queryspec.Cursors, error) {
+	// One extra row tells whether another page follows.
+	users, err := s.adminRepo.GetAllUsers(ctx, spec, 1, limit+1)
+	if err != nil {
+		return nil, queryspec.Cursors{}, err
+	}
+
+	users, cursors := queryspec.Paginate(users, *spec.Cursor, limit, func(user *models.User) int { return user.ID })
+	return users, cursors, nil
 }
 
-func (s *AdminService) UpdateUser(userID int, email, firstName, lastName, hashedPassword *string) (*models.User, error) {
+func (s *AdminService) GetUserByID(ctx context.Context, userID int) (*models.User, error) {
+	return s.adminRepo.GetUserByID(ctx, userID)
+}
+
+func (s *AdminService) UpdateUser(ctx context.Context, userID int, email, firstName, lastName, hashedPassword *string) (*models.User, error) {
 	if email != nil {
-		exists, err := s.adminRepo.EmailExistsByOtherUser(*email, userID)
+		exists, err := s.adminRepo.EmailExistsByOtherUser(ctx, *email, userID)
 		if err != nil {
 			return nil, err
 		}
@@ -83,16 +227,16 @@ func (s *AdminService) UpdateUser(userID int, email, firstName, lastName, hashed
 		}
 	}
 
-	return s.adminRepo.UpdateUser(userID, email, firstName, lastName, hashedPassword)
+	return s.adminRepo.UpdateUser(ctx, userID, email, firstName, lastName, hashedPassword)
 }
 
-func (s *AdminService) GetAllRentals(page, limit int) ([]*models.Rental, int, error) {
-	total, err := s.adminRepo.CountAllRentals()
+func (s *AdminService) GetAllRentals(ctx context.Context, spec *queryspec.Spec, page, limit int) ([]*models.Rental, int, error) {
+	total, err := s.adminRepo.CountAllRentals(ctx, spec)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	rentals, err := s.adminRepo.GetAllRentals(page, limit)
+	rentals, err := s.adminRepo.GetAllRentals(ctx, spec, page, limit)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -100,10 +244,53 @@ func (s *AdminService) GetAllRentals(page, limit int) ([]*models.Rental, int, er
 	return rentals, total, nil
 }
 
-func (s *AdminService) GetRentalByID(rentalID int) (*models.Rental, error) {
-	return s.adminRepo.GetRentalByID(rentalID)
+// GetAllRentalsByCursor returns one page of the rentals matching spec from
+// spec.Cursor, without counting the full list.
+func (s *AdminService) GetAllRentalsByCursor(ctx context.Context, spec *queryspec.Spec, limit int) ([]*models.Rental, queryspec.Cursors, error) {
+	// One extra row tells whether another page follows.
+	rentals, err := s.adminRepo.GetAllRentals(ctx, spec, 1, limit+1)
+	if err != nil {
+		return nil, queryspec.Cursors{}, err
+	}
+
+	rentals, cursors := queryspec.Paginate(rentals, *spec.Cursor, limit, func(rental *models.Rental) int { return rental.ID })
+	return rentals, cursors, nil
+}
+
+func (s *AdminService) GetRentalByID(ctx context.Context, rentalID int) (*models.Rental, error) {
+	return s.adminRepo.GetRentalByID(ctx, rentalID)
 }
 
-func (s *AdminService) UpdateRental(rentalID int, status *string) (*models.Rental, error) {
-	return s.adminRepo.UpdateRental(rentalID, status)
-}
\ No newline at end of file
+// UpdateRental moves a rental to status following the rental state machine.
+// Side effects such as freeing the bike, pricing the trip or refunding its
+// cost are applied in the same transaction as the status change.
+func (s *AdminService) UpdateRental(ctx context.Context, rentalID int, status models.RentalStatus) (*models.Rental, error) {
+	var rental *models.Rental
+	var charged float64
+	err := s.uow.WithTx(ctx, func(tx *repositories.Tx) error {
+		current, err := tx.Rentals.GetByID(ctx, rentalID)
+		if errors.Is(err, constants.ErrRentalNotFound) {
+			return constants.ErrRentalNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if current.Cost != nil {
+			charged = *current.Cost
+		}
+
+		rental, err = transitionRental(ctx, tx, current, status, s.pausedPricePerMinute)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch rental.Status {
+	case models.RentalStatusEnded, models.RentalStatusCancelled:
+		metrics.RecordRentalClosed(rental)
+	case models.RentalStatusRefunded:
+		metrics.RecordRefund(charged)
+	}
+	return rental, nil
+}
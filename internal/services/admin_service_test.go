@@ -1,86 +1,92 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/database"
 	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/pricing"
+	"github.com/Nimirandad/bike-rental-service/internal/queryspec"
+	"github.com/Nimirandad/bike-rental-service/internal/repositories"
 	"github.com/stretchr/testify/assert"
 )
 
 type MockAdminRepository struct {
-	CreateBikeFunc             func(latitude, longitude, pricePerMinute float64) (*models.Bike, error)
-	GetAllBikesFunc            func(page, limit int) ([]*models.Bike, error)
-	CountAllFunc               func() (int, error)
-	UpdateBikeFunc             func(bikeID int, latitude, longitude *float64, isAvailable *bool, pricePerMinute *float64) (*models.Bike, error)
-	GetAllUsersFunc            func(page, limit int) ([]*models.User, error)
-	CountAllUsersFunc          func() (int, error)
+	CreateBikeFunc             func(latitude, longitude, pricePerMinute float64, pricePlanID *int, bikeType models.BikeType, batteryLevel *int) (*models.Bike, error)
+	GetBikeByIDFunc            func(bikeID int) (*models.Bike, error)
+	GetAllBikesFunc            func(spec *queryspec.Spec, page, limit int) ([]*models.Bike, error)
+	CountAllFunc               func(spec *queryspec.Spec) (int, error)
+	UpdateBikeFunc             func(bikeID int, latitude, longitude *float64, isAvailable *bool, pricePerMinute *float64, pricePlanID *int, bikeType *models.BikeType, batteryLevel *int) (*models.Bike, error)
+	GetAllUsersFunc            func(spec *queryspec.Spec, page, limit int) ([]*models.User, error)
+	CountAllUsersFunc          func(spec *queryspec.Spec) (int, error)
 	GetUserByIDFunc            func(userID int) (*models.User, error)
 	EmailExistsByOtherUserFunc func(email string, userID int) (bool, error)
 	UpdateUserFunc             func(userID int, email, firstName, lastName, hashedPassword *string) (*models.User, error)
-	GetAllRentalsFunc          func(page, limit int) ([]*models.Rental, error)
-	CountAllRentalsFunc        func() (int, error)
+	GetAllRentalsFunc          func(spec *queryspec.Spec, page, limit int) ([]*models.Rental, error)
+	CountAllRentalsFunc        func(spec *queryspec.Spec) (int, error)
 	GetRentalByIDFunc          func(rentalID int) (*models.Rental, error)
-	UpdateRentalFunc           func(rentalID int, status *string) (*models.Rental, error)
 }
 
-func (m *MockAdminRepository) CreateBike(latitude, longitude, pricePerMinute float64) (*models.Bike, error) {
-	return m.CreateBikeFunc(latitude, longitude, pricePerMinute)
+func (m *MockAdminRepository) CreateBike(ctx context.Context, latitude, longitude, pricePerMinute float64, pricePlanID *int, bikeType models.BikeType, batteryLevel *int) (*models.Bike, error) {
+	return m.CreateBikeFunc(latitude, longitude, pricePerMinute, pricePlanID, bikeType, batteryLevel)
 }
 
-func (m *MockAdminRepository) GetAllBikes(page, limit int) ([]*models.Bike, error) {
-	return m.GetAllBikesFunc(page, limit)
+func (m *MockAdminRepository) GetBikeByID(ctx context.Context, bikeID int) (*models.Bike, error) {
+	return m.GetBikeByIDFunc(bikeID)
 }
 
-func (m *MockAdminRepository) CountAll() (int, error) {
-	return m.CountAllFunc()
+func (m *MockAdminRepository) GetAllBikes(ctx context.Context, spec *queryspec.Spec, page, limit int) ([]*models.Bike, error) {
+	return m.GetAllBikesFunc(spec, page, limit)
 }
 
-func (m *MockAdminRepository) UpdateBike(bikeID int, latitude, longitude *float64, isAvailable *bool, pricePerMinute *float64) (*models.Bike, error) {
-	return m.UpdateBikeFunc(bikeID, latitude, longitude, isAvailable, pricePerMinute)
+func (m *MockAdminRepository) CountAll(ctx context.Context, spec *queryspec.Spec) (int, error) {
+	return m.CountAllFunc(spec)
 }
 
-func (m *MockAdminRepository) GetAllUsers(page, limit int) ([]*models.User, error) {
-	return m.GetAllUsersFunc(page, limit)
+func (m *MockAdminRepository) UpdateBike(ctx context.Context, bikeID int, latitude, longitude *float64, isAvailable *bool, pricePerMinute *float64, pricePlanID *int, bikeType *models.BikeType, batteryLevel *int) (*models.Bike, error) {
+	return m.UpdateBikeFunc(bikeID, latitude, longitude, isAvailable, pricePerMinute, pricePlanID, bikeType, batteryLevel)
 }
 
-func (m *MockAdminRepository) CountAllUsers() (int, error) {
-	return m.CountAllUsersFunc()
+func (m *MockAdminRepository) GetAllUsers(ctx context.Context, spec *queryspec.Spec, page, limit int) ([]*models.User, error) {
+	return m.GetAllUsersFunc(spec, page, limit)
 }
 
-func (m *MockAdminRepository) GetUserByID(userID int) (*models.User, error) {
+func (m *MockAdminRepository) CountAllUsers(ctx context.Context, spec *queryspec.Spec) (int, error) {
+	return m.CountAllUsersFunc(spec)
+}
+
+func (m *MockAdminRepository) GetUserByID(ctx context.Context, userID int) (*models.User, error) {
 	return m.GetUserByIDFunc(userID)
 }
 
-func (m *MockAdminRepository) EmailExistsByOtherUser(email string, userID int) (bool, error) {
+func (m *MockAdminRepository) EmailExistsByOtherUser(ctx context.Context, email string, userID int) (bool, error) {
 	return m.EmailExistsByOtherUserFunc(email, userID)
 }
 
-func (m *MockAdminRepository) UpdateUser(userID int, email, firstName, lastName, hashedPassword *string) (*models.User, error) {
+func (m *MockAdminRepository) UpdateUser(ctx context.Context, userID int, email, firstName, lastName, hashedPassword *string) (*models.User, error) {
 	return m.UpdateUserFunc(userID, email, firstName, lastName, hashedPassword)
 }
 
-func (m *MockAdminRepository) GetAllRentals(page, limit int) ([]*models.Rental, error) {
-	return m.GetAllRentalsFunc(page, limit)
+func (m *MockAdminRepository) GetAllRentals(ctx context.Context, spec *queryspec.Spec, page, limit int) ([]*models.Rental, error) {
+	return m.GetAllRentalsFunc(spec, page, limit)
 }
 
-func (m *MockAdminRepository) CountAllRentals() (int, error) {
-	return m.CountAllRentalsFunc()
+func (m *MockAdminRepository) CountAllRentals(ctx context.Context, spec *queryspec.Spec) (int, error) {
+	return m.CountAllRentalsFunc(spec)
 }
 
-func (m *MockAdminRepository) GetRentalByID(rentalID int) (*models.Rental, error) {
+func (m *MockAdminRepository) GetRentalByID(ctx context.Context, rentalID int) (*models.Rental, error) {
 	return m.GetRentalByIDFunc(rentalID)
 }
 
-func (m *MockAdminRepository) UpdateRental(rentalID int, status *string) (*models.Rental, error) {
-	return m.UpdateRentalFunc(rentalID, status)
-}
-
 // TestAdminService_CreateBike_Success tests successful bike creation
 func TestAdminService_CreateBike_Success(t *testing.T) {
 	mockRepo := &MockAdminRepository{
-		CreateBikeFunc: func(latitude, longitude, pricePerMinute float64) (*models.Bike, error) {
+		CreateBikeFunc: func(latitude, longitude, pricePerMinute float64, pricePlanID *int, bikeType models.BikeType, batteryLevel *int) (*models.Bike, error) {
 			return &models.Bike{
 				ID:             1,
 				Latitude:       latitude,
@@ -91,8 +97,9 @@ func TestAdminService_CreateBike_Success(t *testing.T) {
 		},
 	}
 
-	service := &AdminService{adminRepo: mockRepo}
-	bike, err := service.CreateBike(40.416775, -3.703790, 0.5)
+	price := 0.5
+	service := &AdminService{adminRepo: mockRepo, geofences: noGeofences()}
+	bike, err := service.CreateBike(t.Context(), 40.416775, -3.703790, &price, nil, models.BikeTypeClassic, nil)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, bike)
@@ -103,16 +110,52 @@ func TestAdminService_CreateBike_Success(t *testing.T) {
 	assert.True(t, bike.IsAvailable)
 }
 
+// TestAdminService_CreateBike_UnknownPricePlan tests that a missing price plan is rejected
+func TestAdminService_CreateBike_UnknownPricePlan(t *testing.T) {
+	mockRepo := &MockAdminRepository{}
+	mockPlanRepo := &MockPricePlanRepository{
+		GetByIDFunc: func(planID int) (*models.PricePlan, error) {
+			return nil, notFoundPricePlan(planID)
+		},
+	}
+
+	planID := 42
+	price := 0.5
+	service := &AdminService{adminRepo: mockRepo, pricePlanRepo: mockPlanRepo, geofences: noGeofences()}
+	bike, err := service.CreateBike(t.Context(), 40.416775, -3.703790, &price, &planID, models.BikeTypeClassic, nil)
+
+	assert.Equal(t, constants.ErrUnknownPricePlan, err)
+	assert.Nil(t, bike)
+}
+
+// TestAdminService_UpdateBike_ClearPricePlan tests that a zero plan ID skips the lookup
+func TestAdminService_UpdateBike_ClearPricePlan(t *testing.T) {
+	mockRepo := &MockAdminRepository{
+		UpdateBikeFunc: func(bikeID int, latitude, longitude *float64, isAvailable *bool, pricePerMinute *float64, pricePlanID *int, bikeType *models.BikeType, batteryLevel *int) (*models.Bike, error) {
+			assert.Equal(t, 0, *pricePlanID)
+			return &models.Bike{ID: bikeID}, nil
+		},
+	}
+
+	planID := 0
+	service := &AdminService{adminRepo: mockRepo, pricePlanRepo: &MockPricePlanRepository{}}
+	bike, err := service.UpdateBike(t.Context(), 1, nil, nil, nil, nil, &planID, nil, nil)
+
+	assert.NoError(t, err)
+	assert.Nil(t, bike.PricePlanID)
+}
+
 // TestAdminService_CreateBike_Error tests error when creating bike
 func TestAdminService_CreateBike_Error(t *testing.T) {
 	mockRepo := &MockAdminRepository{
-		CreateBikeFunc: func(latitude, longitude, pricePerMinute float64) (*models.Bike, error) {
+		CreateBikeFunc: func(latitude, longitude, pricePerMinute float64, pricePlanID *int, bikeType models.BikeType, batteryLevel *int) (*models.Bike, error) {
 			return nil, errors.New("database error")
 		},
 	}
 
-	service := &AdminService{adminRepo: mockRepo}
-	bike, err := service.CreateBike(40.416775, -3.703790, 0.5)
+	price := 0.5
+	service := &AdminService{adminRepo: mockRepo, geofences: noGeofences()}
+	bike, err := service.CreateBike(t.Context(), 40.416775, -3.703790, &price, nil, models.BikeTypeClassic, nil)
 
 	assert.Error(t, err)
 	assert.Equal(t, "database error", err.Error())
@@ -122,10 +165,10 @@ func TestAdminService_CreateBike_Error(t *testing.T) {
 // TestAdminService_GetAllBikes_Success tests successful retrieval of all bikes
 func TestAdminService_GetAllBikes_Success(t *testing.T) {
 	mockRepo := &MockAdminRepository{
-		CountAllFunc: func() (int, error) {
+		CountAllFunc: func(spec *queryspec.Spec) (int, error) {
 			return 20, nil
 		},
-		GetAllBikesFunc: func(page, limit int) ([]*models.Bike, error) {
+		GetAllBikesFunc: func(spec *queryspec.Spec, page, limit int) ([]*models.Bike, error) {
 			bikes := []*models.Bike{
 				{ID: 1, Latitude: 40.416775, Longitude: -3.703790, IsAvailable: true, PricePerMinute: 0.5},
 				{ID: 2, Latitude: 40.417832, Longitude: -3.705064, IsAvailable: false, PricePerMinute: 0.5},
@@ -135,7 +178,7 @@ func TestAdminService_GetAllBikes_Success(t *testing.T) {
 	}
 
 	service := &AdminService{adminRepo: mockRepo}
-	bikes, total, err := service.GetAllBikes(1, 10)
+	bikes, total, err := service.GetAllBikes(t.Context(), nil, 1, 10)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 20, total)
@@ -147,13 +190,13 @@ func TestAdminService_GetAllBikes_Success(t *testing.T) {
 // TestAdminService_GetAllBikes_CountError tests error when counting bikes
 func TestAdminService_GetAllBikes_CountError(t *testing.T) {
 	mockRepo := &MockAdminRepository{
-		CountAllFunc: func() (int, error) {
+		CountAllFunc: func(spec *queryspec.Spec) (int, error) {
 			return 0, errors.New("count error")
 		},
 	}
 
 	service := &AdminService{adminRepo: mockRepo}
-	bikes, total, err := service.GetAllBikes(1, 10)
+	bikes, total, err := service.GetAllBikes(t.Context(), nil, 1, 10)
 
 	assert.Error(t, err)
 	assert.Equal(t, "count error", err.Error())
@@ -164,16 +207,16 @@ func TestAdminService_GetAllBikes_CountError(t *testing.T) {
 // TestAdminService_GetAllBikes_GetBikesError tests error when getting bikes
 func TestAdminService_GetAllBikes_GetBikesError(t *testing.T) {
 	mockRepo := &MockAdminRepository{
-		CountAllFunc: func() (int, error) {
+		CountAllFunc: func(spec *queryspec.Spec) (int, error) {
 			return 20, nil
 		},
-		GetAllBikesFunc: func(page, limit int) ([]*models.Bike, error) {
+		GetAllBikesFunc: func(spec *queryspec.Spec, page, limit int) ([]*models.Bike, error) {
 			return nil, errors.New("query error")
 		},
 	}
 
 	service := &AdminService{adminRepo: mockRepo}
-	bikes, total, err := service.GetAllBikes(1, 10)
+	bikes, total, err := service.GetAllBikes(t.Context(), nil, 1, 10)
 
 	assert.Error(t, err)
 	assert.Equal(t, "query error", err.Error())
@@ -189,7 +232,7 @@ func TestAdminService_UpdateBike_Success(t *testing.T) {
 	newPrice := 0.75
 
 	mockRepo := &MockAdminRepository{
-		UpdateBikeFunc: func(bikeID int, latitude, longitude *float64, isAvailable *bool, pricePerMinute *float64) (*models.Bike, error) {
+		UpdateBikeFunc: func(bikeID int, latitude, longitude *float64, isAvailable *bool, pricePerMinute *float64, pricePlanID *int, bikeType *models.BikeType, batteryLevel *int) (*models.Bike, error) {
 			return &models.Bike{
 				ID:             bikeID,
 				Latitude:       *latitude,
@@ -200,8 +243,8 @@ func TestAdminService_UpdateBike_Success(t *testing.T) {
 		},
 	}
 
-	service := &AdminService{adminRepo: mockRepo}
-	bike, err := service.UpdateBike(1, &newLat, &newLong, &newAvailable, &newPrice)
+	service := &AdminService{adminRepo: mockRepo, geofences: noGeofences()}
+	bike, err := service.UpdateBike(t.Context(), 1, &newLat, &newLong, &newAvailable, &newPrice, nil, nil, nil)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, bike)
@@ -217,13 +260,16 @@ func TestAdminService_UpdateBike_Error(t *testing.T) {
 	newLat := 41.0
 
 	mockRepo := &MockAdminRepository{
-		UpdateBikeFunc: func(bikeID int, latitude, longitude *float64, isAvailable *bool, pricePerMinute *float64) (*models.Bike, error) {
+		UpdateBikeFunc: func(bikeID int, latitude, longitude *float64, isAvailable *bool, pricePerMinute *float64, pricePlanID *int, bikeType *models.BikeType, batteryLevel *int) (*models.Bike, error) {
 			return nil, errors.New("update error")
 		},
+		GetBikeByIDFunc: func(bikeID int) (*models.Bike, error) {
+			return &models.Bike{ID: bikeID, Latitude: 40.0, Longitude: -4.0}, nil
+		},
 	}
 
-	service := &AdminService{adminRepo: mockRepo}
-	bike, err := service.UpdateBike(1, &newLat, nil, nil, nil)
+	service := &AdminService{adminRepo: mockRepo, geofences: noGeofences()}
+	bike, err := service.UpdateBike(t.Context(), 1, &newLat, nil, nil, nil, nil, nil, nil)
 
 	assert.Error(t, err)
 	assert.Equal(t, "update error", err.Error())
@@ -233,10 +279,10 @@ func TestAdminService_UpdateBike_Error(t *testing.T) {
 // TestAdminService_GetAllUsers_Success tests successful retrieval of all users
 func TestAdminService_GetAllUsers_Success(t *testing.T) {
 	mockRepo := &MockAdminRepository{
-		CountAllUsersFunc: func() (int, error) {
+		CountAllUsersFunc: func(spec *queryspec.Spec) (int, error) {
 			return 50, nil
 		},
-		GetAllUsersFunc: func(page, limit int) ([]*models.User, error) {
+		GetAllUsersFunc: func(spec *queryspec.Spec, page, limit int) ([]*models.User, error) {
 			users := []*models.User{
 				{ID: 1, FirstName: "John", LastName: "Doe", Email: "john@example.com"},
 				{ID: 2, FirstName: "Jane", LastName: "Smith", Email: "jane@example.com"},
@@ -246,7 +292,7 @@ func TestAdminService_GetAllUsers_Success(t *testing.T) {
 	}
 
 	service := &AdminService{adminRepo: mockRepo}
-	users, total, err := service.GetAllUsers(1, 10)
+	users, total, err := service.GetAllUsers(t.Context(), nil, 1, 10)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 50, total)
@@ -258,13 +304,13 @@ func TestAdminService_GetAllUsers_Success(t *testing.T) {
 // TestAdminService_GetAllUsers_CountError tests error when counting users
 func TestAdminService_GetAllUsers_CountError(t *testing.T) {
 	mockRepo := &MockAdminRepository{
-		CountAllUsersFunc: func() (int, error) {
+		CountAllUsersFunc: func(spec *queryspec.Spec) (int, error) {
 			return 0, errors.New("count error")
 		},
 	}
 
 	service := &AdminService{adminRepo: mockRepo}
-	users, total, err := service.GetAllUsers(1, 10)
+	users, total, err := service.GetAllUsers(t.Context(), nil, 1, 10)
 
 	assert.Error(t, err)
 	assert.Equal(t, "count error", err.Error())
@@ -275,16 +321,16 @@ func TestAdminService_GetAllUsers_CountError(t *testing.T) {
 // TestAdminService_GetAllUsers_GetUsersError tests error when getting users
 func TestAdminService_GetAllUsers_GetUsersError(t *testing.T) {
 	mockRepo := &MockAdminRepository{
-		CountAllUsersFunc: func() (int, error) {
+		CountAllUsersFunc: func(spec *queryspec.Spec) (int, error) {
 			return 50, nil
 		},
-		GetAllUsersFunc: func(page, limit int) ([]*models.User, error) {
+		GetAllUsersFunc: func(spec *queryspec.Spec, page, limit int) ([]*models.User, error) {
 			return nil, errors.New("query error")
 		},
 	}
 
 	service := &AdminService{adminRepo: mockRepo}
-	users, total, err := service.GetAllUsers(1, 10)
+	users, total, err := service.GetAllUsers(t.Context(), nil, 1, 10)
 
 	assert.Error(t, err)
 	assert.Equal(t, "query error", err.Error())
@@ -306,7 +352,7 @@ func TestAdminService_GetUserByID_Success(t *testing.T) {
 	}
 
 	service := &AdminService{adminRepo: mockRepo}
-	user, err := service.GetUserByID(1)
+	user, err := service.GetUserByID(t.Context(), 1)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, user)
@@ -323,7 +369,7 @@ func TestAdminService_GetUserByID_Error(t *testing.T) {
 	}
 
 	service := &AdminService{adminRepo: mockRepo}
-	user, err := service.GetUserByID(1)
+	user, err := service.GetUserByID(t.Context(), 1)
 
 	assert.Error(t, err)
 	assert.Equal(t, "user not found", err.Error())
@@ -349,7 +395,7 @@ func TestAdminService_UpdateUser_Success(t *testing.T) {
 	}
 
 	service := &AdminService{adminRepo: mockRepo}
-	user, err := service.UpdateUser(1, &newEmail, &newFirstName, nil, nil)
+	user, err := service.UpdateUser(t.Context(), 1, &newEmail, &newFirstName, nil, nil)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, user)
@@ -369,7 +415,7 @@ func TestAdminService_UpdateUser_EmailAlreadyExists(t *testing.T) {
 	}
 
 	service := &AdminService{adminRepo: mockRepo}
-	user, err := service.UpdateUser(1, &newEmail, nil, nil, nil)
+	user, err := service.UpdateUser(t.Context(), 1, &newEmail, nil, nil, nil)
 
 	assert.Error(t, err)
 	assert.Equal(t, constants.ErrEmailAlreadyExists, err)
@@ -387,7 +433,7 @@ func TestAdminService_UpdateUser_EmailCheckError(t *testing.T) {
 	}
 
 	service := &AdminService{adminRepo: mockRepo}
-	user, err := service.UpdateUser(1, &newEmail, nil, nil, nil)
+	user, err := service.UpdateUser(t.Context(), 1, &newEmail, nil, nil, nil)
 
 	assert.Error(t, err)
 	assert.Equal(t, "database error", err.Error())
@@ -409,7 +455,7 @@ func TestAdminService_UpdateUser_NoEmailChange(t *testing.T) {
 	}
 
 	service := &AdminService{adminRepo: mockRepo}
-	user, err := service.UpdateUser(1, nil, &newFirstName, nil, nil)
+	user, err := service.UpdateUser(t.Context(), 1, nil, &newFirstName, nil, nil)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, user)
@@ -427,10 +473,13 @@ func TestAdminService_UpdateUser_UpdateError(t *testing.T) {
 		UpdateUserFunc: func(userID int, email, firstName, lastName, hashedPassword *string) (*models.User, error) {
 			return nil, errors.New("update error")
 		},
+		GetBikeByIDFunc: func(bikeID int) (*models.Bike, error) {
+			return &models.Bike{ID: bikeID, Latitude: 40.0, Longitude: -4.0}, nil
+		},
 	}
 
-	service := &AdminService{adminRepo: mockRepo}
-	user, err := service.UpdateUser(1, &newEmail, nil, nil, nil)
+	service := &AdminService{adminRepo: mockRepo, geofences: noGeofences()}
+	user, err := service.UpdateUser(t.Context(), 1, &newEmail, nil, nil, nil)
 
 	assert.Error(t, err)
 	assert.Equal(t, "update error", err.Error())
@@ -440,10 +489,10 @@ func TestAdminService_UpdateUser_UpdateError(t *testing.T) {
 // TestAdminService_GetAllRentals_Success tests successful retrieval of all rentals
 func TestAdminService_GetAllRentals_Success(t *testing.T) {
 	mockRepo := &MockAdminRepository{
-		CountAllRentalsFunc: func() (int, error) {
+		CountAllRentalsFunc: func(spec *queryspec.Spec) (int, error) {
 			return 100, nil
 		},
-		GetAllRentalsFunc: func(page, limit int) ([]*models.Rental, error) {
+		GetAllRentalsFunc: func(spec *queryspec.Spec, page, limit int) ([]*models.Rental, error) {
 			rentals := []*models.Rental{
 				{ID: 1, UserID: 1, BikeID: 1, Status: "running"},
 				{ID: 2, UserID: 2, BikeID: 2, Status: "ended"},
@@ -453,25 +502,25 @@ func TestAdminService_GetAllRentals_Success(t *testing.T) {
 	}
 
 	service := &AdminService{adminRepo: mockRepo}
-	rentals, total, err := service.GetAllRentals(1, 10)
+	rentals, total, err := service.GetAllRentals(t.Context(), nil, 1, 10)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 100, total)
 	assert.Len(t, rentals, 2)
 	assert.Equal(t, 1, rentals[0].ID)
-	assert.Equal(t, "running", rentals[0].Status)
+	assert.Equal(t, models.RentalStatusRunning, rentals[0].Status)
 }
 
 // TestAdminService_GetAllRentals_CountError tests error when counting rentals
 func TestAdminService_GetAllRentals_CountError(t *testing.T) {
 	mockRepo := &MockAdminRepository{
-		CountAllRentalsFunc: func() (int, error) {
+		CountAllRentalsFunc: func(spec *queryspec.Spec) (int, error) {
 			return 0, errors.New("count error")
 		},
 	}
 
 	service := &AdminService{adminRepo: mockRepo}
-	rentals, total, err := service.GetAllRentals(1, 10)
+	rentals, total, err := service.GetAllRentals(t.Context(), nil, 1, 10)
 
 	assert.Error(t, err)
 	assert.Equal(t, "count error", err.Error())
@@ -482,16 +531,16 @@ func TestAdminService_GetAllRentals_CountError(t *testing.T) {
 // TestAdminService_GetAllRentals_GetRentalsError tests error when getting rentals
 func TestAdminService_GetAllRentals_GetRentalsError(t *testing.T) {
 	mockRepo := &MockAdminRepository{
-		CountAllRentalsFunc: func() (int, error) {
+		CountAllRentalsFunc: func(spec *queryspec.Spec) (int, error) {
 			return 100, nil
 		},
-		GetAllRentalsFunc: func(page, limit int) ([]*models.Rental, error) {
+		GetAllRentalsFunc: func(spec *queryspec.Spec, page, limit int) ([]*models.Rental, error) {
 			return nil, errors.New("query error")
 		},
 	}
 
 	service := &AdminService{adminRepo: mockRepo}
-	rentals, total, err := service.GetAllRentals(1, 10)
+	rentals, total, err := service.GetAllRentals(t.Context(), nil, 1, 10)
 
 	assert.Error(t, err)
 	assert.Equal(t, "query error", err.Error())
@@ -513,12 +562,12 @@ func TestAdminService_GetRentalByID_Success(t *testing.T) {
 	}
 
 	service := &AdminService{adminRepo: mockRepo}
-	rental, err := service.GetRentalByID(1)
+	rental, err := service.GetRentalByID(t.Context(), 1)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, rental)
 	assert.Equal(t, 1, rental.ID)
-	assert.Equal(t, "running", rental.Status)
+	assert.Equal(t, models.RentalStatusRunning, rental.Status)
 }
 
 // TestAdminService_GetRentalByID_Error tests error when getting rental by ID
@@ -530,49 +579,251 @@ func TestAdminService_GetRentalByID_Error(t *testing.T) {
 	}
 
 	service := &AdminService{adminRepo: mockRepo}
-	rental, err := service.GetRentalByID(1)
+	rental, err := service.GetRentalByID(t.Context(), 1)
 
 	assert.Error(t, err)
 	assert.Equal(t, "rental not found", err.Error())
 	assert.Nil(t, rental)
 }
 
-// TestAdminService_UpdateRental_Success tests successful rental update
-func TestAdminService_UpdateRental_Success(t *testing.T) {
-	newStatus := "ended"
+func newTestAdminService(db *database.DB) *AdminService {
+	return NewAdminService(
+		repositories.NewAdminRepository(db),
+		repositories.NewPricePlanRepository(db),
+		repositories.NewBikeTypePriceRepository(db),
+		repositories.NewGeofenceRepository(db),
+		repositories.NewUnitOfWork(db),
+		0.1,
+	)
+}
 
-	mockRepo := &MockAdminRepository{
-		UpdateRentalFunc: func(rentalID int, status *string) (*models.Rental, error) {
-			return &models.Rental{
-				ID:     rentalID,
-				Status: *status,
-			}, nil
-		},
-	}
+func TestAdminService_UpdateRental_EndFreesBike(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
 
-	service := &AdminService{adminRepo: mockRepo}
-	rental, err := service.UpdateRental(1, &newStatus)
+	started, err := newTestRentalService(db).StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	rental, err := newTestAdminService(db).UpdateRental(t.Context(), started.ID, models.RentalStatusEnded)
 
 	assert.NoError(t, err)
-	assert.NotNil(t, rental)
-	assert.Equal(t, 1, rental.ID)
-	assert.Equal(t, "ended", rental.Status)
+	assert.Equal(t, models.RentalStatusEnded, rental.Status)
+	assert.False(t, rental.EndTime.IsZero())
+	assert.NotNil(t, rental.Cost)
+	assert.NotNil(t, rental.DurationMinutes)
+	assert.True(t, bikeIsAvailable(t, db, bikeID))
 }
 
-// TestAdminService_UpdateRental_Error tests error when updating rental
-func TestAdminService_UpdateRental_Error(t *testing.T) {
-	newStatus := "ended"
+func TestAdminService_UpdateRental_CancelIsFree(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
 
-	mockRepo := &MockAdminRepository{
-		UpdateRentalFunc: func(rentalID int, status *string) (*models.Rental, error) {
-			return nil, errors.New("update error")
+	started, err := newTestRentalService(db).StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	rental, err := newTestAdminService(db).UpdateRental(t.Context(), started.ID, models.RentalStatusCancelled)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.RentalStatusCancelled, rental.Status)
+	assert.Equal(t, 0.0, *rental.Cost)
+	assert.True(t, bikeIsAvailable(t, db, bikeID))
+}
+
+func TestAdminService_UpdateRental_RefundEndedRental(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	rentalService := newTestRentalService(db)
+	service := newTestAdminService(db)
+
+	started, err := rentalService.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	_, err = service.UpdateRental(t.Context(), started.ID, models.RentalStatusRefunded)
+	var transitionErr *models.RentalTransitionError
+	assert.ErrorAs(t, err, &transitionErr)
+
+	backdateTestRental(t, db, started.ID, 3*time.Minute)
+	ended, err := rentalService.EndRental(t.Context(), 1, 40.420000, -3.700000)
+	assert.NoError(t, err)
+	assert.Greater(t, *ended.Cost, 0.0)
+
+	rental, err := service.UpdateRental(t.Context(), started.ID, models.RentalStatusRefunded)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.RentalStatusRefunded, rental.Status)
+	assert.Equal(t, 0.0, *rental.Cost)
+	assert.Len(t, rental.CostBreakdown, len(ended.CostBreakdown)+1)
+	refund := rental.CostBreakdown[len(rental.CostBreakdown)-1]
+	assert.Equal(t, pricing.LineItemRefund, refund.Code)
+	assert.Equal(t, -*ended.Cost, refund.Amount)
+	assert.Equal(t, ended.EndTime, rental.EndTime)
+	assert.True(t, bikeIsAvailable(t, db, bikeID))
+
+	_, err = service.UpdateRental(t.Context(), started.ID, models.RentalStatusRefunded)
+	assert.ErrorAs(t, err, &transitionErr)
+}
+
+func TestAdminService_UpdateRental_InvalidTransition(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+
+	started, err := newTestRentalService(db).StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	rental, err := newTestAdminService(db).UpdateRental(t.Context(), started.ID, "banana")
+
+	assert.Nil(t, rental)
+	assert.ErrorIs(t, err, constants.ErrInvalidRentalTransition)
+	var transitionErr *models.RentalTransitionError
+	assert.ErrorAs(t, err, &transitionErr)
+	assert.Equal(t, models.RentalStatusRunning, transitionErr.From)
+	assert.Equal(t, []models.RentalStatus{models.RentalStatusPaused, models.RentalStatusEnded, models.RentalStatusCancelled}, transitionErr.Allowed)
+	assert.False(t, bikeIsAvailable(t, db, bikeID))
+}
+
+func TestAdminService_UpdateRental_PauseAndResume(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	service := newTestAdminService(db)
+
+	started, err := newTestRentalService(db).StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	paused, err := service.UpdateRental(t.Context(), started.ID, models.RentalStatusPaused)
+	assert.NoError(t, err)
+	assert.Equal(t, models.RentalStatusPaused, paused.Status)
+	assert.Len(t, paused.Segments, 2)
+
+	resumed, err := service.UpdateRental(t.Context(), started.ID, models.RentalStatusRunning)
+	assert.NoError(t, err)
+	assert.Equal(t, models.RentalStatusRunning, resumed.Status)
+}
+
+func TestAdminService_UpdateRental_NotFound(t *testing.T) {
+	db := newTestDB(t)
+
+	rental, err := newTestAdminService(db).UpdateRental(t.Context(), 99, models.RentalStatusEnded)
+
+	assert.Equal(t, constants.ErrRentalNotFound, err)
+	assert.Nil(t, rental)
+}
+
+func TestAdminService_CreateBike_TypeDefaultPrice(t *testing.T) {
+	db := newTestDB(t)
+	service := newTestAdminService(db)
+
+	_, err := repositories.NewBikeTypePriceRepository(db).Save(t.Context(), models.BikeTypeEBike, 0.8)
+	assert.NoError(t, err)
+
+	battery := 64
+	ebike, err := service.CreateBike(t.Context(), 40.416775, -3.703790, nil, nil, models.BikeTypeEBike, &battery)
+	assert.NoError(t, err)
+	assert.Equal(t, models.BikeTypeEBike, ebike.Type)
+	assert.Equal(t, 0.8, ebike.PricePerMinute)
+	assert.Equal(t, 64, *ebike.BatteryLevel)
+	assert.Equal(t, 38.4, *ebike.RangeKm)
+
+	classic, err := service.CreateBike(t.Context(), 40.416775, -3.703790, nil, nil, models.BikeTypeClassic, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, classic.PricePerMinute)
+
+	price := 1.2
+	cargo, err := service.CreateBike(t.Context(), 40.416775, -3.703790, &price, nil, models.BikeTypeCargo, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.2, cargo.PricePerMinute, "an explicit price wins over the type default")
+}
+
+func TestAdminService_DefaultPrice(t *testing.T) {
+	explicit := 1.2
+	dbErr := errors.New("database error")
+
+	tests := []struct {
+		name           string
+		pricePerMinute *float64
+		typePrice      *models.BikeTypePrice
+		typeErr        error
+		wantLookup     bool
+		want           float64
+		wantErr        error
+	}{
+		{
+			name:           "Explicit price wins without looking up the type",
+			pricePerMinute: &explicit,
+			typePrice:      &models.BikeTypePrice{Type: models.BikeTypeEBike, PricePerMinute: 0.8},
+			want:           1.2,
+		},
+		{
+			name:       "Type price when no price is given",
+			typePrice:  &models.BikeTypePrice{Type: models.BikeTypeEBike, PricePerMinute: 0.8},
+			wantLookup: true,
+			want:       0.8,
+		},
+		{
+			name:       "Global default when the type has no price",
+			wantLookup: true,
+			want:       constants.DefaultPricePerMinute,
+		},
+		{
+			name:       "Lookup error",
+			typeErr:    dbErr,
+			wantLookup: true,
+			wantErr:    dbErr,
 		},
 	}
 
-	service := &AdminService{adminRepo: mockRepo}
-	rental, err := service.UpdateRental(1, &newStatus)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			looked := false
+			mockRepo := &MockBikeTypePriceRepository{
+				GetByTypeFunc: func(bikeType models.BikeType) (*models.BikeTypePrice, error) {
+					looked = true
+					assert.Equal(t, models.BikeTypeEBike, bikeType)
+					return tc.typePrice, tc.typeErr
+				},
+			}
 
-	assert.Error(t, err)
-	assert.Equal(t, "update error", err.Error())
-	assert.Nil(t, rental)
-}
\ No newline at end of file
+			service := &AdminService{bikeTypePriceRepo: mockRepo}
+			price, err := service.defaultPrice(t.Context(), models.BikeTypeEBike, tc.pricePerMinute)
+
+			assert.Equal(t, tc.wantLookup, looked)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, price)
+		})
+	}
+}
+
+func TestAdminService_BatteryOnlyForEBikes(t *testing.T) {
+	db := newTestDB(t)
+	service := newTestAdminService(db)
+	battery := 50
+
+	_, err := service.CreateBike(t.Context(), 40.416775, -3.703790, nil, nil, models.BikeTypeCargo, &battery)
+	assert.ErrorIs(t, err, constants.ErrBatteryNotSupported)
+
+	bike, err := service.CreateBike(t.Context(), 40.416775, -3.703790, nil, nil, models.BikeTypeClassic, nil)
+	assert.NoError(t, err)
+
+	_, err = service.UpdateBike(t.Context(), bike.ID, nil, nil, nil, nil, nil, nil, &battery)
+	assert.ErrorIs(t, err, constants.ErrBatteryNotSupported)
+
+	ebike := models.BikeTypeEBike
+	bike, err = service.UpdateBike(t.Context(), bike.ID, nil, nil, nil, nil, nil, &ebike, &battery)
+	assert.NoError(t, err)
+	assert.Equal(t, models.BikeTypeEBike, bike.Type)
+	assert.Equal(t, 50, *bike.BatteryLevel)
+
+	battery = 15
+	bike, err = service.UpdateBike(t.Context(), bike.ID, nil, nil, nil, nil, nil, nil, &battery)
+	assert.NoError(t, err)
+	assert.Equal(t, 15, *bike.BatteryLevel)
+
+	cargo := models.BikeTypeCargo
+	bike, err = service.UpdateBike(t.Context(), bike.ID, nil, nil, nil, nil, nil, &cargo, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, bike.BatteryLevel)
+	assert.Nil(t, bike.RangeKm)
+}
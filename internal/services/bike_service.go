@@ -1,35 +1,87 @@
 package services
 
 import (
+	"context"
+	"sort"
+
 	"github.com/Nimirandad/bike-rental-service/internal/models"
 	"github.com/Nimirandad/bike-rental-service/internal/repositories"
+	"github.com/Nimirandad/bike-rental-service/internal/utils"
 )
 
 type BikeRepository interface {
-	CountAvailable() (int, error)
-	GetAvailable(page, limit int) ([]*models.Bike, error)
-	GetByID(bikeID int) (*models.Bike, error)
-	UpdateAvailability(bikeID int, isAvailable bool) error
+	CountAvailableMatching(ctx context.Context, filter models.BikeFilter) (int, error)
+	GetAvailable(ctx context.Context, filter models.BikeFilter, page, limit int) ([]*models.Bike, error)
+	GetAvailableInBounds(ctx context.Context, filter models.BikeFilter, minLat, maxLat, minLong, maxLong float64) ([]*models.Bike, error)
+	GetByID(ctx context.Context, bikeID int) (*models.Bike, error)
+	UpdateAvailability(ctx context.Context, bikeID int, isAvailable bool) error
 }
 
 type BikeService struct {
-	bikeRepo BikeRepository
+	bikeRepo        BikeRepository
+	minBatteryLevel int
+}
+
+// NewBikeService returns a service that hides e-bikes whose battery level is
+// below minBatteryLevel percent from riders.
+func NewBikeService(bikeRepo *repositories.BikeRepository, minBatteryLevel int) *BikeService {
+	return &BikeService{bikeRepo: bikeRepo, minBatteryLevel: minBatteryLevel}
 }
 
-func NewBikeService(bikeRepo *repositories.BikeRepository) *BikeService {
-	return &BikeService{bikeRepo: bikeRepo}
+// filter returns the filter for bikes offered to riders. An empty bikeType
+// matches every type.
+func (s *BikeService) filter(bikeType models.BikeType) models.BikeFilter {
+	return models.BikeFilter{Type: bikeType, MinBatteryLevel: s.minBatteryLevel}
 }
 
-func (s *BikeService) GetAvailableBikes(page, limit int) ([]*models.Bike, int, error) {
-	total, err := s.bikeRepo.CountAvailable()
+func (s *BikeService) GetAvailableBikes(ctx context.Context, bikeType models.BikeType, page, limit int) ([]*models.Bike, int, error) {
+	filter := s.filter(bikeType)
+
+	total, err := s.bikeRepo.CountAvailableMatching(ctx, filter)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	bikes, err := s.bikeRepo.GetAvailable(page, limit)
+	bikes, err := s.bikeRepo.GetAvailable(ctx, filter, page, limit)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	return bikes, total, nil
-}
\ No newline at end of file
+}
+
+func (s *BikeService) GetNearbyBikes(ctx context.Context, bikeType models.BikeType, latitude, longitude, radiusKm float64, page, limit int) ([]*models.Bike, int, error) {
+	minLat, maxLat, minLong, maxLong := utils.BoundingBox(latitude, longitude, radiusKm)
+
+	candidates, err := s.bikeRepo.GetAvailableInBounds(ctx, s.filter(bikeType), minLat, maxLat, minLong, maxLong)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	bikes := []*models.Bike{}
+	for _, bike := range candidates {
+		distance := utils.HaversineDistance(latitude, longitude, bike.Latitude, bike.Longitude)
+		if distance > radiusKm {
+			continue
+		}
+		bike.DistanceKm = &distance
+		bikes = append(bikes, bike)
+	}
+
+	sort.SliceStable(bikes, func(i, j int) bool {
+		return *bikes[i].DistanceKm < *bikes[j].DistanceKm
+	})
+
+	total := len(bikes)
+	offset := (page - 1) * limit
+	if offset >= total {
+		return []*models.Bike{}, total, nil
+	}
+
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+
+	return bikes[offset:end], total, nil
+}
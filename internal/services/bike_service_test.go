@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -9,34 +10,39 @@ import (
 )
 
 type MockBikeRepository struct {
-	CountAvailableFunc     func() (int, error)
-	GetAvailableFunc       func(page, limit int) ([]*models.Bike, error)
-	GetByIDFunc            func(bikeID int) (*models.Bike, error)
-	UpdateAvailabilityFunc func(bikeID int, isAvailable bool) error
+	CountAvailableMatchingFunc func(filter models.BikeFilter) (int, error)
+	GetAvailableFunc           func(filter models.BikeFilter, page, limit int) ([]*models.Bike, error)
+	GetAvailableInBoundsFunc   func(filter models.BikeFilter, minLat, maxLat, minLong, maxLong float64) ([]*models.Bike, error)
+	GetByIDFunc                func(bikeID int) (*models.Bike, error)
+	UpdateAvailabilityFunc     func(bikeID int, isAvailable bool) error
 }
 
-func (m *MockBikeRepository) CountAvailable() (int, error) {
-	return m.CountAvailableFunc()
+func (m *MockBikeRepository) CountAvailableMatching(ctx context.Context, filter models.BikeFilter) (int, error) {
+	return m.CountAvailableMatchingFunc(filter)
 }
 
-func (m *MockBikeRepository) GetAvailable(page, limit int) ([]*models.Bike, error) {
-	return m.GetAvailableFunc(page, limit)
+func (m *MockBikeRepository) GetAvailable(ctx context.Context, filter models.BikeFilter, page, limit int) ([]*models.Bike, error) {
+	return m.GetAvailableFunc(filter, page, limit)
 }
 
-func (m *MockBikeRepository) GetByID(bikeID int) (*models.Bike, error) {
+func (m *MockBikeRepository) GetAvailableInBounds(ctx context.Context, filter models.BikeFilter, minLat, maxLat, minLong, maxLong float64) ([]*models.Bike, error) {
+	return m.GetAvailableInBoundsFunc(filter, minLat, maxLat, minLong, maxLong)
+}
+
+func (m *MockBikeRepository) GetByID(ctx context.Context, bikeID int) (*models.Bike, error) {
 	return m.GetByIDFunc(bikeID)
 }
 
-func (m *MockBikeRepository) UpdateAvailability(bikeID int, isAvailable bool) error {
+func (m *MockBikeRepository) UpdateAvailability(ctx context.Context, bikeID int, isAvailable bool) error {
 	return m.UpdateAvailabilityFunc(bikeID, isAvailable)
 }
 
 func TestBikeService_GetAvailableBikes_Success(t *testing.T) {
 	mockRepo := &MockBikeRepository{
-		CountAvailableFunc: func() (int, error) {
+		CountAvailableMatchingFunc: func(filter models.BikeFilter) (int, error) {
 			return 5, nil
 		},
-		GetAvailableFunc: func(page, limit int) ([]*models.Bike, error) {
+		GetAvailableFunc: func(filter models.BikeFilter, page, limit int) ([]*models.Bike, error) {
 			bikes := []*models.Bike{
 				{ID: 1, Latitude: 40.416775, Longitude: -3.703790, IsAvailable: true, PricePerMinute: 0.5},
 				{ID: 2, Latitude: 40.417832, Longitude: -3.705064, IsAvailable: true, PricePerMinute: 0.5},
@@ -46,7 +52,7 @@ func TestBikeService_GetAvailableBikes_Success(t *testing.T) {
 	}
 
 	service := &BikeService{bikeRepo: mockRepo}
-	bikes, total, err := service.GetAvailableBikes(1, 10)
+	bikes, total, err := service.GetAvailableBikes(t.Context(), "", 1, 10)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 5, total)
@@ -57,13 +63,13 @@ func TestBikeService_GetAvailableBikes_Success(t *testing.T) {
 
 func TestBikeService_GetAvailableBikes_CountError(t *testing.T) {
 	mockRepo := &MockBikeRepository{
-		CountAvailableFunc: func() (int, error) {
+		CountAvailableMatchingFunc: func(filter models.BikeFilter) (int, error) {
 			return 0, errors.New("database error")
 		},
 	}
 
 	service := &BikeService{bikeRepo: mockRepo}
-	bikes, total, err := service.GetAvailableBikes(1, 10)
+	bikes, total, err := service.GetAvailableBikes(t.Context(), "", 1, 10)
 
 	assert.Error(t, err)
 	assert.Equal(t, "database error", err.Error())
@@ -73,16 +79,16 @@ func TestBikeService_GetAvailableBikes_CountError(t *testing.T) {
 
 func TestBikeService_GetAvailableBikes_GetAvailableError(t *testing.T) {
 	mockRepo := &MockBikeRepository{
-		CountAvailableFunc: func() (int, error) {
+		CountAvailableMatchingFunc: func(filter models.BikeFilter) (int, error) {
 			return 5, nil
 		},
-		GetAvailableFunc: func(page, limit int) ([]*models.Bike, error) {
+		GetAvailableFunc: func(filter models.BikeFilter, page, limit int) ([]*models.Bike, error) {
 			return nil, errors.New("query error")
 		},
 	}
 
 	service := &BikeService{bikeRepo: mockRepo}
-	bikes, total, err := service.GetAvailableBikes(1, 10)
+	bikes, total, err := service.GetAvailableBikes(t.Context(), "", 1, 10)
 
 	assert.Error(t, err)
 	assert.Equal(t, "query error", err.Error())
@@ -92,18 +98,117 @@ func TestBikeService_GetAvailableBikes_GetAvailableError(t *testing.T) {
 
 func TestBikeService_GetAvailableBikes_EmptyResult(t *testing.T) {
 	mockRepo := &MockBikeRepository{
-		CountAvailableFunc: func() (int, error) {
+		CountAvailableMatchingFunc: func(filter models.BikeFilter) (int, error) {
 			return 0, nil
 		},
-		GetAvailableFunc: func(page, limit int) ([]*models.Bike, error) {
+		GetAvailableFunc: func(filter models.BikeFilter, page, limit int) ([]*models.Bike, error) {
 			return []*models.Bike{}, nil
 		},
 	}
 
 	service := &BikeService{bikeRepo: mockRepo}
-	bikes, total, err := service.GetAvailableBikes(1, 10)
+	bikes, total, err := service.GetAvailableBikes(t.Context(), "", 1, 10)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 0, total)
 	assert.Empty(t, bikes)
-}
\ No newline at end of file
+}
+
+func TestBikeService_GetNearbyBikes_FiltersAndSortsByDistance(t *testing.T) {
+	mockRepo := &MockBikeRepository{
+		GetAvailableInBoundsFunc: func(filter models.BikeFilter, minLat, maxLat, minLong, maxLong float64) ([]*models.Bike, error) {
+			assert.Less(t, minLat, 51.5074)
+			assert.Greater(t, maxLat, 51.5074)
+			assert.Less(t, minLong, -0.1278)
+			assert.Greater(t, maxLong, -0.1278)
+			return []*models.Bike{
+				{ID: 1, Latitude: 51.5155, Longitude: -0.0922, IsAvailable: true},
+				{ID: 2, Latitude: 51.5080, Longitude: -0.1280, IsAvailable: true},
+				{ID: 3, Latitude: 51.5194, Longitude: -0.1269, IsAvailable: true},
+				{ID: 4, Latitude: 51.5300, Longitude: -0.1000, IsAvailable: true},
+			}, nil
+		},
+	}
+
+	service := &BikeService{bikeRepo: mockRepo}
+	bikes, total, err := service.GetNearbyBikes(t.Context(), "", 51.5074, -0.1278, 2.0, 1, 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Len(t, bikes, 2)
+	assert.Equal(t, 2, bikes[0].ID)
+	assert.Equal(t, 3, bikes[1].ID)
+	assert.NotNil(t, bikes[0].DistanceKm)
+	assert.Less(t, *bikes[0].DistanceKm, *bikes[1].DistanceKm)
+	assert.LessOrEqual(t, *bikes[1].DistanceKm, 2.0)
+}
+
+func TestBikeService_GetNearbyBikes_Pagination(t *testing.T) {
+	mockRepo := &MockBikeRepository{
+		GetAvailableInBoundsFunc: func(filter models.BikeFilter, minLat, maxLat, minLong, maxLong float64) ([]*models.Bike, error) {
+			return []*models.Bike{
+				{ID: 1, Latitude: 51.5074, Longitude: -0.1278},
+				{ID: 2, Latitude: 51.5084, Longitude: -0.1278},
+				{ID: 3, Latitude: 51.5094, Longitude: -0.1278},
+			}, nil
+		},
+	}
+
+	service := &BikeService{bikeRepo: mockRepo}
+
+	bikes, total, err := service.GetNearbyBikes(t.Context(), "", 51.5074, -0.1278, 1.0, 2, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Len(t, bikes, 1)
+	assert.Equal(t, 3, bikes[0].ID)
+
+	bikes, total, err = service.GetNearbyBikes(t.Context(), "", 51.5074, -0.1278, 1.0, 3, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Empty(t, bikes)
+}
+
+func TestBikeService_GetNearbyBikes_RepositoryError(t *testing.T) {
+	mockRepo := &MockBikeRepository{
+		GetAvailableInBoundsFunc: func(filter models.BikeFilter, minLat, maxLat, minLong, maxLong float64) ([]*models.Bike, error) {
+			return nil, errors.New("query error")
+		},
+	}
+
+	service := &BikeService{bikeRepo: mockRepo}
+	bikes, total, err := service.GetNearbyBikes(t.Context(), "", 51.5074, -0.1278, 1.0, 1, 10)
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, total)
+	assert.Nil(t, bikes)
+}
+
+func TestBikeService_AppliesTypeAndBatteryFilter(t *testing.T) {
+	var counted, listed, nearby models.BikeFilter
+	mockRepo := &MockBikeRepository{
+		CountAvailableMatchingFunc: func(filter models.BikeFilter) (int, error) {
+			counted = filter
+			return 0, nil
+		},
+		GetAvailableFunc: func(filter models.BikeFilter, page, limit int) ([]*models.Bike, error) {
+			listed = filter
+			return []*models.Bike{}, nil
+		},
+		GetAvailableInBoundsFunc: func(filter models.BikeFilter, minLat, maxLat, minLong, maxLong float64) ([]*models.Bike, error) {
+			nearby = filter
+			return []*models.Bike{}, nil
+		},
+	}
+
+	service := &BikeService{bikeRepo: mockRepo, minBatteryLevel: 20}
+
+	_, _, err := service.GetAvailableBikes(t.Context(), models.BikeTypeEBike, 1, 10)
+	assert.NoError(t, err)
+	want := models.BikeFilter{Type: models.BikeTypeEBike, MinBatteryLevel: 20}
+	assert.Equal(t, want, counted)
+	assert.Equal(t, want, listed)
+
+	_, _, err = service.GetNearbyBikes(t.Context(), "", 51.5074, -0.1278, 1.0, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, models.BikeFilter{MinBatteryLevel: 20}, nearby)
+}
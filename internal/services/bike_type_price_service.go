@@ -0,0 +1,34 @@
+package services
+
+import (
+	"context"
+
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/repositories"
+)
+
+type BikeTypePriceRepository interface {
+	GetAll(ctx context.Context) ([]*models.BikeTypePrice, error)
+	GetByType(ctx context.Context, bikeType models.BikeType) (*models.BikeTypePrice, error)
+	Save(ctx context.Context, bikeType models.BikeType, pricePerMinute float64) (*models.BikeTypePrice, error)
+}
+
+// BikeTypePriceService manages the default price per minute of each bike
+// type. Changing a default only affects bikes created afterwards.
+type BikeTypePriceService struct {
+	bikeTypePriceRepo BikeTypePriceRepository
+}
+
+func NewBikeTypePriceService(bikeTypePriceRepo *repositories.BikeTypePriceRepository) *BikeTypePriceService {
+	return &BikeTypePriceService{
+		bikeTypePriceRepo: bikeTypePriceRepo,
+	}
+}
+
+func (s *BikeTypePriceService) GetBikeTypePrices(ctx context.Context) ([]*models.BikeTypePrice, error) {
+	return s.bikeTypePriceRepo.GetAll(ctx)
+}
+
+func (s *BikeTypePriceService) UpdateBikeTypePrice(ctx context.Context, bikeType models.BikeType, pricePerMinute float64) (*models.BikeTypePrice, error) {
+	return s.bikeTypePriceRepo.Save(ctx, bikeType, pricePerMinute)
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockBikeTypePriceRepository struct {
+	GetAllFunc    func() ([]*models.BikeTypePrice, error)
+	GetByTypeFunc func(bikeType models.BikeType) (*models.BikeTypePrice, error)
+	SaveFunc      func(bikeType models.BikeType, pricePerMinute float64) (*models.BikeTypePrice, error)
+}
+
+func (m *MockBikeTypePriceRepository) GetAll(ctx context.Context) ([]*models.BikeTypePrice, error) {
+	return m.GetAllFunc()
+}
+
+func (m *MockBikeTypePriceRepository) GetByType(ctx context.Context, bikeType models.BikeType) (*models.BikeTypePrice, error) {
+	return m.GetByTypeFunc(bikeType)
+}
+
+func (m *MockBikeTypePriceRepository) Save(ctx context.Context, bikeType models.BikeType, pricePerMinute float64) (*models.BikeTypePrice, error) {
+	return m.SaveFunc(bikeType, pricePerMinute)
+}
+
+func TestBikeTypePriceService_GetBikeTypePrices_Success(t *testing.T) {
+	mockRepo := &MockBikeTypePriceRepository{
+		GetAllFunc: func() ([]*models.BikeTypePrice, error) {
+			return []*models.BikeTypePrice{
+				{Type: models.BikeTypeClassic, PricePerMinute: 0.5},
+				{Type: models.BikeTypeEBike, PricePerMinute: 0.8},
+			}, nil
+		},
+	}
+
+	service := &BikeTypePriceService{bikeTypePriceRepo: mockRepo}
+	prices, err := service.GetBikeTypePrices(t.Context())
+
+	assert.NoError(t, err)
+	assert.Len(t, prices, 2)
+	assert.Equal(t, models.BikeTypeEBike, prices[1].Type)
+	assert.Equal(t, 0.8, prices[1].PricePerMinute)
+}
+
+func TestBikeTypePriceService_GetBikeTypePrices_Error(t *testing.T) {
+	mockRepo := &MockBikeTypePriceRepository{
+		GetAllFunc: func() ([]*models.BikeTypePrice, error) {
+			return nil, errors.New("database error")
+		},
+	}
+
+	service := &BikeTypePriceService{bikeTypePriceRepo: mockRepo}
+	prices, err := service.GetBikeTypePrices(t.Context())
+
+	assert.EqualError(t, err, "database error")
+	assert.Nil(t, prices)
+}
+
+func TestBikeTypePriceService_UpdateBikeTypePrice_Success(t *testing.T) {
+	mockRepo := &MockBikeTypePriceRepository{
+		SaveFunc: func(bikeType models.BikeType, pricePerMinute float64) (*models.BikeTypePrice, error) {
+			assert.Equal(t, models.BikeTypeCargo, bikeType)
+			assert.Equal(t, 0.9, pricePerMinute)
+			return &models.BikeTypePrice{Type: bikeType, PricePerMinute: pricePerMinute}, nil
+		},
+	}
+
+	service := &BikeTypePriceService{bikeTypePriceRepo: mockRepo}
+	price, err := service.UpdateBikeTypePrice(t.Context(), models.BikeTypeCargo, 0.9)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.BikeTypeCargo, price.Type)
+	assert.Equal(t, 0.9, price.PricePerMinute)
+}
+
+func TestBikeTypePriceService_UpdateBikeTypePrice_Error(t *testing.T) {
+	mockRepo := &MockBikeTypePriceRepository{
+		SaveFunc: func(bikeType models.BikeType, pricePerMinute float64) (*models.BikeTypePrice, error) {
+			return nil, errors.New("database error")
+		},
+	}
+
+	service := &BikeTypePriceService{bikeTypePriceRepo: mockRepo}
+	price, err := service.UpdateBikeTypePrice(t.Context(), models.BikeTypeCargo, 0.9)
+
+	assert.EqualError(t, err, "database error")
+	assert.Nil(t, price)
+}
+
+// TestBikeTypePriceService_UpdateBikeTypePrice_AffectsNewBikesOnly tests that
+// a new default prices bikes created afterwards and leaves existing ones
+func TestBikeTypePriceService_UpdateBikeTypePrice_AffectsNewBikesOnly(t *testing.T) {
+	db := newTestDB(t)
+	admin := newTestAdminService(db)
+	service := NewBikeTypePriceService(repositories.NewBikeTypePriceRepository(db))
+
+	before, err := admin.CreateBike(t.Context(), 40.416775, -3.703790, nil, nil, models.BikeTypeCargo, nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, 0.9, before.PricePerMinute)
+
+	_, err = service.UpdateBikeTypePrice(t.Context(), models.BikeTypeCargo, 0.9)
+	assert.NoError(t, err)
+
+	after, err := admin.CreateBike(t.Context(), 40.416775, -3.703790, nil, nil, models.BikeTypeCargo, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.9, after.PricePerMinute)
+
+	existing, err := repositories.NewBikeRepository(db).GetByID(t.Context(), before.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, before.PricePerMinute, existing.PricePerMinute)
+}
@@ -0,0 +1,10 @@
+package services
+
+import "time"
+
+// utcNow is the time source of services that store times compared by the
+// database, such as expiries and retry schedules. Times are kept in UTC and
+// truncated to the second so they compare correctly as stored text.
+func utcNow() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
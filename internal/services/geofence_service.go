@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/repositories"
+	"github.com/Nimirandad/bike-rental-service/internal/utils"
+)
+
+// GeofenceLookup finds the geofences that apply at a point. It is satisfied
+// by *repositories.GeofenceRepository, both on the pool and inside a
+// transaction.
+type GeofenceLookup interface {
+	GetByBoundsContaining(ctx context.Context, latitude, longitude float64) ([]*models.Geofence, error)
+	CountByKind(ctx context.Context, kind models.GeofenceKind) (int, error)
+}
+
+type GeofenceRepository interface {
+	GetAll(ctx context.Context, kind models.GeofenceKind) ([]*models.Geofence, error)
+}
+
+type GeofenceService struct {
+	geofenceRepo GeofenceRepository
+	uow          UnitOfWork
+}
+
+func NewGeofenceService(geofenceRepo *repositories.GeofenceRepository, uow *repositories.UnitOfWork) *GeofenceService {
+	return &GeofenceService{
+		geofenceRepo: geofenceRepo,
+		uow:          uow,
+	}
+}
+
+// ReplaceGeofences swaps every geofence of kind for geofences in a single
+// transaction, so readers never see a partial upload. An empty list removes
+// the kind altogether.
+func (s *GeofenceService) ReplaceGeofences(ctx context.Context, kind models.GeofenceKind, geofences []*models.Geofence) ([]*models.Geofence, error) {
+	var stored []*models.Geofence
+	err := s.uow.WithTx(ctx, func(tx *repositories.Tx) error {
+		if err := tx.Geofences.DeleteByKind(ctx, kind); err != nil {
+			return err
+		}
+
+		for _, geofence := range geofences {
+			geofence.Kind = kind
+			if err := tx.Geofences.Create(ctx, geofence); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		stored, err = tx.Geofences.GetAll(ctx, kind)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stored, nil
+}
+
+// GetGeofences returns the geofences of kind, or all of them when kind is
+// empty.
+func (s *GeofenceService) GetGeofences(ctx context.Context, kind models.GeofenceKind) ([]*models.Geofence, error) {
+	return s.geofenceRepo.GetAll(ctx, kind)
+}
+
+// zoneCheck is where a point stands against the geofences.
+type zoneCheck struct {
+	// OutsideOperatingArea is set when operating areas are defined and none
+	// contains the point.
+	OutsideOperatingArea bool
+	NoParking            bool
+}
+
+func checkZones(ctx context.Context, lookup GeofenceLookup, latitude, longitude float64) (zoneCheck, error) {
+	var check zoneCheck
+
+	candidates, err := lookup.GetByBoundsContaining(ctx, latitude, longitude)
+	if err != nil {
+		return check, err
+	}
+
+	inOperatingArea := false
+	for _, geofence := range candidates {
+		if !utils.PointInRings(latitude, longitude, geofence.Rings) {
+			continue
+		}
+		switch geofence.Kind {
+		case models.GeofenceOperatingArea:
+			inOperatingArea = true
+		case models.GeofenceNoParking:
+			check.NoParking = true
+		}
+	}
+
+	if !inOperatingArea {
+		areas, err := lookup.CountByKind(ctx, models.GeofenceOperatingArea)
+		if err != nil {
+			return check, err
+		}
+		check.OutsideOperatingArea = areas > 0
+	}
+
+	return check, nil
+}
+
+// validatePlacement rejects a bike location outside the operating area or
+// inside a no-parking zone.
+func validatePlacement(ctx context.Context, lookup GeofenceLookup, latitude, longitude float64) error {
+	check, err := checkZones(ctx, lookup, latitude, longitude)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case check.OutsideOperatingArea:
+		return constants.ErrOutsideOperatingArea
+	case check.NoParking:
+		return constants.ErrNoParkingZone
+	}
+	return nil
+}
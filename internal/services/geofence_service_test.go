@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/database"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/pricing"
+	"github.com/Nimirandad/bike-rental-service/internal/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockGeofenceLookup struct {
+	GetByBoundsContainingFunc func(latitude, longitude float64) ([]*models.Geofence, error)
+	CountByKindFunc           func(kind models.GeofenceKind) (int, error)
+}
+
+func (m *MockGeofenceLookup) GetByBoundsContaining(ctx context.Context, latitude, longitude float64) ([]*models.Geofence, error) {
+	return m.GetByBoundsContainingFunc(latitude, longitude)
+}
+
+func (m *MockGeofenceLookup) CountByKind(ctx context.Context, kind models.GeofenceKind) (int, error) {
+	return m.CountByKindFunc(kind)
+}
+
+// noGeofences is a lookup for a city without geofences.
+func noGeofences() *MockGeofenceLookup {
+	return &MockGeofenceLookup{
+		GetByBoundsContainingFunc: func(latitude, longitude float64) ([]*models.Geofence, error) {
+			return nil, nil
+		},
+		CountByKindFunc: func(kind models.GeofenceKind) (int, error) {
+			return 0, nil
+		},
+	}
+}
+
+// squareGeofence returns a geofence covering the square from (lat, lng) to
+// (lat+size, lng+size).
+func squareGeofence(kind models.GeofenceKind, lat, lng, size float64) *models.Geofence {
+	geofence := &models.Geofence{
+		Kind: kind,
+		Rings: [][]models.GeoPoint{{
+			{Latitude: lat, Longitude: lng},
+			{Latitude: lat, Longitude: lng + size},
+			{Latitude: lat + size, Longitude: lng + size},
+			{Latitude: lat + size, Longitude: lng},
+			{Latitude: lat, Longitude: lng},
+		}},
+	}
+	geofence.SetBounds()
+	return geofence
+}
+
+func insertTestGeofence(t *testing.T, db *database.DB, geofence *models.Geofence) {
+	t.Helper()
+
+	if err := repositories.NewGeofenceRepository(db).Create(t.Context(), geofence); err != nil {
+		t.Fatalf("failed to insert geofence: %v", err)
+	}
+}
+
+func TestCheckZones(t *testing.T) {
+	area := squareGeofence(models.GeofenceOperatingArea, 40.0, -4.0, 1.0)
+	noParking := squareGeofence(models.GeofenceNoParking, 40.4, -3.8, 0.1)
+	lookup := &MockGeofenceLookup{
+		GetByBoundsContainingFunc: func(latitude, longitude float64) ([]*models.Geofence, error) {
+			return []*models.Geofence{area, noParking}, nil
+		},
+		CountByKindFunc: func(kind models.GeofenceKind) (int, error) {
+			assert.Equal(t, models.GeofenceOperatingArea, kind)
+			return 1, nil
+		},
+	}
+
+	check, err := checkZones(t.Context(), lookup, 40.45, -3.75)
+	assert.NoError(t, err)
+	assert.False(t, check.OutsideOperatingArea)
+	assert.True(t, check.NoParking)
+
+	check, err = checkZones(t.Context(), lookup, 40.2, -3.5)
+	assert.NoError(t, err)
+	assert.False(t, check.OutsideOperatingArea)
+	assert.False(t, check.NoParking)
+
+	check, err = checkZones(t.Context(), lookup, 41.5, -3.5)
+	assert.NoError(t, err)
+	assert.True(t, check.OutsideOperatingArea)
+}
+
+func TestCheckZones_NoOperatingAreas(t *testing.T) {
+	check, err := checkZones(t.Context(), noGeofences(), 51.5, -0.12)
+
+	assert.NoError(t, err)
+	assert.False(t, check.OutsideOperatingArea)
+	assert.False(t, check.NoParking)
+}
+
+func TestCheckZones_LookupError(t *testing.T) {
+	lookup := &MockGeofenceLookup{
+		GetByBoundsContainingFunc: func(latitude, longitude float64) ([]*models.Geofence, error) {
+			return nil, errors.New("query error")
+		},
+	}
+
+	_, err := checkZones(t.Context(), lookup, 51.5, -0.12)
+	assert.EqualError(t, err, "query error")
+}
+
+func TestGeofenceService_ReplaceGeofences(t *testing.T) {
+	db := newTestDB(t)
+	service := NewGeofenceService(repositories.NewGeofenceRepository(db), repositories.NewUnitOfWork(db))
+
+	insertTestGeofence(t, db, squareGeofence(models.GeofenceOperatingArea, 40.0, -4.0, 1.0))
+	insertTestGeofence(t, db, squareGeofence(models.GeofenceNoParking, 40.4, -3.8, 0.1))
+
+	stored, err := service.ReplaceGeofences(t.Context(), models.GeofenceNoParking, []*models.Geofence{
+		squareGeofence("", 40.1, -3.9, 0.1),
+		squareGeofence("", 40.6, -3.2, 0.1),
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, stored, 2)
+	for _, geofence := range stored {
+		assert.Equal(t, models.GeofenceNoParking, geofence.Kind)
+	}
+
+	all, err := service.GetGeofences(t.Context(), "")
+	assert.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	stored, err = service.ReplaceGeofences(t.Context(), models.GeofenceNoParking, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, stored)
+}
+
+func TestAdminService_CreateBike_Geofences(t *testing.T) {
+	db := newTestDB(t)
+	insertTestGeofence(t, db, squareGeofence(models.GeofenceOperatingArea, 40.0, -4.0, 1.0))
+	insertTestGeofence(t, db, squareGeofence(models.GeofenceNoParking, 40.4, -3.8, 0.1))
+
+	service := newTestAdminService(db)
+
+	_, err := service.CreateBike(t.Context(), 41.5, -3.5, nil, nil, models.BikeTypeClassic, nil)
+	assert.ErrorIs(t, err, constants.ErrOutsideOperatingArea)
+
+	_, err = service.CreateBike(t.Context(), 40.45, -3.75, nil, nil, models.BikeTypeClassic, nil)
+	assert.ErrorIs(t, err, constants.ErrNoParkingZone)
+
+	bike, err := service.CreateBike(t.Context(), 40.2, -3.5, nil, nil, models.BikeTypeClassic, nil)
+	assert.NoError(t, err)
+
+	lat := 40.45
+	lng := -3.75
+	_, err = service.UpdateBike(t.Context(), bike.ID, &lat, &lng, nil, nil, nil, nil, nil)
+	assert.ErrorIs(t, err, constants.ErrNoParkingZone)
+
+	outside := 41.5
+	_, err = service.UpdateBike(t.Context(), bike.ID, &outside, nil, nil, nil, nil, nil, nil)
+	assert.ErrorIs(t, err, constants.ErrOutsideOperatingArea)
+}
+
+func TestRentalService_EndRental_Geofences(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	insertTestGeofence(t, db, squareGeofence(models.GeofenceOperatingArea, 40.40, -3.72, 0.04))
+	insertTestGeofence(t, db, squareGeofence(models.GeofenceNoParking, 40.42, -3.71, 0.01))
+	service := newTestRentalService(db)
+
+	_, err := service.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	rental, err := service.EndRental(t.Context(), 1, 40.39, -3.70)
+	assert.ErrorIs(t, err, constants.ErrOutsideOperatingArea)
+	assert.Nil(t, rental)
+
+	rental, err = service.EndRental(t.Context(), 1, 40.425, -3.705)
+	assert.ErrorIs(t, err, constants.ErrNoParkingZone)
+	assert.Nil(t, rental)
+	assert.False(t, bikeIsAvailable(t, db, bikeID))
+
+	rental, err = service.EndRental(t.Context(), 1, 40.41, -3.70)
+	assert.NoError(t, err)
+	assert.Equal(t, models.RentalStatusEnded, rental.Status)
+}
+
+func TestRentalService_EndRental_NoParkingFine(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	insertTestGeofence(t, db, squareGeofence(models.GeofenceNoParking, 40.42, -3.71, 0.01))
+	service := NewRentalService(repositories.NewRentalRepository(db), repositories.NewUnitOfWork(db), 0.1, StationRules{Policy: ReturnFreeFloating}, ZoneRules{NoParking: NoParkingFined, NoParkingFine: 5}, testReturnRule)
+
+	_, err := service.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	rental, err := service.EndRental(t.Context(), 1, 40.425, -3.705)
+
+	assert.NoError(t, err)
+	assert.Len(t, rental.CostBreakdown, 2)
+	assert.Equal(t, pricing.LineItemNoParking, rental.CostBreakdown[1].Code)
+	assert.Equal(t, float64(*rental.DurationMinutes)*0.5+5, *rental.Cost)
+}
+
+func TestRentalService_EndRental_StationInsideNoParkingZone(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	insertTestGeofence(t, db, squareGeofence(models.GeofenceNoParking, 40.42, -3.71, 0.01))
+	insertTestStation(t, db, "Sol", 40.425, -3.705, 50, 10)
+	service := newTestRentalService(db)
+
+	_, err := service.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	rental, err := service.EndRental(t.Context(), 1, 40.425, -3.705)
+
+	assert.NoError(t, err)
+	assert.Len(t, rental.CostBreakdown, 1)
+}
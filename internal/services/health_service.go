@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"database/sql"
 	"time"
 )
@@ -23,7 +24,7 @@ type HealthStatus struct {
 	Database  string `json:"database"`
 }
 
-func (s *HealthService) CheckHealth() (*HealthStatus, bool) {
+func (s *HealthService) CheckHealth(ctx context.Context) (*HealthStatus, bool) {
 	health := &HealthStatus{
 		Status:    "healthy",
 		Timestamp: time.Now().Format(time.RFC3339),
@@ -32,7 +33,7 @@ func (s *HealthService) CheckHealth() (*HealthStatus, bool) {
 		Database:  "connected",
 	}
 
-	if err := s.db.Ping(); err != nil {
+	if err := s.db.PingContext(ctx); err != nil {
 		health.Status = "unhealthy"
 		health.Database = "disconnected"
 		return health, false
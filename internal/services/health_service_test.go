@@ -18,7 +18,7 @@ func TestHealthService_CheckHealth_Healthy(t *testing.T) {
 	mock.ExpectPing()
 
 	service := NewHealthService(db)
-	health, isHealthy := service.CheckHealth()
+	health, isHealthy := service.CheckHealth(t.Context())
 
 	assert.True(t, isHealthy)
 	assert.NotNil(t, health)
@@ -40,7 +40,7 @@ func TestHealthService_CheckHealth_Unhealthy(t *testing.T) {
 	mock.ExpectPing().WillReturnError(errors.New("connection refused"))
 
 	service := NewHealthService(db)
-	health, isHealthy := service.CheckHealth()
+	health, isHealthy := service.CheckHealth(t.Context())
 
 	assert.False(t, isHealthy)
 	assert.NotNil(t, health)
@@ -61,6 +61,6 @@ func TestHealthService_CheckHealth_DatabaseNil(t *testing.T) {
 
 	// This should panic or return unhealthy - we expect a panic for nil pointer
 	assert.Panics(t, func() {
-		service.CheckHealth()
+		service.CheckHealth(t.Context())
 	})
 }
\ No newline at end of file
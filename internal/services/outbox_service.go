@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/logger"
+	"github.com/Nimirandad/bike-rental-service/internal/mailer"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/repositories"
+)
+
+const (
+	// outboxBatchSize is how many due emails one delivery run picks up.
+	outboxBatchSize = 50
+	// outboxMaxAttempts is how many deliveries are tried before an email is
+	// marked failed.
+	outboxMaxAttempts = 8
+	outboxBaseBackoff = 30 * time.Second
+	outboxMaxBackoff  = time.Hour
+	// outboxClaimLease is how long a claimed email stays reserved for the
+	// worker that claimed it. It outlasts a batch of sends, and an email whose
+	// worker died is retried once it ends.
+	outboxClaimLease = 10 * time.Minute
+)
+
+type EmailOutboxRepository interface {
+	ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*models.OutboxEmail, error)
+	MarkSent(ctx context.Context, emailID, attempts int, sentAt time.Time) error
+	MarkAttemptFailed(ctx context.Context, emailID, attempts int, status, lastError string, nextAttemptAt time.Time) error
+}
+
+// OutboxService delivers queued emails with the configured mailer and
+// retries failed deliveries with exponential backoff.
+type OutboxService struct {
+	outboxRepo EmailOutboxRepository
+	mailer     mailer.Mailer
+	now        func() time.Time
+}
+
+func NewOutboxService(outboxRepo *repositories.EmailOutboxRepository, m mailer.Mailer) *OutboxService {
+	return &OutboxService{
+		outboxRepo: outboxRepo,
+		mailer:     m,
+		now:        utcNow,
+	}
+}
+
+// DeliverPending claims the emails that are due, sends them and returns how
+// many were sent. Claiming keeps workers on other replicas from sending the
+// same emails. A failed delivery is rescheduled rather than returned as an
+// error.
+func (s *OutboxService) DeliverPending(ctx context.Context) (int, error) {
+	log := logger.FromContext(ctx)
+	now := s.now()
+
+	emails, err := s.outboxRepo.ClaimDue(ctx, now, now.Add(outboxClaimLease), outboxBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, email := range emails {
+		attempts := email.Attempts + 1
+
+		sendErr := s.mailer.Send(mailer.Message{To: email.Recipient, Subject: email.Subject, Body: email.Body})
+		if sendErr == nil {
+			if err := s.outboxRepo.MarkSent(ctx, email.ID, attempts, s.now()); err != nil {
+				return sent, err
+			}
+			sent++
+			continue
+		}
+
+		status := models.OutboxStatusPending
+		if attempts >= outboxMaxAttempts {
+			status = models.OutboxStatusFailed
+		}
+		log.Warn().Err(sendErr).Int("email_id", email.ID).Int("attempts", attempts).Str("status", status).Msg("Email delivery failed")
+
+		if err := s.outboxRepo.MarkAttemptFailed(ctx, email.ID, attempts, status, sendErr.Error(), now.Add(outboxBackoff(attempts))); err != nil {
+			return sent, err
+		}
+	}
+
+	return sent, nil
+}
+
+// outboxBackoff is the wait before the next delivery after attempts failures.
+func outboxBackoff(attempts int) time.Duration {
+	backoff := outboxBaseBackoff
+	for i := 1; i < attempts && backoff < outboxMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > outboxMaxBackoff {
+		return outboxMaxBackoff
+	}
+	return backoff
+}
+
+// RunDeliveryWorker calls DeliverPending every interval until ctx is done.
+func (s *OutboxService) RunDeliveryWorker(ctx context.Context, interval time.Duration) {
+	log := logger.Get()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Info().Dur("interval", interval).Msg("Email outbox worker started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Email outbox worker stopped")
+			return
+		case <-ticker.C:
+			sent, err := s.DeliverPending(ctx)
+			if err != nil {
+				log.Error().Err(err).Msg("Error delivering queued emails")
+				continue
+			}
+			if sent > 0 {
+				log.Info().Int("sent", sent).Msg("Queued emails delivered")
+			}
+		}
+	}
+}
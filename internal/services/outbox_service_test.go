@@ -0,0 +1,123 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/mailer"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeMailer records sent messages and fails while err is set. onSend, when
+// set, runs before each delivery.
+type fakeMailer struct {
+	sent   []mailer.Message
+	err    error
+	onSend func()
+}
+
+func (m *fakeMailer) Send(msg mailer.Message) error {
+	if m.onSend != nil {
+		m.onSend()
+	}
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func TestOutboxService_DeliverPending_RetriesWithBackoff(t *testing.T) {
+	db := newTestDB(t)
+	outboxRepo := repositories.NewEmailOutboxRepository(db)
+	m := &fakeMailer{err: errors.New("connection refused")}
+	service := NewOutboxService(outboxRepo, m)
+
+	now := utcNow()
+	service.now = func() time.Time { return now }
+	assert.NoError(t, outboxRepo.Enqueue(t.Context(), "rider@example.com", "Hello", "Body", now))
+
+	sent, err := service.DeliverPending(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	// Not due again until the backoff has passed.
+	m.err = nil
+	sent, err = service.DeliverPending(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	service.now = func() time.Time { return now.Add(outboxBaseBackoff) }
+	sent, err = service.DeliverPending(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, "rider@example.com", m.sent[0].To)
+
+	var status string
+	var attempts int
+	assert.NoError(t, db.QueryRow("SELECT status, attempts FROM email_outbox").Scan(&status, &attempts))
+	assert.Equal(t, models.OutboxStatusSent, status)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestOutboxService_DeliverPending_GivesUp(t *testing.T) {
+	db := newTestDB(t)
+	outboxRepo := repositories.NewEmailOutboxRepository(db)
+	service := NewOutboxService(outboxRepo, &fakeMailer{err: errors.New("mailbox unavailable")})
+
+	now := utcNow()
+	assert.NoError(t, outboxRepo.Enqueue(t.Context(), "rider@example.com", "Hello", "Body", now))
+
+	for i := 0; i < outboxMaxAttempts; i++ {
+		at := now.Add(time.Duration(i) * outboxMaxBackoff)
+		service.now = func() time.Time { return at }
+		_, err := service.DeliverPending(t.Context())
+		assert.NoError(t, err)
+	}
+
+	var status, lastError string
+	assert.NoError(t, db.QueryRow("SELECT status, last_error FROM email_outbox").Scan(&status, &lastError))
+	assert.Equal(t, models.OutboxStatusFailed, status)
+	assert.Equal(t, "mailbox unavailable", lastError)
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, outboxBackoff(1))
+	assert.Equal(t, time.Minute, outboxBackoff(2))
+	assert.Equal(t, 4*time.Minute, outboxBackoff(4))
+	assert.Equal(t, time.Hour, outboxBackoff(20))
+}
+
+func TestOutboxService_DeliverPending_WorkersShareTheQueue(t *testing.T) {
+	db := newTestDB(t)
+	outboxRepo := repositories.NewEmailOutboxRepository(db)
+	now := utcNow()
+
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, outboxRepo.Enqueue(t.Context(), "rider@example.com", "Hello", "Body", now))
+	}
+
+	other := &fakeMailer{}
+	otherService := NewOutboxService(outboxRepo, other)
+	otherService.now = func() time.Time { return now }
+
+	// The other worker runs while the first is still sending its batch.
+	first := &fakeMailer{}
+	first.onSend = func() {
+		if len(first.sent) == 2 {
+			_, err := otherService.DeliverPending(t.Context())
+			assert.NoError(t, err)
+		}
+	}
+	service := NewOutboxService(outboxRepo, first)
+	service.now = func() time.Time { return now }
+
+	sent, err := service.DeliverPending(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, 5, sent)
+	assert.Len(t, first.sent, 5)
+	assert.Empty(t, other.sent, "emails claimed by one worker are not sent by another")
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/repositories"
+)
+
+type PricePlanRepository interface {
+	GetByID(ctx context.Context, planID int) (*models.PricePlan, error)
+	GetAll(ctx context.Context, page, limit int) ([]*models.PricePlan, error)
+	CountAll(ctx context.Context) (int, error)
+}
+
+type PricePlanService struct {
+	pricePlanRepo PricePlanRepository
+	uow           UnitOfWork
+}
+
+func NewPricePlanService(pricePlanRepo *repositories.PricePlanRepository, uow *repositories.UnitOfWork) *PricePlanService {
+	return &PricePlanService{
+		pricePlanRepo: pricePlanRepo,
+		uow:           uow,
+	}
+}
+
+// CreatePlan stores the plan and its zone rates in a single transaction.
+func (s *PricePlanService) CreatePlan(ctx context.Context, plan *models.PricePlan) (*models.PricePlan, error) {
+	var created *models.PricePlan
+	err := s.uow.WithTx(ctx, func(tx *repositories.Tx) error {
+		stored, err := tx.PricePlans.Create(ctx, plan)
+		if err != nil {
+			return err
+		}
+
+		created, err = replaceZoneRates(ctx, tx, stored.ID, plan.ZoneRates)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (s *PricePlanService) GetAllPlans(ctx context.Context, page, limit int) ([]*models.PricePlan, int, error) {
+	total, err := s.pricePlanRepo.CountAll(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	plans, err := s.pricePlanRepo.GetAll(ctx, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return plans, total, nil
+}
+
+func (s *PricePlanService) GetPlanByID(ctx context.Context, planID int) (*models.PricePlan, error) {
+	plan, err := s.pricePlanRepo.GetByID(ctx, planID)
+	if errors.Is(err, constants.ErrPricePlanNotFound) {
+		return nil, constants.ErrPricePlanNotFound
+	}
+	return plan, err
+}
+
+// UpdatePlan writes the full plan and swaps its zone rates for
+// plan.ZoneRates in a single transaction.
+func (s *PricePlanService) UpdatePlan(ctx context.Context, plan *models.PricePlan) (*models.PricePlan, error) {
+	var updated *models.PricePlan
+	err := s.uow.WithTx(ctx, func(tx *repositories.Tx) error {
+		if _, err := tx.PricePlans.Update(ctx, plan); err != nil {
+			return err
+		}
+
+		var err error
+		updated, err = replaceZoneRates(ctx, tx, plan.ID, plan.ZoneRates)
+		return err
+	})
+	if errors.Is(err, constants.ErrPricePlanNotFound) {
+		return nil, constants.ErrPricePlanNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (s *PricePlanService) DeletePlan(ctx context.Context, planID int) error {
+	err := s.uow.WithTx(ctx, func(tx *repositories.Tx) error {
+		return tx.PricePlans.Delete(ctx, planID)
+	})
+	if errors.Is(err, constants.ErrPricePlanNotFound) {
+		return constants.ErrPricePlanNotFound
+	}
+	return err
+}
+
+// replaceZoneRates stores rates for the plan after checking that every
+// station they name exists, and returns the plan as stored.
+func replaceZoneRates(ctx context.Context, tx *repositories.Tx, planID int, rates []models.ZoneRate) (*models.PricePlan, error) {
+	for _, rate := range rates {
+		_, err := tx.Stations.GetByID(ctx, rate.StationID)
+		if errors.Is(err, constants.ErrStationNotFound) {
+			return nil, constants.ErrUnknownZoneStation
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.PricePlans.ReplaceZoneRates(ctx, planID, rates); err != nil {
+		return nil, err
+	}
+
+	return tx.PricePlans.GetByID(ctx, planID)
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/database"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockPricePlanRepository struct {
+	GetByIDFunc  func(planID int) (*models.PricePlan, error)
+	GetAllFunc   func(page, limit int) ([]*models.PricePlan, error)
+	CountAllFunc func() (int, error)
+}
+
+func (m *MockPricePlanRepository) GetByID(ctx context.Context, planID int) (*models.PricePlan, error) {
+	return m.GetByIDFunc(planID)
+}
+
+func (m *MockPricePlanRepository) GetAll(ctx context.Context, page, limit int) ([]*models.PricePlan, error) {
+	return m.GetAllFunc(page, limit)
+}
+
+func (m *MockPricePlanRepository) CountAll(ctx context.Context) (int, error) {
+	return m.CountAllFunc()
+}
+
+func notFoundPricePlan(planID int) error {
+	return fmt.Errorf("price plan with id %d: %w", planID, constants.ErrPricePlanNotFound)
+}
+
+func TestPricePlanService_GetAllPlans_Success(t *testing.T) {
+	mockRepo := &MockPricePlanRepository{
+		CountAllFunc: func() (int, error) {
+			return 2, nil
+		},
+		GetAllFunc: func(page, limit int) ([]*models.PricePlan, error) {
+			return []*models.PricePlan{{ID: 1, Name: "Standard"}, {ID: 2, Name: "Night owl"}}, nil
+		},
+	}
+
+	service := &PricePlanService{pricePlanRepo: mockRepo}
+	plans, total, err := service.GetAllPlans(t.Context(), 1, 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Len(t, plans, 2)
+}
+
+func TestPricePlanService_GetAllPlans_CountError(t *testing.T) {
+	mockRepo := &MockPricePlanRepository{
+		CountAllFunc: func() (int, error) {
+			return 0, errors.New("database error")
+		},
+	}
+
+	service := &PricePlanService{pricePlanRepo: mockRepo}
+	plans, total, err := service.GetAllPlans(t.Context(), 1, 10)
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, total)
+	assert.Nil(t, plans)
+}
+
+func TestPricePlanService_GetPlanByID_NotFound(t *testing.T) {
+	mockRepo := &MockPricePlanRepository{
+		GetByIDFunc: func(planID int) (*models.PricePlan, error) {
+			return nil, notFoundPricePlan(planID)
+		},
+	}
+
+	service := &PricePlanService{pricePlanRepo: mockRepo}
+	plan, err := service.GetPlanByID(t.Context(), 99)
+
+	assert.Equal(t, constants.ErrPricePlanNotFound, err)
+	assert.Nil(t, plan)
+}
+
+func newTestPricePlanService(db *database.DB) *PricePlanService {
+	return NewPricePlanService(repositories.NewPricePlanRepository(db), repositories.NewUnitOfWork(db))
+}
+
+func testPricePlan(name string, zoneRates ...models.ZoneRate) *models.PricePlan {
+	pricePerMinute := 0.2
+	return &models.PricePlan{
+		Name: name, UnlockFee: 1, PricePerMinute: &pricePerMinute, NightMultiplier: 1,
+		WeekendMultiplier: 1, Timezone: "UTC", ZoneRates: zoneRates,
+	}
+}
+
+func TestPricePlanService_CreatePlan_StoresZoneRates(t *testing.T) {
+	db := newTestDB(t)
+	stationID := insertTestStation(t, db, "Sol", 40.4168, -3.7038, 100, 10)
+	service := newTestPricePlanService(db)
+
+	plan, err := service.CreatePlan(t.Context(), testPricePlan("Centro",
+		models.ZoneRate{StationID: stationID, ApplyTo: models.ZoneRateStart, Fee: 0.5, Multiplier: 1},
+		models.ZoneRate{StationID: stationID, ApplyTo: models.ZoneRateEnd, Multiplier: 0.8},
+	))
+
+	assert.NoError(t, err)
+	assert.Len(t, plan.ZoneRates, 2)
+	assert.Equal(t, models.ZoneRateStart, plan.ZoneRates[0].ApplyTo)
+	assert.Equal(t, 0.5, plan.ZoneRates[0].Fee)
+	assert.Equal(t, 0.8, plan.ZoneRates[1].Multiplier)
+	assert.Equal(t, "Sol", plan.ZoneRates[1].Station.Name)
+
+	stored, err := service.GetPlanByID(t.Context(), plan.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, plan.ZoneRates, stored.ZoneRates)
+}
+
+func TestPricePlanService_CreatePlan_UnknownZoneStation(t *testing.T) {
+	db := newTestDB(t)
+	service := newTestPricePlanService(db)
+
+	plan, err := service.CreatePlan(t.Context(), testPricePlan("Centro",
+		models.ZoneRate{StationID: 99, ApplyTo: models.ZoneRateStart, Fee: 0.5, Multiplier: 1},
+	))
+
+	assert.Equal(t, constants.ErrUnknownZoneStation, err)
+	assert.Nil(t, plan)
+
+	// The plan row is rolled back along with its zone rates.
+	_, total, err := service.GetAllPlans(t.Context(), 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, total)
+}
+
+func TestPricePlanService_UpdatePlan_ReplacesZoneRates(t *testing.T) {
+	db := newTestDB(t)
+	solID := insertTestStation(t, db, "Sol", 40.4168, -3.7038, 100, 10)
+	atochaID := insertTestStation(t, db, "Atocha", 40.4066, -3.6892, 100, 10)
+	service := newTestPricePlanService(db)
+
+	plan, err := service.CreatePlan(t.Context(), testPricePlan("Centro",
+		models.ZoneRate{StationID: solID, ApplyTo: models.ZoneRateStart, Fee: 0.5, Multiplier: 1},
+	))
+	assert.NoError(t, err)
+
+	plan.ZoneRates = []models.ZoneRate{{StationID: atochaID, ApplyTo: models.ZoneRateEnd, Multiplier: 1.5}}
+	updated, err := service.UpdatePlan(t.Context(), plan)
+
+	assert.NoError(t, err)
+	assert.Len(t, updated.ZoneRates, 1)
+	assert.Equal(t, atochaID, updated.ZoneRates[0].StationID)
+
+	plan.ZoneRates = []models.ZoneRate{}
+	updated, err = service.UpdatePlan(t.Context(), plan)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, updated.ZoneRates)
+	assert.Empty(t, updated.ZoneRates)
+}
+
+func TestPricePlanService_UpdatePlan_NotFound(t *testing.T) {
+	db := newTestDB(t)
+	service := newTestPricePlanService(db)
+
+	plan := testPricePlan("Gone")
+	plan.ID = 99
+	updated, err := service.UpdatePlan(t.Context(), plan)
+
+	assert.Equal(t, constants.ErrPricePlanNotFound, err)
+	assert.Nil(t, updated)
+}
+
+func TestPricePlanService_DeletePlan_InUse(t *testing.T) {
+	db := newTestDB(t)
+	service := newTestPricePlanService(db)
+
+	plan, err := service.CreatePlan(t.Context(), testPricePlan("Standard"))
+	assert.NoError(t, err)
+
+	bikeID := insertTestBike(t, db, true, 40.4168, -3.7038, 0.1)
+	if _, err := db.Exec("UPDATE bikes SET price_plan_id = ? WHERE id = ?", plan.ID, bikeID); err != nil {
+		t.Fatalf("failed to assign price plan: %v", err)
+	}
+
+	err = service.DeletePlan(t.Context(), plan.ID)
+
+	assert.Equal(t, constants.ErrPricePlanInUse, err)
+}
+
+func TestPricePlanService_DeletePlan_FreesZoneStations(t *testing.T) {
+	db := newTestDB(t)
+	stationID := insertTestStation(t, db, "Sol", 40.4168, -3.7038, 100, 10)
+	service := newTestPricePlanService(db)
+	stationService := NewStationService(repositories.NewStationRepository(db))
+
+	plan, err := service.CreatePlan(t.Context(), testPricePlan("Centro",
+		models.ZoneRate{StationID: stationID, ApplyTo: models.ZoneRateStart, Fee: 0.5, Multiplier: 1},
+	))
+	assert.NoError(t, err)
+
+	err = stationService.DeleteStation(t.Context(), stationID)
+	assert.Equal(t, constants.ErrStationHasZoneRates, err)
+
+	assert.NoError(t, service.DeletePlan(t.Context(), plan.ID))
+	assert.NoError(t, stationService.DeleteStation(t.Context(), stationID))
+}
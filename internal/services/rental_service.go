@@ -1,39 +1,102 @@
 package services
 
 import (
-	"math"
+	"context"
+	"errors"
 	"time"
 
 	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/metrics"
 	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/pricing"
+	"github.com/Nimirandad/bike-rental-service/internal/queryspec"
 	"github.com/Nimirandad/bike-rental-service/internal/repositories"
-	"github.com/Nimirandad/bike-rental-service/internal/utils"
 )
 
 type RentalRepository interface {
-	HasActiveRental(userID int) (bool, error)
-	Create(userID, bikeID int, startLat, startLong float64) (*models.Rental, error)
-	GetByID(rentalID int) (*models.Rental, error)
-	GetActiveRentalsByUser(userID, page, limit int) ([]*models.Rental, error)
-	CountByUser(userID int) (int, error)
-	GetActiveRentalByUser(userID int) (*models.Rental, error)
-	EndRental(rentalID int, endLat, endLong float64, durationMinutes int, cost float64) (*models.Rental, error)
+	HasActiveRental(ctx context.Context, userID int) (bool, error)
+	Create(ctx context.Context, userID, bikeID int, startLat, startLong float64, startTime time.Time) (*models.Rental, error)
+	GetByID(ctx context.Context, rentalID int) (*models.Rental, error)
+	GetActiveRentalsByUser(ctx context.Context, userID, page, limit int) ([]*models.Rental, error)
+	GetRentalsByUserCursor(ctx context.Context, userID int, cursor queryspec.Cursor, limit int) ([]*models.Rental, error)
+	CountByUser(ctx context.Context, userID int) (int, error)
+	GetActiveRentalByUser(ctx context.Context, userID int) (*models.Rental, error)
+}
+
+type UnitOfWork interface {
+	WithTx(ctx context.Context, fn func(tx *repositories.Tx) error) error
+}
+
+// ReturnPolicy decides whether a rental may end outside a station.
+type ReturnPolicy string
+
+const (
+	// ReturnFreeFloating lets rentals end anywhere. It is also used for
+	// unknown policy names.
+	ReturnFreeFloating ReturnPolicy = "free_floating"
+	// ReturnStationRequired rejects returns outside a station with a free
+	// dock.
+	ReturnStationRequired ReturnPolicy = "station_required"
+	// ReturnSurcharge accepts returns anywhere but charges the
+	// out-of-station fee for those outside a station with a free dock.
+	ReturnSurcharge ReturnPolicy = "surcharge"
+)
+
+// StationRules configures where rentals may end.
+type StationRules struct {
+	Policy          ReturnPolicy
+	OutOfStationFee float64
+}
+
+// NoParkingPolicy decides what happens to a rental ending in a no-parking
+// zone.
+type NoParkingPolicy string
+
+const (
+	// NoParkingReject refuses to end the rental. It is also used for unknown
+	// policy names.
+	NoParkingReject NoParkingPolicy = "reject"
+	// NoParkingFined ends the rental and charges the no-parking fine.
+	NoParkingFined NoParkingPolicy = "fine"
+)
+
+// ZoneRules configures how geofences apply to returns.
+type ZoneRules struct {
+	NoParking     NoParkingPolicy
+	NoParkingFine float64
 }
 
 type RentalService struct {
-	rentalRepo RentalRepository
-	bikeRepo   BikeRepository
+	rentalRepo           RentalRepository
+	uow                  UnitOfWork
+	pausedPricePerMinute float64
+	stationRules         StationRules
+	zoneRules            ZoneRules
+	defaultReturnRule    models.ReturnDistanceRule
 }
 
-func NewRentalService(rentalRepo *repositories.RentalRepository, bikeRepo *repositories.BikeRepository) *RentalService {
+// NewRentalService builds the rental service. pausedPricePerMinute is the
+// rate charged while a rental is paused, unless the bike's price plan sets
+// its own. defaultReturnRule limits where rentals end until an admin sets a
+// rule.
+func NewRentalService(rentalRepo *repositories.RentalRepository, uow *repositories.UnitOfWork, pausedPricePerMinute float64, stationRules StationRules, zoneRules ZoneRules, defaultReturnRule models.ReturnDistanceRule) *RentalService {
 	return &RentalService{
-		rentalRepo: rentalRepo,
-		bikeRepo:   bikeRepo,
+		rentalRepo:           rentalRepo,
+		uow:                  uow,
+		pausedPricePerMinute: pausedPricePerMinute,
+		stationRules:         stationRules,
+		zoneRules:            zoneRules,
+		defaultReturnRule:    defaultReturnRule,
 	}
 }
 
-func (s *RentalService) StartRental(userID, bikeID int) (*models.Rental, error) {
-	hasActive, err := s.rentalRepo.HasActiveRental(userID)
+// StartRental claims the bike and creates the rental in a single transaction.
+// The conditional bike update and the one-running-rental-per-user index make
+// it safe against concurrent requests for the same bike or user. A bike
+// reserved by the same user is converted into the rental; a bike reserved by
+// someone else is rejected with ErrBikeReserved.
+func (s *RentalService) StartRental(ctx context.Context, userID, bikeID int) (*models.Rental, error) {
+	hasActive, err := s.rentalRepo.HasActiveRental(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -41,35 +104,121 @@ func (s *RentalService) StartRental(userID, bikeID int) (*models.Rental, error)
 		return nil, constants.ErrUserHasActiveRental
 	}
 
-	bike, err := s.bikeRepo.GetByID(bikeID)
-	if err != nil {
-		return nil, constants.ErrBikeNotFound
-	}
+	var rental *models.Rental
+	err = s.uow.WithTx(ctx, func(tx *repositories.Tx) error {
+		now := utcNow()
 
-	if !bike.IsAvailable {
-		return nil, constants.ErrBikeNotAvailable
-	}
+		reservation, err := tx.Reservations.GetActiveByBike(ctx, bikeID)
+		if err != nil {
+			return err
+		}
+		reservation, err = releaseIfExpired(ctx, tx, reservation, now)
+		if err != nil {
+			return err
+		}
+		if reservation != nil && reservation.UserID != userID {
+			return constants.ErrBikeReserved
+		}
+
+		if err := releaseOtherReservation(ctx, tx, userID, bikeID); err != nil {
+			return err
+		}
+
+		if reservation != nil {
+			bike, err := tx.Bikes.GetByID(ctx, bikeID)
+			if errors.Is(err, constants.ErrBikeNotFound) {
+				return constants.ErrBikeNotFound
+			}
+			if err != nil {
+				return err
+			}
+
+			rental, err = tx.Rentals.Create(ctx, userID, bikeID, bike.Latitude, bike.Longitude, now)
+			if errors.Is(err, constants.ErrUserHasActiveRental) {
+				return constants.ErrUserHasActiveRental
+			}
+			if err != nil {
+				return err
+			}
+
+			if err := tx.Segments.Open(ctx, rental.ID, models.RentalStatusRunning, rental.StartTime); err != nil {
+				return err
+			}
+			if err := tx.Bikes.Park(ctx, bikeID, nil); err != nil {
+				return err
+			}
+
+			return tx.Reservations.Convert(ctx, reservation.ID, rental.ID)
+		}
+
+		claimed, err := tx.Bikes.ClaimAvailable(ctx, bikeID)
+		if err != nil {
+			return err
+		}
 
-	rental, err := s.rentalRepo.Create(userID, bikeID, bike.Latitude, bike.Longitude)
+		bike, err := tx.Bikes.GetByID(ctx, bikeID)
+		if errors.Is(err, constants.ErrBikeNotFound) {
+			return constants.ErrBikeNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if !claimed {
+			return constants.ErrBikeNotAvailable
+		}
+
+		rental, err = tx.Rentals.Create(ctx, userID, bikeID, bike.Latitude, bike.Longitude, now)
+		if errors.Is(err, constants.ErrUserHasActiveRental) {
+			return constants.ErrUserHasActiveRental
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Segments.Open(ctx, rental.ID, models.RentalStatusRunning, rental.StartTime); err != nil {
+			return err
+		}
+
+		// A rented bike leaves its station and frees the dock.
+		return tx.Bikes.Park(ctx, bikeID, nil)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	err = s.bikeRepo.UpdateAvailability(bikeID, false)
+	metrics.RentalsStarted.Inc()
+	return rental, nil
+}
+
+// releaseOtherReservation cancels the user's reservation on a different bike,
+// since starting a rental gives up any bike they were holding.
+func releaseOtherReservation(ctx context.Context, tx *repositories.Tx, userID, bikeID int) error {
+	reservation, err := tx.Reservations.GetActiveByUser(ctx, userID)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if reservation == nil || reservation.BikeID == bikeID {
+		return nil
 	}
 
-	return rental, nil
+	closed, err := tx.Reservations.Close(ctx, reservation.ID, models.ReservationStatusCancelled)
+	if err != nil {
+		return err
+	}
+	if closed {
+		return tx.Bikes.UpdateAvailability(ctx, reservation.BikeID, true)
+	}
+	return nil
 }
 
-func (s *RentalService) GetRentalHistory(userID int, page, limit int) ([]*models.Rental, int, error) {
-	total, err := s.rentalRepo.CountByUser(userID)
+func (s *RentalService) GetRentalHistory(ctx context.Context, userID int, page, limit int) ([]*models.Rental, int, error) {
+	total, err := s.rentalRepo.CountByUser(ctx, userID)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	rentals, err := s.rentalRepo.GetActiveRentalsByUser(userID, page, limit)
+	rentals, err := s.rentalRepo.GetActiveRentalsByUser(ctx, userID, page, limit)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -77,46 +226,138 @@ func (s *RentalService) GetRentalHistory(userID int, page, limit int) ([]*models
 	return rentals, total, nil
 }
 
-func (s *RentalService) EndRental(userID int, endLat, endLong float64) (*models.Rental, error) {
-	activeRental, err := s.rentalRepo.GetActiveRentalByUser(userID)
+// GetRentalHistoryByCursor returns one page of the user's rentals, newest
+// first, without counting the full history.
+func (s *RentalService) GetRentalHistoryByCursor(ctx context.Context, userID int, cursor queryspec.Cursor, limit int) ([]*models.Rental, queryspec.Cursors, error) {
+	// One extra row tells whether another page follows.
+	rentals, err := s.rentalRepo.GetRentalsByUserCursor(ctx, userID, cursor, limit+1)
 	if err != nil {
-		return nil, err
+		return nil, queryspec.Cursors{}, err
 	}
 
-	if activeRental == nil {
-		return nil, constants.ErrNoActiveRental
-	}
+	rentals, cursors := queryspec.Paginate(rentals, cursor, limit, func(rental *models.Rental) int { return rental.ID })
+	return rentals, cursors, nil
+}
 
-	distance := utils.HaversineDistance(
-		activeRental.StartLatitude,
-		activeRental.StartLongitude,
-		endLat,
-		endLong,
-	)
+// PauseRental locks the user's running rental without ending it. Paused time
+// is billed at the paused rate when the rental ends.
+func (s *RentalService) PauseRental(ctx context.Context, userID int) (*models.Rental, error) {
+	return s.switchSegment(ctx, userID, models.RentalStatusPaused, constants.ErrRentalNotRunning)
+}
 
-	if distance > 5.0 {
-		return nil, constants.ErrEndLocationTooFar
-	}
+// ResumeRental returns the user's paused rental to running.
+func (s *RentalService) ResumeRental(ctx context.Context, userID int) (*models.Rental, error) {
+	return s.switchSegment(ctx, userID, models.RentalStatusRunning, constants.ErrRentalNotPaused)
+}
+
+// switchSegment moves the user's active rental to a running or paused
+// status. wrongState is returned when the transition is not allowed from the
+// rental's current status.
+func (s *RentalService) switchSegment(ctx context.Context, userID int, to models.RentalStatus, wrongState error) (*models.Rental, error) {
+	var rental *models.Rental
+	err := s.uow.WithTx(ctx, func(tx *repositories.Tx) error {
+		activeRental, err := tx.Rentals.GetActiveRentalByUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if activeRental == nil {
+			return constants.ErrNoActiveRental
+		}
+		if !activeRental.Status.CanTransitionTo(to) {
+			return wrongState
+		}
 
-	bike, err := s.bikeRepo.GetByID(activeRental.BikeID)
+		rental, err = changeSegment(ctx, tx, activeRental, to)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	duration := time.Since(activeRental.StartTime)
-	durationMinutes := int(math.Ceil(duration.Minutes()))
+	return rental, nil
+}
 
-	cost := float64(durationMinutes) * bike.PricePerMinute
+// EndRental closes the user's active rental at the given point and leaves the
+// bike there, parked at the station the point falls in, if any. The return distance rule
+// in effect limits how far the point may be, and the station rules decide
+// whether a return outside a station is rejected or surcharged.
+func (s *RentalService) EndRental(ctx context.Context, userID int, endLat, endLong float64) (*models.Rental, error) {
+	var rental *models.Rental
+	err := s.uow.WithTx(ctx, func(tx *repositories.Tx) error {
+		activeRental, err := tx.Rentals.GetActiveRentalByUser(ctx, userID)
+		if err != nil {
+			return err
+		}
 
-	rental, err := s.rentalRepo.EndRental(activeRental.ID, endLat, endLong, durationMinutes, cost)
-	if err != nil {
-		return nil, err
-	}
+		if activeRental == nil {
+			return constants.ErrNoActiveRental
+		}
+
+		returnRule, err := currentReturnRule(ctx, tx.ReturnRules, s.defaultReturnRule)
+		if err != nil {
+			return err
+		}
+		if err := checkReturnDistance(ctx, tx, returnRule, activeRental, endLat, endLong); err != nil {
+			return err
+		}
+
+		zones, err := checkZones(ctx, tx.Geofences, endLat, endLong)
+		if err != nil {
+			return err
+		}
+		if zones.OutsideOperatingArea {
+			return constants.ErrOutsideOperatingArea
+		}
 
-	err = s.bikeRepo.UpdateAvailability(activeRental.BikeID, true)
+		station, full, err := findReturnStation(ctx, tx, endLat, endLong)
+		if err != nil {
+			return err
+		}
+
+		var stationID *int
+		var fees []pricing.Fee
+		switch {
+		case station != nil:
+			// A station is a designated spot even inside a no-parking zone.
+			stationID = &station.ID
+		case s.stationRules.Policy == ReturnStationRequired && full:
+			return constants.ErrStationFull
+		case s.stationRules.Policy == ReturnStationRequired:
+			return constants.ErrReturnOutsideStation
+		case s.stationRules.Policy == ReturnSurcharge:
+			fees = append(fees, pricing.Fee{Code: pricing.LineItemOutOfStation, Description: "Return outside a station", Amount: s.stationRules.OutOfStationFee})
+		}
+
+		if station == nil && zones.NoParking {
+			if s.zoneRules.NoParking != NoParkingFined {
+				return constants.ErrNoParkingZone
+			}
+			fees = append(fees, pricing.Fee{Code: pricing.LineItemNoParking, Description: "Return in a no-parking zone", Amount: s.zoneRules.NoParkingFine})
+		}
+
+		rental, err = closeRental(ctx, tx, activeRental, models.RentalStatusEnded, &endLat, &endLong, s.pausedPricePerMinute, fees)
+		if err != nil {
+			return err
+		}
+
+		return tx.Bikes.ParkAt(ctx, activeRental.BikeID, stationID, endLat, endLong)
+	})
+	switch {
+	case errors.Is(err, constants.ErrEndLocationTooFar):
+		metrics.ReturnsRejected.WithLabelValues(metrics.ReasonLocationTooFar).Inc()
+	case errors.Is(err, constants.ErrReturnOutsideStation):
+		metrics.ReturnsRejected.WithLabelValues(metrics.ReasonOutsideStation).Inc()
+	case errors.Is(err, constants.ErrStationFull):
+		metrics.ReturnsRejected.WithLabelValues(metrics.ReasonStationFull).Inc()
+	case errors.Is(err, constants.ErrOutsideOperatingArea):
+		metrics.ReturnsRejected.WithLabelValues(metrics.ReasonOutsideArea).Inc()
+	case errors.Is(err, constants.ErrNoParkingZone):
+		metrics.ReturnsRejected.WithLabelValues(metrics.ReasonNoParkingZone).Inc()
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	metrics.RecordRentalClosed(rental)
 	return rental, nil
-}
\ No newline at end of file
+}
@@ -1,96 +1,146 @@
 package services
 
 import (
+	"context"
+	"database/sql"
 	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/database"
+	"github.com/Nimirandad/bike-rental-service/internal/metrics"
 	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/pricing"
+	"github.com/Nimirandad/bike-rental-service/internal/queryspec"
+	"github.com/Nimirandad/bike-rental-service/internal/repositories"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 )
 
 type MockRentalRepository struct {
 	HasActiveRentalFunc        func(userID int) (bool, error)
-	CreateFunc                 func(userID, bikeID int, startLat, startLong float64) (*models.Rental, error)
+	CreateFunc                 func(userID, bikeID int, startLat, startLong float64, startTime time.Time) (*models.Rental, error)
 	GetByIDFunc                func(rentalID int) (*models.Rental, error)
 	GetActiveRentalsByUserFunc func(userID, page, limit int) ([]*models.Rental, error)
 	CountByUserFunc            func(userID int) (int, error)
 	GetActiveRentalByUserFunc  func(userID int) (*models.Rental, error)
-	EndRentalFunc              func(rentalID int, endLat, endLong float64, durationMinutes int, cost float64) (*models.Rental, error)
+	GetRentalsByUserCursorFunc func(userID int, cursor queryspec.Cursor, limit int) ([]*models.Rental, error)
 }
 
-func (m *MockRentalRepository) HasActiveRental(userID int) (bool, error) {
+func (m *MockRentalRepository) HasActiveRental(ctx context.Context, userID int) (bool, error) {
 	return m.HasActiveRentalFunc(userID)
 }
 
-func (m *MockRentalRepository) Create(userID, bikeID int, startLat, startLong float64) (*models.Rental, error) {
-	return m.CreateFunc(userID, bikeID, startLat, startLong)
+func (m *MockRentalRepository) Create(ctx context.Context, userID, bikeID int, startLat, startLong float64, startTime time.Time) (*models.Rental, error) {
+	return m.CreateFunc(userID, bikeID, startLat, startLong, startTime)
 }
 
-func (m *MockRentalRepository) GetByID(rentalID int) (*models.Rental, error) {
+func (m *MockRentalRepository) GetByID(ctx context.Context, rentalID int) (*models.Rental, error) {
 	return m.GetByIDFunc(rentalID)
 }
 
-func (m *MockRentalRepository) GetActiveRentalsByUser(userID, page, limit int) ([]*models.Rental, error) {
+func (m *MockRentalRepository) GetActiveRentalsByUser(ctx context.Context, userID, page, limit int) ([]*models.Rental, error) {
 	return m.GetActiveRentalsByUserFunc(userID, page, limit)
 }
 
-func (m *MockRentalRepository) CountByUser(userID int) (int, error) {
+func (m *MockRentalRepository) GetRentalsByUserCursor(ctx context.Context, userID int, cursor queryspec.Cursor, limit int) ([]*models.Rental, error) {
+	return m.GetRentalsByUserCursorFunc(userID, cursor, limit)
+}
+
+func (m *MockRentalRepository) CountByUser(ctx context.Context, userID int) (int, error) {
 	return m.CountByUserFunc(userID)
 }
 
-func (m *MockRentalRepository) GetActiveRentalByUser(userID int) (*models.Rental, error) {
+func (m *MockRentalRepository) GetActiveRentalByUser(ctx context.Context, userID int) (*models.Rental, error) {
 	return m.GetActiveRentalByUserFunc(userID)
 }
 
-func (m *MockRentalRepository) EndRental(rentalID int, endLat, endLong float64, durationMinutes int, cost float64) (*models.Rental, error) {
-	return m.EndRentalFunc(rentalID, endLat, endLong, durationMinutes, cost)
+// newTestDB opens a file-backed SQLite database with all migrations applied.
+// Transactional service methods are exercised against it instead of mocks.
+func newTestDB(t *testing.T) *database.DB {
+	t.Helper()
+
+	db, err := database.Connect(database.DriverSQLite, filepath.Join(t.TempDir(), "test.db"), true)
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
 }
 
-// TestRentalService_StartRental_Success tests successful rental start
-func TestRentalService_StartRental_Success(t *testing.T) {
-	mockRentalRepo := &MockRentalRepository{
-		HasActiveRentalFunc: func(userID int) (bool, error) {
-			return false, nil
-		},
-		CreateFunc: func(userID, bikeID int, startLat, startLong float64) (*models.Rental, error) {
-			return &models.Rental{
-				ID:             1,
-				UserID:         userID,
-				BikeID:         bikeID,
-				StartLatitude:  startLat,
-				StartLongitude: startLong,
-				Status:         "running",
-				StartTime:      time.Now(),
-			}, nil
-		},
+func insertTestBike(t *testing.T, db *database.DB, isAvailable bool, latitude, longitude, pricePerMinute float64) int {
+	t.Helper()
+
+	available := 0
+	if isAvailable {
+		available = 1
 	}
 
-	mockBikeRepo := &MockBikeRepository{
-		GetByIDFunc: func(bikeID int) (*models.Bike, error) {
-			return &models.Bike{
-				ID:             bikeID,
-				Latitude:       40.416775,
-				Longitude:      -3.703790,
-				IsAvailable:    true,
-				PricePerMinute: 0.5,
-			}, nil
-		},
-		UpdateAvailabilityFunc: func(bikeID int, isAvailable bool) error {
-			return nil
-		},
+	result, err := db.Exec(
+		"INSERT INTO bikes (is_available, latitude, longitude, price_per_minute) VALUES (?, ?, ?, ?)",
+		available, latitude, longitude, pricePerMinute,
+	)
+	if err != nil {
+		t.Fatalf("failed to insert bike: %v", err)
+	}
+
+	id, _ := result.LastInsertId()
+	return int(id)
+}
+
+// testReturnRule is the default return distance rule: within 5 km of the
+// start.
+var testReturnRule = models.ReturnDistanceRule{Mode: models.ReturnDistanceFromStart, MaxDistanceKm: 5}
+
+func newTestRentalService(db *database.DB) *RentalService {
+	return NewRentalService(repositories.NewRentalRepository(db), repositories.NewUnitOfWork(db), 0.1, StationRules{Policy: ReturnFreeFloating}, ZoneRules{NoParking: NoParkingReject}, testReturnRule)
+}
+
+// backdateTestRental moves the start of a running rental d into the past, so
+// that ending it bills the elapsed minutes.
+func backdateTestRental(t *testing.T, db *database.DB, rentalID int, d time.Duration) {
+	t.Helper()
+
+	start := time.Now().UTC().Add(-d).Truncate(time.Second)
+	if _, err := db.Exec("UPDATE rentals SET start_time = ? WHERE id = ?", start, rentalID); err != nil {
+		t.Fatalf("failed to backdate rental: %v", err)
 	}
+	if _, err := db.Exec("UPDATE rental_segments SET started_at = ? WHERE rental_id = ?", start, rentalID); err != nil {
+		t.Fatalf("failed to backdate rental segments: %v", err)
+	}
+}
 
-	service := &RentalService{rentalRepo: mockRentalRepo, bikeRepo: mockBikeRepo}
-	rental, err := service.StartRental(1, 1)
+func bikeIsAvailable(t *testing.T, db *database.DB, bikeID int) bool {
+	t.Helper()
+
+	bike, err := repositories.NewBikeRepository(db).GetByID(t.Context(), bikeID)
+	if err != nil {
+		t.Fatalf("failed to load bike: %v", err)
+	}
+	return bike.IsAvailable
+}
+
+// TestRentalService_StartRental_Success tests successful rental start
+func TestRentalService_StartRental_Success(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+
+	service := newTestRentalService(db)
+	rental, err := service.StartRental(t.Context(), 1, bikeID)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, rental)
-	assert.Equal(t, 1, rental.ID)
 	assert.Equal(t, 1, rental.UserID)
-	assert.Equal(t, 1, rental.BikeID)
-	assert.Equal(t, "running", rental.Status)
+	assert.Equal(t, bikeID, rental.BikeID)
+	assert.Equal(t, models.RentalStatusRunning, rental.Status)
+	assert.Equal(t, 40.416775, rental.StartLatitude)
+	assert.Equal(t, -3.703790, rental.StartLongitude)
+	assert.False(t, bikeIsAvailable(t, db, bikeID))
 }
 
 // TestRentalService_StartRental_UserHasActiveRental tests error when user already has active rental
@@ -101,8 +151,8 @@ func TestRentalService_StartRental_UserHasActiveRental(t *testing.T) {
 		},
 	}
 
-	service := &RentalService{rentalRepo: mockRentalRepo, bikeRepo: nil}
-	rental, err := service.StartRental(1, 1)
+	service := &RentalService{rentalRepo: mockRentalRepo}
+	rental, err := service.StartRental(t.Context(), 1, 1)
 
 	assert.Error(t, err)
 	assert.Equal(t, constants.ErrUserHasActiveRental, err)
@@ -117,8 +167,8 @@ func TestRentalService_StartRental_HasActiveRentalCheckError(t *testing.T) {
 		},
 	}
 
-	service := &RentalService{rentalRepo: mockRentalRepo, bikeRepo: nil}
-	rental, err := service.StartRental(1, 1)
+	service := &RentalService{rentalRepo: mockRentalRepo}
+	rental, err := service.StartRental(t.Context(), 1, 1)
 
 	assert.Error(t, err)
 	assert.Equal(t, "database error", err.Error())
@@ -127,20 +177,10 @@ func TestRentalService_StartRental_HasActiveRentalCheckError(t *testing.T) {
 
 // TestRentalService_StartRental_BikeNotFound tests error when bike doesn't exist
 func TestRentalService_StartRental_BikeNotFound(t *testing.T) {
-	mockRentalRepo := &MockRentalRepository{
-		HasActiveRentalFunc: func(userID int) (bool, error) {
-			return false, nil
-		},
-	}
-
-	mockBikeRepo := &MockBikeRepository{
-		GetByIDFunc: func(bikeID int) (*models.Bike, error) {
-			return nil, errors.New("bike not found")
-		},
-	}
+	db := newTestDB(t)
 
-	service := &RentalService{rentalRepo: mockRentalRepo, bikeRepo: mockBikeRepo}
-	rental, err := service.StartRental(1, 1)
+	service := newTestRentalService(db)
+	rental, err := service.StartRental(t.Context(), 1, 999)
 
 	assert.Error(t, err)
 	assert.Equal(t, constants.ErrBikeNotFound, err)
@@ -149,103 +189,125 @@ func TestRentalService_StartRental_BikeNotFound(t *testing.T) {
 
 // TestRentalService_StartRental_BikeNotAvailable tests error when bike is not available
 func TestRentalService_StartRental_BikeNotAvailable(t *testing.T) {
-	mockRentalRepo := &MockRentalRepository{
-		HasActiveRentalFunc: func(userID int) (bool, error) {
-			return false, nil
-		},
-	}
-
-	mockBikeRepo := &MockBikeRepository{
-		GetByIDFunc: func(bikeID int) (*models.Bike, error) {
-			return &models.Bike{
-				ID:             bikeID,
-				Latitude:       40.416775,
-				Longitude:      -3.703790,
-				IsAvailable:    false,
-				PricePerMinute: 0.5,
-			}, nil
-		},
-	}
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, false, 40.416775, -3.703790, 0.5)
 
-	service := &RentalService{rentalRepo: mockRentalRepo, bikeRepo: mockBikeRepo}
-	rental, err := service.StartRental(1, 1)
+	service := newTestRentalService(db)
+	rental, err := service.StartRental(t.Context(), 1, bikeID)
 
 	assert.Error(t, err)
 	assert.Equal(t, constants.ErrBikeNotAvailable, err)
 	assert.Nil(t, rental)
 }
 
-// TestRentalService_StartRental_CreateRentalError tests error when creating rental
-func TestRentalService_StartRental_CreateRentalError(t *testing.T) {
-	mockRentalRepo := &MockRentalRepository{
-		HasActiveRentalFunc: func(userID int) (bool, error) {
-			return false, nil
-		},
-		CreateFunc: func(userID, bikeID int, startLat, startLong float64) (*models.Rental, error) {
-			return nil, errors.New("create error")
-		},
-	}
+// TestRentalService_StartRental_ActiveRentalIndexRollsBack tests that a running
+// rental missed by the pre-check is caught by the unique index and the bike claim
+// is rolled back
+func TestRentalService_StartRental_ActiveRentalIndexRollsBack(t *testing.T) {
+	db := newTestDB(t)
+	firstBikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	secondBikeID := insertTestBike(t, db, true, 40.417832, -3.705064, 0.5)
+
+	_, err := newTestRentalService(db).StartRental(t.Context(), 1, firstBikeID)
+	assert.NoError(t, err)
 
-	mockBikeRepo := &MockBikeRepository{
-		GetByIDFunc: func(bikeID int) (*models.Bike, error) {
-			return &models.Bike{
-				ID:             bikeID,
-				Latitude:       40.416775,
-				Longitude:      -3.703790,
-				IsAvailable:    true,
-				PricePerMinute: 0.5,
-			}, nil
+	service := &RentalService{
+		rentalRepo: &MockRentalRepository{
+			HasActiveRentalFunc: func(userID int) (bool, error) {
+				return false, nil
+			},
 		},
+		uow: repositories.NewUnitOfWork(db),
 	}
-
-	service := &RentalService{rentalRepo: mockRentalRepo, bikeRepo: mockBikeRepo}
-	rental, err := service.StartRental(1, 1)
+	rental, err := service.StartRental(t.Context(), 1, secondBikeID)
 
 	assert.Error(t, err)
-	assert.Equal(t, "create error", err.Error())
+	assert.Equal(t, constants.ErrUserHasActiveRental, err)
 	assert.Nil(t, rental)
+	assert.True(t, bikeIsAvailable(t, db, secondBikeID))
 }
 
-// TestRentalService_StartRental_UpdateAvailabilityError tests error when updating bike availability
-func TestRentalService_StartRental_UpdateAvailabilityError(t *testing.T) {
-	mockRentalRepo := &MockRentalRepository{
-		HasActiveRentalFunc: func(userID int) (bool, error) {
-			return false, nil
-		},
-		CreateFunc: func(userID, bikeID int, startLat, startLong float64) (*models.Rental, error) {
-			return &models.Rental{
-				ID:             1,
-				UserID:         userID,
-				BikeID:         bikeID,
-				StartLatitude:  startLat,
-				StartLongitude: startLong,
-				Status:         "running",
-				StartTime:      time.Now(),
-			}, nil
-		},
+// TestRentalService_StartRental_ConcurrentSameBike tests that only one of many
+// parallel rental attempts on the same bike succeeds
+func TestRentalService_StartRental_ConcurrentSameBike(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	service := newTestRentalService(db)
+
+	const attempts = 200
+
+	var wg sync.WaitGroup
+	errs := make(chan error, attempts)
+	for userID := 1; userID <= attempts; userID++ {
+		wg.Add(1)
+		go func(userID int) {
+			defer wg.Done()
+			_, err := service.StartRental(t.Context(), userID, bikeID)
+			errs <- err
+		}(userID)
+	}
+	wg.Wait()
+	close(errs)
+
+	successes := 0
+	for err := range errs {
+		if err == nil {
+			successes++
+			continue
+		}
+		assert.Equal(t, constants.ErrBikeNotAvailable, err)
 	}
 
-	mockBikeRepo := &MockBikeRepository{
-		GetByIDFunc: func(bikeID int) (*models.Bike, error) {
-			return &models.Bike{
-				ID:             bikeID,
-				Latitude:       40.416775,
-				Longitude:      -3.703790,
-				IsAvailable:    true,
-				PricePerMinute: 0.5,
-			}, nil
-		},
-		UpdateAvailabilityFunc: func(bikeID int, isAvailable bool) error {
-			return errors.New("update error")
-		},
+	var running int
+	err := db.QueryRow("SELECT COUNT(*) FROM rentals WHERE bike_id = ? AND status = 'running'", bikeID).Scan(&running)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, successes)
+	assert.Equal(t, 1, running)
+	assert.False(t, bikeIsAvailable(t, db, bikeID))
+}
+
+// TestRentalService_StartRental_ConcurrentSameUser tests that a user racing
+// against themselves on different bikes ends up with exactly one rental
+func TestRentalService_StartRental_ConcurrentSameUser(t *testing.T) {
+	db := newTestDB(t)
+	service := newTestRentalService(db)
+
+	const attempts = 50
+
+	bikeIDs := make([]int, attempts)
+	for i := range bikeIDs {
+		bikeIDs[i] = insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
 	}
 
-	service := &RentalService{rentalRepo: mockRentalRepo, bikeRepo: mockBikeRepo}
-	rental, err := service.StartRental(1, 1)
+	var wg sync.WaitGroup
+	errs := make(chan error, attempts)
+	for _, bikeID := range bikeIDs {
+		wg.Add(1)
+		go func(bikeID int) {
+			defer wg.Done()
+			_, err := service.StartRental(t.Context(), 1, bikeID)
+			errs <- err
+		}(bikeID)
+	}
+	wg.Wait()
+	close(errs)
+
+	successes := 0
+	for err := range errs {
+		if err == nil {
+			successes++
+			continue
+		}
+		assert.Equal(t, constants.ErrUserHasActiveRental, err)
+	}
 
-	assert.Error(t, err)
-	assert.Equal(t, "update error", err.Error())
-	assert.Nil(t, rental)
+	var unavailable int
+	err := db.QueryRow("SELECT COUNT(*) FROM bikes WHERE is_available = 0").Scan(&unavailable)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, successes)
+	assert.Equal(t, 1, unavailable)
 }
 
 // TestRentalService_GetRentalHistory_Success tests successful rental history retrieval
@@ -263,8 +325,8 @@ func TestRentalService_GetRentalHistory_Success(t *testing.T) {
 		},
 	}
 
-	service := &RentalService{rentalRepo: mockRentalRepo, bikeRepo: nil}
-	rentals, total, err := service.GetRentalHistory(1, 1, 10)
+	service := &RentalService{rentalRepo: mockRentalRepo}
+	rentals, total, err := service.GetRentalHistory(t.Context(), 1, 1, 10)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 10, total)
@@ -281,8 +343,8 @@ func TestRentalService_GetRentalHistory_CountError(t *testing.T) {
 		},
 	}
 
-	service := &RentalService{rentalRepo: mockRentalRepo, bikeRepo: nil}
-	rentals, total, err := service.GetRentalHistory(1, 1, 10)
+	service := &RentalService{rentalRepo: mockRentalRepo}
+	rentals, total, err := service.GetRentalHistory(t.Context(), 1, 1, 10)
 
 	assert.Error(t, err)
 	assert.Equal(t, "count error", err.Error())
@@ -301,8 +363,8 @@ func TestRentalService_GetRentalHistory_GetRentalsError(t *testing.T) {
 		},
 	}
 
-	service := &RentalService{rentalRepo: mockRentalRepo, bikeRepo: nil}
-	rentals, total, err := service.GetRentalHistory(1, 1, 10)
+	service := &RentalService{rentalRepo: mockRentalRepo}
+	rentals, total, err := service.GetRentalHistory(t.Context(), 1, 1, 10)
 
 	assert.Error(t, err)
 	assert.Equal(t, "query error", err.Error())
@@ -312,228 +374,648 @@ func TestRentalService_GetRentalHistory_GetRentalsError(t *testing.T) {
 
 // TestRentalService_EndRental_Success tests successful rental ending within 5km
 func TestRentalService_EndRental_Success(t *testing.T) {
-	startTime := time.Now().Add(-30 * time.Minute)
-
-	mockRentalRepo := &MockRentalRepository{
-		GetActiveRentalByUserFunc: func(userID int) (*models.Rental, error) {
-			return &models.Rental{
-				ID:             1,
-				UserID:         userID,
-				BikeID:         1,
-				StartLatitude:  40.416775,
-				StartLongitude: -3.703790,
-				Status:         "running",
-				StartTime:      startTime,
-			}, nil
-		},
-		EndRentalFunc: func(rentalID int, endLat, endLong float64, durationMinutes int, cost float64) (*models.Rental, error) {
-			return &models.Rental{
-				ID:     rentalID,
-				Status: "ended",
-			}, nil
-		},
-	}
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	service := newTestRentalService(db)
 
-	mockBikeRepo := &MockBikeRepository{
-		GetByIDFunc: func(bikeID int) (*models.Bike, error) {
-			return &models.Bike{
-				ID:             bikeID,
-				Latitude:       40.416775,
-				Longitude:      -3.703790,
-				IsAvailable:    false,
-				PricePerMinute: 0.5,
-			}, nil
-		},
-		UpdateAvailabilityFunc: func(bikeID int, isAvailable bool) error {
-			return nil
-		},
-	}
+	_, err := service.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
 
-	service := &RentalService{rentalRepo: mockRentalRepo, bikeRepo: mockBikeRepo}
 	// End location within 5km (approximately same location)
-	rental, err := service.EndRental(1, 40.420000, -3.700000)
+	rental, err := service.EndRental(t.Context(), 1, 40.420000, -3.700000)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, rental)
-	assert.Equal(t, 1, rental.ID)
-	assert.Equal(t, "ended", rental.Status)
+	assert.Equal(t, models.RentalStatusEnded, rental.Status)
+	assert.Equal(t, 40.420000, rental.EndLatitude)
+	assert.NotNil(t, rental.DurationMinutes)
+	assert.NotNil(t, rental.Cost)
+	assert.Equal(t, float64(*rental.DurationMinutes)*0.5, *rental.Cost)
+	assert.Len(t, rental.CostBreakdown, 1)
+	assert.True(t, bikeIsAvailable(t, db, bikeID))
 }
 
 // TestRentalService_EndRental_NoActiveRental tests error when user has no active rental
-func TestRentalService_EndRental_NoActiveRental(t *testing.T) {
-	mockRentalRepo := &MockRentalRepository{
-		GetActiveRentalByUserFunc: func(userID int) (*models.Rental, error) {
-			return nil, nil
-		},
-	}
+func TestRentalService_EndRental_AppliesPricePlan(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	service := newTestRentalService(db)
 
-	service := &RentalService{rentalRepo: mockRentalRepo, bikeRepo: nil}
-	rental, err := service.EndRental(1, 40.420000, -3.700000)
+	result, err := db.Exec("INSERT INTO price_plans (name, unlock_fee, free_minutes) VALUES ('Unlock', 1.0, 5)")
+	assert.NoError(t, err)
+	planID, _ := result.LastInsertId()
+	_, err = db.Exec("UPDATE bikes SET price_plan_id = ? WHERE id = ?", planID, bikeID)
+	assert.NoError(t, err)
 
-	assert.Error(t, err)
-	assert.Equal(t, constants.ErrNoActiveRental, err)
-	assert.Nil(t, rental)
+	started, err := service.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+	backdateTestRental(t, db, started.ID, 3*time.Minute)
+
+	rental, err := service.EndRental(t.Context(), 1, 40.420000, -3.700000)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, rental.Cost)
+	assert.Equal(t, 1.0, *rental.Cost)
+	assert.Len(t, rental.CostBreakdown, 2)
+	assert.Equal(t, pricing.LineItemUnlock, rental.CostBreakdown[0].Code)
+	assert.Equal(t, pricing.LineItemFreeMinutes, rental.CostBreakdown[1].Code)
 }
 
-// TestRentalService_EndRental_GetActiveRentalError tests error when getting active rental
-func TestRentalService_EndRental_GetActiveRentalError(t *testing.T) {
-	mockRentalRepo := &MockRentalRepository{
-		GetActiveRentalByUserFunc: func(userID int) (*models.Rental, error) {
-			return nil, errors.New("database error")
-		},
-	}
+func TestRentalService_EndRental_AppliesZoneRates(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	startStationID := insertTestStation(t, db, "Sol", 40.416775, -3.703790, 100, 10)
+	endStationID := insertTestStation(t, db, "Atocha", 40.406600, -3.689200, 100, 10)
+	service := newTestRentalService(db)
 
-	service := &RentalService{rentalRepo: mockRentalRepo, bikeRepo: nil}
-	rental, err := service.EndRental(1, 40.420000, -3.700000)
+	result, err := db.Exec("INSERT INTO price_plans (name) VALUES ('Centro')")
+	assert.NoError(t, err)
+	planID, _ := result.LastInsertId()
+	_, err = db.Exec(
+		"INSERT INTO price_plan_zone_rates (price_plan_id, station_id, apply_to, fee, multiplier) VALUES (?, ?, 'start', 0.5, 2), (?, ?, 'end', 3.0, 1)",
+		planID, startStationID, planID, endStationID,
+	)
+	assert.NoError(t, err)
+	_, err = db.Exec("UPDATE bikes SET price_plan_id = ? WHERE id = ?", planID, bikeID)
+	assert.NoError(t, err)
+
+	started, err := service.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+	backdateTestRental(t, db, started.ID, 3*time.Minute)
+
+	// The trip starts inside Sol and ends outside Atocha, so only the start
+	// rate applies.
+	rental, err := service.EndRental(t.Context(), 1, 40.420000, -3.700000)
+
+	assert.NoError(t, err)
+	assert.Len(t, rental.CostBreakdown, 2)
+	assert.Equal(t, pricing.LineItemZone, rental.CostBreakdown[0].Code)
+	assert.Equal(t, "Start zone fee (Sol)", rental.CostBreakdown[0].Description)
+	assert.Equal(t, 0.5, rental.CostBreakdown[0].Amount)
+	// The ride line item may be night or weekend time depending on when the
+	// test runs, but the plan's multipliers for those default to 1.
+	assert.Equal(t, 1.0, rental.CostBreakdown[1].UnitPrice)
+	assert.Equal(t, 0.5+float64(rental.CostBreakdown[1].Quantity), *rental.Cost)
+}
+
+func TestRentalService_EndRental_NoActiveRental(t *testing.T) {
+	db := newTestDB(t)
+
+	service := newTestRentalService(db)
+	rental, err := service.EndRental(t.Context(), 1, 40.420000, -3.700000)
 
 	assert.Error(t, err)
-	assert.Equal(t, "database error", err.Error())
+	assert.Equal(t, constants.ErrNoActiveRental, err)
 	assert.Nil(t, rental)
 }
 
 // TestRentalService_EndRental_EndLocationTooFar tests error when end location is more than 5km away
+// under the default rule
 func TestRentalService_EndRental_EndLocationTooFar(t *testing.T) {
-	startTime := time.Now().Add(-30 * time.Minute)
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	service := newTestRentalService(db)
 
-	mockRentalRepo := &MockRentalRepository{
-		GetActiveRentalByUserFunc: func(userID int) (*models.Rental, error) {
-			return &models.Rental{
-				ID:             1,
-				UserID:         userID,
-				BikeID:         1,
-				StartLatitude:  40.416775,
-				StartLongitude: -3.703790,
-				Status:         "running",
-				StartTime:      startTime,
-			}, nil
-		},
-	}
+	_, err := service.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
 
-	service := &RentalService{rentalRepo: mockRentalRepo, bikeRepo: nil}
 	// End location more than 5km away (Paris coordinates - ~1050km from Madrid)
-	rental, err := service.EndRental(1, 48.856614, 2.352222)
+	rental, err := service.EndRental(t.Context(), 1, 48.856614, 2.352222)
 
-	assert.Error(t, err)
-	assert.Equal(t, constants.ErrEndLocationTooFar, err)
+	assert.ErrorIs(t, err, constants.ErrEndLocationTooFar)
+	assert.EqualError(t, err, "end location must be within 5 km of the start location")
 	assert.Nil(t, rental)
+	assert.False(t, bikeIsAvailable(t, db, bikeID))
 }
 
-// TestRentalService_EndRental_GetBikeError tests error when getting bike
-func TestRentalService_EndRental_GetBikeError(t *testing.T) {
-	startTime := time.Now().Add(-30 * time.Minute)
+func TestRentalService_EndRental_ParksBikeAtStation(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	stationID := insertTestStation(t, db, "Sol", 40.420000, -3.700000, 50, 10)
+	service := newTestRentalService(db)
 
-	mockRentalRepo := &MockRentalRepository{
-		GetActiveRentalByUserFunc: func(userID int) (*models.Rental, error) {
-			return &models.Rental{
-				ID:             1,
-				UserID:         userID,
-				BikeID:         1,
-				StartLatitude:  40.416775,
-				StartLongitude: -3.703790,
-				Status:         "running",
-				StartTime:      startTime,
-			}, nil
-		},
+	_, err := service.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	_, err = service.EndRental(t.Context(), 1, 40.420100, -3.700000)
+	assert.NoError(t, err)
+
+	bike, err := repositories.NewBikeRepository(db).GetByID(t.Context(), bikeID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, bike.StationID) {
+		assert.Equal(t, stationID, *bike.StationID)
 	}
 
-	mockBikeRepo := &MockBikeRepository{
-		GetByIDFunc: func(bikeID int) (*models.Bike, error) {
-			return nil, errors.New("bike not found")
-		},
+	_, err = service.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	bike, err = repositories.NewBikeRepository(db).GetByID(t.Context(), bikeID)
+	assert.NoError(t, err)
+	assert.Nil(t, bike.StationID)
+}
+
+func TestRentalService_EndRental_MovesBikeToEndLocation(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	service := newTestRentalService(db)
+
+	_, err := service.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	_, err = service.EndRental(t.Context(), 1, 40.440000, -3.690000)
+	assert.NoError(t, err)
+
+	bike, err := repositories.NewBikeRepository(db).GetByID(t.Context(), bikeID)
+	assert.NoError(t, err)
+	assert.Equal(t, 40.440000, bike.Latitude)
+	assert.Equal(t, -3.690000, bike.Longitude)
+	assert.Nil(t, bike.StationID)
+
+	bikeService := NewBikeService(repositories.NewBikeRepository(db), 0)
+	nearEnd, _, err := bikeService.GetNearbyBikes(t.Context(), "", 40.440000, -3.690000, 0.5, 1, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, nearEnd, 1) {
+		assert.Equal(t, bikeID, nearEnd[0].ID)
 	}
 
-	service := &RentalService{rentalRepo: mockRentalRepo, bikeRepo: mockBikeRepo}
-	rental, err := service.EndRental(1, 40.420000, -3.700000)
+	nearStart, _, err := bikeService.GetNearbyBikes(t.Context(), "", 40.416775, -3.703790, 0.5, 1, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, nearStart)
 
-	assert.Error(t, err)
-	assert.Equal(t, "bike not found", err.Error())
+	// The next rental starts where the previous one ended.
+	rental, err := service.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+	assert.Equal(t, 40.440000, rental.StartLatitude)
+	assert.Equal(t, -3.690000, rental.StartLongitude)
+}
+
+func TestRentalService_EndRental_StationRequired(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	otherBikeID := insertTestBike(t, db, true, 40.420000, -3.700000, 0.5)
+	stationID := insertTestStation(t, db, "Sol", 40.420000, -3.700000, 50, 1)
+	service := NewRentalService(repositories.NewRentalRepository(db), repositories.NewUnitOfWork(db), 0.1, StationRules{Policy: ReturnStationRequired}, ZoneRules{}, testReturnRule)
+
+	_, err := service.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	outside := testutil.ToFloat64(metrics.ReturnsRejected.WithLabelValues(metrics.ReasonOutsideStation))
+	rental, err := service.EndRental(t.Context(), 1, 40.410000, -3.700000)
+	assert.ErrorIs(t, err, constants.ErrReturnOutsideStation)
+	assert.Nil(t, rental)
+	assert.Equal(t, outside+1, testutil.ToFloat64(metrics.ReturnsRejected.WithLabelValues(metrics.ReasonOutsideStation)))
+
+	_, err = db.Exec("UPDATE bikes SET station_id = ? WHERE id = ?", stationID, otherBikeID)
+	assert.NoError(t, err)
+
+	rental, err = service.EndRental(t.Context(), 1, 40.420000, -3.700000)
+	assert.ErrorIs(t, err, constants.ErrStationFull)
 	assert.Nil(t, rental)
+	assert.False(t, bikeIsAvailable(t, db, bikeID))
+
+	_, err = db.Exec("UPDATE stations SET capacity = 2 WHERE id = ?", stationID)
+	assert.NoError(t, err)
+
+	rental, err = service.EndRental(t.Context(), 1, 40.420000, -3.700000)
+	assert.NoError(t, err)
+	assert.Equal(t, models.RentalStatusEnded, rental.Status)
 }
 
-// TestRentalService_EndRental_EndRentalError tests error when ending rental in repository
-func TestRentalService_EndRental_EndRentalError(t *testing.T) {
-	startTime := time.Now().Add(-30 * time.Minute)
+func TestRentalService_EndRental_OutOfStationSurcharge(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	service := NewRentalService(repositories.NewRentalRepository(db), repositories.NewUnitOfWork(db), 0.1, StationRules{Policy: ReturnSurcharge, OutOfStationFee: 2}, ZoneRules{}, testReturnRule)
 
-	mockRentalRepo := &MockRentalRepository{
-		GetActiveRentalByUserFunc: func(userID int) (*models.Rental, error) {
-			return &models.Rental{
-				ID:             1,
-				UserID:         userID,
-				BikeID:         1,
-				StartLatitude:  40.416775,
-				StartLongitude: -3.703790,
-				Status:         "running",
-				StartTime:      startTime,
-			}, nil
-		},
-		EndRentalFunc: func(rentalID int, endLat, endLong float64, durationMinutes int, cost float64) (*models.Rental, error) {
-			return nil, errors.New("update error")
-		},
+	_, err := service.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	rental, err := service.EndRental(t.Context(), 1, 40.420000, -3.700000)
+
+	assert.NoError(t, err)
+	assert.Len(t, rental.CostBreakdown, 2)
+	assert.Equal(t, pricing.LineItemOutOfStation, rental.CostBreakdown[1].Code)
+	assert.Equal(t, float64(*rental.DurationMinutes)*0.5+2, *rental.Cost)
+}
+
+func TestRentalService_RecordsMetrics(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	service := newTestRentalService(db)
+
+	_, err := db.Exec("INSERT INTO price_plans (name, unlock_fee, free_minutes) VALUES ('Unlock', 1.0, 5)")
+	assert.NoError(t, err)
+	_, err = db.Exec("UPDATE bikes SET price_plan_id = (SELECT MAX(id) FROM price_plans) WHERE id = ?", bikeID)
+	assert.NoError(t, err)
+
+	started := testutil.ToFloat64(metrics.RentalsStarted)
+	ended := testutil.ToFloat64(metrics.RentalsClosed.WithLabelValues("ended"))
+	revenue := testutil.ToFloat64(metrics.Revenue)
+	rejected := testutil.ToFloat64(metrics.ReturnsRejected.WithLabelValues(metrics.ReasonLocationTooFar))
+
+	_, err = service.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+	_, err = service.EndRental(t.Context(), 1, 48.856614, 2.352222)
+	assert.ErrorIs(t, err, constants.ErrEndLocationTooFar)
+	_, err = service.EndRental(t.Context(), 1, 40.420000, -3.700000)
+	assert.NoError(t, err)
+
+	assert.Equal(t, started+1, testutil.ToFloat64(metrics.RentalsStarted))
+	assert.Equal(t, rejected+1, testutil.ToFloat64(metrics.ReturnsRejected.WithLabelValues(metrics.ReasonLocationTooFar)))
+	assert.Equal(t, ended+1, testutil.ToFloat64(metrics.RentalsClosed.WithLabelValues("ended")))
+	assert.InDelta(t, revenue+1.0, testutil.ToFloat64(metrics.Revenue), 0.0001)
+}
+
+// TestRentalService_EndRental_ConcurrentEnds tests that parallel end requests
+// for the same rental only end it once
+// TestRentalService_StoresTimesInUTC tests that rental times compare
+// correctly with UTC filters on a host outside UTC, and that the stored end
+// time is the one the rental was billed up to
+func TestRentalService_StoresTimesInUTC(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("UTC+5", 5*60*60)
+	t.Cleanup(func() { time.Local = local })
+
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	service := newTestRentalService(db)
+
+	started, err := service.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+	ended, err := service.EndRental(t.Context(), 1, 40.420000, -3.700000)
+	assert.NoError(t, err)
+
+	segments, err := repositories.NewRentalSegmentRepository(db).GetByRental(t.Context(), started.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, segments, 1) && assert.NotNil(t, segments[0].EndedAt) {
+		assert.True(t, ended.StartTime.Equal(segments[0].StartedAt))
+		assert.True(t, ended.EndTime.Equal(*segments[0].EndedAt))
 	}
 
-	mockBikeRepo := &MockBikeRepository{
-		GetByIDFunc: func(bikeID int) (*models.Bike, error) {
-			return &models.Bike{
-				ID:             bikeID,
-				Latitude:       40.416775,
-				Longitude:      -3.703790,
-				IsAvailable:    false,
-				PricePerMinute: 0.5,
-			}, nil
-		},
+	spec := &queryspec.Spec{Filters: []queryspec.Filter{
+		{Column: "start_time", Op: queryspec.OpLte, Value: utcNow().Add(time.Minute)},
+		{Column: "end_time", Op: queryspec.OpGte, Value: utcNow().Add(-time.Minute)},
+	}}
+	rentals, err := repositories.NewAdminRepository(db).GetAllRentals(t.Context(), spec, 1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, rentals, 1)
+}
+
+func TestRentalService_EndRental_ConcurrentEnds(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	service := newTestRentalService(db)
+
+	_, err := service.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	const attempts = 20
+
+	var wg sync.WaitGroup
+	errs := make(chan error, attempts)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := service.EndRental(t.Context(), 1, 40.420000, -3.700000)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	successes := 0
+	for err := range errs {
+		if err == nil {
+			successes++
+			continue
+		}
+		assert.Equal(t, constants.ErrNoActiveRental, err)
 	}
 
-	service := &RentalService{rentalRepo: mockRentalRepo, bikeRepo: mockBikeRepo}
-	rental, err := service.EndRental(1, 40.420000, -3.700000)
+	assert.Equal(t, 1, successes)
+	assert.True(t, bikeIsAvailable(t, db, bikeID))
+}
+
+// TestRentalService_EndRental_ConcurrentReturnsToStation tests that riders
+// racing for the last docks of a station never park more bikes than it holds
+func TestRentalService_EndRental_ConcurrentReturnsToStation(t *testing.T) {
+	db := newTestDB(t)
+	stationID := insertTestStation(t, db, "Sol", 40.420000, -3.700000, 50, 3)
+	service := NewRentalService(repositories.NewRentalRepository(db), repositories.NewUnitOfWork(db), 0.1, StationRules{Policy: ReturnStationRequired}, ZoneRules{NoParking: NoParkingReject}, testReturnRule)
+
+	const riders = 20
+
+	for userID := 1; userID <= riders; userID++ {
+		bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+		_, err := service.StartRental(t.Context(), userID, bikeID)
+		assert.NoError(t, err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, riders)
+	for userID := 1; userID <= riders; userID++ {
+		wg.Add(1)
+		go func(userID int) {
+			defer wg.Done()
+			_, err := service.EndRental(t.Context(), userID, 40.420100, -3.700000)
+			errs <- err
+		}(userID)
+	}
+	wg.Wait()
+	close(errs)
+
+	successes := 0
+	for err := range errs {
+		if err == nil {
+			successes++
+			continue
+		}
+		assert.Equal(t, constants.ErrStationFull, err)
+	}
+
+	var parked int
+	err := db.QueryRow("SELECT COUNT(*) FROM bikes WHERE station_id = ?", stationID).Scan(&parked)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 3, successes)
+	assert.Equal(t, 3, parked)
+}
+
+// faultyTx times out the statements of a transaction that contain failOn,
+// standing in for a repository call that fails halfway through a service
+// method.
+type faultyTx struct {
+	*database.Tx
+	failOn string
+}
+
+func (f *faultyTx) context(ctx context.Context, query string) context.Context {
+	if !strings.Contains(query, f.failOn) {
+		return ctx
+	}
+	ctx, cancel := context.WithDeadline(ctx, time.Time{})
+	cancel()
+	return ctx
+}
+
+func (f *faultyTx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return f.Tx.ExecContext(f.context(ctx, query), query, args...)
+}
+
+func (f *faultyTx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return f.Tx.QueryContext(f.context(ctx, query), query, args...)
+}
+
+func (f *faultyTx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return f.Tx.QueryRowContext(f.context(ctx, query), query, args...)
+}
+
+// faultyUnitOfWork runs fn in a transaction whose repositories go through a
+// faultyTx, and records whether the transaction was rolled back.
+type faultyUnitOfWork struct {
+	db         *database.DB
+	failOn     string
+	rolledBack bool
+}
+
+func (u *faultyUnitOfWork) WithTx(ctx context.Context, fn func(tx *repositories.Tx) error) error {
+	sqlTx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(repositories.NewTx(&faultyTx{Tx: sqlTx, failOn: u.failOn})); err != nil {
+		u.rolledBack = sqlTx.Rollback() == nil
+		return err
+	}
+	return sqlTx.Commit()
+}
+
+// assertEndRentalRollsBack ends a rental while the statement containing
+// failOn times out, and checks that nothing EndRental wrote before the
+// failure was kept.
+func assertEndRentalRollsBack(t *testing.T, failOn string) {
+	t.Helper()
+
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	service := newTestRentalService(db)
+
+	started, err := service.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	uow := &faultyUnitOfWork{db: db, failOn: failOn}
+	faulty := *service
+	faulty.uow = uow
+
+	rental, err := faulty.EndRental(t.Context(), 1, 40.420000, -3.700000)
 
-	assert.Error(t, err)
-	assert.Equal(t, "update error", err.Error())
 	assert.Nil(t, rental)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, uow.rolledBack)
+
+	active, err := repositories.NewRentalRepository(db).GetActiveRentalByUser(t.Context(), 1)
+	assert.NoError(t, err)
+	if assert.NotNil(t, active) {
+		assert.Equal(t, started.ID, active.ID)
+		assert.Equal(t, models.RentalStatusRunning, active.Status)
+		assert.True(t, active.EndTime.IsZero())
+		assert.Nil(t, active.Cost)
+	}
+
+	bike, err := repositories.NewBikeRepository(db).GetByID(t.Context(), bikeID)
+	assert.NoError(t, err)
+	assert.False(t, bike.IsAvailable)
+	assert.Equal(t, 40.416775, bike.Latitude)
+	assert.Equal(t, -3.703790, bike.Longitude)
+
+	segments, err := repositories.NewRentalSegmentRepository(db).GetByRental(t.Context(), started.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, segments, 1) {
+		assert.Nil(t, segments[0].EndedAt)
+	}
+
+	points, err := repositories.NewRentalPointRepository(db).GetByRental(t.Context(), started.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, points)
+
+	// Nothing is left locked or half written, so the rental can still end.
+	ended, err := service.EndRental(t.Context(), 1, 40.420000, -3.700000)
+	if assert.NoError(t, err) {
+		assert.Equal(t, models.RentalStatusEnded, ended.Status)
+	}
+	assert.True(t, bikeIsAvailable(t, db, bikeID))
+}
+
+// TestRentalService_EndRental_GetActiveRentalError tests that a failed lookup
+// of the active rental ends nothing
+func TestRentalService_EndRental_GetActiveRentalError(t *testing.T) {
+	assertEndRentalRollsBack(t, "FROM rentals WHERE user_id = ?")
+}
+
+// TestRentalService_EndRental_GetBikeError tests that a failed bike lookup
+// rolls back the closed segment and the recorded end point
+func TestRentalService_EndRental_GetBikeError(t *testing.T) {
+	assertEndRentalRollsBack(t, "FROM bikes WHERE id = ?")
+}
+
+// TestRentalService_EndRental_EndRentalError tests that a failed rental
+// update rolls back the closed segment and the recorded end point
+func TestRentalService_EndRental_EndRentalError(t *testing.T) {
+	assertEndRentalRollsBack(t, "UPDATE rentals SET status = ?, end_time")
 }
 
-// TestRentalService_EndRental_UpdateAvailabilityError tests error when updating bike availability after ending rental
+// TestRentalService_EndRental_UpdateAvailabilityError tests that failing to
+// free the bike leaves the rental running
 func TestRentalService_EndRental_UpdateAvailabilityError(t *testing.T) {
-	startTime := time.Now().Add(-30 * time.Minute)
+	assertEndRentalRollsBack(t, "UPDATE bikes SET is_available = ?")
+}
 
-	mockRentalRepo := &MockRentalRepository{
-		GetActiveRentalByUserFunc: func(userID int) (*models.Rental, error) {
-			return &models.Rental{
-				ID:             1,
-				UserID:         userID,
-				BikeID:         1,
-				StartLatitude:  40.416775,
-				StartLongitude: -3.703790,
-				Status:         "running",
-				StartTime:      startTime,
-			}, nil
-		},
-		EndRentalFunc: func(rentalID int, endLat, endLong float64, durationMinutes int, cost float64) (*models.Rental, error) {
-			return &models.Rental{
-				ID:     rentalID,
-				Status: "ended",
-			}, nil
-		},
-	}
+// TestRentalService_EndRental_ParkAtError tests that failing to move the bike
+// to the end location, the last step, undoes the whole return
+func TestRentalService_EndRental_ParkAtError(t *testing.T) {
+	assertEndRentalRollsBack(t, "UPDATE bikes SET station_id")
+}
 
-	mockBikeRepo := &MockBikeRepository{
-		GetByIDFunc: func(bikeID int) (*models.Bike, error) {
-			return &models.Bike{
-				ID:             bikeID,
-				Latitude:       40.416775,
-				Longitude:      -3.703790,
-				IsAvailable:    false,
-				PricePerMinute: 0.5,
-			}, nil
-		},
-		UpdateAvailabilityFunc: func(bikeID int, isAvailable bool) error {
-			return errors.New("availability update error")
-		},
+// assertStartRentalRollsBack starts a rental while the statement containing
+// failOn times out, and checks that the bike stays available and no rental
+// is left behind.
+func assertStartRentalRollsBack(t *testing.T, failOn string) {
+	t.Helper()
+
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	service := newTestRentalService(db)
+
+	uow := &faultyUnitOfWork{db: db, failOn: failOn}
+	faulty := *service
+	faulty.uow = uow
+
+	rental, err := faulty.StartRental(t.Context(), 1, bikeID)
+
+	assert.Nil(t, rental)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, uow.rolledBack)
+	assert.True(t, bikeIsAvailable(t, db, bikeID))
+
+	var rentals, segments int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM rentals").Scan(&rentals))
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM rental_segments").Scan(&segments))
+	assert.Equal(t, 0, rentals)
+	assert.Equal(t, 0, segments)
+
+	// Nothing is left locked or half written, so the bike can still be rented.
+	started, err := service.StartRental(t.Context(), 1, bikeID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, models.RentalStatusRunning, started.Status)
 	}
+	assert.False(t, bikeIsAvailable(t, db, bikeID))
+}
 
-	service := &RentalService{rentalRepo: mockRentalRepo, bikeRepo: mockBikeRepo}
-	rental, err := service.EndRental(1, 40.420000, -3.700000)
+// TestRentalService_StartRental_CreateRentalError tests that a failed rental
+// insert gives the claimed bike back
+func TestRentalService_StartRental_CreateRentalError(t *testing.T) {
+	assertStartRentalRollsBack(t, "INSERT INTO rentals")
+}
 
-	assert.Error(t, err)
-	assert.Equal(t, "availability update error", err.Error())
+// TestRentalService_StartRental_UpdateAvailabilityError tests that a failed
+// bike claim starts no rental
+func TestRentalService_StartRental_UpdateAvailabilityError(t *testing.T) {
+	assertStartRentalRollsBack(t, "UPDATE bikes SET is_available = 0")
+}
+
+func TestRentalService_PauseAndResumeRental(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	otherBikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	service := newTestRentalService(db)
+
+	_, err := service.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	paused, err := service.PauseRental(t.Context(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, models.RentalStatusPaused, paused.Status)
+	assert.Len(t, paused.Segments, 2)
+	assert.NotNil(t, paused.Segments[0].EndedAt)
+	assert.Equal(t, models.RentalStatusPaused, paused.Segments[1].State)
+	assert.False(t, bikeIsAvailable(t, db, bikeID))
+
+	_, err = service.PauseRental(t.Context(), 1)
+	assert.Equal(t, constants.ErrRentalNotRunning, err)
+
+	_, err = service.StartRental(t.Context(), 1, otherBikeID)
+	assert.Equal(t, constants.ErrUserHasActiveRental, err)
+
+	resumed, err := service.ResumeRental(t.Context(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, models.RentalStatusRunning, resumed.Status)
+	assert.Len(t, resumed.Segments, 3)
+
+	_, err = service.ResumeRental(t.Context(), 1)
+	assert.Equal(t, constants.ErrRentalNotPaused, err)
+}
+
+func TestRentalService_PauseRental_NoActiveRental(t *testing.T) {
+	db := newTestDB(t)
+	service := newTestRentalService(db)
+
+	rental, err := service.PauseRental(t.Context(), 1)
+
+	assert.Equal(t, constants.ErrNoActiveRental, err)
 	assert.Nil(t, rental)
-}
\ No newline at end of file
+}
+
+func TestRentalService_EndRental_WhilePaused(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	service := newTestRentalService(db)
+
+	_, err := service.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+	_, err = service.PauseRental(t.Context(), 1)
+	assert.NoError(t, err)
+
+	rental, err := service.EndRental(t.Context(), 1, 40.420000, -3.700000)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.RentalStatusEnded, rental.Status)
+	assert.True(t, bikeIsAvailable(t, db, bikeID))
+	for _, segment := range rental.Segments {
+		assert.NotNil(t, segment.EndedAt)
+	}
+}
+
+func TestRentalService_EndRental_BillsPausedTime(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	service := newTestRentalService(db)
+
+	started, err := service.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	// Rewrite the timeline as 10 minutes riding, 10 paused, then riding again.
+	now := time.Now().UTC().Truncate(time.Second)
+	_, err = db.Exec("UPDATE rentals SET start_time = ? WHERE id = ?", now.Add(-30*time.Minute), started.ID)
+	assert.NoError(t, err)
+	_, err = db.Exec("DELETE FROM rental_segments WHERE rental_id = ?", started.ID)
+	assert.NoError(t, err)
+	_, err = db.Exec(
+		`INSERT INTO rental_segments (rental_id, state, started_at, ended_at) VALUES
+		(?, 'running', ?, ?), (?, 'paused', ?, ?), (?, 'running', ?, NULL)`,
+		started.ID, now.Add(-30*time.Minute), now.Add(-20*time.Minute),
+		started.ID, now.Add(-20*time.Minute), now.Add(-10*time.Minute),
+		started.ID, now.Add(-10*time.Minute),
+	)
+	assert.NoError(t, err)
+
+	rental, err := service.EndRental(t.Context(), 1, 40.420000, -3.700000)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, rental.DurationMinutes)
+	assert.Len(t, rental.CostBreakdown, 2)
+	assert.Equal(t, pricing.LineItemPaused, rental.CostBreakdown[1].Code)
+	assert.Equal(t, 10, rental.CostBreakdown[1].Quantity)
+	assert.Equal(t, 1.0, rental.CostBreakdown[1].Amount)
+	assert.InDelta(t, float64(*rental.DurationMinutes-10)*0.5+1.0, *rental.Cost, 0.001)
+}
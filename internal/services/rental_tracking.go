@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/repositories"
+	"github.com/Nimirandad/bike-rental-service/internal/utils"
+)
+
+// RentalPointRepository reads the points recorded for a rental. It is
+// satisfied by *repositories.RentalPointRepository.
+type RentalPointRepository interface {
+	GetByRental(ctx context.Context, rentalID int) ([]models.RentalPoint, error)
+}
+
+// TrackRental records a batch of locations for the user's active rental,
+// running or paused. Points without a timestamp are recorded at the current
+// time; points recorded before the rental started are rejected.
+func (s *RentalService) TrackRental(ctx context.Context, userID int, points []models.RentalPoint) (int, error) {
+	err := s.uow.WithTx(ctx, func(tx *repositories.Tx) error {
+		activeRental, err := tx.Rentals.GetActiveRentalByUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if activeRental == nil {
+			return constants.ErrNoActiveRental
+		}
+
+		now := utcNow()
+		for i := range points {
+			if points[i].RecordedAt.IsZero() {
+				points[i].RecordedAt = now
+			}
+			if points[i].RecordedAt.Before(activeRental.StartTime) {
+				return constants.ErrTrackPointTooEarly
+			}
+		}
+
+		return tx.RentalPoints.CreateBatch(ctx, activeRental.ID, points)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(points), nil
+}
+
+// GetRentalRoute returns the route of one of the user's rentals. Rentals of
+// other users are reported as not found.
+func (s *RentalService) GetRentalRoute(ctx context.Context, userID, rentalID int) (*models.RentalRoute, error) {
+	var route *models.RentalRoute
+	err := s.uow.WithTx(ctx, func(tx *repositories.Tx) error {
+		rental, err := tx.Rentals.GetByID(ctx, rentalID)
+		if err != nil {
+			return err
+		}
+		if rental.UserID != userID {
+			return constants.ErrRentalNotFound
+		}
+
+		route, err = rentalRoute(ctx, tx.RentalPoints, rental)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return route, nil
+}
+
+// rentalRoute builds the route from the rental's start location and its
+// recorded points.
+func rentalRoute(ctx context.Context, pointRepo RentalPointRepository, rental *models.Rental) (*models.RentalRoute, error) {
+	recorded, err := pointRepo.GetByRental(ctx, rental.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	start := models.RentalPoint{
+		RentalID:   rental.ID,
+		Latitude:   rental.StartLatitude,
+		Longitude:  rental.StartLongitude,
+		RecordedAt: rental.StartTime,
+	}
+
+	route := &models.RentalRoute{
+		RentalID: rental.ID,
+		Status:   rental.Status,
+		Points:   append([]models.RentalPoint{start}, recorded...),
+	}
+	route.DistanceKm = utils.PathDistance(route.GeoPoints())
+
+	return route, nil
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRentalService_TrackRental_RecordsRouteAndDistance(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	service := newTestRentalService(db)
+
+	rental, err := service.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	recorded, err := service.TrackRental(t.Context(), 1, []models.RentalPoint{
+		{Latitude: 40.418000, Longitude: -3.703790},
+		{Latitude: 40.420000, Longitude: -3.703790},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, recorded)
+
+	route, err := service.GetRentalRoute(t.Context(), 1, rental.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.RentalStatusRunning, route.Status)
+	assert.Len(t, route.Points, 3)
+	assert.InDelta(t, 0.358, route.DistanceKm, 0.002)
+
+	ended, err := service.EndRental(t.Context(), 1, 40.420000, -3.700000)
+	assert.NoError(t, err)
+	assert.NotNil(t, ended.DistanceKm)
+	assert.InDelta(t, 0.679, *ended.DistanceKm, 0.002)
+
+	route, err = service.GetRentalRoute(t.Context(), 1, rental.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.RentalStatusEnded, route.Status)
+	assert.Len(t, route.Points, 4)
+	assert.Equal(t, *ended.DistanceKm, route.DistanceKm)
+}
+
+func TestRentalService_EndRental_DistanceWithoutTracking(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	service := newTestRentalService(db)
+
+	_, err := service.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	ended, err := service.EndRental(t.Context(), 1, 40.420000, -3.703790)
+	assert.NoError(t, err)
+	assert.NotNil(t, ended.DistanceKm)
+	assert.InDelta(t, 0.358, *ended.DistanceKm, 0.002)
+}
+
+func TestRentalService_TrackRental_NoActiveRental(t *testing.T) {
+	db := newTestDB(t)
+	service := newTestRentalService(db)
+
+	_, err := service.TrackRental(t.Context(), 1, []models.RentalPoint{{Latitude: 40.4, Longitude: -3.7}})
+
+	assert.ErrorIs(t, err, constants.ErrNoActiveRental)
+}
+
+func TestRentalService_TrackRental_PointBeforeStart(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	service := newTestRentalService(db)
+
+	rental, err := service.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	_, err = service.TrackRental(t.Context(), 1, []models.RentalPoint{
+		{Latitude: 40.418, Longitude: -3.7038},
+		{Latitude: 40.419, Longitude: -3.7038, RecordedAt: rental.StartTime.Add(-time.Hour)},
+	})
+	assert.ErrorIs(t, err, constants.ErrTrackPointTooEarly)
+
+	route, err := service.GetRentalRoute(t.Context(), 1, rental.ID)
+	assert.NoError(t, err)
+	assert.Len(t, route.Points, 1, "a rejected batch must not be partially stored")
+}
+
+func TestRentalService_GetRentalRoute_OtherUser(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	service := newTestRentalService(db)
+
+	rental, err := service.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	_, err = service.GetRentalRoute(t.Context(), 2, rental.ID)
+	assert.ErrorIs(t, err, constants.ErrRentalNotFound)
+
+	_, err = service.GetRentalRoute(t.Context(), 1, rental.ID+1)
+	assert.ErrorIs(t, err, constants.ErrRentalNotFound)
+}
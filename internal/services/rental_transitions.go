@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"slices"
+
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/pricing"
+	"github.com/Nimirandad/bike-rental-service/internal/repositories"
+)
+
+// transitionRental moves rental to status inside tx and applies the side
+// effects of the change: segments for pause and resume, end time, cost and
+// bike availability for ended and cancelled, and a refund line item that
+// zeroes the cost for refunded. Transitions not allowed by the state machine
+// return a *models.RentalTransitionError.
+func transitionRental(ctx context.Context, tx *repositories.Tx, rental *models.Rental, to models.RentalStatus, pausedPricePerMinute float64) (*models.Rental, error) {
+	if !rental.Status.CanTransitionTo(to) {
+		return nil, models.NewRentalTransitionError(rental.Status, to)
+	}
+
+	switch to {
+	case models.RentalStatusRunning, models.RentalStatusPaused:
+		return changeSegment(ctx, tx, rental, to)
+	case models.RentalStatusEnded, models.RentalStatusCancelled:
+		return closeRental(ctx, tx, rental, to, nil, nil, pausedPricePerMinute, nil)
+	case models.RentalStatusRefunded:
+		return refundRental(ctx, tx, rental)
+	default:
+		updated, err := tx.Rentals.UpdateStatus(ctx, rental.ID, rental.Status, to)
+		if err != nil {
+			return nil, err
+		}
+		if !updated {
+			return nil, models.NewRentalTransitionError(rental.Status, to)
+		}
+		return tx.Rentals.GetByID(ctx, rental.ID)
+	}
+}
+
+// changeSegment moves an active rental between running and paused, closing
+// the current segment and opening a new one at the same instant.
+func changeSegment(ctx context.Context, tx *repositories.Tx, rental *models.Rental, to models.RentalStatus) (*models.Rental, error) {
+	updated, err := tx.Rentals.UpdateStatus(ctx, rental.ID, rental.Status, to)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, models.NewRentalTransitionError(rental.Status, to)
+	}
+
+	now := utcNow()
+	if err := tx.Segments.CloseOpen(ctx, rental.ID, now); err != nil {
+		return nil, err
+	}
+	if err := tx.Segments.Open(ctx, rental.ID, to, now); err != nil {
+		return nil, err
+	}
+
+	changed, err := tx.Rentals.GetByID(ctx, rental.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	changed.Segments, err = tx.Segments.GetByRental(ctx, rental.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return changed, nil
+}
+
+// closeRental ends an active rental with status ended or cancelled and frees
+// its bike. The end location, when given, is recorded as the last point of
+// the route, and the route's length as the ridden distance. Ended rentals are
+// priced with the bike's pricing policy plus fees; cancelled rentals record
+// their duration at no cost.
+func closeRental(ctx context.Context, tx *repositories.Tx, rental *models.Rental, status models.RentalStatus, endLat, endLong *float64, pausedPricePerMinute float64, fees []pricing.Fee) (*models.Rental, error) {
+	if !rental.Status.CanTransitionTo(status) {
+		return nil, models.NewRentalTransitionError(rental.Status, status)
+	}
+
+	endTime := utcNow()
+	if err := tx.Segments.CloseOpen(ctx, rental.ID, endTime); err != nil {
+		return nil, err
+	}
+
+	if endLat != nil && endLong != nil {
+		end := models.RentalPoint{Latitude: *endLat, Longitude: *endLong, RecordedAt: endTime}
+		if err := tx.RentalPoints.CreateBatch(ctx, rental.ID, []models.RentalPoint{end}); err != nil {
+			return nil, err
+		}
+	}
+
+	route, err := rentalRoute(ctx, tx.RentalPoints, rental)
+	if err != nil {
+		return nil, err
+	}
+
+	segments, err := tx.Segments.GetByRental(ctx, rental.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	trip := pricing.Trip{
+		StartTime: rental.StartTime,
+		EndTime:   endTime,
+		Pauses:    pausesFrom(segments),
+		Start:     pricing.Location{Latitude: rental.StartLatitude, Longitude: rental.StartLongitude},
+	}
+	if endLat != nil && endLong != nil {
+		trip.End = &pricing.Location{Latitude: *endLat, Longitude: *endLong}
+	}
+
+	quote := pricing.Quote{Minutes: trip.Minutes()}
+	if status == models.RentalStatusEnded {
+		bike, err := tx.Bikes.GetByID(ctx, rental.BikeID)
+		if err != nil {
+			return nil, err
+		}
+
+		policy, err := pricingPolicyFor(ctx, tx, bike, pausedPricePerMinute)
+		if err != nil {
+			return nil, err
+		}
+
+		quote = policy.Quote(trip)
+		for _, fee := range fees {
+			quote.AddFee(fee)
+		}
+	}
+
+	closed, err := tx.Rentals.Close(ctx, rental.ID, status, endTime, endLat, endLong, &route.DistanceKm, quote.Minutes, quote.Total, quote.LineItems)
+	if err != nil {
+		return nil, err
+	}
+	closed.Segments = segments
+
+	if err := tx.Bikes.UpdateAvailability(ctx, rental.BikeID, true); err != nil {
+		return nil, err
+	}
+
+	return closed, nil
+}
+
+// refundRental moves an ended rental to refunded. Its cost drops to zero and
+// its breakdown keeps the original charges, followed by a refund line item
+// that cancels them.
+func refundRental(ctx context.Context, tx *repositories.Tx, rental *models.Rental) (*models.Rental, error) {
+	charged := 0.0
+	if rental.Cost != nil {
+		charged = *rental.Cost
+	}
+
+	breakdown := append(slices.Clone(rental.CostBreakdown), models.CostLineItem{
+		Code:        pricing.LineItemRefund,
+		Description: "Refund",
+		Amount:      -charged,
+	})
+
+	updated, err := tx.Rentals.Refund(ctx, rental.ID, 0, breakdown)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, models.NewRentalTransitionError(rental.Status, models.RentalStatusRefunded)
+	}
+
+	return tx.Rentals.GetByID(ctx, rental.ID)
+}
+
+// pausesFrom returns the closed paused segments as pricing pauses.
+func pausesFrom(segments []models.RentalSegment) []pricing.Pause {
+	pauses := []pricing.Pause{}
+	for _, segment := range segments {
+		if segment.State != models.RentalStatusPaused || segment.EndedAt == nil {
+			continue
+		}
+		pauses = append(pauses, pricing.Pause{Start: segment.StartedAt, End: *segment.EndedAt})
+	}
+	return pauses
+}
+
+// pricingPolicyFor returns the rule-based policy of the bike's price plan, or
+// plain per-minute pricing at the bike's rate when it has no plan.
+func pricingPolicyFor(ctx context.Context, tx *repositories.Tx, bike *models.Bike, pausedPricePerMinute float64) (pricing.PricingPolicy, error) {
+	if bike.PricePlanID == nil {
+		return pricing.NewPerMinutePolicy(bike.PricePerMinute, pausedPricePerMinute), nil
+	}
+
+	plan, err := tx.PricePlans.GetByID(ctx, *bike.PricePlanID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pricing.NewRulePolicy(plan, bike.PricePerMinute, pausedPricePerMinute), nil
+}
@@ -0,0 +1,402 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/database"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/pricing"
+	"github.com/Nimirandad/bike-rental-service/internal/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+var allRentalStatuses = []models.RentalStatus{
+	models.RentalStatusRunning,
+	models.RentalStatusPaused,
+	models.RentalStatusEnded,
+	models.RentalStatusCancelled,
+	models.RentalStatusRefunded,
+}
+
+// insertTestRental stores a rental of user 1 in status on a new bike priced
+// at 0.5 per minute. The rental started 9.5 minutes ago, so it bills 10
+// minutes; paused rentals have been paused for the last 4.5 of them. Ended
+// and refunded rentals were charged 5.0, cancelled ones nothing.
+func insertTestRental(t *testing.T, db *database.DB, status models.RentalStatus) (rentalID, bikeID int) {
+	t.Helper()
+
+	final := status != models.RentalStatusRunning && status != models.RentalStatusPaused
+	bikeID = insertTestBike(t, db, final, 40.416775, -3.703790, 0.5)
+
+	start := time.Now().UTC().Add(-9*time.Minute - 30*time.Second).Truncate(time.Second)
+	var endTime, duration, cost, breakdown interface{}
+	switch status {
+	case models.RentalStatusEnded, models.RentalStatusRefunded:
+		endTime, duration, cost = start.Add(9*time.Minute), 10, 5.0
+		breakdown = `[{"code":"time","description":"Ride time","quantity":10,"unit_price":0.5,"amount":5}]`
+	case models.RentalStatusCancelled:
+		endTime, duration, cost = start.Add(9*time.Minute), 10, 0.0
+	}
+
+	result, err := db.Exec(
+		`INSERT INTO rentals (user_id, bike_id, status, start_time, end_time, start_latitude, start_longitude,
+		duration_minutes, cost, cost_breakdown) VALUES (1, ?, ?, ?, ?, 40.416775, -3.703790, ?, ?, ?)`,
+		bikeID, status, start, endTime, duration, cost, breakdown,
+	)
+	if err != nil {
+		t.Fatalf("failed to insert rental: %v", err)
+	}
+	id, _ := result.LastInsertId()
+	rentalID = int(id)
+
+	switch status {
+	case models.RentalStatusRunning:
+		_, err = db.Exec("INSERT INTO rental_segments (rental_id, state, started_at) VALUES (?, 'running', ?)", rentalID, start)
+	case models.RentalStatusPaused:
+		_, err = db.Exec(
+			`INSERT INTO rental_segments (rental_id, state, started_at, ended_at) VALUES
+			(?, 'running', ?, ?), (?, 'paused', ?, NULL)`,
+			rentalID, start, start.Add(5*time.Minute), rentalID, start.Add(5*time.Minute),
+		)
+	default:
+		_, err = db.Exec(
+			"INSERT INTO rental_segments (rental_id, state, started_at, ended_at) VALUES (?, 'running', ?, ?)",
+			rentalID, start, start.Add(9*time.Minute),
+		)
+	}
+	if err != nil {
+		t.Fatalf("failed to insert rental segments: %v", err)
+	}
+
+	return rentalID, bikeID
+}
+
+// loadTestRental returns the stored rental together with its segments.
+func loadTestRental(t *testing.T, db *database.DB, rentalID int) *models.Rental {
+	t.Helper()
+
+	rental, err := repositories.NewRentalRepository(db).GetByID(t.Context(), rentalID)
+	if err != nil {
+		t.Fatalf("failed to load rental: %v", err)
+	}
+	rental.Segments, err = repositories.NewRentalSegmentRepository(db).GetByRental(t.Context(), rentalID)
+	if err != nil {
+		t.Fatalf("failed to load rental segments: %v", err)
+	}
+	return rental
+}
+
+// runInTx runs fn in a transaction the way the services do, so refused
+// transitions roll back.
+func runInTx(t *testing.T, db *database.DB, fn func(ctx context.Context, tx *repositories.Tx) (*models.Rental, error)) (*models.Rental, error) {
+	t.Helper()
+
+	var rental *models.Rental
+	err := repositories.NewUnitOfWork(db).WithTx(t.Context(), func(tx *repositories.Tx) error {
+		var err error
+		rental, err = fn(t.Context(), tx)
+		return err
+	})
+	return rental, err
+}
+
+// transitionEffects is the state a rental is left in after a transition.
+type transitionEffects struct {
+	bikeAvailable bool
+	ended         bool
+	cost          *float64
+	segments      int
+	openSegment   models.RentalStatus
+}
+
+func costOf(amount float64) *float64 {
+	return &amount
+}
+
+func assertTransitionEffects(t *testing.T, db *database.DB, bikeID int, rental *models.Rental, want transitionEffects) {
+	t.Helper()
+
+	assert.Equal(t, want.bikeAvailable, bikeIsAvailable(t, db, bikeID))
+	assert.Equal(t, want.ended, !rental.EndTime.IsZero())
+	if want.cost == nil {
+		assert.Nil(t, rental.Cost)
+	} else if assert.NotNil(t, rental.Cost) {
+		assert.InDelta(t, *want.cost, *rental.Cost, 0.001)
+	}
+
+	assert.Len(t, rental.Segments, want.segments)
+	var open []models.RentalStatus
+	for _, segment := range rental.Segments {
+		if segment.EndedAt == nil {
+			open = append(open, segment.State)
+		}
+	}
+	if want.openSegment == "" {
+		assert.Empty(t, open)
+	} else {
+		assert.Equal(t, []models.RentalStatus{want.openSegment}, open)
+	}
+}
+
+func TestTransitionRental(t *testing.T) {
+	allowed := []struct {
+		from models.RentalStatus
+		to   models.RentalStatus
+		want transitionEffects
+	}{
+		{models.RentalStatusRunning, models.RentalStatusPaused, transitionEffects{segments: 2, openSegment: models.RentalStatusPaused}},
+		{models.RentalStatusRunning, models.RentalStatusEnded, transitionEffects{bikeAvailable: true, ended: true, cost: costOf(5.0), segments: 1}},
+		{models.RentalStatusRunning, models.RentalStatusCancelled, transitionEffects{bikeAvailable: true, ended: true, cost: costOf(0), segments: 1}},
+		{models.RentalStatusPaused, models.RentalStatusRunning, transitionEffects{segments: 3, openSegment: models.RentalStatusRunning}},
+		// 5 minutes riding at 0.5 plus 5 paused at 0.1.
+		{models.RentalStatusPaused, models.RentalStatusEnded, transitionEffects{bikeAvailable: true, ended: true, cost: costOf(3.0), segments: 2}},
+		{models.RentalStatusPaused, models.RentalStatusCancelled, transitionEffects{bikeAvailable: true, ended: true, cost: costOf(0), segments: 2}},
+		{models.RentalStatusEnded, models.RentalStatusRefunded, transitionEffects{bikeAvailable: true, ended: true, cost: costOf(0), segments: 1}},
+	}
+
+	isAllowed := map[[2]models.RentalStatus]bool{}
+	for _, tc := range allowed {
+		isAllowed[[2]models.RentalStatus{tc.from, tc.to}] = true
+
+		t.Run(string(tc.from)+" to "+string(tc.to), func(t *testing.T) {
+			db := newTestDB(t)
+			rentalID, bikeID := insertTestRental(t, db, tc.from)
+			before := loadTestRental(t, db, rentalID)
+
+			rental, err := runInTx(t, db, func(ctx context.Context, tx *repositories.Tx) (*models.Rental, error) {
+				return transitionRental(ctx, tx, before, tc.to, 0.1)
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.to, rental.Status)
+			stored := loadTestRental(t, db, rentalID)
+			assert.Equal(t, tc.to, stored.Status)
+			assertTransitionEffects(t, db, bikeID, stored, tc.want)
+			if tc.to == models.RentalStatusRefunded {
+				assert.Equal(t, before.EndTime, stored.EndTime)
+			}
+		})
+	}
+
+	for _, from := range allRentalStatuses {
+		for _, to := range append(allRentalStatuses, "banana") {
+			if isAllowed[[2]models.RentalStatus{from, to}] {
+				continue
+			}
+
+			t.Run(string(from)+" to "+string(to)+" is refused", func(t *testing.T) {
+				db := newTestDB(t)
+				rentalID, bikeID := insertTestRental(t, db, from)
+				before := loadTestRental(t, db, rentalID)
+				available := bikeIsAvailable(t, db, bikeID)
+
+				rental, err := runInTx(t, db, func(ctx context.Context, tx *repositories.Tx) (*models.Rental, error) {
+					return transitionRental(ctx, tx, before, to, 0.1)
+				})
+
+				assert.Nil(t, rental)
+				assert.ErrorIs(t, err, constants.ErrInvalidRentalTransition)
+				var transitionErr *models.RentalTransitionError
+				if assert.ErrorAs(t, err, &transitionErr) {
+					assert.Equal(t, from, transitionErr.From)
+					assert.Equal(t, to, transitionErr.To)
+				}
+
+				stored := loadTestRental(t, db, rentalID)
+				assert.Equal(t, before.Status, stored.Status)
+				assert.Equal(t, before.EndTime, stored.EndTime)
+				assert.Equal(t, before.Cost, stored.Cost)
+				assert.Equal(t, before.Segments, stored.Segments)
+				assert.Equal(t, available, bikeIsAvailable(t, db, bikeID))
+			})
+		}
+	}
+}
+
+func TestChangeSegment(t *testing.T) {
+	tests := []struct {
+		name    string
+		stored  models.RentalStatus
+		claimed models.RentalStatus
+		to      models.RentalStatus
+		wantErr bool
+		want    transitionEffects
+	}{
+		{
+			name:    "Pause a running rental",
+			stored:  models.RentalStatusRunning,
+			claimed: models.RentalStatusRunning,
+			to:      models.RentalStatusPaused,
+			want:    transitionEffects{segments: 2, openSegment: models.RentalStatusPaused},
+		},
+		{
+			name:    "Resume a paused rental",
+			stored:  models.RentalStatusPaused,
+			claimed: models.RentalStatusPaused,
+			to:      models.RentalStatusRunning,
+			want:    transitionEffects{segments: 3, openSegment: models.RentalStatusRunning},
+		},
+		{
+			name:    "Rental paused concurrently",
+			stored:  models.RentalStatusPaused,
+			claimed: models.RentalStatusRunning,
+			to:      models.RentalStatusPaused,
+			wantErr: true,
+		},
+		{
+			name:    "Rental ended concurrently",
+			stored:  models.RentalStatusEnded,
+			claimed: models.RentalStatusPaused,
+			to:      models.RentalStatusRunning,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db := newTestDB(t)
+			rentalID, bikeID := insertTestRental(t, db, tc.stored)
+			before := loadTestRental(t, db, rentalID)
+			claimed := *before
+			claimed.Status = tc.claimed
+
+			rental, err := runInTx(t, db, func(ctx context.Context, tx *repositories.Tx) (*models.Rental, error) {
+				return changeSegment(ctx, tx, &claimed, tc.to)
+			})
+
+			stored := loadTestRental(t, db, rentalID)
+			if tc.wantErr {
+				assert.Nil(t, rental)
+				var transitionErr *models.RentalTransitionError
+				assert.ErrorAs(t, err, &transitionErr)
+				assert.Equal(t, tc.stored, stored.Status)
+				assert.Equal(t, before.Segments, stored.Segments)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.to, rental.Status)
+			assert.Equal(t, stored.Segments, rental.Segments)
+			assertTransitionEffects(t, db, bikeID, stored, tc.want)
+
+			// The closed and the opened segment meet at the same instant.
+			last := stored.Segments[len(stored.Segments)-1]
+			previous := stored.Segments[len(stored.Segments)-2]
+			assert.Equal(t, last.StartedAt, *previous.EndedAt)
+		})
+	}
+}
+
+func TestCloseRental(t *testing.T) {
+	endLat, endLong := 40.420000, -3.700000
+	fine := pricing.Fee{Code: pricing.LineItemNoParking, Description: "No parking fine", Amount: 2.0}
+
+	tests := []struct {
+		name     string
+		stored   models.RentalStatus
+		claimed  models.RentalStatus
+		status   models.RentalStatus
+		wantErr  error
+		want     transitionEffects
+		wantCode []string
+	}{
+		{
+			name:     "End a running rental",
+			stored:   models.RentalStatusRunning,
+			status:   models.RentalStatusEnded,
+			want:     transitionEffects{bikeAvailable: true, ended: true, cost: costOf(7.0), segments: 1},
+			wantCode: []string{pricing.LineItemTime, pricing.LineItemNoParking},
+		},
+		{
+			name:     "End a paused rental",
+			stored:   models.RentalStatusPaused,
+			status:   models.RentalStatusEnded,
+			want:     transitionEffects{bikeAvailable: true, ended: true, cost: costOf(5.0), segments: 2},
+			wantCode: []string{pricing.LineItemTime, pricing.LineItemPaused, pricing.LineItemNoParking},
+		},
+		{
+			name:   "Cancel a running rental",
+			stored: models.RentalStatusRunning,
+			status: models.RentalStatusCancelled,
+			want:   transitionEffects{bikeAvailable: true, ended: true, cost: costOf(0), segments: 1},
+		},
+		{
+			name:   "Cancel a paused rental",
+			stored: models.RentalStatusPaused,
+			status: models.RentalStatusCancelled,
+			want:   transitionEffects{bikeAvailable: true, ended: true, cost: costOf(0), segments: 2},
+		},
+		{
+			name:    "End an ended rental",
+			stored:  models.RentalStatusEnded,
+			status:  models.RentalStatusEnded,
+			wantErr: constants.ErrInvalidRentalTransition,
+		},
+		{
+			name:    "Cancel a refunded rental",
+			stored:  models.RentalStatusRefunded,
+			status:  models.RentalStatusCancelled,
+			wantErr: constants.ErrInvalidRentalTransition,
+		},
+		{
+			name:    "Close a running rental as refunded",
+			stored:  models.RentalStatusRunning,
+			status:  models.RentalStatusRefunded,
+			wantErr: constants.ErrInvalidRentalTransition,
+		},
+		{
+			name:    "Rental cancelled concurrently",
+			stored:  models.RentalStatusCancelled,
+			claimed: models.RentalStatusRunning,
+			status:  models.RentalStatusEnded,
+			wantErr: constants.ErrNoActiveRental,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db := newTestDB(t)
+			rentalID, bikeID := insertTestRental(t, db, tc.stored)
+			before := loadTestRental(t, db, rentalID)
+			available := bikeIsAvailable(t, db, bikeID)
+			claimed := *before
+			if tc.claimed != "" {
+				claimed.Status = tc.claimed
+			}
+
+			rental, err := runInTx(t, db, func(ctx context.Context, tx *repositories.Tx) (*models.Rental, error) {
+				return closeRental(ctx, tx, &claimed, tc.status, &endLat, &endLong, 0.1, []pricing.Fee{fine})
+			})
+
+			stored := loadTestRental(t, db, rentalID)
+			if tc.wantErr != nil {
+				assert.Nil(t, rental)
+				assert.ErrorIs(t, err, tc.wantErr)
+				assert.Equal(t, before.Status, stored.Status)
+				assert.Equal(t, before.EndTime, stored.EndTime)
+				assert.Equal(t, before.Cost, stored.Cost)
+				assert.Equal(t, before.Segments, stored.Segments)
+				assert.Equal(t, available, bikeIsAvailable(t, db, bikeID))
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.status, stored.Status)
+			assert.Equal(t, stored.Segments, rental.Segments)
+			assertTransitionEffects(t, db, bikeID, stored, tc.want)
+			assert.Equal(t, 10, *stored.DurationMinutes)
+			assert.Equal(t, endLat, stored.EndLatitude)
+			assert.Equal(t, endLong, stored.EndLongitude)
+			assert.Greater(t, *stored.DistanceKm, 0.0)
+
+			var codes []string
+			for _, item := range stored.CostBreakdown {
+				codes = append(codes, item.Code)
+			}
+			assert.Equal(t, tc.wantCode, codes)
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/logger"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/repositories"
+)
+
+type ReservationService struct {
+	rentalRepo RentalRepository
+	uow        UnitOfWork
+	holdFor    time.Duration
+	now        func() time.Time
+}
+
+// NewReservationService creates a service that holds bikes for holdMinutes.
+func NewReservationService(rentalRepo *repositories.RentalRepository, uow *repositories.UnitOfWork, holdMinutes int) *ReservationService {
+	return &ReservationService{
+		rentalRepo: rentalRepo,
+		uow:        uow,
+		holdFor:    time.Duration(holdMinutes) * time.Minute,
+		now:        utcNow,
+	}
+}
+
+// ReserveBike holds the bike for the user. The bike is marked unavailable so
+// it disappears from the available list, and only the same user can start a
+// rental on it until the reservation is cancelled or expires.
+func (s *ReservationService) ReserveBike(ctx context.Context, userID, bikeID int) (*models.Reservation, error) {
+	hasActive, err := s.rentalRepo.HasActiveRental(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if hasActive {
+		return nil, constants.ErrUserHasActiveRental
+	}
+
+	now := s.now()
+	var reservation *models.Reservation
+	err = s.uow.WithTx(ctx, func(tx *repositories.Tx) error {
+		existing, err := tx.Reservations.GetActiveByUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		active, err := releaseIfExpired(ctx, tx, existing, now)
+		if err != nil {
+			return err
+		}
+		if active != nil {
+			return constants.ErrUserHasActiveReservation
+		}
+
+		onBike, err := tx.Reservations.GetActiveByBike(ctx, bikeID)
+		if err != nil {
+			return err
+		}
+		onBike, err = releaseIfExpired(ctx, tx, onBike, now)
+		if err != nil {
+			return err
+		}
+
+		claimed, err := tx.Bikes.ClaimAvailable(ctx, bikeID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Bikes.GetByID(ctx, bikeID)
+		if errors.Is(err, constants.ErrBikeNotFound) {
+			return constants.ErrBikeNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if !claimed {
+			if onBike != nil {
+				return constants.ErrBikeReserved
+			}
+			return constants.ErrBikeNotAvailable
+		}
+
+		reservation, err = tx.Reservations.Create(ctx, userID, bikeID, now.Add(s.holdFor))
+		switch {
+		case errors.Is(err, constants.ErrUserHasActiveReservation):
+			return constants.ErrUserHasActiveReservation
+		case errors.Is(err, constants.ErrBikeReserved):
+			return constants.ErrBikeReserved
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+// CancelReservation releases the user's active reservation and makes the bike
+// available again.
+func (s *ReservationService) CancelReservation(ctx context.Context, userID int) (*models.Reservation, error) {
+	var reservation *models.Reservation
+	err := s.uow.WithTx(ctx, func(tx *repositories.Tx) error {
+		active, err := tx.Reservations.GetActiveByUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if active == nil {
+			return constants.ErrNoActiveReservation
+		}
+
+		closed, err := tx.Reservations.Close(ctx, active.ID, models.ReservationStatusCancelled)
+		if err != nil {
+			return err
+		}
+		if !closed {
+			return constants.ErrNoActiveReservation
+		}
+
+		if err := tx.Bikes.UpdateAvailability(ctx, active.BikeID, true); err != nil {
+			return err
+		}
+
+		reservation, err = tx.Reservations.GetByID(ctx, active.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+// ExpireReservations releases every active reservation past its expiry and
+// returns how many were expired.
+func (s *ReservationService) ExpireReservations(ctx context.Context) (int, error) {
+	now := s.now()
+	expired := 0
+	err := s.uow.WithTx(ctx, func(tx *repositories.Tx) error {
+		due, err := tx.Reservations.GetExpired(ctx, now)
+		if err != nil {
+			return err
+		}
+
+		for _, reservation := range due {
+			active, err := releaseIfExpired(ctx, tx, reservation, now)
+			if err != nil {
+				return err
+			}
+			if active == nil {
+				expired++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return expired, nil
+}
+
+// RunExpiryWorker calls ExpireReservations every interval until ctx is done.
+func (s *ReservationService) RunExpiryWorker(ctx context.Context, interval time.Duration) {
+	log := logger.Get()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Info().Dur("interval", interval).Msg("Reservation expiry worker started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Reservation expiry worker stopped")
+			return
+		case <-ticker.C:
+			expired, err := s.ExpireReservations(ctx)
+			if err != nil {
+				log.Error().Err(err).Msg("Error expiring reservations")
+				continue
+			}
+			if expired > 0 {
+				log.Info().Int("expired", expired).Msg("Expired reservations released")
+			}
+		}
+	}
+}
+
+// releaseIfExpired expires reservation and frees its bike when it is past its
+// expiry. It returns the reservation if it is still active, or nil otherwise.
+func releaseIfExpired(ctx context.Context, tx *repositories.Tx, reservation *models.Reservation, now time.Time) (*models.Reservation, error) {
+	if reservation == nil || reservation.ExpiresAt.After(now) {
+		return reservation, nil
+	}
+
+	closed, err := tx.Reservations.Close(ctx, reservation.ID, models.ReservationStatusExpired)
+	if err != nil {
+		return nil, err
+	}
+	if closed {
+		if err := tx.Bikes.UpdateAvailability(ctx, reservation.BikeID, true); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
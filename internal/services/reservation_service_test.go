@@ -0,0 +1,243 @@
+package services
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/database"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestReservationService(db *database.DB) *ReservationService {
+	return NewReservationService(repositories.NewRentalRepository(db), repositories.NewUnitOfWork(db), 10)
+}
+
+func reservationStatus(t *testing.T, db *database.DB, reservationID int) string {
+	t.Helper()
+
+	reservation, err := repositories.NewReservationRepository(db).GetByID(t.Context(), reservationID)
+	if err != nil {
+		t.Fatalf("failed to load reservation: %v", err)
+	}
+	return reservation.Status
+}
+
+// TestReservationService_ReserveBike_Success tests that a reserved bike is
+// held and hidden from the available list
+func TestReservationService_ReserveBike_Success(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	service := newTestReservationService(db)
+
+	reservation, err := service.ReserveBike(t.Context(), 1, bikeID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReservationStatusActive, reservation.Status)
+	assert.Equal(t, bikeID, reservation.BikeID)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), reservation.ExpiresAt, 5*time.Second)
+	assert.False(t, bikeIsAvailable(t, db, bikeID))
+
+	available, err := repositories.NewBikeRepository(db).GetAvailable(t.Context(), models.BikeFilter{}, 1, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, available)
+}
+
+// TestReservationService_ReserveBike_AlreadyReserved tests that a second user
+// gets the reserved error
+func TestReservationService_ReserveBike_AlreadyReserved(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	service := newTestReservationService(db)
+
+	_, err := service.ReserveBike(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	reservation, err := service.ReserveBike(t.Context(), 2, bikeID)
+
+	assert.Equal(t, constants.ErrBikeReserved, err)
+	assert.Nil(t, reservation)
+}
+
+// TestReservationService_ReserveBike_OnePerUser tests that a user can hold
+// only one bike at a time
+func TestReservationService_ReserveBike_OnePerUser(t *testing.T) {
+	db := newTestDB(t)
+	firstBike := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	secondBike := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	service := newTestReservationService(db)
+
+	_, err := service.ReserveBike(t.Context(), 1, firstBike)
+	assert.NoError(t, err)
+
+	_, err = service.ReserveBike(t.Context(), 1, secondBike)
+
+	assert.Equal(t, constants.ErrUserHasActiveReservation, err)
+	assert.True(t, bikeIsAvailable(t, db, secondBike))
+}
+
+// TestReservationService_ReserveBike_WhileRiding tests that a rider with an
+// active rental cannot reserve
+func TestReservationService_ReserveBike_WhileRiding(t *testing.T) {
+	db := newTestDB(t)
+	rentedBike := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	otherBike := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+
+	_, err := newTestRentalService(db).StartRental(t.Context(), 1, rentedBike)
+	assert.NoError(t, err)
+
+	_, err = newTestReservationService(db).ReserveBike(t.Context(), 1, otherBike)
+
+	assert.Equal(t, constants.ErrUserHasActiveRental, err)
+}
+
+// TestReservationService_ReserveBike_NotFound tests reserving a missing bike
+func TestReservationService_ReserveBike_NotFound(t *testing.T) {
+	db := newTestDB(t)
+
+	_, err := newTestReservationService(db).ReserveBike(t.Context(), 1, 999)
+
+	assert.Equal(t, constants.ErrBikeNotFound, err)
+}
+
+// TestReservationService_StartRental_ConvertsOwnReservation tests that the
+// reserving user can start a rental on the held bike
+func TestReservationService_StartRental_ConvertsOwnReservation(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	reservationService := newTestReservationService(db)
+	rentalService := newTestRentalService(db)
+
+	reservation, err := reservationService.ReserveBike(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	_, err = rentalService.StartRental(t.Context(), 2, bikeID)
+	assert.Equal(t, constants.ErrBikeReserved, err)
+
+	rental, err := rentalService.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+	assert.Equal(t, bikeID, rental.BikeID)
+
+	converted, err := repositories.NewReservationRepository(db).GetByID(t.Context(), reservation.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReservationStatusConverted, converted.Status)
+	assert.Equal(t, rental.ID, *converted.RentalID)
+	assert.False(t, bikeIsAvailable(t, db, bikeID))
+}
+
+// TestReservationService_StartRental_ReleasesOtherReservation tests that
+// renting a different bike gives up the held one
+func TestReservationService_StartRental_ReleasesOtherReservation(t *testing.T) {
+	db := newTestDB(t)
+	heldBike := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	otherBike := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+
+	reservation, err := newTestReservationService(db).ReserveBike(t.Context(), 1, heldBike)
+	assert.NoError(t, err)
+
+	_, err = newTestRentalService(db).StartRental(t.Context(), 1, otherBike)
+	assert.NoError(t, err)
+
+	assert.Equal(t, models.ReservationStatusCancelled, reservationStatus(t, db, reservation.ID))
+	assert.True(t, bikeIsAvailable(t, db, heldBike))
+}
+
+// TestReservationService_CancelReservation tests that cancelling frees the bike
+func TestReservationService_CancelReservation(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	service := newTestReservationService(db)
+
+	_, err := service.ReserveBike(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	reservation, err := service.CancelReservation(t.Context(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReservationStatusCancelled, reservation.Status)
+	assert.True(t, bikeIsAvailable(t, db, bikeID))
+
+	_, err = service.CancelReservation(t.Context(), 1)
+	assert.Equal(t, constants.ErrNoActiveReservation, err)
+}
+
+// TestReservationService_ExpireReservations tests that the expiry sweep
+// releases only reservations past their expiry
+func TestReservationService_ExpireReservations(t *testing.T) {
+	db := newTestDB(t)
+	expiringBike := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	freshBike := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	service := newTestReservationService(db)
+
+	expiring, err := service.ReserveBike(t.Context(), 1, expiringBike)
+	assert.NoError(t, err)
+
+	service.now = func() time.Time { return utcNow().Add(5 * time.Minute) }
+	fresh, err := service.ReserveBike(t.Context(), 2, freshBike)
+	assert.NoError(t, err)
+
+	service.now = func() time.Time { return utcNow().Add(11 * time.Minute) }
+	expired, err := service.ExpireReservations(t.Context())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, expired)
+	assert.Equal(t, models.ReservationStatusExpired, reservationStatus(t, db, expiring.ID))
+	assert.Equal(t, models.ReservationStatusActive, reservationStatus(t, db, fresh.ID))
+	assert.True(t, bikeIsAvailable(t, db, expiringBike))
+	assert.False(t, bikeIsAvailable(t, db, freshBike))
+}
+
+// TestReservationService_StartRental_AfterUnsweptExpiry tests that an expired
+// reservation does not block other riders before the worker runs
+func TestReservationService_StartRental_AfterUnsweptExpiry(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	service := newTestReservationService(db)
+
+	service.now = func() time.Time { return utcNow().Add(-time.Hour) }
+	reservation, err := service.ReserveBike(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	rental, err := newTestRentalService(db).StartRental(t.Context(), 2, bikeID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, rental.UserID)
+	assert.Equal(t, models.ReservationStatusExpired, reservationStatus(t, db, reservation.ID))
+}
+
+// TestReservationService_ReserveBike_Concurrent tests that exactly one of many
+// parallel reservations on the same bike succeeds
+func TestReservationService_ReserveBike_Concurrent(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	service := newTestReservationService(db)
+
+	const attempts = 50
+
+	var wg sync.WaitGroup
+	errs := make(chan error, attempts)
+	for userID := 1; userID <= attempts; userID++ {
+		wg.Add(1)
+		go func(userID int) {
+			defer wg.Done()
+			_, err := service.ReserveBike(t.Context(), userID, bikeID)
+			errs <- err
+		}(userID)
+	}
+	wg.Wait()
+	close(errs)
+
+	successes := 0
+	for err := range errs {
+		if err == nil {
+			successes++
+			continue
+		}
+		assert.Equal(t, constants.ErrBikeReserved, err)
+	}
+
+	assert.Equal(t, 1, successes)
+}
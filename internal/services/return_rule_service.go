@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/Nimirandad/bike-rental-service/internal/apperrors"
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/repositories"
+	"github.com/Nimirandad/bike-rental-service/internal/utils"
+)
+
+// ReturnRuleRepository stores the return distance rule set by an admin. It
+// is satisfied by *repositories.ReturnRuleRepository, both on the pool and
+// inside a transaction.
+type ReturnRuleRepository interface {
+	Get(ctx context.Context) (*models.ReturnDistanceRule, error)
+	Save(ctx context.Context, rule *models.ReturnDistanceRule) (*models.ReturnDistanceRule, error)
+}
+
+type ReturnRuleService struct {
+	returnRuleRepo ReturnRuleRepository
+	defaultRule    models.ReturnDistanceRule
+}
+
+// NewReturnRuleService builds the service. defaultRule applies until an
+// admin sets a rule.
+func NewReturnRuleService(returnRuleRepo *repositories.ReturnRuleRepository, defaultRule models.ReturnDistanceRule) *ReturnRuleService {
+	return &ReturnRuleService{
+		returnRuleRepo: returnRuleRepo,
+		defaultRule:    defaultRule,
+	}
+}
+
+// DefaultReturnRule builds the rule configured at startup. An unknown mode
+// falls back to measuring from the start of the rental.
+func DefaultReturnRule(mode string, maxDistanceKm float64) models.ReturnDistanceRule {
+	rule := models.ReturnDistanceRule{Mode: models.ReturnDistanceMode(mode), MaxDistanceKm: maxDistanceKm}
+	if !rule.Mode.IsValid() {
+		rule.Mode = models.ReturnDistanceFromStart
+	}
+	return rule
+}
+
+// GetReturnRule returns the rule in effect: the one set by an admin, or the
+// configured default.
+func (s *ReturnRuleService) GetReturnRule(ctx context.Context) (*models.ReturnDistanceRule, error) {
+	return currentReturnRule(ctx, s.returnRuleRepo, s.defaultRule)
+}
+
+// UpdateReturnRule replaces the rule in effect. Rentals ending after it
+// returns are checked against the new rule.
+func (s *ReturnRuleService) UpdateReturnRule(ctx context.Context, rule *models.ReturnDistanceRule) (*models.ReturnDistanceRule, error) {
+	if rule.Mode == models.ReturnDistanceNone {
+		rule.MaxDistanceKm = 0
+	}
+	return s.returnRuleRepo.Save(ctx, rule)
+}
+
+func currentReturnRule(ctx context.Context, repo ReturnRuleRepository, defaultRule models.ReturnDistanceRule) (*models.ReturnDistanceRule, error) {
+	rule, err := repo.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil {
+		return &defaultRule, nil
+	}
+	return rule, nil
+}
+
+// checkReturnDistance rejects a return farther from the rule's reference
+// point than its limit. The error message states the limit.
+func checkReturnDistance(ctx context.Context, tx *repositories.Tx, rule *models.ReturnDistanceRule, rental *models.Rental, latitude, longitude float64) error {
+	limit := strconv.FormatFloat(rule.MaxDistanceKm, 'f', -1, 64)
+
+	switch rule.Mode {
+	case models.ReturnDistanceFromStart:
+		distance := utils.HaversineDistance(rental.StartLatitude, rental.StartLongitude, latitude, longitude)
+		if distance > rule.MaxDistanceKm {
+			return apperrors.WithMessage(constants.ErrEndLocationTooFar,
+				fmt.Sprintf("end location must be within %s km of the start location", limit))
+		}
+	case models.ReturnDistanceFromStation:
+		distance, found, err := nearestStationDistance(ctx, tx, latitude, longitude, rule.MaxDistanceKm)
+		if err != nil {
+			return err
+		}
+		if !found || distance > rule.MaxDistanceKm {
+			return apperrors.WithMessage(constants.ErrEndLocationTooFar,
+				fmt.Sprintf("end location must be within %s km of a station", limit))
+		}
+	}
+
+	return nil
+}
+
+// nearestStationDistance returns the distance in km from the point to the
+// closest station within radiusKm, counting a point inside a station's area
+// as 0. found is false when there is none.
+func nearestStationDistance(ctx context.Context, tx *repositories.Tx, latitude, longitude, radiusKm float64) (distance float64, found bool, err error) {
+	minLat, maxLat, minLong, maxLong := utils.BoundingBox(latitude, longitude, radiusKm+constants.MaxStationRadiusMeters/1000)
+
+	stations, err := tx.Stations.GetInBounds(ctx, minLat, maxLat, minLong, maxLong)
+	if err != nil {
+		return 0, false, err
+	}
+
+	for _, station := range stations {
+		d := utils.HaversineDistance(station.Latitude, station.Longitude, latitude, longitude)
+		if utils.StationContains(station, latitude, longitude) {
+			d = 0
+		}
+		if !found || d < distance {
+			distance, found = d, true
+		}
+	}
+
+	return distance, found, nil
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultReturnRule(t *testing.T) {
+	rule := DefaultReturnRule("from_station", 0.5)
+	assert.Equal(t, models.ReturnDistanceFromStation, rule.Mode)
+	assert.Equal(t, 0.5, rule.MaxDistanceKm)
+
+	rule = DefaultReturnRule("anywhere", 3)
+	assert.Equal(t, models.ReturnDistanceFromStart, rule.Mode)
+	assert.Equal(t, 3.0, rule.MaxDistanceKm)
+}
+
+func TestReturnRuleService_GetAndUpdate(t *testing.T) {
+	db := newTestDB(t)
+	service := NewReturnRuleService(repositories.NewReturnRuleRepository(db), testReturnRule)
+
+	rule, err := service.GetReturnRule(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReturnDistanceFromStart, rule.Mode)
+	assert.Equal(t, 5.0, rule.MaxDistanceKm)
+	assert.Nil(t, rule.UpdatedAt)
+
+	rule, err = service.UpdateReturnRule(t.Context(), &models.ReturnDistanceRule{Mode: models.ReturnDistanceFromStation, MaxDistanceKm: 0.5})
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReturnDistanceFromStation, rule.Mode)
+	assert.NotNil(t, rule.UpdatedAt)
+
+	rule, err = service.UpdateReturnRule(t.Context(), &models.ReturnDistanceRule{Mode: models.ReturnDistanceNone, MaxDistanceKm: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, rule.MaxDistanceKm)
+
+	rule, err = service.GetReturnRule(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReturnDistanceNone, rule.Mode)
+}
+
+func TestRentalService_EndRental_FromStationRule(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	insertTestStation(t, db, "Sol", 40.420000, -3.700000, 50, 10)
+	service := newTestRentalService(db)
+	rules := NewReturnRuleService(repositories.NewReturnRuleRepository(db), testReturnRule)
+
+	_, err := rules.UpdateReturnRule(t.Context(), &models.ReturnDistanceRule{Mode: models.ReturnDistanceFromStation, MaxDistanceKm: 0.5})
+	assert.NoError(t, err)
+
+	_, err = service.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	// About 1.1 km from the station, though close to the start.
+	rental, err := service.EndRental(t.Context(), 1, 40.410000, -3.700000)
+	assert.ErrorIs(t, err, constants.ErrEndLocationTooFar)
+	assert.EqualError(t, err, "end location must be within 0.5 km of a station")
+	assert.Nil(t, rental)
+
+	// About 330 m from the station, outside its area.
+	rental, err = service.EndRental(t.Context(), 1, 40.423000, -3.700000)
+	assert.NoError(t, err)
+	assert.True(t, bikeIsAvailable(t, db, bikeID))
+}
+
+func TestRentalService_EndRental_FromStationRuleWithoutStations(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	rule := models.ReturnDistanceRule{Mode: models.ReturnDistanceFromStation, MaxDistanceKm: 1}
+	service := NewRentalService(repositories.NewRentalRepository(db), repositories.NewUnitOfWork(db), 0.1, StationRules{Policy: ReturnFreeFloating}, ZoneRules{}, rule)
+
+	_, err := service.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	_, err = service.EndRental(t.Context(), 1, 40.416775, -3.703790)
+	assert.ErrorIs(t, err, constants.ErrEndLocationTooFar)
+	assert.EqualError(t, err, "end location must be within 1 km of a station")
+}
+
+func TestRentalService_EndRental_NoDistanceRule(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	service := newTestRentalService(db)
+	rules := NewReturnRuleService(repositories.NewReturnRuleRepository(db), testReturnRule)
+
+	_, err := service.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	_, err = service.EndRental(t.Context(), 1, 48.856614, 2.352222)
+	assert.ErrorIs(t, err, constants.ErrEndLocationTooFar)
+
+	// The rule changed at runtime applies to the same active rental.
+	_, err = rules.UpdateReturnRule(t.Context(), &models.ReturnDistanceRule{Mode: models.ReturnDistanceNone})
+	assert.NoError(t, err)
+
+	rental, err := service.EndRental(t.Context(), 1, 48.856614, 2.352222)
+	assert.NoError(t, err)
+	assert.Equal(t, models.RentalStatusEnded, rental.Status)
+}
+
+func TestRentalService_EndRental_ConfiguredLimit(t *testing.T) {
+	db := newTestDB(t)
+	bikeID := insertTestBike(t, db, true, 40.416775, -3.703790, 0.5)
+	rule := models.ReturnDistanceRule{Mode: models.ReturnDistanceFromStart, MaxDistanceKm: 0.25}
+	service := NewRentalService(repositories.NewRentalRepository(db), repositories.NewUnitOfWork(db), 0.1, StationRules{Policy: ReturnFreeFloating}, ZoneRules{}, rule)
+
+	_, err := service.StartRental(t.Context(), 1, bikeID)
+	assert.NoError(t, err)
+
+	_, err = service.EndRental(t.Context(), 1, 40.420000, -3.700000)
+	assert.EqualError(t, err, "end location must be within 0.25 km of the start location")
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/repositories"
+	"github.com/Nimirandad/bike-rental-service/internal/utils"
+)
+
+type StationRepository interface {
+	Create(ctx context.Context, station *models.Station) (*models.Station, error)
+	GetByID(ctx context.Context, stationID int) (*models.Station, error)
+	GetAll(ctx context.Context, page, limit int) ([]*models.Station, error)
+	CountAll(ctx context.Context) (int, error)
+	Update(ctx context.Context, station *models.Station) (*models.Station, error)
+	Delete(ctx context.Context, stationID int) error
+}
+
+type StationService struct {
+	stationRepo StationRepository
+}
+
+func NewStationService(stationRepo *repositories.StationRepository) *StationService {
+	return &StationService{
+		stationRepo: stationRepo,
+	}
+}
+
+func (s *StationService) CreateStation(ctx context.Context, station *models.Station) (*models.Station, error) {
+	return s.stationRepo.Create(ctx, station)
+}
+
+// GetAllStations returns one page of stations with their current bike
+// counts and free docks.
+func (s *StationService) GetAllStations(ctx context.Context, page, limit int) ([]*models.Station, int, error) {
+	total, err := s.stationRepo.CountAll(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	stations, err := s.stationRepo.GetAll(ctx, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return stations, total, nil
+}
+
+func (s *StationService) GetStationByID(ctx context.Context, stationID int) (*models.Station, error) {
+	station, err := s.stationRepo.GetByID(ctx, stationID)
+	if errors.Is(err, constants.ErrStationNotFound) {
+		return nil, constants.ErrStationNotFound
+	}
+	return station, err
+}
+
+func (s *StationService) UpdateStation(ctx context.Context, station *models.Station) (*models.Station, error) {
+	updated, err := s.stationRepo.Update(ctx, station)
+	if errors.Is(err, constants.ErrStationNotFound) {
+		return nil, constants.ErrStationNotFound
+	}
+	return updated, err
+}
+
+func (s *StationService) DeleteStation(ctx context.Context, stationID int) error {
+	err := s.stationRepo.Delete(ctx, stationID)
+	if errors.Is(err, constants.ErrStationNotFound) {
+		return constants.ErrStationNotFound
+	}
+	return err
+}
+
+// findReturnStation returns the station with a free dock whose area contains
+// the point, preferring the one whose anchor is closest. full reports that
+// the point lies only in stations without free docks.
+func findReturnStation(ctx context.Context, tx *repositories.Tx, latitude, longitude float64) (station *models.Station, full bool, err error) {
+	minLat, maxLat, minLong, maxLong := utils.BoundingBox(latitude, longitude, constants.MaxStationRadiusMeters/1000)
+
+	candidates, err := tx.Stations.GetInBounds(ctx, minLat, maxLat, minLong, maxLong)
+	if err != nil {
+		return nil, false, err
+	}
+
+	bestDistance := 0.0
+	for _, candidate := range candidates {
+		if !utils.StationContains(candidate, latitude, longitude) {
+			continue
+		}
+		if candidate.FreeDocks == 0 {
+			full = true
+			continue
+		}
+
+		distance := utils.HaversineDistance(candidate.Latitude, candidate.Longitude, latitude, longitude)
+		if station == nil || distance < bestDistance {
+			station, bestDistance = candidate, distance
+		}
+	}
+
+	return station, full && station == nil, nil
+}
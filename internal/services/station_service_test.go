@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/database"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockStationRepository struct {
+	CreateFunc   func(station *models.Station) (*models.Station, error)
+	GetByIDFunc  func(stationID int) (*models.Station, error)
+	GetAllFunc   func(page, limit int) ([]*models.Station, error)
+	CountAllFunc func() (int, error)
+	UpdateFunc   func(station *models.Station) (*models.Station, error)
+	DeleteFunc   func(stationID int) error
+}
+
+func (m *MockStationRepository) Create(ctx context.Context, station *models.Station) (*models.Station, error) {
+	return m.CreateFunc(station)
+}
+
+func (m *MockStationRepository) GetByID(ctx context.Context, stationID int) (*models.Station, error) {
+	return m.GetByIDFunc(stationID)
+}
+
+func (m *MockStationRepository) GetAll(ctx context.Context, page, limit int) ([]*models.Station, error) {
+	return m.GetAllFunc(page, limit)
+}
+
+func (m *MockStationRepository) CountAll(ctx context.Context) (int, error) {
+	return m.CountAllFunc()
+}
+
+func (m *MockStationRepository) Update(ctx context.Context, station *models.Station) (*models.Station, error) {
+	return m.UpdateFunc(station)
+}
+
+func (m *MockStationRepository) Delete(ctx context.Context, stationID int) error {
+	return m.DeleteFunc(stationID)
+}
+
+func notFoundStation(stationID int) error {
+	return fmt.Errorf("station with id %d: %w", stationID, constants.ErrStationNotFound)
+}
+
+// insertTestStation adds a radius station and returns its id.
+func insertTestStation(t *testing.T, db *database.DB, name string, latitude, longitude, radiusMeters float64, capacity int) int {
+	t.Helper()
+
+	result, err := db.Exec(
+		"INSERT INTO stations (name, latitude, longitude, radius_meters, capacity) VALUES (?, ?, ?, ?, ?)",
+		name, latitude, longitude, radiusMeters, capacity,
+	)
+	if err != nil {
+		t.Fatalf("failed to insert station: %v", err)
+	}
+
+	id, _ := result.LastInsertId()
+	return int(id)
+}
+
+func TestStationService_GetAllStations_Success(t *testing.T) {
+	mockRepo := &MockStationRepository{
+		CountAllFunc: func() (int, error) {
+			return 2, nil
+		},
+		GetAllFunc: func(page, limit int) ([]*models.Station, error) {
+			assert.Equal(t, 1, page)
+			assert.Equal(t, 20, limit)
+			return []*models.Station{{ID: 1, Name: "Sol"}, {ID: 2, Name: "Retiro"}}, nil
+		},
+	}
+
+	service := &StationService{stationRepo: mockRepo}
+	stations, total, err := service.GetAllStations(t.Context(), 1, 20)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Len(t, stations, 2)
+}
+
+func TestStationService_GetAllStations_CountError(t *testing.T) {
+	mockRepo := &MockStationRepository{
+		CountAllFunc: func() (int, error) {
+			return 0, errors.New("count error")
+		},
+	}
+
+	service := &StationService{stationRepo: mockRepo}
+	stations, total, err := service.GetAllStations(t.Context(), 1, 20)
+
+	assert.EqualError(t, err, "count error")
+	assert.Equal(t, 0, total)
+	assert.Nil(t, stations)
+}
+
+func TestStationService_GetStationByID_NotFound(t *testing.T) {
+	mockRepo := &MockStationRepository{
+		GetByIDFunc: func(stationID int) (*models.Station, error) {
+			return nil, notFoundStation(stationID)
+		},
+	}
+
+	service := &StationService{stationRepo: mockRepo}
+	station, err := service.GetStationByID(t.Context(), 99)
+
+	assert.Equal(t, constants.ErrStationNotFound, err)
+	assert.Nil(t, station)
+}
+
+func TestStationService_UpdateStation_NotFound(t *testing.T) {
+	mockRepo := &MockStationRepository{
+		UpdateFunc: func(station *models.Station) (*models.Station, error) {
+			return nil, notFoundStation(station.ID)
+		},
+	}
+
+	service := &StationService{stationRepo: mockRepo}
+	station, err := service.UpdateStation(t.Context(), &models.Station{ID: 99})
+
+	assert.Equal(t, constants.ErrStationNotFound, err)
+	assert.Nil(t, station)
+}
+
+func TestStationService_DeleteStation(t *testing.T) {
+	t.Run("Not found", func(t *testing.T) {
+		mockRepo := &MockStationRepository{
+			DeleteFunc: func(stationID int) error {
+				return notFoundStation(stationID)
+			},
+		}
+
+		service := &StationService{stationRepo: mockRepo}
+		assert.Equal(t, constants.ErrStationNotFound, service.DeleteStation(t.Context(), 99))
+	})
+
+	t.Run("Bikes parked", func(t *testing.T) {
+		mockRepo := &MockStationRepository{
+			DeleteFunc: func(stationID int) error {
+				return constants.ErrStationInUse
+			},
+		}
+
+		service := &StationService{stationRepo: mockRepo}
+		assert.ErrorIs(t, service.DeleteStation(t.Context(), 1), constants.ErrStationInUse)
+	})
+}
+
+func TestFindReturnStation(t *testing.T) {
+	db := newTestDB(t)
+	uow := repositories.NewUnitOfWork(db)
+
+	fullID := insertTestStation(t, db, "Full", 40.4155, -3.7074, 200, 1)
+	insertTestBike(t, db, true, 40.4155, -3.7074, 0.5)
+	_, err := db.Exec("UPDATE bikes SET station_id = ?", fullID)
+	assert.NoError(t, err)
+
+	t.Run("Only full stations contain the point", func(t *testing.T) {
+		err := uow.WithTx(t.Context(), func(tx *repositories.Tx) error {
+			station, full, err := findReturnStation(t.Context(), tx, 40.4156, -3.7074)
+			assert.Nil(t, station)
+			assert.True(t, full)
+			return err
+		})
+		assert.NoError(t, err)
+	})
+
+	freeID := insertTestStation(t, db, "Free", 40.4160, -3.7074, 200, 5)
+
+	t.Run("Station with a free dock is chosen", func(t *testing.T) {
+		err := uow.WithTx(t.Context(), func(tx *repositories.Tx) error {
+			station, full, err := findReturnStation(t.Context(), tx, 40.4156, -3.7074)
+			if assert.NotNil(t, station) {
+				assert.Equal(t, freeID, station.ID)
+			}
+			assert.False(t, full)
+			return err
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Point outside every station", func(t *testing.T) {
+		err := uow.WithTx(t.Context(), func(tx *repositories.Tx) error {
+			station, full, err := findReturnStation(t.Context(), tx, 40.43, -3.7074)
+			assert.Nil(t, station)
+			assert.False(t, full)
+			return err
+		})
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/logger"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/repositories"
+	"github.com/Nimirandad/bike-rental-service/internal/utils"
+)
+
+type RevokedTokenRepository interface {
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// TokenService issues rider access tokens together with rotating refresh
+// tokens and keeps the revocation list used to log tokens out early.
+type TokenService struct {
+	revokedRepo RevokedTokenRepository
+	uow         UnitOfWork
+	accessTTL   time.Duration
+	refreshTTL  time.Duration
+	now         func() time.Time
+}
+
+func NewTokenService(revokedRepo *repositories.RevokedTokenRepository, uow *repositories.UnitOfWork, accessTTL, refreshTTL time.Duration) *TokenService {
+	return &TokenService{
+		revokedRepo: revokedRepo,
+		uow:         uow,
+		accessTTL:   accessTTL,
+		refreshTTL:  refreshTTL,
+		now:         utcNow,
+	}
+}
+
+// IssueTokens starts a new refresh token family for the user, as on login.
+func (s *TokenService) IssueTokens(ctx context.Context, user *models.User) (*models.TokenPair, error) {
+	familyID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	var pair *models.TokenPair
+	err = s.uow.WithTx(ctx, func(tx *repositories.Tx) error {
+		pair, err = s.issue(ctx, tx, user, familyID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pair, nil
+}
+
+// Refresh exchanges a refresh token for a new pair in the same family. Each
+// refresh token can be used once; presenting one that was already rotated
+// means it leaked, so the whole family and its access tokens are revoked and
+// ErrRefreshTokenReused is returned.
+func (s *TokenService) Refresh(ctx context.Context, refreshToken string) (*models.TokenPair, error) {
+	var pair *models.TokenPair
+	reused := false
+
+	err := s.uow.WithTx(ctx, func(tx *repositories.Tx) error {
+		now := s.now()
+
+		token, err := tx.RefreshTokens.GetByHash(ctx, utils.HashToken(refreshToken))
+		if errors.Is(err, constants.ErrInvalidRefreshToken) {
+			return constants.ErrInvalidRefreshToken
+		}
+		if err != nil {
+			return err
+		}
+
+		if token.RevokedAt != nil || !token.ExpiresAt.After(now) {
+			return constants.ErrInvalidRefreshToken
+		}
+
+		claimed := false
+		if token.UsedAt == nil {
+			claimed, err = tx.RefreshTokens.MarkUsed(ctx, token.ID, now)
+			if err != nil {
+				return err
+			}
+		}
+		if !claimed {
+			// The revocation has to be committed, so the error is only
+			// returned once the transaction is done.
+			reused = true
+			return revokeFamily(ctx, tx, token.FamilyID, now)
+		}
+
+		user, err := tx.Users.GetByID(ctx, token.UserID)
+		if err != nil {
+			return constants.ErrInvalidRefreshToken
+		}
+
+		pair, err = s.issue(ctx, tx, user, token.FamilyID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return nil, constants.ErrRefreshTokenReused
+	}
+
+	return pair, nil
+}
+
+// Logout revokes the access token the request was made with and, when a
+// refresh token of the same user is given, the session family it belongs to.
+func (s *TokenService) Logout(ctx context.Context, userID int, accessJTI string, accessExpiresAt time.Time, refreshToken string) error {
+	return s.uow.WithTx(ctx, func(tx *repositories.Tx) error {
+		now := s.now()
+
+		if err := tx.RevokedTokens.Revoke(ctx, accessJTI, userID, accessExpiresAt.UTC()); err != nil {
+			return err
+		}
+
+		if refreshToken == "" {
+			return nil
+		}
+
+		token, err := tx.RefreshTokens.GetByHash(ctx, utils.HashToken(refreshToken))
+		if errors.Is(err, constants.ErrInvalidRefreshToken) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if token.UserID != userID {
+			return nil
+		}
+
+		return revokeFamily(ctx, tx, token.FamilyID, now)
+	})
+}
+
+// LogoutAll revokes every refresh token of the user and every access token
+// issued with them, ending the user's sessions on all devices.
+func (s *TokenService) LogoutAll(ctx context.Context, userID int, accessJTI string, accessExpiresAt time.Time) error {
+	return s.uow.WithTx(ctx, func(tx *repositories.Tx) error {
+		now := s.now()
+
+		if err := tx.RevokedTokens.Revoke(ctx, accessJTI, userID, accessExpiresAt.UTC()); err != nil {
+			return err
+		}
+		if err := tx.RevokedTokens.RevokeUserAccessTokens(ctx, userID, now); err != nil {
+			return err
+		}
+
+		return tx.RefreshTokens.RevokeAllForUser(ctx, userID, now)
+	})
+}
+
+// IsRevoked reports whether the access token with the given jti was revoked.
+func (s *TokenService) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	return s.revokedRepo.IsRevoked(ctx, jti)
+}
+
+// DeleteExpiredTokens removes expired refresh tokens and expired entries of
+// the revocation list, and returns how many rows it removed.
+func (s *TokenService) DeleteExpiredTokens(ctx context.Context) (int64, error) {
+	var deleted int64
+	err := s.uow.WithTx(ctx, func(tx *repositories.Tx) error {
+		now := s.now()
+
+		revoked, err := tx.RevokedTokens.DeleteExpired(ctx, now)
+		if err != nil {
+			return err
+		}
+		refresh, err := tx.RefreshTokens.DeleteExpired(ctx, now)
+		if err != nil {
+			return err
+		}
+
+		deleted = revoked + refresh
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, nil
+}
+
+// RunCleanupWorker calls DeleteExpiredTokens every interval until ctx is done.
+func (s *TokenService) RunCleanupWorker(ctx context.Context, interval time.Duration) {
+	log := logger.Get()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Info().Dur("interval", interval).Msg("Token cleanup worker started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Token cleanup worker stopped")
+			return
+		case <-ticker.C:
+			deleted, err := s.DeleteExpiredTokens(ctx)
+			if err != nil {
+				log.Error().Err(err).Msg("Error deleting expired tokens")
+				continue
+			}
+			if deleted > 0 {
+				log.Info().Int64("deleted", deleted).Msg("Expired tokens deleted")
+			}
+		}
+	}
+}
+
+func (s *TokenService) issue(ctx context.Context, tx *repositories.Tx, user *models.User, familyID string) (*models.TokenPair, error) {
+	accessToken, claims, err := utils.GenerateAccessToken(user, s.accessTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	accessExpiresAt := claims.ExpiresAt.Time.UTC()
+	_, err = tx.RefreshTokens.Create(
+		ctx,
+		user.ID, familyID, utils.HashToken(refreshToken), claims.ID,
+		accessExpiresAt, s.now().Add(s.refreshTTL),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TokenPair{
+		AccessToken:     accessToken,
+		AccessExpiresAt: accessExpiresAt,
+		RefreshToken:    refreshToken,
+	}, nil
+}
+
+// revokeFamily revokes every refresh token of a family and the access tokens
+// issued with them.
+func revokeFamily(ctx context.Context, tx *repositories.Tx, familyID string, now time.Time) error {
+	if err := tx.RevokedTokens.RevokeFamilyAccessTokens(ctx, familyID, now); err != nil {
+		return err
+	}
+	return tx.RefreshTokens.RevokeFamily(ctx, familyID, now)
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/database"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+	"github.com/Nimirandad/bike-rental-service/internal/repositories"
+	"github.com/Nimirandad/bike-rental-service/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTokenService(t *testing.T) (*TokenService, *database.DB, *models.User) {
+	t.Helper()
+
+	os.Setenv("JWT_SECRET", "test-secret")
+	t.Cleanup(func() { os.Unsetenv("JWT_SECRET") })
+
+	db := newTestDB(t)
+	user, err := repositories.NewUserRepository(db).Create(t.Context(), "rider@example.com", "hash", "Ada", "Lovelace")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	service := NewTokenService(repositories.NewRevokedTokenRepository(db), repositories.NewUnitOfWork(db), 15*time.Minute, 24*time.Hour)
+	return service, db, user
+}
+
+func accessJTI(t *testing.T, pair *models.TokenPair) string {
+	t.Helper()
+
+	claims, err := utils.ValidateJWT(pair.AccessToken)
+	if err != nil {
+		t.Fatalf("invalid access token: %v", err)
+	}
+	return claims.ID
+}
+
+func TestTokenService_Refresh_RotatesToken(t *testing.T) {
+	service, _, user := newTestTokenService(t)
+
+	pair, err := service.IssueTokens(t.Context(), user)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, pair.RefreshToken)
+
+	refreshed, err := service.Refresh(t.Context(), pair.RefreshToken)
+	assert.NoError(t, err)
+	assert.NotEqual(t, pair.RefreshToken, refreshed.RefreshToken)
+	assert.NotEqual(t, accessJTI(t, pair), accessJTI(t, refreshed))
+
+	_, err = service.Refresh(t.Context(), "unknown")
+	assert.Equal(t, constants.ErrInvalidRefreshToken, err)
+}
+
+func TestTokenService_Refresh_ReuseRevokesFamily(t *testing.T) {
+	service, _, user := newTestTokenService(t)
+
+	first, err := service.IssueTokens(t.Context(), user)
+	assert.NoError(t, err)
+	second, err := service.Refresh(t.Context(), first.RefreshToken)
+	assert.NoError(t, err)
+
+	other, err := service.IssueTokens(t.Context(), user)
+	assert.NoError(t, err)
+
+	_, err = service.Refresh(t.Context(), first.RefreshToken)
+	assert.Equal(t, constants.ErrRefreshTokenReused, err)
+
+	_, err = service.Refresh(t.Context(), second.RefreshToken)
+	assert.Equal(t, constants.ErrInvalidRefreshToken, err)
+
+	revoked, err := service.IsRevoked(t.Context(), accessJTI(t, second))
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	revoked, err = service.IsRevoked(t.Context(), accessJTI(t, other))
+	assert.NoError(t, err)
+	assert.False(t, revoked, "sessions from other logins are untouched")
+
+	_, err = service.Refresh(t.Context(), other.RefreshToken)
+	assert.NoError(t, err)
+}
+
+func TestTokenService_Refresh_Expired(t *testing.T) {
+	service, _, user := newTestTokenService(t)
+
+	pair, err := service.IssueTokens(t.Context(), user)
+	assert.NoError(t, err)
+
+	service.now = func() time.Time { return utcNow().Add(25 * time.Hour) }
+
+	_, err = service.Refresh(t.Context(), pair.RefreshToken)
+	assert.Equal(t, constants.ErrInvalidRefreshToken, err)
+}
+
+func TestTokenService_DeleteExpiredTokens(t *testing.T) {
+	service, _, user := newTestTokenService(t)
+
+	expired, err := service.IssueTokens(t.Context(), user)
+	assert.NoError(t, err)
+	assert.NoError(t, service.Logout(t.Context(), user.ID, accessJTI(t, expired), expired.AccessExpiresAt, expired.RefreshToken))
+
+	// A day later the first session has expired while a new one is live.
+	later := utcNow().Add(25 * time.Hour)
+	service.now = func() time.Time { return later }
+	live, err := service.IssueTokens(t.Context(), user)
+	assert.NoError(t, err)
+
+	deleted, err := service.DeleteExpiredTokens(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	revoked, err := service.IsRevoked(t.Context(), accessJTI(t, expired))
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+	_, err = service.Refresh(t.Context(), expired.RefreshToken)
+	assert.Equal(t, constants.ErrInvalidRefreshToken, err)
+	_, err = service.Refresh(t.Context(), live.RefreshToken)
+	assert.NoError(t, err)
+
+	deleted, err = service.DeleteExpiredTokens(t.Context())
+	assert.NoError(t, err)
+	assert.Zero(t, deleted)
+}
+
+func TestTokenService_Logout(t *testing.T) {
+	service, _, user := newTestTokenService(t)
+
+	pair, err := service.IssueTokens(t.Context(), user)
+	assert.NoError(t, err)
+	jti := accessJTI(t, pair)
+
+	err = service.Logout(t.Context(), user.ID, jti, pair.AccessExpiresAt, pair.RefreshToken)
+	assert.NoError(t, err)
+
+	revoked, err := service.IsRevoked(t.Context(), jti)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	_, err = service.Refresh(t.Context(), pair.RefreshToken)
+	assert.Equal(t, constants.ErrInvalidRefreshToken, err)
+
+	// Logging out twice with the same token is harmless.
+	assert.NoError(t, service.Logout(t.Context(), user.ID, jti, pair.AccessExpiresAt, pair.RefreshToken))
+}
+
+func TestTokenService_Logout_IgnoresOtherUsersRefreshToken(t *testing.T) {
+	service, db, user := newTestTokenService(t)
+
+	other, err := repositories.NewUserRepository(db).Create(t.Context(), "other@example.com", "hash", "Grace", "Hopper")
+	assert.NoError(t, err)
+	otherPair, err := service.IssueTokens(t.Context(), other)
+	assert.NoError(t, err)
+
+	pair, err := service.IssueTokens(t.Context(), user)
+	assert.NoError(t, err)
+
+	err = service.Logout(t.Context(), user.ID, accessJTI(t, pair), pair.AccessExpiresAt, otherPair.RefreshToken)
+	assert.NoError(t, err)
+
+	_, err = service.Refresh(t.Context(), otherPair.RefreshToken)
+	assert.NoError(t, err)
+}
+
+func TestTokenService_LogoutAll(t *testing.T) {
+	service, _, user := newTestTokenService(t)
+
+	phone, err := service.IssueTokens(t.Context(), user)
+	assert.NoError(t, err)
+	laptop, err := service.IssueTokens(t.Context(), user)
+	assert.NoError(t, err)
+
+	err = service.LogoutAll(t.Context(), user.ID, accessJTI(t, phone), phone.AccessExpiresAt)
+	assert.NoError(t, err)
+
+	for _, pair := range []*models.TokenPair{phone, laptop} {
+		revoked, err := service.IsRevoked(t.Context(), accessJTI(t, pair))
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+
+		_, err = service.Refresh(t.Context(), pair.RefreshToken)
+		assert.Equal(t, constants.ErrInvalidRefreshToken, err)
+	}
+}
@@ -1,6 +1,8 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"github.com/Nimirandad/bike-rental-service/internal/constants"
@@ -10,12 +12,12 @@ import (
 )
 
 type UserRepository interface {
-	EmailExists(email string) (bool, error)
-	Create(email, hashedPassword, firstName, lastName string) (*models.User, error)
-	GetByID(userID int) (*models.User, error)
-	GetPasswordHashByEmail(email string) (string, *models.User, error)
-	EmailExistsByOtherUser(email string, userID int) (bool, error)
-	Update(userID int, email, firstName, lastName *string) (*models.User, error)
+	EmailExists(ctx context.Context, email string) (bool, error)
+	Create(ctx context.Context, email, hashedPassword, firstName, lastName string) (*models.User, error)
+	GetByID(ctx context.Context, userID int) (*models.User, error)
+	GetPasswordHashByEmail(ctx context.Context, email string) (string, *models.User, error)
+	EmailExistsByOtherUser(ctx context.Context, email string, userID int) (bool, error)
+	Update(ctx context.Context, userID int, email, firstName, lastName *string) (*models.User, error)
 }
 
 type UserService struct {
@@ -26,8 +28,8 @@ func NewUserService(userRepo *repositories.UserRepository) *UserService {
 	return &UserService{userRepo: userRepo}
 }
 
-func (s *UserService) RegisterUser(email, password, firstName, lastName string) (*models.User, error) {
-	exists, err := s.userRepo.EmailExists(email)
+func (s *UserService) RegisterUser(ctx context.Context, email, password, firstName, lastName string) (*models.User, error) {
+	exists, err := s.userRepo.EmailExists(ctx, email)
 	if err != nil {
 		return nil, fmt.Errorf("error checking email: %w", err)
 	}
@@ -40,7 +42,7 @@ func (s *UserService) RegisterUser(email, password, firstName, lastName string)
 		return nil, fmt.Errorf("error hashing password: %w", err)
 	}
 
-	user, err := s.userRepo.Create(email, hashedPassword, firstName, lastName)
+	user, err := s.userRepo.Create(ctx, email, hashedPassword, firstName, lastName)
 	if err != nil {
 		return nil, fmt.Errorf("error creating user: %w", err)
 	}
@@ -48,15 +50,18 @@ func (s *UserService) RegisterUser(email, password, firstName, lastName string)
 	return user, nil
 }
 
-func (s *UserService) GetByID(userID int) (*models.User, error) {
-	return s.userRepo.GetByID(userID)
+func (s *UserService) GetByID(ctx context.Context, userID int) (*models.User, error) {
+	return s.userRepo.GetByID(ctx, userID)
 }
 
-func (s *UserService) Login(email, password string) (*models.User, error) {
-	hashedPassword, user, err := s.userRepo.GetPasswordHashByEmail(email)
-	if err != nil {
+func (s *UserService) Login(ctx context.Context, email, password string) (*models.User, error) {
+	hashedPassword, user, err := s.userRepo.GetPasswordHashByEmail(ctx, email)
+	if errors.Is(err, constants.ErrUserNotFound) {
 		return nil, constants.ErrInvalidCredentials
 	}
+	if err != nil {
+		return nil, err
+	}
 
 	if !utils.VerifyPassword(password, hashedPassword) {
 		return nil, constants.ErrInvalidCredentials
@@ -65,9 +70,9 @@ func (s *UserService) Login(email, password string) (*models.User, error) {
 	return user, nil
 }
 
-func (s *UserService) UpdateUser(userID int, email, firstName, lastName *string) (*models.User, error) {
+func (s *UserService) UpdateUser(ctx context.Context, userID int, email, firstName, lastName *string) (*models.User, error) {
 	if email != nil && *email != "" {
-		exists, err := s.userRepo.EmailExistsByOtherUser(*email, userID)
+		exists, err := s.userRepo.EmailExistsByOtherUser(ctx, *email, userID)
 		if err != nil {
 			return nil, fmt.Errorf("error checking email: %w", err)
 		}
@@ -76,7 +81,7 @@ func (s *UserService) UpdateUser(userID int, email, firstName, lastName *string)
 		}
 	}
 
-	user, err := s.userRepo.Update(userID, email, firstName, lastName)
+	user, err := s.userRepo.Update(ctx, userID, email, firstName, lastName)
 	if err != nil {
 		return nil, fmt.Errorf("error updating user: %w", err)
 	}
@@ -1,7 +1,9 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -20,27 +22,27 @@ type MockUserRepository struct {
 	UpdateFunc                 func(userID int, email, firstName, lastName *string) (*models.User, error)
 }
 
-func (m *MockUserRepository) EmailExists(email string) (bool, error) {
+func (m *MockUserRepository) EmailExists(ctx context.Context, email string) (bool, error) {
 	return m.EmailExistsFunc(email)
 }
 
-func (m *MockUserRepository) Create(email, hashedPassword, firstName, lastName string) (*models.User, error) {
+func (m *MockUserRepository) Create(ctx context.Context, email, hashedPassword, firstName, lastName string) (*models.User, error) {
 	return m.CreateFunc(email, hashedPassword, firstName, lastName)
 }
 
-func (m *MockUserRepository) GetByID(userID int) (*models.User, error) {
+func (m *MockUserRepository) GetByID(ctx context.Context, userID int) (*models.User, error) {
 	return m.GetByIDFunc(userID)
 }
 
-func (m *MockUserRepository) GetPasswordHashByEmail(email string) (string, *models.User, error) {
+func (m *MockUserRepository) GetPasswordHashByEmail(ctx context.Context, email string) (string, *models.User, error) {
 	return m.GetPasswordHashByEmailFunc(email)
 }
 
-func (m *MockUserRepository) EmailExistsByOtherUser(email string, userID int) (bool, error) {
+func (m *MockUserRepository) EmailExistsByOtherUser(ctx context.Context, email string, userID int) (bool, error) {
 	return m.EmailExistsByOtherUserFunc(email, userID)
 }
 
-func (m *MockUserRepository) Update(userID int, email, firstName, lastName *string) (*models.User, error) {
+func (m *MockUserRepository) Update(ctx context.Context, userID int, email, firstName, lastName *string) (*models.User, error) {
 	return m.UpdateFunc(userID, email, firstName, lastName)
 }
 
@@ -63,7 +65,7 @@ func TestUserService_RegisterUser(t *testing.T) {
 
 		service := &UserService{userRepo: mockRepo}
 
-		user, err := service.RegisterUser("test@example.com", "Password123", "John", "Doe")
+		user, err := service.RegisterUser(t.Context(), "test@example.com", "Password123", "John", "Doe")
 
 		assert.NoError(t, err)
 		assert.NotNil(t, user)
@@ -80,7 +82,7 @@ func TestUserService_RegisterUser(t *testing.T) {
 
 		service := &UserService{userRepo: mockRepo}
 
-		user, err := service.RegisterUser("test@example.com", "Password123", "John", "Doe")
+		user, err := service.RegisterUser(t.Context(), "test@example.com", "Password123", "John", "Doe")
 
 		assert.Error(t, err)
 		assert.Nil(t, user)
@@ -96,7 +98,7 @@ func TestUserService_RegisterUser(t *testing.T) {
 
 		service := &UserService{userRepo: mockRepo}
 
-		user, err := service.RegisterUser("test@example.com", "Password123", "John", "Doe")
+		user, err := service.RegisterUser(t.Context(), "test@example.com", "Password123", "John", "Doe")
 
 		assert.Error(t, err)
 		assert.Nil(t, user)
@@ -120,7 +122,7 @@ func TestUserService_GetByID(t *testing.T) {
 
 		service := &UserService{userRepo: mockRepo}
 
-		user, err := service.GetByID(1)
+		user, err := service.GetByID(t.Context(), 1)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, user)
@@ -136,7 +138,7 @@ func TestUserService_GetByID(t *testing.T) {
 
 		service := &UserService{userRepo: mockRepo}
 
-		user, err := service.GetByID(999)
+		user, err := service.GetByID(t.Context(), 999)
 
 		assert.Error(t, err)
 		assert.Nil(t, user)
@@ -161,7 +163,7 @@ func TestUserService_Login(t *testing.T) {
 
 		service := &UserService{userRepo: mockRepo}
 
-		user, err := service.Login("test@example.com", "secret")
+		user, err := service.Login(t.Context(), "test@example.com", "secret")
 
 		assert.NoError(t, err)
 		assert.NotNil(t, user)
@@ -171,19 +173,35 @@ func TestUserService_Login(t *testing.T) {
 	t.Run("User not found", func(t *testing.T) {
 		mockRepo := &MockUserRepository{
 			GetPasswordHashByEmailFunc: func(email string) (string, *models.User, error) {
-				return "", nil, errors.New("user not found")
+				return "", nil, fmt.Errorf("user with email %s: %w", email, constants.ErrUserNotFound)
 			},
 		}
 
 		service := &UserService{userRepo: mockRepo}
 
-		user, err := service.Login("notfound@example.com", "password")
+		user, err := service.Login(t.Context(), "notfound@example.com", "password")
 
 		assert.Error(t, err)
 		assert.Nil(t, user)
 		assert.Equal(t, constants.ErrInvalidCredentials, err)
 	})
 
+	t.Run("Database error", func(t *testing.T) {
+		mockRepo := &MockUserRepository{
+			GetPasswordHashByEmailFunc: func(email string) (string, *models.User, error) {
+				return "", nil, errors.New("database error")
+			},
+		}
+
+		service := &UserService{userRepo: mockRepo}
+
+		user, err := service.Login(t.Context(), "user@example.com", "password")
+
+		assert.Error(t, err)
+		assert.Nil(t, user)
+		assert.NotEqual(t, constants.ErrInvalidCredentials, err)
+	})
+
 	t.Run("Wrong password", func(t *testing.T) {
 		hashedPassword, _ := utils.HashPassword("secret")
 
@@ -201,7 +219,7 @@ func TestUserService_Login(t *testing.T) {
 
 		service := &UserService{userRepo: mockRepo}
 
-		user, err := service.Login("test@example.com", "wrongpassword")
+		user, err := service.Login(t.Context(), "test@example.com", "wrongpassword")
 
 		assert.Error(t, err)
 		assert.Nil(t, user)
@@ -231,7 +249,7 @@ func TestUserService_UpdateUser(t *testing.T) {
 
 		service := &UserService{userRepo: mockRepo}
 
-		user, err := service.UpdateUser(1, &newEmail, &newFirstName, nil)
+		user, err := service.UpdateUser(t.Context(), 1, &newEmail, &newFirstName, nil)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, user)
@@ -248,7 +266,7 @@ func TestUserService_UpdateUser(t *testing.T) {
 
 		service := &UserService{userRepo: mockRepo}
 
-		user, err := service.UpdateUser(1, &newEmail, nil, nil)
+		user, err := service.UpdateUser(t.Context(), 1, &newEmail, nil, nil)
 
 		assert.Error(t, err)
 		assert.Nil(t, user)
@@ -271,7 +289,7 @@ func TestUserService_UpdateUser(t *testing.T) {
 
 		service := &UserService{userRepo: mockRepo}
 
-		user, err := service.UpdateUser(1, &emptyEmail, &newFirstName, nil)
+		user, err := service.UpdateUser(t.Context(), 1, &emptyEmail, &newFirstName, nil)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, user)
@@ -0,0 +1,25 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetup(t *testing.T) {
+	for _, exporter := range []string{"", ExporterNone, ExporterStdout, ExporterOTLP} {
+		shutdown, err := Setup(context.Background(), exporter, "http://localhost:4318")
+		assert.NoError(t, err, exporter)
+		if shutdown != nil {
+			assert.NoError(t, shutdown(context.Background()), exporter)
+		}
+	}
+}
+
+func TestSetup_UnknownExporter(t *testing.T) {
+	shutdown, err := Setup(context.Background(), "jaeger", "")
+
+	assert.Error(t, err)
+	assert.Nil(t, shutdown)
+}
@@ -1,5 +1,11 @@
 package types
 
+import (
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+)
+
 type RegisterUserRequest struct {
 	Email     string `json:"email"`
 	Password  string `json:"password"`
@@ -12,23 +18,56 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+// RefreshTokenRequest carries a refresh token. It is required to refresh and
+// optional on logout, where it also ends the token's session.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
 type UpdateUserRequest struct {
 	Email     *string `json:"email,omitempty"`
 	FirstName *string `json:"first_name,omitempty"`
 	LastName  *string `json:"last_name,omitempty"`
 }
 
+// AddBikeRequest describes a new bike. Type defaults to classic, and a
+// missing price_per_minute is taken from the type's default price.
 type AddBikeRequest struct {
 	Latitude       float64  `json:"latitude"`
 	Longitude      float64  `json:"longitude"`
 	PricePerMinute *float64 `json:"price_per_minute,omitempty"`
+	PricePlanID    *int     `json:"price_plan_id,omitempty"`
+	Type           string   `json:"type,omitempty"`
+	BatteryLevel   *int     `json:"battery_level,omitempty"`
 }
 
+// UpdateBikeRequest holds the fields to change. A price_plan_id of 0 removes
+// the bike's price plan.
 type UpdateBikeRequest struct {
 	Latitude       *float64 `json:"latitude,omitempty"`
 	Longitude      *float64 `json:"longitude,omitempty"`
 	IsAvailable    *bool    `json:"is_available,omitempty"`
 	PricePerMinute *float64 `json:"price_per_minute,omitempty"`
+	PricePlanID    *int     `json:"price_plan_id,omitempty"`
+	Type           *string  `json:"type,omitempty"`
+	BatteryLevel   *int     `json:"battery_level,omitempty"`
+}
+
+type BikeTypePriceRequest struct {
+	PricePerMinute float64 `json:"price_per_minute"`
 }
 
 type AdminUpdateUserRequest struct {
@@ -49,4 +88,81 @@ type StartRentalRequest struct {
 type EndRentalRequest struct {
 	Latitude  float64 `json:"latitude"`
 	Longitude float64 `json:"longitude"`
-}
\ No newline at end of file
+}
+
+// TrackRentalRequest is a batch of locations for the active rental. A point
+// without recorded_at is taken as recorded when the request arrives.
+type TrackRentalRequest struct {
+	Points []TrackPoint `json:"points"`
+}
+
+type TrackPoint struct {
+	Latitude   float64    `json:"latitude"`
+	Longitude  float64    `json:"longitude"`
+	RecordedAt *time.Time `json:"recorded_at,omitempty"`
+}
+
+// PricePlanRequest is used to create and update price plans. On update only
+// the fields present are changed; a price_per_minute, daily_cap or
+// paused_price_per_minute of 0 clears the value so the bike's own price, no
+// cap or the default paused rate applies. zone_rates, when present, replaces
+// every zone rate of the plan; an empty list removes them.
+type PricePlanRequest struct {
+	Name                 *string            `json:"name,omitempty"`
+	UnlockFee            *float64           `json:"unlock_fee,omitempty"`
+	PricePerMinute       *float64           `json:"price_per_minute,omitempty"`
+	FreeMinutes          *int               `json:"free_minutes,omitempty"`
+	DailyCap             *float64           `json:"daily_cap,omitempty"`
+	NightMultiplier      *float64           `json:"night_multiplier,omitempty"`
+	NightStartHour       *int               `json:"night_start_hour,omitempty"`
+	NightEndHour         *int               `json:"night_end_hour,omitempty"`
+	WeekendMultiplier    *float64           `json:"weekend_multiplier,omitempty"`
+	Timezone             *string            `json:"timezone,omitempty"`
+	PausedPricePerMinute *float64           `json:"paused_price_per_minute,omitempty"`
+	ZoneRates            *[]ZoneRateRequest `json:"zone_rates,omitempty"`
+}
+
+// ZoneRateRequest is one zone rate of a price plan. multiplier defaults to 1.
+type ZoneRateRequest struct {
+	StationID  int      `json:"station_id"`
+	ApplyTo    string   `json:"apply_to"`
+	Fee        float64  `json:"fee"`
+	Multiplier *float64 `json:"multiplier,omitempty"`
+}
+
+// StationRequest is used to create and update stations. On update only the
+// fields present are changed. Setting radius_meters clears the polygon and
+// setting polygon clears the radius, so a station always has one of them.
+type StationRequest struct {
+	Name         *string            `json:"name,omitempty"`
+	Latitude     *float64           `json:"latitude,omitempty"`
+	Longitude    *float64           `json:"longitude,omitempty"`
+	RadiusMeters *float64           `json:"radius_meters,omitempty"`
+	Polygon      *[]models.GeoPoint `json:"polygon,omitempty"`
+	Capacity     *int               `json:"capacity,omitempty"`
+}
+
+// ReturnRuleRequest replaces the return distance rule. max_distance_km is
+// required unless mode is none.
+type ReturnRuleRequest struct {
+	Mode          string  `json:"mode"`
+	MaxDistanceKm float64 `json:"max_distance_km"`
+}
+
+type ReserveBikeRequest struct {
+	BikeID int `json:"bike_id"`
+}
+
+type CreateAdminRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+}
+
+type UpdateAdminRequest struct {
+	Name     *string `json:"name,omitempty"`
+	Role     *string `json:"role,omitempty"`
+	Password *string `json:"password,omitempty"`
+	Disabled *bool   `json:"disabled,omitempty"`
+}
@@ -1,13 +1,34 @@
 package types
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strings"
+
+	"github.com/Nimirandad/bike-rental-service/internal/apperrors"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
 )
 
-type ErrorResponse struct {
-	Error   string            `json:"error"`
+// ProblemContentType is the media type of error responses (RFC 7807).
+const ProblemContentType = "application/problem+json"
+
+// Problem is the body of every error response. Code is a stable,
+// machine-readable identifier that clients should match on instead of Detail.
+// Details lists the failed fields of a validation error, and the status
+// fields describe a refused rental status transition.
+type Problem struct {
+	Type    string            `json:"type"`
+	Title   string            `json:"title"`
+	Status  int               `json:"status"`
+	Detail  string            `json:"detail,omitempty"`
+	Code    string            `json:"code"`
 	Details map[string]string `json:"details,omitempty"`
+
+	CurrentStatus      string   `json:"current_status,omitempty"`
+	RequestedStatus    string   `json:"requested_status,omitempty"`
+	AllowedTransitions []string `json:"allowed_transitions,omitempty"`
 }
 
 type SuccessResponse struct {
@@ -21,15 +42,69 @@ func WriteJSON(w http.ResponseWriter, status int, data interface{}) error {
 	return json.NewEncoder(w).Encode(data)
 }
 
+func newProblem(status int, code, detail string) Problem {
+	return Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+func writeProblem(w http.ResponseWriter, problem Problem) {
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// WriteError writes a problem for an error detected in the handler itself,
+// such as a malformed path parameter. Its code is derived from the status,
+// e.g. bad_request or unauthorized.
 func WriteError(w http.ResponseWriter, status int, message string) {
-	WriteJSON(w, status, ErrorResponse{Error: message})
+	code := strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+	writeProblem(w, newProblem(status, code, message))
+}
+
+// WriteProblem maps an error returned by a service to a problem response.
+// Typed errors from apperrors use the status of their kind and their code;
+// a refused rental transition also lists the statuses it could move to. A
+// query that ran past its deadline is answered with 504 and one whose request
+// was cancelled with 503. Any other error is a 500 with message as detail, so
+// internal error text never reaches the client.
+func WriteProblem(w http.ResponseWriter, err error, message string) {
+	var transitionErr *models.RentalTransitionError
+	if errors.As(err, &transitionErr) {
+		problem := newProblem(http.StatusUnprocessableEntity, "invalid_rental_transition",
+			"Rental status cannot be changed from "+string(transitionErr.From)+" to "+string(transitionErr.To))
+		problem.CurrentStatus = string(transitionErr.From)
+		problem.RequestedStatus = string(transitionErr.To)
+		for _, status := range transitionErr.Allowed {
+			problem.AllowedTransitions = append(problem.AllowedTransitions, string(status))
+		}
+		writeProblem(w, problem)
+		return
+	}
+
+	if appErr, ok := apperrors.As(err); ok {
+		writeProblem(w, newProblem(appErr.Kind.Status(), appErr.Code, appErr.Message))
+		return
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		writeProblem(w, newProblem(http.StatusGatewayTimeout, "timeout", "The request timed out"))
+	case errors.Is(err, context.Canceled):
+		writeProblem(w, newProblem(http.StatusServiceUnavailable, "request_cancelled", "The request was cancelled"))
+	default:
+		writeProblem(w, newProblem(http.StatusInternalServerError, "internal_error", message))
+	}
 }
 
 func WriteValidationErrors(w http.ResponseWriter, errors map[string]string) {
-	WriteJSON(w, http.StatusBadRequest, ErrorResponse{
-		Error:   "Validation failed",
-		Details: errors,
-	})
+	problem := newProblem(http.StatusBadRequest, "validation_failed", "Validation failed")
+	problem.Details = errors
+	writeProblem(w, problem)
 }
 
 func WriteSuccess(w http.ResponseWriter, message string, data interface{}) {
@@ -39,8 +114,17 @@ func WriteSuccess(w http.ResponseWriter, message string, data interface{}) {
 	})
 }
 
+// LoginResponse carries the access token. Rider logins and refreshes also
+// return a refresh token and the access token's lifetime in seconds.
 type LoginResponse struct {
-	Token string      `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int    `json:"expires_in,omitempty"`
+}
+
+// TrackRentalResponse reports how many locations were recorded.
+type TrackRentalResponse struct {
+	Recorded int `json:"recorded"`
 }
 
 type PaginatedResponse struct {
@@ -66,4 +150,24 @@ func WritePaginatedSuccess(w http.ResponseWriter, message string, data interface
 		Limit:      limit,
 		TotalPages: totalPages,
 	})
-}
\ No newline at end of file
+}
+
+// CursorPaginatedResponse is the envelope for keyset-paginated lists. It
+// carries no totals; an empty cursor means there is no page that way.
+type CursorPaginatedResponse struct {
+	Message    string      `json:"message,omitempty"`
+	Data       interface{} `json:"data"`
+	Limit      int         `json:"limit"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+	PrevCursor string      `json:"prev_cursor,omitempty"`
+}
+
+func WriteCursorPaginatedSuccess(w http.ResponseWriter, message string, data interface{}, limit int, nextCursor, prevCursor string) {
+	WriteJSON(w, http.StatusOK, CursorPaginatedResponse{
+		Message:    message,
+		Data:       data,
+		Limit:      limit,
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+	})
+}
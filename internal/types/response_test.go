@@ -1,9 +1,16 @@
 package types
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/Nimirandad/bike-rental-service/internal/apperrors"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -25,7 +32,9 @@ func TestWriteError(t *testing.T) {
 	WriteError(w, 400, "test error")
 
 	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
 	assert.Contains(t, w.Body.String(), "test error")
+	assert.Contains(t, w.Body.String(), `"code":"bad_request"`)
 }
 
 func TestWriteValidationErrors(t *testing.T) {
@@ -39,6 +48,7 @@ func TestWriteValidationErrors(t *testing.T) {
 
 	assert.Equal(t, 400, w.Code)
 	assert.Contains(t, w.Body.String(), "Validation failed")
+	assert.Contains(t, w.Body.String(), `"code":"validation_failed"`)
 	assert.Contains(t, w.Body.String(), "invalid email")
 }
 
@@ -72,4 +82,63 @@ func TestWritePaginatedSuccess_ZeroTotal(t *testing.T) {
 
 	assert.Equal(t, 200, w.Code)
 	assert.Contains(t, w.Body.String(), "empty")
-}
\ No newline at end of file
+}
+
+func TestWriteCursorPaginatedSuccess(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	WriteCursorPaginatedSuccess(w, "test", []string{"item1"}, 10, "next-token", "")
+
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), `"next_cursor":"next-token"`)
+	assert.NotContains(t, w.Body.String(), "prev_cursor")
+	assert.NotContains(t, w.Body.String(), "total")
+}
+
+func TestWriteProblem(t *testing.T) {
+	errBikeNotFound := apperrors.NotFound("bike_not_found", "bike not found")
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+		detail string
+	}{
+		{"Typed error", fmt.Errorf("bike with id 7: %w", errBikeNotFound), http.StatusNotFound, "bike_not_found", "bike not found"},
+		{"Deadline exceeded", fmt.Errorf("error counting bikes: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, "timeout", "The request timed out"},
+		{"Request cancelled", context.Canceled, http.StatusServiceUnavailable, "request_cancelled", "The request was cancelled"},
+		{"Other errors", errors.New("connection refused"), http.StatusInternalServerError, "internal_error", "Error retrieving bikes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			WriteProblem(w, tt.err, "Error retrieving bikes")
+
+			var problem Problem
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.status, problem.Status)
+			assert.Equal(t, http.StatusText(tt.status), problem.Title)
+			assert.Equal(t, tt.code, problem.Code)
+			assert.Equal(t, tt.detail, problem.Detail)
+		})
+	}
+}
+
+func TestWriteProblem_RentalTransition(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	WriteProblem(w, models.NewRentalTransitionError(models.RentalStatusEnded, models.RentalStatusRunning), "Error updating rental")
+
+	var problem Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "invalid_rental_transition", problem.Code)
+	assert.Equal(t, "ended", problem.CurrentStatus)
+	assert.Equal(t, "running", problem.RequestedStatus)
+	assert.Equal(t, []string{"refunded"}, problem.AllowedTransitions)
+}
@@ -1,41 +1,21 @@
 package utils
 
 import (
-	"encoding/base64"
 	"fmt"
-	"os"
-	"strings"
 )
 
-func ValidateAdminBasicAuth(authHeader string) error {
-	if authHeader == "" {
-		return fmt.Errorf("authorization header is required")
-	}
-
-	if !strings.HasPrefix(authHeader, "Basic ") {
-		return fmt.Errorf("authorization header must use Basic authentication")
-	}
-
-	encodedCredentials := strings.TrimPrefix(authHeader, "Basic ")
-
-	expectedCredentials := os.Getenv("ADMIN_CREDENTIALS")
-	if expectedCredentials == "" {
-		return fmt.Errorf("admin credentials not configured")
-	}
-
-	if encodedCredentials != expectedCredentials {
-		return fmt.Errorf("invalid admin credentials")
-	}
-
-	decodedBytes, err := base64.StdEncoding.DecodeString(encodedCredentials)
+// ValidateAdminToken checks a "Bearer <token>" header carrying an admin JWT
+// and returns its claims. Rider tokens are rejected.
+func ValidateAdminToken(authHeader string) (*AdminJWTClaims, error) {
+	tokenString, err := ExtractTokenFromHeader(authHeader)
 	if err != nil {
-		return fmt.Errorf("invalid authorization format")
+		return nil, err
 	}
 
-	credentials := string(decodedBytes)
-	if !strings.Contains(credentials, ":") {
-		return fmt.Errorf("invalid credentials format")
+	claims, err := ValidateAdminJWT(tokenString)
+	if err != nil {
+		return nil, fmt.Errorf("invalid or expired admin token")
 	}
 
-	return nil
-}
\ No newline at end of file
+	return claims, nil
+}
@@ -1,207 +1,72 @@
 package utils
 
 import (
-	"encoding/base64"
 	"os"
 	"testing"
+
+	"github.com/Nimirandad/bike-rental-service/internal/models"
 )
 
-func TestValidateAdminBasicAuth(t *testing.T) {
-	originalCreds := os.Getenv("ADMIN_CREDENTIALS")
+func TestValidateAdminToken(t *testing.T) {
+	originalSecret := os.Getenv("JWT_SECRET")
 	defer func() {
-		if originalCreds != "" {
-			os.Setenv("ADMIN_CREDENTIALS", originalCreds)
+		if originalSecret != "" {
+			os.Setenv("JWT_SECRET", originalSecret)
 		} else {
-			os.Unsetenv("ADMIN_CREDENTIALS")
+			os.Unsetenv("JWT_SECRET")
 		}
 	}()
+	os.Setenv("JWT_SECRET", "test-secret-key")
 
-	t.Run("Valid credentials", func(t *testing.T) {
-		encodedCreds := base64.StdEncoding.EncodeToString([]byte("admin:password123"))
-		os.Setenv("ADMIN_CREDENTIALS", encodedCreds)
-
-		authHeader := "Basic " + encodedCreds
-
-		err := ValidateAdminBasicAuth(authHeader)
+	t.Run("Valid admin token", func(t *testing.T) {
+		token, err := GenerateAdminJWT(&models.Admin{ID: 3, Email: "ops@example.com", Role: models.AdminRoleFleet})
 		if err != nil {
-			t.Errorf("ValidateAdminBasicAuth() error = %v, want nil", err)
-		}
-	})
-
-	t.Run("Valid credentials with extra spaces", func(t *testing.T) {
-		encodedCreds := base64.StdEncoding.EncodeToString([]byte("admin:password123"))
-		os.Setenv("ADMIN_CREDENTIALS", encodedCreds)
-
-		authHeader := "Basic " + encodedCreds + "  "
-
-		err := ValidateAdminBasicAuth(authHeader)
-		if err == nil {
-			t.Error("ValidateAdminBasicAuth() expected error for extra spaces, got nil")
-		}
-	})
-
-	t.Run("Missing authorization header", func(t *testing.T) {
-		encodedCreds := base64.StdEncoding.EncodeToString([]byte("admin:password123"))
-		os.Setenv("ADMIN_CREDENTIALS", encodedCreds)
-
-		err := ValidateAdminBasicAuth("")
-		if err == nil {
-			t.Error("ValidateAdminBasicAuth() expected error for empty header, got nil")
-		}
-
-		expectedError := "authorization header is required"
-		if err.Error() != expectedError {
-			t.Errorf("ValidateAdminBasicAuth() error = %v, want %v", err.Error(), expectedError)
-		}
-	})
-
-	t.Run("Invalid header format - no Basic prefix", func(t *testing.T) {
-		encodedCreds := base64.StdEncoding.EncodeToString([]byte("admin:password123"))
-		os.Setenv("ADMIN_CREDENTIALS", encodedCreds)
-
-		authHeader := encodedCreds
-
-		err := ValidateAdminBasicAuth(authHeader)
-		if err == nil {
-			t.Error("ValidateAdminBasicAuth() expected error for missing Basic prefix, got nil")
-		}
-
-		expectedError := "authorization header must use Basic authentication"
-		if err.Error() != expectedError {
-			t.Errorf("ValidateAdminBasicAuth() error = %v, want %v", err.Error(), expectedError)
-		}
-	})
-
-	t.Run("Invalid header format - wrong case", func(t *testing.T) {
-		encodedCreds := base64.StdEncoding.EncodeToString([]byte("admin:password123"))
-		os.Setenv("ADMIN_CREDENTIALS", encodedCreds)
-
-		authHeader := "basic " + encodedCreds
-
-		err := ValidateAdminBasicAuth(authHeader)
-		if err == nil {
-			t.Error("ValidateAdminBasicAuth() expected error for wrong case, got nil")
-		}
-	})
-
-	t.Run("Invalid base64 encoding", func(t *testing.T) {
-		encodedCreds := base64.StdEncoding.EncodeToString([]byte("admin:password123"))
-		os.Setenv("ADMIN_CREDENTIALS", encodedCreds)
-
-		authHeader := "Basic not-valid-base64!"
-
-		err := ValidateAdminBasicAuth(authHeader)
-		if err == nil {
-			t.Error("ValidateAdminBasicAuth() expected error for invalid base64, got nil")
-		}
-
-		expectedError := "invalid admin credentials"
-		if err.Error() != expectedError {
-			t.Errorf("ValidateAdminBasicAuth() error = %v, want %v", err.Error(), expectedError)
-		}
-	})
-
-	t.Run("Invalid credentials format - no colon", func(t *testing.T) {
-		wrongCredentials := base64.StdEncoding.EncodeToString([]byte("adminpassword123"))
-		os.Setenv("ADMIN_CREDENTIALS", wrongCredentials)
-
-		authHeader := "Basic " + wrongCredentials
-
-		err := ValidateAdminBasicAuth(authHeader)
-		if err == nil {
-			t.Error("ValidateAdminBasicAuth() expected error for missing colon, got nil")
+			t.Fatalf("GenerateAdminJWT() error = %v", err)
 		}
 
-		expectedError := "invalid credentials format"
-		if err.Error() != expectedError {
-			t.Errorf("ValidateAdminBasicAuth() error = %v, want %v", err.Error(), expectedError)
-		}
-	})
-
-	t.Run("Wrong username", func(t *testing.T) {
-		correctCreds := base64.StdEncoding.EncodeToString([]byte("admin:password123"))
-		os.Setenv("ADMIN_CREDENTIALS", correctCreds)
-
-		wrongCreds := base64.StdEncoding.EncodeToString([]byte("wronguser:password123"))
-		authHeader := "Basic " + wrongCreds
-
-		err := ValidateAdminBasicAuth(authHeader)
-		if err == nil {
-			t.Error("ValidateAdminBasicAuth() expected error for wrong username, got nil")
+		claims, err := ValidateAdminToken("Bearer " + token)
+		if err != nil {
+			t.Errorf("ValidateAdminToken() error = %v, want nil", err)
 		}
-
-		expectedError := "invalid admin credentials"
-		if err.Error() != expectedError {
-			t.Errorf("ValidateAdminBasicAuth() error = %v, want %v", err.Error(), expectedError)
+		if claims.Sub != 3 || claims.Role != models.AdminRoleFleet {
+			t.Errorf("ValidateAdminToken() claims = %+v, want admin 3 with role fleet", claims)
 		}
 	})
 
-	t.Run("Wrong password", func(t *testing.T) {
-		correctCreds := base64.StdEncoding.EncodeToString([]byte("admin:password123"))
-		os.Setenv("ADMIN_CREDENTIALS", correctCreds)
-
-		wrongCreds := base64.StdEncoding.EncodeToString([]byte("admin:wrongpassword"))
-		authHeader := "Basic " + wrongCreds
-
-		err := ValidateAdminBasicAuth(authHeader)
-		if err == nil {
-			t.Error("ValidateAdminBasicAuth() expected error for wrong password, got nil")
-		}
-
-		expectedError := "invalid admin credentials"
-		if err.Error() != expectedError {
-			t.Errorf("ValidateAdminBasicAuth() error = %v, want %v", err.Error(), expectedError)
+	t.Run("Missing authorization header", func(t *testing.T) {
+		if _, err := ValidateAdminToken(""); err == nil {
+			t.Error("ValidateAdminToken() expected error for empty header, got nil")
 		}
 	})
 
-	t.Run("Missing ADMIN_CREDENTIALS env var", func(t *testing.T) {
-		os.Unsetenv("ADMIN_CREDENTIALS")
-
-		authHeader := "Basic " + base64.StdEncoding.EncodeToString([]byte("admin:password123"))
-
-		err := ValidateAdminBasicAuth(authHeader)
-		if err == nil {
-			t.Error("ValidateAdminBasicAuth() expected error for missing env var, got nil")
-		}
-
-		expectedError := "admin credentials not configured"
-		if err.Error() != expectedError {
-			t.Errorf("ValidateAdminBasicAuth() error = %v, want %v", err.Error(), expectedError)
+	t.Run("Basic authentication is rejected", func(t *testing.T) {
+		if _, err := ValidateAdminToken("Basic YWRtaW46YWRtaW4xMjM="); err == nil {
+			t.Error("ValidateAdminToken() expected error for Basic header, got nil")
 		}
 	})
 
-	t.Run("ADMIN_CREDENTIALS without colon", func(t *testing.T) {
-		wrongCreds := base64.StdEncoding.EncodeToString([]byte("adminpassword"))
-		os.Setenv("ADMIN_CREDENTIALS", wrongCreds)
-
-		authHeader := "Basic " + wrongCreds
-
-		err := ValidateAdminBasicAuth(authHeader)
-		if err == nil {
-			t.Error("ValidateAdminBasicAuth() expected error for malformed env var, got nil")
+	t.Run("Rider token is rejected", func(t *testing.T) {
+		token, err := GenerateJWT(&models.User{ID: 3, Email: "rider@example.com"})
+		if err != nil {
+			t.Fatalf("GenerateJWT() error = %v", err)
 		}
 
-		expectedError := "invalid credentials format"
-		if err.Error() != expectedError {
-			t.Errorf("ValidateAdminBasicAuth() error = %v, want %v", err.Error(), expectedError)
+		if _, err := ValidateAdminToken("Bearer " + token); err == nil {
+			t.Error("ValidateAdminToken() expected error for rider token, got nil")
 		}
 	})
+}
 
-	t.Run("Empty credentials", func(t *testing.T) {
-		correctCreds := base64.StdEncoding.EncodeToString([]byte("admin:password123"))
-		os.Setenv("ADMIN_CREDENTIALS", correctCreds)
+func TestValidateJWT_RejectsAdminToken(t *testing.T) {
+	os.Setenv("JWT_SECRET", "test-secret-key")
+	defer os.Unsetenv("JWT_SECRET")
 
-		emptyCreds := base64.StdEncoding.EncodeToString([]byte(":"))
-		authHeader := "Basic " + emptyCreds
+	token, err := GenerateAdminJWT(&models.Admin{ID: 1, Email: "ops@example.com", Role: models.AdminRoleSuperadmin})
+	if err != nil {
+		t.Fatalf("GenerateAdminJWT() error = %v", err)
+	}
 
-		err := ValidateAdminBasicAuth(authHeader)
-		if err == nil {
-			t.Error("ValidateAdminBasicAuth() expected error for empty credentials, got nil")
-		}
-
-		expectedError := "invalid admin credentials"
-		if err.Error() != expectedError {
-			t.Errorf("ValidateAdminBasicAuth() error = %v, want %v", err.Error(), expectedError)
-		}
-	})
-}
\ No newline at end of file
+	if _, err := ValidateJWT(token); err == nil {
+		t.Error("ValidateJWT() expected error for admin token, got nil")
+	}
+}
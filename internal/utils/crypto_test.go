@@ -153,6 +153,36 @@ func TestHashPasswordConsistency(t *testing.T) {
 	}
 }
 
+func TestGenerateRandomToken(t *testing.T) {
+	token1, err := GenerateRandomToken(32)
+	if err != nil {
+		t.Fatalf("GenerateRandomToken() error = %v", err)
+	}
+
+	token2, _ := GenerateRandomToken(32)
+
+	if len(token1) != 43 {
+		t.Errorf("GenerateRandomToken(32) length = %d, want 43", len(token1))
+	}
+	if token1 == token2 {
+		t.Error("GenerateRandomToken() returned the same token twice")
+	}
+}
+
+func TestHashToken(t *testing.T) {
+	hash := HashToken("refresh-token")
+
+	if len(hash) != 64 {
+		t.Errorf("HashToken() length = %d, want 64", len(hash))
+	}
+	if hash != HashToken("refresh-token") {
+		t.Error("HashToken() is not deterministic")
+	}
+	if hash == HashToken("other-token") {
+		t.Error("HashToken() returned the same hash for different tokens")
+	}
+}
+
 func BenchmarkHashPassword(b *testing.B) {
 	password := "Test1234"
 
@@ -1,6 +1,10 @@
 package utils
 
-import "math"
+import (
+	"math"
+
+	"github.com/Nimirandad/bike-rental-service/internal/models"
+)
 
 const earthRadiusKm = 6371.0
 
@@ -22,4 +26,84 @@ func HaversineDistance(lat1, lon1, lat2, lon2 float64) float64 {
 
 func degreesToRadians(degrees float64) float64 {
 	return degrees * math.Pi / 180
-}
\ No newline at end of file
+}
+
+// BoundingBox returns the latitude/longitude box that fully contains the circle
+// of radiusKm around the given point. It is meant as a cheap SQL prefilter; the
+// exact distance still has to be checked with HaversineDistance.
+func BoundingBox(lat, lon, radiusKm float64) (minLat, maxLat, minLon, maxLon float64) {
+	latDelta := radiusKm / earthRadiusKm * 180 / math.Pi
+
+	minLat = math.Max(lat-latDelta, -90)
+	maxLat = math.Min(lat+latDelta, 90)
+
+	if minLat == -90 || maxLat == 90 {
+		return minLat, maxLat, -180, 180
+	}
+
+	lonDelta := latDelta / math.Cos(degreesToRadians(lat))
+	minLon = lon - lonDelta
+	maxLon = lon + lonDelta
+
+	if minLon < -180 || maxLon > 180 {
+		return minLat, maxLat, -180, 180
+	}
+
+	return minLat, maxLat, minLon, maxLon
+}
+
+// PointInPolygon reports whether the point lies inside polygon, using ray
+// casting on plain latitude/longitude. That is accurate enough for areas the
+// size of a city block; polygons crossing the antimeridian are not supported.
+func PointInPolygon(lat, lon float64, polygon []models.GeoPoint) bool {
+	if len(polygon) < 3 {
+		return false
+	}
+
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a.Latitude > lat) != (b.Latitude > lat) &&
+			lon < (b.Longitude-a.Longitude)*(lat-a.Latitude)/(b.Latitude-a.Latitude)+a.Longitude {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// PointInRings reports whether the point lies inside a polygon given as
+// rings, where rings[0] is the outer boundary and the rest are holes.
+func PointInRings(lat, lon float64, rings [][]models.GeoPoint) bool {
+	if len(rings) == 0 || !PointInPolygon(lat, lon, rings[0]) {
+		return false
+	}
+	for _, hole := range rings[1:] {
+		if PointInPolygon(lat, lon, hole) {
+			return false
+		}
+	}
+	return true
+}
+
+// StationContains reports whether the point lies in the station's polygon,
+// or within its radius when it has no polygon.
+func StationContains(station *models.Station, lat, lon float64) bool {
+	if len(station.Polygon) > 0 {
+		return PointInPolygon(lat, lon, station.Polygon)
+	}
+	if station.RadiusMeters == nil {
+		return false
+	}
+	return HaversineDistance(station.Latitude, station.Longitude, lat, lon)*1000 <= *station.RadiusMeters
+}
+
+// PathDistance returns the length in km of the path through points, summing
+// the great-circle distance between consecutive points. It is rounded to the
+// meter.
+func PathDistance(points []models.GeoPoint) float64 {
+	total := 0.0
+	for i := 1; i < len(points); i++ {
+		total += HaversineDistance(points[i-1].Latitude, points[i-1].Longitude, points[i].Latitude, points[i].Longitude)
+	}
+	return math.Round(total*1000) / 1000
+}
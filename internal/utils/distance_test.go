@@ -3,6 +3,8 @@ package utils
 import (
 	"math"
 	"testing"
+
+	"github.com/Nimirandad/bike-rental-service/internal/models"
 )
 
 func TestHaversineDistance(t *testing.T) {
@@ -117,6 +119,155 @@ func TestDegreesToRadians(t *testing.T) {
 	}
 }
 
+func TestBoundingBox(t *testing.T) {
+	t.Run("Box contains points on the radius", func(t *testing.T) {
+		lat, lon, radius := 51.5074, -0.1278, 2.0
+		minLat, maxLat, minLon, maxLon := BoundingBox(lat, lon, radius)
+
+		if minLat >= lat || maxLat <= lat || minLon >= lon || maxLon <= lon {
+			t.Fatalf("BoundingBox() does not contain the centre: %v %v %v %v", minLat, maxLat, minLon, maxLon)
+		}
+
+		north := HaversineDistance(lat, lon, maxLat, lon)
+		east := HaversineDistance(lat, lon, lat, maxLon)
+
+		if math.Abs(north-radius) > 0.01 {
+			t.Errorf("north edge distance = %.4f, want %.4f", north, radius)
+		}
+		if east < radius-0.01 {
+			t.Errorf("east edge distance = %.4f, want at least %.4f", east, radius)
+		}
+	})
+
+	t.Run("Near the pole covers all longitudes", func(t *testing.T) {
+		_, maxLat, minLon, maxLon := BoundingBox(89.99, 10, 5)
+
+		if maxLat != 90 || minLon != -180 || maxLon != 180 {
+			t.Errorf("BoundingBox() = maxLat %v, lon [%v, %v], want 90 and [-180, 180]", maxLat, minLon, maxLon)
+		}
+	})
+
+	t.Run("Crossing the antimeridian covers all longitudes", func(t *testing.T) {
+		_, _, minLon, maxLon := BoundingBox(0, 179.99, 5)
+
+		if minLon != -180 || maxLon != 180 {
+			t.Errorf("BoundingBox() lon = [%v, %v], want [-180, 180]", minLon, maxLon)
+		}
+	})
+}
+
+func TestPointInPolygon(t *testing.T) {
+	square := []models.GeoPoint{
+		{Latitude: 40.0, Longitude: -3.0},
+		{Latitude: 40.0, Longitude: -2.9},
+		{Latitude: 40.1, Longitude: -2.9},
+		{Latitude: 40.1, Longitude: -3.0},
+	}
+
+	tests := []struct {
+		name     string
+		lat, lon float64
+		want     bool
+	}{
+		{"Centre", 40.05, -2.95, true},
+		{"Near a corner inside", 40.001, -2.999, true},
+		{"North of the square", 40.2, -2.95, false},
+		{"West of the square", 40.05, -3.1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PointInPolygon(tt.lat, tt.lon, square); got != tt.want {
+				t.Errorf("PointInPolygon(%v, %v) = %v, want %v", tt.lat, tt.lon, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("Too few points", func(t *testing.T) {
+		if PointInPolygon(40.0, -3.0, square[:2]) {
+			t.Error("PointInPolygon() = true for a two point polygon")
+		}
+	})
+}
+
+func TestPointInRings(t *testing.T) {
+	outer := []models.GeoPoint{
+		{Latitude: 40.0, Longitude: -3.0},
+		{Latitude: 40.0, Longitude: -2.0},
+		{Latitude: 41.0, Longitude: -2.0},
+		{Latitude: 41.0, Longitude: -3.0},
+		{Latitude: 40.0, Longitude: -3.0},
+	}
+	hole := []models.GeoPoint{
+		{Latitude: 40.4, Longitude: -2.6},
+		{Latitude: 40.4, Longitude: -2.4},
+		{Latitude: 40.6, Longitude: -2.4},
+		{Latitude: 40.6, Longitude: -2.6},
+		{Latitude: 40.4, Longitude: -2.6},
+	}
+	rings := [][]models.GeoPoint{outer, hole}
+
+	if !PointInRings(40.2, -2.8, rings) {
+		t.Error("PointInRings() = false for a point between the boundary and the hole")
+	}
+	if PointInRings(40.5, -2.5, rings) {
+		t.Error("PointInRings() = true for a point in the hole")
+	}
+	if PointInRings(42.0, -2.5, rings) {
+		t.Error("PointInRings() = true for a point outside the boundary")
+	}
+	if PointInRings(40.5, -2.5, nil) {
+		t.Error("PointInRings() = true without rings")
+	}
+}
+
+func TestStationContains(t *testing.T) {
+	radius := 100.0
+	circle := &models.Station{Latitude: 40.4155, Longitude: -3.7074, RadiusMeters: &radius}
+	square := &models.Station{
+		Latitude:  40.4155,
+		Longitude: -3.7074,
+		Polygon: []models.GeoPoint{
+			{Latitude: 40.415, Longitude: -3.708},
+			{Latitude: 40.415, Longitude: -3.707},
+			{Latitude: 40.416, Longitude: -3.707},
+			{Latitude: 40.416, Longitude: -3.708},
+		},
+	}
+
+	if !StationContains(circle, 40.4160, -3.7074) {
+		t.Error("StationContains() = false for a point within the radius")
+	}
+	if StationContains(circle, 40.4180, -3.7074) {
+		t.Error("StationContains() = true for a point beyond the radius")
+	}
+	if !StationContains(square, 40.4152, -3.7078) {
+		t.Error("StationContains() = false for a point in the polygon")
+	}
+	if StationContains(square, 40.4170, -3.7078) {
+		t.Error("StationContains() = true for a point outside the polygon")
+	}
+	if StationContains(&models.Station{}, 0, 0) {
+		t.Error("StationContains() = true for a station without an area")
+	}
+}
+
+func TestPathDistance(t *testing.T) {
+	madrid := models.GeoPoint{Latitude: 40.416775, Longitude: -3.703790}
+	toledo := models.GeoPoint{Latitude: 39.862832, Longitude: -4.027323}
+
+	direct := HaversineDistance(madrid.Latitude, madrid.Longitude, toledo.Latitude, toledo.Longitude)
+	if got := PathDistance([]models.GeoPoint{madrid, toledo, madrid}); math.Abs(got-2*direct) > 0.001 {
+		t.Errorf("PathDistance() = %v, want %v", got, 2*direct)
+	}
+	if got := PathDistance([]models.GeoPoint{madrid}); got != 0 {
+		t.Errorf("PathDistance() with one point = %v, want 0", got)
+	}
+	if got := PathDistance(nil); got != 0 {
+		t.Errorf("PathDistance() without points = %v, want 0", got)
+	}
+}
+
 func BenchmarkHaversineDistance(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		HaversineDistance(51.5074, -0.1278, 48.8566, 2.3522)
@@ -18,20 +18,102 @@ type JWTClaims struct {
 	jwt.RegisteredClaims
 }
 
+// AccessTokenTTL is the lifetime of rider access tokens issued by GenerateJWT.
+// Riders keep their session with refresh tokens instead of long-lived JWTs.
+const AccessTokenTTL = 15 * time.Minute
+
 func GenerateJWT(user *models.User) (string, error) {
+	tokenString, _, err := GenerateAccessToken(user, AccessTokenTTL)
+	return tokenString, err
+}
+
+// GenerateAccessToken signs a rider token valid for ttl and returns it with
+// its claims. Every token gets a random jti so it can be revoked on its own.
+func GenerateAccessToken(user *models.User, ttl time.Duration) (string, *JWTClaims, error) {
 	secret := os.Getenv("JWT_SECRET")
 	if secret == "" {
-		return "", fmt.Errorf("JWT_SECRET environment variable not set")
+		return "", nil, fmt.Errorf("JWT_SECRET environment variable not set")
+	}
+
+	jti, err := GenerateRandomToken(16)
+	if err != nil {
+		return "", nil, fmt.Errorf("error generating token id: %w", err)
 	}
 
-	expirationTime := time.Now().Add(30 * 24 * time.Hour)
+	now := time.Now()
 
-	claims := JWTClaims{
+	claims := &JWTClaims{
 		Sub:       user.ID,
 		Email:     user.Email,
 		FirstName: user.FirstName,
 		LastName:  user.LastName,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	tokenString, err := token.SignedString([]byte(secret))
+	if err != nil {
+		return "", nil, fmt.Errorf("error signing token: %w", err)
+	}
+
+	return tokenString, claims, nil
+}
+
+func ValidateJWT(tokenString string) (*JWTClaims, error) {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		return nil, fmt.Errorf("JWT_SECRET environment variable not set")
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(secret), nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("error parsing token: %w", err)
+	}
+
+	// Rider access tokens carry no audience; admin and action tokens always do.
+	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid && len(claims.Audience) == 0 {
+		return claims, nil
+	}
+
+	return nil, fmt.Errorf("invalid token")
+}
+
+// AdminAudience marks tokens issued to admin accounts, so an admin token is
+// never accepted as a rider token and the other way around.
+const AdminAudience = "admin"
+
+type AdminJWTClaims struct {
+	Sub   int              `json:"sub"`
+	Email string           `json:"email"`
+	Role  models.AdminRole `json:"role"`
+	jwt.RegisteredClaims
+}
+
+func GenerateAdminJWT(admin *models.Admin) (string, error) {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		return "", fmt.Errorf("JWT_SECRET environment variable not set")
+	}
+
+	expirationTime := time.Now().Add(12 * time.Hour)
+
+	claims := AdminJWTClaims{
+		Sub:   admin.ID,
+		Email: admin.Email,
+		Role:  admin.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{AdminAudience},
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
@@ -47,24 +129,90 @@ func GenerateJWT(user *models.User) (string, error) {
 	return tokenString, nil
 }
 
-func ValidateJWT(tokenString string) (*JWTClaims, error) {
+func ValidateAdminJWT(tokenString string) (*AdminJWTClaims, error) {
 	secret := os.Getenv("JWT_SECRET")
 	if secret == "" {
 		return nil, fmt.Errorf("JWT_SECRET environment variable not set")
 	}
 
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &AdminJWTClaims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return []byte(secret), nil
-	})
+	}, jwt.WithAudience(AdminAudience))
+
+	if err != nil {
+		return nil, fmt.Errorf("error parsing token: %w", err)
+	}
+
+	if claims, ok := token.Claims.(*AdminJWTClaims); ok && token.Valid && claims.Role.IsValid() {
+		return claims, nil
+	}
+
+	return nil, fmt.Errorf("invalid token")
+}
+
+// ActionTokenClaims identify the user a single-use emailed token was issued
+// to. The token's purpose is its audience.
+type ActionTokenClaims struct {
+	Sub int `json:"sub"`
+	jwt.RegisteredClaims
+}
+
+// GenerateActionToken signs a token for purpose, such as a password reset,
+// valid for ttl. Its jti is what the caller stores to make it single-use.
+func GenerateActionToken(userID int, purpose string, ttl time.Duration) (string, *ActionTokenClaims, error) {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		return "", nil, fmt.Errorf("JWT_SECRET environment variable not set")
+	}
+
+	jti, err := GenerateRandomToken(16)
+	if err != nil {
+		return "", nil, fmt.Errorf("error generating token id: %w", err)
+	}
+
+	now := time.Now()
+
+	claims := &ActionTokenClaims{
+		Sub: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Audience:  jwt.ClaimStrings{purpose},
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		return "", nil, fmt.Errorf("error signing token: %w", err)
+	}
+
+	return tokenString, claims, nil
+}
+
+// ValidateActionToken checks the signature, expiry and purpose of a token from
+// GenerateActionToken. Whether it was already used is up to the caller.
+func ValidateActionToken(tokenString, purpose string) (*ActionTokenClaims, error) {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		return nil, fmt.Errorf("JWT_SECRET environment variable not set")
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &ActionTokenClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(secret), nil
+	}, jwt.WithAudience(purpose), jwt.WithExpirationRequired())
 
 	if err != nil {
 		return nil, fmt.Errorf("error parsing token: %w", err)
 	}
 
-	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
+	if claims, ok := token.Claims.(*ActionTokenClaims); ok && token.Valid && claims.ID != "" {
 		return claims, nil
 	}
 
@@ -109,6 +109,39 @@ func TestGenerateJWT(t *testing.T) {
 	})
 }
 
+func TestGenerateAccessToken(t *testing.T) {
+	os.Setenv("JWT_SECRET", "test-secret-key")
+	defer os.Unsetenv("JWT_SECRET")
+
+	user := &models.User{ID: 1, Email: "test@example.com"}
+
+	token, claims, err := GenerateAccessToken(user, 5*time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateAccessToken() error = %v, want nil", err)
+	}
+
+	if claims.ID == "" {
+		t.Error("GenerateAccessToken() claims have no jti")
+	}
+
+	if ttl := time.Until(claims.ExpiresAt.Time); ttl > 5*time.Minute || ttl < 4*time.Minute {
+		t.Errorf("GenerateAccessToken() expires in %v, want about 5m", ttl)
+	}
+
+	parsed, err := ValidateJWT(token)
+	if err != nil {
+		t.Fatalf("ValidateJWT() error = %v, want nil", err)
+	}
+	if parsed.ID != claims.ID {
+		t.Errorf("ValidateJWT() jti = %q, want %q", parsed.ID, claims.ID)
+	}
+
+	_, other, _ := GenerateAccessToken(user, 5*time.Minute)
+	if other.ID == claims.ID {
+		t.Error("GenerateAccessToken() reused a jti")
+	}
+}
+
 func TestValidateJWT(t *testing.T) {
 	originalSecret := os.Getenv("JWT_SECRET")
 	defer func() {
@@ -1,8 +1,13 @@
 package utils
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
+	"time"
+
+	"github.com/Nimirandad/bike-rental-service/internal/constants"
+	"github.com/Nimirandad/bike-rental-service/internal/models"
 )
 
 func ValidateEmail(email string) (bool, string) {
@@ -100,6 +105,20 @@ func ValidateLoginRequest(email, password string) map[string]string {
 	return errors
 }
 
+func ValidateResetPasswordRequest(token, password string) map[string]string {
+	errors := make(map[string]string)
+
+	if token == "" {
+		errors["token"] = "Token is required"
+	}
+
+	if valid, msg := ValidatePassword(password); !valid {
+		errors["password"] = msg
+	}
+
+	return errors
+}
+
 func ValidateUpdateUserRequest(email, firstName, lastName *string) map[string]string {
 	errors := make(map[string]string)
 
@@ -122,4 +141,216 @@ func ValidateUpdateUserRequest(email, firstName, lastName *string) map[string]st
 	}
 
 	return errors
-}
\ No newline at end of file
+}
+
+func ValidateCreateAdminRequest(email, password, name, role string) map[string]string {
+	errors := make(map[string]string)
+
+	if valid, msg := ValidateEmail(email); !valid {
+		errors["email"] = msg
+	}
+
+	if valid, msg := ValidatePassword(password); !valid {
+		errors["password"] = msg
+	}
+
+	if valid, msg := ValidateName(name, "Name"); !valid {
+		errors["name"] = msg
+	}
+
+	if !models.AdminRole(role).IsValid() {
+		errors["role"] = "Role must be one of support, fleet, finance or superadmin"
+	}
+
+	return errors
+}
+
+func ValidateUpdateAdminRequest(name, role, password *string) map[string]string {
+	errors := make(map[string]string)
+
+	if name != nil {
+		if valid, msg := ValidateName(*name, "Name"); !valid {
+			errors["name"] = msg
+		}
+	}
+
+	if role != nil && !models.AdminRole(*role).IsValid() {
+		errors["role"] = "Role must be one of support, fleet, finance or superadmin"
+	}
+
+	if password != nil {
+		if valid, msg := ValidatePassword(*password); !valid {
+			errors["password"] = msg
+		}
+	}
+
+	return errors
+}
+
+func ValidatePricePlan(plan *models.PricePlan) map[string]string {
+	errors := make(map[string]string)
+
+	name := strings.TrimSpace(plan.Name)
+	if name == "" {
+		errors["name"] = "Name is required"
+	} else if len(name) > 100 {
+		errors["name"] = "Name is too long (max 100 characters)"
+	}
+
+	if plan.UnlockFee < 0 {
+		errors["unlock_fee"] = "Unlock fee cannot be negative"
+	}
+
+	if plan.PricePerMinute != nil && *plan.PricePerMinute <= 0 {
+		errors["price_per_minute"] = "Price per minute must be greater than 0"
+	}
+
+	if plan.FreeMinutes < 0 {
+		errors["free_minutes"] = "Free minutes cannot be negative"
+	}
+
+	if plan.DailyCap != nil && *plan.DailyCap <= 0 {
+		errors["daily_cap"] = "Daily cap must be greater than 0"
+	}
+
+	if plan.NightMultiplier <= 0 {
+		errors["night_multiplier"] = "Night multiplier must be greater than 0"
+	}
+
+	if plan.NightStartHour < 0 || plan.NightStartHour > 23 {
+		errors["night_start_hour"] = "Night start hour must be between 0 and 23"
+	}
+
+	if plan.NightEndHour < 0 || plan.NightEndHour > 23 {
+		errors["night_end_hour"] = "Night end hour must be between 0 and 23"
+	}
+
+	if plan.WeekendMultiplier <= 0 {
+		errors["weekend_multiplier"] = "Weekend multiplier must be greater than 0"
+	}
+
+	if plan.PausedPricePerMinute != nil && *plan.PausedPricePerMinute < 0 {
+		errors["paused_price_per_minute"] = "Paused price per minute cannot be negative"
+	}
+
+	if plan.Timezone == "" {
+		errors["timezone"] = "Timezone is required"
+	} else if _, err := time.LoadLocation(plan.Timezone); err != nil {
+		errors["timezone"] = "Unknown timezone"
+	}
+
+	for i, rate := range plan.ZoneRates {
+		key := fmt.Sprintf("zone_rates[%d]", i)
+		switch {
+		case rate.StationID <= 0:
+			errors[key] = "Station ID is required"
+		case !rate.ApplyTo.IsValid():
+			errors[key] = "Apply to must be start or end"
+		case rate.Fee < 0:
+			errors[key] = "Fee cannot be negative"
+		case rate.Multiplier <= 0:
+			errors[key] = "Multiplier must be greater than 0"
+		}
+	}
+
+	return errors
+}
+
+func ValidateStation(station *models.Station) map[string]string {
+	errors := make(map[string]string)
+
+	name := strings.TrimSpace(station.Name)
+	if name == "" {
+		errors["name"] = "Name is required"
+	} else if len(name) > 100 {
+		errors["name"] = "Name is too long (max 100 characters)"
+	}
+
+	if !validCoordinates(station.Latitude, station.Longitude) {
+		errors["location"] = "Latitude must be between -90 and 90 and longitude between -180 and 180"
+	}
+
+	if station.Capacity <= 0 {
+		errors["capacity"] = "Capacity must be greater than 0"
+	}
+
+	switch {
+	case station.RadiusMeters == nil && len(station.Polygon) == 0:
+		errors["area"] = "Either radius_meters or polygon is required"
+	case station.RadiusMeters != nil && len(station.Polygon) > 0:
+		errors["area"] = "Set either radius_meters or polygon, not both"
+	case station.RadiusMeters != nil:
+		if *station.RadiusMeters <= 0 || *station.RadiusMeters > constants.MaxStationRadiusMeters {
+			errors["radius_meters"] = fmt.Sprintf("Radius must be greater than 0 and at most %.0f meters", constants.MaxStationRadiusMeters)
+		}
+	default:
+		if msg := validatePolygon(station); msg != "" {
+			errors["polygon"] = msg
+		}
+	}
+
+	return errors
+}
+
+func ValidateReturnRule(rule *models.ReturnDistanceRule) map[string]string {
+	errors := make(map[string]string)
+
+	if !rule.Mode.IsValid() {
+		errors["mode"] = "Mode must be one of none, from_start or from_station"
+	} else if rule.Mode != models.ReturnDistanceNone && rule.MaxDistanceKm <= 0 {
+		errors["max_distance_km"] = "Max distance must be greater than 0"
+	}
+
+	return errors
+}
+
+// ValidateTrackPoints checks a batch of tracked locations. Errors are keyed
+// by the point's index, e.g. points[2].
+func ValidateTrackPoints(points []models.RentalPoint, now time.Time) map[string]string {
+	errors := make(map[string]string)
+
+	if len(points) == 0 {
+		errors["points"] = "At least one point is required"
+		return errors
+	}
+	if len(points) > constants.MaxTrackPoints {
+		errors["points"] = fmt.Sprintf("At most %d points can be sent at once", constants.MaxTrackPoints)
+		return errors
+	}
+
+	for i, point := range points {
+		key := fmt.Sprintf("points[%d]", i)
+		switch {
+		case !validCoordinates(point.Latitude, point.Longitude):
+			errors[key] = "Latitude must be between -90 and 90 and longitude between -180 and 180"
+		case point.RecordedAt.After(now.Add(constants.MaxTrackClockSkew)):
+			errors[key] = "Recorded time cannot be in the future"
+		}
+	}
+
+	return errors
+}
+
+// validatePolygon checks that the polygon has enough valid vertices and lies
+// within the maximum station radius of the station's anchor point.
+func validatePolygon(station *models.Station) string {
+	if len(station.Polygon) < constants.MinStationPolygonSize {
+		return fmt.Sprintf("Polygon needs at least %d points", constants.MinStationPolygonSize)
+	}
+
+	for _, point := range station.Polygon {
+		if !validCoordinates(point.Latitude, point.Longitude) {
+			return "Polygon points must have valid coordinates"
+		}
+		if HaversineDistance(station.Latitude, station.Longitude, point.Latitude, point.Longitude)*1000 > constants.MaxStationRadiusMeters {
+			return fmt.Sprintf("Polygon points must be within %.0f meters of the station location", constants.MaxStationRadiusMeters)
+		}
+	}
+
+	return ""
+}
+
+func validCoordinates(latitude, longitude float64) bool {
+	return latitude >= constants.MinLatitude && latitude <= constants.MaxLatitude &&
+		longitude >= constants.MinLongitude && longitude <= constants.MaxLongitude
+}
@@ -2,6 +2,8 @@ package utils
 
 import (
 	"testing"
+
+	"github.com/Nimirandad/bike-rental-service/internal/models"
 )
 
 func TestValidateEmail(t *testing.T) {
@@ -384,6 +386,47 @@ func TestValidateLoginRequest(t *testing.T) {
 	}
 }
 
+func TestValidateResetPasswordRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      string
+		password   string
+		wantErrors map[string]string
+	}{
+		{
+			name:       "Valid reset",
+			token:      "signed-token",
+			password:   "NewPass123",
+			wantErrors: map[string]string{},
+		},
+		{
+			name:     "Missing token and weak password",
+			token:    "",
+			password: "short",
+			wantErrors: map[string]string{
+				"token":    "Token is required",
+				"password": "Password must be at least 8 characters",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errors := ValidateResetPasswordRequest(tt.token, tt.password)
+
+			if len(errors) != len(tt.wantErrors) {
+				t.Errorf("ValidateResetPasswordRequest() errors count = %v, want %v", len(errors), len(tt.wantErrors))
+			}
+
+			for key, wantMsg := range tt.wantErrors {
+				if gotMsg := errors[key]; gotMsg != wantMsg {
+					t.Errorf("ValidateResetPasswordRequest() error[%v] = %v, want %v", key, gotMsg, wantMsg)
+				}
+			}
+		})
+	}
+}
+
 func TestValidateUpdateUserRequest(t *testing.T) {
 	validEmail := "test@example.com"
 	invalidEmail := "invalid"
@@ -457,4 +500,285 @@ func TestValidateUpdateUserRequest(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestValidatePricePlan(t *testing.T) {
+	negative := -1.0
+	zero := 0.0
+
+	validPlan := func() *models.PricePlan {
+		return &models.PricePlan{
+			Name:              "Standard",
+			NightMultiplier:   1,
+			WeekendMultiplier: 1,
+			Timezone:          "Europe/Madrid",
+		}
+	}
+
+	tests := []struct {
+		name       string
+		modify     func(plan *models.PricePlan)
+		wantErrors map[string]string
+	}{
+		{
+			name:       "Valid plan",
+			modify:     func(plan *models.PricePlan) {},
+			wantErrors: map[string]string{},
+		},
+		{
+			name:   "Missing name",
+			modify: func(plan *models.PricePlan) { plan.Name = "  " },
+			wantErrors: map[string]string{
+				"name": "Name is required",
+			},
+		},
+		{
+			name: "Negative amounts",
+			modify: func(plan *models.PricePlan) {
+				plan.UnlockFee = -1
+				plan.FreeMinutes = -5
+				plan.PricePerMinute = &negative
+				plan.DailyCap = &zero
+			},
+			wantErrors: map[string]string{
+				"unlock_fee":       "Unlock fee cannot be negative",
+				"free_minutes":     "Free minutes cannot be negative",
+				"price_per_minute": "Price per minute must be greater than 0",
+				"daily_cap":        "Daily cap must be greater than 0",
+			},
+		},
+		{
+			name: "Invalid night window and multipliers",
+			modify: func(plan *models.PricePlan) {
+				plan.NightStartHour = 24
+				plan.NightEndHour = -1
+				plan.NightMultiplier = 0
+				plan.WeekendMultiplier = -2
+			},
+			wantErrors: map[string]string{
+				"night_start_hour":   "Night start hour must be between 0 and 23",
+				"night_end_hour":     "Night end hour must be between 0 and 23",
+				"night_multiplier":   "Night multiplier must be greater than 0",
+				"weekend_multiplier": "Weekend multiplier must be greater than 0",
+			},
+		},
+		{
+			name:   "Unknown timezone",
+			modify: func(plan *models.PricePlan) { plan.Timezone = "Mars/Olympus" },
+			wantErrors: map[string]string{
+				"timezone": "Unknown timezone",
+			},
+		},
+		{
+			name: "Valid zone rates",
+			modify: func(plan *models.PricePlan) {
+				plan.ZoneRates = []models.ZoneRate{
+					{StationID: 1, ApplyTo: models.ZoneRateStart, Fee: 0.5, Multiplier: 1},
+					{StationID: 1, ApplyTo: models.ZoneRateEnd, Multiplier: 0.8},
+				}
+			},
+			wantErrors: map[string]string{},
+		},
+		{
+			name: "Invalid zone rates",
+			modify: func(plan *models.PricePlan) {
+				plan.ZoneRates = []models.ZoneRate{
+					{ApplyTo: models.ZoneRateStart, Multiplier: 1},
+					{StationID: 1, ApplyTo: "middle", Multiplier: 1},
+					{StationID: 1, ApplyTo: models.ZoneRateEnd, Fee: -1, Multiplier: 1},
+					{StationID: 1, ApplyTo: models.ZoneRateEnd},
+				}
+			},
+			wantErrors: map[string]string{
+				"zone_rates[0]": "Station ID is required",
+				"zone_rates[1]": "Apply to must be start or end",
+				"zone_rates[2]": "Fee cannot be negative",
+				"zone_rates[3]": "Multiplier must be greater than 0",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := validPlan()
+			tt.modify(plan)
+			errors := ValidatePricePlan(plan)
+
+			if len(errors) != len(tt.wantErrors) {
+				t.Errorf("ValidatePricePlan() errors count = %v, want %v (%v)", len(errors), len(tt.wantErrors), errors)
+			}
+
+			for key, wantMsg := range tt.wantErrors {
+				if gotMsg, ok := errors[key]; !ok {
+					t.Errorf("ValidatePricePlan() missing error for %v", key)
+				} else if gotMsg != wantMsg {
+					t.Errorf("ValidatePricePlan() error[%v] = %v, want %v", key, gotMsg, wantMsg)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateStation(t *testing.T) {
+	radius := 50.0
+	tooLarge := 5000.0
+
+	validStation := func() *models.Station {
+		return &models.Station{
+			Name:         "Plaza Mayor",
+			Latitude:     40.4155,
+			Longitude:    -3.7074,
+			RadiusMeters: &radius,
+			Capacity:     10,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		modify     func(station *models.Station)
+		wantErrors map[string]string
+	}{
+		{
+			name:       "Valid radius station",
+			modify:     func(station *models.Station) {},
+			wantErrors: map[string]string{},
+		},
+		{
+			name: "Valid polygon station",
+			modify: func(station *models.Station) {
+				station.RadiusMeters = nil
+				station.Polygon = []models.GeoPoint{
+					{Latitude: 40.4154, Longitude: -3.7075},
+					{Latitude: 40.4154, Longitude: -3.7073},
+					{Latitude: 40.4156, Longitude: -3.7074},
+				}
+			},
+			wantErrors: map[string]string{},
+		},
+		{
+			name: "Missing name, capacity and area",
+			modify: func(station *models.Station) {
+				station.Name = " "
+				station.Capacity = 0
+				station.RadiusMeters = nil
+			},
+			wantErrors: map[string]string{
+				"name":     "Name is required",
+				"capacity": "Capacity must be greater than 0",
+				"area":     "Either radius_meters or polygon is required",
+			},
+		},
+		{
+			name: "Radius and polygon",
+			modify: func(station *models.Station) {
+				station.Polygon = []models.GeoPoint{{Latitude: 40.4154, Longitude: -3.7075}}
+			},
+			wantErrors: map[string]string{
+				"area": "Set either radius_meters or polygon, not both",
+			},
+		},
+		{
+			name: "Radius too large and invalid location",
+			modify: func(station *models.Station) {
+				station.RadiusMeters = &tooLarge
+				station.Latitude = 91
+			},
+			wantErrors: map[string]string{
+				"radius_meters": "Radius must be greater than 0 and at most 1000 meters",
+				"location":      "Latitude must be between -90 and 90 and longitude between -180 and 180",
+			},
+		},
+		{
+			name: "Polygon with too few points",
+			modify: func(station *models.Station) {
+				station.RadiusMeters = nil
+				station.Polygon = []models.GeoPoint{{Latitude: 40.4154, Longitude: -3.7075}, {Latitude: 40.4156, Longitude: -3.7074}}
+			},
+			wantErrors: map[string]string{
+				"polygon": "Polygon needs at least 3 points",
+			},
+		},
+		{
+			name: "Polygon far from the station",
+			modify: func(station *models.Station) {
+				station.RadiusMeters = nil
+				station.Polygon = []models.GeoPoint{
+					{Latitude: 40.4154, Longitude: -3.7075},
+					{Latitude: 40.4154, Longitude: -3.7073},
+					{Latitude: 40.5, Longitude: -3.7074},
+				}
+			},
+			wantErrors: map[string]string{
+				"polygon": "Polygon points must be within 1000 meters of the station location",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			station := validStation()
+			tt.modify(station)
+			errors := ValidateStation(station)
+
+			if len(errors) != len(tt.wantErrors) {
+				t.Errorf("ValidateStation() errors count = %v, want %v (%v)", len(errors), len(tt.wantErrors), errors)
+			}
+
+			for key, wantMsg := range tt.wantErrors {
+				if gotMsg, ok := errors[key]; !ok {
+					t.Errorf("ValidateStation() missing error for %v", key)
+				} else if gotMsg != wantMsg {
+					t.Errorf("ValidateStation() error[%v] = %v, want %v", key, gotMsg, wantMsg)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateReturnRule(t *testing.T) {
+	tests := []struct {
+		name       string
+		rule       models.ReturnDistanceRule
+		wantErrors map[string]string
+	}{
+		{
+			name:       "From start",
+			rule:       models.ReturnDistanceRule{Mode: models.ReturnDistanceFromStart, MaxDistanceKm: 5},
+			wantErrors: map[string]string{},
+		},
+		{
+			name:       "None needs no distance",
+			rule:       models.ReturnDistanceRule{Mode: models.ReturnDistanceNone},
+			wantErrors: map[string]string{},
+		},
+		{
+			name: "From station without distance",
+			rule: models.ReturnDistanceRule{Mode: models.ReturnDistanceFromStation},
+			wantErrors: map[string]string{
+				"max_distance_km": "Max distance must be greater than 0",
+			},
+		},
+		{
+			name: "Unknown mode",
+			rule: models.ReturnDistanceRule{Mode: "anywhere", MaxDistanceKm: 5},
+			wantErrors: map[string]string{
+				"mode": "Mode must be one of none, from_start or from_station",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errors := ValidateReturnRule(&tt.rule)
+
+			if len(errors) != len(tt.wantErrors) {
+				t.Errorf("ValidateReturnRule() errors count = %v, want %v (%v)", len(errors), len(tt.wantErrors), errors)
+			}
+
+			for key, wantMsg := range tt.wantErrors {
+				if gotMsg := errors[key]; gotMsg != wantMsg {
+					t.Errorf("ValidateReturnRule() error[%v] = %v, want %v", key, gotMsg, wantMsg)
+				}
+			}
+		})
+	}
+}